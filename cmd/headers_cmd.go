@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/ledgerstore"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/urfave/cli"
+)
+
+var HeadersCommand = cli.Command{
+	Action:    auditHeaders,
+	Name:      "headers",
+	Usage:     "Audit the side chain headers stored in DB",
+	ArgsUsage: "",
+	Flags: []cli.Flag{
+		utils.HeadersChainIDFlag,
+		utils.HeadersReportFileFlag,
+		utils.DataDirFlag,
+		utils.ConfigFlag,
+		utils.NetworkIdFlag,
+	},
+	Description: "Open the states DB read-only, dump the main chain index of the side chain and the orphaned headers, " +
+		"and verify the parent links and difficulty sums or consensus peers from genesis header to current header. " +
+		"Supported routers are eth, btc and ont. The node must be stopped before auditing.",
+}
+
+func auditHeaders(ctx *cli.Context) error {
+	if !ctx.IsSet(utils.GetFlagName(utils.HeadersChainIDFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.HeadersChainIDFlag.Name)
+		cli.ShowCommandHelp(ctx, "headers")
+		return nil
+	}
+	chainID := ctx.Uint64(utils.GetFlagName(utils.HeadersChainIDFlag))
+
+	cfg := config.DefConfig
+	err := setGenesis(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("setGenesis error:%s", err)
+	}
+	cfg.P2PNode.NetworkId = uint32(ctx.Uint(utils.GetFlagName(utils.NetworkIdFlag)))
+	cfg.P2PNode.NetworkMagic = config.GetNetworkMagic(cfg.P2PNode.NetworkId)
+	cfg.P2PNode.NetworkName = config.GetNetworkName(cfg.P2PNode.NetworkId)
+	cfg.Common.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+
+	dbDir := utils.GetStoreDirPath(cfg.Common.DataDir, cfg.P2PNode.NetworkName)
+	statePath := fmt.Sprintf("%s%s%s", dbDir, string(os.PathSeparator), ledgerstore.DBDirState)
+	store, err := leveldbstore.NewReadOnlyLevelDBStore(statePath)
+	if err != nil {
+		return fmt.Errorf("open states DB:%s error:%s", statePath, err)
+	}
+	defer store.Close()
+
+	//writes of the audit only go to the memory of cache db, the store is never committed
+	cacheDB := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	service, err := native.NewNativeService(cacheDB, &types.Transaction{}, 0, 0, common.Uint256{}, 0, nil, true)
+	if err != nil {
+		return fmt.Errorf("NewNativeService error:%s", err)
+	}
+	audit, err := header_sync.AuditHeaders(service, chainID)
+	if err != nil {
+		return fmt.Errorf("audit headers of chain %d error:%s", chainID, err)
+	}
+
+	reportFile := ctx.String(utils.GetFlagName(utils.HeadersReportFileFlag))
+	if reportFile == "" {
+		PrintJsonObject(audit)
+		return nil
+	}
+	data, err := json.MarshalIndent(audit, "", "   ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal error:%s", err)
+	}
+	err = ioutil.WriteFile(reportFile, data, 0664)
+	if err != nil {
+		return fmt.Errorf("write report file:%s error:%s", reportFile, err)
+	}
+	PrintInfoMsg("Audit headers of chain %d successfully.", chainID)
+	PrintInfoMsg("MainChain:%d Orphans:%d Issues:%d", len(audit.MainChain), len(audit.Orphans), len(audit.Issues))
+	PrintInfoMsg("Report file:%s", reportFile)
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/ledger"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/urfave/cli"
+)
+
+var ReplayCommand = cli.Command{
+	Action:    replayBlocks,
+	Name:      "replay",
+	Usage:     "Re-execute blocks in DB to check the determinism of native contracts",
+	ArgsUsage: "",
+	Flags: []cli.Flag{
+		utils.ReplayStartHeightFlag,
+		utils.ReplayEndHeightFlag,
+		utils.ReplayPerturbClockFlag,
+		utils.ReplayReportFileFlag,
+		utils.DataDirFlag,
+		utils.ConfigFlag,
+		utils.NetworkIdFlag,
+	},
+	Description: "Execute each block of the range on the state before it, and compare the write set hash, the write set " +
+		"and the cross states root with the stored ones, reporting the first diverging key and transaction of each block. " +
+		"With --perturb-clock each block is executed again with a shifted clock to find handlers depending on the local time. " +
+		"The state before the start height must be archived, so the node must have run in archive mode. " +
+		"The node must be stopped before replaying.",
+}
+
+//replayReport is the result of replaying a block range, only the blocks diverged or depending on the clock are listed
+type replayReport struct {
+	StartHeight    uint32              `json:"startHeight"`
+	EndHeight      uint32              `json:"endHeight"`
+	PerturbClock   int                 `json:"perturbClock,omitempty"`
+	Diverged       int                 `json:"diverged"`
+	FirstDiverged  uint32              `json:"firstDiverged,omitempty"`
+	ClockDependent int                 `json:"clockDependent"`
+	Blocks         []*scom.BlockReplay `json:"blocks"`
+}
+
+func replayBlocks(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+	genesisBlock, bookKeepers, err := initSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+
+	report := &replayReport{
+		StartHeight:  uint32(ctx.Uint(utils.GetFlagName(utils.ReplayStartHeightFlag))),
+		EndHeight:    uint32(ctx.Uint(utils.GetFlagName(utils.ReplayEndHeightFlag))),
+		PerturbClock: ctx.Int(utils.GetFlagName(utils.ReplayPerturbClockFlag)),
+		Blocks:       make([]*scom.BlockReplay, 0),
+	}
+	currentHeight := ledger.DefLedger.GetCurrentBlockHeight()
+	if report.EndHeight == 0 || report.EndHeight > currentHeight {
+		report.EndHeight = currentHeight
+	}
+	if report.StartHeight == 0 {
+		report.StartHeight = 1
+	}
+	if report.StartHeight > report.EndHeight {
+		return fmt.Errorf("start height %d is higher than end height %d", report.StartHeight, report.EndHeight)
+	}
+
+	PrintInfoMsg("Start replay blocks from %d to %d.", report.StartHeight, report.EndHeight)
+	clockSkew := time.Duration(report.PerturbClock) * time.Second
+	for height := report.StartHeight; height <= report.EndHeight; height++ {
+		replay, err := ledger.DefLedger.ReplayBlock(height, clockSkew)
+		if err != nil {
+			return fmt.Errorf("replay block %d error:%s", height, err)
+		}
+		if replay.Diverged {
+			if report.Diverged == 0 {
+				report.FirstDiverged = height
+			}
+			report.Diverged++
+		}
+		if replay.ClockDependent {
+			report.ClockDependent++
+		}
+		if replay.Diverged || replay.ClockDependent {
+			report.Blocks = append(report.Blocks, replay)
+		}
+		if (height-report.StartHeight+1)%1000 == 0 {
+			PrintInfoMsg("Replayed blocks to %d, diverged:%d clock dependent:%d", height, report.Diverged, report.ClockDependent)
+		}
+	}
+
+	reportFile := ctx.String(utils.GetFlagName(utils.ReplayReportFileFlag))
+	if reportFile == "" {
+		PrintJsonObject(report)
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "   ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal error:%s", err)
+	}
+	err = ioutil.WriteFile(reportFile, data, 0664)
+	if err != nil {
+		return fmt.Errorf("write report file:%s error:%s", reportFile, err)
+	}
+	PrintInfoMsg("Replay blocks from %d to %d successfully.", report.StartHeight, report.EndHeight)
+	PrintInfoMsg("Diverged:%d ClockDependent:%d", report.Diverged, report.ClockDependent)
+	PrintInfoMsg("Report file:%s", reportFile)
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/polynetwork/poly/cmd/common"
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/consensus/vbft"
+	"github.com/polynetwork/poly/consensus/vbft/signer"
+	"github.com/urfave/cli"
+)
+
+var SignerCommand = cli.Command{
+	Action:    startSigner,
+	Name:      "signer",
+	Usage:     "Run a signer daemon holding the consensus key for a vbft node",
+	ArgsUsage: "",
+	Flags: []cli.Flag{
+		utils.WalletFileFlag,
+		utils.AccountAddressFlag,
+		utils.AccountPassFlag,
+		utils.SignerListenFlag,
+		utils.SignerStateFileFlag,
+		utils.SignerTLSCertFlag,
+		utils.SignerTLSKeyFlag,
+		utils.SignerTLSCAFlag,
+	},
+	Description: "The node started with --signer signs consensus messages and blocks through this daemon instead of the wallet. " +
+		"The daemon records the highest block it has signed of each step in the state file, and refuses to sign a different block " +
+		"of the same proposer at the same height or any block at a lower height, so restarting or duplicating the node can not " +
+		"make the key sign conflicting blocks. Listening on a TCP address requires --signer-tls-cert, --signer-tls-key and " +
+		"--signer-tls-ca, and the node must connect with a client certificate signed by the CA.",
+}
+
+//GetSignerTLSConfig returns the tls config of the signer connection set in flags, nil if no certificate is set
+func GetSignerTLSConfig(ctx *cli.Context, server bool) (*tls.Config, error) {
+	certFile := ctx.GlobalString(utils.GetFlagName(utils.SignerTLSCertFlag))
+	keyFile := ctx.GlobalString(utils.GetFlagName(utils.SignerTLSKeyFlag))
+	caFile := ctx.GlobalString(utils.GetFlagName(utils.SignerTLSCAFlag))
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, fmt.Errorf("--%s, --%s and --%s must be set together", utils.GetFlagName(utils.SignerTLSCertFlag),
+			utils.GetFlagName(utils.SignerTLSKeyFlag), utils.GetFlagName(utils.SignerTLSCAFlag))
+	}
+	return signer.LoadTLSConfig(certFile, keyFile, caFile, server)
+}
+
+func startSigner(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog, log.PATH, log.Stdout)
+	acc, err := common.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("get account error:%s", err)
+	}
+	guard, err := signer.OpenGuard(ctx.String(utils.GetFlagName(utils.SignerStateFileFlag)))
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := GetSignerTLSConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	address := ctx.String(utils.GetFlagName(utils.SignerListenFlag))
+	listener, err := signer.Listen(address, tlsConfig)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	log.Infof("signer of account %s listening on %s", acc.Address.ToBase58(), address)
+
+	exit := make(chan error, 1)
+	go func() { exit <- signer.Serve(listener, signer.NewService(vbft.NewLocalSigner(acc), guard)) }()
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	select {
+	case sig := <-sc:
+		log.Infof("signer received exit signal:%v.", sig.String())
+		return nil
+	case err := <-exit:
+		return fmt.Errorf("signer serve error: %s", err)
+	}
+}
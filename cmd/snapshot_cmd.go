@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/urfave/cli"
+)
+
+var SnapshotCommand = cli.Command{
+	Name:  "snapshot",
+	Usage: "Export or import ledger snapshot for fast node bootstrap",
+	Subcommands: []cli.Command{
+		{
+			Action:    exportSnapshot,
+			Name:      "export",
+			Usage:     "Export the snapshot of current block in DB to a file",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+			Description: "Export the block headers, the current block and the states of current block. The node must be stopped before exporting. " +
+				"The current block must be above the state root height of the network, from which the state root is committed by consensus.",
+		},
+		{
+			Action:    importSnapshot,
+			Name:      "import",
+			Usage:     "Import a snapshot to an empty DB",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.SnapshotHeaderFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+			Description: "The block headers of snapshot are verified from genesis block, and the states are checked against the state root committed in the header of next block. " +
+				"Blocks below the snapshot height only have headers after importing, like pruned blocks. " +
+				"Snapshots below the state root height of the network are refused, for their state root is not committed by consensus.",
+		},
+	},
+	Description: "Note that snapshot cmd doesn't support testmode",
+}
+
+//initSnapshotLedger open the ledger of the data dir and network set in flags, return the genesis block with the ledger
+func initSnapshotLedger(ctx *cli.Context) (*types.Block, []keypair.PublicKey, error) {
+	cfg := config.DefConfig
+	err := setGenesis(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("setGenesis error:%s", err)
+	}
+	cfg.P2PNode.NetworkId = uint32(ctx.Uint(utils.GetFlagName(utils.NetworkIdFlag)))
+	cfg.P2PNode.NetworkMagic = config.GetNetworkMagic(cfg.P2PNode.NetworkId)
+	cfg.P2PNode.NetworkName = config.GetNetworkName(cfg.P2PNode.NetworkId)
+	cfg.Common.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+
+	dbDir := utils.GetStoreDirPath(cfg.Common.DataDir, cfg.P2PNode.NetworkName)
+	ledger.DefLedger, err = ledger.NewLedger(dbDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewLedger error:%s", err)
+	}
+	bookKeepers, err := cfg.GetBookkeepers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, cfg.Genesis)
+	if err != nil {
+		return nil, nil, fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	return genesisBlock, bookKeepers, nil
+}
+
+func exportSnapshot(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	genesisBlock, bookKeepers, err := initSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+
+	sf, err := os.OpenFile(snapshotFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return fmt.Errorf("open file:%s error:%s", snapshotFile, err)
+	}
+	defer sf.Close()
+	fWriter := bufio.NewWriter(sf)
+	zWriter := zlib.NewWriter(fWriter)
+
+	PrintInfoMsg("Start export snapshot.")
+	info, err := ledger.DefLedger.ExportSnapshot(zWriter)
+	if err != nil {
+		return fmt.Errorf("ExportSnapshot error:%s", err)
+	}
+	err = zWriter.Close()
+	if err != nil {
+		return fmt.Errorf("zlib close error:%s", err)
+	}
+	err = fWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("export flush file error:%s", err)
+	}
+	PrintInfoMsg("Export snapshot successfully.")
+	PrintInfoMsg("BlockHeight:%d", info.Height)
+	PrintInfoMsg("BlockHash:%s", info.BlockHash.ToHexString())
+	PrintInfoMsg("StateRoot:%s", info.StateRoot.ToHexString())
+	PrintInfoMsg("Snapshot file:%s", snapshotFile)
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	var nextHeader *types.Header
+	if headerHex := ctx.String(utils.GetFlagName(utils.SnapshotHeaderFlag)); headerHex != "" {
+		raw, err := hex.DecodeString(headerHex)
+		if err != nil {
+			return fmt.Errorf("decode snapshot header error:%s", err)
+		}
+		nextHeader, err = types.HeaderFromRawBytes(raw)
+		if err != nil {
+			return fmt.Errorf("HeaderFromRawBytes error:%s", err)
+		}
+	}
+	sf, err := os.OpenFile(snapshotFile, os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile error:%s", err)
+	}
+	defer sf.Close()
+	zReader, err := zlib.NewReader(bufio.NewReader(sf))
+	if err != nil {
+		return fmt.Errorf("zlib.NewReader error:%s", err)
+	}
+	defer zReader.Close()
+
+	genesisBlock, bookKeepers, err := initSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+
+	PrintInfoMsg("Start import snapshot.")
+	info, err := ledger.DefLedger.ImportSnapshot(bufio.NewReader(zReader), genesisBlock, nextHeader)
+	if err != nil {
+		return fmt.Errorf("ImportSnapshot error:%s", err)
+	}
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+	PrintInfoMsg("Import snapshot completed, current block height:%d.", ledger.DefLedger.GetCurrentBlockHeight())
+	PrintInfoMsg("BlockHash:%s", info.BlockHash.ToHexString())
+	PrintInfoMsg("StateRoot:%s", info.StateRoot.ToHexString())
+	return nil
+}
@@ -100,7 +100,11 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.ConfigFlag,
 			utils.LogLevelFlag,
+			utils.LogFormatFlag,
+			utils.LogModulesFlag,
 			utils.DisableEventLogFlag,
+			utils.ArchiveFlag,
+			utils.PruneHeightFlag,
 			utils.DataDirFlag,
 		},
 	},
@@ -132,12 +136,14 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.EnableConsensusFlag,
 			utils.MaxTxInBlockFlag,
+			utils.SignerFlag,
 		},
 	},
 	{
 		Name: "TXPOOL",
 		Flags: []cli.Flag{
 			utils.TxpoolPreExecDisableFlag,
+			utils.GasPriceFlag,
 			utils.DisableBroadcastNetTxFlag,
 		},
 	},
@@ -163,6 +169,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCPortFlag,
 			utils.RPCLocalEnableFlag,
 			utils.RPCLocalProtFlag,
+			utils.EthRPCEnableFlag,
+			utils.EthRPCPortFlag,
 		},
 	},
 	{
@@ -180,6 +188,13 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WsPortFlag,
 		},
 	},
+	{
+		Name: "METRICS",
+		Flags: []cli.Flag{
+			utils.MetricsEnableFlag,
+			utils.MetricsPortFlag,
+		},
+	},
 	{
 		Name: "TEST MODE",
 		Flags: []cli.Flag{
@@ -245,6 +260,39 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ImportEndHeightFlag,
 		},
 	},
+	{
+		Name: "SNAPSHOT",
+		Flags: []cli.Flag{
+			utils.SnapshotFileFlag,
+			utils.SnapshotHeaderFlag,
+		},
+	},
+	{
+		Name: "HEADERS",
+		Flags: []cli.Flag{
+			utils.HeadersChainIDFlag,
+			utils.HeadersReportFileFlag,
+		},
+	},
+	{
+		Name: "REPLAY",
+		Flags: []cli.Flag{
+			utils.ReplayStartHeightFlag,
+			utils.ReplayEndHeightFlag,
+			utils.ReplayPerturbClockFlag,
+			utils.ReplayReportFileFlag,
+		},
+	},
+	{
+		Name: "SIGNER",
+		Flags: []cli.Flag{
+			utils.SignerListenFlag,
+			utils.SignerStateFileFlag,
+			utils.SignerTLSCertFlag,
+			utils.SignerTLSKeyFlag,
+			utils.SignerTLSCAFlag,
+		},
+	},
 	{
 		Name: "MISC",
 	},
@@ -27,6 +27,7 @@ import (
 
 const (
 	DEFAULT_EXPORT_FILE   = "./OntBlocks.dat"
+	DEFAULT_SNAPSHOT_FILE = "./PolySnapshot.dat"
 	DEFAULT_ABI_PATH      = "./abi"
 	DEFAULT_EXPORT_HEIGHT = 0
 	DEFAULT_WALLET_PATH   = "./wallet_data"
@@ -43,10 +44,27 @@ var (
 		Usage: "Set the log level to `<level>` (0~6). 0:Trace 1:Debug 2:Info 3:Warn 4:Error 5:Fatal 6:MaxLevel",
 		Value: config.DEFAULT_LOG_LEVEL,
 	}
+	LogFormatFlag = cli.StringFlag{
+		Name:  "log-format",
+		Usage: "Set the log format to `<format>`, text or json",
+		Value: "text",
+	}
+	LogModulesFlag = cli.StringFlag{
+		Name:  "log-modules",
+		Usage: "Override the log level of modules by `<levels>` like consensus/vbft=debug,p2pserver=warn",
+	}
 	DisableEventLogFlag = cli.BoolFlag{
 		Name:  "disable-event-log",
 		Usage: "Discard event log output by smart contract execution",
 	}
+	ArchiveFlag = cli.BoolFlag{
+		Name:  "archive",
+		Usage: "Keep the state history and the state trie of every block, to query storage, pre-execute transaction and prove storage at history height",
+	}
+	PruneHeightFlag = cli.UintFlag{
+		Name:  "prune-height",
+		Usage: "Prune the transactions and events of blocks below `<height>` at startup, block headers are kept. 0 means no pruning. Not supported with consensus enabled",
+	}
 	WalletFileFlag = cli.StringFlag{
 		Name:  "wallet,w",
 		Value: config.DEFAULT_WALLET_FILE_NAME,
@@ -78,6 +96,10 @@ var (
 		Usage: "Max transaction `<number>` in block",
 		Value: config.DEFAULT_MAX_TX_IN_BLOCK,
 	}
+	SignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "Sign vbft consensus messages with the signer daemon at `<address>`, unix:<path> or <host>:<port>, instead of the wallet",
+	}
 
 	//Test Mode setting
 	EnableTestModeFlag = cli.BoolFlag{
@@ -158,6 +180,15 @@ var (
 		Usage: "Json rpc local server listening port `<number>`",
 		Value: config.DEFAULT_RPC_LOCAL_PORT,
 	}
+	EthRPCEnableFlag = cli.BoolFlag{
+		Name:  "ethrpc",
+		Usage: "Enable ethereum compatible json rpc server",
+	}
+	EthRPCPortFlag = cli.UintFlag{
+		Name:  "ethrpcport",
+		Usage: "Ethereum compatible json rpc server listening port `<number>`",
+		Value: config.DEFAULT_ETH_RPC_PORT,
+	}
 
 	//Websocket setting
 	WsEnabledFlag = cli.BoolFlag{
@@ -186,6 +217,17 @@ var (
 		Value: config.DEFAULT_REST_MAX_CONN,
 	}
 
+	//Metrics setting
+	MetricsEnableFlag = cli.BoolFlag{
+		Name:  "metrics",
+		Usage: "Enable prometheus metrics server",
+	}
+	MetricsPortFlag = cli.UintFlag{
+		Name:  "metricsport",
+		Usage: "Prometheus metrics server listening port `<number>`",
+		Value: config.DEFAULT_METRICS_PORT,
+	}
+
 	//Account setting
 	AccountPassFlag = cli.StringFlag{
 		Name:   "password,p",
@@ -434,12 +476,82 @@ var (
 		Value: "m",
 	}
 
+	//Snapshot setting
+	SnapshotFileFlag = cli.StringFlag{
+		Name:  "snapshot-file",
+		Usage: "Path of snapshot `<file>`",
+		Value: DEFAULT_SNAPSHOT_FILE,
+	}
+	SnapshotHeaderFlag = cli.StringFlag{
+		Name:  "snapshot-header",
+		Usage: "Hex of the block `<header>` following the snapshot height, fetched from a trusted node. Required if the snapshot doesn't include it",
+	}
+
+	//Headers audit setting
+	HeadersChainIDFlag = cli.Uint64Flag{
+		Name:  "chain-id",
+		Usage: "Side chain `<id>` of the headers to audit",
+	}
+	HeadersReportFileFlag = cli.StringFlag{
+		Name:  "report-file",
+		Usage: "Write the JSON report to `<file>`, print to stdout if not set",
+	}
+
+	//Replay setting
+	ReplayStartHeightFlag = cli.UintFlag{
+		Name:  "start-height",
+		Usage: "Replay blocks from `<height>`",
+		Value: 1,
+	}
+	ReplayEndHeightFlag = cli.UintFlag{
+		Name:  "end-height",
+		Usage: "Replay blocks to `<height>`, current block height if not set",
+	}
+	ReplayPerturbClockFlag = cli.IntFlag{
+		Name:  "perturb-clock",
+		Usage: "Execute each block again with the clock of native contracts shifted by `<seconds>`, 0 disables",
+	}
+	ReplayReportFileFlag = cli.StringFlag{
+		Name:  "report-file",
+		Usage: "Write the JSON report to `<file>`, print to stdout if not set",
+	}
+
+	//Signer setting
+	SignerListenFlag = cli.StringFlag{
+		Name:  "listen",
+		Usage: "Signer listen `<address>`, unix:<path> or <host>:<port>. TCP address requires TLS",
+		Value: "unix:signer.sock",
+	}
+	SignerStateFileFlag = cli.StringFlag{
+		Name:  "state-file",
+		Usage: "`<file>` recording the highest signed blocks, signer refuses to sign a conflicting block of a lower or the same height",
+		Value: "signer_state.json",
+	}
+	SignerTLSCertFlag = cli.StringFlag{
+		Name:  "signer-tls-cert",
+		Usage: "TLS certificate `<file>` of the signer connection",
+	}
+	SignerTLSKeyFlag = cli.StringFlag{
+		Name:  "signer-tls-key",
+		Usage: "TLS private key `<file>` of the signer connection",
+	}
+	SignerTLSCAFlag = cli.StringFlag{
+		Name:  "signer-tls-ca",
+		Usage: "CA certificate `<file>` verifying the peer of the signer connection",
+	}
+
 	//PreExecute switcher
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disable-tx-pool-pre-exec",
 		Usage: "Disable preExecute in tx pool",
 	}
 
+	GasPriceFlag = cli.Uint64Flag{
+		Name:  "gasprice",
+		Usage: "Min gas price of transaction to be accepted by tx pool, consensus nodes should use the same price",
+		Value: 0,
+	}
+
 	DisableBroadcastNetTxFlag = cli.BoolFlag{
 		Name:  "disable-broadcast-net-tx",
 		Usage: "Disable broadcast tx from network in tx pool",
@@ -24,6 +24,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
@@ -52,6 +53,7 @@ const (
 	DEFAULT_CONSENSUS_PORT                  = uint(20339)
 	DEFAULT_RPC_PORT                        = uint(20336)
 	DEFAULT_RPC_LOCAL_PORT                  = uint(20337)
+	DEFAULT_ETH_RPC_PORT                    = uint(20333)
 	DEFAULT_REST_PORT                       = uint(20334)
 	DEFAULT_WS_PORT                         = uint(20335)
 	DEFAULT_REST_MAX_CONN                   = uint(1024)
@@ -59,6 +61,7 @@ const (
 	DEFAULT_MAX_CONN_OUT_BOUND              = uint(1024)
 	DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP = uint(16)
 	DEFAULT_HTTP_INFO_PORT                  = uint(0)
+	DEFAULT_METRICS_PORT                    = uint(20332)
 	DEFAULT_MAX_TX_IN_BLOCK                 = 60000
 	DEFAULT_MAX_SYNC_HEADER                 = 500
 	DEFAULT_ENABLE_CONSENSUS                = true
@@ -118,6 +121,56 @@ var HECO120_HEIGHT = map[uint32]uint64{
 	NETWORK_ID_TEST_NET: constants.HECO120_HEIGHT_TESTNET,
 }
 
+var STATE_ROOT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.STATE_ROOT_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.STATE_ROOT_HEIGHT_TESTNET,
+}
+
+var GAS_METERING_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.GAS_METERING_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.GAS_METERING_HEIGHT_TESTNET,
+}
+
+var RELAYER_INCENTIVE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.RELAYER_INCENTIVE_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.RELAYER_INCENTIVE_HEIGHT_TESTNET,
+}
+
+var RELAYER_SCOPE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.RELAYER_SCOPE_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.RELAYER_SCOPE_HEIGHT_TESTNET,
+}
+
+var CROSS_CHAIN_RATE_LIMIT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.CROSS_CHAIN_RATE_LIMIT_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.CROSS_CHAIN_RATE_LIMIT_HEIGHT_TESTNET,
+}
+
+var CROSS_CHAIN_DELAY_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.CROSS_CHAIN_DELAY_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.CROSS_CHAIN_DELAY_HEIGHT_TESTNET,
+}
+
+var HEADER_RETENTION_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.HEADER_RETENTION_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.HEADER_RETENTION_HEIGHT_TESTNET,
+}
+
+var GOVERNANCE_PROPOSAL_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.GOVERNANCE_PROPOSAL_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.GOVERNANCE_PROPOSAL_HEIGHT_TESTNET,
+}
+
+var EVIDENCE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.EVIDENCE_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.EVIDENCE_HEIGHT_TESTNET,
+}
+
+var STAKING_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.STAKING_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.STAKING_HEIGHT_TESTNET,
+}
+
 var POLYGON_SNAP_CHAINID = map[uint32]uint32{
 	NETWORK_ID_MAIN_NET: constants.POLYGON_SNAP_CHAINID_MAINNET,
 }
@@ -161,6 +214,68 @@ func GetHeco120Height(id uint32) uint64 {
 	return height
 }
 
+//GetStateRootHeight return the height from which blocks must commit the state trie root of previous block, other
+//networks require it from genesis
+func GetStateRootHeight(id uint32) uint32 {
+	return STATE_ROOT_HEIGHT[id]
+}
+
+//GetGasMeteringHeight return the height from which the gas limit of transaction is enforced and gas fee is charged,
+//other networks enforce it from the GasMeteringHeight of genesis config, which is off if not set
+func GetGasMeteringHeight(id uint32) uint32 {
+	height, ok := GAS_METERING_HEIGHT[id]
+	if ok {
+		return height
+	}
+	if DefConfig.Genesis == nil || DefConfig.Genesis.GasMeteringHeight == 0 {
+		return math.MaxUint32
+	}
+	return DefConfig.Genesis.GasMeteringHeight
+}
+
+//GetRelayerIncentiveHeight return the height from which relayers are rewarded, other networks reward from genesis
+func GetRelayerIncentiveHeight(id uint32) uint32 {
+	return RELAYER_INCENTIVE_HEIGHT[id]
+}
+
+//GetRelayerScopeHeight return the height from which the chain scope and quota of relayers are enforced, other networks enforce them from genesis
+func GetRelayerScopeHeight(id uint32) uint32 {
+	return RELAYER_SCOPE_HEIGHT[id]
+}
+
+//GetCrossChainRateLimitHeight return the height from which rate limits of chain pairs can be set, other networks allow them from genesis
+func GetCrossChainRateLimitHeight(id uint32) uint32 {
+	return CROSS_CHAIN_RATE_LIMIT_HEIGHT[id]
+}
+
+//GetCrossChainDelayHeight return the height from which cross chain txs can be delayed, other networks allow it from genesis
+func GetCrossChainDelayHeight(id uint32) uint32 {
+	return CROSS_CHAIN_DELAY_HEIGHT[id]
+}
+
+//GetHeaderRetentionHeight return the height from which side chain headers can be retained and pruned, other networks allow it from genesis
+func GetHeaderRetentionHeight(id uint32) uint32 {
+	return HEADER_RETENTION_HEIGHT[id]
+}
+
+//GetGovernanceProposalHeight return the height from which approve methods vote through governance proposals, other
+//networks use them from genesis
+func GetGovernanceProposalHeight(id uint32) uint32 {
+	return GOVERNANCE_PROPOSAL_HEIGHT[id]
+}
+
+//GetEvidenceHeight return the height from which equivocation evidence of consensus peers is accepted, other networks
+//accept it from genesis
+func GetEvidenceHeight(id uint32) uint32 {
+	return EVIDENCE_HEIGHT[id]
+}
+
+//GetStakingHeight return the height from which the staking methods of node_manager are available, other networks
+//have them from genesis
+func GetStakingHeight(id uint32) uint32 {
+	return STAKING_HEIGHT[id]
+}
+
 func GetExtraInfoHeight(id uint32) uint32 {
 	return EXTRA_INFO_HEIGHT[id]
 }
@@ -284,11 +399,12 @@ var MainNetConfig = &GenesisConfig{
 var DefConfig = NewOntologyConfig()
 
 type GenesisConfig struct {
-	SeedList      []string
-	ConsensusType string
-	VBFT          *VBFTConfig
-	DBFT          *DBFTConfig
-	SOLO          *SOLOConfig
+	SeedList          []string
+	ConsensusType     string
+	VBFT              *VBFTConfig
+	DBFT              *DBFTConfig
+	SOLO              *SOLOConfig
+	GasMeteringHeight uint32 //gas metering height of networks other than mainnet and testnet, 0 means off
 }
 
 func NewGenesisConfig() *GenesisConfig {
@@ -430,6 +546,8 @@ type CommonConfig struct {
 	LogLevel       uint
 	NodeType       string
 	EnableEventLog bool
+	EnableArchive  bool
+	PruneHeight    uint32
 	SystemFee      map[string]int64
 	GasLimit       uint64
 	GasPrice       uint64
@@ -470,6 +588,8 @@ type RpcConfig struct {
 	EnableHttpJsonRpc bool
 	HttpJsonPort      uint
 	HttpLocalPort     uint
+	EnableEthRpc      bool
+	HttpEthPort       uint
 }
 
 type RestfulConfig struct {
@@ -487,6 +607,11 @@ type WebSocketConfig struct {
 	HttpKeyPath  string
 }
 
+type MetricsConfig struct {
+	EnableMetrics   bool
+	HttpMetricsPort uint
+}
+
 type OntologyConfig struct {
 	Genesis   *GenesisConfig
 	Common    *CommonConfig
@@ -495,6 +620,7 @@ type OntologyConfig struct {
 	Rpc       *RpcConfig
 	Restful   *RestfulConfig
 	Ws        *WebSocketConfig
+	Metrics   *MetricsConfig
 }
 
 func NewOntologyConfig() *OntologyConfig {
@@ -534,6 +660,7 @@ func NewOntologyConfig() *OntologyConfig {
 			EnableHttpJsonRpc: true,
 			HttpJsonPort:      DEFAULT_RPC_PORT,
 			HttpLocalPort:     DEFAULT_RPC_LOCAL_PORT,
+			HttpEthPort:       DEFAULT_ETH_RPC_PORT,
 		},
 		Restful: &RestfulConfig{
 			EnableHttpRestful: true,
@@ -543,6 +670,9 @@ func NewOntologyConfig() *OntologyConfig {
 			EnableHttpWs: true,
 			HttpWsPort:   DEFAULT_WS_PORT,
 		},
+		Metrics: &MetricsConfig{
+			HttpMetricsPort: DEFAULT_METRICS_PORT,
+		},
 	}
 }
 
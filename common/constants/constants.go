@@ -19,6 +19,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -56,3 +57,43 @@ const POLYGON_SNAP_CHAINID_MAINNET = 16
 
 // eth arrow glacier upgrade
 const ETH4345_HEIGHT_MAINNET = 13_773_000
+
+// state trie root required in vbft block info, not scheduled on mainnet and testnet yet
+const STATE_ROOT_HEIGHT_MAINNET = math.MaxUint32
+const STATE_ROOT_HEIGHT_TESTNET = math.MaxUint32
+
+// gas metering of native contract, not scheduled on mainnet and testnet yet
+const GAS_METERING_HEIGHT_MAINNET = math.MaxUint32
+const GAS_METERING_HEIGHT_TESTNET = math.MaxUint32
+
+// relayer incentive, not scheduled on mainnet and testnet yet
+const RELAYER_INCENTIVE_HEIGHT_MAINNET = math.MaxUint32
+const RELAYER_INCENTIVE_HEIGHT_TESTNET = math.MaxUint32
+
+// per chain relayer scope and quota, not scheduled on mainnet and testnet yet
+const RELAYER_SCOPE_HEIGHT_MAINNET = math.MaxUint32
+const RELAYER_SCOPE_HEIGHT_TESTNET = math.MaxUint32
+
+// cross chain rate limit of chain pairs, not scheduled on mainnet and testnet yet
+const CROSS_CHAIN_RATE_LIMIT_HEIGHT_MAINNET = math.MaxUint32
+const CROSS_CHAIN_RATE_LIMIT_HEIGHT_TESTNET = math.MaxUint32
+
+// delayed cross chain txs of target contracts, not scheduled on mainnet and testnet yet
+const CROSS_CHAIN_DELAY_HEIGHT_MAINNET = math.MaxUint32
+const CROSS_CHAIN_DELAY_HEIGHT_TESTNET = math.MaxUint32
+
+// retention and pruning of side chain headers, not scheduled on mainnet and testnet yet
+const HEADER_RETENTION_HEIGHT_MAINNET = math.MaxUint32
+const HEADER_RETENTION_HEIGHT_TESTNET = math.MaxUint32
+
+// on-chain governance proposals replacing consensus signs of approve methods, not scheduled on mainnet and testnet yet
+const GOVERNANCE_PROPOSAL_HEIGHT_MAINNET = math.MaxUint32
+const GOVERNANCE_PROPOSAL_HEIGHT_TESTNET = math.MaxUint32
+
+// equivocation evidence of vbft consensus msgs blacking offenders, not scheduled on mainnet and testnet yet
+const EVIDENCE_HEIGHT_MAINNET = math.MaxUint32
+const EVIDENCE_HEIGHT_TESTNET = math.MaxUint32
+
+// staking mode of node_manager electing consensus peers by stake, not scheduled on mainnet and testnet yet
+const STAKING_HEIGHT_MAINNET = math.MaxUint32
+const STAKING_HEIGHT_TESTNET = math.MaxUint32
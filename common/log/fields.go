@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const JSON_TIME_FORMAT = "2006-01-02T15:04:05.000000Z07:00"
+
+var jsonFormat int32
+
+//Fields are the structured fields of a log line, like chainID, txHash and height
+type Fields map[string]interface{}
+
+//Entry is a logger with fields added to every line
+type Entry struct {
+	fields Fields
+}
+
+//WithFields return an entry logging with fields. Hash values should be passed as hex strings.
+func WithFields(fields Fields) *Entry {
+	return &Entry{fields: fields}
+}
+
+//WithFields return an entry logging with the fields of entry and fields
+func (e *Entry) WithFields(fields Fields) *Entry {
+	all := make(Fields, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		all[k] = v
+	}
+	for k, v := range fields {
+		all[k] = v
+	}
+	return &Entry{fields: all}
+}
+
+func (e *Entry) Debug(a ...interface{}) {
+	Log.logln(DebugLog, callerFull, e.fields, a...)
+}
+
+func (e *Entry) Debugf(format string, a ...interface{}) {
+	Log.logf(DebugLog, callerFull, e.fields, format, a...)
+}
+
+func (e *Entry) Info(a ...interface{}) {
+	Log.logln(InfoLog, callerNone, e.fields, a...)
+}
+
+func (e *Entry) Infof(format string, a ...interface{}) {
+	Log.logf(InfoLog, callerNone, e.fields, format, a...)
+}
+
+func (e *Entry) Warn(a ...interface{}) {
+	Log.logln(WarnLog, callerNone, e.fields, a...)
+}
+
+func (e *Entry) Warnf(format string, a ...interface{}) {
+	Log.logf(WarnLog, callerNone, e.fields, format, a...)
+}
+
+func (e *Entry) Error(a ...interface{}) {
+	Log.logln(ErrorLog, callerNone, e.fields, a...)
+}
+
+func (e *Entry) Errorf(format string, a ...interface{}) {
+	Log.logf(ErrorLog, callerNone, e.fields, format, a...)
+}
+
+//SetJSONFormat switch the output of log lines between JSON objects and text
+func SetJSONFormat(enable bool) {
+	if enable {
+		atomic.StoreInt32(&jsonFormat, 1)
+	} else {
+		atomic.StoreInt32(&jsonFormat, 0)
+	}
+}
+
+//JSONFormat return whether log lines are output as JSON objects
+func JSONFormat() bool {
+	return atomic.LoadInt32(&jsonFormat) != 0
+}
+
+//jsonLine return a log line as JSON object, fields never overwrite the standard keys
+func jsonLine(level int, gid uint64, c *caller, callerMode int, fields Fields, msg string) string {
+	line := make(map[string]interface{}, len(fields)+7)
+	for k, v := range fields {
+		line[k] = jsonValue(v)
+	}
+	line["time"] = time.Now().Format(JSON_TIME_FORMAT)
+	line["level"] = LevelText(level)
+	line["module"] = c.module
+	line["gid"] = gid
+	line["msg"] = msg
+	if callerMode != callerNone {
+		line["func"] = c.function
+		line["caller"] = c.file + ":" + strconv.Itoa(c.line)
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		data, _ = json.Marshal(map[string]interface{}{
+			"time":   line["time"],
+			"level":  line["level"],
+			"module": c.module,
+			"gid":    gid,
+			"msg":    fmt.Sprintf("%s, marshal fields error: %s", msg, err),
+		})
+	}
+	return string(data)
+}
+
+func jsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return value
+	case error:
+		return value.Error()
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func writeTextFields(buf *bytes.Buffer, fields Fields) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, " %s=%v", k, fields[k])
+	}
+}
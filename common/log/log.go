@@ -26,7 +26,6 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -115,6 +114,7 @@ func NameLevel(name string) int {
 type Logger struct {
 	level   int
 	logger  *log.Logger
+	json    *log.Logger
 	logFile *os.File
 }
 
@@ -122,6 +122,7 @@ func New(out io.Writer, prefix string, flag, level int, file *os.File) *Logger {
 	return &Logger{
 		level:   level,
 		logger:  log.New(out, prefix, flag),
+		json:    log.New(out, "", 0),
 		logFile: file,
 	}
 }
@@ -135,180 +136,169 @@ func (l *Logger) SetDebugLevel(level int) error {
 	return nil
 }
 
-func (l *Logger) Output(level int, a ...interface{}) error {
-	if level >= l.level {
-		gid := GetGID()
-		gidStr := strconv.FormatUint(gid, 10)
+//GetDebugLevel return the level of modules without level override
+func (l *Logger) GetDebugLevel() int {
+	return l.level
+}
 
-		a = append([]interface{}{LevelName(level), "GID",
-			gidStr + ","}, a...)
+//enabled return whether level is enabled for the module of the caller, and the caller if it is needed.
+//It is called by logln and logf, the function logging is 4 stack frames above getCaller.
+func (l *Logger) enabled(level, callerMode int) (*caller, bool) {
+	if level < minLevel(l.level) {
+		return nil, false
+	}
+	var c *caller
+	if callerMode != callerNone || hasModuleLevels() || JSONFormat() {
+		c = getCaller(4)
+		if level < moduleLevel(c.module, l.level) {
+			return nil, false
+		}
+	}
+	return c, true
+}
 
-		return l.logger.Output(CALL_DEPTH, fmt.Sprintln(a...))
+//logln write a log line of operands formatted like fmt.Sprintln without the newline, if level is enabled
+func (l *Logger) logln(level, callerMode int, fields Fields, a ...interface{}) error {
+	c, ok := l.enabled(level, callerMode)
+	if !ok {
+		return nil
 	}
-	return nil
+	return l.write(level, callerMode, c, fields, strings.TrimSuffix(fmt.Sprintln(a...), "\n"))
 }
 
-func (l *Logger) Outputf(level int, format string, v ...interface{}) error {
-	if level >= l.level {
-		gid := GetGID()
-		v = append([]interface{}{LevelName(level), "GID",
-			gid}, v...)
+//logf write a log line formatted like fmt.Sprintf, if level is enabled
+func (l *Logger) logf(level, callerMode int, fields Fields, format string, a ...interface{}) error {
+	c, ok := l.enabled(level, callerMode)
+	if !ok {
+		return nil
+	}
+	return l.write(level, callerMode, c, fields, fmt.Sprintf(format, a...))
+}
 
-		return l.logger.Output(CALL_DEPTH, fmt.Sprintf("%s %s %d, "+format+"\n", v...))
+func (l *Logger) write(level, callerMode int, c *caller, fields Fields, msg string) error {
+	gid := GetGID()
+	if JSONFormat() {
+		return l.json.Output(CALL_DEPTH, jsonLine(level, gid, c, callerMode, fields, msg))
 	}
-	return nil
+
+	buf := bytes.NewBufferString(LevelName(level))
+	buf.WriteString(" GID ")
+	buf.WriteString(strconv.FormatUint(gid, 10))
+	buf.WriteString(", ")
+	switch callerMode {
+	case callerFull:
+		fmt.Fprintf(buf, "%s %s:%d ", c.function, c.file, c.line)
+	case callerShort:
+		fmt.Fprintf(buf, "%s() %s:%d ", c.shortFunction(), c.file, c.line)
+	}
+	buf.WriteString(msg)
+	writeTextFields(buf, fields)
+	buf.WriteByte('\n')
+	return l.logger.Output(CALL_DEPTH, buf.String())
+}
+
+func (l *Logger) Output(level int, a ...interface{}) error {
+	return l.logln(level, callerNone, nil, a...)
+}
+
+func (l *Logger) Outputf(level int, format string, v ...interface{}) error {
+	return l.logf(level, callerNone, nil, format, v...)
 }
 
 func (l *Logger) Trace(a ...interface{}) {
-	l.Output(TraceLog, a...)
+	l.logln(TraceLog, callerNone, nil, a...)
 }
 
 func (l *Logger) Tracef(format string, a ...interface{}) {
-	l.Outputf(TraceLog, format, a...)
+	l.logf(TraceLog, callerNone, nil, format, a...)
 }
 
 func (l *Logger) Debug(a ...interface{}) {
-	l.Output(DebugLog, a...)
+	l.logln(DebugLog, callerNone, nil, a...)
 }
 
 func (l *Logger) Debugf(format string, a ...interface{}) {
-	l.Outputf(DebugLog, format, a...)
+	l.logf(DebugLog, callerNone, nil, format, a...)
 }
 
 func (l *Logger) Info(a ...interface{}) {
-	l.Output(InfoLog, a...)
+	l.logln(InfoLog, callerNone, nil, a...)
 }
 
 func (l *Logger) Infof(format string, a ...interface{}) {
-	l.Outputf(InfoLog, format, a...)
+	l.logf(InfoLog, callerNone, nil, format, a...)
 }
 
 func (l *Logger) Warn(a ...interface{}) {
-	l.Output(WarnLog, a...)
+	l.logln(WarnLog, callerNone, nil, a...)
 }
 
 func (l *Logger) Warnf(format string, a ...interface{}) {
-	l.Outputf(WarnLog, format, a...)
+	l.logf(WarnLog, callerNone, nil, format, a...)
 }
 
 func (l *Logger) Error(a ...interface{}) {
-	l.Output(ErrorLog, a...)
+	l.logln(ErrorLog, callerNone, nil, a...)
 }
 
 func (l *Logger) Errorf(format string, a ...interface{}) {
-	l.Outputf(ErrorLog, format, a...)
+	l.logf(ErrorLog, callerNone, nil, format, a...)
 }
 
 func (l *Logger) Fatal(a ...interface{}) {
-	l.Output(FatalLog, a...)
+	l.logln(FatalLog, callerNone, nil, a...)
 }
 
 func (l *Logger) Fatalf(format string, a ...interface{}) {
-	l.Outputf(FatalLog, format, a...)
+	l.logf(FatalLog, callerNone, nil, format, a...)
 }
 
 func Trace(a ...interface{}) {
-	if TraceLog < Log.level {
-		return
-	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	nameFull := f.Name()
-	nameEnd := filepath.Ext(nameFull)
-	funcName := strings.TrimPrefix(nameEnd, ".")
-
-	a = append([]interface{}{funcName + "()", fileName + ":" + strconv.Itoa(line)}, a...)
-
-	Log.Trace(a...)
+	Log.logln(TraceLog, callerShort, nil, a...)
 }
 
 func Tracef(format string, a ...interface{}) {
-	if TraceLog < Log.level {
-		return
-	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	nameFull := f.Name()
-	nameEnd := filepath.Ext(nameFull)
-	funcName := strings.TrimPrefix(nameEnd, ".")
-
-	a = append([]interface{}{funcName, fileName, line}, a...)
-
-	Log.Tracef("%s() %s:%d "+format, a...)
+	Log.logf(TraceLog, callerShort, nil, format, a...)
 }
 
 func Debug(a ...interface{}) {
-	if DebugLog < Log.level {
-		return
-	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	a = append([]interface{}{f.Name(), fileName + ":" + strconv.Itoa(line)}, a...)
-
-	Log.Debug(a...)
+	Log.logln(DebugLog, callerFull, nil, a...)
 }
 
 func Debugf(format string, a ...interface{}) {
-	if DebugLog < Log.level {
-		return
-	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	a = append([]interface{}{f.Name(), fileName, line}, a...)
-
-	Log.Debugf("%s %s:%d "+format, a...)
+	Log.logf(DebugLog, callerFull, nil, format, a...)
 }
 
 func Info(a ...interface{}) {
-	Log.Info(a...)
+	Log.logln(InfoLog, callerNone, nil, a...)
 }
 
 func Warn(a ...interface{}) {
-	Log.Warn(a...)
+	Log.logln(WarnLog, callerNone, nil, a...)
 }
 
 func Error(a ...interface{}) {
-	Log.Error(a...)
+	Log.logln(ErrorLog, callerNone, nil, a...)
 }
 
 func Fatal(a ...interface{}) {
-	Log.Fatal(a...)
+	Log.logln(FatalLog, callerNone, nil, a...)
 }
 
 func Infof(format string, a ...interface{}) {
-	Log.Infof(format, a...)
+	Log.logf(InfoLog, callerNone, nil, format, a...)
 }
 
 func Warnf(format string, a ...interface{}) {
-	Log.Warnf(format, a...)
+	Log.logf(WarnLog, callerNone, nil, format, a...)
 }
 
 func Errorf(format string, a ...interface{}) {
-	Log.Errorf(format, a...)
+	Log.logf(ErrorLog, callerNone, nil, format, a...)
 }
 
 func Fatalf(format string, a ...interface{}) {
-	Log.Fatalf(format, a...)
+	Log.logf(FatalLog, callerNone, nil, format, a...)
 }
 
 func FileOpen(path string) (*os.File, error) {
@@ -18,10 +18,13 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -83,3 +86,103 @@ func TestNewLogFile(t *testing.T) {
 	}
 	assert.Equal(t, len(logfileNum1), (len(logfileNum2) - 1))
 }
+
+func TestModuleOf(t *testing.T) {
+	assert.Equal(t, "consensus/vbft", ModuleOf("github.com/polynetwork/poly/consensus/vbft.(*Server).run"))
+	assert.Equal(t, "common/log", ModuleOf("github.com/polynetwork/poly/common/log.TestModuleOf.func1"))
+	assert.Equal(t, "main", ModuleOf("main.main"))
+	assert.Equal(t, "github.com/ontio/ontology-eventbus/actor", ModuleOf("github.com/ontio/ontology-eventbus/actor.spawn"))
+}
+
+func TestSetLevels(t *testing.T) {
+	defer func() {
+		InitLog(InfoLog, Stdout)
+		assert.Nil(t, SetLevels("consensus=default,common=default,common/log=default"))
+	}()
+	buf := bytes.NewBuffer(nil)
+	Log = New(buf, "", 0, InfoLog, nil)
+
+	assert.NotNil(t, SetLevels("consensus/vbft"))
+	assert.NotNil(t, SetLevels("consensus/vbft=verbose"))
+	assert.NotNil(t, SetLevels("consensus=debug,=info"))
+	assert.Equal(t, map[string]string{GLOBAL_MODULE: "info"}, GetLevels())
+
+	assert.Nil(t, SetLevels("consensus=debug, common/log=warn,*=error"))
+	assert.Equal(t, "*=error,common/log=warn,consensus=debug", FormatLevels())
+	assert.Equal(t, DebugLog, moduleLevel("consensus/vbft", Log.GetDebugLevel()))
+	assert.Equal(t, ErrorLog, moduleLevel("p2pserver", Log.GetDebugLevel()))
+	Info("info")
+	Warn("warn")
+	Debugf("debug %d", 1)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 1, len(lines))
+	assert.True(t, strings.HasSuffix(lines[0], ", warn"))
+
+	// a parent module level is overridden by the sub module
+	buf.Reset()
+	assert.Nil(t, SetLevels("common=debug,common/log=default"))
+	Debugf("debug %d", 1)
+	assert.Contains(t, buf.String(), "log_test.go")
+	assert.Contains(t, buf.String(), "debug 1")
+}
+
+func TestJSONFormat(t *testing.T) {
+	defer func() {
+		InitLog(InfoLog, Stdout)
+		SetJSONFormat(false)
+	}()
+	buf := bytes.NewBuffer(nil)
+	Log = New(buf, "", 0, DebugLog, nil)
+
+	WithFields(Fields{"chainID": 2, "height": uint32(100), "msg": "ignored"}).Infof("header %s synced", "abc")
+	WithFields(Fields{"txHash": "aa"}).Info("text")
+	assert.True(t, strings.HasPrefix(buf.String(), LevelName(InfoLog)+" GID "))
+	assert.Contains(t, buf.String(), ", header abc synced chainID=2 height=100 msg=ignored\n")
+	assert.Contains(t, buf.String(), ", text txHash=aa\n")
+
+	buf.Reset()
+	SetJSONFormat(true)
+	WithFields(Fields{"chainID": 2, "height": uint32(100), "msg": "ignored", "err": fmt.Errorf("failed")}).Infof("header %s synced", "abc")
+	Debug("debug")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	line := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "common/log", line["module"])
+	assert.Equal(t, "header abc synced", line["msg"])
+	assert.Equal(t, float64(2), line["chainID"])
+	assert.Equal(t, float64(100), line["height"])
+	assert.Equal(t, "failed", line["err"])
+	assert.Nil(t, line["caller"])
+	line = make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &line))
+	assert.Equal(t, "debug", line["level"])
+	assert.True(t, strings.HasPrefix(line["caller"].(string), "log_test.go:"))
+}
+
+type countStringer int
+
+func (c *countStringer) String() string {
+	*c++
+	return "counted"
+}
+
+func TestDisabledLevelNotFormatted(t *testing.T) {
+	defer func() {
+		InitLog(InfoLog, Stdout)
+	}()
+	buf := bytes.NewBuffer(nil)
+	Log = New(buf, "", 0, WarnLog, nil)
+
+	count := new(countStringer)
+	Info(count)
+	Infof("%s", count)
+	WithFields(Fields{"height": 1}).Debugf("%s", count)
+	assert.Equal(t, 0, int(*count))
+	assert.Equal(t, 0, buf.Len())
+
+	Warnf("%s", count)
+	assert.Equal(t, 1, int(*count))
+	assert.Contains(t, buf.String(), ", counted\n")
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	MODULE_ROOT   = "github.com/polynetwork/poly/" //Package path prefix trimmed from module names
+	DEFAULT_LEVEL = "default"                      //Level name removing the level override of a module
+	GLOBAL_MODULE = "*"                            //Module name of the level of modules without override
+)
+
+const (
+	callerNone = iota
+	callerFull
+	callerShort
+)
+
+var levelNames = map[int]string{
+	TraceLog: "trace",
+	DebugLog: "debug",
+	InfoLog:  "info",
+	WarnLog:  "warn",
+	ErrorLog: "error",
+	FatalLog: "fatal",
+}
+
+var (
+	moduleLock   sync.RWMutex
+	moduleLevels = make(map[string]int)
+	//lowest level of module overrides, MaxLevelLog if there is no override
+	moduleMin   int32 = MaxLevelLog
+	moduleCount int32
+	callerFunc  sync.Map //pc => *callerFunction
+)
+
+//caller is the function which writes a log line
+type caller struct {
+	*callerFunction
+	file string
+	line int
+}
+
+type callerFunction struct {
+	function string
+	module   string
+}
+
+func (c *caller) shortFunction() string {
+	return strings.TrimPrefix(filepath.Ext(c.function), ".")
+}
+
+func getCaller(skip int) *caller {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return &caller{callerFunction: &callerFunction{}, file: "???"}
+	}
+	c := &caller{file: filepath.Base(file), line: line}
+	if f, ok := callerFunc.Load(pc); ok {
+		c.callerFunction = f.(*callerFunction)
+		return c
+	}
+	f := &callerFunction{}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		f.function = fn.Name()
+		f.module = ModuleOf(f.function)
+	}
+	callerFunc.Store(pc, f)
+	c.callerFunction = f
+	return c
+}
+
+//ModuleOf return the module of a function name, which is the package path without MODULE_ROOT,
+//e.g. consensus/vbft for github.com/polynetwork/poly/consensus/vbft.(*Server).run
+func ModuleOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	pkg := function
+	if dot >= 0 {
+		pkg = function[:slash+1+dot]
+	}
+	return strings.TrimPrefix(pkg, MODULE_ROOT)
+}
+
+//ParseLevel return the level of a name like debug, or of a number like 1
+func ParseLevel(name string) (int, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for level, levelName := range levelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	level, err := strconv.Atoi(name)
+	if err != nil || level < 0 || level > MaxLevelLog {
+		return 0, fmt.Errorf("invalid log level %s", name)
+	}
+	return level, nil
+}
+
+//LevelText return the lower case name of level
+func LevelText(level int) string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return strconv.Itoa(level)
+}
+
+//SetLevels update the log levels by a spec like consensus/vbft=debug,p2pserver=warn. The level of a module
+//applies to its sub packages without their own level. Level default removes the level of a module, and
+//module * sets the level of modules without level. The spec is applied only if it is entirely valid.
+func SetLevels(spec string) error {
+	levels := make(map[string]int)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.Split(item, "=")
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid module level %s, module=level expected", item)
+		}
+		module := strings.Trim(strings.TrimSpace(kv[0]), "/")
+		if module == "" {
+			return fmt.Errorf("invalid module level %s, module is empty", item)
+		}
+		if strings.TrimSpace(kv[1]) == DEFAULT_LEVEL && module != GLOBAL_MODULE {
+			levels[module] = -1
+			continue
+		}
+		level, err := ParseLevel(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid module level %s, %s", item, err)
+		}
+		levels[module] = level
+	}
+
+	moduleLock.Lock()
+	defer moduleLock.Unlock()
+	for module, level := range levels {
+		if module == GLOBAL_MODULE {
+			Log.SetDebugLevel(level)
+		} else if level < 0 {
+			delete(moduleLevels, module)
+		} else {
+			moduleLevels[module] = level
+		}
+	}
+	min := MaxLevelLog
+	for _, level := range moduleLevels {
+		if level < min {
+			min = level
+		}
+	}
+	atomic.StoreInt32(&moduleMin, int32(min))
+	atomic.StoreInt32(&moduleCount, int32(len(moduleLevels)))
+	return nil
+}
+
+//GetLevels return the level names of modules with level, and the level of other modules as module *
+func GetLevels() map[string]string {
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	levels := make(map[string]string, len(moduleLevels)+1)
+	for module, level := range moduleLevels {
+		levels[module] = LevelText(level)
+	}
+	levels[GLOBAL_MODULE] = LevelText(Log.GetDebugLevel())
+	return levels
+}
+
+//FormatLevels return the levels of GetLevels as a spec accepted by SetLevels
+func FormatLevels() string {
+	levels := GetLevels()
+	modules := make([]string, 0, len(levels))
+	for module := range levels {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	items := make([]string, 0, len(modules))
+	for _, module := range modules {
+		items = append(items, module+"="+levels[module])
+	}
+	return strings.Join(items, ",")
+}
+
+func hasModuleLevels() bool {
+	return atomic.LoadInt32(&moduleCount) != 0
+}
+
+func minLevel(level int) int {
+	if min := int(atomic.LoadInt32(&moduleMin)); min < level {
+		return min
+	}
+	return level
+}
+
+//moduleLevel return the level of the longest module with level which is module or a parent of module
+func moduleLevel(module string, level int) int {
+	if !hasModuleLevels() {
+		return level
+	}
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	for {
+		if l, ok := moduleLevels[module]; ok {
+			return l
+		}
+		i := strings.LastIndex(module, "/")
+		if i < 0 {
+			return level
+		}
+		module = module[:i]
+	}
+}
@@ -0,0 +1,383 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package metrics provides counters and gauges exposed in the prometheus text format
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//Type is the type of a metric family
+type Type string
+
+const (
+	COUNTER Type = "counter"
+	GAUGE   Type = "gauge"
+)
+
+//Sample is a value of a metric family with its label values
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+//Metric is a family of samples sharing name, help and label names
+type Metric interface {
+	Desc() *Desc
+	Collect() []Sample
+}
+
+//Desc describes a metric family
+type Desc struct {
+	Name   string
+	Help   string
+	Type   Type
+	Labels []string
+}
+
+func newDesc(typ Type, name, help string, labels []string) *Desc {
+	return &Desc{Name: name, Help: help, Type: typ, Labels: labels}
+}
+
+//Counter is a monotonically increasing value
+type Counter struct {
+	desc  *Desc
+	value uint64
+}
+
+//NewCounter returns a counter without labels
+func NewCounter(name, help string) *Counter {
+	return &Counter{desc: newDesc(COUNTER, name, help, nil)}
+}
+
+//Inc increases the counter by 1
+func (this *Counter) Inc() {
+	atomic.AddUint64(&this.value, 1)
+}
+
+//Add increases the counter by delta
+func (this *Counter) Add(delta uint64) {
+	atomic.AddUint64(&this.value, delta)
+}
+
+//Value returns the current value of the counter
+func (this *Counter) Value() uint64 {
+	return atomic.LoadUint64(&this.value)
+}
+
+func (this *Counter) Desc() *Desc {
+	return this.desc
+}
+
+func (this *Counter) Collect() []Sample {
+	return []Sample{{Value: float64(this.Value())}}
+}
+
+//Gauge is a value that can go up and down
+type Gauge struct {
+	desc *Desc
+	bits uint64
+}
+
+//NewGauge returns a gauge without labels
+func NewGauge(name, help string) *Gauge {
+	return &Gauge{desc: newDesc(GAUGE, name, help, nil)}
+}
+
+//Set sets the gauge to v
+func (this *Gauge) Set(v float64) {
+	atomic.StoreUint64(&this.bits, math.Float64bits(v))
+}
+
+//Add adds delta to the gauge, delta may be negative
+func (this *Gauge) Add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&this.bits)
+		v := math.Float64frombits(old) + delta
+		if atomic.CompareAndSwapUint64(&this.bits, old, math.Float64bits(v)) {
+			return
+		}
+	}
+}
+
+//Inc increases the gauge by 1
+func (this *Gauge) Inc() {
+	this.Add(1)
+}
+
+//Dec decreases the gauge by 1
+func (this *Gauge) Dec() {
+	this.Add(-1)
+}
+
+//Value returns the current value of the gauge
+func (this *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&this.bits))
+}
+
+func (this *Gauge) Desc() *Desc {
+	return this.desc
+}
+
+func (this *Gauge) Collect() []Sample {
+	return []Sample{{Value: this.Value()}}
+}
+
+//vec keeps the children of a labeled metric family
+type vec struct {
+	desc     *Desc
+	lock     sync.RWMutex
+	children map[string]*child
+}
+
+type child struct {
+	labelValues []string
+	metric      interface{ Collect() []Sample }
+}
+
+func (this *vec) get(labelValues []string, newMetric func() interface{ Collect() []Sample }) interface{} {
+	if len(labelValues) != len(this.desc.Labels) {
+		panic(fmt.Errorf("metric %s expects %d label values, got %d", this.desc.Name, len(this.desc.Labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	this.lock.RLock()
+	c, ok := this.children[key]
+	this.lock.RUnlock()
+	if ok {
+		return c.metric
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if c, ok := this.children[key]; ok {
+		return c.metric
+	}
+	c = &child{labelValues: append([]string{}, labelValues...), metric: newMetric()}
+	this.children[key] = c
+	return c.metric
+}
+
+func (this *vec) Desc() *Desc {
+	return this.desc
+}
+
+func (this *vec) Collect() []Sample {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	samples := make([]Sample, 0, len(this.children))
+	for _, c := range this.children {
+		for _, s := range c.metric.Collect() {
+			samples = append(samples, Sample{LabelValues: c.labelValues, Value: s.Value})
+		}
+	}
+	return samples
+}
+
+//CounterVec is a counter family partitioned by label values
+type CounterVec struct {
+	vec
+}
+
+//NewCounterVec returns a counter family with the label names
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{vec{desc: newDesc(COUNTER, name, help, labels), children: make(map[string]*child)}}
+}
+
+//WithLabelValues returns the counter of the label values, creating it if absent
+func (this *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return this.get(labelValues, func() interface{ Collect() []Sample } {
+		return &Counter{desc: this.desc}
+	}).(*Counter)
+}
+
+//GaugeVec is a gauge family partitioned by label values
+type GaugeVec struct {
+	vec
+}
+
+//NewGaugeVec returns a gauge family with the label names
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{vec{desc: newDesc(GAUGE, name, help, labels), children: make(map[string]*child)}}
+}
+
+//WithLabelValues returns the gauge of the label values, creating it if absent
+func (this *GaugeVec) WithLabelValues(labelValues ...string) *Gauge {
+	return this.get(labelValues, func() interface{ Collect() []Sample } {
+		return &Gauge{desc: this.desc}
+	}).(*Gauge)
+}
+
+//Func is a metric family whose samples are read when collected
+type Func struct {
+	desc    *Desc
+	collect func() []Sample
+}
+
+//NewVecFunc returns a metric family whose samples are returned by collect
+func NewVecFunc(typ Type, name, help string, labels []string, collect func() []Sample) *Func {
+	return &Func{desc: newDesc(typ, name, help, labels), collect: collect}
+}
+
+//NewGaugeFunc returns a gauge whose value is returned by value
+func NewGaugeFunc(name, help string, value func() float64) *Func {
+	return NewVecFunc(GAUGE, name, help, nil, func() []Sample {
+		return []Sample{{Value: value()}}
+	})
+}
+
+//NewCounterFunc returns a counter whose value is returned by value
+func NewCounterFunc(name, help string, value func() float64) *Func {
+	return NewVecFunc(COUNTER, name, help, nil, func() []Sample {
+		return []Sample{{Value: value()}}
+	})
+}
+
+func (this *Func) Desc() *Desc {
+	return this.desc
+}
+
+func (this *Func) Collect() []Sample {
+	return this.collect()
+}
+
+//Registry holds metric families and writes them in the prometheus text format
+type Registry struct {
+	lock    sync.RWMutex
+	metrics map[string]Metric
+}
+
+//DefaultRegistry is the registry served by the metrics http server
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]Metric)}
+}
+
+//Register adds the metrics to the registry, names must be unique
+func (this *Registry) Register(metrics ...Metric) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for _, m := range metrics {
+		name := m.Desc().Name
+		if _, ok := this.metrics[name]; ok {
+			return fmt.Errorf("metric %s is already registered", name)
+		}
+	}
+	for _, m := range metrics {
+		this.metrics[m.Desc().Name] = m
+	}
+	return nil
+}
+
+//MustRegister adds the metrics to the registry, panics if any name is duplicated
+func (this *Registry) MustRegister(metrics ...Metric) {
+	if err := this.Register(metrics...); err != nil {
+		panic(err)
+	}
+}
+
+//Unregister removes the metric of name from the registry
+func (this *Registry) Unregister(name string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.metrics, name)
+}
+
+//WriteTo writes all metric families sorted by name
+func (this *Registry) WriteTo(w io.Writer) (int64, error) {
+	this.lock.RLock()
+	names := make([]string, 0, len(this.metrics))
+	for name := range this.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]Metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, this.metrics[name])
+	}
+	this.lock.RUnlock()
+
+	var sb strings.Builder
+	for _, m := range metrics {
+		writeMetric(&sb, m)
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func writeMetric(sb *strings.Builder, m Metric) {
+	desc := m.Desc()
+	samples := m.Collect()
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].LabelValues, "\xff") < strings.Join(samples[j].LabelValues, "\xff")
+	})
+	fmt.Fprintf(sb, "# HELP %s %s\n", desc.Name, escapeHelp(desc.Help))
+	fmt.Fprintf(sb, "# TYPE %s %s\n", desc.Name, desc.Type)
+	for _, s := range samples {
+		sb.WriteString(desc.Name)
+		if len(desc.Labels) > 0 {
+			sb.WriteByte('{')
+			for i, label := range desc.Labels {
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				value := ""
+				if i < len(s.LabelValues) {
+					value = s.LabelValues[i]
+				}
+				fmt.Fprintf(sb, "%s=\"%s\"", label, escapeLabelValue(value))
+			}
+			sb.WriteByte('}')
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(formatValue(s.Value))
+		sb.WriteByte('\n')
+	}
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+	labelReplacer = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\"", "\\\"")
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelReplacer.Replace(s)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterAndGauge(t *testing.T) {
+	c := NewCounter("test_total", "test counter")
+	c.Inc()
+	c.Add(2)
+	assert.Equal(t, uint64(3), c.Value())
+
+	g := NewGauge("test_gauge", "test gauge")
+	g.Set(5)
+	g.Inc()
+	g.Add(-2.5)
+	assert.Equal(t, 3.5, g.Value())
+
+	v := NewCounterVec("test_vec_total", "test counter vec", "router", "result")
+	v.WithLabelValues("2", "success").Inc()
+	v.WithLabelValues("2", "success").Inc()
+	v.WithLabelValues("2", "failure").Inc()
+	assert.Equal(t, uint64(2), v.WithLabelValues("2", "success").Value())
+	assert.Len(t, v.Collect(), 2)
+	assert.Panics(t, func() { v.WithLabelValues("2") })
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	c := NewCounterVec("poly_test_total", "count of \"tests\"\nsecond line", "type")
+	c.WithLabelValues("b").Add(2)
+	c.WithLabelValues("a\"\\").Inc()
+	g := NewGaugeFunc("poly_height", "block height", func() float64 { return 100 })
+	f := NewVecFunc(GAUGE, "poly_pool", "pool size", []string{"state"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"pending"}, Value: 1.5}}
+	})
+	assert.NoError(t, r.Register(c, g, f))
+	assert.Error(t, r.Register(NewGauge("poly_height", "duplicated")))
+
+	buf := new(bytes.Buffer)
+	_, err := r.WriteTo(buf)
+	assert.NoError(t, err)
+	expected := `# HELP poly_height block height
+# TYPE poly_height gauge
+poly_height 100
+# HELP poly_pool pool size
+# TYPE poly_pool gauge
+poly_pool{state="pending"} 1.5
+# HELP poly_test_total count of "tests"\nsecond line
+# TYPE poly_test_total counter
+poly_test_total{type="a\"\\"} 1
+poly_test_total{type="b"} 2
+`
+	assert.Equal(t, expected, buf.String())
+
+	r.Unregister("poly_pool")
+	buf.Reset()
+	r.WriteTo(buf)
+	assert.NotContains(t, buf.String(), "poly_pool")
+}
@@ -52,3 +52,9 @@ func NewConsensusService(consensusType string, account *account.Account, txpool
 	log.Infof("ConsensusType:%s", consensusType)
 	return consensus, err
 }
+
+//NewVbftService creates vbft consensus service signing with signer, which may be a remote signer daemon
+func NewVbftService(signer vbft.Signer, txpool *actor.PID, p2p *actor.PID) (ConsensusService, error) {
+	log.Infof("ConsensusType:%s", CONSENSUS_VBFT)
+	return vbft.NewVbftServerWithSigner(signer, txpool, p2p)
+}
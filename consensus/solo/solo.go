@@ -197,10 +197,15 @@ func (self *SoloService) makeBlock() (*types.Block, error) {
 	}
 	txRoot := common.ComputeMerkleRoot(txHash)
 	blockRoot := ledger.DefLedger.GetBlockRootWithPreBlockHashes(height+1, []common.Uint256{prevHash})
+	crossStateRoot, err := ledger.DefLedger.GetCrossStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetCrossStateRoot height:%d error:%s", height, err)
+	}
 	header := &types.Header{
 		Version:          ContextVersion,
 		PrevBlockHash:    prevHash,
 		TransactionsRoot: txRoot,
+		CrossStateRoot:   crossStateRoot,
 		BlockRoot:        blockRoot,
 		Timestamp:        uint32(time.Now().Unix()),
 		Height:           height + 1,
@@ -772,3 +772,9 @@ func (pool *BlockPool) getCrossStatesRoot(blkNum uint32) (common.Uint256, error)
 	defer pool.lock.RUnlock()
 	return pool.chainStore.getCrossStateRoot(blkNum)
 }
+
+func (pool *BlockPool) getStateRoot(blkNum uint32) (common.Uint256, error) {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+	return pool.chainStore.getStateRoot(blkNum)
+}
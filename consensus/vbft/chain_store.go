@@ -60,12 +60,16 @@ func OpenBlockStore(db *ledger.Ledger, serverPid *actor.PID) (*ChainStore, error
 	if err != nil {
 		return nil, fmt.Errorf("GetCrossStatesRoot blockNum:%d, error :%s", chainstore.chainedBlockNum, err)
 	}
+	stateRoot, err := db.GetStateRoot(chainstore.chainedBlockNum)
+	if err != nil {
+		return nil, fmt.Errorf("GetStateRoot blockNum:%d, error :%s", chainstore.chainedBlockNum, err)
+	}
 	writeSet := overlaydb.NewMemDB(1, 1)
 	block, err := chainstore.getBlock(chainstore.chainedBlockNum)
 	if err != nil {
 		return nil, err
 	}
-	chainstore.pendingBlocks[chainstore.chainedBlockNum] = &PendingBlock{block: block, execResult: &store.ExecuteResult{WriteSet: writeSet, MerkleRoot: merkleRoot, CrossStatesRoot: crossStatesRoot}}
+	chainstore.pendingBlocks[chainstore.chainedBlockNum] = &PendingBlock{block: block, execResult: &store.ExecuteResult{WriteSet: writeSet, MerkleRoot: merkleRoot, CrossStatesRoot: crossStatesRoot, StateRoot: stateRoot}}
 	return chainstore, nil
 }
 
@@ -95,6 +99,20 @@ func (self *ChainStore) getCrossStateRoot(blkNum uint32) (common.Uint256, error)
 	}
 }
 
+func (self *ChainStore) getStateRoot(blkNum uint32) (common.Uint256, error) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+
+	if blk, present := self.pendingBlocks[blkNum]; blk != nil && present {
+		return blk.execResult.StateRoot, nil
+	}
+	stateRoot, err := self.db.GetStateRoot(blkNum)
+	if err != nil {
+		return common.Uint256{}, fmt.Errorf("GetStateRoot blockNum:%d, error :%s", blkNum, err)
+	}
+	return stateRoot, nil
+}
+
 func (self *ChainStore) getExecWriteSet(blkNum uint32) *overlaydb.MemDB {
 	self.lock.RLock()
 	defer self.lock.RUnlock()
@@ -61,6 +61,7 @@ type VbftBlockInfo struct {
 	VrfProof           []byte       `json:"vrf_proof"`
 	LastConfigBlockNum uint32       `json:"last_config_block_num"`
 	NewChainConfig     *ChainConfig `json:"new_chain_config"`
+	StateRoot          []byte       `json:"state_root,omitempty"` // state trie root of previous block
 }
 
 const (
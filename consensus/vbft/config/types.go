@@ -24,6 +24,7 @@ import (
 	"fmt"
 
 	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/types"
 )
 
@@ -45,6 +46,11 @@ func Pubkey(nodeid string) (keypair.PublicKey, error) {
 	return pk, err
 }
 
+//StateRootRequired return whether the vbft block info of height must commit the state trie root of height-1
+func StateRootRequired(height uint32) bool {
+	return height >= config.GetStateRootHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
 func VbftBlock(header *types.Header) (*VbftBlockInfo, error) {
 	blkInfo := &VbftBlockInfo{}
 	if err := json.Unmarshal(header.ConsensusPayload, blkInfo); err != nil {
@@ -44,6 +44,27 @@ const (
 	EventMax
 )
 
+var timerEventNames = map[TimerEventType]string{
+	EventProposeBlockTimeout:      "propose_block_timeout",
+	EventProposalBackoff:          "proposal_backoff",
+	EventRandomBackoff:            "random_backoff",
+	EventPropose2ndBlockTimeout:   "propose_2nd_block_timeout",
+	EventEndorseBlockTimeout:      "endorse_block_timeout",
+	EventEndorseEmptyBlockTimeout: "endorse_empty_block_timeout",
+	EventCommitBlockTimeout:       "commit_block_timeout",
+	EventPeerHeartbeat:            "peer_heartbeat",
+	EventTxPool:                   "tx_pool",
+	EventTxBlockTimeout:           "tx_block_timeout",
+	EventMax:                      "normal",
+}
+
+func (evtType TimerEventType) String() string {
+	if name, present := timerEventNames[evtType]; present {
+		return name
+	}
+	return fmt.Sprintf("unknown_%d", int(evtType))
+}
+
 var (
 	makeProposalTimeout    = 300 * time.Millisecond
 	make2ndProposalTimeout = 300 * time.Millisecond
@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"github.com/polynetwork/poly/common/metrics"
+)
+
+var (
+	roundCounter      = metrics.NewCounter("poly_vbft_rounds_total", "Count of vbft consensus rounds started")
+	viewGauge         = metrics.NewGauge("poly_vbft_view", "View of the current vbft chain config")
+	viewChangeCounter = metrics.NewCounter("poly_vbft_view_changes_total", "Count of vbft chain config view changes")
+	timerEventCounter = metrics.NewCounterVec("poly_vbft_timer_events_total", "Count of vbft timer events fired", "event")
+)
+
+func init() {
+	metrics.DefaultRegistry.MustRegister(roundCounter, viewGauge, viewChangeCounter, timerEventCounter)
+}
@@ -30,7 +30,6 @@ import (
 	"github.com/polynetwork/poly/common/log"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/ledger"
-	"github.com/polynetwork/poly/core/signature"
 	"github.com/polynetwork/poly/core/types"
 )
 
@@ -111,6 +110,12 @@ func DeserializeVbftMsg(msgPayload []byte) (ConsensusMsg, error) {
 			return nil, fmt.Errorf("failed to unmarshal msg (type: %d): %s", m.Type, err)
 		}
 		return t, nil
+	case EquivocationEvidenceMessage:
+		t := &equivocationEvidenceMsg{}
+		if err := json.Unmarshal(m.Payload, t); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal msg (type: %d): %s", m.Type, err)
+		}
+		return t, nil
 	}
 
 	return nil, fmt.Errorf("unknown msg type: %d", m.Type)
@@ -180,7 +185,7 @@ func (self *Server) constructHeartbeatMsg() (*peerHeartbeatMsg, error) {
 }
 
 func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, txs []*types.Transaction,
-	consensusPayload []byte, blocktimestamp uint32, nextBookkeeper common.Address) (*types.Block, error) {
+	consensusPayload []byte, blocktimestamp uint32, nextBookkeeper common.Address, forEmpty bool) (*types.Block, error) {
 	txHash := []common.Uint256{}
 	for _, t := range txs {
 		txHash = append(txHash, t.Hash())
@@ -217,11 +222,17 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 		Transactions: txs,
 	}
 	blkHash := blk.Hash()
-	sig, err := signature.Sign(self.account, blkHash[:])
+	sig, err := self.signer.SignBlock(&BlockSignRequest{
+		Step:     SIGN_PROPOSAL,
+		Height:   blkNum,
+		Proposer: self.Index,
+		ForEmpty: forEmpty,
+		Hash:     blkHash,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("sign block failed, block hash:%s, error: %s", blkHash.ToHexString(), err)
 	}
-	blkHeader.Bookkeepers = []keypair.PublicKey{self.account.PublicKey}
+	blkHeader.Bookkeepers = []keypair.PublicKey{self.signer.PublicKey()}
 	blkHeader.SigData = [][]byte{sig}
 
 	return blk, nil
@@ -238,7 +249,7 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 		blocktimestamp = prevBlk.Block.Header.Timestamp + 1
 	}
 
-	vrfValue, vrfProof, err := computeVrf(self.account.PrivateKey, blkNum, prevBlk.getVrfValue())
+	vrfValue, vrfProof, err := self.signer.Vrf(blkNum, prevBlk.getVrfValue())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vrf and proof: %s", err)
 	}
@@ -274,16 +285,23 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 		LastConfigBlockNum: lastConfigBlkNum,
 		NewChainConfig:     chainconfig,
 	}
+	if vconfig.StateRootRequired(blkNum) {
+		stateRoot, err := self.blockPool.getStateRoot(blkNum - 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetStateRoot: %s,blkNum:%d", err, (blkNum - 1))
+		}
+		vbftBlkInfo.StateRoot = stateRoot.ToArray()
+	}
 	consensusPayload, err := json.Marshal(vbftBlkInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	emptyBlk, err := self.constructBlock(blkNum, prevBlkHash, sysTxs, consensusPayload, blocktimestamp, nextBookkeeper)
+	emptyBlk, err := self.constructBlock(blkNum, prevBlkHash, sysTxs, consensusPayload, blocktimestamp, nextBookkeeper, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct empty block: %s", err)
 	}
-	blk, err := self.constructBlock(blkNum, prevBlkHash, append(sysTxs, userTxs...), consensusPayload, blocktimestamp, nextBookkeeper)
+	blk, err := self.constructBlock(blkNum, prevBlkHash, append(sysTxs, userTxs...), consensusPayload, blocktimestamp, nextBookkeeper, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to constuct blk: %s", err)
 	}
@@ -319,7 +337,13 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 		proposerSig = proposal.Block.EmptyBlock.Header.SigData[0]
 		blkHash = proposal.Block.EmptyBlock.Hash()
 	}
-	endorserSig, err = signature.Sign(self.account, blkHash[:])
+	endorserSig, err = self.signer.SignBlock(&BlockSignRequest{
+		Step:     SIGN_ENDORSE,
+		Height:   proposal.Block.getBlockNum(),
+		Proposer: proposal.Block.getProposer(),
+		ForEmpty: forEmpty,
+		Hash:     blkHash,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, err: %s", blkHash, err)
 	}
@@ -357,7 +381,13 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 		proposerSig = proposal.Block.EmptyBlock.Header.SigData[0]
 		blkHash = proposal.Block.EmptyBlock.Hash()
 	}
-	committerSig, err = signature.Sign(self.account, blkHash[:])
+	committerSig, err = self.signer.SignBlock(&BlockSignRequest{
+		Step:     SIGN_COMMIT,
+		Height:   proposal.Block.getBlockNum(),
+		Proposer: proposal.Block.getProposer(),
+		ForEmpty: forEmpty,
+		Hash:     blkHash,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, caused by: %s", blkHash, err)
 	}
@@ -24,24 +24,42 @@ import (
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
 )
 
 var errDropFarFutureMsg = errors.New("msg pool dropped msg for far future")
 
 type ConsensusRoundMsgs map[MsgType][]ConsensusMsg // indexed by MsgType (proposal, endorsement, ...)
 
+//signedMsgKey identifies the block signed in a consensus msg, a peer signs at most one block for each key
+type signedMsgKey struct {
+	msgType  MsgType
+	author   uint32
+	proposer uint32
+	forEmpty bool
+}
+
+type signedMsg struct {
+	blockHash common.Uint256
+	payload   []byte // serialized consensus payload signed by the author
+	reported  bool
+}
+
 type ConsensusRound struct {
-	blockNum uint32
-	msgs     map[MsgType][]ConsensusMsg
-	msgHashs map[common.Uint256]interface{} // for msg-dup checking
+	blockNum   uint32
+	msgs       map[MsgType][]ConsensusMsg
+	msgHashs   map[common.Uint256]interface{} // for msg-dup checking
+	signedMsgs map[signedMsgKey]*signedMsg    // for equivocation checking
 }
 
 func newConsensusRound(num uint32) *ConsensusRound {
 
 	r := &ConsensusRound{
-		blockNum: num,
-		msgs:     make(map[MsgType][]ConsensusMsg),
-		msgHashs: make(map[common.Uint256]interface{}),
+		blockNum:   num,
+		msgs:       make(map[MsgType][]ConsensusMsg),
+		msgHashs:   make(map[common.Uint256]interface{}),
+		signedMsgs: make(map[signedMsgKey]*signedMsg),
 	}
 
 	r.msgs[BlockProposalMessage] = make([]ConsensusMsg, 0)
@@ -155,6 +173,54 @@ func (pool *MsgPool) HasMsg(msg ConsensusMsg, msgHash common.Uint256) bool {
 	return false
 }
 
+func getSignedMsgKey(msg ConsensusMsg) (signedMsgKey, common.Uint256, bool) {
+	switch m := msg.(type) {
+	case *blockProposalMsg:
+		proposer := m.Block.getProposer()
+		return signedMsgKey{BlockProposalMessage, proposer, proposer, false}, m.Block.Block.Hash(), true
+	case *blockEndorseMsg:
+		return signedMsgKey{BlockEndorseMessage, m.Endorser, m.EndorsedProposer, m.EndorseForEmpty}, m.EndorsedBlockHash, true
+	case *blockCommitMsg:
+		return signedMsgKey{BlockCommitMessage, m.Committer, m.BlockProposer, m.CommitForEmpty}, m.CommitBlockHash, true
+	}
+	return signedMsgKey{}, common.Uint256{}, false
+}
+
+//AddSignedMsg records the block signed in msg, which is received in payload from peer owner. Msgs relayed by other
+//peers are ignored. Returns the evidence if the author has signed another block for the same key, once for each key.
+func (pool *MsgPool) AddSignedMsg(owner uint32, msg ConsensusMsg, payload *p2pmsg.ConsensusPayload) *node_manager.EquivocationEvidence {
+	key, blockHash, ok := getSignedMsgKey(msg)
+	if !ok || key.author != owner {
+		return nil
+	}
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	blkNum := msg.GetBlockNum()
+	curBlkNum := pool.server.GetCurrentBlockNo()
+	if blkNum > curBlkNum+pool.historyLen || blkNum+pool.historyLen < curBlkNum {
+		return nil
+	}
+	if _, present := pool.rounds[blkNum]; !present {
+		pool.rounds[blkNum] = newConsensusRound(blkNum)
+	}
+	signed, present := pool.rounds[blkNum].signedMsgs[key]
+	if present && (signed.blockHash == blockHash || signed.reported) {
+		return nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := payload.Serialization(sink); err != nil {
+		log.Errorf("msgpool failed to serialize consensus payload: %s", err)
+		return nil
+	}
+	if !present {
+		pool.rounds[blkNum].signedMsgs[key] = &signedMsg{blockHash: blockHash, payload: sink.Bytes()}
+		return nil
+	}
+	signed.reported = true
+	return node_manager.NewEquivocationEvidence(signed.payload, sink.Bytes())
+}
+
 func (pool *MsgPool) Persist() error {
 	// TODO
 	return nil
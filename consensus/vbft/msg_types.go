@@ -46,6 +46,7 @@ const (
 	ProposalFetchMessage
 	BlockFetchMessage
 	BlockFetchRespMessage
+	EquivocationEvidenceMessage
 )
 
 type ConsensusMsg interface {
@@ -384,3 +385,26 @@ func (msg *proposalFetchMsg) GetBlockNum() uint32 {
 func (msg *proposalFetchMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
+
+//equivocationEvidenceMsg gossips two conflicting consensus messages signed by the same peer
+type equivocationEvidenceMsg struct {
+	MsgA []byte `json:"msg_a"`
+	MsgB []byte `json:"msg_b"`
+}
+
+func (msg *equivocationEvidenceMsg) Type() MsgType {
+	return EquivocationEvidenceMessage
+}
+
+//Verify does nothing, the evidence is verified with the signatures of the offender when processed
+func (msg *equivocationEvidenceMsg) Verify(pub keypair.PublicKey) error {
+	return nil
+}
+
+func (msg *equivocationEvidenceMsg) GetBlockNum() uint32 {
+	return 0
+}
+
+func (msg *equivocationEvidenceMsg) Serialize() ([]byte, error) {
+	return json.Marshal(msg)
+}
@@ -19,13 +19,11 @@
 package vbft
 
 import (
-	"bytes"
 	"fmt"
 	"math"
 
 	"github.com/polynetwork/poly/common/log"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
-	"github.com/polynetwork/poly/core/signature"
 	msgpack "github.com/polynetwork/poly/p2pserver/message/msg_pack"
 	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
 )
@@ -384,12 +382,12 @@ func (self *Server) heartbeat() {
 	}
 }
 
-func (self *Server) receiveFromPeer(peerIdx uint32) (uint32, []byte, error) {
+func (self *Server) receiveFromPeer(peerIdx uint32) (uint32, *p2pmsg.ConsensusPayload, error) {
 	if C, present := self.msgRecvC[peerIdx]; present {
 		select {
 		case payload := <-C:
 			if payload != nil {
-				return payload.fromPeer, payload.payload.Data, nil
+				return payload.fromPeer, payload.payload, nil
 			}
 
 		case <-self.quitC:
@@ -400,21 +398,28 @@ func (self *Server) receiveFromPeer(peerIdx uint32) (uint32, []byte, error) {
 	return 0, nil, fmt.Errorf("nil consensus payload")
 }
 
-func (self *Server) sendToPeer(peerIdx uint32, data []byte) error {
+//signMsg makes the consensus payload of serialized msg signed by signer
+func (self *Server) signMsg(msgType MsgType, data []byte) (*p2pmsg.ConsensusPayload, error) {
+	req := &MsgSignRequest{Type: msgType, Msg: data}
+	msg, _, err := req.Payload(self.signer.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	if msg.Signature, err = self.signer.SignMsg(req); err != nil {
+		return nil, fmt.Errorf("failed to sign consensus msg: %s", err)
+	}
+	return msg, nil
+}
+
+func (self *Server) sendToPeer(peerIdx uint32, msgType MsgType, data []byte) error {
 	peer := self.peerPool.getPeer(peerIdx)
 	if peer == nil {
 		return fmt.Errorf("send peer failed: failed to get peer %d", peerIdx)
 	}
-	msg := &p2pmsg.ConsensusPayload{
-		Data:  data,
-		Owner: self.account.PublicKey,
-	}
-
-	buf := new(bytes.Buffer)
-	if err := msg.SerializeUnsigned(buf); err != nil {
-		return fmt.Errorf("failed to serialize consensus msg: %s", err)
+	msg, err := self.signMsg(msgType, data)
+	if err != nil {
+		return err
 	}
-	msg.Signature, _ = signature.Sign(self.account, buf.Bytes())
 
 	cons := msgpack.NewConsensus(msg)
 	p2pid, present := self.peerPool.getP2pId(peerIdx)
@@ -434,17 +439,11 @@ func (self *Server) broadcast(msg ConsensusMsg) error {
 	return nil
 }
 
-func (self *Server) broadcastToAll(data []byte) error {
-	msg := &p2pmsg.ConsensusPayload{
-		Data:  data,
-		Owner: self.account.PublicKey,
-	}
-
-	buf := new(bytes.Buffer)
-	if err := msg.SerializeUnsigned(buf); err != nil {
-		return fmt.Errorf("failed to serialize consensus msg: %s", err)
+func (self *Server) broadcastToAll(msgType MsgType, data []byte) error {
+	msg, err := self.signMsg(msgType, data)
+	if err != nil {
+		return err
 	}
-	msg.Signature, _ = signature.Sign(self.account, buf.Bytes())
 
 	self.p2p.Broadcast(msg)
 	return nil
@@ -88,7 +88,7 @@ type p2pMsgPayload struct {
 
 type Server struct {
 	Index         uint32
-	account       *account.Account
+	signer        Signer
 	poolActor     *actorTypes.TxPoolActor
 	p2p           *actorTypes.P2PActor
 	ledger        *ledger.Ledger
@@ -119,6 +119,9 @@ type Server struct {
 	stateMgr   *StateMgr
 	timer      *EventTimer
 
+	evidenceLock sync.Mutex
+	evidenceTxs  map[common.Uint256]*types.Transaction // evidence transactions to be packed in proposals
+
 	msgRecvC   map[uint32]chan *p2pMsgPayload
 	msgC       chan ConsensusMsg
 	bftActionC chan *BftAction
@@ -130,9 +133,14 @@ type Server struct {
 }
 
 func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, error) {
+	return NewVbftServerWithSigner(NewLocalSigner(account), txpool, p2p)
+}
+
+//NewVbftServerWithSigner creates vbft server signing blocks and consensus payloads with signer
+func NewVbftServerWithSigner(signer Signer, txpool, p2p *actor.PID) (*Server, error) {
 	server := &Server{
 		msgHistoryDuration: 64,
-		account:            account,
+		signer:             signer,
 		poolActor:          &actorTypes.TxPoolActor{Pool: txpool},
 		p2p:                &actorTypes.P2PActor{P2P: p2p},
 		ledger:             ledger.DefLedger,
@@ -203,7 +211,8 @@ func (self *Server) Halt() error {
 }
 
 func (self *Server) handleBlockPersistCompleted(block *types.Block) {
-	log.Infof("persist block: %d, %x", block.Header.Height, block.Hash())
+	blockHash := block.Hash()
+	log.WithFields(log.Fields{"height": block.Header.Height, "blockHash": blockHash.ToHexString()}).Info("persist block")
 
 	if block.Header.Height <= self.completedBlockNum {
 		log.Infof("server %d, persist block %d, vs completed %d",
@@ -290,6 +299,7 @@ func (self *Server) LoadChainConfig(blkNum uint32) error {
 	if self.config.View == 0 || self.config.MaxBlockChangeView == 0 {
 		panic("invalid view or maxblockchangeview ")
 	}
+	viewGauge.Set(float64(self.config.View))
 	// update msg delays
 	makeProposalTimeout = time.Duration(self.config.BlockMsgDelay * 2)
 	make2ndProposalTimeout = time.Duration(self.config.BlockMsgDelay)
@@ -333,8 +343,12 @@ func (self *Server) updateChainConfig() error {
 	}
 	log.Infof("updateChainConfig blkNum:%d", self.completedBlockNum)
 	self.metaLock.Lock()
+	if self.config.View != block.Info.NewChainConfig.View {
+		viewChangeCounter.Inc()
+	}
 	self.config = block.Info.NewChainConfig
 	self.LastConfigBlockNum = block.getLastConfigBlockNum()
+	viewGauge.Set(float64(self.config.View))
 	self.metaLock.Unlock()
 
 	self.metaLock.RLock()
@@ -345,7 +359,7 @@ func (self *Server) updateChainConfig() error {
 	// 2. remove nonparticipation consensus node
 	// 3. update statemgr peers
 	// 4. reset remove peer connections, create new connections with new peers
-	pubkey := vconfig.PubkeyID(self.account.PublicKey)
+	pubkey := vconfig.PubkeyID(self.signer.PublicKey())
 	peermap := make(map[uint32]string)
 	for _, p := range self.config.Peers {
 		peermap[p.Index] = p.ID
@@ -406,7 +420,7 @@ func (self *Server) initialize() error {
 	// TODO: load config from chain
 
 	// TODO: configurable log
-	selfNodeId := vconfig.PubkeyID(self.account.PublicKey)
+	selfNodeId := vconfig.PubkeyID(self.signer.PublicKey())
 	log.Infof("server: %s starting", selfNodeId)
 
 	store, err := OpenBlockStore(self.ledger, self.pid)
@@ -431,6 +445,7 @@ func (self *Server) initialize() error {
 	self.msgC = make(chan ConsensusMsg, CAP_MESSAGE_CHANNEL)
 	self.bftActionC = make(chan *BftAction, CAP_ACTION_CHANNEL)
 	self.msgSendC = make(chan *SendMsgEvent, CAP_MSG_SEND_CHANNEL)
+	self.evidenceTxs = make(map[common.Uint256]*types.Transaction)
 
 	self.quitC = make(chan struct{})
 	if err := self.LoadChainConfig(store.GetChainedBlockNum()); err != nil {
@@ -455,7 +470,7 @@ func (self *Server) initialize() error {
 	}
 
 	//index equal math.MaxUint32  is noconsensus node
-	id := vconfig.PubkeyID(self.account.PublicKey)
+	id := vconfig.PubkeyID(self.signer.PublicKey())
 	index, present := self.peerPool.GetPeerIndex(id)
 	if present {
 		self.Index = index
@@ -494,7 +509,7 @@ func (self *Server) initialize() error {
 
 func (self *Server) start() error {
 	// check if server pubkey support VRF
-	if !vrf.ValidatePrivateKey(self.account.PrivateKey) || !vrf.ValidatePublicKey(self.account.PublicKey) {
+	if !vrf.ValidatePublicKey(self.signer.PublicKey()) {
 		return fmt.Errorf("server %d consensus start failed: invalid account key for VRF", self.Index)
 	}
 
@@ -576,11 +591,12 @@ func (self *Server) run(peerPubKey keypair.PublicKey) error {
 	errC := make(chan error)
 	go func() {
 		for {
-			fromPeer, msgData, err := self.receiveFromPeer(peerIdx)
+			fromPeer, payload, err := self.receiveFromPeer(peerIdx)
 			if err != nil {
 				errC <- err
 				return
 			}
+			msgData := payload.Data
 			msg, err := DeserializeVbftMsg(msgData)
 
 			if err != nil {
@@ -592,6 +608,7 @@ func (self *Server) run(peerPubKey keypair.PublicKey) error {
 					continue
 				}
 
+				owner := fromPeer
 				if msg.Type() == BlockProposalMessage {
 					if proposal := msg.(*blockProposalMsg); proposal != nil {
 						fromPeer = proposal.Block.getProposer()
@@ -609,6 +626,9 @@ func (self *Server) run(peerPubKey keypair.PublicKey) error {
 					log.Infof("server %d received consensus msg, blk %d, type: %d from %d",
 						self.Index, msg.GetBlockNum(), msg.Type(), fromPeer)
 				}
+				if evidence := self.msgPool.AddSignedMsg(owner, msg, payload); evidence != nil {
+					self.onEquivocationEvidence(evidence)
+				}
 
 				self.onConsensusMsg(fromPeer, msg, hashData(msgData))
 			}
@@ -649,6 +669,7 @@ func (self *Server) updateParticipantConfig() error {
 
 func (self *Server) startNewRound() error {
 	blkNum := self.GetCurrentBlockNo()
+	roundCounter.Inc()
 
 	if err := self.updateParticipantConfig(); err != nil {
 		log.Errorf("startNewRound error:%s", err)
@@ -1042,7 +1063,37 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 			fromPeer: peerIdx,
 			msg:      msg,
 		}
+
+	case EquivocationEvidenceMessage:
+		pMsg, ok := msg.(*equivocationEvidenceMsg)
+		if !ok {
+			log.Errorf("invalid msg with equivocation evidence msg type")
+			return
+		}
+		self.onEquivocationEvidence(node_manager.NewEquivocationEvidence(pMsg.MsgA, pMsg.MsgB))
+	}
+}
+
+//onEquivocationEvidence gossips new evidence to peers, and keeps the transaction submitting it to node_manager,
+//which is packed in proposals of this node until it is in ledger
+func (self *Server) onEquivocationEvidence(evidence *node_manager.EquivocationEvidence) {
+	msgA, msgB, err := evidence.Verify()
+	if err != nil {
+		log.Errorf("server %d, invalid equivocation evidence: %s", self.Index, err)
+		return
+	}
+	tx := self.createEvidenceTransaction(evidence, msgA.Height)
+	self.evidenceLock.Lock()
+	if _, present := self.evidenceTxs[tx.Hash()]; present {
+		self.evidenceLock.Unlock()
+		return
 	}
+	self.evidenceTxs[tx.Hash()] = tx
+	self.evidenceLock.Unlock()
+
+	log.Warnf("server %d, peer %d signed conflicting msgs (type %d) of proposer %d for block %d: %s, %s",
+		self.Index, msgA.Author, msgA.Type, msgA.Proposer, msgA.Height, msgA.Hash.ToHexString(), msgB.Hash.ToHexString())
+	self.broadcast(&equivocationEvidenceMsg{MsgA: evidence.MsgA, MsgB: evidence.MsgB})
 }
 
 func (self *Server) processProposalMsg(msg *blockProposalMsg) {
@@ -1073,6 +1124,17 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 		log.Errorf("BlockPrposalMessage check crossStateRoot blocknum:%d,msg crossStateRoot:%s,self crossStateRoot:%s", msg.GetBlockNum(), msgCrossStateRoot.ToHexString(), crossStateRoot.ToHexString())
 		return
 	}
+	if msgStateRoot := msg.Block.getPrevBlockStateRoot(); len(msgStateRoot) != 0 || vconfig.StateRootRequired(msgBlkNum) {
+		stateRoot, err := self.blockPool.getStateRoot(msgBlkNum - 1)
+		if err != nil {
+			log.Errorf("failed to getStateRoot: %s,blkNum:%d", err, (msgBlkNum - 1))
+			return
+		}
+		if !bytes.Equal(stateRoot[:], msgStateRoot) {
+			log.Errorf("BlockPrposalMessage check stateRoot blocknum:%d,msg stateRoot:%x,self stateRoot:%s", msg.GetBlockNum(), msgStateRoot, stateRoot.ToHexString())
+			return
+		}
+	}
 
 	cfg := vconfig.ChainConfig{}
 	if blk.getNewChainConfig() != nil {
@@ -1672,6 +1734,7 @@ func (self *Server) actionLoop() {
 }
 
 func (self *Server) processTimerEvent(evt *TimerEvent) error {
+	timerEventCounter.WithLabelValues(evt.evtType.String()).Inc()
 	switch evt.evtType {
 	case EventProposalBackoff:
 		// 1. if endorsed, return
@@ -2133,12 +2196,12 @@ func (self *Server) msgSendLoop() {
 			}
 			if evt.ToPeer == math.MaxUint32 {
 				// broadcast
-				if err := self.broadcastToAll(payload); err != nil {
+				if err := self.broadcastToAll(evt.Msg.Type(), payload); err != nil {
 					log.Errorf("server %d xmit msg (type %d): %s",
 						self.Index, evt.Msg.Type(), err)
 				}
 			} else {
-				if err := self.sendToPeer(evt.ToPeer, payload); err != nil {
+				if err := self.sendToPeer(evt.ToPeer, evt.Msg.Type(), payload); err != nil {
 					log.Errorf("server %d xmit to peer %d failed: %s", self.Index, evt.ToPeer, err)
 				}
 			}
@@ -2160,6 +2223,45 @@ func (self *Server) createGovernaceTransaction(blkNum uint32) *types.Transaction
 	return tx
 }
 
+//createEvidenceTransaction invoke governance native contract submit_evidence, the nonce is the height of the
+//conflicting msgs, so all nodes make the same transaction for the same evidence
+func (self *Server) createEvidenceTransaction(evidence *node_manager.EquivocationEvidence, height uint32) *types.Transaction {
+	args := common.NewZeroCopySink(nil)
+	evidence.Serialization(args)
+	contractInvokeParam := &states.ContractInvokeParam{Address: utils.NodeManagerContractAddress,
+		Method: node_manager.SUBMIT_EVIDENCE, Args: args.Bytes()}
+	invokeCode := new(common.ZeroCopySink)
+	contractInvokeParam.Serialization(invokeCode)
+	return genesis.NewInvokeTransaction(invokeCode.Bytes(), height)
+}
+
+//getEvidenceTxs returns evidence transactions to be packed in block blkNum, the ones already in ledger or not
+//accepted any more are dropped
+func (self *Server) getEvidenceTxs(blkNum, validHeight uint32) []*types.Transaction {
+	self.evidenceLock.Lock()
+	defer self.evidenceLock.Unlock()
+
+	txs := make([]*types.Transaction, 0)
+	for hash, tx := range self.evidenceTxs {
+		if packed, err := self.ledger.IsContainTransaction(hash); err == nil && packed {
+			delete(self.evidenceTxs, hash)
+			continue
+		}
+		//nonce of evidence transaction is the height of the conflicting msgs, packed when the block is reached
+		if tx.Nonce > blkNum {
+			continue
+		}
+		if err := node_manager.CheckEvidenceHeight(blkNum, tx.Nonce); err != nil {
+			delete(self.evidenceTxs, hash)
+			continue
+		}
+		if err := self.incrValidator.Verify(tx, validHeight); err == nil {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
 //checkNeedUpdateChainConfig use blockcount
 func (self *Server) checkNeedUpdateChainConfig(blockNum uint32) bool {
 	prevBlk, _ := self.blockPool.getSealedBlock(blockNum - 1)
@@ -2244,6 +2346,7 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	}
 
 	if !forEmpty {
+		userTxs = append(userTxs, self.getEvidenceTxs(blkNum, validHeight)...)
 		for _, e := range self.poolActor.GetTxnPool(true, validHeight) {
 			if err := self.incrValidator.Verify(e.Tx, validHeight); err == nil {
 				userTxs = append(userTxs, e.Tx)
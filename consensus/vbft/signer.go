@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+//SignStep is the consensus step a block is signed for
+type SignStep byte
+
+const (
+	SIGN_PROPOSAL SignStep = 1 //proposer seals the block and the empty block of its proposal
+	SIGN_ENDORSE  SignStep = 2
+	SIGN_COMMIT   SignStep = 3
+)
+
+func (step SignStep) String() string {
+	switch step {
+	case SIGN_PROPOSAL:
+		return "proposal"
+	case SIGN_ENDORSE:
+		return "endorse"
+	case SIGN_COMMIT:
+		return "commit"
+	}
+	return fmt.Sprintf("step(%d)", byte(step))
+}
+
+//BlockSignRequest is a block hash to sign for a consensus step. Proposals of different proposers can be endorsed and
+//committed at the same height, so a signed block is identified by height, proposer and whether it is the empty block.
+type BlockSignRequest struct {
+	Step     SignStep
+	Height   uint32
+	Proposer uint32
+	ForEmpty bool
+	Hash     common.Uint256
+}
+
+//MsgSignRequest is a vbft message of Type serialized by SerializeVbftMsg. Proposals, endorsements and commits are
+//evidence against the payload owner, so they are signed as their blocks, checked by height, view and step. The view of a
+//height is the proposer of the block and whether it is the empty block.
+type MsgSignRequest struct {
+	Type MsgType
+	Msg  []byte
+}
+
+//Blocks decodes the message and returns the blocks signed by owner in it, other types of message sign no block. It
+//returns an error if the message is not of the request type.
+func (req *MsgSignRequest) Blocks(owner keypair.PublicKey) ([]*BlockSignRequest, error) {
+	msg, err := DeserializeVbftMsg(req.Msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type() != req.Type {
+		return nil, fmt.Errorf("msg of type %d is not type %d", msg.Type(), req.Type)
+	}
+	switch m := msg.(type) {
+	case *blockProposalMsg:
+		if m.Block == nil || m.Block.Block == nil || m.Block.Info == nil {
+			return nil, fmt.Errorf("incomplete proposal msg")
+		}
+		//proposals of other peers are relayed too, only a block signed by owner can be evidence against it
+		blocks := make([]*BlockSignRequest, 0)
+		for i, blk := range []*types.Block{m.Block.Block, m.Block.EmptyBlock} {
+			if blk == nil || len(blk.Header.SigData) == 0 {
+				continue
+			}
+			hash := blk.Hash()
+			if signature.Verify(owner, hash[:], blk.Header.SigData[0]) != nil {
+				continue
+			}
+			blocks = append(blocks, &BlockSignRequest{
+				Step:     SIGN_PROPOSAL,
+				Height:   blk.Header.Height,
+				Proposer: m.Block.getProposer(),
+				ForEmpty: i == 1,
+				Hash:     hash,
+			})
+		}
+		return blocks, nil
+	case *blockEndorseMsg:
+		return []*BlockSignRequest{{
+			Step:     SIGN_ENDORSE,
+			Height:   m.BlockNum,
+			Proposer: m.EndorsedProposer,
+			ForEmpty: m.EndorseForEmpty,
+			Hash:     m.EndorsedBlockHash,
+		}}, nil
+	case *blockCommitMsg:
+		return []*BlockSignRequest{{
+			Step:     SIGN_COMMIT,
+			Height:   m.BlockNum,
+			Proposer: m.BlockProposer,
+			ForEmpty: m.CommitForEmpty,
+			Hash:     m.CommitBlockHash,
+		}}, nil
+	}
+	return nil, nil
+}
+
+//Payload returns the consensus payload of the message owned by owner, the unsigned data of it is signed
+func (req *MsgSignRequest) Payload(owner keypair.PublicKey) (*p2pmsg.ConsensusPayload, []byte, error) {
+	payload := &p2pmsg.ConsensusPayload{
+		Data:  req.Msg,
+		Owner: owner,
+	}
+	buf := new(bytes.Buffer)
+	if err := payload.SerializeUnsigned(buf); err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize consensus msg: %s", err)
+	}
+	return payload, buf.Bytes(), nil
+}
+
+//Signer signs blocks and consensus payloads with the consensus key of node
+type Signer interface {
+	PublicKey() keypair.PublicKey
+	SignBlock(req *BlockSignRequest) ([]byte, error)
+	//SignMsg signs the consensus payload of a message sent to peers, the payload is owned by the public key of signer
+	SignMsg(req *MsgSignRequest) ([]byte, error)
+	//Vrf computes the vrf value and proof of block from the vrf value of previous block
+	Vrf(blkNum uint32, prevVrf []byte) ([]byte, []byte, error)
+}
+
+type localSigner struct {
+	account *account.Account
+}
+
+//NewLocalSigner returns a signer with the key of account loaded into the node process
+func NewLocalSigner(account *account.Account) Signer {
+	return &localSigner{account: account}
+}
+
+func (self *localSigner) PublicKey() keypair.PublicKey {
+	return self.account.PublicKey
+}
+
+func (self *localSigner) SignBlock(req *BlockSignRequest) ([]byte, error) {
+	return signature.Sign(self.account, req.Hash[:])
+}
+
+func (self *localSigner) SignMsg(req *MsgSignRequest) ([]byte, error) {
+	if _, err := req.Blocks(self.account.PublicKey); err != nil {
+		return nil, fmt.Errorf("invalid msg to sign: %s", err)
+	}
+	_, data, err := req.Payload(self.account.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return signature.Sign(self.account, data)
+}
+
+func (self *localSigner) Vrf(blkNum uint32, prevVrf []byte) ([]byte, []byte, error) {
+	return computeVrf(self.account.PrivateKey, blkNum, prevVrf)
+}
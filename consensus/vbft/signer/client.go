@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/consensus/vbft"
+)
+
+//Client is a vbft signer backed by the signer daemon, it reconnects when the connection is lost
+type Client struct {
+	address   string
+	tlsConfig *tls.Config
+	pubKey    keypair.PublicKey
+
+	lock   sync.Mutex
+	client *rpc.Client
+}
+
+//Dial connects to the signer daemon at address and fetches the public key of signer
+func Dial(address string, tlsConfig *tls.Config) (*Client, error) {
+	client := &Client{address: address, tlsConfig: tlsConfig}
+	var data []byte
+	if err := client.call("PublicKey", uint32(0), &data); err != nil {
+		return nil, fmt.Errorf("get public key from signer error: %s", err)
+	}
+	pubKey, err := keypair.DeserializePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize public key error: %s", err)
+	}
+	client.pubKey = pubKey
+	return client, nil
+}
+
+func (this *Client) call(method string, args interface{}, reply interface{}) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	//the call is retried once with a new connection if the connection is broken, it is safe to sign a block twice
+	//since the guard of signer only refuses a different block
+	var err error
+	for i := 0; i < 2; i++ {
+		if this.client == nil {
+			conn, err := dial(this.address, this.tlsConfig)
+			if err != nil {
+				return fmt.Errorf("dial signer %s error: %s", this.address, err)
+			}
+			this.client = rpc.NewClientWithCodec(jsonrpc.NewClientCodec(conn))
+		}
+		err = this.client.Call(SERVICE_NAME+"."+method, args, reply)
+		if _, ok := err.(rpc.ServerError); ok || err == nil {
+			return err
+		}
+		log.Warnf("signer connection %s error: %s, reconnect", this.address, err)
+		this.client.Close()
+		this.client = nil
+	}
+	return err
+}
+
+func (this *Client) PublicKey() keypair.PublicKey {
+	return this.pubKey
+}
+
+func (this *Client) SignBlock(req *vbft.BlockSignRequest) ([]byte, error) {
+	var sig []byte
+	if err := this.call("SignBlock", req, &sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func (this *Client) SignMsg(req *vbft.MsgSignRequest) ([]byte, error) {
+	var sig []byte
+	if err := this.call("SignMsg", req, &sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func (this *Client) Vrf(blkNum uint32, prevVrf []byte) ([]byte, []byte, error) {
+	reply := new(VrfResponse)
+	if err := this.call("Vrf", &VrfRequest{BlockNum: blkNum, PrevVrf: prevVrf}, reply); err != nil {
+		return nil, nil, err
+	}
+	return reply.Value, reply.Proof, nil
+}
+
+//Close closes the connection to signer daemon
+func (this *Client) Close() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.client == nil {
+		return nil
+	}
+	err := this.client.Close()
+	this.client = nil
+	return err
+}
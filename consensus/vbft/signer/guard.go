@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/polynetwork/poly/consensus/vbft"
+)
+
+//signedBlock is a block signed at the height of record
+type signedBlock struct {
+	Proposer uint32 `json:"proposer"`
+	ForEmpty bool   `json:"for_empty"`
+	Hash     string `json:"hash"`
+}
+
+//signRecord is the highest height signed for a step and the blocks signed at the height
+type signRecord struct {
+	Height uint32         `json:"height"`
+	Blocks []*signedBlock `json:"blocks"`
+}
+
+//Guard keeps a persistent record of the highest block signed for each consensus step. It refuses to sign below the
+//recorded height, or a different hash for the same proposer at the recorded height, so a restarted or duplicated
+//node can not make the key double sign.
+type Guard struct {
+	lock    sync.Mutex
+	path    string
+	records map[vbft.SignStep]*signRecord
+}
+
+//OpenGuard loads the sign records from file of path, the file is created at the first signing if it doesn't exist
+func OpenGuard(path string) (*Guard, error) {
+	guard := &Guard{path: path, records: make(map[vbft.SignStep]*signRecord)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return guard, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sign records error: %s", err)
+	}
+	if err := json.Unmarshal(data, &guard.records); err != nil {
+		return nil, fmt.Errorf("unmarshal sign records error: %s", err)
+	}
+	return guard, nil
+}
+
+//Check records the block of request, it returns an error if signing the block may be a double sign. The record is
+//persisted before Check returns, so the signature is never released without it.
+func (this *Guard) Check(req *vbft.BlockSignRequest) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	hash := req.Hash.ToHexString()
+	record := this.records[req.Step]
+	if record != nil {
+		if req.Height < record.Height {
+			return fmt.Errorf("%s of height %d is below signed height %d", req.Step, req.Height, record.Height)
+		}
+		if req.Height == record.Height {
+			for _, block := range record.Blocks {
+				if block.Proposer != req.Proposer || block.ForEmpty != req.ForEmpty {
+					continue
+				}
+				if block.Hash != hash {
+					return fmt.Errorf("%s of height %d proposer %d has signed block %s, refuse to sign %s", req.Step,
+						req.Height, req.Proposer, block.Hash, hash)
+				}
+				return nil
+			}
+		}
+	}
+
+	next := &signRecord{Height: req.Height}
+	if record != nil && record.Height == req.Height {
+		next.Blocks = append(next.Blocks, record.Blocks...)
+	}
+	next.Blocks = append(next.Blocks, &signedBlock{Proposer: req.Proposer, ForEmpty: req.ForEmpty, Hash: hash})
+	this.records[req.Step] = next
+	if err := this.save(); err != nil {
+		this.records[req.Step] = record
+		return err
+	}
+	return nil
+}
+
+//save writes the records to a temporary file and renames it, so the file is never left half written
+func (this *Guard) save() error {
+	data, err := json.MarshalIndent(this.records, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal sign records error: %s", err)
+	}
+	tmp := this.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("open sign records file error: %s", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write sign records error: %s", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync sign records error: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close sign records file error: %s", err)
+	}
+	if err := os.Rename(tmp, this.path); err != nil {
+		return fmt.Errorf("rename sign records file error: %s", err)
+	}
+	return nil
+}
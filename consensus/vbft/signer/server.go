@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/consensus/vbft"
+)
+
+const SERVICE_NAME = "Signer"
+
+//VrfRequest is the block to compute vrf for
+type VrfRequest struct {
+	BlockNum uint32
+	PrevVrf  []byte
+}
+
+//VrfResponse is the vrf value and proof of block
+type VrfResponse struct {
+	Value []byte
+	Proof []byte
+}
+
+//Service is the json rpc service of signer daemon
+type Service struct {
+	signer vbft.Signer
+	guard  *Guard
+}
+
+//NewService returns the service signing with signer, blocks are checked by guard before signing
+func NewService(signer vbft.Signer, guard *Guard) *Service {
+	return &Service{signer: signer, guard: guard}
+}
+
+//PublicKey returns the serialized public key of signer
+func (this *Service) PublicKey(_ uint32, reply *[]byte) error {
+	*reply = keypair.SerializePublicKey(this.signer.PublicKey())
+	return nil
+}
+
+func (this *Service) SignBlock(req *vbft.BlockSignRequest, reply *[]byte) error {
+	if err := this.guard.Check(req); err != nil {
+		log.Warnf("signer refuse to sign block: %s", err)
+		return err
+	}
+	sig, err := this.signer.SignBlock(req)
+	if err != nil {
+		return err
+	}
+	log.Infof("signer signed %s of height %d proposer %d, block %s", req.Step, req.Height, req.Proposer,
+		req.Hash.ToHexString())
+	*reply = sig
+	return nil
+}
+
+//SignMsg signs the consensus payload of a vbft message, blocks signed in the message are checked by guard as SignBlock
+func (this *Service) SignMsg(req *vbft.MsgSignRequest, reply *[]byte) error {
+	blocks, err := req.Blocks(this.signer.PublicKey())
+	if err != nil {
+		log.Warnf("signer refuse to sign msg of type %d: %s", req.Type, err)
+		return err
+	}
+	for _, block := range blocks {
+		if err := this.guard.Check(block); err != nil {
+			log.Warnf("signer refuse to sign msg of type %d: %s", req.Type, err)
+			return err
+		}
+	}
+	sig, err := this.signer.SignMsg(req)
+	if err != nil {
+		return err
+	}
+	*reply = sig
+	return nil
+}
+
+func (this *Service) Vrf(req *VrfRequest, reply *VrfResponse) error {
+	value, proof, err := this.signer.Vrf(req.BlockNum, req.PrevVrf)
+	if err != nil {
+		return err
+	}
+	reply.Value, reply.Proof = value, proof
+	return nil
+}
+
+//Serve accepts connections of listener and serves them with service until listener is closed
+func Serve(listener net.Listener, service *Service) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(SERVICE_NAME, service); err != nil {
+		return fmt.Errorf("register signer service error: %s", err)
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		log.Infof("signer accepted connection from %s", conn.RemoteAddr())
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/consensus/vbft"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func blockRequest(step vbft.SignStep, height, proposer uint32, forEmpty bool, hash string) *vbft.BlockSignRequest {
+	return &vbft.BlockSignRequest{
+		Step:     step,
+		Height:   height,
+		Proposer: proposer,
+		ForEmpty: forEmpty,
+		Hash:     common.Uint256(sha256Sum(hash)),
+	}
+}
+
+func sha256Sum(data string) [32]byte {
+	var hash [32]byte
+	copy(hash[:], data)
+	return hash
+}
+
+type endorseMsg struct {
+	Endorser          uint32         `json:"endorser"`
+	EndorsedProposer  uint32         `json:"endorsed_proposer"`
+	BlockNum          uint32         `json:"block_num"`
+	EndorsedBlockHash common.Uint256 `json:"endorsed_block_hash"`
+	EndorseForEmpty   bool           `json:"endorse_for_empty"`
+}
+
+type commitMsg struct {
+	Committer       uint32         `json:"committer"`
+	BlockProposer   uint32         `json:"block_proposer"`
+	BlockNum        uint32         `json:"block_num"`
+	CommitBlockHash common.Uint256 `json:"commit_block_hash"`
+	CommitForEmpty  bool           `json:"commit_for_empty"`
+}
+
+type fetchMsg struct {
+	BlockNum uint32 `json:"block_num"`
+}
+
+//msgRequest serializes msg in the vbft msg envelope of type
+func msgRequest(t *testing.T, msgType vbft.MsgType, msg interface{}) *vbft.MsgSignRequest {
+	payload, err := json.Marshal(msg)
+	assert.Nil(t, err)
+	data, err := json.Marshal(&vbft.ConsensusMsgPayload{Type: msgType, Len: uint32(len(payload)), Payload: payload})
+	assert.Nil(t, err)
+	return &vbft.MsgSignRequest{Type: msgType, Msg: data}
+}
+
+func TestGuard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	guard, err := OpenGuard(path)
+	assert.Nil(t, err)
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 1, false, "a")))
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 1, false, "a")))
+	assert.NotNil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 1, false, "b")))
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 1, true, "c")))
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 2, false, "b")))
+	assert.NotNil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 9, 1, false, "d")))
+	//steps are recorded separately
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_COMMIT, 9, 1, false, "d")))
+
+	//records are kept after restart
+	guard, err = OpenGuard(path)
+	assert.Nil(t, err)
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 2, false, "b")))
+	assert.NotNil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 1, false, "b")))
+	assert.NotNil(t, guard.Check(blockRequest(vbft.SIGN_COMMIT, 9, 1, false, "e")))
+	assert.NotNil(t, guard.Check(blockRequest(vbft.SIGN_COMMIT, 8, 1, false, "d")))
+	assert.Nil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 11, 1, false, "b")))
+	assert.NotNil(t, guard.Check(blockRequest(vbft.SIGN_ENDORSE, 10, 2, false, "b")))
+
+	ioutil.WriteFile(path, []byte("{"), 0600)
+	_, err = OpenGuard(path)
+	assert.NotNil(t, err)
+}
+
+//connTracker records accepted connections, so that stopping the signer drops its clients like a restart does
+type connTracker struct {
+	net.Listener
+	lock  sync.Mutex
+	conns []net.Conn
+}
+
+func (this *connTracker) Accept() (net.Conn, error) {
+	conn, err := this.Listener.Accept()
+	if err == nil {
+		this.lock.Lock()
+		this.conns = append(this.conns, conn)
+		this.lock.Unlock()
+	}
+	return conn, err
+}
+
+func (this *connTracker) Close() error {
+	err := this.Listener.Close()
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for _, conn := range this.conns {
+		conn.Close()
+	}
+	return err
+}
+
+func startSigner(t *testing.T, address string, tlsConfig *tls.Config, acc *account.Account, statePath string) func() {
+	guard, err := OpenGuard(statePath)
+	assert.Nil(t, err)
+	listener, err := Listen(address, tlsConfig)
+	assert.Nil(t, err)
+	tracker := &connTracker{Listener: listener}
+	go Serve(tracker, NewService(vbft.NewLocalSigner(acc), guard))
+	return func() { tracker.Close() }
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	acc := account.NewAccount("SHA256withECDSA")
+	address := UNIX_PREFIX + filepath.Join(dir, "signer.sock")
+	stop := startSigner(t, address, nil, acc, filepath.Join(dir, "state.json"))
+
+	client, err := Dial(address, nil)
+	assert.Nil(t, err)
+	defer client.Close()
+	assert.Equal(t, acc.PublicKey, client.PublicKey())
+
+	req := blockRequest(vbft.SIGN_PROPOSAL, 5, 0, false, "block")
+	sig, err := client.SignBlock(req)
+	assert.Nil(t, err)
+	assert.Nil(t, signature.Verify(acc.PublicKey, req.Hash[:], sig))
+	_, err = client.SignBlock(blockRequest(vbft.SIGN_PROPOSAL, 5, 0, false, "other block"))
+	assert.NotNil(t, err)
+
+	//endorsement is guarded as signing its block, other msgs are signed if they are of the request type
+	endorse := msgRequest(t, vbft.BlockEndorseMessage, &endorseMsg{BlockNum: 5, EndorsedBlockHash: sha256Sum("block")})
+	sig, err = client.SignMsg(endorse)
+	assert.Nil(t, err)
+	_, data, err := endorse.Payload(acc.PublicKey)
+	assert.Nil(t, err)
+	assert.Nil(t, signature.Verify(acc.PublicKey, data, sig))
+	_, err = client.SignMsg(msgRequest(t, vbft.BlockEndorseMessage, &endorseMsg{BlockNum: 5,
+		EndorsedBlockHash: sha256Sum("other block")}))
+	assert.NotNil(t, err)
+	_, err = client.SignMsg(msgRequest(t, vbft.BlockCommitMessage, &commitMsg{BlockNum: 5,
+		CommitBlockHash: sha256Sum("block")}))
+	assert.Nil(t, err)
+	_, err = client.SignMsg(msgRequest(t, vbft.BlockCommitMessage, &commitMsg{BlockNum: 4,
+		CommitBlockHash: sha256Sum("block")}))
+	assert.NotNil(t, err)
+	payload := msgRequest(t, vbft.BlockFetchMessage, &fetchMsg{BlockNum: 5})
+	_, err = client.SignMsg(payload)
+	assert.Nil(t, err)
+	_, err = client.SignMsg(&vbft.MsgSignRequest{Type: vbft.BlockEndorseMessage, Msg: payload.Msg})
+	assert.NotNil(t, err)
+	_, err = client.SignMsg(&vbft.MsgSignRequest{Type: vbft.BlockFetchMessage, Msg: req.Hash[:]})
+	assert.NotNil(t, err)
+
+	value, proof, err := client.Vrf(5, []byte("prev vrf"))
+	assert.Nil(t, err)
+	localValue, _, err := vbft.NewLocalSigner(acc).Vrf(5, []byte("prev vrf"))
+	assert.Nil(t, err)
+	assert.Equal(t, localValue, value)
+	assert.NotEmpty(t, proof)
+
+	//client reconnects after signer restarts, and the record survives the restart
+	stop()
+	_, err = client.SignMsg(payload)
+	assert.NotNil(t, err)
+	stop = startSigner(t, address, nil, acc, filepath.Join(dir, "state.json"))
+	defer stop()
+	_, err = client.SignMsg(payload)
+	assert.Nil(t, err)
+	_, err = client.SignBlock(blockRequest(vbft.SIGN_PROPOSAL, 5, 0, false, "other block"))
+	assert.NotNil(t, err)
+	_, err = client.SignBlock(blockRequest(vbft.SIGN_PROPOSAL, 6, 0, false, "other block"))
+	assert.Nil(t, err)
+}
+
+//writeCert writes a certificate signed by parent, or a self signed one if parent is nil
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+	}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return cert, key
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "server", ca, caKey)
+	writeCert(t, dir, "client", ca, caKey)
+	writeCert(t, dir, "other", nil, nil)
+	file := func(name string) string { return filepath.Join(dir, name) }
+
+	serverConfig, err := LoadTLSConfig(file("server.crt"), file("server.key"), file("ca.crt"), true)
+	assert.Nil(t, err)
+	_, err = Listen("127.0.0.1:0", nil)
+	assert.NotNil(t, err)
+	listener, err := Listen("127.0.0.1:0", serverConfig)
+	assert.Nil(t, err)
+	defer listener.Close()
+	acc := account.NewAccount("SHA256withECDSA")
+	guard, err := OpenGuard(file("state.json"))
+	assert.Nil(t, err)
+	go Serve(listener, NewService(vbft.NewLocalSigner(acc), guard))
+	address := "localhost:" + listener.Addr().String()[len("127.0.0.1:"):]
+
+	clientConfig, err := LoadTLSConfig(file("client.crt"), file("client.key"), file("ca.crt"), false)
+	assert.Nil(t, err)
+	client, err := Dial(address, clientConfig)
+	assert.Nil(t, err)
+	defer client.Close()
+	assert.Equal(t, acc.PublicKey, client.PublicKey())
+
+	//client certificate not signed by the ca is rejected
+	otherConfig, err := LoadTLSConfig(file("other.crt"), file("other.key"), file("ca.crt"), false)
+	assert.Nil(t, err)
+	_, err = Dial(address, otherConfig)
+	assert.NotNil(t, err)
+	_, err = Dial(address, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	UNIX_PREFIX  = "unix:"
+	DIAL_TIMEOUT = 10 * time.Second
+)
+
+//LoadTLSConfig loads the certificate of this side and the CA which signs certificates of the other side. The server
+//requires and verifies client certificates, so the connection is mutually authenticated.
+func LoadTLSConfig(certFile, keyFile, caFile string, server bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load key pair error: %s", err)
+	}
+	caData, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca file error: %s", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caData) {
+		return nil, fmt.Errorf("no certificate in ca file %s", caFile)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if server {
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+//Listen listens on "unix:<path>" or a tcp address, tcp connections must be secured by mutual tls
+func Listen(address string, tlsConfig *tls.Config) (net.Listener, error) {
+	if strings.HasPrefix(address, UNIX_PREFIX) {
+		path := strings.TrimPrefix(address, UNIX_PREFIX)
+		//remove the socket left by last run
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("remove socket file error: %s", err)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("chmod socket file error: %s", err)
+		}
+		return listener, nil
+	}
+	if tlsConfig == nil {
+		return nil, fmt.Errorf("tls config is required to listen on tcp address %s", address)
+	}
+	return tls.Listen("tcp", address, tlsConfig)
+}
+
+func dial(address string, tlsConfig *tls.Config) (net.Conn, error) {
+	if strings.HasPrefix(address, UNIX_PREFIX) {
+		return net.DialTimeout("unix", strings.TrimPrefix(address, UNIX_PREFIX), DIAL_TIMEOUT)
+	}
+	if tlsConfig == nil {
+		return nil, fmt.Errorf("tls config is required to dial tcp address %s", address)
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: DIAL_TIMEOUT}, "tcp", address, tlsConfig)
+}
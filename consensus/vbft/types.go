@@ -58,6 +58,10 @@ func (blk *Block) getPrevBlockCrossStateRoot() common.Uint256 {
 	return blk.Block.Header.CrossStateRoot
 }
 
+func (blk *Block) getPrevBlockStateRoot() []byte {
+	return blk.Info.StateRoot
+}
+
 //
 // getVrfValue() is a helper function for participant selection.
 //
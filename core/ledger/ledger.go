@@ -21,11 +21,15 @@ package ledger
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"time"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/ledgerstore"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native/event"
@@ -88,6 +92,10 @@ func (self *Ledger) GetCrossStateRoot(height uint32) (common.Uint256, error) {
 	return self.ldgStore.GetCrossStateRoot(height)
 }
 
+func (self *Ledger) GetStateRoot(height uint32) (common.Uint256, error) {
+	return self.ldgStore.GetStateRoot(height)
+}
+
 func (self *Ledger) GetBlockRootWithPreBlockHashes(startHeight uint32, txRoots []common.Uint256) common.Uint256 {
 	return self.ldgStore.GetBlockRootWithPreBlockHashes(startHeight, txRoots)
 }
@@ -165,6 +173,18 @@ func (self *Ledger) GetStorageItem(codeHash common.Address, key []byte) ([]byte,
 	return storageItem.Value, nil
 }
 
+func (self *Ledger) GetStorageItemAt(codeHash common.Address, key []byte, height uint32) ([]byte, error) {
+	storageKey := &states.StorageKey{
+		ContractAddress: codeHash,
+		Key:             key,
+	}
+	storageItem, err := self.ldgStore.GetStorageItemAt(storageKey, height)
+	if err != nil {
+		return nil, err
+	}
+	return storageItem.Value, nil
+}
+
 func (self *Ledger) GetMerkleProof(proofHeight, rootHeight uint32) ([]byte, error) {
 	blockHash := self.ldgStore.GetBlockHash(proofHeight)
 	if bytes.Equal(blockHash.ToArray(), common.UINT256_EMPTY.ToArray()) {
@@ -177,10 +197,18 @@ func (self *Ledger) GetCrossStatesProof(height uint32, key []byte) ([]byte, erro
 	return self.ldgStore.GetCrossStatesProof(height, key)
 }
 
+func (self *Ledger) GetStorageProof(height uint32, key []byte) (common.Uint256, []byte, []byte, error) {
+	return self.ldgStore.GetStorageProof(height, key)
+}
+
 func (self *Ledger) PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
 	return self.ldgStore.PreExecuteContract(tx)
 }
 
+func (self *Ledger) PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractAt(tx, height)
+}
+
 func (self *Ledger) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return self.ldgStore.GetEventNotifyByTx(tx)
 }
@@ -189,6 +217,38 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) GetCrossTx(fromChainID uint64, id []byte) (*scom.CrossTx, error) {
+	return self.ldgStore.GetCrossTx(fromChainID, id)
+}
+
+func (self *Ledger) ListCrossTxs(fromChainID uint64, height uint32, crossChainID []byte, limit uint32) ([]*scom.CrossTx, error) {
+	return self.ldgStore.ListCrossTxs(fromChainID, height, crossChainID, limit)
+}
+
+func (self *Ledger) GetEventLogTxs(address *common.Address, topic *common.Uint256, startHeight, endHeight, limit uint32) ([]*scom.EventLogTx, error) {
+	return self.ldgStore.GetEventLogTxs(address, topic, startHeight, endHeight, limit)
+}
+
+func (self *Ledger) GetPruneHeight() uint32 {
+	return self.ldgStore.GetPruneHeight()
+}
+
+func (self *Ledger) PruneBlocks(height uint32) error {
+	return self.ldgStore.PruneBlocks(height)
+}
+
+func (self *Ledger) ExportSnapshot(w io.Writer) (*scom.SnapshotInfo, error) {
+	return self.ldgStore.ExportSnapshot(w)
+}
+
+func (self *Ledger) ImportSnapshot(r io.Reader, genesisBlock *types.Block, nextHeader *types.Header) (*scom.SnapshotInfo, error) {
+	return self.ldgStore.ImportSnapshot(r, genesisBlock, nextHeader)
+}
+
+func (self *Ledger) ReplayBlock(height uint32, clockSkew time.Duration) (*scom.BlockReplay, error) {
+	return self.ldgStore.ReplayBlock(height, clockSkew)
+}
+
 func (self *Ledger) Close() error {
 	return self.ldgStore.Close()
 }
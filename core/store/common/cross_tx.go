@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+//Status of a cross chain transaction on poly
+type CrossTxStatus byte
+
+const (
+	CROSS_TX_IMPORTED CrossTxStatus = 1 //source chain transaction is verified and marked done on poly
+	CROSS_TX_PROVED   CrossTxStatus = 2 //request to target chain is made, the proof is available at ProofHeight
+)
+
+func (s CrossTxStatus) String() string {
+	switch s {
+	case CROSS_TX_IMPORTED:
+		return "imported"
+	case CROSS_TX_PROVED:
+		return "proved"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+//CrossTx is the lifecycle record of a cross chain transaction, indexed by source chain id and cross chain id
+type CrossTx struct {
+	FromChainID  uint64
+	CrossChainID []byte
+	SourceTxHash []byte //tx hash in MakeTxParam, set when proved
+	ToChainID    uint64
+	PolyTxHash   common.Uint256
+	Height       uint32 //height the tx first seen on poly
+	ProofHeight  uint32 //height to query getcrossstatesproof with RequestKey
+	RequestKey   []byte
+	Status       CrossTxStatus
+}
+
+//Merge update this with a later record of the same cross chain transaction
+func (this *CrossTx) Merge(other *CrossTx) {
+	if this.Height == 0 || (other.Height != 0 && other.Height < this.Height) {
+		this.Height = other.Height
+	}
+	if other.Status < this.Status {
+		return
+	}
+	this.Status = other.Status
+	this.PolyTxHash = other.PolyTxHash
+	if other.Status == CROSS_TX_PROVED {
+		this.SourceTxHash = other.SourceTxHash
+		this.ToChainID = other.ToChainID
+		this.ProofHeight = other.ProofHeight
+		this.RequestKey = other.RequestKey
+	}
+}
+
+func (this *CrossTx) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.FromChainID)
+	sink.WriteVarBytes(this.CrossChainID)
+	sink.WriteVarBytes(this.SourceTxHash)
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteHash(this.PolyTxHash)
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.ProofHeight)
+	sink.WriteVarBytes(this.RequestKey)
+	sink.WriteByte(byte(this.Status))
+}
+
+func (this *CrossTx) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if this.FromChainID, eof = source.NextUint64(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read from chain id error")
+	}
+	if this.CrossChainID, eof = source.NextVarBytes(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read cross chain id error")
+	}
+	if this.SourceTxHash, eof = source.NextVarBytes(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read source tx hash error")
+	}
+	if this.ToChainID, eof = source.NextUint64(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read to chain id error")
+	}
+	if this.PolyTxHash, eof = source.NextHash(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read poly tx hash error")
+	}
+	if this.Height, eof = source.NextUint32(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read height error")
+	}
+	if this.ProofHeight, eof = source.NextUint32(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read proof height error")
+	}
+	if this.RequestKey, eof = source.NextVarBytes(); eof {
+		return fmt.Errorf("CrossTx.Deserialization, read request key error")
+	}
+	status, eof := source.NextByte()
+	if eof {
+		return fmt.Errorf("CrossTx.Deserialization, read status error")
+	}
+	this.Status = CrossTxStatus(status)
+	return nil
+}
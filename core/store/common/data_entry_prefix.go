@@ -27,6 +27,8 @@ const (
 	DATA_HEADER                            = 0x01 //Block hash => block hash key prefix
 	DATA_TRANSACTION                       = 0x02 //Transction hash = > transaction key prefix
 	DATA_STATE_MERKLE_ROOT                 = 0x21 // block height => write set hash + state merkle root
+	DATA_STATE_TRIE_ROOT                   = 0x25 // block height => state trie root
+	DATA_STATE_HISTORY                     = 0x26 // key + block height => value of key before the block, kept in archive mode
 
 	// Transaction
 	ST_BOOKKEEPER DataEntryPrefix = 0x03 //BookKeeper state key prefix
@@ -35,6 +37,9 @@ const (
 	ST_VALIDATOR  DataEntryPrefix = 0x07 //no use
 	ST_VOTE       DataEntryPrefix = 0x08 //Vote state key prefix
 
+	ST_STATE_TRIE     DataEntryPrefix = 0x24 //State trie node hash => trie node
+	ST_STATE_TRIE_REF DataEntryPrefix = 0x29 //State trie node hash => reference count of trie node
+
 	IX_HEADER_HASH_LIST DataEntryPrefix = 0x09 //Block height => block hash key prefix
 
 	//SYSTEM
@@ -45,6 +50,15 @@ const (
 	SYS_STATE_MERKLE_TREE  DataEntryPrefix = 0x20 // state merkle tree root key prefix
 	SYS_CROSS_STATES       DataEntryPrefix = 0x22
 	SYS_CROSS_STATES_HASH  DataEntryPrefix = 0x23
+	SYS_STATE_HISTORY      DataEntryPrefix = 0x27 // height range of the state history
+	SYS_BLOCK_PRUNE_HEIGHT DataEntryPrefix = 0x28 // transactions and events of blocks below the height are pruned
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
+
+	IX_CROSS_TX        DataEntryPrefix = 0x15 //Source chain id + cross chain id => cross chain tx
+	IX_CROSS_TX_SOURCE DataEntryPrefix = 0x16 //Source chain id + source tx hash => cross chain id
+	IX_CROSS_TX_LIST   DataEntryPrefix = 0x17 //Source chain id + height + cross chain id => nil, cross chain txs of a chain in height order
+
+	IX_EVENT_LOG_ADDRESS DataEntryPrefix = 0x18 //Contract address + height + tx hash => nil, txs with notify of contract
+	IX_EVENT_LOG_TOPIC   DataEntryPrefix = 0x19 //Event topic + height + tx hash => nil, txs with notify of topic
 )
@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"golang.org/x/crypto/sha3"
+
+	"github.com/polynetwork/poly/common"
+)
+
+//EventLogTx is a transaction whose notify matches an event log query
+type EventLogTx struct {
+	Height uint32
+	TxHash common.Uint256
+}
+
+//EventLogTopic return the topic of a notify, which is the keccak256 hash of the event name in States[0].
+//Notify without event name has no topic.
+func EventLogTopic(states interface{}) (common.Uint256, bool) {
+	items, ok := states.([]interface{})
+	if !ok || len(items) == 0 {
+		return common.UINT256_EMPTY, false
+	}
+	name, ok := items[0].(string)
+	if !ok {
+		return common.UINT256_EMPTY, false
+	}
+	var topic common.Uint256
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(name))
+	hasher.Sum(topic[:0])
+	return topic, true
+}
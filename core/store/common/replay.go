@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+//ReplayDiff is the first key of a block write set with different values in two executions of the block
+type ReplayDiff struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"` //Empty if the key not exists
+	Actual   string `json:"actual"`   //Empty if the key not exists
+	TxIndex  int    `json:"txIndex"`  //Index of the last tx changing the key in the replay, -1 if no tx of the replay changed it
+	TxHash   string `json:"txHash,omitempty"`
+}
+
+//ReplayTxDiff is the first transaction of a block with different execution states in two executions of the block
+type ReplayTxDiff struct {
+	TxIndex  int    `json:"txIndex"`
+	TxHash   string `json:"txHash"`
+	Expected byte   `json:"expected"`
+	Actual   byte   `json:"actual"`
+}
+
+//BlockReplay is the result of re-executing a block on the state before it
+type BlockReplay struct {
+	Height                  uint32        `json:"height"`
+	TxCount                 int           `json:"txCount"`
+	WriteSetHash            string        `json:"writeSetHash"`
+	ExpectedWriteSetHash    string        `json:"expectedWriteSetHash,omitempty"`
+	CrossStatesRoot         string        `json:"crossStatesRoot"`
+	ExpectedCrossStatesRoot string        `json:"expectedCrossStatesRoot"`
+	Diverged                bool          `json:"diverged"`
+	FirstDiff               *ReplayDiff   `json:"firstDiff,omitempty"`
+	FirstTxDiff             *ReplayTxDiff `json:"firstTxDiff,omitempty"`
+	Message                 string        `json:"message,omitempty"`
+	ClockDependent          bool          `json:"clockDependent,omitempty"`
+	ClockDiff               *ReplayDiff   `json:"clockDiff,omitempty"`
+	ClockTxDiff             *ReplayTxDiff `json:"clockTxDiff,omitempty"`
+}
@@ -42,16 +42,17 @@ type StoreIterator interface {
 
 //PersistStore of ledger
 type PersistStore interface {
-	Put(key []byte, value []byte) error      //Put the key-value pair to store
-	Has(key []byte) (bool, error)            //Whether the key is exist in store
-	Get(key []byte) ([]byte, error)          //Get the value if key in store
-	Delete(key []byte) error                 //Delete the key in store
-	NewBatch()                               //Start commit batch
-	BatchPut(key []byte, value []byte)       //Put a key-value pair to batch
-	BatchDelete(key []byte)                  //Delete the key in batch
-	BatchCommit() error                      //Commit batch to store
-	Close() error                            //Close store
-	NewIterator(prefix []byte) StoreIterator //Return the iterator of store
+	Put(key []byte, value []byte) error                         //Put the key-value pair to store
+	Has(key []byte) (bool, error)                               //Whether the key is exist in store
+	Get(key []byte) ([]byte, error)                             //Get the value if key in store
+	Delete(key []byte) error                                    //Delete the key in store
+	NewBatch()                                                  //Start commit batch
+	BatchPut(key []byte, value []byte)                          //Put a key-value pair to batch
+	BatchDelete(key []byte)                                     //Delete the key in batch
+	BatchCommit() error                                         //Commit batch to store
+	Close() error                                               //Close store
+	NewIterator(prefix []byte) StoreIterator                    //Return the iterator of store
+	NewRangeIterator(prefix []byte, start []byte) StoreIterator //Return the iterator of store with key prefix, starting from key start
 }
 
 //StateStore save result of smart contract execution, before commit to store
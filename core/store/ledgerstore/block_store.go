@@ -356,6 +356,10 @@ func (this *BlockStore) loadTransaction(txHash common.Uint256) (*types.Transacti
 	if eof {
 		return nil, 0, io.ErrUnexpectedEOF
 	}
+	// only the height is kept for the transaction of pruned block
+	if source.Len() == 0 {
+		return nil, 0, scom.ErrNotFound
+	}
 	tx = new(types.Transaction)
 	err = tx.Deserialization(source)
 	if err != nil {
@@ -400,6 +404,53 @@ func (this *BlockStore) SaveVersion(ver byte) error {
 	return this.store.Put(key, []byte{ver})
 }
 
+//GetPruneHeight return the height below which the transactions of blocks are pruned
+func (this *BlockStore) GetPruneHeight() (uint32, error) {
+	value, err := this.store.Get(this.getPruneHeightKey())
+	if err == scom.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(value) != 4 {
+		return 0, fmt.Errorf("invalid prune height %x", value)
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+//SavePruneHeight persist the prune height to store
+func (this *BlockStore) SavePruneHeight(height uint32) {
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, height)
+	this.store.BatchPut(this.getPruneHeightKey(), value)
+}
+
+//PruneBlock delete the transactions of block, only the header and the height of the transactions are kept,
+//so that ContainTransaction still reports the pruned transactions for replay protection
+func (this *BlockStore) PruneBlock(blockHash common.Uint256) ([]common.Uint256, error) {
+	header, txHashes, err := this.loadHeaderWithTx(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if this.enableCache {
+		this.cache.RemoveBlock(blockHash)
+	}
+	height := make([]byte, 4)
+	binary.LittleEndian.PutUint32(height, header.Height)
+	for _, txHash := range txHashes {
+		if this.enableCache {
+			this.cache.RemoveTransaction(txHash)
+		}
+		this.store.BatchPut(this.getTransactionKey(txHash), height)
+	}
+	err = this.SaveHeader(&types.Block{Header: header})
+	if err != nil {
+		return nil, err
+	}
+	return txHashes, nil
+}
+
 //ClearAll clear all the data of block store
 func (this *BlockStore) ClearAll() error {
 	this.NewBatch()
@@ -458,6 +509,10 @@ func (this *BlockStore) getVersionKey() []byte {
 	return []byte{byte(scom.SYS_VERSION)}
 }
 
+func (this *BlockStore) getPruneHeightKey() []byte {
+	return []byte{byte(scom.SYS_BLOCK_PRUNE_HEIGHT)}
+}
+
 func (this *BlockStore) getHeaderIndexListKey(startHeight uint32) []byte {
 	key := bytes.NewBuffer(nil)
 	key.WriteByte(byte(scom.IX_HEADER_HASH_LIST))
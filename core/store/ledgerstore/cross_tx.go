@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/states"
+	scom "github.com/polynetwork/poly/core/store/common"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+var (
+	doneTxKeyPrefix  = crossChainStorageKey(ccom.DONE_TX)
+	requestKeyPrefix = crossChainStorageKey(ccom.REQUEST)
+)
+
+func crossChainStorageKey(name string) []byte {
+	return append([]byte{byte(scom.ST_STORAGE)}, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(name))...)
+}
+
+//crossTxsOfTx parse the cross chain txs from the storage written by a successful transaction.
+//A source chain tx is marked in doneTx when imported, and the request to target chain is saved in request with the
+//ToMerkleValue committed to cross states.
+func crossTxsOfTx(txHash common.Uint256, height uint32, cache *storage.CacheDB) []*scom.CrossTx {
+	txs := make([]*scom.CrossTx, 0)
+	cache.ForEach(func(key, val []byte) {
+		if len(val) == 0 {
+			return
+		}
+		switch {
+		case bytes.HasPrefix(key, doneTxKeyPrefix) && len(key) > len(doneTxKeyPrefix)+8:
+			suffix := key[len(doneTxKeyPrefix):]
+			txs = append(txs, &scom.CrossTx{
+				FromChainID:  binary.LittleEndian.Uint64(suffix[:8]),
+				CrossChainID: append([]byte{}, suffix[8:]...),
+				PolyTxHash:   txHash,
+				Height:       height,
+				Status:       scom.CROSS_TX_IMPORTED,
+			})
+		case bytes.HasPrefix(key, requestKeyPrefix):
+			raw, err := states.GetValueFromRawStorageItem(val)
+			if err != nil {
+				return
+			}
+			// values in cache are reused by the next transaction
+			value := new(ccom.ToMerkleValue)
+			if err := value.Deserialization(common.NewZeroCopySource(append([]byte{}, raw...))); err != nil {
+				return
+			}
+			txs = append(txs, &scom.CrossTx{
+				FromChainID:  value.FromChainID,
+				CrossChainID: value.MakeTxParam.CrossChainID,
+				SourceTxHash: value.MakeTxParam.TxHash,
+				ToChainID:    value.MakeTxParam.ToChainID,
+				PolyTxHash:   txHash,
+				Height:       height,
+				ProofHeight:  height,
+				RequestKey:   append([]byte{}, key[1:]...),
+				Status:       scom.CROSS_TX_PROVED,
+			})
+		}
+	})
+	return txs
+}
+
+//mergeCrossTxs merge the records of the same cross chain tx in a block, keeping the order they first appear
+func mergeCrossTxs(txs []*scom.CrossTx) []*scom.CrossTx {
+	merged := make([]*scom.CrossTx, 0, len(txs))
+	for _, tx := range txs {
+		found := false
+		for _, v := range merged {
+			if v.FromChainID == tx.FromChainID && bytes.Equal(v.CrossChainID, tx.CrossChainID) {
+				v.Merge(tx)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, tx)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/states"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossTxsOfTx(t *testing.T) {
+	contract := utils.CrossChainManagerContractAddress
+	cache := storage.NewCacheDB(overlaydb.NewOverlayDB(NewMemStateStore(0).store))
+	txHash := common.Uint256{9}
+
+	cache.Put(utils.ConcatKey(contract, []byte(ccom.DONE_TX), utils.GetUint64Bytes(2), []byte{1, 2}),
+		states.GenRawStorageItem([]byte{1, 2}))
+	value := &ccom.ToMerkleValue{
+		TxHash:      txHash[:],
+		FromChainID: 2,
+		MakeTxParam: &ccom.MakeTxParam{
+			TxHash:              []byte{0xaa},
+			CrossChainID:        []byte{1, 2},
+			FromContractAddress: []byte{1},
+			ToChainID:           3,
+			ToContractAddress:   []byte{2},
+			Method:              "unlock",
+			Args:                []byte{3},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	value.Serialization(sink)
+	requestKey := utils.ConcatKey(contract, []byte(ccom.REQUEST), utils.GetUint64Bytes(3), txHash[:])
+	cache.Put(requestKey, states.GenRawStorageItem(sink.Bytes()))
+	cache.Put(utils.ConcatKey(common.Address{1}, []byte(ccom.REQUEST)), states.GenRawStorageItem([]byte{1}))
+
+	txs := mergeCrossTxs(crossTxsOfTx(txHash, 100, cache))
+	cache.Reset()
+	assert.Equal(t, 1, len(txs))
+	tx := txs[0]
+	assert.Equal(t, uint64(2), tx.FromChainID)
+	assert.Equal(t, []byte{1, 2}, tx.CrossChainID)
+	assert.Equal(t, []byte{0xaa}, tx.SourceTxHash)
+	assert.Equal(t, uint64(3), tx.ToChainID)
+	assert.Equal(t, txHash, tx.PolyTxHash)
+	assert.Equal(t, uint32(100), tx.ProofHeight)
+	assert.Equal(t, requestKey, tx.RequestKey)
+	assert.Equal(t, scom.CROSS_TX_PROVED, tx.Status)
+}
@@ -23,6 +23,8 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math"
+
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/common/serialization"
@@ -126,7 +128,7 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 		}
 		evtNotify, err := this.GetEventNotifyByTx(txHash)
 		if err != nil {
-			log.Errorf("getEventNotifyByTx Height:%d by txhash:%s error:%s", height, txHash.ToHexString(), err)
+			log.WithFields(log.Fields{"height": height, "txHash": txHash.ToHexString()}).Errorf("getEventNotifyByTx error:%s", err)
 			continue
 		}
 		evtNotifies = append(evtNotifies, evtNotify)
@@ -134,6 +136,200 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 	return evtNotifies, nil
 }
 
+//SaveCrossTx persist cross chain tx to store, merging with the record saved before
+func (this *EventStore) SaveCrossTx(tx *scom.CrossTx) error {
+	old, err := this.GetCrossTx(tx.FromChainID, tx.CrossChainID)
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if old != nil {
+		height := old.Height
+		old.Merge(tx)
+		tx = old
+		if tx.Height != height {
+			// the list is sorted by the height the tx first seen
+			this.store.BatchDelete(this.getCrossTxListKey(tx.FromChainID, height, tx.CrossChainID))
+			this.store.BatchPut(this.getCrossTxListKey(tx.FromChainID, tx.Height, tx.CrossChainID), nil)
+		}
+	} else {
+		this.store.BatchPut(this.getCrossTxListKey(tx.FromChainID, tx.Height, tx.CrossChainID), nil)
+	}
+	if len(tx.SourceTxHash) != 0 {
+		this.store.BatchPut(this.getCrossTxSourceKey(tx.FromChainID, tx.SourceTxHash), tx.CrossChainID)
+	}
+	sink := common.NewZeroCopySink(nil)
+	tx.Serialization(sink)
+	this.store.BatchPut(this.getCrossTxKey(tx.FromChainID, tx.CrossChainID), sink.Bytes())
+	return nil
+}
+
+//GetCrossTx return cross chain tx by source chain id and cross chain id
+func (this *EventStore) GetCrossTx(fromChainID uint64, crossChainID []byte) (*scom.CrossTx, error) {
+	data, err := this.store.Get(this.getCrossTxKey(fromChainID, crossChainID))
+	if err != nil {
+		return nil, err
+	}
+	tx := new(scom.CrossTx)
+	if err := tx.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+//GetCrossTxBySourceHash return cross chain tx by source chain id and the tx hash in MakeTxParam
+func (this *EventStore) GetCrossTxBySourceHash(fromChainID uint64, sourceTxHash []byte) (*scom.CrossTx, error) {
+	crossChainID, err := this.store.Get(this.getCrossTxSourceKey(fromChainID, sourceTxHash))
+	if err != nil {
+		return nil, err
+	}
+	return this.GetCrossTx(fromChainID, crossChainID)
+}
+
+//ListCrossTxs return at most limit cross chain txs of source chain in height order, starting after the tx of
+//height and crossChainID, which is the last tx of the previous page. The first page starts after height 0 and nil.
+func (this *EventStore) ListCrossTxs(fromChainID uint64, height uint32, crossChainID []byte, limit uint32) ([]*scom.CrossTx, error) {
+	prefix := this.getCrossTxListKey(fromChainID, 0, nil)[:9]
+	start := append(this.getCrossTxListKey(fromChainID, height, crossChainID), 0)
+	iter := this.store.NewRangeIterator(prefix, start)
+	defer iter.Release()
+	txs := make([]*scom.CrossTx, 0)
+	for iter.Next() && uint32(len(txs)) < limit {
+		key := iter.Key()
+		if len(key) < len(prefix)+4 {
+			return nil, fmt.Errorf("ListCrossTxs, invalid index key %x", key)
+		}
+		tx, err := this.GetCrossTx(fromChainID, key[len(prefix)+4:])
+		if err != nil {
+			return nil, fmt.Errorf("GetCrossTx error %s", err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, iter.Error()
+}
+
+//SaveEventLogs index the txs of block by the contract address and topic of their notifies
+func (this *EventStore) SaveEventLogs(height uint32, notifies []*event.ExecuteNotify) {
+	for _, notify := range notifies {
+		for _, key := range this.getEventLogKeys(height, notify) {
+			this.store.BatchPut(key, nil)
+		}
+	}
+}
+
+//getEventLogKeys return the index keys of the notifies of a tx at height
+func (this *EventStore) getEventLogKeys(height uint32, notify *event.ExecuteNotify) [][]byte {
+	if notify.State != event.CONTRACT_STATE_SUCCESS {
+		return nil
+	}
+	keys := make([][]byte, 0, len(notify.Notify))
+	for _, n := range notify.Notify {
+		keys = append(keys, this.getEventLogKey(byte(scom.IX_EVENT_LOG_ADDRESS), n.ContractAddress[:], height, notify.TxHash))
+		if topic, ok := scom.EventLogTopic(n.States); ok {
+			keys = append(keys, this.getEventLogKey(byte(scom.IX_EVENT_LOG_TOPIC), topic[:], height, notify.TxHash))
+		}
+	}
+	return keys
+}
+
+//GetEventLogTxs return at most limit txs in [startHeight, endHeight] with notify of contract address,
+//or of topic if address is nil, in height order
+func (this *EventStore) GetEventLogTxs(address *common.Address, topic *common.Uint256, startHeight, endHeight, limit uint32) ([]*scom.EventLogTx, error) {
+	var prefix []byte
+	switch {
+	case address != nil:
+		prefix = append([]byte{byte(scom.IX_EVENT_LOG_ADDRESS)}, address[:]...)
+	case topic != nil:
+		prefix = append([]byte{byte(scom.IX_EVENT_LOG_TOPIC)}, topic[:]...)
+	default:
+		return nil, fmt.Errorf("GetEventLogTxs, address or topic is required")
+	}
+	start := make([]byte, len(prefix)+4)
+	copy(start, prefix)
+	binary.BigEndian.PutUint32(start[len(prefix):], startHeight)
+	iter := this.store.NewRangeIterator(prefix, start)
+	defer iter.Release()
+	txs := make([]*scom.EventLogTx, 0)
+	for iter.Next() && uint32(len(txs)) < limit {
+		key := iter.Key()
+		if len(key) != len(prefix)+4+common.UINT256_SIZE {
+			return nil, fmt.Errorf("GetEventLogTxs, invalid index key %x", key)
+		}
+		tx := &scom.EventLogTx{Height: binary.BigEndian.Uint32(key[len(prefix):])}
+		if tx.Height > endHeight {
+			break
+		}
+		copy(tx.TxHash[:], key[len(prefix)+4:])
+		txs = append(txs, tx)
+	}
+	return txs, iter.Error()
+}
+
+//PruneEventNotify delete the event notifies of block and its transactions, with the event log indexes of them
+func (this *EventStore) PruneEventNotify(height uint32, txHashs []common.Uint256) error {
+	key, err := this.getEventNotifyByBlockKey(height)
+	if err != nil {
+		return err
+	}
+	this.store.BatchDelete(key)
+	for _, txHash := range txHashs {
+		notify, err := this.GetEventNotifyByTx(txHash)
+		if err != nil && err != scom.ErrNotFound {
+			return fmt.Errorf("GetEventNotifyByTx %s error %s", txHash.ToHexString(), err)
+		}
+		if notify != nil {
+			for _, key := range this.getEventLogKeys(height, notify) {
+				this.store.BatchDelete(key)
+			}
+		}
+		this.store.BatchDelete(this.getEventNotifyByTxKey(txHash))
+	}
+	return nil
+}
+
+//PruneCrossTxs delete the cross chain txs with indexes, which are first seen and proved below height
+func (this *EventStore) PruneCrossTxs(height uint32) error {
+	prefix := []byte{byte(scom.IX_CROSS_TX_LIST)}
+	start := prefix
+	for start != nil {
+		iter := this.store.NewRangeIterator(prefix, start)
+		start = nil
+		for iter.Next() {
+			key := iter.Key()
+			if len(key) < 13 {
+				iter.Release()
+				return fmt.Errorf("PruneCrossTxs, invalid index key %x", key)
+			}
+			fromChainID := binary.BigEndian.Uint64(key[1:9])
+			if binary.BigEndian.Uint32(key[9:13]) >= height {
+				// the list is in height order of each source chain, skip to the next chain
+				if fromChainID != math.MaxUint64 {
+					start = this.getCrossTxListKey(fromChainID+1, 0, nil)
+				}
+				break
+			}
+			crossChainID := append([]byte{}, key[13:]...)
+			tx, err := this.GetCrossTx(fromChainID, crossChainID)
+			if err != nil && err != scom.ErrNotFound {
+				iter.Release()
+				return fmt.Errorf("PruneCrossTxs, GetCrossTx error %s", err)
+			}
+			if tx != nil && tx.ProofHeight >= height {
+				continue
+			}
+			this.store.BatchDelete(append([]byte{}, key...))
+			this.store.BatchDelete(this.getCrossTxKey(fromChainID, crossChainID))
+			if tx != nil && len(tx.SourceTxHash) != 0 {
+				this.store.BatchDelete(this.getCrossTxSourceKey(fromChainID, tx.SourceTxHash))
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //CommitTo event store batch to store
 func (this *EventStore) CommitTo() error {
 	return this.store.BatchCommit()
@@ -200,6 +396,37 @@ func (this *EventStore) getEventNotifyByBlockKey(height uint32) ([]byte, error)
 	return key, nil
 }
 
+func (this *EventStore) getCrossTxKey(fromChainID uint64, crossChainID []byte) []byte {
+	key := make([]byte, 9, 9+len(crossChainID))
+	key[0] = byte(scom.IX_CROSS_TX)
+	binary.BigEndian.PutUint64(key[1:], fromChainID)
+	return append(key, crossChainID...)
+}
+
+func (this *EventStore) getCrossTxSourceKey(fromChainID uint64, sourceTxHash []byte) []byte {
+	key := make([]byte, 9, 9+len(sourceTxHash))
+	key[0] = byte(scom.IX_CROSS_TX_SOURCE)
+	binary.BigEndian.PutUint64(key[1:], fromChainID)
+	return append(key, sourceTxHash...)
+}
+
+func (this *EventStore) getCrossTxListKey(fromChainID uint64, height uint32, crossChainID []byte) []byte {
+	key := make([]byte, 13, 13+len(crossChainID))
+	key[0] = byte(scom.IX_CROSS_TX_LIST)
+	binary.BigEndian.PutUint64(key[1:], fromChainID)
+	binary.BigEndian.PutUint32(key[9:], height)
+	return append(key, crossChainID...)
+}
+
+func (this *EventStore) getEventLogKey(prefix byte, index []byte, height uint32, txHash common.Uint256) []byte {
+	key := make([]byte, 0, 1+len(index)+4+common.UINT256_SIZE)
+	key = append(key, prefix)
+	key = append(key, index...)
+	key = append(key, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(key[len(key)-4:], height)
+	return append(key, txHash[:]...)
+}
+
 func (this *EventStore) getEventNotifyByTxKey(txHash common.Uint256) []byte {
 	data := txHash.ToArray()
 	key := make([]byte, 1+len(data))
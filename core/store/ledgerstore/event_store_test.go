@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossTxIndex(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	eventStore := &EventStore{store: store}
+	save := func(txs ...*scom.CrossTx) {
+		eventStore.NewBatch()
+		for _, tx := range txs {
+			assert.Nil(t, eventStore.SaveCrossTx(tx))
+		}
+		assert.Nil(t, eventStore.CommitTo())
+	}
+
+	save(&scom.CrossTx{FromChainID: 2, CrossChainID: []byte{1}, PolyTxHash: common.Uint256{1}, Height: 10, Status: scom.CROSS_TX_IMPORTED},
+		&scom.CrossTx{FromChainID: 3, CrossChainID: []byte{1}, PolyTxHash: common.Uint256{2}, Height: 10, Status: scom.CROSS_TX_IMPORTED})
+	save(&scom.CrossTx{FromChainID: 2, CrossChainID: []byte{2}, SourceTxHash: []byte{0xaa}, ToChainID: 4,
+		PolyTxHash: common.Uint256{3}, Height: 11, ProofHeight: 11, RequestKey: []byte("key"), Status: scom.CROSS_TX_PROVED})
+	save(&scom.CrossTx{FromChainID: 2, CrossChainID: []byte{1}, SourceTxHash: []byte{0xbb}, ToChainID: 5,
+		PolyTxHash: common.Uint256{4}, Height: 12, ProofHeight: 12, RequestKey: []byte("key1"), Status: scom.CROSS_TX_PROVED})
+
+	tx, err := eventStore.GetCrossTx(2, []byte{1})
+	assert.Nil(t, err)
+	assert.Equal(t, scom.CROSS_TX_PROVED, tx.Status)
+	assert.Equal(t, uint32(10), tx.Height)
+	assert.Equal(t, uint32(12), tx.ProofHeight)
+	assert.Equal(t, uint64(5), tx.ToChainID)
+	assert.Equal(t, common.Uint256{4}, tx.PolyTxHash)
+
+	tx, err = eventStore.GetCrossTxBySourceHash(2, []byte{0xaa})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{2}, tx.CrossChainID)
+	_, err = eventStore.GetCrossTx(4, []byte{1})
+	assert.Equal(t, scom.ErrNotFound, err)
+
+	txs, err := eventStore.ListCrossTxs(2, 0, nil, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(txs))
+	assert.Equal(t, []byte{1}, txs[0].CrossChainID)
+	assert.Equal(t, []byte{2}, txs[1].CrossChainID)
+
+	// pages start after the last tx of the previous page
+	txs, err = eventStore.ListCrossTxs(2, 0, nil, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(txs))
+	txs, err = eventStore.ListCrossTxs(2, txs[0].Height, txs[0].CrossChainID, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, []byte{2}, txs[0].CrossChainID)
+	txs, err = eventStore.ListCrossTxs(2, txs[0].Height, txs[0].CrossChainID, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(txs))
+
+	txs, err = eventStore.ListCrossTxs(3, 0, nil, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, common.Uint256{2}, txs[0].PolyTxHash)
+
+	// a record seen earlier moves the tx in the list
+	save(&scom.CrossTx{FromChainID: 2, CrossChainID: []byte{2}, PolyTxHash: common.Uint256{5}, Height: 9, Status: scom.CROSS_TX_IMPORTED})
+	txs, err = eventStore.ListCrossTxs(2, 0, nil, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(txs))
+	assert.Equal(t, []byte{2}, txs[0].CrossChainID)
+	assert.Equal(t, uint32(9), txs[0].Height)
+	assert.Equal(t, []byte{1}, txs[1].CrossChainID)
+	_, err = store.Get(eventStore.getCrossTxListKey(2, 11, []byte{2}))
+	assert.Equal(t, scom.ErrNotFound, err)
+}
+
+func TestEventLogIndex(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	eventStore := &EventStore{store: store}
+	ccm, scm := common.Address{3}, common.Address{4}
+	notify := func(txHash common.Uint256, state byte, contract common.Address, name string) *event.ExecuteNotify {
+		return &event.ExecuteNotify{TxHash: txHash, State: state,
+			Notify: []*event.NotifyEventInfo{{ContractAddress: contract, States: []interface{}{name, uint64(2)}}}}
+	}
+	for h := uint32(1); h <= 5; h++ {
+		eventStore.NewBatch()
+		eventStore.SaveEventLogs(h, []*event.ExecuteNotify{
+			notify(common.Uint256{byte(h), 1}, event.CONTRACT_STATE_SUCCESS, ccm, "makeProof"),
+			notify(common.Uint256{byte(h), 2}, event.CONTRACT_STATE_SUCCESS, scm, "RegisterSideChain"),
+			notify(common.Uint256{byte(h), 3}, event.CONTRACT_STATE_FAIL, ccm, "makeProof"),
+		})
+		assert.Nil(t, eventStore.CommitTo())
+	}
+
+	txs, err := eventStore.GetEventLogTxs(&ccm, nil, 2, 4, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(txs))
+	for i, tx := range txs {
+		assert.Equal(t, uint32(i+2), tx.Height)
+		assert.Equal(t, common.Uint256{byte(i + 2), 1}, tx.TxHash)
+	}
+
+	topic, ok := scom.EventLogTopic([]interface{}{"RegisterSideChain"})
+	assert.True(t, ok)
+	txs, err = eventStore.GetEventLogTxs(nil, &topic, 0, 10, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(txs))
+	assert.Equal(t, common.Uint256{1, 2}, txs[0].TxHash)
+
+	_, ok = scom.EventLogTopic([]interface{}{uint64(1)})
+	assert.False(t, ok)
+	_, err = eventStore.GetEventLogTxs(nil, nil, 0, 10, 2)
+	assert.NotNil(t, err)
+}
@@ -19,6 +19,7 @@
 package ledgerstore
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"sort"
@@ -52,6 +53,7 @@ import (
 const (
 	SYSTEM_VERSION          = byte(1)      //Version of ledger store
 	HEADER_INDEX_BATCH_SIZE = uint32(2000) //Bath size of saving header index
+	PRUNE_BATCH_SIZE        = uint32(1000) //Batch size of pruning blocks
 )
 
 var (
@@ -70,6 +72,7 @@ type LedgerStoreImp struct {
 	storedIndexCount     uint32                           //record the count of have saved block index
 	currBlockHeight      uint32                           //Current block height
 	currBlockHash        common.Uint256                   //Current block hash
+	pruneHeight          uint32                           //Transactions and events of blocks below the height are pruned
 	headerCache          map[common.Uint256]*types.Header //BlockHash => Header
 	headerIndex          map[uint32]common.Uint256        //Header index, Mapping header height => block hash
 	savingBlockSemaphore chan bool
@@ -229,6 +232,11 @@ func (this *LedgerStoreImp) init() error {
 	if err != nil {
 		return fmt.Errorf("loadHeaderIndexList error %s", err)
 	}
+	pruneHeight, err := this.blockStore.GetPruneHeight()
+	if err != nil {
+		return fmt.Errorf("GetPruneHeight error %s", err)
+	}
+	this.setPruneHeight(pruneHeight)
 	err = this.recoverStore()
 	if err != nil {
 		return fmt.Errorf("recoverStore error %s", err)
@@ -297,7 +305,7 @@ func (this *LedgerStoreImp) recoverStore() error {
 		if err != nil {
 			return fmt.Errorf("save to state store height:%d error:%s", i, err)
 		}
-		err = this.saveBlockToEventStore(block)
+		err = this.saveBlockToEventStore(block, result)
 		if err != nil {
 			return fmt.Errorf("save to event store height:%d error:%s", i, err)
 		}
@@ -522,6 +530,10 @@ func (this *LedgerStoreImp) GetCrossStateRoot(height uint32) (common.Uint256, er
 	return this.stateStore.GetCrossStateRoot(height)
 }
 
+func (this *LedgerStoreImp) GetStateRoot(height uint32) (common.Uint256, error) {
+	return this.stateStore.GetStateTrieRoot(height)
+}
+
 func (this *LedgerStoreImp) ExecuteBlock(block *types.Block) (result store.ExecuteResult, err error) {
 	this.getSavingBlockLock()
 	defer this.releaseSavingBlockLock()
@@ -529,6 +541,10 @@ func (this *LedgerStoreImp) ExecuteBlock(block *types.Block) (result store.Execu
 	blockHeight := block.Header.Height
 	if blockHeight <= currBlockHeight {
 		result.MerkleRoot, err = this.GetStateMerkleRoot(blockHeight)
+		if err != nil {
+			return
+		}
+		result.StateRoot, err = this.GetStateRoot(blockHeight)
 		return
 	}
 	nextBlockHeight := currBlockHeight + 1
@@ -608,6 +624,14 @@ func (this *LedgerStoreImp) GetCrossStatesProof(height uint32, key []byte) ([]by
 	return path, nil
 }
 
+//GetStorageProof return the value of storage key at block height, with the proof against the state trie root of that height
+func (this *LedgerStoreImp) GetStorageProof(height uint32, key []byte) (common.Uint256, []byte, []byte, error) {
+	if height > this.GetCurrentBlockHeight() {
+		return common.UINT256_EMPTY, nil, nil, fmt.Errorf("height %d exceeds current block height", height)
+	}
+	return this.stateStore.GetStorageProof(height, key)
+}
+
 func (this *LedgerStoreImp) saveBlockToBlockStore(block *types.Block) error {
 	blockHash := block.Hash()
 	blockHeight := block.Header.Height
@@ -642,6 +666,9 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 		}
 		result.Notify = append(result.Notify, notify)
 		result.CrossHashes = append(result.CrossHashes, crossHashes...)
+		if notify.State == event.CONTRACT_STATE_SUCCESS {
+			result.CrossTxs = append(result.CrossTxs, crossTxsOfTx(tx.Hash(), block.Header.Height, cache)...)
+		}
 	}
 	if len(result.CrossHashes) != 0 {
 		result.CrossStatesRoot = merkle.TreeHasher{}.HashFullTreeWithLeafHash(result.CrossHashes)
@@ -651,6 +678,10 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 	result.Hash = overlay.ChangeHash()
 	result.WriteSet = overlay.GetWriteSet()
 	result.MerkleRoot = this.stateStore.GetStateMerkleRootWithNewHash(result.Hash)
+	result.StateRoot, result.StateTrieSet, err = this.stateStore.CalcStateTrieRoot(block.Header.Height, result.WriteSet)
+	if err != nil {
+		err = fmt.Errorf("CalcStateTrieRoot error %s", err)
+	}
 	return
 }
 
@@ -685,7 +716,19 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 		return err
 	}
 
-	log.Debugf("the state transition hash of block %d is:%s", blockHeight, result.Hash.ToHexString())
+	err = this.stateStore.AddStateTrieRoot(blockHeight, result.StateRoot, result.StateTrieSet)
+	if err != nil {
+		return fmt.Errorf("AddStateTrieRoot error %s", err)
+	}
+
+	if config.DefConfig.Common.EnableArchive {
+		err = this.stateStore.AddStateHistory(blockHeight, result.WriteSet)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{"height": blockHeight, "hash": result.Hash.ToHexString()}).Debug("the state transition hash of block")
 
 	result.WriteSet.ForEach(func(key, val []byte) {
 		if len(val) == 0 {
@@ -698,7 +741,7 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 	return nil
 }
 
-func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) error {
+func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block, result store.ExecuteResult) error {
 	blockHash := block.Hash()
 	blockHeight := block.Header.Height
 	txs := make([]common.Uint256, 0)
@@ -712,6 +755,14 @@ func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) error {
 			return fmt.Errorf("SaveEventNotifyByBlock error %s", err)
 		}
 	}
+	if config.DefConfig.Common.EnableEventLog {
+		this.eventStore.SaveEventLogs(blockHeight, result.Notify)
+	}
+	for _, crossTx := range mergeCrossTxs(result.CrossTxs) {
+		if err := this.eventStore.SaveCrossTx(crossTx); err != nil {
+			return fmt.Errorf("SaveCrossTx error %s", err)
+		}
+	}
 	err := this.eventStore.SaveCurrentBlock(blockHeight, blockHash)
 	if err != nil {
 		return fmt.Errorf("SaveCurrentBlock error %s", err)
@@ -741,6 +792,30 @@ func (this *LedgerStoreImp) releaseSavingBlockLock() {
 	}
 }
 
+//verifyStateRoot check the state trie root of previous block committed in vbft block info, which is optional
+//before the state root height
+func (this *LedgerStoreImp) verifyStateRoot(header *types.Header) error {
+	if header.Height == 0 || strings.ToLower(config.DefConfig.Genesis.ConsensusType) != "vbft" {
+		return nil
+	}
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return err
+	}
+	if len(blkInfo.StateRoot) == 0 && !vconfig.StateRootRequired(header.Height) {
+		return nil
+	}
+	stateRoot, err := this.GetStateRoot(header.Height - 1)
+	if err != nil {
+		return fmt.Errorf("GetStateRoot height:%d error %s", header.Height-1, err)
+	}
+	if !bytes.Equal(stateRoot[:], blkInfo.StateRoot) {
+		return fmt.Errorf("wrong state root at height:%d, expected:%s, got:%x",
+			header.Height, stateRoot.ToHexString(), blkInfo.StateRoot)
+	}
+	return nil
+}
+
 //saveBlock do the job of execution samrt contract and commit block to store.
 func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.ExecuteResult) error {
 	blockHash := block.Hash()
@@ -750,11 +825,15 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 		return fmt.Errorf("wrong block root at height:%d, expected:%s, got:%s",
 			block.Header.Height, blockRoot.ToHexString(), block.Header.BlockRoot.ToHexString())
 	}
+	err := this.verifyStateRoot(block.Header)
+	if err != nil {
+		return err
+	}
 
 	this.blockStore.NewBatch()
 	this.stateStore.NewBatch()
 	this.eventStore.NewBatch()
-	err := this.saveBlockToBlockStore(block)
+	err = this.saveBlockToBlockStore(block)
 	if err != nil {
 		return fmt.Errorf("save to block store height:%d error:%s", blockHeight, err)
 	}
@@ -762,7 +841,7 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 	if err != nil {
 		return fmt.Errorf("save to state store height:%d error:%s", blockHeight, err)
 	}
-	err = this.saveBlockToEventStore(block)
+	err = this.saveBlockToEventStore(block, result)
 	if err != nil {
 		return fmt.Errorf("save to event store height:%d error:%s", blockHeight, err)
 	}
@@ -824,7 +903,7 @@ func (this *LedgerStoreImp) handleTransaction(overlay *overlaydb.OverlayDB, cach
 			return nil, nil, fmt.Errorf("HandleInvokeTransaction tx %s error %s", txHash.ToHexString(), overlay.Error())
 		}
 		if err != nil {
-			log.Debugf("HandleInvokeTransaction tx %s error %s", txHash.ToHexString(), err)
+			log.WithFields(log.Fields{"height": block.Header.Height, "txHash": txHash.ToHexString()}).Debugf("HandleInvokeTransaction error %s", err)
 		}
 		return notify, crossHashes, nil
 	} else {
@@ -869,19 +948,42 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*cstates.
 	if err != nil {
 		return result, fmt.Errorf("get current block error")
 	}
-	overlay := this.stateStore.NewOverlayDB()
+	return this.preExecuteContract(tx, block, this.stateStore.NewOverlayDB())
+}
+
+//PreExecuteContractAt return the result of smart contract execution on the state after block of height without commit to store.
+//The state history of height is required, unless height is the current height.
+func (this *LedgerStoreImp) PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstates.PreExecResult, error) {
+	result := &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Result: nil}
+	if _, ok := tx.Payload.(*payload.InvokeCode); !ok {
+		return result, fmt.Errorf("transaction payload type error")
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil || block == nil {
+		return result, fmt.Errorf("get block of height %d error", height)
+	}
+	overlay, err := this.stateStore.NewOverlayDBAt(height)
+	if err != nil {
+		return result, err
+	}
+	return this.preExecuteContract(tx, block, overlay)
+}
+
+func (this *LedgerStoreImp) preExecuteContract(tx *types.Transaction, block *types.Block, overlay *overlaydb.OverlayDB) (*cstates.PreExecResult, error) {
+	result := &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Result: nil}
 	cache := storage.NewCacheDB(overlay)
 
 	service, err := native.NewNativeService(cache, tx, uint32(time.Now().Unix()), block.Header.Height,
-		hash, block.Header.ChainID, tx.Payload.(*payload.InvokeCode).Code, true)
+		block.Hash(), block.Header.ChainID, tx.Payload.(*payload.InvokeCode).Code, true)
 	if err != nil {
 		return result, fmt.Errorf("PreExecuteContract Error: %+v\n", err)
 	}
 	res, err := service.Invoke()
 	if err != nil {
+		result.Gas = service.GetGasUsed()
 		return result, err
 	}
-	return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Result: common.ToHexString(res.([]byte)), Notify: service.GetNotify()}, nil
+	return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Result: common.ToHexString(res.([]byte)), Notify: service.GetNotify(), Gas: service.GetGasUsed()}, nil
 }
 
 //IsContainBlock return whether the block is in store
@@ -940,7 +1042,14 @@ func (this *LedgerStoreImp) GetTransaction(txHash common.Uint256) (*types.Transa
 
 //GetBlockByHash return block by block hash. Wrap function of BlockStore.GetBlockByHash
 func (this *LedgerStoreImp) GetBlockByHash(blockHash common.Uint256) (*types.Block, error) {
-	return this.blockStore.GetBlock(blockHash)
+	block, err := this.blockStore.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if pruneHeight := this.GetPruneHeight(); block.Header.Height < pruneHeight {
+		return nil, fmt.Errorf("block of height %d is pruned, prune height %d", block.Header.Height, pruneHeight)
+	}
+	return block, nil
 }
 
 //GetBlockByHeight return block by height.
@@ -968,6 +1077,11 @@ func (this *LedgerStoreImp) GetStorageItem(key *states.StorageKey) (*states.Stor
 	return this.stateStore.GetStorageState(key)
 }
 
+//GetStorageItemAt return the storage value of the key in smart contract after block of height. Wrap function of StateStore.GetStorageStateAt
+func (this *LedgerStoreImp) GetStorageItemAt(key *states.StorageKey, height uint32) (*states.StorageItem, error) {
+	return this.stateStore.GetStorageStateAt(key, height)
+}
+
 //GetEventNotifyByTx return the events notify gen by executing of smart contract.  Wrap function of EventStore.GetEventNotifyByTx
 func (this *LedgerStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return this.eventStore.GetEventNotifyByTx(tx)
@@ -978,6 +1092,100 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetCrossTx return the cross chain tx of source chain by cross chain id, or by the tx hash in MakeTxParam
+func (this *LedgerStoreImp) GetCrossTx(fromChainID uint64, id []byte) (*scom.CrossTx, error) {
+	tx, err := this.eventStore.GetCrossTx(fromChainID, id)
+	if err == scom.ErrNotFound {
+		return this.eventStore.GetCrossTxBySourceHash(fromChainID, id)
+	}
+	return tx, err
+}
+
+//ListCrossTxs return cross chain txs of source chain in height order after a cursor. Wrap function of EventStore.ListCrossTxs
+func (this *LedgerStoreImp) ListCrossTxs(fromChainID uint64, height uint32, crossChainID []byte, limit uint32) ([]*scom.CrossTx, error) {
+	return this.eventStore.ListCrossTxs(fromChainID, height, crossChainID, limit)
+}
+
+//GetEventLogTxs return txs in height range with notify of contract address or topic. Wrap function of EventStore.GetEventLogTxs
+func (this *LedgerStoreImp) GetEventLogTxs(address *common.Address, topic *common.Uint256, startHeight, endHeight, limit uint32) ([]*scom.EventLogTx, error) {
+	return this.eventStore.GetEventLogTxs(address, topic, startHeight, endHeight, limit)
+}
+
+//GetPruneHeight return the height below which the transactions and events of blocks are pruned
+func (this *LedgerStoreImp) GetPruneHeight() uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.pruneHeight
+}
+
+func (this *LedgerStoreImp) setPruneHeight(height uint32) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.pruneHeight = height
+}
+
+//PruneBlocks delete the transactions and event notifies of blocks below height, with the event log and cross chain tx
+//indexes of them, the block headers are kept. The current block is never pruned.
+func (this *LedgerStoreImp) PruneBlocks(height uint32) error {
+	if currHeight := this.GetCurrentBlockHeight(); height > currHeight {
+		height = currHeight
+	}
+	start := this.GetPruneHeight()
+	if start >= height {
+		return nil
+	}
+	log.Infof("prune blocks from height %d to %d", start, height)
+	for start < height {
+		end := start + PRUNE_BATCH_SIZE
+		if end > height || end < start {
+			end = height
+		}
+		err := this.pruneBlocks(start, end)
+		if err != nil {
+			return fmt.Errorf("prune blocks from height %d to %d error %s", start, end, err)
+		}
+		log.Infof("blocks below height %d are pruned", end)
+		start = end
+	}
+	return nil
+}
+
+func (this *LedgerStoreImp) pruneBlocks(start, end uint32) error {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+	this.blockStore.NewBatch()
+	this.eventStore.NewBatch()
+	for height := start; height < end; height++ {
+		blockHash := this.GetBlockHash(height)
+		if blockHash == common.UINT256_EMPTY {
+			return fmt.Errorf("block hash of height %d not found", height)
+		}
+		txHashes, err := this.blockStore.PruneBlock(blockHash)
+		if err != nil {
+			return fmt.Errorf("PruneBlock height %d error %s", height, err)
+		}
+		err = this.eventStore.PruneEventNotify(height, txHashes)
+		if err != nil {
+			return fmt.Errorf("PruneEventNotify height %d error %s", height, err)
+		}
+	}
+	if err := this.eventStore.PruneCrossTxs(end); err != nil {
+		return fmt.Errorf("PruneCrossTxs error %s", err)
+	}
+	this.blockStore.SavePruneHeight(end)
+	// event store is pruned first, so that the blocks are pruned again if interrupted
+	err := this.eventStore.CommitTo()
+	if err != nil {
+		return fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	err = this.blockStore.CommitTo()
+	if err != nil {
+		return fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	this.setPruneHeight(end)
+	return nil
+}
+
 //Close ledger store.
 func (this *LedgerStoreImp) Close() error {
 	err := this.blockStore.Close()
@@ -0,0 +1,246 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+//replayExecution is the result of executing a block on the archived state before it
+type replayExecution struct {
+	block           *types.Block
+	notify          []*event.ExecuteNotify
+	crossStatesRoot common.Uint256
+	hash            common.Uint256
+	overlay         *overlaydb.OverlayDB
+	writeSet        *overlaydb.MemDB
+	writers         map[string]int //key => index of the last tx changing the key
+}
+
+//ReplayBlock re-execute the block of height on the archived state before it, and compare the write set hash,
+//the write set and the cross states root with the stored ones. The clock of native contracts is set to the
+//block timestamp, if clockSkew is not zero the block is executed again with the clock shifted by clockSkew
+//to find handlers depending on the local time. The ledger must not execute blocks during the replay.
+func (this *LedgerStoreImp) ReplayBlock(height uint32, clockSkew time.Duration) (*scom.BlockReplay, error) {
+	if height == 0 {
+		return nil, fmt.Errorf("genesis block can not be replayed")
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil || block == nil {
+		return nil, fmt.Errorf("get block of height %d error %v", height, err)
+	}
+	blockTime := time.Unix(int64(block.Header.Timestamp), 0)
+	exec, err := this.replayExecute(block, func() time.Time { return blockTime })
+	if err != nil {
+		return nil, err
+	}
+	replay := &scom.BlockReplay{
+		Height:          height,
+		TxCount:         len(block.Transactions),
+		WriteSetHash:    exec.hash.ToHexString(),
+		CrossStatesRoot: exec.crossStatesRoot.ToHexString(),
+	}
+
+	expectedHash, err := this.stateStore.GetWriteSetHash(height)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, fmt.Errorf("GetWriteSetHash %d error %s", height, err)
+	}
+	// the write set hash is not saved below the state hash check height
+	hashDiverged := err == nil && expectedHash != exec.hash
+	if err == nil {
+		replay.ExpectedWriteSetHash = expectedHash.ToHexString()
+	}
+	expectedCrossRoot, err := this.stateStore.GetCrossStateRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetCrossStateRoot %d error %s", height, err)
+	}
+	replay.ExpectedCrossStatesRoot = expectedCrossRoot.ToHexString()
+
+	for i, notify := range exec.notify {
+		stored, err := this.eventStore.GetEventNotifyByTx(notify.TxHash)
+		if err == scom.ErrNotFound {
+			// event log is disabled
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("GetEventNotifyByTx %s error %s", notify.TxHash.ToHexString(), err)
+		}
+		if stored.State != notify.State {
+			replay.FirstTxDiff = &scom.ReplayTxDiff{
+				TxIndex:  i,
+				TxHash:   notify.TxHash.ToHexString(),
+				Expected: stored.State,
+				Actual:   notify.State,
+			}
+			break
+		}
+	}
+
+	exec.writeSet.ForEach(func(key, val []byte) {
+		if err != nil || replay.FirstDiff != nil {
+			return
+		}
+		var expected []byte
+		expected, err = this.stateStore.getStateAt(height, key)
+		if err == scom.ErrNotFound {
+			err = nil
+		}
+		if err == nil && !bytes.Equal(expected, val) {
+			replay.FirstDiff = exec.diff(key, expected, val)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get state of height %d error %s", height, err)
+	}
+
+	replay.Diverged = hashDiverged || expectedCrossRoot != exec.crossStatesRoot ||
+		replay.FirstDiff != nil || replay.FirstTxDiff != nil
+	if hashDiverged && replay.FirstDiff == nil {
+		replay.Message = "write set hash differs while the keys written by the replay match the stored state, " +
+			"the stored block wrote keys not written by the replay"
+	}
+
+	if clockSkew != 0 {
+		skewTime := blockTime.Add(clockSkew)
+		skewed, err := this.replayExecute(block, func() time.Time { return skewTime })
+		if err != nil {
+			return nil, err
+		}
+		replay.ClockTxDiff = diffReplayTxs(exec, skewed)
+		replay.ClockDiff, err = diffReplayWriteSets(exec, skewed)
+		if err != nil {
+			return nil, fmt.Errorf("diff write sets of height %d error %s", height, err)
+		}
+		replay.ClockDependent = skewed.hash != exec.hash || skewed.crossStatesRoot != exec.crossStatesRoot ||
+			replay.ClockTxDiff != nil
+	}
+	return replay, nil
+}
+
+func (this *LedgerStoreImp) replayExecute(block *types.Block, now func() time.Time) (*replayExecution, error) {
+	overlay, err := this.stateStore.NewOverlayDBAt(block.Header.Height - 1)
+	if err != nil {
+		return nil, err
+	}
+	restore := native.SetClock(now)
+	defer restore()
+
+	exec := &replayExecution{block: block, writers: make(map[string]int)}
+	values := make(map[string][]byte)
+	crossHashes := make([]common.Uint256, 0)
+	cache := storage.NewCacheDB(overlay)
+	for i, tx := range block.Transactions {
+		cache.Reset()
+		notify, hashes, err := this.handleTransaction(overlay, cache, block, tx)
+		if err != nil {
+			return nil, err
+		}
+		exec.notify = append(exec.notify, notify)
+		crossHashes = append(crossHashes, hashes...)
+		// the write set is scanned after every tx to know which tx changed a key, it is slow but only used by replay
+		overlay.GetWriteSet().ForEach(func(key, val []byte) {
+			if old, ok := values[string(key)]; !ok || !bytes.Equal(old, val) {
+				values[string(key)] = append([]byte{}, val...)
+				exec.writers[string(key)] = i
+			}
+		})
+	}
+	if len(crossHashes) != 0 {
+		exec.crossStatesRoot = merkle.TreeHasher{}.HashFullTreeWithLeafHash(crossHashes)
+	} else {
+		exec.crossStatesRoot = common.UINT256_EMPTY
+	}
+	exec.hash = overlay.ChangeHash()
+	exec.overlay = overlay
+	exec.writeSet = overlay.GetWriteSet()
+	return exec, nil
+}
+
+func (this *replayExecution) diff(key, expected, actual []byte) *scom.ReplayDiff {
+	diff := &scom.ReplayDiff{
+		Key:      common.ToHexString(key),
+		Expected: common.ToHexString(expected),
+		Actual:   common.ToHexString(actual),
+		TxIndex:  -1,
+	}
+	if index, ok := this.writers[string(key)]; ok {
+		diff.TxIndex = index
+		txHash := this.block.Transactions[index].Hash()
+		diff.TxHash = txHash.ToHexString()
+	}
+	return diff
+}
+
+//diffReplayTxs return the first tx with different execution states in two executions of a block
+func diffReplayTxs(expected, actual *replayExecution) *scom.ReplayTxDiff {
+	for i, notify := range expected.notify {
+		if notify.State != actual.notify[i].State {
+			return &scom.ReplayTxDiff{
+				TxIndex:  i,
+				TxHash:   notify.TxHash.ToHexString(),
+				Expected: notify.State,
+				Actual:   actual.notify[i].State,
+			}
+		}
+	}
+	return nil
+}
+
+//diffReplayWriteSets return the first key with different values in the write sets of two executions of a block
+func diffReplayWriteSets(expected, actual *replayExecution) (*scom.ReplayDiff, error) {
+	var firstKey, expectedVal, actualVal []byte
+	var err error
+	compare := func(writeSet *overlaydb.MemDB) {
+		writeSet.ForEach(func(key, _ []byte) {
+			if err != nil || (firstKey != nil && bytes.Compare(key, firstKey) >= 0) {
+				return
+			}
+			var val, other []byte
+			if val, err = expected.overlay.Get(key); err != nil {
+				return
+			}
+			if other, err = actual.overlay.Get(key); err != nil {
+				return
+			}
+			if !bytes.Equal(val, other) {
+				firstKey = append([]byte{}, key...)
+				expectedVal, actualVal = val, other
+			}
+		})
+	}
+	compare(expected.writeSet)
+	compare(actual.writeSet)
+	if err != nil {
+		return nil, err
+	}
+	if firstKey == nil {
+		return nil, nil
+	}
+	return actual.diff(firstKey, expectedVal, actualVal), nil
+}
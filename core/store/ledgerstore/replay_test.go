@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayBlock(t *testing.T) {
+	consensusType := config.DefConfig.Genesis.ConsensusType
+	archive := config.DefConfig.Common.EnableArchive
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	config.DefConfig.Common.EnableArchive = true
+	defer func() {
+		config.DefConfig.Genesis.ConsensusType = consensusType
+		config.DefConfig.Common.EnableArchive = archive
+	}()
+
+	acc := account.NewAccount("")
+	bookkeepers := []keypair.PublicKey{acc.PublicKey}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	store, err := NewLedgerStore("test/replay")
+	assert.Nil(t, err)
+	defer store.Close()
+	assert.Nil(t, store.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers))
+	for height := uint32(1); height <= 3; height++ {
+		addSnapshotTestBlock(t, store, makeSnapshotTestBlock(t, store, acc, height))
+	}
+
+	_, err = store.ReplayBlock(0, 0)
+	assert.NotNil(t, err)
+	_, err = store.ReplayBlock(4, 0)
+	assert.NotNil(t, err)
+	for height := uint32(2); height <= 3; height++ {
+		replay, err := store.ReplayBlock(height, -time.Hour)
+		assert.Nil(t, err)
+		assert.Equal(t, height, replay.Height)
+		assert.Equal(t, 1, replay.TxCount)
+		assert.Equal(t, replay.ExpectedWriteSetHash, replay.WriteSetHash)
+		assert.Equal(t, replay.ExpectedCrossStatesRoot, replay.CrossStatesRoot)
+		assert.False(t, replay.Diverged)
+		assert.False(t, replay.ClockDependent)
+	}
+
+	// tamper the write set hash of block 3
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteHash(common.Uint256{1})
+	sink.WriteHash(common.Uint256{2})
+	assert.Nil(t, store.stateStore.store.Put(store.stateStore.genStateMerkleRootKey(3), sink.Bytes()))
+	replay, err := store.ReplayBlock(3, 0)
+	assert.Nil(t, err)
+	assert.True(t, replay.Diverged)
+	assert.Nil(t, replay.FirstDiff)
+	assert.NotEqual(t, "", replay.Message)
+}
+
+func TestDiffReplayWriteSets(t *testing.T) {
+	backend, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+	assert.Nil(t, backend.Put([]byte("k1"), []byte("v1")))
+	assert.Nil(t, backend.Put([]byte("k3"), []byte("v3")))
+	block := &types.Block{Transactions: []*types.Transaction{{}, {}}}
+	newExecution := func() *replayExecution {
+		overlay := overlaydb.NewOverlayDB(backend)
+		return &replayExecution{
+			block:    block,
+			overlay:  overlay,
+			writeSet: overlay.GetWriteSet(),
+			writers:  make(map[string]int),
+		}
+	}
+
+	expected, actual := newExecution(), newExecution()
+	expected.overlay.Put([]byte("k1"), []byte("v1"))
+	expected.overlay.Put([]byte("k2"), []byte("v2"))
+	actual.overlay.Put([]byte("k2"), []byte("v2"))
+	diff, err := diffReplayWriteSets(expected, actual)
+	assert.Nil(t, err)
+	assert.Nil(t, diff)
+
+	// k3 only deleted by the actual execution, k4 only written by the expected one
+	actual.overlay.Delete([]byte("k3"))
+	actual.writers["k3"] = 1
+	expected.overlay.Put([]byte("k4"), []byte("v4"))
+	diff, err = diffReplayWriteSets(expected, actual)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ToHexString([]byte("k3")), diff.Key)
+	assert.Equal(t, common.ToHexString([]byte("v3")), diff.Expected)
+	assert.Equal(t, "", diff.Actual)
+	assert.Equal(t, 1, diff.TxIndex)
+
+	actual.overlay.Put([]byte("k2"), []byte("v0"))
+	diff, err = diffReplayWriteSets(expected, actual)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ToHexString([]byte("k2")), diff.Key)
+	assert.Equal(t, -1, diff.TxIndex)
+}
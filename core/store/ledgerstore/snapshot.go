@@ -0,0 +1,482 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/common/serialization"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+)
+
+// A snapshot of height H is made of the snapshot info, all the headers from genesis to H, the full block of H,
+// the header of H+1 if the exporting ledger has it, and the key-values of the state db. The state trie nodes, the
+// state history and the block merkle tree are not exported, they are rebuilt when the snapshot is imported: the block
+// merkle tree from the headers, and the state trie from the contract storage. The rebuilt state trie root must be the
+// state root committed by consensus in the header of H+1, the import is refused without the header. So snapshot is
+// only supported from the state root height of the network, see config.GetStateRootHeight.
+
+const (
+	SNAPSHOT_VERSION           = byte(2)       //Version of snapshot file
+	SNAPSHOT_STATE_BATCH_SIZE  = 10000         //Batch size of importing state key-values
+	SNAPSHOT_HEADER_BATCH_SIZE = uint32(10000) //Batch size of importing headers
+)
+
+var snapshotMagic = []byte("POLYSNAPSHOT")
+
+//checkSnapshotHeight return error if the state root of snapshot height is not committed in the header of next block
+func checkSnapshotHeight(height uint32) error {
+	if !vconfig.StateRootRequired(height + 1) {
+		return fmt.Errorf("state root of height %d is not committed by consensus, snapshot is supported from height %d",
+			height, config.GetStateRootHeight(config.DefConfig.P2PNode.NetworkId))
+	}
+	return nil
+}
+
+//isSnapshotState return whether the key of state db is exported in snapshot
+func isSnapshotState(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	switch scom.DataEntryPrefix(key[0]) {
+	case scom.ST_STATE_TRIE, scom.ST_STATE_TRIE_REF, scom.DATA_STATE_TRIE_ROOT, scom.DATA_STATE_HISTORY, scom.SYS_STATE_HISTORY, scom.SYS_BLOCK_MERKLE_TREE:
+		return false
+	}
+	return true
+}
+
+//ExportSnapshot write the snapshot of current block to w
+func (this *LedgerStoreImp) ExportSnapshot(w io.Writer) (*scom.SnapshotInfo, error) {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	height, blockHash := this.GetCurrentBlock()
+	if err := checkSnapshotHeight(height); err != nil {
+		return nil, err
+	}
+	stateHash, stateHeight, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if stateHeight != height || stateHash != blockHash {
+		return nil, fmt.Errorf("state of height %d is not consistent with current block %d", stateHeight, height)
+	}
+	stateRoot, err := this.stateStore.GetStateTrieRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetStateTrieRoot height:%d error %s", height, err)
+	}
+	info := &scom.SnapshotInfo{Height: height, BlockHash: blockHash, StateRoot: stateRoot}
+
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBytes(snapshotMagic)
+	sink.WriteByte(SNAPSHOT_VERSION)
+	info.Serialization(sink)
+	if _, err = w.Write(sink.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for h := uint32(0); h <= height; h++ {
+		header, err := this.GetHeaderByHeight(h)
+		if err != nil || header == nil {
+			return nil, fmt.Errorf("GetHeaderByHeight height:%d error %v", h, err)
+		}
+		sink.Reset()
+		header.Serialization(sink)
+		if err = serialization.WriteVarBytes(w, sink.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	block, err := this.blockStore.GetBlock(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlock height:%d error %s", height, err)
+	}
+	sink.Reset()
+	if err = block.Serialization(sink); err != nil {
+		return nil, err
+	}
+	if err = serialization.WriteVarBytes(w, sink.Bytes()); err != nil {
+		return nil, err
+	}
+	// the header of next block is only known if the ledger has synced headers ahead of blocks
+	sink.Reset()
+	if nextHeader, _ := this.GetHeaderByHeight(height + 1); nextHeader != nil {
+		nextHeader.Serialization(sink)
+	}
+	if err = serialization.WriteVarBytes(w, sink.Bytes()); err != nil {
+		return nil, err
+	}
+
+	iter := this.stateStore.store.NewIterator(nil)
+	defer iter.Release()
+	for iter.Next() {
+		if !isSnapshotState(iter.Key()) {
+			continue
+		}
+		if err = serialization.WriteVarBytes(w, iter.Key()); err != nil {
+			return nil, err
+		}
+		if err = serialization.WriteVarBytes(w, iter.Value()); err != nil {
+			return nil, err
+		}
+	}
+	if err = iter.Error(); err != nil {
+		return nil, err
+	}
+	// empty key ends the state key-values
+	if err = serialization.WriteVarBytes(w, nil); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+//batchHashStore defers the file sync of merkle hash store to the commit of each import batch
+type batchHashStore struct {
+	merkle.HashStore
+}
+
+func (self batchHashStore) Flush() error {
+	return nil
+}
+
+//ImportSnapshot boot an empty ledger store from the snapshot read from r. The header chain is verified from
+//genesis block, and the block merkle tree, the cross states roots and the state of snapshot are checked against it.
+//nextHeader is the header following the snapshot block, it is used if the snapshot doesn't include one.
+func (this *LedgerStoreImp) ImportSnapshot(r io.Reader, genesisBlock *types.Block, nextHeader *types.Header) (*scom.SnapshotInfo, error) {
+	hasInit, err := this.hasAlreadyInitGenesisBlock()
+	if err != nil {
+		return nil, fmt.Errorf("hasAlreadyInit error %s", err)
+	}
+	if hasInit {
+		return nil, fmt.Errorf("ledger is already initialized, snapshot can only be imported to an empty data dir")
+	}
+
+	magic := make([]byte, len(snapshotMagic)+1)
+	if _, err = io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("read snapshot magic error %s", err)
+	}
+	if !bytes.Equal(magic[:len(snapshotMagic)], snapshotMagic) {
+		return nil, fmt.Errorf("invalid snapshot file")
+	}
+	if magic[len(snapshotMagic)] != SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("unsupported snapshot version %d", magic[len(snapshotMagic)])
+	}
+	buf := make([]byte, 4+2*common.UINT256_SIZE)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("read snapshot info error %s", err)
+	}
+	info := new(scom.SnapshotInfo)
+	if err = info.Deserialization(common.NewZeroCopySource(buf)); err != nil {
+		return nil, fmt.Errorf("snapshot info deserialize error %s", err)
+	}
+	if err = checkSnapshotHeight(info.Height); err != nil {
+		return nil, err
+	}
+
+	if err = this.blockStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("blockStore.ClearAll error %s", err)
+	}
+	if err = this.stateStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("stateStore.ClearAll error %s", err)
+	}
+	if err = this.eventStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("eventStore.ClearAll error %s", err)
+	}
+
+	stateRoot, err := this.importSnapshotHeaders(r, info, genesisBlock, nextHeader)
+	if err != nil {
+		return nil, err
+	}
+	if err = this.importSnapshotState(r, info, stateRoot); err != nil {
+		return nil, err
+	}
+
+	this.eventStore.NewBatch()
+	if err = this.eventStore.SaveCurrentBlock(info.Height, info.BlockHash); err != nil {
+		return nil, err
+	}
+	if err = this.eventStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	this.blockStore.NewBatch()
+	if err = this.blockStore.SaveCurrentBlock(info.Height, info.BlockHash); err != nil {
+		return nil, err
+	}
+	this.blockStore.SavePruneHeight(info.Height)
+	if err = this.blockStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	// the version is saved at last, an interrupted import is cleared by the next one
+	if err = this.initGenesisBlock(); err != nil {
+		return nil, fmt.Errorf("init error %s", err)
+	}
+	this.setCurrentBlock(info.Height, info.BlockHash)
+	this.setPruneHeight(info.Height)
+	return info, nil
+}
+
+//importSnapshotHeaders imports the headers and the block of snapshot, returns the state root of snapshot block
+//committed in the verified header of next block
+func (this *LedgerStoreImp) importSnapshotHeaders(r io.Reader, info *scom.SnapshotInfo, genesisBlock *types.Block,
+	nextHeader *types.Header) (common.Uint256, error) {
+	var peerInfo map[string]uint32
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) == "vbft" {
+		blkInfo, err := vconfig.VbftBlock(genesisBlock.Header)
+		if err != nil {
+			return common.UINT256_EMPTY, fmt.Errorf("genesis block info error %s", err)
+		}
+		if blkInfo.NewChainConfig == nil {
+			return common.UINT256_EMPTY, fmt.Errorf("genesis block has no chain config")
+		}
+		peerInfo = make(map[string]uint32)
+		for _, p := range blkInfo.NewChainConfig.Peers {
+			peerInfo[p.ID] = p.Index
+		}
+	}
+	hashStore := this.stateStore.merkleHashStore
+	if hashStore != nil {
+		this.stateStore.merkleTree = merkle.NewTree(0, nil, batchHashStore{hashStore})
+	}
+	commit := func() error {
+		if hashStore != nil {
+			if err := hashStore.Flush(); err != nil {
+				return fmt.Errorf("flush merkle hash store error %s", err)
+			}
+		}
+		if err := this.blockStore.CommitTo(); err != nil {
+			return fmt.Errorf("blockStore.CommitTo error %s", err)
+		}
+		if err := this.stateStore.CommitTo(); err != nil {
+			return fmt.Errorf("stateStore.CommitTo error %s", err)
+		}
+		return nil
+	}
+
+	var prevHash common.Uint256
+	this.blockStore.NewBatch()
+	this.stateStore.NewBatch()
+	for height := uint32(0); height <= info.Height; height++ {
+		raw, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return common.UINT256_EMPTY, fmt.Errorf("read header height:%d error %s", height, err)
+		}
+		header, err := types.HeaderFromRawBytes(raw)
+		if err != nil {
+			return common.UINT256_EMPTY, fmt.Errorf("header height:%d deserialize error %s", height, err)
+		}
+		blockHash := header.Hash()
+		if header.Height != height {
+			return common.UINT256_EMPTY, fmt.Errorf("header height %d not equal expected height %d", header.Height, height)
+		}
+		if height == 0 {
+			genesisHash := genesisBlock.Hash()
+			if blockHash != genesisHash {
+				return common.UINT256_EMPTY, fmt.Errorf("genesis block hash %s not match snapshot %s", genesisHash.ToHexString(), blockHash.ToHexString())
+			}
+		} else {
+			peerInfo, err = this.verifyHeader(header, peerInfo)
+			if err != nil {
+				return common.UINT256_EMPTY, fmt.Errorf("verifyHeader height:%d error %s", height, err)
+			}
+		}
+		if err = this.stateStore.AddBlockMerkleTreeRoot(header.PrevBlockHash); err != nil {
+			return common.UINT256_EMPTY, err
+		}
+		if height != 0 && this.stateStore.merkleTree.Root() != header.BlockRoot {
+			return common.UINT256_EMPTY, fmt.Errorf("wrong block root at height:%d", height)
+		}
+		if err = this.blockStore.SaveHeader(&types.Block{Header: header}); err != nil {
+			return common.UINT256_EMPTY, err
+		}
+		this.blockStore.SaveBlockHash(height, blockHash)
+		this.setHeaderIndex(height, blockHash)
+		this.addHeaderCache(header)
+		this.delHeaderCache(prevHash)
+		prevHash = blockHash
+		if height%HEADER_INDEX_BATCH_SIZE == HEADER_INDEX_BATCH_SIZE-1 && height < info.Height {
+			start := height + 1 - HEADER_INDEX_BATCH_SIZE
+			indexList := make([]common.Uint256, 0, HEADER_INDEX_BATCH_SIZE)
+			for h := start; h <= height; h++ {
+				indexList = append(indexList, this.getHeaderIndex(h))
+			}
+			if err = this.blockStore.SaveHeaderIndexList(start, indexList); err != nil {
+				return common.UINT256_EMPTY, err
+			}
+			this.storedIndexCount = height + 1
+		}
+		if height%SNAPSHOT_HEADER_BATCH_SIZE == SNAPSHOT_HEADER_BATCH_SIZE-1 {
+			if err = commit(); err != nil {
+				return common.UINT256_EMPTY, err
+			}
+			log.Infof("snapshot headers imported to height %d", height)
+			this.blockStore.NewBatch()
+			this.stateStore.NewBatch()
+		}
+	}
+	this.delHeaderCache(prevHash)
+	if prevHash != info.BlockHash {
+		return common.UINT256_EMPTY, fmt.Errorf("block hash of height %d not match snapshot info", info.Height)
+	}
+
+	raw, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("read block height:%d error %s", info.Height, err)
+	}
+	block, err := types.BlockFromRawBytes(raw)
+	if err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("block height:%d deserialize error %s", info.Height, err)
+	}
+	if block.Hash() != info.BlockHash {
+		return common.UINT256_EMPTY, fmt.Errorf("block hash of height %d not match snapshot info", info.Height)
+	}
+	if err = this.blockStore.SaveBlock(block); err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	if err = commit(); err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	treeSize, hashes := this.stateStore.merkleTree.TreeSize(), this.stateStore.merkleTree.Hashes()
+	this.stateStore.merkleTree = merkle.NewTree(treeSize, hashes, hashStore)
+
+	raw, err = serialization.ReadVarBytes(r)
+	if err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("read header height:%d error %s", info.Height+1, err)
+	}
+	if len(raw) != 0 {
+		if nextHeader, err = types.HeaderFromRawBytes(raw); err != nil {
+			return common.UINT256_EMPTY, fmt.Errorf("header height:%d deserialize error %s", info.Height+1, err)
+		}
+	}
+	if nextHeader == nil {
+		return common.UINT256_EMPTY, fmt.Errorf("header of height %d is required to verify the snapshot state", info.Height+1)
+	}
+	if nextHeader.Height != info.Height+1 || nextHeader.PrevBlockHash != info.BlockHash {
+		return common.UINT256_EMPTY, fmt.Errorf("header of height %d doesn't follow the snapshot block", nextHeader.Height)
+	}
+	if _, err = this.verifyHeader(nextHeader, peerInfo); err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("verifyHeader height:%d error %s", nextHeader.Height, err)
+	}
+	blkInfo, err := vconfig.VbftBlock(nextHeader)
+	if err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("header height:%d block info error %s", nextHeader.Height, err)
+	}
+	if len(blkInfo.StateRoot) == 0 {
+		return common.UINT256_EMPTY, fmt.Errorf("header of height %d has no state root", nextHeader.Height)
+	}
+	stateRoot, err := common.Uint256ParseFromBytes(blkInfo.StateRoot)
+	if err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("header height:%d state root error %s", nextHeader.Height, err)
+	}
+	return stateRoot, nil
+}
+
+//importSnapshotState imports the state key-values of snapshot, the state trie rebuilt from them must be of stateRoot
+func (this *LedgerStoreImp) importSnapshotState(r io.Reader, info *scom.SnapshotInfo, stateRoot common.Uint256) error {
+	count := 0
+	this.stateStore.NewBatch()
+	for {
+		key, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return fmt.Errorf("read state key error %s", err)
+		}
+		if len(key) == 0 {
+			break
+		}
+		value, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return fmt.Errorf("read state value error %s", err)
+		}
+		if !isSnapshotState(key) {
+			return fmt.Errorf("unexpected state key %x", key)
+		}
+		if key[0] == byte(scom.SYS_CROSS_STATES_HASH) && len(key) == 5 {
+			if err = this.checkSnapshotCrossStateRoot(binary.LittleEndian.Uint32(key[1:]), value); err != nil {
+				return err
+			}
+		}
+		this.stateStore.BatchPutRawKeyVal(key, value)
+		count++
+		if count%SNAPSHOT_STATE_BATCH_SIZE == 0 {
+			if err = this.stateStore.CommitTo(); err != nil {
+				return fmt.Errorf("stateStore.CommitTo error %s", err)
+			}
+			this.stateStore.NewBatch()
+		}
+	}
+	if err := this.stateStore.CommitTo(); err != nil {
+		return fmt.Errorf("stateStore.CommitTo error %s", err)
+	}
+	log.Infof("snapshot state imported with %d keys", count)
+	treeSize, hashes, err := this.stateStore.GetStateMerkleTree()
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("GetStateMerkleTree error %s", err)
+	}
+	this.stateStore.deltaMerkleTree = merkle.NewTree(treeSize, hashes, nil)
+
+	stateHash, stateHeight, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if stateHeight != info.Height || stateHash != info.BlockHash {
+		return fmt.Errorf("state of height %d is not consistent with snapshot height %d", stateHeight, info.Height)
+	}
+	header, err := this.blockStore.GetHeader(info.BlockHash)
+	if err != nil {
+		return err
+	}
+	crossStateRoot, err := this.stateStore.GetCrossStateRoot(info.Height)
+	if err != nil {
+		return err
+	}
+	if crossStateRoot != header.CrossStateRoot {
+		return fmt.Errorf("wrong cross state root at height:%d", info.Height)
+	}
+	if err = this.stateStore.initStateTrie(info.Height); err != nil {
+		return fmt.Errorf("initStateTrie error %s", err)
+	}
+	if this.stateStore.stateTrieRoot != stateRoot || info.StateRoot != stateRoot {
+		return fmt.Errorf("wrong state root, expected:%s, got:%s, snapshot info:%s", stateRoot.ToHexString(),
+			this.stateStore.stateTrieRoot.ToHexString(), info.StateRoot.ToHexString())
+	}
+	return nil
+}
+
+func (this *LedgerStoreImp) checkSnapshotCrossStateRoot(height uint32, value []byte) error {
+	root, err := common.Uint256ParseFromBytes(value)
+	if err != nil {
+		return fmt.Errorf("cross state root of height %d error %s", height, err)
+	}
+	header, err := this.GetHeaderByHeight(height)
+	if err != nil || header == nil {
+		return fmt.Errorf("GetHeaderByHeight height:%d error %v", height, err)
+	}
+	if root != header.CrossStateRoot {
+		return fmt.Errorf("wrong cross state root at height:%d", height)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/signature"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeSnapshotTestBlock(t *testing.T, store *LedgerStoreImp, acc *account.Account, height uint32) *types.Block {
+	param := &states.ContractInvokeParam{Address: common.Address{1, 2, 3}, Method: "test", Args: []byte{byte(height)}}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	tx := &types.Transaction{
+		TxType:  types.Invoke,
+		Nonce:   height,
+		Payload: &payload.InvokeCode{Code: sink.Bytes()},
+		Sigs:    []types.Sig{},
+	}
+	sink = common.NewZeroCopySink(nil)
+	assert.Nil(t, tx.Serialization(sink))
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	assert.Nil(t, err)
+
+	prevHeader, err := store.GetHeaderByHeight(height - 1)
+	assert.Nil(t, err)
+	nextBookkeeper, err := types.AddressFromBookkeepers([]keypair.PublicKey{acc.PublicKey})
+	assert.Nil(t, err)
+	blkInfo := &vconfig.VbftBlockInfo{}
+	if vconfig.StateRootRequired(height) {
+		stateRoot, err := store.GetStateRoot(height - 1)
+		assert.Nil(t, err)
+		blkInfo.StateRoot = stateRoot.ToArray()
+	}
+	consensusPayload, err := json.Marshal(blkInfo)
+	assert.Nil(t, err)
+	block := &types.Block{
+		Header: &types.Header{
+			Version:          types.CURR_HEADER_VERSION,
+			ChainID:          prevHeader.ChainID,
+			PrevBlockHash:    prevHeader.Hash(),
+			BlockRoot:        store.GetBlockRootWithPreBlockHashes(height, []common.Uint256{prevHeader.Hash()}),
+			Timestamp:        prevHeader.Timestamp + 1,
+			Height:           height,
+			ConsensusPayload: consensusPayload,
+			NextBookkeeper:   nextBookkeeper,
+			Bookkeepers:      []keypair.PublicKey{acc.PublicKey},
+		},
+		Transactions: []*types.Transaction{tx},
+	}
+	block.RebuildMerkleRoot()
+	hash := block.Hash()
+	sig, err := signature.Sign(acc, hash[:])
+	assert.Nil(t, err)
+	block.Header.SigData = [][]byte{sig}
+	return block
+}
+
+func addSnapshotTestBlock(t *testing.T, store *LedgerStoreImp, block *types.Block) {
+	result, err := store.ExecuteBlock(block)
+	assert.Nil(t, err)
+	assert.Nil(t, store.SubmitBlock(block, result))
+}
+
+func TestSnapshot(t *testing.T) {
+	consensusType, networkId := config.DefConfig.Genesis.ConsensusType, config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() {
+		config.DefConfig.Genesis.ConsensusType = consensusType
+		config.DefConfig.P2PNode.NetworkId = networkId
+	}()
+
+	acc := account.NewAccount("")
+	bookkeepers := []keypair.PublicKey{acc.PublicKey}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	src, err := NewLedgerStore("test/snapshot/src")
+	assert.Nil(t, err)
+	defer src.Close()
+	assert.Nil(t, src.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers))
+	for height := uint32(1); height <= 3; height++ {
+		addSnapshotTestBlock(t, src, makeSnapshotTestBlock(t, src, acc, height))
+	}
+	buf := bytes.NewBuffer(nil)
+	_, err = src.ExportSnapshot(buf)
+	assert.Nil(t, err)
+	snapshotNoHeader := buf.Bytes()
+	// the header of next block is exported once synced
+	nextBlock := makeSnapshotTestBlock(t, src, acc, 4)
+	assert.Nil(t, src.AddHeader(nextBlock.Header))
+
+	buf = bytes.NewBuffer(nil)
+	info, err := src.ExportSnapshot(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), info.Height)
+	assert.Equal(t, src.GetCurrentBlockHash(), info.BlockHash)
+	snapshot := buf.Bytes()
+
+	dst, err := NewLedgerStore("test/snapshot/dst")
+	assert.Nil(t, err)
+	defer dst.Close()
+	_, err = dst.ImportSnapshot(bytes.NewReader(snapshot), genesisBlock, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, dst.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers))
+	assert.Equal(t, uint32(3), dst.GetCurrentBlockHeight())
+	assert.Equal(t, info.BlockHash, dst.GetCurrentBlockHash())
+	assert.Equal(t, uint32(3), dst.GetPruneHeight())
+	root, err := dst.GetStateRoot(3)
+	assert.Nil(t, err)
+	assert.Equal(t, info.StateRoot, root)
+
+	block, err := dst.GetBlockByHeight(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(block.Transactions))
+	_, err = dst.GetBlockByHeight(2)
+	assert.NotNil(t, err)
+	header, err := dst.GetHeaderByHeight(2)
+	assert.Nil(t, err)
+	assert.Equal(t, src.GetBlockHash(2), header.Hash())
+	proof, err := src.GetMerkleProof(nil, 1, 3)
+	assert.Nil(t, err)
+	proof1, err := dst.GetMerkleProof(nil, 1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, proof, proof1)
+
+	// both ledgers continue with the same next block
+	block = nextBlock
+	addSnapshotTestBlock(t, src, block)
+	addSnapshotTestBlock(t, dst, block)
+	root, err = src.GetStateMerkleRoot(4)
+	assert.Nil(t, err)
+	root1, err := dst.GetStateMerkleRoot(4)
+	assert.Nil(t, err)
+	assert.Equal(t, root, root1)
+	root, err = src.GetStateRoot(4)
+	assert.Nil(t, err)
+	root1, err = dst.GetStateRoot(4)
+	assert.Nil(t, err)
+	assert.Equal(t, root, root1)
+
+	// already initialized
+	_, err = dst.ImportSnapshot(bytes.NewReader(snapshot), genesisBlock, nil)
+	assert.NotNil(t, err)
+
+	// header with invalid signature
+	header, err = src.GetHeaderByHeight(2)
+	assert.Nil(t, err)
+	sink := common.NewZeroCopySink(nil)
+	header.Serialization(sink)
+	index := bytes.Index(snapshot, sink.Bytes())
+	assert.True(t, index > 0)
+	tampered := append([]byte{}, snapshot...)
+	tampered[index+len(sink.Bytes())-1] ^= 1
+	other, err := NewLedgerStore("test/snapshot/other")
+	assert.Nil(t, err)
+	defer other.Close()
+	_, err = other.ImportSnapshot(bytes.NewReader(tampered), genesisBlock, nil)
+	assert.NotNil(t, err)
+
+	// snapshot of another genesis block
+	acc1 := account.NewAccount("")
+	genesisBlock1, err := genesis.BuildGenesisBlock([]keypair.PublicKey{acc1.PublicKey}, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	_, err = other.ImportSnapshot(bytes.NewReader(snapshot), genesisBlock1, nil)
+	assert.NotNil(t, err)
+
+	// the state root must be committed by the header of next block
+	_, err = other.ImportSnapshot(bytes.NewReader(snapshotNoHeader), genesisBlock, nil)
+	assert.NotNil(t, err)
+	wrongHeader := *nextBlock.Header
+	wrongHeader.ConsensusPayload, err = json.Marshal(&vconfig.VbftBlockInfo{StateRoot: []byte{common.UINT256_SIZE - 1: 1}})
+	assert.Nil(t, err)
+	_, err = other.ImportSnapshot(bytes.NewReader(snapshotNoHeader), genesisBlock, &wrongHeader)
+	assert.NotNil(t, err)
+	// state root is not committed below the state root height of main net
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	_, err = other.ImportSnapshot(bytes.NewReader(snapshot), genesisBlock, nil)
+	assert.NotNil(t, err)
+	_, err = src.ExportSnapshot(new(bytes.Buffer))
+	assert.NotNil(t, err)
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	noRootHeader := *nextBlock.Header
+	noRootHeader.ConsensusPayload, err = json.Marshal(&vconfig.VbftBlockInfo{})
+	assert.Nil(t, err)
+	_, err = other.ImportSnapshot(bytes.NewReader(snapshotNoHeader), genesisBlock, &noRootHeader)
+	assert.NotNil(t, err)
+	_, err = other.ImportSnapshot(bytes.NewReader(snapshotNoHeader), genesisBlock, nextBlock.Header)
+	assert.Nil(t, err)
+}
+
+func TestPruneBlocks(t *testing.T) {
+	consensusType, networkId := config.DefConfig.Genesis.ConsensusType, config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() {
+		config.DefConfig.Genesis.ConsensusType = consensusType
+		config.DefConfig.P2PNode.NetworkId = networkId
+	}()
+
+	acc := account.NewAccount("")
+	bookkeepers := []keypair.PublicKey{acc.PublicKey}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	store, err := NewLedgerStore("test/prune")
+	assert.Nil(t, err)
+	defer store.Close()
+	assert.Nil(t, store.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers))
+	blocks := make([]*types.Block, 0)
+	for height := uint32(1); height <= 3; height++ {
+		block := makeSnapshotTestBlock(t, store, acc, height)
+		addSnapshotTestBlock(t, store, block)
+		blocks = append(blocks, block)
+	}
+	// the event log and cross tx indexes of block 1
+	txHash := blocks[0].Transactions[0].Hash()
+	notify := &event.ExecuteNotify{TxHash: txHash, State: event.CONTRACT_STATE_SUCCESS,
+		Notify: []*event.NotifyEventInfo{{ContractAddress: common.Address{1, 2, 3}, States: []interface{}{"test"}}}}
+	prunedTx := &scom.CrossTx{FromChainID: 2, CrossChainID: []byte{1}, SourceTxHash: []byte{1}, Height: 1, ProofHeight: 1}
+	provedTx := &scom.CrossTx{FromChainID: 2, CrossChainID: []byte{2}, Height: 1, ProofHeight: 3}
+	store.eventStore.NewBatch()
+	assert.Nil(t, store.eventStore.SaveEventNotifyByTx(txHash, notify))
+	store.eventStore.SaveEventLogs(1, []*event.ExecuteNotify{notify})
+	assert.Nil(t, store.eventStore.SaveCrossTx(prunedTx))
+	assert.Nil(t, store.eventStore.SaveCrossTx(provedTx))
+	assert.Nil(t, store.eventStore.CommitTo())
+	logTxs, err := store.GetEventLogTxs(&common.Address{1, 2, 3}, nil, 0, 3, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logTxs))
+
+	assert.Nil(t, store.PruneBlocks(2))
+	assert.Equal(t, uint32(2), store.GetPruneHeight())
+	_, err = store.GetBlockByHeight(1)
+	assert.NotNil(t, err)
+	_, _, err = store.GetTransaction(blocks[0].Transactions[0].Hash())
+	assert.Equal(t, scom.ErrNotFound, err)
+	// pruned transaction is still contained for replay protection
+	contained, err := store.IsContainTransaction(blocks[0].Transactions[0].Hash())
+	assert.Nil(t, err)
+	assert.True(t, contained)
+	_, err = store.GetEventNotifyByTx(blocks[0].Transactions[0].Hash())
+	assert.Equal(t, scom.ErrNotFound, err)
+	header, err := store.GetHeaderByHeight(1)
+	assert.Nil(t, err)
+	assert.Equal(t, blocks[0].Hash(), header.Hash())
+	block, err := store.GetBlockByHeight(2)
+	assert.Nil(t, err)
+	assert.Equal(t, blocks[1].Hash(), block.Hash())
+	_, err = store.GetEventNotifyByTx(blocks[1].Transactions[0].Hash())
+	assert.Nil(t, err)
+	logTxs, err = store.GetEventLogTxs(&common.Address{1, 2, 3}, nil, 0, 3, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(logTxs))
+	_, err = store.GetCrossTx(2, prunedTx.CrossChainID)
+	assert.Equal(t, scom.ErrNotFound, err)
+	_, err = store.eventStore.GetCrossTxBySourceHash(2, prunedTx.SourceTxHash)
+	assert.Equal(t, scom.ErrNotFound, err)
+	crossTxs, err := store.ListCrossTxs(2, 0, nil, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(crossTxs))
+	assert.Equal(t, provedTx.CrossChainID, crossTxs[0].CrossChainID)
+
+	// current block is never pruned
+	assert.Nil(t, store.PruneBlocks(100))
+	assert.Equal(t, uint32(3), store.GetPruneHeight())
+	block, err = store.GetBlockByHeight(3)
+	assert.Nil(t, err)
+	assert.Equal(t, blocks[2].Hash(), block.Hash())
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+)
+
+// In archive mode, the value of every key written by a block is saved before it is overwritten,
+// keyed by the key and the block height. The value of a key after block h is the value saved by the
+// first block above h which changed the key, or the current value if no block changed it since.
+
+//AddStateHistory save the values of the keys in write set before they are changed by block of height
+func (self *StateStore) AddStateHistory(height uint32, writeSet *overlaydb.MemDB) error {
+	start, end, err := self.GetStateHistoryRange()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if err == scom.ErrNotFound || end+1 != height {
+		// history is not continuous, older entries can not be used anymore
+		start = height
+	}
+	err = nil
+	writeSet.ForEach(func(key, _ []byte) {
+		if err != nil {
+			return
+		}
+		var old []byte
+		old, err = self.store.Get(key)
+		if err == scom.ErrNotFound {
+			self.store.BatchPut(genStateHistoryKey(key, height), []byte{0})
+			err = nil
+		} else if err == nil {
+			self.store.BatchPut(genStateHistoryKey(key, height), append([]byte{1}, old...))
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("AddStateHistory height:%d error %s", height, err)
+	}
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint32(value, start)
+	binary.LittleEndian.PutUint32(value[4:], height)
+	self.store.BatchPut([]byte{byte(scom.SYS_STATE_HISTORY)}, value)
+	return nil
+}
+
+//GetStateHistoryRange return the first and the last block height with state history saved
+func (self *StateStore) GetStateHistoryRange() (uint32, uint32, error) {
+	value, err := self.store.Get([]byte{byte(scom.SYS_STATE_HISTORY)})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(value) != 8 {
+		return 0, 0, fmt.Errorf("invalid state history range %x", value)
+	}
+	return binary.LittleEndian.Uint32(value), binary.LittleEndian.Uint32(value[4:]), nil
+}
+
+//checkHistoryHeight check the state after block of height can be read from state history
+func (self *StateStore) checkHistoryHeight(height uint32) error {
+	_, current, err := self.GetCurrentBlock()
+	if err != nil {
+		return err
+	}
+	if height > current {
+		return fmt.Errorf("height %d is higher than current height %d", height, current)
+	}
+	if height == current {
+		return nil
+	}
+	start, end, err := self.GetStateHistoryRange()
+	if err == scom.ErrNotFound || (err == nil && end != current) {
+		return fmt.Errorf("state history is not archived, start the node in archive mode")
+	}
+	if err != nil {
+		return err
+	}
+	if uint64(height)+1 < uint64(start) {
+		return fmt.Errorf("state of height %d is not archived, state history starts at height %d", height, start)
+	}
+	return nil
+}
+
+//GetStateAt return the raw value of key after executing block of height. Return ErrNotFound if key not exist at height.
+func (self *StateStore) GetStateAt(height uint32, key []byte) ([]byte, error) {
+	if err := self.checkHistoryHeight(height); err != nil {
+		return nil, err
+	}
+	return self.getStateAt(height, key)
+}
+
+func (self *StateStore) getStateAt(height uint32, key []byte) ([]byte, error) {
+	prefix := genStateHistoryPrefix(key)
+	iter := self.store.NewRangeIterator(prefix, genStateHistoryKey(key, height+1))
+	defer iter.Release()
+	if !iter.Next() {
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+		return self.store.Get(key)
+	}
+	value := iter.Value()
+	if len(value) == 0 || value[0] == 0 {
+		return nil, scom.ErrNotFound
+	}
+	return append([]byte{}, value[1:]...), nil
+}
+
+//NewOverlayDBAt return an overlay db on the state after executing block of height, used to pre-execute tx
+func (self *StateStore) NewOverlayDBAt(height uint32) (*overlaydb.OverlayDB, error) {
+	if err := self.checkHistoryHeight(height); err != nil {
+		return nil, err
+	}
+	return overlaydb.NewOverlayDB(&historyStore{state: self, height: height}), nil
+}
+
+//historyStore is a read only PersistStore of the state after executing block of height
+type historyStore struct {
+	state  *StateStore
+	height uint32
+}
+
+var errHistoryReadOnly = fmt.Errorf("state history is read only")
+
+func (self *historyStore) Get(key []byte) ([]byte, error) {
+	return self.state.getStateAt(self.height, key)
+}
+
+func (self *historyStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == scom.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (self *historyStore) Put(key []byte, value []byte) error { return errHistoryReadOnly }
+func (self *historyStore) Delete(key []byte) error            { return errHistoryReadOnly }
+func (self *historyStore) NewBatch()                          {}
+func (self *historyStore) BatchPut(key []byte, value []byte)  {}
+func (self *historyStore) BatchDelete(key []byte)             {}
+func (self *historyStore) BatchCommit() error                 { return errHistoryReadOnly }
+func (self *historyStore) Close() error                       { return nil }
+
+func (self *historyStore) NewIterator(prefix []byte) scom.StoreIterator {
+	return historyIterator{}
+}
+
+func (self *historyStore) NewRangeIterator(prefix []byte, start []byte) scom.StoreIterator {
+	return historyIterator{}
+}
+
+//historyIterator is returned by historyStore, since keys can not be iterated at history height
+type historyIterator struct{}
+
+func (historyIterator) Next() bool    { return false }
+func (historyIterator) First() bool   { return false }
+func (historyIterator) Key() []byte   { return nil }
+func (historyIterator) Value() []byte { return nil }
+func (historyIterator) Release()      {}
+func (historyIterator) Error() error {
+	return fmt.Errorf("iterating state history is not supported")
+}
+
+//genStateHistoryPrefix return the prefix of history keys of key, the key length is included
+//so that a key is never the prefix of another one
+func genStateHistoryPrefix(key []byte) []byte {
+	prefix := make([]byte, 5, 5+len(key))
+	prefix[0] = byte(scom.DATA_STATE_HISTORY)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(key)))
+	return append(prefix, key...)
+}
+
+func genStateHistoryKey(key []byte, height uint32) []byte {
+	prefix := genStateHistoryPrefix(key)
+	historyKey := make([]byte, len(prefix)+4)
+	copy(historyKey, prefix)
+	binary.BigEndian.PutUint32(historyKey[len(prefix):], height)
+	return historyKey
+}
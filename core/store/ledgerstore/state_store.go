@@ -47,6 +47,8 @@ type StateStore struct {
 	deltaMerkleTree      *merkle.CompactMerkleTree //Merkle tree of delta state root
 	merkleHashStore      merkle.HashStore
 	stateHashCheckHeight uint32
+	stateTrieRoot        common.Uint256 //State trie root of current block
+	stateTrieBuilt       bool           //Whether the state trie of current block is built
 }
 
 //NewStateStore return state store instance
@@ -65,10 +67,17 @@ func NewStateStore(dbDir, merklePath string) (*StateStore, error) {
 	if err != nil && err != scom.ErrNotFound {
 		return nil, fmt.Errorf("GetCurrentBlock error %s", err)
 	}
+	hasCurrentBlock := err == nil
 	err = stateStore.init(height)
 	if err != nil {
 		return nil, fmt.Errorf("init error %s", err)
 	}
+	if hasCurrentBlock {
+		err = stateStore.initStateTrie(height)
+		if err != nil {
+			return nil, fmt.Errorf("initStateTrie error %s", err)
+		}
+	}
 	return stateStore, nil
 }
 
@@ -178,6 +187,21 @@ func (self *StateStore) GetStateMerkleRoot(height uint32) (result common.Uint256
 	return
 }
 
+//GetWriteSetHash return the hash of the write set of block of height
+func (self *StateStore) GetWriteSetHash(height uint32) (result common.Uint256, err error) {
+	key := self.genStateMerkleRootKey(height)
+	var value []byte
+	value, err = self.store.Get(key)
+	if err != nil {
+		return
+	}
+	result, eof := common.NewZeroCopySource(value).NextHash()
+	if eof {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
 func (self *StateStore) AddStateMerkleTreeRoot(blockHeight uint32, writeSetHash common.Uint256) error {
 	if blockHeight < self.stateHashCheckHeight {
 		return nil
@@ -354,6 +378,24 @@ func (self *StateStore) GetStorageState(key *states.StorageKey) (*states.Storage
 	return storageState, nil
 }
 
+//GetStorageStateAt return the storage value of the key in smart contract after block of height.
+func (self *StateStore) GetStorageStateAt(key *states.StorageKey, height uint32) (*states.StorageItem, error) {
+	storeKey, err := self.getStorageKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := self.GetStateAt(height, storeKey)
+	if err != nil {
+		return nil, err
+	}
+	storageState := new(states.StorageItem)
+	err = storageState.Deserialize(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return storageState, nil
+}
+
 func (self *StateStore) GetStorageValue(key []byte) ([]byte, error) {
 	data, err := self.store.Get(append([]byte{byte(byte(scom.ST_STORAGE))}, key...))
 	if err != nil {
@@ -474,6 +516,7 @@ func (self *StateStore) ClearAll() error {
 		self.store.NewBatch() // reset the batch
 		return err
 	}
+	self.stateTrieRoot, self.stateTrieBuilt = common.UINT256_EMPTY, false
 	return self.store.BatchCommit()
 }
 
@@ -19,11 +19,17 @@
 package ledgerstore
 
 import (
+	"fmt"
 	"math/rand"
 	"testing"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/states"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/merkle"
+	"github.com/polynetwork/poly/merkle/smt"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,3 +77,195 @@ func TestStateMerkleRoot(t *testing.T) {
 	}
 
 }
+
+func TestStateTrieRoot(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	db := NewMemStateStore(0)
+	contract := common.Address{1}
+	storageKey := func(key string) []byte {
+		return append([]byte{byte(scom.ST_STORAGE)}, append(contract[:], key...)...)
+	}
+	commit := func(height uint32, writeSet *overlaydb.MemDB) common.Uint256 {
+		root, nodes, err := db.CalcStateTrieRoot(height, writeSet)
+		assert.Nil(t, err)
+		db.NewBatch()
+		writeSet.ForEach(func(key, val []byte) {
+			if len(val) == 0 {
+				db.BatchDeleteRawKey(key)
+			} else {
+				db.BatchPutRawKeyVal(key, val)
+			}
+		})
+		assert.Nil(t, db.AddStateTrieRoot(height, root, nodes))
+		assert.Nil(t, db.CommitTo())
+		return root
+	}
+
+	writeSet := overlaydb.NewMemDB(0, 0)
+	for i := 0; i < 10; i++ {
+		writeSet.Put(storageKey(fmt.Sprintf("key%d", i)), states.GenRawStorageItem([]byte(fmt.Sprintf("value%d", i))))
+	}
+	writeSet.Put([]byte{byte(scom.ST_BOOKKEEPER)}, []byte("not in trie"))
+	root0 := commit(0, writeSet)
+
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Put(storageKey("key3"), states.GenRawStorageItem([]byte("changed")))
+	writeSet.Delete(storageKey("key4"))
+	root1 := commit(1, writeSet)
+	assert.NotEqual(t, root0, root1)
+
+	key := append(contract[:], "key3"...)
+	root, value, proof, err := db.GetStorageProof(0, key)
+	assert.Nil(t, err)
+	assert.Equal(t, root0, root)
+	assert.Equal(t, []byte("value3"), value)
+	assert.Nil(t, smt.VerifyProofBytes(root, key, value, proof))
+
+	root, value, proof, err = db.GetStorageProof(1, key)
+	assert.Nil(t, err)
+	assert.Equal(t, root1, root)
+	assert.Equal(t, []byte("changed"), value)
+	assert.Nil(t, smt.VerifyProofBytes(root, key, value, proof))
+
+	key = append(contract[:], "key4"...)
+	root, value, proof, err = db.GetStorageProof(1, key)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	assert.Nil(t, smt.VerifyProofBytes(root, key, nil, proof))
+
+	// storage item which can not be decoded
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Put(storageKey("key5"), []byte{0xff})
+	_, _, err = db.CalcStateTrieRoot(2, writeSet)
+	assert.NotNil(t, err)
+
+	// a db without trie root rebuilds the same root from storage
+	assert.Nil(t, db.initStateTrie(2))
+	assert.Equal(t, root1, db.stateTrieRoot)
+	root, _, _, err = db.GetStorageProof(2, key)
+	assert.Nil(t, err)
+	assert.Equal(t, root1, root)
+
+	// the trie is released STATE_TRIE_KEEP_HEIGHTS blocks later, with the nodes only referenced by it
+	leaf := genStateTrieNodeKey(smt.HashLeaf(smt.HashKey(append(contract[:], "key4"...)), smt.HashValue([]byte("value4"))))
+	_, err = db.store.Get(leaf)
+	assert.Nil(t, err)
+	for height := uint32(3); height <= STATE_TRIE_KEEP_HEIGHTS+1; height++ {
+		writeSet = overlaydb.NewMemDB(0, 0)
+		writeSet.Put(storageKey("key3"), states.GenRawStorageItem([]byte(fmt.Sprintf("value%d", height))))
+		commit(height, writeSet)
+	}
+	_, _, _, err = db.GetStorageProof(0, key)
+	assert.NotNil(t, err)
+	_, err = db.store.Get(leaf)
+	assert.Equal(t, scom.ErrNotFound, err)
+	key = append(contract[:], "key0"...)
+	root, value, proof, err = db.GetStorageProof(STATE_TRIE_KEEP_HEIGHTS+1, key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value0"), value)
+	assert.Nil(t, smt.VerifyProofBytes(root, key, value, proof))
+	root, value, proof, err = db.GetStorageProof(2, key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value0"), value)
+	assert.Nil(t, smt.VerifyProofBytes(root, key, value, proof))
+
+	// no state trie below the state root height
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	_, _, _, err = db.GetStorageProof(STATE_TRIE_KEEP_HEIGHTS+1, key)
+	assert.NotNil(t, err)
+	root, nodes, err := db.CalcStateTrieRoot(STATE_TRIE_KEEP_HEIGHTS+2, writeSet)
+	assert.Nil(t, err)
+	assert.Equal(t, common.UINT256_EMPTY, root)
+	assert.Nil(t, nodes)
+}
+
+func TestStateHistory(t *testing.T) {
+	db := NewMemStateStore(0)
+	commit := func(height uint32, archive bool, writeSet *overlaydb.MemDB) {
+		db.NewBatch()
+		if archive {
+			assert.Nil(t, db.AddStateHistory(height, writeSet))
+		}
+		writeSet.ForEach(func(key, val []byte) {
+			if len(val) == 0 {
+				db.BatchDeleteRawKey(key)
+			} else {
+				db.BatchPutRawKeyVal(key, val)
+			}
+		})
+		assert.Nil(t, db.SaveCurrentBlock(height, common.Uint256{byte(height)}))
+		assert.Nil(t, db.CommitTo())
+	}
+	key1, key2, key3 := []byte("key1"), []byte("key2"), []byte("key")
+
+	writeSet := overlaydb.NewMemDB(0, 0)
+	writeSet.Put(key1, []byte("a0"))
+	writeSet.Put(key3, []byte("c0"))
+	commit(0, false, writeSet)
+
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Put(key1, []byte("a1"))
+	writeSet.Put(key2, []byte("b1"))
+	commit(1, true, writeSet)
+
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Delete(key2)
+	commit(2, true, writeSet)
+
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Put(key1, []byte("a3"))
+	commit(3, true, writeSet)
+
+	start, end, err := db.GetStateHistoryRange()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), start)
+	assert.Equal(t, uint32(3), end)
+
+	expects := []map[string]string{
+		{"key1": "a0", "key": "c0"},
+		{"key1": "a1", "key2": "b1", "key": "c0"},
+		{"key1": "a1", "key": "c0"},
+		{"key1": "a3", "key": "c0"},
+	}
+	for height, expect := range expects {
+		for _, key := range [][]byte{key1, key2, key3} {
+			value, err := db.GetStateAt(uint32(height), key)
+			if v, ok := expect[string(key)]; ok {
+				assert.Nil(t, err)
+				assert.Equal(t, []byte(v), value, "height %d key %s", height, key)
+			} else {
+				assert.Equal(t, scom.ErrNotFound, err, "height %d key %s", height, key)
+			}
+		}
+	}
+	_, err = db.GetStateAt(4, key1)
+	assert.NotNil(t, err)
+
+	overlay, err := db.NewOverlayDBAt(1)
+	assert.Nil(t, err)
+	value, err := overlay.Get(key2)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("b1"), value)
+
+	// a block saved without archive breaks the history
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Put(key1, []byte("a4"))
+	commit(4, false, writeSet)
+	_, err = db.GetStateAt(3, key1)
+	assert.NotNil(t, err)
+	value, err = db.GetStateAt(4, key1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("a4"), value)
+
+	writeSet = overlaydb.NewMemDB(0, 0)
+	writeSet.Put(key1, []byte("a5"))
+	commit(5, true, writeSet)
+	value, err = db.GetStateAt(4, key1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("a4"), value)
+	_, err = db.GetStateAt(2, key1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,390 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/states"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/merkle/smt"
+)
+
+// The state trie is only kept from the block whose root is committed in the next header, see
+// config.GetStateRootHeight. It is built from the whole contract storage when the first such block
+// is executed, and updated with the write set of every block after. The nodes are reference counted
+// by the stored nodes and the kept roots, the root of a height is released STATE_TRIE_KEEP_HEIGHTS
+// blocks later unless in archive mode, and the nodes no longer referenced are deleted with it.
+
+const STATE_TRIE_KEEP_HEIGHTS = uint32(10000) //Number of latest heights whose state trie is kept out of archive mode
+
+//stateTrieRequired return whether the state trie root of block height is committed by consensus in the next block
+func stateTrieRequired(height uint32) bool {
+	return vconfig.StateRootRequired(height + 1)
+}
+
+// trieNodeStore reads trie nodes from the pending node set first and then from the state db.
+type trieNodeStore struct {
+	store   scom.PersistStore
+	pending *overlaydb.MemDB
+}
+
+func newTrieNodeStore(store scom.PersistStore) *trieNodeStore {
+	return &trieNodeStore{store: store, pending: overlaydb.NewMemDB(0, 0)}
+}
+
+func (self *trieNodeStore) GetNode(hash common.Uint256) ([]byte, error) {
+	key := genStateTrieNodeKey(hash)
+	if val, unknown := self.pending.Get(key); !unknown {
+		return val, nil
+	}
+	val, err := self.store.Get(key)
+	if err == scom.ErrNotFound {
+		return nil, nil
+	}
+	return val, err
+}
+
+func (self *trieNodeStore) PutNode(hash common.Uint256, data []byte) {
+	self.pending.Put(genStateTrieNodeKey(hash), data)
+}
+
+// trieRefs changes the reference counts of trie nodes in a block, a node is stored when it is first
+// referenced and deleted with its last reference.
+type trieRefs struct {
+	store   scom.PersistStore
+	nodes   *overlaydb.MemDB // new trie nodes of the block
+	changes *overlaydb.MemDB // changed trie nodes, reference counts and roots, a nil value is deleted
+}
+
+func (self *trieRefs) get(key []byte) ([]byte, error) {
+	if val, unknown := self.changes.Get(key); !unknown {
+		return val, nil
+	}
+	val, err := self.store.Get(key)
+	if err == scom.ErrNotFound {
+		return nil, nil
+	}
+	return val, err
+}
+
+func (self *trieRefs) refCount(hash common.Uint256) (uint32, error) {
+	value, err := self.get(genStateTrieRefKey(hash))
+	if err != nil {
+		return 0, err
+	}
+	if len(value) == 0 {
+		return 0, nil
+	}
+	if len(value) != 4 {
+		return 0, fmt.Errorf("invalid reference count %x of trie node %s", value, hash.ToHexString())
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+func (self *trieRefs) setRefCount(hash common.Uint256, count uint32) {
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, count)
+	self.changes.Put(genStateTrieRefKey(hash), value)
+}
+
+func (self *trieRefs) incRef(hash common.Uint256) error {
+	if hash == common.UINT256_EMPTY {
+		return nil
+	}
+	count, err := self.refCount(hash)
+	if err != nil {
+		return err
+	}
+	self.setRefCount(hash, count+1)
+	if count > 0 {
+		return nil
+	}
+	key := genStateTrieNodeKey(hash)
+	data, _ := self.nodes.Get(key)
+	if data == nil {
+		if data, err = self.get(key); err != nil {
+			return err
+		}
+	}
+	if data == nil {
+		return fmt.Errorf("trie node %s missing", hash.ToHexString())
+	}
+	self.changes.Put(key, data)
+	for _, child := range trieNodeChildren(data) {
+		if err = self.incRef(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *trieRefs) decRef(hash common.Uint256) error {
+	if hash == common.UINT256_EMPTY {
+		return nil
+	}
+	count, err := self.refCount(hash)
+	if err != nil {
+		return err
+	}
+	if count > 1 {
+		self.setRefCount(hash, count-1)
+		return nil
+	}
+	if count == 0 {
+		return fmt.Errorf("trie node %s is not referenced", hash.ToHexString())
+	}
+	key := genStateTrieNodeKey(hash)
+	data, err := self.get(key)
+	if err != nil {
+		return err
+	}
+	self.changes.Delete(genStateTrieRefKey(hash))
+	self.changes.Delete(key)
+	for _, child := range trieNodeChildren(data) {
+		if err = self.decRef(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//trieNodeChildren return the children of an internal trie node, a leaf has none
+func trieNodeChildren(data []byte) []common.Uint256 {
+	if len(data) != smt.NODE_SIZE || data[0] != smt.INTERNAL_NODE {
+		return nil
+	}
+	var left, right common.Uint256
+	copy(left[:], data[1:1+common.UINT256_SIZE])
+	copy(right[:], data[1+common.UINT256_SIZE:])
+	return []common.Uint256{left, right}
+}
+
+// trieValue returns the value committed in the trie for a raw storage item, nil means deleted
+func trieValue(raw []byte) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	value, err := states.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return []byte{}, nil
+	}
+	return value, nil
+}
+
+//buildStateTrie build the state trie of the whole contract storage in db
+func (self *StateStore) buildStateTrie(nodes *trieNodeStore) (common.Uint256, int, error) {
+	tree := smt.NewTree(common.UINT256_EMPTY, nodes)
+	iter := self.store.NewIterator([]byte{byte(scom.ST_STORAGE)})
+	defer iter.Release()
+	count := 0
+	for iter.Next() {
+		value, err := trieValue(iter.Value())
+		if err != nil {
+			return common.UINT256_EMPTY, 0, fmt.Errorf("storage key %x error %s", iter.Key(), err)
+		}
+		if err = tree.Update(iter.Key()[1:], value); err != nil {
+			return common.UINT256_EMPTY, 0, err
+		}
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return common.UINT256_EMPTY, 0, err
+	}
+	return tree.Root(), count, nil
+}
+
+//CalcStateTrieRoot apply the storage changes of write set of block height to current state trie, return the new root
+//and the new trie nodes. The root is empty if the state trie is not required at height, and the trie is built from
+//storage if it is the first required height.
+func (self *StateStore) CalcStateTrieRoot(height uint32, writeSet *overlaydb.MemDB) (common.Uint256, *overlaydb.MemDB, error) {
+	if !stateTrieRequired(height) {
+		return common.UINT256_EMPTY, nil, nil
+	}
+	nodes := newTrieNodeStore(self.store)
+	root := self.stateTrieRoot
+	if !self.stateTrieBuilt {
+		var count int
+		var err error
+		if root, count, err = self.buildStateTrie(nodes); err != nil {
+			return common.UINT256_EMPTY, nil, fmt.Errorf("build state trie error %s", err)
+		}
+		log.Infof("state trie built with %d keys before block %d", count, height)
+	}
+	tree := smt.NewTree(root, nodes)
+	var err error
+	writeSet.ForEach(func(key, val []byte) {
+		if err != nil || len(key) == 0 || key[0] != byte(scom.ST_STORAGE) {
+			return
+		}
+		var value []byte
+		if value, err = trieValue(val); err != nil {
+			err = fmt.Errorf("storage key %x error %s", key, err)
+			return
+		}
+		err = tree.Update(key[1:], value)
+	})
+	if err != nil {
+		return common.UINT256_EMPTY, nil, err
+	}
+	return tree.Root(), nodes.pending, nil
+}
+
+//AddStateTrieRoot save the state trie root of block height and the new trie nodes referenced by it, and release the
+//root of STATE_TRIE_KEEP_HEIGHTS blocks before out of archive mode
+func (self *StateStore) AddStateTrieRoot(blockHeight uint32, root common.Uint256, nodes *overlaydb.MemDB) error {
+	if !stateTrieRequired(blockHeight) {
+		return nil
+	}
+	// the root of a recovered block is already saved
+	if _, err := self.GetStateTrieRoot(blockHeight); err != scom.ErrNotFound {
+		if err != nil {
+			return err
+		}
+		self.stateTrieRoot, self.stateTrieBuilt = root, true
+		return nil
+	}
+	if nodes == nil {
+		nodes = overlaydb.NewMemDB(0, 0)
+	}
+	refs := &trieRefs{store: self.store, nodes: nodes, changes: overlaydb.NewMemDB(0, 0)}
+	if err := refs.incRef(root); err != nil {
+		return fmt.Errorf("reference state trie root of height %d error %s", blockHeight, err)
+	}
+	refs.changes.Put(genStateTrieRootKey(blockHeight), root.ToArray())
+	if !config.DefConfig.Common.EnableArchive && blockHeight >= STATE_TRIE_KEEP_HEIGHTS {
+		height := blockHeight - STATE_TRIE_KEEP_HEIGHTS
+		oldRoot, err := self.GetStateTrieRoot(height)
+		if err != nil && err != scom.ErrNotFound {
+			return err
+		}
+		if err == nil {
+			if err = refs.decRef(oldRoot); err != nil {
+				return fmt.Errorf("release state trie root of height %d error %s", height, err)
+			}
+			refs.changes.Delete(genStateTrieRootKey(height))
+		}
+	}
+	refs.changes.ForEach(func(key, val []byte) {
+		if len(val) == 0 {
+			self.store.BatchDelete(key)
+		} else {
+			self.store.BatchPut(key, val)
+		}
+	})
+	self.stateTrieRoot, self.stateTrieBuilt = root, true
+	return nil
+}
+
+//GetStateTrieRoot return the state trie root after executing block of height
+func (self *StateStore) GetStateTrieRoot(height uint32) (common.Uint256, error) {
+	value, err := self.store.Get(genStateTrieRootKey(height))
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	return common.Uint256ParseFromBytes(value)
+}
+
+//GetStorageProof return the storage value of key at block height and the proof against the state trie root.
+//The value is nil if the key does not exist.
+func (self *StateStore) GetStorageProof(height uint32, key []byte) (common.Uint256, []byte, []byte, error) {
+	if !stateTrieRequired(height) {
+		return common.UINT256_EMPTY, nil, nil, fmt.Errorf("state root of height %d is not committed by consensus, "+
+			"state trie starts from the block before height %d", height, config.GetStateRootHeight(config.DefConfig.P2PNode.NetworkId))
+	}
+	root, err := self.GetStateTrieRoot(height)
+	if err == scom.ErrNotFound {
+		return common.UINT256_EMPTY, nil, nil, fmt.Errorf("state trie of height %d is released, only the latest %d "+
+			"heights are kept out of archive mode", height, STATE_TRIE_KEEP_HEIGHTS)
+	}
+	if err != nil {
+		return common.UINT256_EMPTY, nil, nil, fmt.Errorf("GetStateTrieRoot height:%d error %s", height, err)
+	}
+	tree := smt.NewTree(root, newTrieNodeStore(self.store))
+	value, proof, err := tree.Prove(key)
+	if err != nil {
+		return common.UINT256_EMPTY, nil, nil, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	proof.Serialization(sink)
+	return root, value, sink.Bytes(), nil
+}
+
+// initStateTrie load the state trie root of current height. The trie of a db whose current height passed the
+// state root height without the trie is rebuilt from the whole contract storage, otherwise it is built when
+// the state trie is first required.
+func (self *StateStore) initStateTrie(currBlockHeight uint32) error {
+	self.stateTrieRoot, self.stateTrieBuilt = common.UINT256_EMPTY, false
+	root, err := self.GetStateTrieRoot(currBlockHeight)
+	if err == nil {
+		self.stateTrieRoot, self.stateTrieBuilt = root, true
+		return nil
+	}
+	if err != scom.ErrNotFound {
+		return err
+	}
+	if !stateTrieRequired(currBlockHeight) {
+		return nil
+	}
+	log.Infof("state trie root of height %d not found, rebuilding from storage", currBlockHeight)
+	nodes := newTrieNodeStore(self.store)
+	root, count, err := self.buildStateTrie(nodes)
+	if err != nil {
+		return fmt.Errorf("rebuild state trie error %s", err)
+	}
+	self.store.NewBatch()
+	if err = self.AddStateTrieRoot(currBlockHeight, root, nodes.pending); err != nil {
+		return err
+	}
+	if err = self.store.BatchCommit(); err != nil {
+		return err
+	}
+	log.Infof("state trie rebuilt with %d keys, root:%s", count, root.ToHexString())
+	return nil
+}
+
+func genStateTrieNodeKey(hash common.Uint256) []byte {
+	key := make([]byte, 1+common.UINT256_SIZE)
+	key[0] = byte(scom.ST_STATE_TRIE)
+	copy(key[1:], hash[:])
+	return key
+}
+
+func genStateTrieRefKey(hash common.Uint256) []byte {
+	key := make([]byte, 1+common.UINT256_SIZE)
+	key[0] = byte(scom.ST_STATE_TRIE_REF)
+	copy(key[1:], hash[:])
+	return key
+}
+
+func genStateTrieRootKey(height uint32) []byte {
+	key := make([]byte, 5, 5)
+	key[0] = byte(scom.DATA_STATE_TRIE_ROOT)
+	binary.LittleEndian.PutUint32(key[1:], height)
+	return key
+}
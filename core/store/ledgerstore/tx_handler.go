@@ -41,10 +41,25 @@ func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay
 	if err != nil {
 		return nil, fmt.Errorf("HandleInvokeTransaction Error: %+v\n", err)
 	}
-	if _, err := service.Invoke(); err != nil {
+	_, err = service.Invoke()
+	notify.GasConsumed = service.GetGasUsed()
+	var feeNotify *event.NotifyEventInfo
+	if err == nil {
+		feeNotify, err = service.ChargeGasFee(false)
+	}
+	if err != nil {
+		//the failed transaction is reverted, but still pays the gas it consumed as far as the balance of payer covers
+		cache.Reset()
+		if feeNotify, e := service.ChargeGasFee(true); e == nil && feeNotify != nil {
+			notify.Notify = append(notify.Notify, feeNotify)
+			cache.Commit()
+		}
 		return nil, err
 	}
 	notify.Notify = append(notify.Notify, service.GetNotify()...)
+	if feeNotify != nil {
+		notify.Notify = append(notify.Notify, feeNotify)
+	}
 	notify.State = event.CONTRACT_STATE_SUCCESS
 	service.GetCacheDB().Commit()
 	return service.GetCrossHashes(), nil
@@ -74,6 +74,24 @@ func NewLevelDBStore(file string) (*LevelDBStore, error) {
 	}, nil
 }
 
+//NewReadOnlyLevelDBStore open an existing LevelDBStore for reading only, writes to the store return error
+func NewReadOnlyLevelDBStore(file string) (*LevelDBStore, error) {
+	o := opt.Options{
+		ReadOnly:       true,
+		ErrorIfMissing: true,
+		Filter:         filter.NewBloomFilter(BITSPERKEY),
+	}
+	db, err := leveldb.OpenFile(file, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LevelDBStore{
+		db:    db,
+		batch: nil,
+	}, nil
+}
+
 func NewMemLevelDBStore() (*LevelDBStore, error) {
 	store := storage.NewMemStorage()
 	// default Options
@@ -157,3 +175,10 @@ func (self *LevelDBStore) NewIterator(prefix []byte) common.StoreIterator {
 
 	return iter
 }
+
+//NewRangeIterator return a iterator of leveldb with the key prefix, starting from the key start
+func (self *LevelDBStore) NewRangeIterator(prefix []byte, start []byte) common.StoreIterator {
+	r := util.BytesPrefix(prefix)
+	r.Start = start
+	return self.db.NewIterator(r, nil)
+}
@@ -19,9 +19,13 @@
 package store
 
 import (
+	"io"
+	"time"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/core/states"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native/event"
@@ -34,7 +38,10 @@ type ExecuteResult struct {
 	CrossStatesRoot common.Uint256
 	Hash            common.Uint256
 	MerkleRoot      common.Uint256
+	StateRoot       common.Uint256   // state trie root after execution
+	StateTrieSet    *overlaydb.MemDB // new state trie nodes
 	Notify          []*event.ExecuteNotify
+	CrossTxs        []*scom.CrossTx // cross chain txs imported or proved in block
 }
 
 // LedgerStore provides func with store package.
@@ -47,6 +54,7 @@ type LedgerStore interface {
 	SubmitBlock(b *types.Block, exec ExecuteResult) error // called by consensus
 	GetStateMerkleRoot(height uint32) (result common.Uint256, err error)
 	GetCrossStateRoot(height uint32) (result common.Uint256, err error)
+	GetStateRoot(height uint32) (result common.Uint256, err error)
 	GetCurrentBlockHash() common.Uint256
 	GetCurrentBlockHeight() uint32
 	GetCurrentHeaderHeight() uint32
@@ -62,9 +70,20 @@ type LedgerStore interface {
 	GetBlockRootWithPreBlockHashes(startHeight uint32, txRoots []common.Uint256) common.Uint256
 	GetMerkleProof(raw []byte, m, n uint32) ([]byte, error)
 	GetCrossStatesProof(height uint32, key []byte) ([]byte, error)
+	GetStorageProof(height uint32, key []byte) (root common.Uint256, value []byte, proof []byte, err error)
 	GetBookkeeperState() (*states.BookkeeperState, error)
 	GetStorageItem(key *states.StorageKey) (*states.StorageItem, error)
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
+	GetStorageItemAt(key *states.StorageKey, height uint32) (*states.StorageItem, error)
+	PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstates.PreExecResult, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	GetCrossTx(fromChainID uint64, id []byte) (*scom.CrossTx, error)
+	ListCrossTxs(fromChainID uint64, height uint32, crossChainID []byte, limit uint32) ([]*scom.CrossTx, error)
+	GetEventLogTxs(address *common.Address, topic *common.Uint256, startHeight, endHeight, limit uint32) ([]*scom.EventLogTx, error)
+	GetPruneHeight() uint32
+	PruneBlocks(height uint32) error
+	ExportSnapshot(w io.Writer) (*scom.SnapshotInfo, error)
+	ImportSnapshot(r io.Reader, genesisBlock *types.Block, nextHeader *types.Header) (*scom.SnapshotInfo, error)
+	ReplayBlock(height uint32, clockSkew time.Duration) (*scom.BlockReplay, error)
 }
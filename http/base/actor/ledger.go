@@ -21,6 +21,7 @@ package actor
 import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/core/ledger"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native/event"
 	cstate "github.com/polynetwork/poly/native/states"
@@ -76,6 +77,11 @@ func GetStorageItem(address common.Address, key []byte) ([]byte, error) {
 	return ledger.DefLedger.GetStorageItem(address, key)
 }
 
+//GetStorageItemAt from ledger
+func GetStorageItemAt(address common.Address, key []byte, height uint32) ([]byte, error) {
+	return ledger.DefLedger.GetStorageItemAt(address, key, height)
+}
+
 //GetTxnWithHeightByTxHash from ledger
 func GetTxnWithHeightByTxHash(hash common.Uint256) (uint32, *types.Transaction, error) {
 	tx, height, err := ledger.DefLedger.GetTransactionWithHeight(hash)
@@ -87,6 +93,11 @@ func PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
 	return ledger.DefLedger.PreExecuteContract(tx)
 }
 
+//PreExecuteContractAt from ledger
+func PreExecuteContractAt(tx *types.Transaction, height uint32) (*cstate.PreExecResult, error) {
+	return ledger.DefLedger.PreExecuteContractAt(tx, height)
+}
+
 //GetEventNotifyByTxHash from ledger
 func GetEventNotifyByTxHash(txHash common.Uint256) (*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByTx(txHash)
@@ -97,6 +108,21 @@ func GetEventNotifyByHeight(height uint32) ([]*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByBlock(height)
 }
 
+//GetCrossTx from ledger
+func GetCrossTx(fromChainID uint64, id []byte) (*scom.CrossTx, error) {
+	return ledger.DefLedger.GetCrossTx(fromChainID, id)
+}
+
+//ListCrossTxs from ledger
+func ListCrossTxs(fromChainID uint64, height uint32, crossChainID []byte, limit uint32) ([]*scom.CrossTx, error) {
+	return ledger.DefLedger.ListCrossTxs(fromChainID, height, crossChainID, limit)
+}
+
+//GetEventLogTxs from ledger
+func GetEventLogTxs(address *common.Address, topic *common.Uint256, startHeight, endHeight, limit uint32) ([]*scom.EventLogTx, error) {
+	return ledger.DefLedger.GetEventLogTxs(address, topic, startHeight, endHeight, limit)
+}
+
 //GetMerkleProof from ledger
 func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]byte, error) {
 	return ledger.DefLedger.GetMerkleProof(proofHeight, rootHeight)
@@ -109,3 +135,7 @@ func GetCrossStatesProof(height uint32, key []byte) ([]byte, error) {
 func GetCrossStateRoot(height uint32) (common.Uint256, error) {
 	return ledger.DefLedger.GetCrossStateRoot(height)
 }
+
+func GetStorageProof(height uint32, key []byte) (common.Uint256, []byte, []byte, error) {
+	return ledger.DefLedger.GetStorageProof(height, key)
+}
@@ -20,17 +20,28 @@
 package common
 
 import (
+	"math/big"
+	"sort"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	ontErrors "github.com/polynetwork/poly/errors"
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	"github.com/polynetwork/poly/native/event"
+	crosscommon "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_incentive"
+	"github.com/polynetwork/poly/native/service/utils"
 	cstate "github.com/polynetwork/poly/native/states"
 )
 
 const MAX_SEARCH_HEIGHT uint32 = 100
+const MAX_CROSS_TX_LIMIT uint32 = 100
+const MAX_PROPOSAL_LIMIT uint32 = 100
 
 type BalanceOfRsp struct {
 	Ont string `json:"ont"`
@@ -42,6 +53,109 @@ type MerkleProof struct {
 	AuditPath string
 }
 
+type StorageProof struct {
+	Height       uint32
+	CommitHeight uint32 //height of the block committing StateRoot in its vbft block info
+	StateRoot    string
+	Exist        bool
+	Value        string
+	Proof        string
+}
+
+type CrossChainTx struct {
+	FromChainID  uint64
+	CrossChainID string
+	SourceTxHash string
+	ToChainID    uint64
+	PolyTxHash   string
+	Height       uint32
+	ProofHeight  uint32
+	RequestKey   string
+	Status       string
+}
+
+type RelayerReward struct {
+	ChainID   uint64
+	Claimable string
+	Earned    string
+	Withdrawn string
+	Headers   uint64
+	Transfers uint64
+}
+
+type RateLimitBudget struct {
+	FromChainID uint64
+	ToChainID   uint64
+	ToContract  string
+	Window      uint32
+	Limit       uint64
+	AutoPause   bool
+	Height      uint32
+	Remaining   uint64
+	ResetHeight uint32
+	Paused      bool
+}
+
+type PendingTx struct {
+	FromChainID   uint64
+	ToChainID     uint64
+	TxHash        string
+	SourceTxHash  string
+	ToContract    string
+	Method        string
+	ReleaseHeight uint32
+}
+
+type StakingInfo struct {
+	Enabled        bool
+	MinSelfStake   string
+	MaxValidators  uint32
+	UnbondingViews uint32
+	RewardPool     string
+}
+
+type ValidatorStake struct {
+	PeerPubkey string
+	Index      uint32
+	Status     uint8
+	Owner      string
+	SelfStake  string
+	TotalStake string
+}
+
+type Delegation struct {
+	PeerPubkey    string
+	Address       string
+	Amount        string
+	PendingReward string
+}
+
+type Unbonding struct {
+	Amount      string
+	ReleaseView uint32
+}
+
+type StakeAccount struct {
+	Address   string
+	Balance   string
+	Unbonding []Unbonding
+}
+
+type Proposal struct {
+	ID        uint64
+	Type      string
+	Content   string
+	Proposer  string
+	Height    uint32
+	EndTime   uint32
+	Status    string
+	Yes       int //yes votes of current consensus peers
+	No        int //no votes of current consensus peers
+	Threshold int //yes votes needed to pass
+	Voters    int //number of current consensus peers
+	Votes     map[string]bool
+}
+
 type LogEventArgs struct {
 	TxHash          string
 	ContractAddress string
@@ -59,6 +173,7 @@ type PreExecuteResult struct {
 	State  byte
 	Result interface{}
 	Notify []NotifyEventInfo
+	Gas    uint64
 }
 
 type NotifyEventInfo struct {
@@ -162,12 +277,26 @@ func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify)
 	return contractAddrs, ExecuteNotify{txhash, obj.State, obj.GasConsumed, evts}
 }
 
+func GetCrossChainTx(tx *scom.CrossTx) CrossChainTx {
+	return CrossChainTx{
+		FromChainID:  tx.FromChainID,
+		CrossChainID: common.ToHexString(tx.CrossChainID),
+		SourceTxHash: common.ToHexString(tx.SourceTxHash),
+		ToChainID:    tx.ToChainID,
+		PolyTxHash:   tx.PolyTxHash.ToHexString(),
+		Height:       tx.Height,
+		ProofHeight:  tx.ProofHeight,
+		RequestKey:   common.ToHexString(tx.RequestKey),
+		Status:       tx.Status.String(),
+	}
+}
+
 func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	evts := []NotifyEventInfo{}
 	for _, v := range obj.Notify {
 		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
 	}
-	return PreExecuteResult{obj.State, obj.Result, evts}
+	return PreExecuteResult{obj.State, obj.Result, evts, obj.Gas}
 }
 
 func SendTxToPool(txn *types.Transaction) (ontErrors.ErrCode, string) {
@@ -276,3 +405,382 @@ func GetAddress(str string) (common.Address, error) {
 	}
 	return address, err
 }
+
+//GetRelayerRewards return the rewards of relayer on the side chains, or on all side chains with reward if chainIDs is empty
+func GetRelayerRewards(relayer common.Address, chainIDs []uint64) ([]RelayerReward, error) {
+	contract := utils.RelayerIncentiveContractAddress
+	if len(chainIDs) == 0 {
+		value, err := bactor.GetStorageItem(contract, relayer_incentive.RewardChainsKey(relayer))
+		if err != nil && err != scom.ErrNotFound {
+			return nil, err
+		}
+		chains := new(relayer_incentive.RewardChains)
+		if len(value) > 0 {
+			if err := chains.Deserialization(common.NewZeroCopySource(value)); err != nil {
+				return nil, err
+			}
+		}
+		chainIDs = chains.ChainIDs
+	}
+	rewards := make([]RelayerReward, 0, len(chainIDs))
+	for _, chainID := range chainIDs {
+		value, err := bactor.GetStorageItem(contract, relayer_incentive.RewardKey(relayer, chainID))
+		if err != nil && err != scom.ErrNotFound {
+			return nil, err
+		}
+		reward := relayer_incentive.NewRelayerReward()
+		if len(value) > 0 {
+			if err := reward.Deserialization(common.NewZeroCopySource(value)); err != nil {
+				return nil, err
+			}
+		}
+		rewards = append(rewards, RelayerReward{
+			ChainID:   chainID,
+			Claimable: reward.Claimable.String(),
+			Earned:    reward.Earned.String(),
+			Withdrawn: reward.Withdrawn.String(),
+			Headers:   reward.Headers,
+			Transfers: reward.Transfers,
+		})
+	}
+	return rewards, nil
+}
+
+//GetRateLimitBudget return the current budget of a rate limited cross chain pair, nil if the pair is not limited
+func GetRateLimitBudget(fromChainID, toChainID uint64, toContract []byte) (*RateLimitBudget, error) {
+	contract := utils.CrossChainManagerContractAddress
+	value, err := bactor.GetStorageItem(contract, crosscommon.RateLimitKey(fromChainID, toChainID, toContract))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	limit := new(crosscommon.RateLimit)
+	if err := limit.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	state := new(crosscommon.RateLimitState)
+	value, err = bactor.GetStorageItem(contract, crosscommon.RateLimitStateKey(fromChainID, toChainID, toContract))
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+	if len(value) > 0 {
+		if err := state.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+	}
+	budget := crosscommon.GetRateLimitBudget(limit, state, bactor.GetCurrentBlockHeight())
+	return &RateLimitBudget{
+		FromChainID: fromChainID,
+		ToChainID:   toChainID,
+		ToContract:  common.ToHexString(toContract),
+		Window:      budget.Window,
+		Limit:       budget.Limit,
+		AutoPause:   budget.AutoPause,
+		Height:      budget.Height,
+		Remaining:   budget.Remaining,
+		ResetHeight: budget.ResetHeight,
+		Paused:      budget.Paused,
+	}, nil
+}
+
+//GetPendingTxs return the delayed cross chain txs to the target chain, or to all chains if toChainID is nil
+func GetPendingTxs(toChainID *uint64) ([]PendingTx, error) {
+	contract := utils.CrossChainManagerContractAddress
+	value, err := bactor.GetStorageItem(contract, crosscommon.PendingTxCountKey())
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+	count := utils.GetBytesUint64(value)
+	txs := make([]PendingTx, 0)
+	for index := uint64(0); index < count; index++ {
+		value, err := bactor.GetStorageItem(contract, crosscommon.PendingTxIndexKey(index))
+		if err != nil {
+			return nil, err
+		}
+		id := new(crosscommon.PendingTxID)
+		if err := id.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+		if toChainID != nil && id.ToChainID != *toChainID {
+			continue
+		}
+		value, err = bactor.GetStorageItem(contract, crosscommon.PendingTxKey(id.ToChainID, id.TxHash))
+		if err != nil {
+			return nil, err
+		}
+		pending := new(crosscommon.PendingTx)
+		if err := pending.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+		merkleValue := new(crosscommon.ToMerkleValue)
+		if err := merkleValue.Deserialization(common.NewZeroCopySource(pending.Value)); err != nil {
+			return nil, err
+		}
+		txs = append(txs, PendingTx{
+			FromChainID:   merkleValue.FromChainID,
+			ToChainID:     id.ToChainID,
+			TxHash:        common.ToHexString(id.TxHash),
+			SourceTxHash:  common.ToHexString(merkleValue.MakeTxParam.TxHash),
+			ToContract:    common.ToHexString(merkleValue.MakeTxParam.ToContractAddress),
+			Method:        merkleValue.MakeTxParam.Method,
+			ReleaseHeight: pending.ReleaseHeight,
+		})
+	}
+	return txs, nil
+}
+
+func getNodeManagerValue(key []byte) ([]byte, error) {
+	value, err := bactor.GetStorageItem(utils.NodeManagerContractAddress, key)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+	return value, nil
+}
+
+func getCurrentPeerPool() (*node_manager.PeerPoolMap, error) {
+	value, err := getNodeManagerValue([]byte(node_manager.GOVERNANCE_VIEW))
+	if err != nil {
+		return nil, err
+	}
+	governanceView := new(node_manager.GovernanceView)
+	if err := governanceView.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	value, err = getNodeManagerValue(append([]byte(node_manager.PEER_POOL), utils.GetUint32Bytes(governanceView.View)...))
+	if err != nil {
+		return nil, err
+	}
+	peerPoolMap := new(node_manager.PeerPoolMap)
+	if err := peerPoolMap.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	return peerPoolMap, nil
+}
+
+func getValidatorStake(peerPubkey string) (*node_manager.ValidatorStake, error) {
+	key, err := node_manager.ValidatorStakeKey(peerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	value, err := getNodeManagerValue(key)
+	if err != nil {
+		return nil, err
+	}
+	validator := node_manager.NewValidatorStake(peerPubkey, common.ADDRESS_EMPTY)
+	if len(value) > 0 {
+		if err := validator.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+	}
+	return validator, nil
+}
+
+//GetStakingInfo return the staking config and the undistributed reward, Enabled is false on approval only networks
+func GetStakingInfo() (*StakingInfo, error) {
+	info := new(StakingInfo)
+	value, err := getNodeManagerValue([]byte(node_manager.STAKING_CONFIG))
+	if err != nil {
+		return nil, err
+	}
+	if len(value) > 0 {
+		stakingConfig := new(node_manager.StakingConfig)
+		if err := stakingConfig.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+		info.Enabled = true
+		info.MinSelfStake = stakingConfig.MinSelfStake.String()
+		info.MaxValidators = stakingConfig.MaxValidators
+		info.UnbondingViews = stakingConfig.UnbondingViews
+	}
+	value, err = getNodeManagerValue([]byte(node_manager.REWARD_POOL))
+	if err != nil {
+		return nil, err
+	}
+	info.RewardPool = new(big.Int).SetBytes(value).String()
+	return info, nil
+}
+
+//GetValidatorStakes return the stake of peers in the current peer pool ordered by total stake, or of the given peer only
+func GetValidatorStakes(peerPubkey string) ([]ValidatorStake, error) {
+	peerPoolMap, err := getCurrentPeerPool()
+	if err != nil {
+		return nil, err
+	}
+	stakes := make([]ValidatorStake, 0)
+	validators := make(map[string]*node_manager.ValidatorStake)
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPubkey != "" && peerPoolItem.PeerPubkey != peerPubkey {
+			continue
+		}
+		validator, err := getValidatorStake(peerPoolItem.PeerPubkey)
+		if err != nil {
+			return nil, err
+		}
+		validators[peerPoolItem.PeerPubkey] = validator
+		stakes = append(stakes, ValidatorStake{
+			PeerPubkey: peerPoolItem.PeerPubkey,
+			Index:      peerPoolItem.Index,
+			Status:     uint8(peerPoolItem.Status),
+			Owner:      peerPoolItem.Address.ToBase58(),
+			SelfStake:  validator.SelfStake.String(),
+			TotalStake: validator.TotalStake.String(),
+		})
+	}
+	sort.SliceStable(stakes, func(i, j int) bool {
+		if c := validators[stakes[i].PeerPubkey].TotalStake.Cmp(validators[stakes[j].PeerPubkey].TotalStake); c != 0 {
+			return c > 0
+		}
+		return stakes[i].Index < stakes[j].Index
+	})
+	return stakes, nil
+}
+
+//GetDelegation return the stake and the unsettled reward of address on peer
+func GetDelegation(peerPubkey string, address common.Address) (*Delegation, error) {
+	validator, err := getValidatorStake(peerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := node_manager.DelegationKey(peerPubkey, address)
+	if err != nil {
+		return nil, err
+	}
+	value, err := getNodeManagerValue(key)
+	if err != nil {
+		return nil, err
+	}
+	delegation := node_manager.NewDelegation()
+	if len(value) > 0 {
+		if err := delegation.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+	}
+	return &Delegation{
+		PeerPubkey:    peerPubkey,
+		Address:       address.ToBase58(),
+		Amount:        delegation.Amount.String(),
+		PendingReward: delegation.PendingReward(validator).String(),
+	}, nil
+}
+
+//GetStakeAccount return the stake balance and the unbonding stake of address
+func GetStakeAccount(address common.Address) (*StakeAccount, error) {
+	value, err := getNodeManagerValue(node_manager.StakeBalanceKey(address))
+	if err != nil {
+		return nil, err
+	}
+	account := &StakeAccount{
+		Address:   address.ToBase58(),
+		Balance:   new(big.Int).SetBytes(value).String(),
+		Unbonding: make([]Unbonding, 0),
+	}
+	value, err = getNodeManagerValue(node_manager.UnbondingKey(address))
+	if err != nil {
+		return nil, err
+	}
+	unbondingList := new(node_manager.UnbondingList)
+	if len(value) > 0 {
+		if err := unbondingList.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range unbondingList.Items {
+		account.Unbonding = append(account.Unbonding, Unbonding{
+			Amount:      item.Amount.String(),
+			ReleaseView: item.ReleaseView,
+		})
+	}
+	return account, nil
+}
+
+type proposalReader struct {
+	voters map[common.Address]bool
+	time   uint32
+}
+
+func newProposalReader() (*proposalReader, error) {
+	peerPoolMap, err := getCurrentPeerPool()
+	if err != nil {
+		return nil, err
+	}
+	voters, err := proposal_manager.ConsensusVoters(peerPoolMap)
+	if err != nil {
+		return nil, err
+	}
+	header, err := bactor.GetHeaderByHeight(bactor.GetCurrentBlockHeight())
+	if err != nil {
+		return nil, err
+	}
+	return &proposalReader{voters: voters, time: header.Timestamp}, nil
+}
+
+func (this *proposalReader) get(id uint64) (*Proposal, error) {
+	value, err := bactor.GetStorageItem(utils.ProposalManagerContractAddress, proposal_manager.ProposalKey(id))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	proposal := new(proposal_manager.Proposal)
+	if err := proposal.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	yes, no, threshold := proposal.Tally(this.voters)
+	result := &Proposal{
+		ID:        proposal.ID,
+		Type:      proposal.Type.String(),
+		Content:   common.ToHexString(proposal.Content),
+		Proposer:  proposal.Proposer.ToBase58(),
+		Height:    proposal.Height,
+		EndTime:   proposal.EndTime,
+		Status:    proposal.State(this.time).String(),
+		Yes:       yes,
+		No:        no,
+		Threshold: threshold,
+		Voters:    len(this.voters),
+		Votes:     make(map[string]bool),
+	}
+	for address, approve := range proposal.Votes {
+		result.Votes[address.ToBase58()] = approve
+	}
+	return result, nil
+}
+
+//GetProposal return the governance proposal of id with votes tallied against current consensus peers, nil if
+//it does not exist
+func GetProposal(id uint64) (*Proposal, error) {
+	reader, err := newProposalReader()
+	if err != nil {
+		return nil, err
+	}
+	return reader.get(id)
+}
+
+//ListProposals return at most limit governance proposals from id downwards, from the latest one if id is 0
+func ListProposals(id uint64, limit uint32) ([]Proposal, error) {
+	reader, err := newProposalReader()
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		value, err := bactor.GetStorageItem(utils.ProposalManagerContractAddress, []byte(proposal_manager.PROPOSAL_ID))
+		if err != nil && err != scom.ErrNotFound {
+			return nil, err
+		}
+		id = utils.GetBytesUint64(value)
+	}
+	proposals := make([]Proposal, 0)
+	for ; id > 0 && uint32(len(proposals)) < limit; id-- {
+		proposal, err := reader.get(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal != nil {
+			proposals = append(proposals, *proposal)
+		}
+	}
+	return proposals, nil
+}
@@ -29,6 +29,7 @@ import (
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	bcomn "github.com/polynetwork/poly/http/base/common"
 	berr "github.com/polynetwork/poly/http/base/error"
+	cstate "github.com/polynetwork/poly/native/states"
 	"strconv"
 )
 
@@ -53,6 +54,16 @@ func GetNetworkId(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+//get the min gas price accepted by tx pool
+func GetGasPrice(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	resp["Result"] = map[string]interface{}{
+		"gasprice": config.DefConfig.Common.GasPrice,
+		"height":   bactor.GetCurrentBlockHeight(),
+	}
+	return resp
+}
+
 //get connection node count
 func GetConnectionCount(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -261,9 +272,18 @@ func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("SendRawTransaction recv %s", hash.ToHexString())
 	if txn.TxType == types.Invoke || txn.TxType == types.Deploy {
 		if preExec, ok := cmd["PreExec"].(string); ok && preExec == "1" {
-			rst, err := bactor.PreExecuteContract(txn)
+			var rst *cstate.PreExecResult
+			if str, ok := cmd["Height"].(string); ok && str != "" {
+				height, e := strconv.ParseUint(str, 10, 32)
+				if e != nil {
+					return ResponsePack(berr.INVALID_PARAMS)
+				}
+				rst, err = bactor.PreExecuteContractAt(txn, uint32(height))
+			} else {
+				rst, err = bactor.PreExecuteContract(txn)
+			}
 			if err != nil {
-				log.Infof("PreExec: ", err)
+				log.Infof("PreExec: %s", err)
 				resp = ResponsePack(berr.SMARTCODE_ERROR)
 				resp["Result"] = err.Error()
 				return resp
@@ -363,17 +383,99 @@ func GetStorage(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	value, err := bactor.GetStorageItem(address, item)
+	var value []byte
+	if str, ok := cmd["Height"].(string); ok && str != "" {
+		height, e := strconv.ParseUint(str, 10, 32)
+		if e != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		value, err = bactor.GetStorageItemAt(address, item, uint32(height))
+	} else {
+		value, err = bactor.GetStorageItem(address, item)
+	}
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return ResponsePack(berr.SUCCESS)
 		}
-		return ResponsePack(berr.INTERNAL_ERROR)
+		resp = ResponsePack(berr.INTERNAL_ERROR)
+		resp["Result"] = err.Error()
+		return resp
 	}
 	resp["Result"] = common.ToHexString(value)
 	return resp
 }
 
+//get the lifecycle of a cross chain tx by source chain id and cross chain id or source tx hash
+func GetCrossChainTx(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["ChainId"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	chainID, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	str, ok = cmd["Id"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	id, err := hex.DecodeString(str)
+	if err != nil || len(id) == 0 {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	tx, err := bactor.GetCrossTx(chainID, id)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return ResponsePack(berr.SUCCESS)
+		}
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = bcomn.GetCrossChainTx(tx)
+	return resp
+}
+
+//list the cross chain txs from a source chain in height order, starting after the tx of height and id
+func ListCrossChainTxs(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["ChainId"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	chainID, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	height, limit := uint64(0), uint64(bcomn.MAX_CROSS_TX_LIMIT)
+	if str, ok = cmd["Height"].(string); ok && len(str) > 0 {
+		if height, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	var crossChainID []byte
+	if str, ok = cmd["Id"].(string); ok && len(str) > 0 {
+		if crossChainID, err = hex.DecodeString(str); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	if str, ok = cmd["Limit"].(string); ok && len(str) > 0 {
+		limit, err = strconv.ParseUint(str, 10, 32)
+		if err != nil || limit == 0 || limit > uint64(bcomn.MAX_CROSS_TX_LIMIT) {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	txs, err := bactor.ListCrossTxs(chainID, uint32(height), crossChainID, uint32(limit))
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	result := make([]bcomn.CrossChainTx, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, bcomn.GetCrossChainTx(tx))
+	}
+	resp["Result"] = result
+	return resp
+}
+
 //get merkle proof by transaction hash
 func GetMerkleProof(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -21,6 +21,7 @@ package rpc
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -32,6 +33,7 @@ import (
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	bcomn "github.com/polynetwork/poly/http/base/common"
 	berr "github.com/polynetwork/poly/http/base/error"
+	cstate "github.com/polynetwork/poly/native/states"
 )
 
 //get best block hash
@@ -270,12 +272,22 @@ func GetStorage(params []interface{}) map[string]interface{} {
 	default:
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
-	value, err := bactor.GetStorageItem(address, key)
+	var value []byte
+	var err error
+	if len(params) > 2 {
+		height, ok := params[2].(float64)
+		if !ok || height < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		value, err = bactor.GetStorageItemAt(address, key, uint32(height))
+	} else {
+		value, err = bactor.GetStorageItem(address, key)
+	}
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return responseSuccess(nil)
 		}
-		return responsePack(berr.INVALID_PARAMS, "")
+		return responsePack(berr.INVALID_PARAMS, err.Error())
 	}
 	return responseSuccess(common.ToHexString(value))
 }
@@ -305,9 +317,18 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 			if len(params) > 1 {
 				preExec, ok := params[1].(float64)
 				if ok && preExec == 1 {
-					result, err := bactor.PreExecuteContract(txn)
+					var result *cstate.PreExecResult
+					if len(params) > 2 {
+						height, ok := params[2].(float64)
+						if !ok || height < 0 {
+							return responsePack(berr.INVALID_PARAMS, "")
+						}
+						result, err = bactor.PreExecuteContractAt(txn, uint32(height))
+					} else {
+						result, err = bactor.PreExecuteContract(txn)
+					}
 					if err != nil {
-						log.Infof("PreExec: ", err)
+						log.Infof("PreExec: %s", err)
 						return responsePack(berr.SMARTCODE_ERROR, err.Error())
 					}
 					return responseSuccess(bcomn.ConvertPreExecuteResult(result))
@@ -337,6 +358,16 @@ func GetNetworkId(params []interface{}) map[string]interface{} {
 	return responseSuccess(config.DefConfig.P2PNode.NetworkId)
 }
 
+//get the min gas price accepted by tx pool
+// A JSON example for getgasprice method as following:
+//   {"jsonrpc": "2.0", "method": "getgasprice", "params": [], "id": 0}
+func GetGasPrice(params []interface{}) map[string]interface{} {
+	return responseSuccess(map[string]interface{}{
+		"gasprice": config.DefConfig.Common.GasPrice,
+		"height":   bactor.GetCurrentBlockHeight(),
+	})
+}
+
 //get smartconstract event
 func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if !config.DefConfig.Common.EnableEventLog {
@@ -457,6 +488,337 @@ func GetCrossStatesProof(params []interface{}) map[string]interface{} {
 	return responseSuccess(bcomn.MerkleProof{"CrossStatesProof", hex.EncodeToString(proof)})
 }
 
+//get storage value with state trie proof
+// A JSON example for getstorageproof method as following:
+//   {"jsonrpc": "2.0", "method": "getstorageproof", "params": ["contract address", "key in hex", height], "id": 0}
+// height is optional and defaults to the current block height. StateRoot is the root after executing block height,
+// it is committed in the vbft block info of the next block CommitHeight, so the proof of the current height can
+// only be checked against a signed header once the next block is committed. Proofs are only served from the block
+// before the state root height of the network, and for the latest heights kept out of archive mode.
+func GetStorageProof(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok = params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	key, err := hex.DecodeString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	height := bactor.GetCurrentBlockHeight()
+	if len(params) > 2 {
+		h, ok := params[2].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		height = uint32(h)
+	}
+	root, value, proof, err := bactor.GetStorageProof(height, append(address[:], key...))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.StorageProof{
+		Height:       height,
+		CommitHeight: height + 1,
+		StateRoot:    root.ToHexString(),
+		Exist:        value != nil,
+		Value:        hex.EncodeToString(value),
+		Proof:        hex.EncodeToString(proof),
+	})
+}
+
+//get the lifecycle of a cross chain tx
+// A JSON example for getcrosschaintx method as following:
+//   {"jsonrpc": "2.0", "method": "getcrosschaintx", "params": [source chain id, "cross chain id or source tx hash in hex"], "id": 0}
+func GetCrossChainTx(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	id, err := hex.DecodeString(str)
+	if err != nil || len(id) == 0 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	tx, err := bactor.GetCrossTx(uint64(chainID), id)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return responseSuccess(nil)
+		}
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.GetCrossChainTx(tx))
+}
+
+//list the cross chain txs from a source chain in height order
+// A JSON example for listcrosschaintxs method as following:
+//   {"jsonrpc": "2.0", "method": "listcrosschaintxs", "params": [source chain id, height, "cross chain id in hex", limit], "id": 0}
+// the page starts after the tx of height and cross chain id, the last tx of the previous page. height defaults to 0
+// and cross chain id to "" for the first page, limit defaults to bcomn.MAX_CROSS_TX_LIMIT
+func ListCrossChainTxs(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	height, limit := float64(0), float64(bcomn.MAX_CROSS_TX_LIMIT)
+	var crossChainID []byte
+	if len(params) > 1 {
+		if height, ok = params[1].(float64); !ok || height < 0 || height > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if len(params) > 2 {
+		str, ok := params[2].(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		var err error
+		if crossChainID, err = hex.DecodeString(str); err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if len(params) > 3 {
+		if limit, ok = params[3].(float64); !ok || limit <= 0 || limit > float64(bcomn.MAX_CROSS_TX_LIMIT) {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	txs, err := bactor.ListCrossTxs(uint64(chainID), uint32(height), crossChainID, uint32(limit))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := make([]bcomn.CrossChainTx, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, bcomn.GetCrossChainTx(tx))
+	}
+	return responseSuccess(result)
+}
+
+//get the rewards of a relayer
+// A JSON example for getrelayerreward method as following:
+//   {"jsonrpc": "2.0", "method": "getrelayerreward", "params": ["relayer address", side chain id], "id": 0}
+// side chain id is optional, the rewards on all side chains are returned if absent
+func GetRelayerReward(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	relayer, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var chainIDs []uint64
+	if len(params) > 1 {
+		chainID, ok := params[1].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		chainIDs = append(chainIDs, uint64(chainID))
+	}
+	rewards, err := bcomn.GetRelayerRewards(relayer, chainIDs)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(rewards)
+}
+
+//get the budget of a rate limited cross chain pair
+// A JSON example for getratelimit method as following:
+//   {"jsonrpc": "2.0", "method": "getratelimit", "params": [from chain id, to chain id, "hex of to contract"], "id": 0}
+// the result is null if the pair is not rate limited
+func GetRateLimit(params []interface{}) map[string]interface{} {
+	if len(params) < 3 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	fromChainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	toChainID, ok := params[1].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[2].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	toContract, err := common.HexToBytes(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	budget, err := bcomn.GetRateLimitBudget(uint64(fromChainID), uint64(toChainID), toContract)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(budget)
+}
+
+//get the delayed cross chain txs
+// A JSON example for getpendingtxs method as following:
+//   {"jsonrpc": "2.0", "method": "getpendingtxs", "params": [to chain id], "id": 0}
+// to chain id is optional, the pending txs to all chains are returned if absent
+func GetPendingTxs(params []interface{}) map[string]interface{} {
+	var toChainID *uint64
+	if len(params) > 0 {
+		chainID, ok := params[0].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		id := uint64(chainID)
+		toChainID = &id
+	}
+	txs, err := bcomn.GetPendingTxs(toChainID)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(txs)
+}
+
+//get the staking config of node_manager
+// A JSON example for getstakinginfo method as following:
+//   {"jsonrpc": "2.0", "method": "getstakinginfo", "params": [], "id": 0}
+func GetStakingInfo(params []interface{}) map[string]interface{} {
+	info, err := bcomn.GetStakingInfo()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(info)
+}
+
+//get the stake bonded to peers
+// A JSON example for getvalidatorstake method as following:
+//   {"jsonrpc": "2.0", "method": "getvalidatorstake", "params": ["peer pubkey"], "id": 0}
+// peer pubkey is optional, the stakes of all peers in current peer pool are returned if absent
+func GetValidatorStake(params []interface{}) map[string]interface{} {
+	var peerPubkey string
+	if len(params) > 0 {
+		str, ok := params[0].(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		peerPubkey = str
+	}
+	stakes, err := bcomn.GetValidatorStakes(peerPubkey)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(stakes)
+}
+
+//get the stake and pending reward of an address on a peer
+// A JSON example for getdelegation method as following:
+//   {"jsonrpc": "2.0", "method": "getdelegation", "params": ["peer pubkey", "address"], "id": 0}
+func GetDelegation(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	peerPubkey, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	delegation, err := bcomn.GetDelegation(peerPubkey, address)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(delegation)
+}
+
+//get the stake balance and unbonding stake of an address
+// A JSON example for getstakeaccount method as following:
+//   {"jsonrpc": "2.0", "method": "getstakeaccount", "params": ["address"], "id": 0}
+func GetStakeAccount(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	account, err := bcomn.GetStakeAccount(address)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(account)
+}
+
+//get a governance proposal by id
+// A JSON example for getproposal method as following:
+//   {"jsonrpc": "2.0", "method": "getproposal", "params": [proposal id], "id": 0}
+func GetProposal(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	id, ok := params[0].(float64)
+	if !ok || id < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	proposal, err := bcomn.GetProposal(uint64(id))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	if proposal == nil {
+		return responseSuccess(nil)
+	}
+	return responseSuccess(proposal)
+}
+
+//list the governance proposals from the newest
+// A JSON example for listproposals method as following:
+//   {"jsonrpc": "2.0", "method": "listproposals", "params": [from proposal id, limit], "id": 0}
+// from proposal id defaults to 0 for the latest proposal and limit defaults to bcomn.MAX_PROPOSAL_LIMIT
+func ListProposals(params []interface{}) map[string]interface{} {
+	var ok bool
+	id, limit := float64(0), float64(bcomn.MAX_PROPOSAL_LIMIT)
+	if len(params) > 0 {
+		if id, ok = params[0].(float64); !ok || id < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if len(params) > 1 {
+		if limit, ok = params[1].(float64); !ok || limit <= 0 || limit > float64(bcomn.MAX_PROPOSAL_LIMIT) {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	proposals, err := bcomn.ListProposals(uint64(id), uint32(limit))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(proposals)
+}
+
 func GetHeaderByHeight(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
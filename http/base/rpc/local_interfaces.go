@@ -122,3 +122,25 @@ func SetDebugInfo(params []interface{}) map[string]interface{} {
 	}
 	return responsePack(berr.SUCCESS, true)
 }
+
+//SetLogLevel update the log levels of modules by a spec like consensus/vbft=debug,p2pserver=warn,
+//return the levels after updating
+func SetLogLevel(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	switch params[0].(type) {
+	case string:
+		if err := log.SetLevels(params[0].(string)); err != nil {
+			return responsePack(berr.INVALID_PARAMS, err.Error())
+		}
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	return responseSuccess(log.GetLevels())
+}
+
+//GetLogLevel return the log levels of modules, module * is the level of modules without level
+func GetLogLevel(params []interface{}) map[string]interface{} {
+	return responseSuccess(log.GetLevels())
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package ethrpc privides an ethereum compatible json rpc server, which maps poly blocks,
+// transactions and notify events to eth blocks, transactions, receipts and logs
+package ethrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+
+	cfg "github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+)
+
+const (
+	ETH_PARSE_ERROR      = -32700
+	ETH_INVALID_REQUEST  = -32600
+	ETH_METHOD_NOT_FOUND = -32601
+	ETH_INVALID_PARAMS   = -32602
+	ETH_INTERNAL_ERROR   = -32603
+	ETH_LIMIT_EXCEEDED   = -32005
+
+	MAX_BATCH_SIZE = 100
+)
+
+type ethError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func invalidParams(msg string) *ethError {
+	return &ethError{Code: ETH_INVALID_PARAMS, Message: msg}
+}
+
+func internalError(err error) *ethError {
+	return &ethError{Code: ETH_INTERNAL_ERROR, Message: err.Error()}
+}
+
+func limitExceeded() *ethError {
+	return &ethError{Code: ETH_LIMIT_EXCEEDED, Message: fmt.Sprintf("query returned more than %d results", MAX_LOG_RESULTS)}
+}
+
+type ethRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  []interface{}   `json:"params"`
+}
+
+var (
+	handlerLock sync.RWMutex
+	handlers    = make(map[string]func([]interface{}) (interface{}, *ethError))
+)
+
+//HandleFunc register the handler of eth rpc method
+func HandleFunc(method string, handler func([]interface{}) (interface{}, *ethError)) {
+	handlerLock.Lock()
+	defer handlerLock.Unlock()
+	handlers[method] = handler
+}
+
+func StartEthRPCServer() error {
+	log.Debug()
+	HandleFunc("web3_clientVersion", ClientVersion)
+	HandleFunc("net_version", NetVersion)
+	HandleFunc("eth_chainId", ChainId)
+	HandleFunc("eth_blockNumber", BlockNumber)
+	HandleFunc("eth_getBlockByNumber", GetBlockByNumber)
+	HandleFunc("eth_getBlockByHash", GetBlockByHash)
+	HandleFunc("eth_getTransactionByHash", GetTransactionByHash)
+	HandleFunc("eth_getTransactionReceipt", GetTransactionReceipt)
+	HandleFunc("eth_getLogs", GetLogs)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", Handle)
+	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpEthPort)), mux)
+	if err != nil {
+		return fmt.Errorf("ListenAndServe error:%s", err)
+	}
+	return nil
+}
+
+//Handle serve a single request or a batch of requests
+func Handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != "POST" || r.Body == nil {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1*1024*1024)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error("Eth JSON RPC Handle - ioutil.ReadAll: ", err)
+		return
+	}
+	data, err := json.Marshal(handleBody(body))
+	if err != nil {
+		log.Error("Eth JSON RPC Handle - json.Marshal: ", err)
+		return
+	}
+	w.Write(data)
+}
+
+func handleBody(body []byte) interface{} {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		req := new(ethRequest)
+		if err := json.Unmarshal(body, req); err != nil {
+			return errorResponse(nil, &ethError{Code: ETH_PARSE_ERROR, Message: err.Error()})
+		}
+		return handleRequest(req)
+	}
+	var reqs []json.RawMessage
+	if err := json.Unmarshal(body, &reqs); err != nil {
+		return errorResponse(nil, &ethError{Code: ETH_PARSE_ERROR, Message: err.Error()})
+	}
+	if len(reqs) == 0 || len(reqs) > MAX_BATCH_SIZE {
+		return errorResponse(nil, &ethError{Code: ETH_INVALID_REQUEST, Message: fmt.Sprintf("batch size should be in [1, %d]", MAX_BATCH_SIZE)})
+	}
+	resps := make([]map[string]interface{}, 0, len(reqs))
+	for _, raw := range reqs {
+		req := new(ethRequest)
+		if err := json.Unmarshal(raw, req); err != nil {
+			resps = append(resps, errorResponse(nil, &ethError{Code: ETH_INVALID_REQUEST, Message: err.Error()}))
+			continue
+		}
+		resps = append(resps, handleRequest(req))
+	}
+	return resps
+}
+
+func handleRequest(req *ethRequest) map[string]interface{} {
+	handlerLock.RLock()
+	handler, ok := handlers[req.Method]
+	handlerLock.RUnlock()
+	if !ok {
+		log.Warn("Eth JSON RPC Handle - No function to call for ", req.Method)
+		return errorResponse(req.Id, &ethError{Code: ETH_METHOD_NOT_FOUND, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)})
+	}
+	result, e := handler(req.Params)
+	if e != nil {
+		return errorResponse(req.Id, e)
+	}
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.Id,
+		"result":  result,
+	}
+}
+
+func errorResponse(id json.RawMessage, e *ethError) map[string]interface{} {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   e,
+	}
+}
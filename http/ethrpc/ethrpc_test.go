@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTx(contract common.Address, nonce uint32) *types.Transaction {
+	param := &states.ContractInvokeParam{Address: contract, Method: "test", Args: []byte{1}}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	tx := &types.Transaction{
+		TxType:  types.Invoke,
+		Nonce:   nonce,
+		Payload: &payload.InvokeCode{Code: sink.Bytes()},
+		Payer:   common.Address{9},
+		Sigs:    []types.Sig{},
+	}
+	sink = common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		panic(err)
+	}
+	// hash is set by deserialization
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	return tx
+}
+
+func TestEthReceipts(t *testing.T) {
+	ccm := common.Address{3}
+	txs := []*types.Transaction{makeTx(ccm, 1), makeTx(ccm, 2), makeTx(ccm, 3)}
+	block := &types.Block{Header: &types.Header{Height: 7, Timestamp: 100}, Transactions: txs}
+	notifies := []*event.ExecuteNotify{
+		{TxHash: txs[0].Hash(), State: event.CONTRACT_STATE_SUCCESS, GasConsumed: 10, Notify: []*event.NotifyEventInfo{
+			{ContractAddress: ccm, States: []interface{}{"makeProof", uint64(2), uint64(6)}},
+			{ContractAddress: ccm, States: "raw"},
+		}},
+		{TxHash: txs[1].Hash(), State: event.CONTRACT_STATE_FAIL, GasConsumed: 5, Notify: []*event.NotifyEventInfo{
+			{ContractAddress: ccm, States: []interface{}{"makeProof"}},
+		}},
+		{TxHash: txs[2].Hash(), State: event.CONTRACT_STATE_SUCCESS, Notify: []*event.NotifyEventInfo{
+			{ContractAddress: ccm, States: []interface{}{"makeProof", float64(3), float64(6)}},
+		}},
+	}
+	receipts := newEthReceipts(block, notifies)
+	assert.Equal(t, 3, len(receipts))
+	assert.Equal(t, hexutil.Uint64(1), receipts[0].Status)
+	assert.Equal(t, hexutil.Uint64(0), receipts[1].Status)
+	assert.Equal(t, 0, len(receipts[1].Logs))
+	assert.Equal(t, hexutil.Uint64(15), receipts[1].CumulativeGasUsed)
+	assert.Equal(t, "0x"+ccm.ToHexString(), *receipts[0].To)
+	assert.Equal(t, hashString(txs[2].Hash()), receipts[2].TransactionHash)
+
+	topic, _ := scom.EventLogTopic([]interface{}{"makeProof"})
+	logs := receipts[0].Logs
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, []string{hexutil.Encode(topic[:])}, logs[0].Topics)
+	assert.Equal(t, hexutil.Bytes(`["makeProof",2,6]`), logs[0].Data)
+	assert.Equal(t, 0, len(logs[1].Topics))
+	assert.Equal(t, hexutil.Uint64(1), logs[1].LogIndex)
+	assert.Equal(t, hexutil.Uint64(2), receipts[2].Logs[0].LogIndex)
+
+	b := newEthBlock(block, receipts, false)
+	assert.Equal(t, hexutil.Uint64(15), b.GasUsed)
+	assert.Equal(t, hashString(txs[0].Hash()), b.Transactions[0])
+	b = newEthBlock(block, receipts, true)
+	assert.Equal(t, hexutil.Uint64(1), b.Transactions[1].(*ethTransaction).TransactionIndex)
+}
+
+func TestLogFilter(t *testing.T) {
+	ccm := common.Address{3}
+	topic, _ := scom.EventLogTopic([]interface{}{"makeProof"})
+	other, _ := scom.EventLogTopic([]interface{}{"btcTxToRelay"})
+	var obj interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"fromBlock":"0x1","toBlock":"latest","address":"`+addressString(ccm)+
+		`","topics":[["`+hexutil.Encode(topic[:])+`","`+hexutil.Encode(other[:])+`"]]}`), &obj))
+	f, err := parseLogFilter(obj, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), f.FromHeight)
+	assert.Equal(t, uint32(10), f.ToHeight)
+	assert.Equal(t, []common.Address{ccm}, f.Addresses)
+	assert.Equal(t, 2, len(f.topic0()))
+
+	assert.True(t, f.match(&ethLog{contract: ccm, topic: &topic}))
+	assert.False(t, f.match(&ethLog{contract: common.Address{4}, topic: &topic}))
+	assert.False(t, f.match(&ethLog{contract: ccm}))
+
+	f, err = parseLogFilter(map[string]interface{}{"topics": []interface{}{nil, hexutil.Encode(topic[:])}}, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(f.topic0()))
+	assert.False(t, f.match(&ethLog{contract: ccm, topic: &topic}))
+
+	_, err = parseLogFilter(map[string]interface{}{"fromBlock": "0x5", "toBlock": "0x4"}, 10)
+	assert.NotNil(t, err)
+	_, err = parseLogFilter(map[string]interface{}{"blockHash": hashString(common.Uint256{1}), "fromBlock": "0x1"}, 10)
+	assert.NotNil(t, err)
+	_, err = parseLogFilter(map[string]interface{}{"address": 1}, 10)
+	assert.NotNil(t, err)
+
+	height, err := parseBlockNumber("earliest", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), height)
+	_, err = parseBlockNumber("0x100000000", 10)
+	assert.NotNil(t, err)
+}
+
+func TestHandleBody(t *testing.T) {
+	HandleFunc("test_echo", func(params []interface{}) (interface{}, *ethError) {
+		if len(params) == 0 {
+			return nil, invalidParams("empty")
+		}
+		return params[0], nil
+	})
+	resp := handleBody([]byte(`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["a"]}`)).(map[string]interface{})
+	assert.Equal(t, "a", resp["result"])
+	assert.Equal(t, json.RawMessage("1"), resp["id"])
+
+	resps := handleBody([]byte(`[{"id":1,"method":"test_echo","params":[]},{"id":2,"method":"eth_unknown"},1]`)).([]map[string]interface{})
+	assert.Equal(t, 3, len(resps))
+	assert.Equal(t, ETH_INVALID_PARAMS, resps[0]["error"].(*ethError).Code)
+	assert.Equal(t, ETH_METHOD_NOT_FOUND, resps[1]["error"].(*ethError).Code)
+	assert.Equal(t, ETH_INVALID_REQUEST, resps[2]["error"].(*ethError).Code)
+
+	resp = handleBody([]byte(`{"id":1`)).(map[string]interface{})
+	assert.Equal(t, ETH_PARSE_ERROR, resp["error"].(*ethError).Code)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethrpc
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+const (
+	MAX_LOG_RESULTS     = 10000 //max logs returned by eth_getLogs
+	MAX_LOG_BLOCK_RANGE = 2000  //max blocks scanned by eth_getLogs without address or topic
+)
+
+//logFilter is the filter object of eth_getLogs. An empty address list or topic set matches anything.
+type logFilter struct {
+	FromHeight uint32
+	ToHeight   uint32
+	BlockHash  *common.Uint256
+	Addresses  []common.Address
+	Topics     [][]common.Uint256
+}
+
+//parseLogFilter decode the filter object, block tags are resolved against current height
+func parseLogFilter(v interface{}, current uint32) (*logFilter, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("filter should be object")
+	}
+	f := &logFilter{FromHeight: current, ToHeight: current}
+	var err error
+	if hash, ok := obj["blockHash"]; ok && hash != nil {
+		if obj["fromBlock"] != nil || obj["toBlock"] != nil {
+			return nil, fmt.Errorf("blockHash can not be used with fromBlock or toBlock")
+		}
+		blockHash, err := parseHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blockHash: %s", err)
+		}
+		f.BlockHash = &blockHash
+	}
+	if from, ok := obj["fromBlock"]; ok && from != nil {
+		if f.FromHeight, err = parseBlockNumber(from, current); err != nil {
+			return nil, fmt.Errorf("invalid fromBlock: %s", err)
+		}
+	}
+	if to, ok := obj["toBlock"]; ok && to != nil {
+		if f.ToHeight, err = parseBlockNumber(to, current); err != nil {
+			return nil, fmt.Errorf("invalid toBlock: %s", err)
+		}
+	}
+	if f.FromHeight > f.ToHeight {
+		return nil, fmt.Errorf("fromBlock %d higher than toBlock %d", f.FromHeight, f.ToHeight)
+	}
+	switch addr := obj["address"].(type) {
+	case nil:
+	case string:
+		a, err := parseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address: %s", err)
+		}
+		f.Addresses = append(f.Addresses, a)
+	case []interface{}:
+		for _, item := range addr {
+			a, err := parseAddress(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address: %s", err)
+			}
+			f.Addresses = append(f.Addresses, a)
+		}
+	default:
+		return nil, fmt.Errorf("address should be string or array")
+	}
+	switch topics := obj["topics"].(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range topics {
+			set, err := parseTopicSet(item)
+			if err != nil {
+				return nil, err
+			}
+			f.Topics = append(f.Topics, set)
+		}
+	default:
+		return nil, fmt.Errorf("topics should be array")
+	}
+	return f, nil
+}
+
+func parseTopicSet(v interface{}) ([]common.Uint256, error) {
+	switch item := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		topic, err := parseTopic(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid topic: %s", err)
+		}
+		return []common.Uint256{topic}, nil
+	case []interface{}:
+		set := make([]common.Uint256, 0, len(item))
+		for _, t := range item {
+			topic, err := parseTopic(t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid topic: %s", err)
+			}
+			set = append(set, topic)
+		}
+		return set, nil
+	default:
+		return nil, fmt.Errorf("topic should be string or array")
+	}
+}
+
+//topic0 return the topics of the first position, which can be looked up by index
+func (self *logFilter) topic0() []common.Uint256 {
+	if len(self.Topics) == 0 {
+		return nil
+	}
+	return self.Topics[0]
+}
+
+func (self *logFilter) match(l *ethLog) bool {
+	if len(self.Addresses) != 0 {
+		found := false
+		for _, addr := range self.Addresses {
+			if addr == l.contract {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for i, set := range self.Topics {
+		if len(set) == 0 {
+			continue
+		}
+		// logs of poly notify have only one topic
+		if i != 0 || l.topic == nil {
+			return false
+		}
+		found := false
+		for _, topic := range set {
+			if topic == *l.topic {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
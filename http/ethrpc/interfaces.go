@@ -0,0 +1,271 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethrpc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	bactor "github.com/polynetwork/poly/http/base/actor"
+	"github.com/polynetwork/poly/native/event"
+)
+
+func ClientVersion(params []interface{}) (interface{}, *ethError) {
+	return "poly/" + config.Version, nil
+}
+
+func NetVersion(params []interface{}) (interface{}, *ethError) {
+	return strconv.FormatUint(uint64(config.DefConfig.P2PNode.NetworkId), 10), nil
+}
+
+func ChainId(params []interface{}) (interface{}, *ethError) {
+	return hexutil.Uint64(config.DefConfig.P2PNode.NetworkId), nil
+}
+
+func BlockNumber(params []interface{}) (interface{}, *ethError) {
+	return hexutil.Uint64(bactor.GetCurrentBlockHeight()), nil
+}
+
+//GetBlockByNumber params [blockNumber, fullTx]
+func GetBlockByNumber(params []interface{}) (interface{}, *ethError) {
+	if len(params) < 1 {
+		return nil, invalidParams("block number is required")
+	}
+	height, err := parseBlockNumber(params[0], bactor.GetCurrentBlockHeight())
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+	block, err := bactor.GetBlockByHeight(height)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return getEthBlock(block, params)
+}
+
+//GetBlockByHash params [blockHash, fullTx]
+func GetBlockByHash(params []interface{}) (interface{}, *ethError) {
+	if len(params) < 1 {
+		return nil, invalidParams("block hash is required")
+	}
+	hash, err := parseHash(params[0])
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+	block, err := bactor.GetBlockFromStore(hash)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, internalError(err)
+	}
+	return getEthBlock(block, params)
+}
+
+func getEthBlock(block *types.Block, params []interface{}) (interface{}, *ethError) {
+	if block == nil || block.Header == nil {
+		return nil, nil
+	}
+	fullTx := false
+	if len(params) > 1 {
+		fullTx, _ = params[1].(bool)
+	}
+	receipts, err := getBlockReceipts(block)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newEthBlock(block, receipts, fullTx), nil
+}
+
+//GetTransactionByHash params [txHash]
+func GetTransactionByHash(params []interface{}) (interface{}, *ethError) {
+	block, index, err := getTxBlock(params)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return newEthTransaction(block.Transactions[index], block, index), nil
+}
+
+//GetTransactionReceipt params [txHash]
+func GetTransactionReceipt(params []interface{}) (interface{}, *ethError) {
+	block, index, err := getTxBlock(params)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	receipts, e := getBlockReceipts(block)
+	if e != nil {
+		return nil, internalError(e)
+	}
+	return receipts[index], nil
+}
+
+//getTxBlock return the block of tx and the index of tx in block, nil block if tx not found
+func getTxBlock(params []interface{}) (*types.Block, int, *ethError) {
+	if len(params) < 1 {
+		return nil, 0, invalidParams("transaction hash is required")
+	}
+	txHash, err := parseHash(params[0])
+	if err != nil {
+		return nil, 0, invalidParams(err.Error())
+	}
+	height, tx, err := bactor.GetTxnWithHeightByTxHash(txHash)
+	if err == scom.ErrNotFound || (err == nil && tx == nil) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, internalError(err)
+	}
+	block, err := bactor.GetBlockByHeight(height)
+	if err != nil {
+		return nil, 0, internalError(err)
+	}
+	if block == nil {
+		return nil, 0, nil
+	}
+	for i, t := range block.Transactions {
+		if t.Hash() == txHash {
+			return block, i, nil
+		}
+	}
+	return nil, 0, internalError(fmt.Errorf("transaction %s not in block %d", txHash.ToHexString(), height))
+}
+
+func getBlockReceipts(block *types.Block) ([]*ethReceipt, error) {
+	var notifies []*event.ExecuteNotify
+	if config.DefConfig.Common.EnableEventLog && len(block.Transactions) != 0 {
+		var err error
+		notifies, err = bactor.GetEventNotifyByHeight(block.Header.Height)
+		if err != nil && err != scom.ErrNotFound {
+			return nil, err
+		}
+	}
+	return newEthReceipts(block, notifies), nil
+}
+
+//GetLogs params [filter]. Blocks are looked up by the log index of the first address or topic
+//in filter, a filter without them scans at most MAX_LOG_BLOCK_RANGE blocks.
+func GetLogs(params []interface{}) (interface{}, *ethError) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return nil, &ethError{Code: ETH_METHOD_NOT_FOUND, Message: "event log is disabled"}
+	}
+	if len(params) < 1 {
+		return nil, invalidParams("filter is required")
+	}
+	current := bactor.GetCurrentBlockHeight()
+	f, err := parseLogFilter(params[0], current)
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+	if f.BlockHash != nil {
+		block, err := bactor.GetBlockFromStore(*f.BlockHash)
+		if err != nil || block == nil {
+			return nil, invalidParams(fmt.Sprintf("unknown block %s", f.BlockHash.ToHexString()))
+		}
+		f.FromHeight, f.ToHeight = block.Header.Height, block.Header.Height
+	}
+	if f.ToHeight > current {
+		f.ToHeight = current
+	}
+	logs := make([]*ethLog, 0)
+	if f.FromHeight > f.ToHeight {
+		return logs, nil
+	}
+	heights, e := getLogHeights(f)
+	if e != nil {
+		return nil, e
+	}
+	for _, height := range heights {
+		block, err := bactor.GetBlockByHeight(height)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		if block == nil {
+			continue
+		}
+		receipts, err := getBlockReceipts(block)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		for _, r := range receipts {
+			for _, l := range r.Logs {
+				if f.match(l) {
+					logs = append(logs, l)
+				}
+			}
+		}
+		if len(logs) > MAX_LOG_RESULTS {
+			return nil, limitExceeded()
+		}
+	}
+	return logs, nil
+}
+
+//getLogHeights return the heights of blocks which may have logs matching filter, in order
+func getLogHeights(f *logFilter) ([]uint32, *ethError) {
+	addresses, topics := f.Addresses, f.topic0()
+	if len(addresses) == 0 && len(topics) == 0 {
+		if f.ToHeight-f.FromHeight >= MAX_LOG_BLOCK_RANGE {
+			return nil, invalidParams(fmt.Sprintf("block range should be less than %d without address or topic", MAX_LOG_BLOCK_RANGE))
+		}
+		heights := make([]uint32, 0, f.ToHeight-f.FromHeight+1)
+		for h := f.FromHeight; h <= f.ToHeight; h++ {
+			heights = append(heights, h)
+		}
+		return heights, nil
+	}
+	heightSet := make(map[uint32]bool)
+	txCount := 0
+	lookup := func(address *common.Address, topic *common.Uint256) *ethError {
+		txs, err := bactor.GetEventLogTxs(address, topic, f.FromHeight, f.ToHeight, MAX_LOG_RESULTS+1)
+		if err != nil {
+			log.Errorf("eth_getLogs GetEventLogTxs error: %s", err)
+			return internalError(err)
+		}
+		txCount += len(txs)
+		if txCount > MAX_LOG_RESULTS {
+			return limitExceeded()
+		}
+		for _, tx := range txs {
+			heightSet[tx.Height] = true
+		}
+		return nil
+	}
+	if len(addresses) != 0 {
+		for i := range addresses {
+			if err := lookup(&addresses[i], nil); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for i := range topics {
+			if err := lookup(nil, &topics[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	heights := make([]uint32, 0, len(heightSet))
+	for h := range heightSet {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/states"
+)
+
+const (
+	EMPTY_UNCLE_HASH = "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"
+	EMPTY_TRIE_ROOT  = "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"
+	BLOOM_SIZE       = 256
+)
+
+//hashString encode poly hash the same way as the native rpc, so it can be passed to both
+func hashString(hash common.Uint256) string {
+	return "0x" + hash.ToHexString()
+}
+
+func addressString(addr common.Address) string {
+	return "0x" + addr.ToHexString()
+}
+
+func parseHash(v interface{}) (common.Uint256, error) {
+	str, ok := v.(string)
+	if !ok {
+		return common.UINT256_EMPTY, fmt.Errorf("hash should be string")
+	}
+	return common.Uint256FromHexString(strings.TrimPrefix(str, "0x"))
+}
+
+func parseAddress(v interface{}) (common.Address, error) {
+	str, ok := v.(string)
+	if !ok {
+		return common.ADDRESS_EMPTY, fmt.Errorf("address should be string")
+	}
+	return common.AddressFromHexString(strings.TrimPrefix(str, "0x"))
+}
+
+//parseTopic decode topic, which is a keccak256 hash encoded as in eth
+func parseTopic(v interface{}) (common.Uint256, error) {
+	str, ok := v.(string)
+	if !ok {
+		return common.UINT256_EMPTY, fmt.Errorf("topic should be string")
+	}
+	data, err := hexutil.Decode(str)
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	return common.Uint256ParseFromBytes(data)
+}
+
+//parseBlockNumber decode block number or block tag
+func parseBlockNumber(v interface{}, current uint32) (uint32, error) {
+	str, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("block number should be string")
+	}
+	switch str {
+	case "latest", "pending", "safe", "finalized":
+		return current, nil
+	case "earliest":
+		return 0, nil
+	}
+	height, err := hexutil.DecodeUint64(str)
+	if err != nil {
+		return 0, err
+	}
+	if height > uint64(^uint32(0)) {
+		return 0, fmt.Errorf("block number %d out of range", height)
+	}
+	return uint32(height), nil
+}
+
+type ethBlock struct {
+	Number           hexutil.Uint64 `json:"number"`
+	Hash             string         `json:"hash"`
+	ParentHash       string         `json:"parentHash"`
+	Nonce            hexutil.Bytes  `json:"nonce"`
+	Sha3Uncles       string         `json:"sha3Uncles"`
+	LogsBloom        hexutil.Bytes  `json:"logsBloom"`
+	TransactionsRoot string         `json:"transactionsRoot"`
+	StateRoot        string         `json:"stateRoot"`
+	ReceiptsRoot     string         `json:"receiptsRoot"`
+	Miner            string         `json:"miner"`
+	Difficulty       hexutil.Uint64 `json:"difficulty"`
+	TotalDifficulty  hexutil.Uint64 `json:"totalDifficulty"`
+	ExtraData        hexutil.Bytes  `json:"extraData"`
+	Size             hexutil.Uint64 `json:"size"`
+	GasLimit         hexutil.Uint64 `json:"gasLimit"`
+	GasUsed          hexutil.Uint64 `json:"gasUsed"`
+	Timestamp        hexutil.Uint64 `json:"timestamp"`
+	Transactions     []interface{}  `json:"transactions"`
+	Uncles           []string       `json:"uncles"`
+}
+
+type ethTransaction struct {
+	Hash             string         `json:"hash"`
+	Nonce            hexutil.Uint64 `json:"nonce"`
+	BlockHash        string         `json:"blockHash"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	From             string         `json:"from"`
+	To               *string        `json:"to"`
+	Value            hexutil.Uint64 `json:"value"`
+	Gas              hexutil.Uint64 `json:"gas"`
+	GasPrice         hexutil.Uint64 `json:"gasPrice"`
+	Input            hexutil.Bytes  `json:"input"`
+	Type             hexutil.Uint64 `json:"type"`
+	ChainId          hexutil.Uint64 `json:"chainId"`
+}
+
+type ethLog struct {
+	Address          string         `json:"address"`
+	Topics           []string       `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        string         `json:"blockHash"`
+	TransactionHash  string         `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	LogIndex         hexutil.Uint64 `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+
+	contract common.Address
+	topic    *common.Uint256
+}
+
+type ethReceipt struct {
+	TransactionHash   string         `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64 `json:"transactionIndex"`
+	BlockHash         string         `json:"blockHash"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	From              string         `json:"from"`
+	To                *string        `json:"to"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	ContractAddress   *string        `json:"contractAddress"`
+	Logs              []*ethLog      `json:"logs"`
+	LogsBloom         hexutil.Bytes  `json:"logsBloom"`
+	Status            hexutil.Uint64 `json:"status"`
+	Type              hexutil.Uint64 `json:"type"`
+}
+
+//invokedContract return the native contract called by tx, nil if the payload is not a native invoke
+func invokedContract(tx *types.Transaction) *string {
+	invoke, ok := tx.Payload.(*payload.InvokeCode)
+	if !ok {
+		return nil
+	}
+	param := new(states.ContractInvokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(invoke.Code)); err != nil {
+		return nil
+	}
+	to := addressString(param.Address)
+	return &to
+}
+
+func newEthTransaction(tx *types.Transaction, block *types.Block, index int) *ethTransaction {
+	t := &ethTransaction{
+		Hash:             hashString(tx.Hash()),
+		Nonce:            hexutil.Uint64(tx.Nonce),
+		BlockHash:        hashString(block.Hash()),
+		BlockNumber:      hexutil.Uint64(block.Header.Height),
+		TransactionIndex: hexutil.Uint64(index),
+		From:             addressString(tx.Payer),
+		To:               invokedContract(tx),
+		Gas:              hexutil.Uint64(tx.GasLimit),
+		GasPrice:         hexutil.Uint64(tx.GasPrice),
+		Input:            hexutil.Bytes{},
+		ChainId:          hexutil.Uint64(tx.ChainID),
+	}
+	if invoke, ok := tx.Payload.(*payload.InvokeCode); ok {
+		t.Input = invoke.Code
+	}
+	return t
+}
+
+//newEthBlock map poly block to eth block. Poly has no receipts trie, uncles or difficulty,
+//these fields are filled as in an empty eth block.
+func newEthBlock(block *types.Block, receipts []*ethReceipt, fullTx bool) *ethBlock {
+	header := block.Header
+	b := &ethBlock{
+		Number:           hexutil.Uint64(header.Height),
+		Hash:             hashString(block.Hash()),
+		ParentHash:       hashString(header.PrevBlockHash),
+		Nonce:            make(hexutil.Bytes, 8),
+		Sha3Uncles:       EMPTY_UNCLE_HASH,
+		LogsBloom:        make(hexutil.Bytes, BLOOM_SIZE),
+		TransactionsRoot: hashString(header.TransactionsRoot),
+		StateRoot:        hashString(header.CrossStateRoot),
+		ReceiptsRoot:     EMPTY_TRIE_ROOT,
+		Miner:            addressString(header.NextBookkeeper),
+		ExtraData:        hexutil.Bytes{},
+		Size:             hexutil.Uint64(len(block.ToArray())),
+		Timestamp:        hexutil.Uint64(header.Timestamp),
+		Transactions:     make([]interface{}, 0, len(block.Transactions)),
+		Uncles:           []string{},
+	}
+	if len(receipts) != 0 {
+		b.GasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+	for i, tx := range block.Transactions {
+		if fullTx {
+			b.Transactions = append(b.Transactions, newEthTransaction(tx, block, i))
+		} else {
+			b.Transactions = append(b.Transactions, hashString(tx.Hash()))
+		}
+	}
+	return b
+}
+
+//newEthReceipts build the receipts of all txs in block. Every notify of a successful tx is a log, whose only topic
+//is the keccak256 hash of the event name in States[0], and whose data is the json encoded States.
+func newEthReceipts(block *types.Block, notifies []*event.ExecuteNotify) []*ethReceipt {
+	notifyMap := make(map[common.Uint256]*event.ExecuteNotify, len(notifies))
+	for _, notify := range notifies {
+		notifyMap[notify.TxHash] = notify
+	}
+	blockHash := hashString(block.Hash())
+	receipts := make([]*ethReceipt, 0, len(block.Transactions))
+	logIndex, cumulativeGas := uint64(0), uint64(0)
+	for i, tx := range block.Transactions {
+		txHash := tx.Hash()
+		r := &ethReceipt{
+			TransactionHash:  hashString(txHash),
+			TransactionIndex: hexutil.Uint64(i),
+			BlockHash:        blockHash,
+			BlockNumber:      hexutil.Uint64(block.Header.Height),
+			From:             addressString(tx.Payer),
+			To:               invokedContract(tx),
+			Logs:             make([]*ethLog, 0),
+			LogsBloom:        make(hexutil.Bytes, BLOOM_SIZE),
+		}
+		if notify, ok := notifyMap[txHash]; ok {
+			r.GasUsed = hexutil.Uint64(notify.GasConsumed)
+			if notify.State == event.CONTRACT_STATE_SUCCESS {
+				r.Status = 1
+				for _, n := range notify.Notify {
+					r.Logs = append(r.Logs, newEthLog(n, r, logIndex))
+					logIndex++
+				}
+			}
+		}
+		cumulativeGas += uint64(r.GasUsed)
+		r.CumulativeGasUsed = hexutil.Uint64(cumulativeGas)
+		receipts = append(receipts, r)
+	}
+	return receipts
+}
+
+func newEthLog(n *event.NotifyEventInfo, r *ethReceipt, logIndex uint64) *ethLog {
+	l := &ethLog{
+		Address:          addressString(n.ContractAddress),
+		Topics:           []string{},
+		BlockNumber:      r.BlockNumber,
+		BlockHash:        r.BlockHash,
+		TransactionHash:  r.TransactionHash,
+		TransactionIndex: r.TransactionIndex,
+		LogIndex:         hexutil.Uint64(logIndex),
+		contract:         n.ContractAddress,
+	}
+	if topic, ok := scom.EventLogTopic(n.States); ok {
+		l.topic = &topic
+		l.Topics = []string{hexutil.Encode(topic[:])}
+	}
+	l.Data, _ = json.Marshal(n.States)
+	return l
+}
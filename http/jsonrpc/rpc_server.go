@@ -48,6 +48,7 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getstorage", rpc.GetStorage)
 	rpc.HandleFunc("getversion", rpc.GetNodeVersion)
 	rpc.HandleFunc("getnetworkid", rpc.GetNetworkId)
+	rpc.HandleFunc("getgasprice", rpc.GetGasPrice)
 
 	rpc.HandleFunc("getmempooltxcount", rpc.GetMemPoolTxCount)
 	rpc.HandleFunc("getmempooltxstate", rpc.GetMemPoolTxState)
@@ -56,6 +57,18 @@ func StartRPCServer() error {
 
 	rpc.HandleFunc("getmerkleproof", rpc.GetMerkleProof)
 	rpc.HandleFunc("getcrossstatesproof", rpc.GetCrossStatesProof)
+	rpc.HandleFunc("getstorageproof", rpc.GetStorageProof)
+	rpc.HandleFunc("getcrosschaintx", rpc.GetCrossChainTx)
+	rpc.HandleFunc("listcrosschaintxs", rpc.ListCrossChainTxs)
+	rpc.HandleFunc("getrelayerreward", rpc.GetRelayerReward)
+	rpc.HandleFunc("getratelimit", rpc.GetRateLimit)
+	rpc.HandleFunc("getpendingtxs", rpc.GetPendingTxs)
+	rpc.HandleFunc("getstakinginfo", rpc.GetStakingInfo)
+	rpc.HandleFunc("getvalidatorstake", rpc.GetValidatorStake)
+	rpc.HandleFunc("getdelegation", rpc.GetDelegation)
+	rpc.HandleFunc("getstakeaccount", rpc.GetStakeAccount)
+	rpc.HandleFunc("getproposal", rpc.GetProposal)
+	rpc.HandleFunc("listproposals", rpc.ListProposals)
 	rpc.HandleFunc("getheaderbyheight", rpc.GetHeaderByHeight)
 	rpc.HandleFunc("getblocktxsbyheight", rpc.GetBlockTxsByHeight)
 	rpc.HandleFunc("getstatemerkleroot", rpc.GetStateMerkleRoot)
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package metrics privides the http server of the prometheus metrics
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/common/metrics"
+	"github.com/polynetwork/poly/core/ledger"
+	p2p "github.com/polynetwork/poly/p2pserver/net/protocol"
+)
+
+const CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+//RegisterNodeMetrics registers the block and peer metrics of the node
+func RegisterNodeMetrics(r *metrics.Registry, n p2p.P2P) error {
+	return r.Register(
+		metrics.NewGaugeFunc("poly_block_height", "Height of the current block", func() float64 {
+			return float64(ledger.DefLedger.GetCurrentBlockHeight())
+		}),
+		metrics.NewGaugeFunc("poly_block_timestamp_seconds", "Timestamp of the current block", func() float64 {
+			header, err := ledger.DefLedger.GetHeaderByHeight(ledger.DefLedger.GetCurrentBlockHeight())
+			if err != nil || header == nil {
+				return 0
+			}
+			return float64(header.Timestamp)
+		}),
+		metrics.NewGaugeFunc("poly_p2p_peers", "Count of connected peers", func() float64 {
+			return float64(n.GetConnectionCnt())
+		}),
+	)
+}
+
+//Handler returns the http handler writing the metrics of the registry
+func Handler(r *metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", CONTENT_TYPE)
+		if _, err := r.WriteTo(w); err != nil {
+			log.Warnf("write metrics error %s", err)
+		}
+	})
+}
+
+func StartServer(n p2p.P2P) {
+	if err := RegisterNodeMetrics(metrics.DefaultRegistry, n); err != nil {
+		log.Errorf("RegisterNodeMetrics error %s", err)
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(metrics.DefaultRegistry))
+	port := int(config.DefConfig.Metrics.HttpMetricsPort)
+	if err := http.ListenAndServe(":"+strconv.Itoa(port), mux); err != nil {
+		log.Errorf("metrics server error %s", err)
+	}
+}
@@ -48,6 +48,7 @@ func sendBlock2WSclient(v interface{}) {
 		go func() {
 			pushBlock(v)
 			pushBlockTransactions(v)
+			pushFilteredEvents(v)
 		}()
 	}
 }
@@ -72,7 +73,7 @@ func pushSmartCodeEvent(v interface{}) {
 	}
 	rs, ok := v.(types.SmartCodeEvent)
 	if !ok {
-		log.Errorf("[PushSmartCodeEvent]", "SmartCodeEvent err")
+		log.Error("[PushSmartCodeEvent] SmartCodeEvent err")
 		return
 	}
 	go func() {
@@ -112,6 +113,14 @@ func pushBlock(v interface{}) {
 		ws.BroadcastToSubscribers(nil, websocket.WSTOPIC_JSON_BLOCK, resp)
 	}
 }
+func pushFilteredEvents(v interface{}) {
+	if ws == nil {
+		return
+	}
+	if block, ok := v.(types.Block); ok {
+		ws.PushFilteredEvents(block.Header.Height)
+	}
+}
 func pushBlockTransactions(v interface{}) {
 	if ws == nil {
 		return
@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	bcomn "github.com/polynetwork/poly/http/base/common"
+	"github.com/polynetwork/poly/native/event"
+)
+
+const MAX_EVENT_FILTERS = 16
+
+//MAX_REPLAY_BLOCKS is the max number of past blocks whose events are replayed to a stream resuming from FromHeight
+const MAX_REPLAY_BLOCKS = 10000
+
+//EventFilter select the notifies pushed to a session, an empty field matches anything.
+//A notify matches the filter if it is emitted by Contract at a height in [StartHeight, EndHeight],
+//its States[0] is EventName, and every non-null item of States equals the item at the same position.
+//FromChainId and ToChainId are matched on the cross chain events listed in chainIDIndexes.
+type EventFilter struct {
+	Contract    string        `json:"Contract,omitempty"`
+	EventName   string        `json:"EventName,omitempty"`
+	FromChainId *uint64       `json:"FromChainId,omitempty"`
+	ToChainId   *uint64       `json:"ToChainId,omitempty"`
+	States      []interface{} `json:"States,omitempty"`
+	StartHeight uint32        `json:"StartHeight,omitempty"`
+	EndHeight   uint32        `json:"EndHeight,omitempty"`
+}
+
+//positions of from chain id and to chain id in States of cross chain events
+var chainIDIndexes = map[string][2]int{
+	"makeProof":         {1, 2},
+	"btcTxToRelay":      {1, 2},
+	"multisignedTxJson": {1, 2},
+	"rippleTxJson":      {1, 2},
+}
+
+//parseFromHeight check the FromHeight of subscription, which is at most MAX_REPLAY_BLOCKS before nextHeight
+func parseFromHeight(h float64, nextHeight uint32) (uint32, error) {
+	if h < 0 || h > float64(nextHeight) {
+		return 0, fmt.Errorf("FromHeight %v is out of range [0, %d]", h, nextHeight)
+	}
+	if nextHeight > MAX_REPLAY_BLOCKS && h < float64(nextHeight-MAX_REPLAY_BLOCKS) {
+		return 0, fmt.Errorf("FromHeight %v is more than %d blocks before %d", h, MAX_REPLAY_BLOCKS, nextHeight)
+	}
+	return uint32(h), nil
+}
+
+func parseEventFilters(v interface{}) ([]*EventFilter, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	filters := make([]*EventFilter, 0)
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("invalid event filters: %s", err)
+	}
+	if len(filters) > MAX_EVENT_FILTERS {
+		return nil, fmt.Errorf("too many event filters, max %d", MAX_EVENT_FILTERS)
+	}
+	for _, f := range filters {
+		if f == nil {
+			return nil, fmt.Errorf("invalid event filters: null filter")
+		}
+		if f.EndHeight != 0 && f.EndHeight < f.StartHeight {
+			return nil, fmt.Errorf("invalid event filters: end height %d lower than start height %d", f.EndHeight, f.StartHeight)
+		}
+	}
+	return filters, nil
+}
+
+//Match return whether a notify of contract with states at height is selected by the filter
+func (self *EventFilter) Match(height uint32, contract string, states interface{}) bool {
+	if height < self.StartHeight || (self.EndHeight != 0 && height > self.EndHeight) {
+		return false
+	}
+	if self.Contract != "" && self.Contract != contract {
+		return false
+	}
+	items, _ := states.([]interface{})
+	name := ""
+	if len(items) != 0 {
+		name, _ = items[0].(string)
+	}
+	if self.EventName != "" && self.EventName != name {
+		return false
+	}
+	if self.FromChainId != nil || self.ToChainId != nil {
+		indexes, ok := chainIDIndexes[name]
+		if !ok {
+			return false
+		}
+		if self.FromChainId != nil && !matchState(items, indexes[0], *self.FromChainId) {
+			return false
+		}
+		if self.ToChainId != nil && !matchState(items, indexes[1], *self.ToChainId) {
+			return false
+		}
+	}
+	for i, v := range self.States {
+		if v != nil && !matchState(items, i, v) {
+			return false
+		}
+	}
+	return true
+}
+
+//matchState compare the item of states at index with expect. Numbers are compared by value,
+//since states read back from event store are decoded from json.
+func matchState(items []interface{}, index int, expect interface{}) bool {
+	if index < 0 || index >= len(items) {
+		return false
+	}
+	return stateString(items[index]) == stateString(expect)
+}
+
+func stateString(v interface{}) string {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case json.Number:
+		return n.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+//filterNotify return the notifies of a tx selected by any of filters, nil if none
+func filterNotify(filters []*EventFilter, height uint32, notify *event.ExecuteNotify) *FilteredEvent {
+	_, execNotify := bcomn.GetExecuteNotify(notify)
+	matched := make([]bcomn.NotifyEventInfo, 0)
+	for _, n := range execNotify.Notify {
+		for _, f := range filters {
+			if f.Match(height, n.ContractAddress, n.States) {
+				matched = append(matched, n)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	execNotify.Notify = matched
+	return &FilteredEvent{Height: height, ExecuteNotify: execNotify}
+}
+
+//FilteredEvent is pushed to a session with event filters
+type FilteredEvent struct {
+	Height uint32
+	bcomn.ExecuteNotify
+}
+
+//eventStream tracks the next block height whose events will be pushed to a session
+type eventStream struct {
+	sync.Mutex
+	nextHeight uint32
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilterMatch(t *testing.T) {
+	filters, err := parseEventFilters([]interface{}{
+		map[string]interface{}{"EventName": "makeProof", "ToChainId": 6},
+	})
+	assert.Nil(t, err)
+	f := filters[0]
+	assert.True(t, f.Match(10, "0300", []interface{}{"makeProof", uint64(2), uint64(6), "aa", uint32(10), "key"}))
+	assert.False(t, f.Match(10, "0300", []interface{}{"makeProof", uint64(2), uint64(7), "aa", uint32(10), "key"}))
+	assert.False(t, f.Match(10, "0300", []interface{}{"btcTxMultiSign", "aa"}))
+	assert.False(t, f.Match(10, "0300", "not a list"))
+	// states read back from event store are decoded from json
+	var states interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`["makeProof", 2, 6, "aa", 10, "key"]`), &states))
+	assert.True(t, f.Match(10, "0300", states))
+
+	filters, err = parseEventFilters([]interface{}{
+		map[string]interface{}{"Contract": "0300", "States": []interface{}{"btcTxMultiSign", nil}, "StartHeight": 5, "EndHeight": 8},
+	})
+	assert.Nil(t, err)
+	f = filters[0]
+	assert.True(t, f.Match(5, "0300", []interface{}{"btcTxMultiSign", "aa"}))
+	assert.False(t, f.Match(9, "0300", []interface{}{"btcTxMultiSign", "aa"}))
+	assert.False(t, f.Match(4, "0300", []interface{}{"btcTxMultiSign", "aa"}))
+	assert.False(t, f.Match(6, "0400", []interface{}{"btcTxMultiSign", "aa"}))
+	assert.False(t, f.Match(6, "0300", []interface{}{"btcTxToRelay", "aa"}))
+
+	_, err = parseEventFilters([]interface{}{map[string]interface{}{"StartHeight": 5, "EndHeight": 4}})
+	assert.NotNil(t, err)
+	_, err = parseEventFilters("makeProof")
+	assert.NotNil(t, err)
+	_, err = parseEventFilters([]interface{}{nil})
+	assert.NotNil(t, err)
+}
+
+func TestFilterNotify(t *testing.T) {
+	contract := common.Address{3}
+	filters, err := parseEventFilters([]interface{}{
+		map[string]interface{}{"EventName": "AddSignatureQuorum"},
+		map[string]interface{}{"EventName": "makeProof", "FromChainId": 2},
+	})
+	assert.Nil(t, err)
+	notify := &event.ExecuteNotify{
+		TxHash: common.Uint256{1},
+		State:  event.CONTRACT_STATE_SUCCESS,
+		Notify: []*event.NotifyEventInfo{
+			{ContractAddress: contract, States: []interface{}{"makeProof", uint64(2), uint64(6)}},
+			{ContractAddress: contract, States: []interface{}{"makeProof", uint64(3), uint64(6)}},
+			{ContractAddress: contract, States: []interface{}{"AddSignatureQuorum", "id", "subject", uint64(8)}},
+		},
+	}
+	evt := filterNotify(filters, 20, notify)
+	assert.NotNil(t, evt)
+	assert.Equal(t, uint32(20), evt.Height)
+	assert.Equal(t, 2, len(evt.Notify))
+	assert.Equal(t, contract.ToHexString(), evt.Notify[0].ContractAddress)
+
+	filters, err = parseEventFilters([]interface{}{map[string]interface{}{"EventName": "btcTxToRelay"}})
+	assert.Nil(t, err)
+	assert.Nil(t, filterNotify(filters, 20, notify))
+}
+
+func TestParseFromHeight(t *testing.T) {
+	h, err := parseFromHeight(0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), h)
+	h, err = parseFromHeight(100, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(100), h)
+	_, err = parseFromHeight(101, 100)
+	assert.NotNil(t, err)
+	_, err = parseFromHeight(-1, 100)
+	assert.NotNil(t, err)
+
+	//replay is limited to the recent blocks
+	_, err = parseFromHeight(0, MAX_REPLAY_BLOCKS+2)
+	assert.NotNil(t, err)
+	h, err = parseFromHeight(2, MAX_REPLAY_BLOCKS+2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), h)
+}
@@ -33,6 +33,8 @@ import (
 	"github.com/polynetwork/poly/common"
 	cfg "github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	bactor "github.com/polynetwork/poly/http/base/actor"
 	Err "github.com/polynetwork/poly/http/base/error"
 	"github.com/polynetwork/poly/http/base/rest"
 	"github.com/polynetwork/poly/http/websocket/session"
@@ -58,6 +60,9 @@ type subscribe struct {
 	SubscribeJsonBlock    bool     `json:"SubscribeJsonBlock"`
 	SubscribeRawBlock     bool     `json:"SubscribeRawBlock"`
 	SubscribeBlockTxHashs bool     `json:"SubscribeBlockTxHashs"`
+
+	//events selected by EventFilters are pushed by block, instead of SubscribeEvent
+	EventFilters []*EventFilter `json:"EventFilters,omitempty"`
 }
 type WsServer struct {
 	sync.RWMutex
@@ -68,6 +73,7 @@ type WsServer struct {
 	ActionMap    map[string]Handler   //handler functions
 	TxHashMap    map[string]string    //key: txHash   value:sessionid
 	SubscribeMap map[string]subscribe //key: sessionId   value:subscribeInfo
+	streams      map[string]*eventStream
 }
 
 //init websocket server
@@ -77,6 +83,7 @@ func InitWsServer() *WsServer {
 		SessionList:  session.NewSessionList(),
 		TxHashMap:    make(map[string]string),
 		SubscribeMap: make(map[string]subscribe),
+		streams:      make(map[string]*eventStream),
 	}
 	return ws
 }
@@ -165,6 +172,36 @@ func (self *WsServer) registryMethod() {
 				}
 			}
 		}
+		if efs, ok := cmd["EventFilters"]; ok {
+			filters, err := parseEventFilters(efs)
+			if err != nil {
+				log.Infof("websocket subscribe: %s", err)
+				return rest.ResponsePack(Err.INVALID_PARAMS)
+			}
+			if len(filters) != 0 && !cfg.DefConfig.Common.EnableEventLog {
+				return rest.ResponsePack(Err.INVALID_METHOD)
+			}
+			sub.EventFilters = filters
+		}
+		_, fromHeight := cmd["FromHeight"]
+		_, hasFilters := cmd["EventFilters"]
+		if len(sub.EventFilters) == 0 {
+			delete(self.streams, sessionId)
+		} else if self.streams[sessionId] == nil || hasFilters || fromHeight {
+			currentHeight := bactor.GetCurrentBlockHeight()
+			nextHeight := currentHeight + 1
+			if h, ok := cmd["FromHeight"].(float64); ok {
+				from, err := parseFromHeight(h, nextHeight)
+				if err != nil {
+					log.Infof("websocket subscribe: %s", err)
+					return rest.ResponsePack(Err.INVALID_PARAMS)
+				}
+				nextHeight = from
+			}
+			// a new stream replaces the old one, which stops pushing
+			self.streams[sessionId] = &eventStream{nextHeight: nextHeight}
+			go self.pushFilteredEvents(sessionId, currentHeight)
+		}
 		self.SubscribeMap[sessionId] = sub
 
 		resp["Action"] = "subscribe"
@@ -197,6 +234,7 @@ func (self *WsServer) registryMethod() {
 		"getmempooltxstate":         {handler: rest.GetMemPoolTxState},
 		"getversion":                {handler: rest.GetNodeVersion},
 		"getnetworkid":              {handler: rest.GetNetworkId},
+		"getgasprice":               {handler: rest.GetGasPrice},
 
 		"getsessioncount": {handler: getsessioncount},
 	}
@@ -277,7 +315,7 @@ func (self *WsServer) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		e, ok := err.(net.Error)
 		if !ok || !e.Timeout() {
-			log.Infof("websocket conn:", err)
+			log.Infof("websocket conn: %s", err)
 			return
 		}
 	}
@@ -301,7 +339,7 @@ func (self *WsServer) OnDataHandle(curSession *session.Session, bysMsg []byte, r
 	if err := json.Unmarshal(bysMsg, &req); err != nil {
 		resp := rest.ResponsePack(Err.ILLEGAL_DATAFORMAT)
 		curSession.Send(marshalResp(resp))
-		log.Infof("websocket OnDataHandle:", err)
+		log.Infof("websocket OnDataHandle: %s", err)
 		return false
 	}
 	actionName, ok := req["Action"].(string)
@@ -369,13 +407,14 @@ func (self *WsServer) deleteSubscribe(sessionId string) {
 	self.Lock()
 	defer self.Unlock()
 	delete(self.SubscribeMap, sessionId)
+	delete(self.streams, sessionId)
 }
 
 func marshalResp(resp map[string]interface{}) []byte {
 	resp["Desc"] = Err.ErrMap[resp["Error"].(int64)]
 	data, err := json.Marshal(resp)
 	if err != nil {
-		log.Infof("Websocket marshal json error:", err)
+		log.Infof("Websocket marshal json error: %s", err)
 		return nil
 	}
 
@@ -423,7 +462,7 @@ func (self *WsServer) BroadcastToSubscribers(contractAddrs map[string]bool, sub
 			s.Send(data)
 		} else if sub == WSTOPIC_TXHASHS && v.SubscribeBlockTxHashs {
 			s.Send(data)
-		} else if sub == WSTOPIC_EVENT && v.SubscribeEvent {
+		} else if sub == WSTOPIC_EVENT && v.SubscribeEvent && len(v.EventFilters) == 0 {
 			if len(v.ContractsFilter) == 0 {
 				s.Send(data)
 				continue
@@ -438,6 +477,65 @@ func (self *WsServer) BroadcastToSubscribers(contractAddrs map[string]bool, sub
 	}
 }
 
+//PushFilteredEvents push the events up to block height to sessions subscribed with event filters
+func (self *WsServer) PushFilteredEvents(height uint32) {
+	self.RLock()
+	sessionIds := make([]string, 0, len(self.streams))
+	for sid := range self.streams {
+		sessionIds = append(sessionIds, sid)
+	}
+	self.RUnlock()
+	for _, sid := range sessionIds {
+		go self.pushFilteredEvents(sid, height)
+	}
+}
+
+//pushFilteredEvents push the events of session from its next height up to height. Events of a block are
+//pushed at least once, a client resumes with FromHeight set to the height of the last event it handled.
+func (self *WsServer) pushFilteredEvents(sessionId string, height uint32) {
+	self.RLock()
+	stream := self.streams[sessionId]
+	self.RUnlock()
+	if stream == nil {
+		return
+	}
+	stream.Lock()
+	defer stream.Unlock()
+	for ; stream.nextHeight <= height; stream.nextHeight++ {
+		self.RLock()
+		current := self.streams[sessionId]
+		filters := self.SubscribeMap[sessionId].EventFilters
+		self.RUnlock()
+		if current != stream {
+			return
+		}
+		s := self.SessionList.GetSessionById(sessionId)
+		if s == nil {
+			return
+		}
+		notifies, err := bactor.GetEventNotifyByHeight(stream.nextHeight)
+		if err != nil {
+			if err == scom.ErrNotFound {
+				continue
+			}
+			log.Errorf("websocket push events of height %d error: %s", stream.nextHeight, err)
+			return
+		}
+		for _, notify := range notifies {
+			evt := filterNotify(filters, stream.nextHeight, notify)
+			if evt == nil {
+				continue
+			}
+			resp := rest.ResponsePack(Err.SUCCESS)
+			resp["Action"] = "filteredevent"
+			resp["Result"] = evt
+			if err := s.Send(marshalResp(resp)); err != nil {
+				return
+			}
+		}
+	}
+}
+
 func (self *WsServer) initTlsListen() (net.Listener, error) {
 
 	certPath := cfg.DefConfig.Ws.HttpCertPath
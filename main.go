@@ -39,13 +39,18 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/common/metrics"
 	"github.com/polynetwork/poly/consensus"
+	"github.com/polynetwork/poly/consensus/vbft"
+	"github.com/polynetwork/poly/consensus/vbft/signer"
 	"github.com/polynetwork/poly/core/genesis"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/events"
 	hserver "github.com/polynetwork/poly/http/base/actor"
+	"github.com/polynetwork/poly/http/ethrpc"
 	"github.com/polynetwork/poly/http/jsonrpc"
 	"github.com/polynetwork/poly/http/localrpc"
+	hmetrics "github.com/polynetwork/poly/http/metrics"
 	"github.com/polynetwork/poly/http/nodeinfo"
 	"github.com/polynetwork/poly/http/restful"
 	"github.com/polynetwork/poly/http/websocket"
@@ -72,6 +77,10 @@ func setupAPP() *cli.App {
 		cmd.InfoCommand,
 		cmd.ImportCommand,
 		cmd.ExportCommand,
+		cmd.SnapshotCommand,
+		cmd.HeadersCommand,
+		cmd.ReplayCommand,
+		cmd.SignerCommand,
 		cmd.SigTxCommand,
 		cmd.MultiSigAddrCommand,
 		cmd.MultiSigTxCommand,
@@ -82,7 +91,11 @@ func setupAPP() *cli.App {
 		//common setting
 		utils.ConfigFlag,
 		utils.LogLevelFlag,
+		utils.LogFormatFlag,
+		utils.LogModulesFlag,
 		utils.DisableEventLogFlag,
+		utils.ArchiveFlag,
+		utils.PruneHeightFlag,
 		utils.DataDirFlag,
 		//account setting
 		utils.WalletFileFlag,
@@ -91,8 +104,13 @@ func setupAPP() *cli.App {
 		//consensus setting
 		utils.EnableConsensusFlag,
 		utils.MaxTxInBlockFlag,
+		utils.SignerFlag,
+		utils.SignerTLSCertFlag,
+		utils.SignerTLSKeyFlag,
+		utils.SignerTLSCAFlag,
 		//txpool setting
 		utils.TxpoolPreExecDisableFlag,
+		utils.GasPriceFlag,
 		utils.DisableBroadcastNetTxFlag,
 		//p2p setting
 		utils.ReservedPeersOnlyFlag,
@@ -113,6 +131,8 @@ func setupAPP() *cli.App {
 		utils.RPCPortFlag,
 		utils.RPCLocalEnableFlag,
 		utils.RPCLocalProtFlag,
+		utils.EthRPCEnableFlag,
+		utils.EthRPCPortFlag,
 		//rest setting
 		utils.RestfulEnableFlag,
 		utils.RestfulPortFlag,
@@ -120,6 +140,9 @@ func setupAPP() *cli.App {
 		//ws setting
 		utils.WsEnabledFlag,
 		utils.WsPortFlag,
+		//metrics setting
+		utils.MetricsEnableFlag,
+		utils.MetricsPortFlag,
 	}
 	app.Before = func(context *cli.Context) error {
 		runtime.GOMAXPROCS(runtime.NumCPU())
@@ -136,13 +159,17 @@ func main() {
 }
 
 func startOntology(ctx *cli.Context) {
-	initLog(ctx)
+	err := initLog(ctx)
+	if err != nil {
+		log.Errorf("initLog error: %s", err)
+		return
+	}
 
 	log.Infof("poly version %s", config.Version)
 
 	setMaxOpenFiles()
 
-	_, err := initConfig(ctx)
+	_, err = initConfig(ctx)
 	if err != nil {
 		log.Errorf("initConfig error: %s", err)
 		return
@@ -184,19 +211,33 @@ func startOntology(ctx *cli.Context) {
 		log.Errorf("initLocalRpc error:%s", err)
 		return
 	}
+	err = initEthRpc(ctx)
+	if err != nil {
+		log.Errorf("initEthRpc error:%s", err)
+		return
+	}
 	initRestful(ctx)
 	initWs(ctx)
 	initNodeInfo(ctx, p2pSvr)
+	initMetrics(ctx, txpool, p2pSvr)
 
 	go logCurrBlockHeight()
 	waitToExit()
 }
 
-func initLog(ctx *cli.Context) {
+func initLog(ctx *cli.Context) error {
 	//init log module
 	logLevel := ctx.GlobalInt(utils.GetFlagName(utils.LogLevelFlag))
 	alog.InitLog(log.PATH)
 	log.InitLog(logLevel, log.PATH, log.Stdout)
+	switch format := ctx.GlobalString(utils.GetFlagName(utils.LogFormatFlag)); format {
+	case "text":
+	case "json":
+		log.SetJSONFormat(true)
+	default:
+		return fmt.Errorf("invalid log format %s", format)
+	}
+	return log.SetLevels(ctx.GlobalString(utils.GetFlagName(utils.LogModulesFlag)))
 }
 
 func initConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
@@ -213,6 +254,10 @@ func initAccount(ctx *cli.Context) (*account.Account, error) {
 	if !config.DefConfig.Consensus.EnableConsensus {
 		return nil, nil
 	}
+	//consensus key is held by the signer daemon
+	if ctx.GlobalString(utils.GetFlagName(utils.SignerFlag)) != "" {
+		return nil, nil
+	}
 	walletFile := ctx.GlobalString(utils.GetFlagName(utils.WalletFileFlag))
 	if walletFile == "" {
 		return nil, fmt.Errorf("Please config wallet file using --wallet flag")
@@ -259,6 +304,15 @@ func initLedger(ctx *cli.Context) (*ledger.Ledger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Init ledger error:%s", err)
 	}
+	if pruneHeight := config.DefConfig.Common.PruneHeight; pruneHeight > 0 {
+		if config.DefConfig.Consensus.EnableConsensus {
+			return nil, fmt.Errorf("prune height %d is not supported with consensus enabled", pruneHeight)
+		}
+		err = ledger.DefLedger.PruneBlocks(pruneHeight)
+		if err != nil {
+			return nil, fmt.Errorf("PruneBlocks error:%s", err)
+		}
+	}
 
 	log.Infof("Ledger init success")
 	return ledger.DefLedger, nil
@@ -316,7 +370,16 @@ func initConsensus(ctx *cli.Context, p2pPid *actor.PID, txpoolSvr *proc.TXPoolSe
 	pool := txpoolSvr.GetPID(tc.TxPoolActor)
 
 	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
-	consensusService, err := consensus.NewConsensusService(consensusType, acc, pool, nil, p2pPid)
+	var consensusService consensus.ConsensusService
+	remoteSigner, err := initSigner(ctx, consensusType)
+	if err != nil {
+		return nil, fmt.Errorf("initSigner error:%s", err)
+	}
+	if remoteSigner != nil {
+		consensusService, err = consensus.NewVbftService(remoteSigner, pool, p2pPid)
+	} else {
+		consensusService, err = consensus.NewConsensusService(consensusType, acc, pool, nil, p2pPid)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("NewConsensusService:%s error:%s", consensusType, err)
 	}
@@ -329,6 +392,27 @@ func initConsensus(ctx *cli.Context, p2pPid *actor.PID, txpoolSvr *proc.TXPoolSe
 	return consensusService, nil
 }
 
+//initSigner connects to the signer daemon set by --signer, nil if not set
+func initSigner(ctx *cli.Context, consensusType string) (vbft.Signer, error) {
+	address := ctx.GlobalString(utils.GetFlagName(utils.SignerFlag))
+	if address == "" {
+		return nil, nil
+	}
+	if consensusType != consensus.CONSENSUS_VBFT {
+		return nil, fmt.Errorf("signer only supports vbft consensus, not %s", consensusType)
+	}
+	tlsConfig, err := cmd.GetSignerTLSConfig(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	client, err := signer.Dial(address, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Using signer %s, public key:%x", address, keypair.SerializePublicKey(client.PublicKey()))
+	return client, nil
+}
+
 func initRpc(ctx *cli.Context) error {
 	if !config.DefConfig.Rpc.EnableHttpJsonRpc {
 		return nil
@@ -378,6 +462,31 @@ func initLocalRpc(ctx *cli.Context) error {
 	return nil
 }
 
+func initEthRpc(ctx *cli.Context) error {
+	if !config.DefConfig.Rpc.EnableEthRpc {
+		return nil
+	}
+	var err error
+	exitCh := make(chan interface{}, 0)
+	go func() {
+		err = ethrpc.StartEthRPCServer()
+		close(exitCh)
+	}()
+
+	flag := false
+	select {
+	case <-exitCh:
+		if !flag {
+			return err
+		}
+	case <-time.After(time.Millisecond * 5):
+		flag = true
+	}
+
+	log.Infof("Eth rpc init success")
+	return nil
+}
+
 func initRestful(ctx *cli.Context) {
 	if !config.DefConfig.Restful.EnableHttpRestful {
 		return
@@ -405,6 +514,19 @@ func initNodeInfo(ctx *cli.Context, p2pSvr *p2pserver.P2PServer) {
 	log.Infof("Nodeinfo init success")
 }
 
+func initMetrics(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, p2pSvr *p2pserver.P2PServer) {
+	if !config.DefConfig.Metrics.EnableMetrics {
+		return
+	}
+	if err := txpoolSvr.RegisterMetrics(metrics.DefaultRegistry); err != nil {
+		log.Errorf("initMetrics error:%s", err)
+		return
+	}
+	go hmetrics.StartServer(p2pSvr.GetNetWork())
+
+	log.Infof("Metrics init success")
+}
+
 func logCurrBlockHeight() {
 	ticker := time.NewTicker(config.DEFAULT_GEN_BLOCK_TIME * time.Second)
 	for {
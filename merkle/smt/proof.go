@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package smt
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// Proof authenticates the value of one key against a tree root.
+// Siblings are ordered from the root downwards. For a non-membership proof
+// ending at another leaf, LeafKey and LeafValue hold that leaf's key hash and value hash.
+type Proof struct {
+	Siblings  []common.Uint256
+	HasLeaf   bool
+	LeafKey   common.Uint256
+	LeafValue common.Uint256
+}
+
+func (this *Proof) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Siblings)))
+	for _, v := range this.Siblings {
+		sink.WriteHash(v)
+	}
+	sink.WriteBool(this.HasLeaf)
+	if this.HasLeaf {
+		sink.WriteHash(this.LeafKey)
+		sink.WriteHash(this.LeafValue)
+	}
+}
+
+func (this *Proof) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("Proof.Deserialization, read siblings length error: %v", io.ErrUnexpectedEOF)
+	}
+	if n > MAX_DEPTH {
+		return fmt.Errorf("Proof.Deserialization, siblings length %d exceeds %d", n, MAX_DEPTH)
+	}
+	siblings := make([]common.Uint256, 0, n)
+	for i := uint64(0); i < n; i++ {
+		hash, eof := source.NextHash()
+		if eof {
+			return fmt.Errorf("Proof.Deserialization, read sibling error: %v", io.ErrUnexpectedEOF)
+		}
+		siblings = append(siblings, hash)
+	}
+	hasLeaf, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("Proof.Deserialization, read hasLeaf error")
+	}
+	this.Siblings = siblings
+	this.HasLeaf = hasLeaf
+	if hasLeaf {
+		if this.LeafKey, eof = source.NextHash(); eof {
+			return fmt.Errorf("Proof.Deserialization, read leaf key error: %v", io.ErrUnexpectedEOF)
+		}
+		if this.LeafValue, eof = source.NextHash(); eof {
+			return fmt.Errorf("Proof.Deserialization, read leaf value error: %v", io.ErrUnexpectedEOF)
+		}
+	}
+	return nil
+}
+
+// VerifyProof checks proof for key against root. A nil value asks for a proof of absence.
+func VerifyProof(root common.Uint256, key, value []byte, proof *Proof) error {
+	keyHash := HashKey(key)
+	depth := len(proof.Siblings)
+	if depth > MAX_DEPTH {
+		return fmt.Errorf("VerifyProof, proof depth %d exceeds %d", depth, MAX_DEPTH)
+	}
+
+	var current common.Uint256
+	if value != nil {
+		if proof.HasLeaf {
+			return fmt.Errorf("VerifyProof, membership proof must not carry another leaf")
+		}
+		current = HashLeaf(keyHash, HashValue(value))
+	} else if proof.HasLeaf {
+		if proof.LeafKey == keyHash {
+			return fmt.Errorf("VerifyProof, key is present in the tree")
+		}
+		for i := 0; i < depth; i++ {
+			if bit(proof.LeafKey, i) != bit(keyHash, i) {
+				return fmt.Errorf("VerifyProof, leaf is not on the path of key")
+			}
+		}
+		current = HashLeaf(proof.LeafKey, proof.LeafValue)
+	} else {
+		current = common.UINT256_EMPTY
+	}
+
+	for i := depth - 1; i >= 0; i-- {
+		if bit(keyHash, i) == 0 {
+			current = HashInternal(current, proof.Siblings[i])
+		} else {
+			current = HashInternal(proof.Siblings[i], current)
+		}
+	}
+	if current != root {
+		return fmt.Errorf("VerifyProof, root mismatch, expect %s, got %s", root.ToHexString(), current.ToHexString())
+	}
+	return nil
+}
+
+// VerifyProofBytes is VerifyProof with a serialized proof, as returned by the getstorageproof rpc
+func VerifyProofBytes(root common.Uint256, key, value []byte, proof []byte) error {
+	p := new(Proof)
+	if err := p.Deserialization(common.NewZeroCopySource(proof)); err != nil {
+		return fmt.Errorf("VerifyProofBytes, %v", err)
+	}
+	return VerifyProof(root, key, value, p)
+}
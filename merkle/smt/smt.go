@@ -0,0 +1,305 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package smt implements a compacted sparse merkle tree over 256-bit key hashes.
+// A subtree holding a single leaf is stored as the leaf itself, so the root only
+// depends on the set of key-value pairs and not on the order they were written.
+package smt
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+const (
+	LEAF_NODE     byte = 0
+	INTERNAL_NODE byte = 1
+
+	NODE_SIZE = 1 + 2*common.UINT256_SIZE
+	MAX_DEPTH = common.UINT256_SIZE * 8
+)
+
+// NodeStore persists tree nodes by their hash. GetNode returns nil if the node is unknown.
+type NodeStore interface {
+	GetNode(hash common.Uint256) ([]byte, error)
+	PutNode(hash common.Uint256, data []byte)
+}
+
+type node struct {
+	kind  byte
+	left  common.Uint256 // key hash of a leaf
+	right common.Uint256 // value hash of a leaf
+	value []byte         // raw value of a leaf, not part of the hash
+}
+
+func (n *node) encode() []byte {
+	data := make([]byte, 0, NODE_SIZE+len(n.value))
+	data = append(data, n.kind)
+	data = append(data, n.left[:]...)
+	data = append(data, n.right[:]...)
+	return append(data, n.value...)
+}
+
+func (n *node) hash() common.Uint256 {
+	return sha256.Sum256(n.encode()[:NODE_SIZE])
+}
+
+func decodeNode(data []byte) (*node, error) {
+	if len(data) < NODE_SIZE {
+		return nil, fmt.Errorf("decodeNode, node size %d too small", len(data))
+	}
+	n := &node{kind: data[0]}
+	copy(n.left[:], data[1:1+common.UINT256_SIZE])
+	copy(n.right[:], data[1+common.UINT256_SIZE:NODE_SIZE])
+	switch n.kind {
+	case LEAF_NODE:
+		n.value = data[NODE_SIZE:]
+	case INTERNAL_NODE:
+		if len(data) != NODE_SIZE {
+			return nil, fmt.Errorf("decodeNode, internal node size %d invalid", len(data))
+		}
+	default:
+		return nil, fmt.Errorf("decodeNode, unknown node kind %d", n.kind)
+	}
+	return n, nil
+}
+
+func HashKey(key []byte) common.Uint256 {
+	return sha256.Sum256(key)
+}
+
+func HashValue(value []byte) common.Uint256 {
+	return sha256.Sum256(value)
+}
+
+func HashLeaf(keyHash, valueHash common.Uint256) common.Uint256 {
+	return (&node{kind: LEAF_NODE, left: keyHash, right: valueHash}).hash()
+}
+
+func HashInternal(left, right common.Uint256) common.Uint256 {
+	return (&node{kind: INTERNAL_NODE, left: left, right: right}).hash()
+}
+
+// bit returns the bit of hash at depth, counting from the most significant bit
+func bit(hash common.Uint256, depth int) byte {
+	return (hash[depth/8] >> uint(7-depth%8)) & 1
+}
+
+type Tree struct {
+	root  common.Uint256
+	store NodeStore
+}
+
+func NewTree(root common.Uint256, store NodeStore) *Tree {
+	return &Tree{root: root, store: store}
+}
+
+func (self *Tree) Root() common.Uint256 {
+	return self.root
+}
+
+// Update sets the value of key, a nil value removes the key from the tree
+func (self *Tree) Update(key, value []byte) error {
+	keyHash := HashKey(key)
+	var leaf *node
+	if value != nil {
+		leaf = &node{kind: LEAF_NODE, left: keyHash, right: HashValue(value), value: value}
+	}
+	root, err := self.update(self.root, keyHash, leaf, 0)
+	if err != nil {
+		return fmt.Errorf("Update key %x error: %s", key, err)
+	}
+	self.root = root
+	return nil
+}
+
+// Get returns the value of key, or nil if the key is not in the tree
+func (self *Tree) Get(key []byte) ([]byte, error) {
+	leaf, _, err := self.lookup(HashKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if leaf == nil {
+		return nil, nil
+	}
+	return leaf.value, nil
+}
+
+// Prove returns the value of key together with a proof against the current root.
+// The proof shows absence when the returned value is nil.
+func (self *Tree) Prove(key []byte) ([]byte, *Proof, error) {
+	keyHash := HashKey(key)
+	leaf, siblings, err := self.lookup(keyHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof := &Proof{Siblings: siblings}
+	if leaf == nil {
+		return nil, proof, nil
+	}
+	if leaf.left != keyHash {
+		proof.LeafKey = leaf.left
+		proof.LeafValue = leaf.right
+		proof.HasLeaf = true
+		return nil, proof, nil
+	}
+	return leaf.value, proof, nil
+}
+
+// lookup walks down to the leaf on the path of keyHash, collecting the siblings
+func (self *Tree) lookup(keyHash common.Uint256) (*node, []common.Uint256, error) {
+	siblings := make([]common.Uint256, 0)
+	current := self.root
+	for depth := 0; depth <= MAX_DEPTH; depth++ {
+		if current == common.UINT256_EMPTY {
+			return nil, siblings, nil
+		}
+		n, err := self.getNode(current)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n.kind == LEAF_NODE {
+			return n, siblings, nil
+		}
+		if bit(keyHash, depth) == 0 {
+			siblings = append(siblings, n.right)
+			current = n.left
+		} else {
+			siblings = append(siblings, n.left)
+			current = n.right
+		}
+	}
+	return nil, nil, fmt.Errorf("lookup, tree deeper than %d", MAX_DEPTH)
+}
+
+func (self *Tree) getNode(hash common.Uint256) (*node, error) {
+	data, err := self.store.GetNode(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getNode %x error: %s", hash, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("getNode %x, node missing", hash)
+	}
+	return decodeNode(data)
+}
+
+func (self *Tree) putNode(n *node) common.Uint256 {
+	hash := n.hash()
+	self.store.PutNode(hash, n.encode())
+	return hash
+}
+
+func (self *Tree) update(current, keyHash common.Uint256, leaf *node, depth int) (common.Uint256, error) {
+	if depth > MAX_DEPTH {
+		return common.UINT256_EMPTY, fmt.Errorf("update, tree deeper than %d", MAX_DEPTH)
+	}
+	if current == common.UINT256_EMPTY {
+		if leaf == nil {
+			return common.UINT256_EMPTY, nil
+		}
+		return self.putNode(leaf), nil
+	}
+	n, err := self.getNode(current)
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	if n.kind == LEAF_NODE {
+		if n.left == keyHash {
+			if leaf == nil {
+				return common.UINT256_EMPTY, nil
+			}
+			return self.putNode(leaf), nil
+		}
+		if leaf == nil {
+			return current, nil
+		}
+		return self.split(n, current, leaf, depth)
+	}
+
+	left, right := n.left, n.right
+	if bit(keyHash, depth) == 0 {
+		left, err = self.update(left, keyHash, leaf, depth+1)
+	} else {
+		right, err = self.update(right, keyHash, leaf, depth+1)
+	}
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	return self.compact(left, right)
+}
+
+// split builds the smallest subtree that holds both the existing leaf and the new one
+func (self *Tree) split(old *node, oldHash common.Uint256, leaf *node, depth int) (common.Uint256, error) {
+	if depth >= MAX_DEPTH {
+		return common.UINT256_EMPTY, fmt.Errorf("split, key hash collision %x", leaf.left)
+	}
+	oldBit, newBit := bit(old.left, depth), bit(leaf.left, depth)
+	if oldBit != newBit {
+		newHash := self.putNode(leaf)
+		if newBit == 0 {
+			return self.putNode(&node{kind: INTERNAL_NODE, left: newHash, right: oldHash}), nil
+		}
+		return self.putNode(&node{kind: INTERNAL_NODE, left: oldHash, right: newHash}), nil
+	}
+	child, err := self.split(old, oldHash, leaf, depth+1)
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	if newBit == 0 {
+		return self.putNode(&node{kind: INTERNAL_NODE, left: child, right: common.UINT256_EMPTY}), nil
+	}
+	return self.putNode(&node{kind: INTERNAL_NODE, left: common.UINT256_EMPTY, right: child}), nil
+}
+
+// compact keeps the invariant that a subtree with a single leaf is the leaf itself
+func (self *Tree) compact(left, right common.Uint256) (common.Uint256, error) {
+	if left == common.UINT256_EMPTY && right == common.UINT256_EMPTY {
+		return common.UINT256_EMPTY, nil
+	}
+	if left == common.UINT256_EMPTY || right == common.UINT256_EMPTY {
+		only := left
+		if only == common.UINT256_EMPTY {
+			only = right
+		}
+		n, err := self.getNode(only)
+		if err != nil {
+			return common.UINT256_EMPTY, err
+		}
+		if n.kind == LEAF_NODE {
+			return only, nil
+		}
+	}
+	return self.putNode(&node{kind: INTERNAL_NODE, left: left, right: right}), nil
+}
+
+// MemNodeStore is a NodeStore kept in memory
+type MemNodeStore map[common.Uint256][]byte
+
+func NewMemNodeStore() MemNodeStore {
+	return make(MemNodeStore)
+}
+
+func (self MemNodeStore) GetNode(hash common.Uint256) ([]byte, error) {
+	return self[hash], nil
+}
+
+func (self MemNodeStore) PutNode(hash common.Uint256, data []byte) {
+	self[hash] = data
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package smt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyTree(t *testing.T) {
+	tree := NewTree(common.UINT256_EMPTY, NewMemNodeStore())
+	assert.Equal(t, common.UINT256_EMPTY, tree.Root())
+
+	value, proof, err := tree.Prove([]byte("key"))
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	assert.Nil(t, VerifyProof(tree.Root(), []byte("key"), nil, proof))
+	assert.NotNil(t, VerifyProof(tree.Root(), []byte("key"), []byte("value"), proof))
+}
+
+func TestUpdateAndProve(t *testing.T) {
+	tree := NewTree(common.UINT256_EMPTY, NewMemNodeStore())
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, tree.Update([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	root := tree.Root()
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		value, proof, err := tree.Prove(key)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte(fmt.Sprintf("value%d", i)), value)
+		assert.Nil(t, VerifyProof(root, key, value, proof))
+		assert.NotNil(t, VerifyProof(root, key, []byte("other"), proof))
+		assert.NotNil(t, VerifyProof(root, key, nil, proof))
+
+		sink := common.NewZeroCopySink(nil)
+		proof.Serialization(sink)
+		assert.Nil(t, VerifyProofBytes(root, key, value, sink.Bytes()))
+	}
+	for i := 100; i < 150; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		value, proof, err := tree.Prove(key)
+		assert.Nil(t, err)
+		assert.Nil(t, value)
+		assert.Nil(t, VerifyProof(root, key, nil, proof))
+		assert.NotNil(t, VerifyProof(root, key, []byte("value"), proof))
+
+		sink := common.NewZeroCopySink(nil)
+		proof.Serialization(sink)
+		decoded := new(Proof)
+		assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+		assert.Equal(t, proof, decoded)
+	}
+}
+
+func TestRootIndependentOfOrder(t *testing.T) {
+	a := NewTree(common.UINT256_EMPTY, NewMemNodeStore())
+	b := NewTree(common.UINT256_EMPTY, NewMemNodeStore())
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, a.Update([]byte(fmt.Sprintf("key%d", i)), []byte{byte(i)}))
+		assert.Nil(t, b.Update([]byte(fmt.Sprintf("key%d", 49-i)), []byte{byte(49 - i)}))
+	}
+	assert.Equal(t, a.Root(), b.Root())
+
+	// overwrite then delete back to the same content
+	assert.Nil(t, a.Update([]byte("key7"), []byte("changed")))
+	assert.NotEqual(t, a.Root(), b.Root())
+	assert.Nil(t, a.Update([]byte("key7"), []byte{7}))
+	assert.Equal(t, a.Root(), b.Root())
+}
+
+func TestDelete(t *testing.T) {
+	tree := NewTree(common.UINT256_EMPTY, NewMemNodeStore())
+	single := NewTree(common.UINT256_EMPTY, NewMemNodeStore())
+	assert.Nil(t, single.Update([]byte("key0"), []byte("value0")))
+
+	for i := 0; i < 20; i++ {
+		assert.Nil(t, tree.Update([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	old := tree.Root()
+	for i := 1; i < 20; i++ {
+		assert.Nil(t, tree.Update([]byte(fmt.Sprintf("key%d", i)), nil))
+	}
+	assert.Equal(t, single.Root(), tree.Root())
+
+	// deleting a missing key keeps the root
+	assert.Nil(t, tree.Update([]byte("missing"), nil))
+	assert.Equal(t, single.Root(), tree.Root())
+
+	assert.Nil(t, tree.Update([]byte("key0"), nil))
+	assert.Equal(t, common.UINT256_EMPTY, tree.Root())
+
+	// old roots stay readable
+	history := NewTree(old, tree.store)
+	value, err := history.Get([]byte("key5"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value5"), value)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package native
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/native/event"
+)
+
+const (
+	DEFAULT_METHOD_BASE_GAS     = 10000 //Gas charged for each invocation of the native method without gas schedule
+	DEFAULT_METHOD_PER_BYTE_GAS = 10    //Gas charged for each byte of args of the native method without gas schedule
+)
+
+//MethodGas is the gas schedule of a native contract method
+type MethodGas struct {
+	Base    uint64 //Gas charged for each invocation of the method
+	PerByte uint64 //Gas charged for each byte of the invoke args
+}
+
+//GasFeeService deducts the gas fee from the payer of the transaction and returns the fee charged. If the balance of
+//payer is not enough, the whole balance is charged when partial is true, or else nothing is charged and error is returned.
+type GasFeeService func(native *NativeService, fee *big.Int, partial bool) (*big.Int, error)
+
+//GasFeeCharger of GasFeeContract settles the gas fee of metered transactions, the fee is not charged without it
+var (
+	GasFeeContract common.Address
+	GasFeeCharger  GasFeeService
+)
+
+//DefaultMethodGas is the gas schedule of the native methods which don't register their own
+var DefaultMethodGas = MethodGas{Base: DEFAULT_METHOD_BASE_GAS, PerByte: DEFAULT_METHOD_PER_BYTE_GAS}
+
+//Cost return the gas of invoking the method with args of the length
+func (this MethodGas) Cost(argsLen int) uint64 {
+	if this.PerByte != 0 && uint64(argsLen) > (math.MaxUint64-this.Base)/this.PerByte {
+		return math.MaxUint64
+	}
+	return this.Base + this.PerByte*uint64(argsLen)
+}
+
+//isGasMetering return whether the gas limit of transaction is enforced at the height
+func isGasMetering(height uint32) bool {
+	return height >= config.GetGasMeteringHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//RegisterGas set the gas schedule of the native method, should be called in the register service of contract
+func (this *NativeService) RegisterGas(methodName string, gas MethodGas) {
+	this.gasSchedule[methodName] = gas
+}
+
+//ChargeGas consume the gas of the transaction, return error if the gas limit is exceeded.
+//Native methods with cost depending on the input can charge extra gas by it
+func (this *NativeService) ChargeGas(gas uint64) error {
+	if gas > this.gasLimit-this.gasUsed {
+		this.gasUsed = this.gasLimit
+		return fmt.Errorf("out of gas, gas limit %d", this.gasLimit)
+	}
+	this.gasUsed += gas
+	return nil
+}
+
+//GetGasUsed return the gas consumed by the transaction
+func (this *NativeService) GetGasUsed() uint64 {
+	return this.gasUsed
+}
+
+//GetGasFee return the fee of the gas consumed by the transaction at its gas price, it is zero if the transaction
+//is not metered
+func (this *NativeService) GetGasFee() *big.Int {
+	if !this.metering {
+		return new(big.Int)
+	}
+	fee := new(big.Int).SetUint64(this.gasUsed)
+	return fee.Mul(fee, new(big.Int).SetUint64(this.tx.GasPrice))
+}
+
+//ChargeGasFee settle the gas fee of the transaction by GasFeeCharger, return the notify of the fee charged, which is
+//nil if nothing is charged
+func (this *NativeService) ChargeGasFee(partial bool) (*event.NotifyEventInfo, error) {
+	fee := this.GetGasFee()
+	if fee.Sign() <= 0 || GasFeeCharger == nil {
+		return nil, nil
+	}
+	charged, err := GasFeeCharger(this, fee, partial)
+	if err != nil || charged.Sign() <= 0 {
+		return nil, err
+	}
+	return &event.NotifyEventInfo{
+		ContractAddress: GasFeeContract,
+		States:          []interface{}{"chargeGasFee", this.tx.Payer.ToBase58(), charged.String()},
+	}, nil
+}
+
+//getMethodGas return the gas schedule of the native method
+func (this *NativeService) getMethodGas(methodName string) MethodGas {
+	gas, ok := this.gasSchedule[methodName]
+	if !ok {
+		return DefaultMethodGas
+	}
+	return gas
+}
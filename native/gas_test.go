@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package native
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+var testGasContract = common.Address{0xaa}
+
+func init() {
+	Contracts[testGasContract] = func(native *NativeService) {
+		native.Register("cheap", func(native *NativeService) ([]byte, error) {
+			return []byte{1}, nil
+		})
+		native.Register("expensive", func(native *NativeService) ([]byte, error) {
+			if err := native.ChargeGas(5000); err != nil {
+				return nil, err
+			}
+			return []byte{1}, nil
+		})
+		native.Register("nested", func(native *NativeService) ([]byte, error) {
+			if _, err := native.NativeCall(testGasContract, "cheap", nil); err != nil {
+				return nil, err
+			}
+			return []byte{1}, nil
+		})
+		native.RegisterGas("expensive", MethodGas{Base: 1000, PerByte: 100})
+	}
+}
+
+func invokeTestGas(t *testing.T, gasLimit uint64, method string, args []byte) (*NativeService, error) {
+	tx := &types.Transaction{GasLimit: gasLimit, GasPrice: 2, Sigs: []types.Sig{{M: 1}}}
+	param := states.ContractInvokeParam{Address: testGasContract, Method: method, Args: args}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	service, err := NewNativeService(nil, tx, 0, 10, common.UINT256_EMPTY, 0, sink.Bytes(), false)
+	assert.Nil(t, err)
+	_, err = service.Invoke()
+	return service, err
+}
+
+func TestMethodGasCost(t *testing.T) {
+	assert.Equal(t, uint64(DEFAULT_METHOD_BASE_GAS+DEFAULT_METHOD_PER_BYTE_GAS*3), DefaultMethodGas.Cost(3))
+	assert.Equal(t, uint64(7), MethodGas{Base: 7}.Cost(100))
+	assert.Equal(t, uint64(math.MaxUint64), MethodGas{Base: 1, PerByte: math.MaxUint64}.Cost(2))
+}
+
+func TestGasMetering(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	meteringHeight := config.DefConfig.Genesis.GasMeteringHeight
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+		config.DefConfig.Genesis.GasMeteringHeight = meteringHeight
+	}()
+
+	//gas is counted but not limited before the metering height
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	service, err := invokeTestGas(t, 0, "expensive", []byte{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000+100*2+5000), service.GetGasUsed())
+	assert.Equal(t, int64(0), service.GetGasFee().Int64())
+
+	//other networks are not metered unless the height is set in genesis config
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis.GasMeteringHeight = 0
+	_, err = invokeTestGas(t, 0, "expensive", []byte{1, 2})
+	assert.Nil(t, err)
+
+	config.DefConfig.Genesis.GasMeteringHeight = 1
+	service, err = invokeTestGas(t, 6200, "expensive", []byte{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6200), service.GetGasUsed())
+	assert.Equal(t, int64(6200*2), service.GetGasFee().Int64())
+
+	service, err = invokeTestGas(t, 6199, "expensive", []byte{1, 2})
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(6199), service.GetGasUsed())
+
+	service, err = invokeTestGas(t, 100, "cheap", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(100), service.GetGasUsed())
+
+	//nested native call is charged too
+	service, err = invokeTestGas(t, 2*DEFAULT_METHOD_BASE_GAS, "nested", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2*DEFAULT_METHOD_BASE_GAS), service.GetGasUsed())
+}
+
+func TestGasMeteringExemption(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	meteringHeight := config.DefConfig.Genesis.GasMeteringHeight
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+		config.DefConfig.Genesis.GasMeteringHeight = meteringHeight
+	}()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis.GasMeteringHeight = 1
+
+	param := states.ContractInvokeParam{Address: testGasContract, Method: "expensive"}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+
+	//genesis and unsigned system transactions have no gas limit
+	for _, c := range []struct {
+		height uint32
+		sigs   []types.Sig
+	}{{0, []types.Sig{{M: 1}}}, {10, nil}} {
+		tx := &types.Transaction{GasPrice: 1, Sigs: c.sigs}
+		service, err := NewNativeService(nil, tx, 0, c.height, common.UINT256_EMPTY, 0, sink.Bytes(), false)
+		assert.Nil(t, err)
+		_, err = service.Invoke()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), service.GetGasFee().Int64())
+	}
+}
+
+func TestChargeGasFee(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	meteringHeight := config.DefConfig.Genesis.GasMeteringHeight
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+		config.DefConfig.Genesis.GasMeteringHeight = meteringHeight
+		GasFeeCharger = nil
+	}()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis.GasMeteringHeight = 1
+
+	//the fee is not charged without charger
+	service, err := invokeTestGas(t, 6200, "expensive", []byte{1, 2})
+	assert.Nil(t, err)
+	notify, err := service.ChargeGasFee(false)
+	assert.Nil(t, err)
+	assert.Nil(t, notify)
+
+	balance := big.NewInt(20000)
+	GasFeeContract = testGasContract
+	GasFeeCharger = func(native *NativeService, fee *big.Int, partial bool) (*big.Int, error) {
+		if balance.Cmp(fee) < 0 {
+			if !partial {
+				return nil, fmt.Errorf("balance %s is less than gas fee %s", balance.String(), fee.String())
+			}
+			fee = new(big.Int).Set(balance)
+		}
+		balance.Sub(balance, fee)
+		return fee, nil
+	}
+	notify, err = service.ChargeGasFee(false)
+	assert.Nil(t, err)
+	assert.Equal(t, testGasContract, notify.ContractAddress)
+	assert.Equal(t, []interface{}{"chargeGasFee", common.ADDRESS_EMPTY.ToBase58(), "12400"}, notify.States)
+	_, err = service.ChargeGasFee(false)
+	assert.NotNil(t, err)
+	notify, err = service.ChargeGasFee(true)
+	assert.Nil(t, err)
+	assert.Equal(t, "7600", notify.States.([]interface{})[2])
+
+	//nothing charged has no notify
+	notify, err = service.ChargeGasFee(true)
+	assert.Nil(t, err)
+	assert.Nil(t, notify)
+	assert.Equal(t, int64(0), balance.Int64())
+}
@@ -19,6 +19,8 @@ package native
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
@@ -36,6 +38,9 @@ type (
 
 var (
 	Contracts = make(map[common.Address]RegisterService)
+
+	//clock is the local time source of native contracts, replaced when replaying blocks
+	clock = time.Now
 )
 
 const (
@@ -57,6 +62,11 @@ type NativeService struct {
 	crossHashes   []common.Uint256
 	contexts      []common.Address
 	preExec       bool
+	gasSchedule   map[string]MethodGas
+	gasLimit      uint64
+	gasUsed       uint64
+	metering      bool
+	storedHeaders uint32
 }
 
 func NewNativeService(cacheDB *storage.CacheDB, tx *types.Transaction,
@@ -74,11 +84,31 @@ func NewNativeService(cacheDB *storage.CacheDB, tx *types.Transaction,
 		input:      input,
 		chainID:    chainID,
 		preExec:    preExec,
+		gasLimit:   math.MaxUint64,
+	}
+	//gas is always counted, the gas limit of transaction is enforced after the metering height. Genesis and
+	//system transactions made by consensus are unsigned and not metered
+	if !preExec && height != 0 && len(tx.Sigs) != 0 && isGasMetering(height) {
+		service.gasLimit = tx.GasLimit
+		service.metering = true
 	}
 
 	return service, nil
 }
 
+//SetClock replace the local time source of native contracts, return a function restoring the previous one
+func SetClock(now func() time.Time) func() {
+	prev := clock
+	clock = now
+	return func() { clock = prev }
+}
+
+//Now return the local time seen by native contracts. It is not part of the consensus state,
+//handlers using it may produce different results on different nodes.
+func (this *NativeService) Now() time.Time {
+	return clock()
+}
+
 func (this *NativeService) Register(methodName string, handler Handler) {
 	this.serviceMap[methodName] = handler
 }
@@ -92,12 +122,16 @@ func (this *NativeService) Invoke() (interface{}, error) {
 	if !ok {
 		return false, fmt.Errorf("[Invoke] Native contract address %x haven't been registered.", invokeParam.Address)
 	}
+	this.gasSchedule = make(map[string]MethodGas)
 	services(this)
 	service, ok := this.serviceMap[invokeParam.Method]
 	if !ok {
 		return false, fmt.Errorf("[Invoke] Native contract %x doesn't support this function %s.",
 			invokeParam.Address, invokeParam.Method)
 	}
+	if err := this.ChargeGas(this.getMethodGas(invokeParam.Method).Cost(len(invokeParam.Args))); err != nil {
+		return false, fmt.Errorf("[Invoke] Native contract %x function %s error:%s", invokeParam.Address, invokeParam.Method, err)
+	}
 	args := this.input
 	this.input = invokeParam.Args
 	notifications := this.notifications
@@ -162,6 +196,16 @@ func (this *NativeService) PutMerkleVal(data []byte) {
 	this.crossHashes = append(this.crossHashes, merkle.HashLeaf(data))
 }
 
+//AddStoredHeader counts a side chain header newly stored by the transaction
+func (this *NativeService) AddStoredHeader() {
+	this.storedHeaders++
+}
+
+//GetStoredHeaders return the count of side chain headers newly stored by the transaction
+func (this *NativeService) GetStoredHeaders() uint32 {
+	return this.storedHeaders
+}
+
 func (this *NativeService) checkAccountAddress(address common.Address) bool {
 	addresses, err := this.tx.GetSignatureAddresses()
 	if err != nil {
@@ -219,6 +263,11 @@ func (this *NativeService) GetChainID() uint64 {
 	return this.chainID
 }
 
+//IsPreExec return whether the transaction is pre executed, state changes of pre execution are discarded
+func (this *NativeService) IsPreExec() bool {
+	return this.preExec
+}
+
 func (this *NativeService) GetNotify() []*event.NotifyEventInfo {
 	return this.notifications
 }
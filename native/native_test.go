@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package native
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetClock(t *testing.T) {
+	service := &NativeService{}
+	fixed := time.Unix(1600000000, 0)
+	restore := SetClock(func() time.Time { return fixed })
+	assert.Equal(t, fixed, service.Now())
+
+	restoreInner := SetClock(func() time.Time { return fixed.Add(time.Hour) })
+	assert.Equal(t, fixed.Add(time.Hour), service.Now())
+	restoreInner()
+	assert.Equal(t, fixed, service.Now())
+
+	restore()
+	assert.True(t, time.Since(service.Now()) < time.Minute)
+}
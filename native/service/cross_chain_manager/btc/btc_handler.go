@@ -37,6 +37,16 @@ import (
 type BTCHandler struct {
 }
 
+func init() {
+	crosscommon.RegisterChainHandler(&crosscommon.ChainHandlerInfo{
+		Router:     utils.BTC_ROUTER,
+		NewHandler: func() crosscommon.ChainHandler { return NewBTCHandler() },
+		MakeTransaction: func(service *native.NativeService, param *crosscommon.MakeTxParam, fromChainID uint64) error {
+			return NewBTCHandler().MakeTransaction(service, param, fromChainID)
+		},
+	})
+}
+
 func NewBTCHandler() *BTCHandler {
 	return &BTCHandler{}
 }
@@ -35,12 +35,21 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/bytom"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // Handler ...
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:      utils.BYTOM_ROUTER,
+		NewHandler:  func() scom.ChainHandler { return NewHandler() },
+		StartBlocks: utils.HardForkRouterStartBlocks,
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync/cometbft"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type CometBFTHandler struct {
+}
+
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:      utils.COMETBFT_ROUTER,
+		NewHandler:  func() scom.ChainHandler { return NewCometBFTHandler() },
+		StartBlocks: utils.CometBFTRouterStartBlocks,
+	})
+}
+
+func NewCometBFTHandler() *CometBFTHandler {
+	return &CometBFTHandler{}
+}
+
+//MakeDepositProposal verifies the cross chain tx against the app hash of header at params.Height, so the proof
+//should be queried at params.Height-1. The header is verified and synced first if it is in HeaderOrCrossChainMsg.
+func (this *CometBFTHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, contract params deserialize error: %s", err)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, side chain %d is not registered", params.SourceChainID)
+	}
+	ctx, err := cometbft.DecodeContext(sideChain.ExtraInfo)
+	if err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, %v", err)
+	}
+	header, err := cometbft.GetHeaderByHeight(service, uint64(params.Height), params.SourceChainID)
+	if err != nil && len(params.HeaderOrCrossChainMsg) != 0 {
+		if err := cometbft.SyncLightBlock(service, ctx, params.SourceChainID, params.HeaderOrCrossChainMsg); err != nil {
+			return nil, fmt.Errorf("CometBFT MakeDepositProposal, sync header error: %v", err)
+		}
+		header, err = cometbft.GetHeaderByHeight(service, uint64(params.Height), params.SourceChainID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, %v", err)
+	}
+	value, err := verifyFromCometBFTTx(params.Proof, params.Extra, header, ctx.StoreName, sideChain.CCMCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, verifyFromCometBFTTx error: %s", err)
+	}
+	if err := scom.CheckDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, check done transaction error:%s", err)
+	}
+	if err := scom.PutDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("CometBFT MakeDepositProposal, PutDoneTx error:%s", err)
+	}
+	return value, nil
+}
+
+//verifyFromCometBFTTx verifies extra is stored under the proof key of cross chain manager store
+func verifyFromCometBFTTx(proof, extra []byte, header *cometbft.Header, storeName string,
+	ccmcAddress []byte) (*scom.MakeTxParam, error) {
+	commitmentProof := new(CommitmentProof)
+	if err := commitmentProof.Deserialization(common.NewZeroCopySource(proof)); err != nil {
+		return nil, fmt.Errorf("VerifyFromCometBFTProof, deserialize proof error:%s", err)
+	}
+	if err := commitmentProof.checkKey(storeName, ccmcAddress); err != nil {
+		return nil, fmt.Errorf("VerifyFromCometBFTProof, %s", err)
+	}
+	if err := commitmentProof.VerifyMembership(header.AppHash, extra); err != nil {
+		return nil, fmt.Errorf("VerifyFromCometBFTProof, height:%d, %s", header.Height, err)
+	}
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(extra)); err != nil {
+		return nil, fmt.Errorf("VerifyFromCometBFTProof, deserialize merkleValue error:%s", err)
+	}
+	return txParam, nil
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"encoding/json"
+	"testing"
+
+	ics23 "github.com/confio/ics23/go"
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync/cometbft"
+	hscom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/switcheo/tendermint/crypto/tmhash"
+)
+
+var testCCMCAddress = []byte{0x01, 0x02}
+
+func newNative(args []byte, db *storage.CacheDB) *native.NativeService {
+	if db == nil {
+		store, _ := leveldbstore.NewMemLevelDBStore()
+		db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	}
+	service, _ := native.NewNativeService(db, &types.Transaction{}, 0, 0, common.Uint256{0}, 0, args, false)
+	return service
+}
+
+func putHeader(service *native.NativeService, chainID uint64, header *cometbft.Header) {
+	data, _ := json.Marshal(header)
+	service.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscom.BLOCK_HEADER),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(uint64(header.Height))), cstates.GenRawStorageItem(data))
+}
+
+func marshalProof(proof *ics23.CommitmentProof) []byte {
+	data, err := proof.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+//testCommitmentProof builds the proofs of key value pair in an iavl store of two leaves, and the store in a multi
+//store of two stores, it returns the proof and the app hash
+func testCommitmentProof(storeName string, key, value []byte) (*CommitmentProof, []byte) {
+	storeProof := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{
+		Key:   key,
+		Value: value,
+		Leaf: &ics23.LeafOp{
+			Hash:         ics23.HashOp_SHA256,
+			PrehashValue: ics23.HashOp_SHA256,
+			Length:       ics23.LengthOp_VAR_PROTO,
+			Prefix:       []byte{0, 2, 2},
+		},
+		Path: []*ics23.InnerOp{{
+			Hash:   ics23.HashOp_SHA256,
+			Prefix: []byte{2, 4, 4, 32},
+			Suffix: append([]byte{32}, tmhash.Sum([]byte("sibling leaf"))...),
+		}},
+	}}}
+	storeRoot, err := storeProof.Calculate()
+	if err != nil {
+		panic(err)
+	}
+	rootProof := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{
+		Key:   []byte(storeName),
+		Value: storeRoot,
+		Leaf: &ics23.LeafOp{
+			Hash:         ics23.HashOp_SHA256,
+			PrehashValue: ics23.HashOp_SHA256,
+			Length:       ics23.LengthOp_VAR_PROTO,
+			Prefix:       []byte{0},
+		},
+		Path: []*ics23.InnerOp{{
+			Hash:   ics23.HashOp_SHA256,
+			Prefix: []byte{1},
+			Suffix: tmhash.Sum([]byte("sibling store")),
+		}},
+	}}}
+	appHash, err := rootProof.Calculate()
+	if err != nil {
+		panic(err)
+	}
+	return &CommitmentProof{
+		StoreName: storeName,
+		Key:       key,
+		Proofs:    [][]byte{marshalProof(storeProof), marshalProof(rootProof)},
+	}, appHash
+}
+
+func TestVerifyMembership(t *testing.T) {
+	key, value := append(testCCMCAddress, 1), []byte("value")
+	proof, appHash := testCommitmentProof("ccm", key, value)
+	sink := common.NewZeroCopySink(nil)
+	proof.Serialization(sink)
+	decoded := new(CommitmentProof)
+	assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, proof, decoded)
+
+	assert.Nil(t, proof.VerifyMembership(appHash, value))
+	assert.NotNil(t, proof.VerifyMembership(appHash, []byte("other")))
+	assert.NotNil(t, proof.VerifyMembership(tmhash.Sum(appHash), value))
+	assert.Nil(t, proof.checkKey("ccm", testCCMCAddress))
+	assert.NotNil(t, proof.checkKey("bank", testCCMCAddress))
+	assert.NotNil(t, proof.checkKey("ccm", []byte{0x02}))
+	assert.NotNil(t, proof.checkKey("ccm", nil))
+
+	proof.Key = append(testCCMCAddress, 2)
+	assert.NotNil(t, proof.VerifyMembership(appHash, value))
+	proof.Proofs = proof.Proofs[:1]
+	assert.NotNil(t, proof.VerifyMembership(appHash, value))
+}
+
+func TestMakeDepositProposal(t *testing.T) {
+	chainID := uint64(25)
+	txParam := &scom.MakeTxParam{
+		TxHash:              []byte{1},
+		CrossChainID:        []byte{2},
+		FromContractAddress: []byte{3},
+		ToChainID:           2,
+		ToContractAddress:   []byte{4},
+		Method:              "unlock",
+		Args:                []byte{5},
+	}
+	sink := common.NewZeroCopySink(nil)
+	txParam.Serialization(sink)
+	value := sink.Bytes()
+	proof, appHash := testCommitmentProof("ccm", append(testCCMCAddress, txParam.CrossChainID...), value)
+	sink = common.NewZeroCopySink(nil)
+	proof.Serialization(sink)
+	param := &scom.EntranceParam{
+		SourceChainID: chainID,
+		Height:        100,
+		Proof:         sink.Bytes(),
+		Extra:         value,
+	}
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	service := newNative(sink.Bytes(), nil)
+	extraInfo, _ := json.Marshal(&cometbft.Context{ChainID: "cometbft-test", TrustingPeriod: 3600, StoreName: "ccm"})
+	assert.Nil(t, side_chain_manager.PutSideChain(service, &side_chain_manager.SideChain{
+		ChainId:     chainID,
+		Router:      utils.COMETBFT_ROUTER,
+		CCMCAddress: testCCMCAddress,
+		ExtraInfo:   extraInfo,
+	}))
+	handler := NewCometBFTHandler()
+
+	//header is not synced
+	_, err := handler.MakeDepositProposal(service)
+	assert.NotNil(t, err)
+
+	//wrong app hash
+	putHeader(service, chainID, &cometbft.Header{Height: 100, AppHash: tmhash.Sum(appHash)})
+	_, err = handler.MakeDepositProposal(service)
+	assert.NotNil(t, err)
+
+	putHeader(service, chainID, &cometbft.Header{Height: 100, AppHash: appHash})
+	result, err := handler.MakeDepositProposal(service)
+	assert.Nil(t, err)
+	assert.Equal(t, txParam, result)
+
+	//tx is done
+	_, err = handler.MakeDepositProposal(service)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"bytes"
+	"fmt"
+
+	ics23 "github.com/confio/ics23/go"
+	"github.com/polynetwork/poly/common"
+)
+
+//CommitmentProof proves a key value pair in a store of the cosmos multi store with ics23 proofs. The first proof
+//is the existence of the pair in the iavl store, the second is the existence of the store root in the multi store.
+type CommitmentProof struct {
+	StoreName string
+	Key       []byte
+	Proofs    [][]byte // protobuf encoded ics23 commitment proofs
+}
+
+func (this *CommitmentProof) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.StoreName)
+	sink.WriteVarBytes(this.Key)
+	sink.WriteVarUint(uint64(len(this.Proofs)))
+	for _, v := range this.Proofs {
+		sink.WriteVarBytes(v)
+	}
+}
+
+func (this *CommitmentProof) Deserialization(source *common.ZeroCopySource) error {
+	storeName, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("CommitmentProof deserialize storeName error")
+	}
+	key, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("CommitmentProof deserialize key error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("CommitmentProof deserialize proofs length error")
+	}
+	var proofs [][]byte
+	for i := uint64(0); i < n; i++ {
+		proof, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("CommitmentProof deserialize proof error")
+		}
+		proofs = append(proofs, proof)
+	}
+	this.StoreName = storeName
+	this.Key = key
+	this.Proofs = proofs
+	return nil
+}
+
+//VerifyMembership checks the key value pair is committed by app hash
+func (this *CommitmentProof) VerifyMembership(appHash, value []byte) error {
+	if len(this.Proofs) != 2 {
+		return fmt.Errorf("expect 2 proofs, got %d", len(this.Proofs))
+	}
+	storeProof, rootProof := new(ics23.CommitmentProof), new(ics23.CommitmentProof)
+	if err := storeProof.Unmarshal(this.Proofs[0]); err != nil {
+		return fmt.Errorf("unmarshal store proof error: %s", err)
+	}
+	if err := rootProof.Unmarshal(this.Proofs[1]); err != nil {
+		return fmt.Errorf("unmarshal multi store proof error: %s", err)
+	}
+	storeRoot, err := storeProof.Calculate()
+	if err != nil {
+		return fmt.Errorf("calculate store root error: %s", err)
+	}
+	if !ics23.VerifyMembership(ics23.IavlSpec, storeRoot, storeProof, this.Key, value) {
+		return fmt.Errorf("verify membership of key %x in store %s failed", this.Key, this.StoreName)
+	}
+	if !ics23.VerifyMembership(ics23.TendermintSpec, appHash, rootProof, []byte(this.StoreName), storeRoot) {
+		return fmt.Errorf("verify membership of store %s in multi store failed", this.StoreName)
+	}
+	return nil
+}
+
+//checkKey makes sure the key belongs to the cross chain manager, ccmcAddress of side chain is the key prefix of
+//cross chain txs in the store
+func (this *CommitmentProof) checkKey(storeName string, ccmcAddress []byte) error {
+	if storeName == "" || this.StoreName != storeName {
+		return fmt.Errorf("store name %s mismatch with side chain store %s", this.StoreName, storeName)
+	}
+	if len(ccmcAddress) == 0 || !bytes.HasPrefix(this.Key, ccmcAddress) {
+		return fmt.Errorf("key %x does not start with ccmc address %x", this.Key, ccmcAddress)
+	}
+	return nil
+}
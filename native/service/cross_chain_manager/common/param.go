@@ -31,8 +31,21 @@ const (
 	RECONSTRUCT_RIPPLE_TX      = "ReconstructRippleTx"
 	BLACK_CHAIN                = "BlackChain"
 	WHITE_CHAIN                = "WhiteChain"
-
-	BLACKED_CHAIN = "BlackedChain"
+	SET_RATE_LIMIT             = "SetRateLimit"
+	RESUME_RATE_LIMIT          = "ResumeRateLimit"
+	GET_RATE_LIMIT             = "GetRateLimit"
+	SET_DELAY_POLICY           = "SetDelayPolicy"
+	RELEASE_PENDING_TX         = "ReleasePendingTx"
+	CANCEL_PENDING_TX          = "CancelPendingTx"
+
+	//storage key prefixes, none of them may be a prefix of another as ids are appended to them
+	BLACKED_CHAIN    = "BlackedChain"
+	RATE_LIMIT       = "RateLimitConfig"
+	RATE_LIMIT_STATE = "RateLimitState"
+	DELAY_POLICY     = "DelayPolicy"
+	PENDING_TX       = "PendingTxItem"
+	PENDING_TX_COUNT = "PendingTxCount"
+	PENDING_TX_INDEX = "PendingTxIndex"
 )
 
 var (
@@ -317,3 +330,177 @@ func (this *BlackChainParam) Deserialization(source *common.ZeroCopySource) erro
 	this.ChainID = chainID
 	return nil
 }
+
+type SetRateLimitParam struct {
+	FromChainID uint64
+	ToChainID   uint64
+	ToContract  []byte
+	//the rate limit of the pair is removed if Limit.Limit is zero
+	Limit   *RateLimit
+	Address common.Address
+}
+
+func (this *SetRateLimitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.FromChainID)
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteVarBytes(this.ToContract)
+	this.Limit.Serialization(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SetRateLimitParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetRateLimitParam deserialize fromChainID error")
+	}
+	toChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetRateLimitParam deserialize toChainID error")
+	}
+	toContract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetRateLimitParam deserialize toContract error")
+	}
+	limit := new(RateLimit)
+	if err := limit.Deserialization(source); err != nil {
+		return fmt.Errorf("SetRateLimitParam deserialize limit error: %v", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetRateLimitParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("SetRateLimitParam, common.AddressParseFromBytes error: %v", err)
+	}
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	this.ToContract = toContract
+	this.Limit = limit
+	this.Address = addr
+	return nil
+}
+
+// RateLimitPairParam identifies the rate limited pair of ResumeRateLimit and GetRateLimit, Address is only used
+// by ResumeRateLimit
+type RateLimitPairParam struct {
+	FromChainID uint64
+	ToChainID   uint64
+	ToContract  []byte
+	Address     common.Address
+}
+
+func (this *RateLimitPairParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.FromChainID)
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteVarBytes(this.ToContract)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *RateLimitPairParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("RateLimitPairParam deserialize fromChainID error")
+	}
+	toChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("RateLimitPairParam deserialize toChainID error")
+	}
+	toContract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RateLimitPairParam deserialize toContract error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RateLimitPairParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("RateLimitPairParam, common.AddressParseFromBytes error: %v", err)
+	}
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	this.ToContract = toContract
+	this.Address = addr
+	return nil
+}
+
+type SetDelayPolicyParam struct {
+	ToChainID  uint64
+	ToContract []byte
+	//the delay policy of the contract is removed if Policy.Delay is zero
+	Policy  *DelayPolicy
+	Address common.Address
+}
+
+func (this *SetDelayPolicyParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteVarBytes(this.ToContract)
+	this.Policy.Serialization(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SetDelayPolicyParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetDelayPolicyParam deserialize toChainID error")
+	}
+	toContract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetDelayPolicyParam deserialize toContract error")
+	}
+	policy := new(DelayPolicy)
+	if err := policy.Deserialization(source); err != nil {
+		return fmt.Errorf("SetDelayPolicyParam deserialize policy error: %v", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetDelayPolicyParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("SetDelayPolicyParam, common.AddressParseFromBytes error: %v", err)
+	}
+	this.ToChainID = toChainID
+	this.ToContract = toContract
+	this.Policy = policy
+	this.Address = addr
+	return nil
+}
+
+//PendingTxParam identifies the pending tx of ReleasePendingTx and CancelPendingTx by target chain and poly tx hash,
+//Address is only used by CancelPendingTx
+type PendingTxParam struct {
+	ToChainID uint64
+	TxHash    []byte
+	Address   common.Address
+}
+
+func (this *PendingTxParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteVarBytes(this.TxHash)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *PendingTxParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("PendingTxParam deserialize toChainID error")
+	}
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingTxParam deserialize txHash error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingTxParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("PendingTxParam, common.AddressParseFromBytes error: %v", err)
+	}
+	this.ToChainID = toChainID
+	this.TxHash = txHash
+	this.Address = addr
+	return nil
+}
@@ -17,6 +17,7 @@
 package common
 
 import (
+	"strings"
 	"testing"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -43,3 +44,15 @@ func TestMakeTxParamWithSender(t *testing.T) {
 
 	assert.Equal(t, value, decoded)
 }
+
+func TestStorageKeyPrefixes(t *testing.T) {
+	prefixes := []string{BLACKED_CHAIN, RATE_LIMIT, RATE_LIMIT_STATE, DELAY_POLICY, PENDING_TX, PENDING_TX_COUNT, PENDING_TX_INDEX}
+	for _, prefix := range prefixes {
+		for _, other := range prefixes {
+			if prefix != other {
+				assert.False(t, strings.HasPrefix(other, prefix), "%s is a prefix of %s", prefix, other)
+			}
+		}
+	}
+	assert.NotEqual(t, RateLimitKey(2, 3, []byte{1}), RateLimitStateKey(2, 3, []byte{1}))
+}
@@ -0,0 +1,433 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	MAX_PENDING_TX = 10000 //Most pending txs, txs delayed more are refused until pending ones are released or cancelled
+
+	NOTIFY_PENDING_TX = "pendingTx"
+	NOTIFY_RELEASE_TX = "releasePendingTx"
+	NOTIFY_CANCEL_TX  = "cancelPendingTx"
+)
+
+//DelayPolicy keeps cross chain txs to a target contract pending for Delay poly blocks before they are provable.
+//Only txs with lock proxy amount not less than Threshold are delayed, or all txs if Threshold is zero. Txs whose
+//args are not in lock proxy format are always delayed.
+type DelayPolicy struct {
+	Delay     uint32
+	Threshold *big.Int
+}
+
+func (this *DelayPolicy) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Delay)
+	sink.WriteVarBytes(this.Threshold.Bytes())
+}
+
+func (this *DelayPolicy) Deserialization(source *common.ZeroCopySource) error {
+	delay, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("DelayPolicy deserialize delay error")
+	}
+	threshold, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DelayPolicy deserialize threshold error")
+	}
+	this.Delay = delay
+	this.Threshold = new(big.Int).SetBytes(threshold)
+	return nil
+}
+
+//IsDelayed return whether the cross chain tx with args is delayed by the policy
+func (this *DelayPolicy) IsDelayed(args []byte) bool {
+	if this.Threshold.Sign() <= 0 {
+		return true
+	}
+	amount, ok := lockProxyAmount(args)
+	return !ok || amount.Cmp(this.Threshold) >= 0
+}
+
+//lockProxyAmount parses the amount of lock proxy args: to asset, to address and a 32 bytes little endian amount
+func lockProxyAmount(args []byte) (*big.Int, bool) {
+	source := common.NewZeroCopySource(args)
+	if _, eof := source.NextVarBytes(); eof {
+		return nil, false
+	}
+	if _, eof := source.NextVarBytes(); eof {
+		return nil, false
+	}
+	raw, eof := source.NextBytes(32)
+	if eof {
+		return nil, false
+	}
+	amount := make([]byte, len(raw))
+	for i, b := range raw {
+		amount[len(raw)-1-i] = b
+	}
+	return new(big.Int).SetBytes(amount), true
+}
+
+//PendingTx is a delayed cross chain tx, Value is the serialized ToMerkleValue. The source chain header of ProofHeight
+//the tx is proved with is kept from pruning while the tx is pending, ProofHeight is 0 if the tx is not proved with header.
+//Index is the position of the tx in the pending tx index
+type PendingTx struct {
+	ReleaseHeight uint32
+	Value         []byte
+	FromChainID   uint64
+	ProofHeight   uint32
+	Index         uint64
+}
+
+func (this *PendingTx) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.ReleaseHeight)
+	sink.WriteVarBytes(this.Value)
+	sink.WriteUint64(this.FromChainID)
+	sink.WriteUint32(this.ProofHeight)
+	sink.WriteUint64(this.Index)
+}
+
+func (this *PendingTx) Deserialization(source *common.ZeroCopySource) error {
+	releaseHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize releaseHeight error")
+	}
+	value, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize value error")
+	}
+	fromChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize fromChainID error")
+	}
+	proofHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize proofHeight error")
+	}
+	index, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize index error")
+	}
+	this.ReleaseHeight = releaseHeight
+	this.Value = value
+	this.FromChainID = fromChainID
+	this.ProofHeight = proofHeight
+	this.Index = index
+	return nil
+}
+
+type PendingTxID struct {
+	ToChainID uint64
+	TxHash    []byte
+}
+
+func (this *PendingTxID) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteVarBytes(this.TxHash)
+}
+
+func (this *PendingTxID) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("PendingTxID deserialize toChainID error")
+	}
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingTxID deserialize txHash error")
+	}
+	this.ToChainID = toChainID
+	this.TxHash = txHash
+	return nil
+}
+
+//DelayPolicyKey return the storage key of the delay policy of target contract without contract address
+func DelayPolicyKey(toChainID uint64, toContract []byte) []byte {
+	key := append([]byte(DELAY_POLICY), utils.GetUint64Bytes(toChainID)...)
+	return append(key, toContract...)
+}
+
+//PendingTxKey return the storage key of the pending tx without contract address
+func PendingTxKey(toChainID uint64, txHash []byte) []byte {
+	key := append([]byte(PENDING_TX), utils.GetUint64Bytes(toChainID)...)
+	return append(key, txHash...)
+}
+
+//PendingTxCountKey return the storage key of the pending tx count without contract address
+func PendingTxCountKey() []byte {
+	return []byte(PENDING_TX_COUNT)
+}
+
+//PendingTxIndexKey return the storage key of the id of the pending tx at index without contract address, pending txs
+//are indexed from 0 to count-1 in no particular order
+func PendingTxIndexKey(index uint64) []byte {
+	return append([]byte(PENDING_TX_INDEX), utils.GetUint64Bytes(index)...)
+}
+
+func getRawValue(native *native.NativeService, key []byte) ([]byte, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, key))
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, nil
+	}
+	return states.GetValueFromRawStorageItem(store)
+}
+
+func putRawValue(native *native.NativeService, key []byte, value []byte) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, key), states.GenRawStorageItem(value))
+}
+
+//GetDelayPolicy return the delay policy of target contract, nil if txs to the contract are not delayed
+func GetDelayPolicy(native *native.NativeService, toChainID uint64, toContract []byte) (*DelayPolicy, error) {
+	value, err := getRawValue(native, DelayPolicyKey(toChainID, toContract))
+	if err != nil {
+		return nil, fmt.Errorf("GetDelayPolicy, get delay policy error: %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	policy := new(DelayPolicy)
+	if err := policy.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetDelayPolicy, deserialize delay policy error: %v", err)
+	}
+	return policy, nil
+}
+
+func PutDelayPolicy(native *native.NativeService, toChainID uint64, toContract []byte, policy *DelayPolicy) {
+	sink := common.NewZeroCopySink(nil)
+	policy.Serialization(sink)
+	putRawValue(native, DelayPolicyKey(toChainID, toContract), sink.Bytes())
+}
+
+func RemoveDelayPolicy(native *native.NativeService, toChainID uint64, toContract []byte) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, DelayPolicyKey(toChainID, toContract)))
+}
+
+//GetPendingTx return the pending tx, nil if it is not pending
+func GetPendingTx(native *native.NativeService, toChainID uint64, txHash []byte) (*PendingTx, error) {
+	value, err := getRawValue(native, PendingTxKey(toChainID, txHash))
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingTx, get pending tx error: %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	pending := new(PendingTx)
+	if err := pending.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetPendingTx, deserialize pending tx error: %v", err)
+	}
+	return pending, nil
+}
+
+//GetPendingTxCount return the number of pending txs
+func GetPendingTxCount(native *native.NativeService) (uint64, error) {
+	value, err := getRawValue(native, PendingTxCountKey())
+	if err != nil {
+		return 0, fmt.Errorf("GetPendingTxCount, get pending tx count error: %v", err)
+	}
+	return utils.GetBytesUint64(value), nil
+}
+
+//GetPendingTxID return the id of the pending tx at index, nil if index is out of range
+func GetPendingTxID(native *native.NativeService, index uint64) (*PendingTxID, error) {
+	value, err := getRawValue(native, PendingTxIndexKey(index))
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingTxID, get pending tx id error: %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	id := new(PendingTxID)
+	if err := id.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetPendingTxID, deserialize pending tx id error: %v", err)
+	}
+	return id, nil
+}
+
+func putPendingTxID(native *native.NativeService, index uint64, id *PendingTxID) {
+	sink := common.NewZeroCopySink(nil)
+	id.Serialization(sink)
+	putRawValue(native, PendingTxIndexKey(index), sink.Bytes())
+}
+
+func putPendingTx(native *native.NativeService, toChainID uint64, txHash []byte, pending *PendingTx) error {
+	count, err := GetPendingTxCount(native)
+	if err != nil {
+		return err
+	}
+	if count >= MAX_PENDING_TX {
+		return fmt.Errorf("putPendingTx, pending txs reach the limit %d", MAX_PENDING_TX)
+	}
+	pending.Index = count
+	putPendingTxID(native, count, &PendingTxID{ToChainID: toChainID, TxHash: txHash})
+	putRawValue(native, PendingTxCountKey(), utils.GetUint64Bytes(count+1))
+
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+	putRawValue(native, PendingTxKey(toChainID, txHash), sink.Bytes())
+	return nil
+}
+
+//deletePendingTx delete the pending tx, the last indexed tx is moved to its index
+func deletePendingTx(native *native.NativeService, toChainID uint64, txHash []byte, pending *PendingTx) error {
+	if pending.ProofHeight != 0 {
+		if err := hscommon.ReleaseHeader(native, pending.FromChainID, uint64(pending.ProofHeight)); err != nil {
+			return err
+		}
+	}
+	count, err := GetPendingTxCount(native)
+	if err != nil {
+		return err
+	}
+	if count == 0 || pending.Index >= count {
+		return fmt.Errorf("deletePendingTx, invalid index %d of %d pending txs", pending.Index, count)
+	}
+	last := count - 1
+	if pending.Index != last {
+		id, err := GetPendingTxID(native, last)
+		if err != nil {
+			return err
+		}
+		if id == nil {
+			return fmt.Errorf("deletePendingTx, pending tx of index %d not found", last)
+		}
+		moved, err := GetPendingTx(native, id.ToChainID, id.TxHash)
+		if err != nil {
+			return err
+		}
+		if moved == nil {
+			return fmt.Errorf("deletePendingTx, pending tx %x to chain %d not found", id.TxHash, id.ToChainID)
+		}
+		moved.Index = pending.Index
+		sink := common.NewZeroCopySink(nil)
+		moved.Serialization(sink)
+		putRawValue(native, PendingTxKey(id.ToChainID, id.TxHash), sink.Bytes())
+		putPendingTxID(native, pending.Index, id)
+	}
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, PendingTxIndexKey(last)))
+	if last == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, PendingTxCountKey()))
+	} else {
+		putRawValue(native, PendingTxCountKey(), utils.GetUint64Bytes(last))
+	}
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, PendingTxKey(toChainID, txHash)))
+	return nil
+}
+
+//delayTransaction puts the cross chain tx into the pending queue if the policy of its target contract delays it,
+//the source chain header of proofHeight is referenced until the tx is released or cancelled
+func delayTransaction(service *native.NativeService, merkleValue *ToMerkleValue, value []byte, proofHeight uint32) (bool, error) {
+	params := merkleValue.MakeTxParam
+	policy, err := GetDelayPolicy(service, params.ToChainID, params.ToContractAddress)
+	if err != nil {
+		return false, err
+	}
+	if policy == nil || !policy.IsDelayed(params.Args) {
+		return false, nil
+	}
+	pending := &PendingTx{
+		ReleaseHeight: service.GetHeight() + policy.Delay,
+		Value:         value,
+		FromChainID:   merkleValue.FromChainID,
+		ProofHeight:   proofHeight,
+	}
+	if proofHeight != 0 {
+		if err := hscommon.ReferenceHeader(service, merkleValue.FromChainID, uint64(proofHeight)); err != nil {
+			return false, err
+		}
+	}
+	if err := putPendingTx(service, params.ToChainID, merkleValue.TxHash, pending); err != nil {
+		return false, err
+	}
+	if config.DefConfig.Common.EnableEventLog {
+		service.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{NOTIFY_PENDING_TX, merkleValue.FromChainID, params.ToChainID,
+					hex.EncodeToString(params.TxHash), hex.EncodeToString(merkleValue.TxHash), pending.ReleaseHeight},
+			})
+	}
+	return true, nil
+}
+
+//ReleasePendingTx makes the pending tx provable once its release height is reached
+func ReleasePendingTx(service *native.NativeService, toChainID uint64, txHash []byte) error {
+	pending, err := GetPendingTx(service, toChainID, txHash)
+	if err != nil {
+		return fmt.Errorf("ReleasePendingTx, %v", err)
+	}
+	if pending == nil {
+		return fmt.Errorf("ReleasePendingTx, tx %x to chain %d is not pending", txHash, toChainID)
+	}
+	if service.GetHeight() < pending.ReleaseHeight {
+		return fmt.Errorf("ReleasePendingTx, tx %x to chain %d is pending until height %d", txHash, toChainID, pending.ReleaseHeight)
+	}
+	merkleValue := new(ToMerkleValue)
+	if err := merkleValue.Deserialization(common.NewZeroCopySource(pending.Value)); err != nil {
+		return fmt.Errorf("ReleasePendingTx, deserialize merkle value error: %v", err)
+	}
+	if err := deletePendingTx(service, toChainID, txHash, pending); err != nil {
+		return fmt.Errorf("ReleasePendingTx, %v", err)
+	}
+	if err := storeRequest(service, merkleValue, pending.Value); err != nil {
+		return fmt.Errorf("ReleasePendingTx, %v", err)
+	}
+	if config.DefConfig.Common.EnableEventLog {
+		service.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{NOTIFY_RELEASE_TX, toChainID, hex.EncodeToString(txHash)},
+			})
+	}
+	return nil
+}
+
+//CancelPendingTx drops the pending tx before it is released
+func CancelPendingTx(service *native.NativeService, toChainID uint64, txHash []byte) error {
+	pending, err := GetPendingTx(service, toChainID, txHash)
+	if err != nil {
+		return fmt.Errorf("CancelPendingTx, %v", err)
+	}
+	if pending == nil {
+		return fmt.Errorf("CancelPendingTx, tx %x to chain %d is not pending", txHash, toChainID)
+	}
+	if err := deletePendingTx(service, toChainID, txHash, pending); err != nil {
+		return fmt.Errorf("CancelPendingTx, %v", err)
+	}
+	if config.DefConfig.Common.EnableEventLog {
+		service.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{NOTIFY_CANCEL_TX, toChainID, hex.EncodeToString(txHash)},
+			})
+	}
+	return nil
+}
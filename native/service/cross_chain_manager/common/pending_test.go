@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/native"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func lockProxyArgs(amount int64) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte("asset"))
+	sink.WriteVarBytes([]byte("to address"))
+	raw := big.NewInt(amount).Bytes()
+	value := make([]byte, 32)
+	for i, b := range raw {
+		value[len(raw)-1-i] = b
+	}
+	sink.WriteBytes(value)
+	return sink.Bytes()
+}
+
+func TestDelayPolicy(t *testing.T) {
+	policy := &DelayPolicy{Delay: 10, Threshold: big.NewInt(1000)}
+	assert.False(t, policy.IsDelayed(lockProxyArgs(999)))
+	assert.True(t, policy.IsDelayed(lockProxyArgs(1000)))
+	assert.True(t, policy.IsDelayed(lockProxyArgs(70000)))
+	assert.True(t, policy.IsDelayed([]byte("not lock proxy args")))
+
+	policy.Threshold = new(big.Int)
+	assert.True(t, policy.IsDelayed(lockProxyArgs(1)))
+}
+
+func TestPendingTx(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	toContract := []byte{1, 2, 3}
+	PutDelayPolicy(newNativeAt(db, 1), 3, toContract, &DelayPolicy{Delay: 10, Threshold: big.NewInt(1000)})
+
+	requestKey := func(txHash []byte) []byte {
+		return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(REQUEST), utils.GetUint64Bytes(3), txHash)
+	}
+	makeTx := func(ns *native.NativeService, amount int64) []byte {
+		param := &MakeTxParam{
+			TxHash:              []byte{9},
+			CrossChainID:        []byte{1},
+			FromContractAddress: []byte{2},
+			ToChainID:           3,
+			ToContractAddress:   toContract,
+			Method:              "unlock",
+			Args:                lockProxyArgs(amount),
+		}
+		assert.Nil(t, MakeTransaction(ns, param, 2))
+		txHash := ns.GetTx().Hash()
+		return txHash.ToArray()
+	}
+
+	// small transfer is provable at once
+	ns := newNativeAt(db, 1)
+	txHash := makeTx(ns, 10)
+	assert.Equal(t, 1, len(ns.GetCrossHashes()))
+	value, err := db.Get(requestKey(txHash))
+	assert.Nil(t, err)
+	assert.NotNil(t, value)
+	db.Delete(requestKey(txHash))
+
+	ns = newNativeAt(db, 1)
+	txHash = makeTx(ns, 5000)
+	assert.Equal(t, 0, len(ns.GetCrossHashes()))
+	value, err = db.Get(requestKey(txHash))
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	pending, err := GetPendingTx(ns, 3, txHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(11), pending.ReleaseHeight)
+	count, err := GetPendingTxCount(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), count)
+	id, err := GetPendingTxID(ns, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, &PendingTxID{ToChainID: 3, TxHash: txHash}, id)
+
+	assert.NotNil(t, ReleasePendingTx(newNativeAt(db, 10), 3, txHash))
+	ns = newNativeAt(db, 11)
+	assert.Nil(t, ReleasePendingTx(ns, 3, txHash))
+	assert.Equal(t, 1, len(ns.GetCrossHashes()))
+	value, err = db.Get(requestKey(txHash))
+	assert.Nil(t, err)
+	assert.NotNil(t, value)
+	count, err = GetPendingTxCount(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), count)
+	id, err = GetPendingTxID(ns, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+	assert.NotNil(t, ReleasePendingTx(newNativeAt(db, 12), 3, txHash))
+}
+
+func TestCancelPendingTx(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	toContract := []byte{1, 2, 3}
+	PutDelayPolicy(newNativeAt(db, 1), 3, toContract, &DelayPolicy{Delay: 10, Threshold: new(big.Int)})
+
+	ns := newNativeAt(db, 1)
+	param := &MakeTxParam{ToChainID: 3, ToContractAddress: toContract, Method: "unlock"}
+	assert.Nil(t, MakeTransaction(ns, param, 2))
+	txHash := ns.GetTx().Hash()
+
+	assert.Nil(t, CancelPendingTx(newNativeAt(db, 5), 3, txHash[:]))
+	pending, err := GetPendingTx(ns, 3, txHash[:])
+	assert.Nil(t, err)
+	assert.Nil(t, pending)
+	assert.NotNil(t, ReleasePendingTx(newNativeAt(db, 11), 3, txHash[:]))
+}
+
+func TestPendingTxHeaderReference(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	toContract := []byte{1, 2, 3}
+	PutDelayPolicy(newNativeAt(db, 1), 3, toContract, &DelayPolicy{Delay: 10, Threshold: new(big.Int)})
+	ns := newNativeAt(db, 1)
+	hscommon.PutPrunedHeight(ns, 2, 200)
+
+	param := &MakeTxParam{ToChainID: 3, ToContractAddress: toContract, Method: "unlock"}
+	assert.Nil(t, MakeProvedTransaction(ns, param, 2, 100))
+	txHash := ns.GetTx().Hash()
+	pending, err := GetPendingTx(ns, 3, txHash[:])
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), pending.FromChainID)
+	assert.Equal(t, uint32(100), pending.ProofHeight)
+	// the proof header is kept while the tx is pending
+	assert.Nil(t, hscommon.CheckHeaderRetained(ns, 2, 100))
+
+	assert.Nil(t, CancelPendingTx(newNativeAt(db, 5), 3, txHash[:]))
+	assert.NotNil(t, hscommon.CheckHeaderRetained(ns, 2, 100))
+}
+
+func TestPendingTxIndex(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns := newNativeAt(db, 1)
+	pendingIDs := func() map[string]uint64 {
+		count, err := GetPendingTxCount(ns)
+		assert.Nil(t, err)
+		ids := make(map[string]uint64)
+		for index := uint64(0); index < count; index++ {
+			id, err := GetPendingTxID(ns, index)
+			assert.Nil(t, err)
+			pending, err := GetPendingTx(ns, id.ToChainID, id.TxHash)
+			assert.Nil(t, err)
+			assert.Equal(t, index, pending.Index)
+			ids[string(id.TxHash)] = id.ToChainID
+		}
+		return ids
+	}
+	for i := byte(0); i < 4; i++ {
+		assert.Nil(t, putPendingTx(ns, 3, []byte{i}, &PendingTx{ReleaseHeight: 10}))
+	}
+	assert.Equal(t, 4, len(pendingIDs()))
+
+	for _, i := range []byte{1, 3, 0} {
+		pending, err := GetPendingTx(ns, 3, []byte{i})
+		assert.Nil(t, err)
+		assert.Nil(t, deletePendingTx(ns, 3, []byte{i}, pending))
+	}
+	assert.Equal(t, map[string]uint64{string([]byte{2}): 3}, pendingIDs())
+
+	// the pending txs are capped
+	putRawValue(ns, PendingTxCountKey(), utils.GetUint64Bytes(MAX_PENDING_TX))
+	assert.NotNil(t, putPendingTx(ns, 3, []byte{5}, &PendingTx{ReleaseHeight: 10}))
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//RateLimit allows at most Limit cross chain txs of a (from chain, to chain, to contract) pair in every Window
+//blocks. With AutoPause, the pair is paused once the budget of a window is used up, until it is resumed by governance.
+type RateLimit struct {
+	Window    uint32
+	Limit     uint64
+	AutoPause bool
+}
+
+func (this *RateLimit) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Window)
+	sink.WriteUint64(this.Limit)
+	sink.WriteBool(this.AutoPause)
+}
+
+func (this *RateLimit) Deserialization(source *common.ZeroCopySource) error {
+	window, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("RateLimit deserialize window error")
+	}
+	limit, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("RateLimit deserialize limit error")
+	}
+	autoPause, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("RateLimit deserialize autoPause error")
+	}
+	this.Window = window
+	this.Limit = limit
+	this.AutoPause = autoPause
+	return nil
+}
+
+//RateLimitState counts the cross chain txs of a pair in the window starting at WindowStart
+type RateLimitState struct {
+	WindowStart uint32
+	Count       uint64
+	Paused      bool
+}
+
+func (this *RateLimitState) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.WindowStart)
+	sink.WriteUint64(this.Count)
+	sink.WriteBool(this.Paused)
+}
+
+func (this *RateLimitState) Deserialization(source *common.ZeroCopySource) error {
+	windowStart, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("RateLimitState deserialize windowStart error")
+	}
+	count, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("RateLimitState deserialize count error")
+	}
+	paused, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("RateLimitState deserialize paused error")
+	}
+	this.WindowStart = windowStart
+	this.Count = count
+	this.Paused = paused
+	return nil
+}
+
+//RateLimitBudget is the remaining budget of a rate limited pair at Height
+type RateLimitBudget struct {
+	Window    uint32
+	Limit     uint64
+	AutoPause bool
+	Height    uint32
+	Remaining uint64
+	//the budget is reset at ResetHeight unless Paused
+	ResetHeight uint32
+	Paused      bool
+}
+
+func (this *RateLimitBudget) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Window)
+	sink.WriteUint64(this.Limit)
+	sink.WriteBool(this.AutoPause)
+	sink.WriteUint32(this.Height)
+	sink.WriteUint64(this.Remaining)
+	sink.WriteUint32(this.ResetHeight)
+	sink.WriteBool(this.Paused)
+}
+
+//GetRateLimitBudget return the budget of the pair with limit and state at height
+func GetRateLimitBudget(limit *RateLimit, state *RateLimitState, height uint32) *RateLimitBudget {
+	windowStart := height - height%limit.Window
+	budget := &RateLimitBudget{
+		Window:      limit.Window,
+		Limit:       limit.Limit,
+		AutoPause:   limit.AutoPause,
+		Height:      height,
+		Remaining:   limit.Limit,
+		ResetHeight: windowStart + limit.Window,
+		Paused:      state.Paused,
+	}
+	if state.WindowStart == windowStart {
+		if state.Count >= limit.Limit {
+			budget.Remaining = 0
+		} else {
+			budget.Remaining = limit.Limit - state.Count
+		}
+	}
+	if budget.Paused {
+		budget.Remaining = 0
+	}
+	return budget
+}
+
+//RateLimitKey return the storage key of the rate limit of pair without contract address
+func RateLimitKey(fromChainID, toChainID uint64, toContract []byte) []byte {
+	return rateLimitKey(RATE_LIMIT, fromChainID, toChainID, toContract)
+}
+
+//RateLimitStateKey return the storage key of the rate limit state of pair without contract address
+func RateLimitStateKey(fromChainID, toChainID uint64, toContract []byte) []byte {
+	return rateLimitKey(RATE_LIMIT_STATE, fromChainID, toChainID, toContract)
+}
+
+func rateLimitKey(prefix string, fromChainID, toChainID uint64, toContract []byte) []byte {
+	key := append([]byte(prefix), utils.GetUint64Bytes(fromChainID)...)
+	key = append(key, utils.GetUint64Bytes(toChainID)...)
+	return append(key, toContract...)
+}
+
+//GetRateLimit return the rate limit of pair, nil if the pair is not limited
+func GetRateLimit(native *native.NativeService, fromChainID, toChainID uint64, toContract []byte) (*RateLimit, error) {
+	contract := utils.CrossChainManagerContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, RateLimitKey(fromChainID, toChainID, toContract)))
+	if err != nil {
+		return nil, fmt.Errorf("GetRateLimit, get rate limit store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := states.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetRateLimit, deserialize from raw storage item err:%v", err)
+	}
+	limit := new(RateLimit)
+	if err := limit.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetRateLimit, deserialize rate limit error: %v", err)
+	}
+	return limit, nil
+}
+
+func PutRateLimit(native *native.NativeService, fromChainID, toChainID uint64, toContract []byte, limit *RateLimit) {
+	contract := utils.CrossChainManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	limit.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, RateLimitKey(fromChainID, toChainID, toContract)),
+		states.GenRawStorageItem(sink.Bytes()))
+}
+
+//RemoveRateLimit removes the rate limit and state of pair
+func RemoveRateLimit(native *native.NativeService, fromChainID, toChainID uint64, toContract []byte) {
+	contract := utils.CrossChainManagerContractAddress
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, RateLimitKey(fromChainID, toChainID, toContract)))
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, RateLimitStateKey(fromChainID, toChainID, toContract)))
+}
+
+func GetRateLimitState(native *native.NativeService, fromChainID, toChainID uint64, toContract []byte) (*RateLimitState, error) {
+	contract := utils.CrossChainManagerContractAddress
+	state := new(RateLimitState)
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, RateLimitStateKey(fromChainID, toChainID, toContract)))
+	if err != nil {
+		return nil, fmt.Errorf("GetRateLimitState, get rate limit state store error: %v", err)
+	}
+	if store == nil {
+		return state, nil
+	}
+	value, err := states.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetRateLimitState, deserialize from raw storage item err:%v", err)
+	}
+	if err := state.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetRateLimitState, deserialize rate limit state error: %v", err)
+	}
+	return state, nil
+}
+
+func PutRateLimitState(native *native.NativeService, fromChainID, toChainID uint64, toContract []byte, state *RateLimitState) {
+	contract := utils.CrossChainManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	state.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, RateLimitStateKey(fromChainID, toChainID, toContract)),
+		states.GenRawStorageItem(sink.Bytes()))
+}
+
+//CheckRateLimit counts a cross chain tx of pair against its rate limit, it fails if the pair is paused or the
+//budget of current window is used up. The pair is paused with a notify once the budget is used up with AutoPause.
+func CheckRateLimit(native *native.NativeService, fromChainID, toChainID uint64, toContract []byte) error {
+	limit, err := GetRateLimit(native, fromChainID, toChainID, toContract)
+	if err != nil {
+		return fmt.Errorf("CheckRateLimit, %v", err)
+	}
+	if limit == nil {
+		return nil
+	}
+	state, err := GetRateLimitState(native, fromChainID, toChainID, toContract)
+	if err != nil {
+		return fmt.Errorf("CheckRateLimit, %v", err)
+	}
+	if state.Paused {
+		return fmt.Errorf("CheckRateLimit, cross chain from %d to %d contract %x is paused", fromChainID, toChainID, toContract)
+	}
+	height := native.GetHeight()
+	windowStart := height - height%limit.Window
+	if state.WindowStart != windowStart {
+		state.WindowStart = windowStart
+		state.Count = 0
+	}
+	if state.Count >= limit.Limit {
+		return fmt.Errorf("CheckRateLimit, cross chain from %d to %d contract %x exceeds %d txs in %d blocks",
+			fromChainID, toChainID, toContract, limit.Limit, limit.Window)
+	}
+	state.Count++
+	if state.Count >= limit.Limit && limit.AutoPause {
+		state.Paused = true
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{"rateLimitPaused", fromChainID, toChainID, hex.EncodeToString(toContract), height},
+			})
+	}
+	PutRateLimitState(native, fromChainID, toChainID, toContract, state)
+	return nil
+}
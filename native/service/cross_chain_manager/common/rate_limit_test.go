@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNativeAt(db *storage.CacheDB, height uint32) *native.NativeService {
+	ns, _ := native.NewNativeService(db, new(types.Transaction), 0, height, common.Uint256{}, 0, nil, false)
+	return ns
+}
+
+func TestCheckRateLimit(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	toContract := []byte{1, 2, 3}
+
+	// pair without rate limit is not counted
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 1), 2, 3, toContract))
+
+	PutRateLimit(newNativeAt(db, 1), 2, 3, toContract, &RateLimit{Window: 10, Limit: 2})
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 11), 2, 3, toContract))
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 12), 2, 3, toContract))
+	assert.NotNil(t, CheckRateLimit(newNativeAt(db, 19), 2, 3, toContract))
+	// other contract of the chain pair is not limited
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 19), 2, 3, []byte{4}))
+
+	limit, err := GetRateLimit(newNativeAt(db, 19), 2, 3, toContract)
+	assert.Nil(t, err)
+	state, err := GetRateLimitState(newNativeAt(db, 19), 2, 3, toContract)
+	assert.Nil(t, err)
+	budget := GetRateLimitBudget(limit, state, 19)
+	assert.Equal(t, uint64(0), budget.Remaining)
+	assert.Equal(t, uint32(20), budget.ResetHeight)
+	assert.Equal(t, uint64(2), GetRateLimitBudget(limit, state, 20).Remaining)
+
+	// budget is reset in a new window
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 20), 2, 3, toContract))
+
+	RemoveRateLimit(newNativeAt(db, 20), 2, 3, toContract)
+	limit, err = GetRateLimit(newNativeAt(db, 20), 2, 3, toContract)
+	assert.Nil(t, err)
+	assert.Nil(t, limit)
+}
+
+func TestCheckRateLimitAutoPause(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	toContract := []byte{1, 2, 3}
+
+	PutRateLimit(newNativeAt(db, 1), 2, 3, toContract, &RateLimit{Window: 10, Limit: 2, AutoPause: true})
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 1), 2, 3, toContract))
+	ns := newNativeAt(db, 2)
+	assert.Nil(t, CheckRateLimit(ns, 2, 3, toContract))
+	assert.Equal(t, 1, len(ns.GetNotify()))
+	assert.Equal(t, "rateLimitPaused", ns.GetNotify()[0].States.([]interface{})[0])
+
+	// paused pair is not resumed by a new window
+	assert.NotNil(t, CheckRateLimit(newNativeAt(db, 30), 2, 3, toContract))
+	state, err := GetRateLimitState(newNativeAt(db, 30), 2, 3, toContract)
+	assert.Nil(t, err)
+	assert.True(t, state.Paused)
+
+	PutRateLimitState(newNativeAt(db, 30), 2, 3, toContract, &RateLimitState{WindowStart: 30})
+	assert.Nil(t, CheckRateLimit(newNativeAt(db, 30), 2, 3, toContract))
+}
+
+func TestSetRateLimitParam(t *testing.T) {
+	param := &SetRateLimitParam{
+		FromChainID: 2,
+		ToChainID:   3,
+		ToContract:  []byte{1, 2, 3},
+		Limit:       &RateLimit{Window: 100, Limit: 50, AutoPause: true},
+		Address:     common.Address{1},
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	p := new(SetRateLimitParam)
+	assert.Nil(t, p.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, param, p)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type ChainHandlerInfo struct {
+	Router      uint64
+	NewHandler  func() ChainHandler
+	StartBlocks utils.RouterStartBlocks // nil if the router is supported from genesis
+	// MakeTransaction replaces the default way of storing the cross chain request when the router is the target chain
+	MakeTransaction func(service *native.NativeService, param *MakeTxParam, fromChainID uint64) error
+	// AllowEmptyProposal means MakeDepositProposal returns a nil param while the deposit is still being collected
+	AllowEmptyProposal bool
+}
+
+var chainHandlers = make(map[uint64]*ChainHandlerInfo)
+
+//RegisterChainHandler register the cross chain handler of a router, it should be called in init of the chain package
+func RegisterChainHandler(info *ChainHandlerInfo) {
+	if _, present := chainHandlers[info.Router]; present {
+		panic(fmt.Sprintf("cross chain handler of router %d registered twice", info.Router))
+	}
+	chainHandlers[info.Router] = info
+}
+
+func GetChainHandlerInfo(router uint64) (*ChainHandlerInfo, error) {
+	info, present := chainHandlers[router]
+	if !present {
+		return nil, fmt.Errorf("not a supported router:%d", router)
+	}
+	return info, nil
+}
+
+//ChainRouters return all registered routers in ascending order
+func ChainRouters() []uint64 {
+	routers := make([]uint64, 0, len(chainHandlers))
+	for router := range chainHandlers {
+		routers = append(routers, router)
+	}
+	sort.Slice(routers, func(i, j int) bool { return routers[i] < routers[j] })
+	return routers
+}
@@ -17,9 +17,11 @@
 package common
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strings"
 
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
@@ -31,6 +33,54 @@ func Replace0x(s string) string {
 	return strings.Replace(strings.ToLower(s), "0x", "", 1)
 }
 
+//MakeTransaction stores the request to target chain and puts the merkle value for the cross states proof, or puts
+//it into the pending queue if the delay policy of the target contract applies
+func MakeTransaction(service *native.NativeService, params *MakeTxParam, fromChainID uint64) error {
+	return MakeProvedTransaction(service, params, fromChainID, 0)
+}
+
+//MakeProvedTransaction is MakeTransaction of the tx proved with the source chain header of proofHeight
+func MakeProvedTransaction(service *native.NativeService, params *MakeTxParam, fromChainID uint64, proofHeight uint32) error {
+	txHash := service.GetTx().Hash()
+	merkleValue := &ToMerkleValue{
+		TxHash:      txHash.ToArray(),
+		FromChainID: fromChainID,
+		MakeTxParam: params,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	merkleValue.Serialization(sink)
+	delayed, err := delayTransaction(service, merkleValue, sink.Bytes(), proofHeight)
+	if err != nil {
+		return fmt.Errorf("MakeTransaction, delayTransaction error: %v", err)
+	}
+	if delayed {
+		return nil
+	}
+	return storeRequest(service, merkleValue, sink.Bytes())
+}
+
+//storeRequest stores the cross chain request and puts it into the cross state merkle tree to be provable
+func storeRequest(service *native.NativeService, merkleValue *ToMerkleValue, value []byte) error {
+	params := merkleValue.MakeTxParam
+	err := PutRequest(service, merkleValue.TxHash, params.ToChainID, value)
+	if err != nil {
+		return fmt.Errorf("MakeTransaction, putRequest error:%s", err)
+	}
+	service.PutMerkleVal(value)
+	chainIDBytes := utils.GetUint64Bytes(params.ToChainID)
+	key := hex.EncodeToString(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(REQUEST), chainIDBytes, merkleValue.TxHash))
+	NotifyMakeProof(service, merkleValue.FromChainID, params.ToChainID, hex.EncodeToString(params.TxHash), key)
+	return nil
+}
+
+func PutRequest(native *native.NativeService, txHash []byte, chainID uint64, request []byte) error {
+	contract := utils.CrossChainManagerContractAddress
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	utils.PutBytes(native, utils.ConcatKey(contract, []byte(REQUEST), chainIDBytes, txHash), request)
+	return nil
+}
+
 func NotifyMakeProof(native *native.NativeService, fromChainID, toChainID uint64, txHash string, key string) {
 	if !config.DefConfig.Common.EnableEventLog {
 		return
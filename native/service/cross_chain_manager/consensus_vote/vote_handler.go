@@ -31,6 +31,14 @@ import (
 type VoteHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:             utils.VOTE_ROUTER,
+		NewHandler:         func() scom.ChainHandler { return NewVoteHandler() },
+		AllowEmptyProposal: true,
+	})
+}
+
 func NewVoteHandler() *VoteHandler {
 	return &VoteHandler{}
 }
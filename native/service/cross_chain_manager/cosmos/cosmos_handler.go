@@ -24,11 +24,19 @@ import (
 	"github.com/polynetwork/poly/native"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/tendermint/tendermint/crypto/merkle"
 )
 
 type CosmosHandler struct{}
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.COSMOS_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewCosmosHandler() },
+	})
+}
+
 func NewCosmosHandler() *CosmosHandler {
 	return &CosmosHandler{}
 }
@@ -21,34 +21,23 @@ import (
 	"fmt"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
+	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/bytom"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/consensus_vote"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/cosmos"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/harmony"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/hsc"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/msc"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo3"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/ont"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/pixiechain"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/polygon"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/quorum"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/ripple"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/starcoin"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqalegacy"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_incentive"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+var (
+	ImportExTransferGas = native.MethodGas{Base: 50000, PerByte: 20}
+)
+
 func RegisterCrossChainManagerContract(native *native.NativeService) {
 	native.Register(scom.IMPORT_OUTER_TRANSFER_NAME, ImportExTransfer)
 	native.Register(scom.MULTI_SIGN, MultiSign)
@@ -57,55 +46,22 @@ func RegisterCrossChainManagerContract(native *native.NativeService) {
 
 	native.Register(scom.BLACK_CHAIN, BlackChain)
 	native.Register(scom.WHITE_CHAIN, WhiteChain)
+	native.Register(scom.SET_RATE_LIMIT, SetRateLimit)
+	native.Register(scom.RESUME_RATE_LIMIT, ResumeRateLimit)
+	native.Register(scom.GET_RATE_LIMIT, GetRateLimit)
+	native.Register(scom.SET_DELAY_POLICY, SetDelayPolicy)
+	native.Register(scom.RELEASE_PENDING_TX, ReleasePendingTx)
+	native.Register(scom.CANCEL_PENDING_TX, CancelPendingTx)
+
+	native.RegisterGas(scom.IMPORT_OUTER_TRANSFER_NAME, ImportExTransferGas)
 }
 
 func GetChainHandler(router uint64) (scom.ChainHandler, error) {
-	switch router {
-	case utils.VOTE_ROUTER:
-		return consensus_vote.NewVoteHandler(), nil
-	case utils.BTC_ROUTER:
-		return btc.NewBTCHandler(), nil
-	case utils.ETH_ROUTER:
-		return eth.NewETHHandler(), nil
-	case utils.ONT_ROUTER:
-		return ont.NewONTHandler(), nil
-	case utils.NEO_ROUTER:
-		return neo.NewNEOHandler(), nil
-	case utils.NEO3_ROUTER:
-		return neo3.NewNeo3Handler(), nil
-	case utils.COSMOS_ROUTER:
-		return cosmos.NewCosmosHandler(), nil
-	case utils.QUORUM_ROUTER:
-		return quorum.NewQuorumHandler(), nil
-	case utils.BSC_ROUTER:
-		return bsc.NewHandler(), nil
-	case utils.HECO_ROUTER:
-		return heco.NewHecoHandler(), nil
-	case utils.ZILLIQA_LEGACY_ROUTER:
-		return zilliqalegacy.NewHandler(), nil
-	case utils.ZILLIQA_ROUTER:
-		return zilliqa.NewHandler(), nil
-	case utils.MSC_ROUTER:
-		return msc.NewHandler(), nil
-	case utils.OKEX_ROUTER:
-		return okex.NewHandler(), nil
-	case utils.POLYGON_BOR_ROUTER:
-		return polygon.NewHandler(), nil
-	case utils.PIXIECHAIN_ROUTER:
-		return pixiechain.NewPixieHandler(), nil
-	case utils.STARCOIN_ROUTER:
-		return starcoin.NewHandler(), nil
-	case utils.HSC_ROUTER:
-		return hsc.NewHscHandler(), nil
-	case utils.HARMONY_ROUTER:
-		return harmony.NewHandler(), nil
-	case utils.BYTOM_ROUTER:
-		return bytom.NewHandler(), nil
-	case utils.RIPPLE_ROUTER:
-		return ripple.NewRippleHandler(), nil
-	default:
-		return nil, fmt.Errorf("not a supported router:%d", router)
+	info, err := scom.GetChainHandlerInfo(router)
+	if err != nil {
+		return nil, err
 	}
+	return info.NewHandler(), nil
 }
 
 func ImportExTransfer(native *native.NativeService) ([]byte, error) {
@@ -131,64 +87,90 @@ func ImportExTransfer(native *native.NativeService) ([]byte, error) {
 	if sideChain == nil {
 		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", chainID)
 	}
+	if err := relayer_manager.CheckRelayerScope(native, chainID, scom.IMPORT_OUTER_TRANSFER_NAME); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, %v", err)
+	}
 
-	handler, err := GetChainHandler(sideChain.Router)
+	err = importExTransfer(native, params, sideChain.Router)
+	if err == nil {
+		err = relayer_incentive.CreditImportTransfer(native, chainID)
+	}
+	observeImport(native, chainID, sideChain.Router, err)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
-	err = utils.CheckRouterStartBlock(sideChain.Router, native.GetHeight())
+	return utils.BYTE_TRUE, nil
+}
+
+//importExTransfer verifies the tx from source chain with the handler of router and makes the target chain tx
+func importExTransfer(native *native.NativeService, params *scom.EntranceParam, router uint64) error {
+	chainID := params.SourceChainID
+	info, err := scom.GetChainHandlerInfo(router)
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return err
+	}
+	err = info.StartBlocks.Check(router, native.GetHeight())
+	if err != nil {
+		return err
 	}
 
 	//1. verify tx
-	txParam, err := handler.MakeDepositProposal(native)
+	txParam, err := info.NewHandler().MakeDepositProposal(native)
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return err
 	}
-	if txParam == nil && (sideChain.Router == utils.VOTE_ROUTER || sideChain.Router == utils.RIPPLE_ROUTER) {
-		return utils.BYTE_TRUE, nil
+	if txParam == nil && info.AllowEmptyProposal {
+		return nil
 	}
 
 	//2. make target chain tx
 	targetid := txParam.ToChainID
-	blacked, err = scom.CheckIfChainBlacked(native, targetid)
+	blacked, err := scom.CheckIfChainBlacked(native, targetid)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
+		return fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
 	}
 	if blacked {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, target chain is blacked")
+		return fmt.Errorf("ImportExTransfer, target chain is blacked")
+	}
+	if err := scom.CheckRateLimit(native, chainID, targetid, txParam.ToContractAddress); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
 	}
 
+	return makeTargetTransaction(native, txParam, chainID, params.Height)
+}
+
+//makeTargetTransaction makes the target chain tx of txParam with the router of target chain, or stores the request
+//to be proved if the router does not make the tx itself
+func makeTargetTransaction(native *native.NativeService, txParam *scom.MakeTxParam, chainID uint64, proofHeight uint32) error {
 	//check if chainid exist
-	sideChain, err = side_chain_manager.GetSideChain(native, targetid)
+	sideChain, err := side_chain_manager.GetSideChain(native, txParam.ToChainID)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
+		return fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
 	}
 	if sideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", targetid)
+		return fmt.Errorf("ImportExTransfer, side chain %d is not registered", txParam.ToChainID)
 	}
-	if sideChain.Router == utils.BTC_ROUTER {
-		err := btc.NewBTCHandler().MakeTransaction(native, txParam, chainID)
-		if err != nil {
-			return utils.BYTE_FALSE, err
+	if target, err := scom.GetChainHandlerInfo(sideChain.Router); err == nil && target.MakeTransaction != nil {
+		if err := checkNotDelayed(native, sideChain.Router, txParam); err != nil {
+			return fmt.Errorf("ImportExTransfer, %v", err)
 		}
-		return utils.BYTE_TRUE, nil
-	}
-
-	if sideChain.Router == utils.RIPPLE_ROUTER {
-		err := ripple.NewRippleHandler().MakeTransaction(native, txParam, chainID)
-		if err != nil {
-			return utils.BYTE_FALSE, err
-		}
-		return utils.BYTE_TRUE, nil
+		return target.MakeTransaction(native, txParam, chainID)
 	}
 	//NOTE, you need to store the tx in this
-	err = MakeTransaction(native, txParam, chainID)
+	return scom.MakeProvedTransaction(native, txParam, chainID, proofHeight)
+}
+
+//checkNotDelayed refuses the tx if the delay policy of its target contract delays it, routers making the target chain
+//tx themselves do not go through the pending queue
+func checkNotDelayed(native *native.NativeService, router uint64, txParam *scom.MakeTxParam) error {
+	policy, err := scom.GetDelayPolicy(native, txParam.ToChainID, txParam.ToContractAddress)
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return err
 	}
-	return utils.BYTE_TRUE, nil
+	if policy != nil && policy.IsDelayed(txParam.Args) {
+		return fmt.Errorf("delayed tx to chain %d is not supported by router %d", txParam.ToChainID, router)
+	}
+	return nil
 }
 
 func MultiSign(native *native.NativeService) ([]byte, error) {
@@ -223,31 +205,11 @@ func ReconstructRippleTx(native *native.NativeService) ([]byte, error) {
 }
 
 func MakeTransaction(service *native.NativeService, params *scom.MakeTxParam, fromChainID uint64) error {
-	txHash := service.GetTx().Hash()
-	merkleValue := &scom.ToMerkleValue{
-		TxHash:      txHash.ToArray(),
-		FromChainID: fromChainID,
-		MakeTxParam: params,
-	}
-
-	sink := common.NewZeroCopySink(nil)
-	merkleValue.Serialization(sink)
-	err := PutRequest(service, merkleValue.TxHash, params.ToChainID, sink.Bytes())
-	if err != nil {
-		return fmt.Errorf("MakeTransaction, putRequest error:%s", err)
-	}
-	service.PutMerkleVal(sink.Bytes())
-	chainIDBytes := utils.GetUint64Bytes(params.ToChainID)
-	key := hex.EncodeToString(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST), chainIDBytes, merkleValue.TxHash))
-	scom.NotifyMakeProof(service, fromChainID, params.ToChainID, hex.EncodeToString(params.TxHash), key)
-	return nil
+	return scom.MakeTransaction(service, params, fromChainID)
 }
 
 func PutRequest(native *native.NativeService, txHash []byte, chainID uint64, request []byte) error {
-	contract := utils.CrossChainManagerContractAddress
-	chainIDBytes := utils.GetUint64Bytes(chainID)
-	utils.PutBytes(native, utils.ConcatKey(contract, []byte(scom.REQUEST), chainIDBytes, txHash), request)
-	return nil
+	return scom.PutRequest(native, txHash, chainID, request)
 }
 
 func BlackChain(native *native.NativeService) ([]byte, error) {
@@ -291,3 +253,243 @@ func WhiteChain(native *native.NativeService) ([]byte, error) {
 	scom.RemoveBlackChain(native, params.ChainID)
 	return utils.BYTE_TRUE, nil
 }
+
+//isRateLimitEnabled return whether rate limits of chain pairs can be set at the height of the native service
+func isRateLimitEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetCrossChainRateLimitHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+func SetRateLimit(native *native.NativeService) ([]byte, error) {
+	params := new(scom.SetRateLimitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRateLimit, contract params deserialize error: %v", err)
+	}
+	if !isRateLimitEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRateLimit, rate limit is not enabled")
+	}
+	if params.Limit.Limit > 0 && params.Limit.Window == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRateLimit, window should be positive")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRateLimit, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.FromChainID)
+	sink.WriteUint64(params.ToChainID)
+	sink.WriteVarBytes(params.ToContract)
+	params.Limit.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, scom.SET_RATE_LIMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRateLimit, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Limit.Limit == 0 {
+		scom.RemoveRateLimit(native, params.FromChainID, params.ToChainID, params.ToContract)
+	} else {
+		scom.PutRateLimit(native, params.FromChainID, params.ToChainID, params.ToContract, params.Limit)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{scom.SET_RATE_LIMIT, params.FromChainID, params.ToChainID, hex.EncodeToString(params.ToContract),
+				params.Limit.Window, params.Limit.Limit, params.Limit.AutoPause},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//ResumeRateLimit resumes a paused pair and resets its budget
+func ResumeRateLimit(native *native.NativeService) ([]byte, error) {
+	params := new(scom.RateLimitPairParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ResumeRateLimit, contract params deserialize error: %v", err)
+	}
+	if !isRateLimitEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("ResumeRateLimit, rate limit is not enabled")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ResumeRateLimit, checkWitness error: %v", err)
+	}
+
+	state, err := scom.GetRateLimitState(native, params.FromChainID, params.ToChainID, params.ToContract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ResumeRateLimit, %v", err)
+	}
+	if !state.Paused {
+		return utils.BYTE_FALSE, fmt.Errorf("ResumeRateLimit, cross chain from %d to %d contract %x is not paused",
+			params.FromChainID, params.ToChainID, params.ToContract)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.FromChainID)
+	sink.WriteUint64(params.ToChainID)
+	sink.WriteVarBytes(params.ToContract)
+	ok, err := node_manager.CheckConsensusSigns(native, scom.RESUME_RATE_LIMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ResumeRateLimit, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	height := native.GetHeight()
+	scom.PutRateLimitState(native, params.FromChainID, params.ToChainID, params.ToContract, &scom.RateLimitState{WindowStart: height})
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{scom.RESUME_RATE_LIMIT, params.FromChainID, params.ToChainID, hex.EncodeToString(params.ToContract)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//GetRateLimit return the serialized budget of a rate limited pair, used by pre execution
+func GetRateLimit(native *native.NativeService) ([]byte, error) {
+	params := new(scom.RateLimitPairParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRateLimit, contract params deserialize error: %v", err)
+	}
+	limit, err := scom.GetRateLimit(native, params.FromChainID, params.ToChainID, params.ToContract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRateLimit, %v", err)
+	}
+	if limit == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRateLimit, cross chain from %d to %d contract %x is not rate limited",
+			params.FromChainID, params.ToChainID, params.ToContract)
+	}
+	state, err := scom.GetRateLimitState(native, params.FromChainID, params.ToChainID, params.ToContract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRateLimit, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	scom.GetRateLimitBudget(limit, state, native.GetHeight()).Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//isDelayEnabled return whether cross chain txs can be delayed at the height of the native service
+func isDelayEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetCrossChainDelayHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+func SetDelayPolicy(native *native.NativeService) ([]byte, error) {
+	params := new(scom.SetDelayPolicyParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetDelayPolicy, contract params deserialize error: %v", err)
+	}
+	if !isDelayEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("SetDelayPolicy, delayed cross chain tx is not enabled")
+	}
+	if params.Policy.Delay != 0 {
+		sideChain, err := side_chain_manager.GetSideChain(native, params.ToChainID)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("SetDelayPolicy, side_chain_manager.GetSideChain error: %v", err)
+		}
+		if sideChain != nil {
+			target, err := scom.GetChainHandlerInfo(sideChain.Router)
+			if err == nil && target.MakeTransaction != nil {
+				return utils.BYTE_FALSE, fmt.Errorf("SetDelayPolicy, delayed tx is not supported by router %d of chain %d",
+					sideChain.Router, params.ToChainID)
+			}
+		}
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetDelayPolicy, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.ToChainID)
+	sink.WriteVarBytes(params.ToContract)
+	params.Policy.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, scom.SET_DELAY_POLICY, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetDelayPolicy, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Policy.Delay == 0 {
+		scom.RemoveDelayPolicy(native, params.ToChainID, params.ToContract)
+	} else {
+		scom.PutDelayPolicy(native, params.ToChainID, params.ToContract, params.Policy)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{scom.SET_DELAY_POLICY, params.ToChainID, hex.EncodeToString(params.ToContract),
+				params.Policy.Delay, params.Policy.Threshold.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//ReleasePendingTx can be called by anyone once the delay of the pending tx is over
+func ReleasePendingTx(native *native.NativeService) ([]byte, error) {
+	params := new(scom.PendingTxParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ReleasePendingTx, contract params deserialize error: %v", err)
+	}
+	if !isDelayEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("ReleasePendingTx, delayed cross chain tx is not enabled")
+	}
+	blacked, err := scom.CheckIfChainBlacked(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ReleasePendingTx, CheckIfChainBlacked error: %v", err)
+	}
+	if blacked {
+		return utils.BYTE_FALSE, fmt.Errorf("ReleasePendingTx, target chain is blacked")
+	}
+	if err := scom.ReleasePendingTx(native, params.ToChainID, params.TxHash); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func CancelPendingTx(native *native.NativeService) ([]byte, error) {
+	params := new(scom.PendingTxParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CancelPendingTx, contract params deserialize error: %v", err)
+	}
+	if !isDelayEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("CancelPendingTx, delayed cross chain tx is not enabled")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CancelPendingTx, checkWitness error: %v", err)
+	}
+	pending, err := scom.GetPendingTx(native, params.ToChainID, params.TxHash)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CancelPendingTx, %v", err)
+	}
+	if pending == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CancelPendingTx, tx %x to chain %d is not pending", params.TxHash, params.ToChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.ToChainID)
+	sink.WriteVarBytes(params.TxHash)
+	ok, err := node_manager.CheckConsensusSigns(native, scom.CANCEL_PENDING_TX, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CancelPendingTx, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if err := scom.CancelPendingTx(native, params.ToChainID, params.TxHash); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/bytom"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/cometbft"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/consensus_vote"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/cosmos"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/ethpos"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/harmony"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/hsc"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/msc"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo3"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/ont"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/pixiechain"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/polygon"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/quorum"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/ripple"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/starcoin"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqalegacy"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// handlers of all supported routers
+var expectedHandlers = map[uint64]scom.ChainHandler{
+	utils.VOTE_ROUTER:           consensus_vote.NewVoteHandler(),
+	utils.BTC_ROUTER:            btc.NewBTCHandler(),
+	utils.ETH_ROUTER:            eth.NewETHHandler(),
+	utils.ONT_ROUTER:            ont.NewONTHandler(),
+	utils.NEO_ROUTER:            neo.NewNEOHandler(),
+	utils.NEO3_ROUTER:           neo3.NewNeo3Handler(),
+	utils.COSMOS_ROUTER:         cosmos.NewCosmosHandler(),
+	utils.QUORUM_ROUTER:         quorum.NewQuorumHandler(),
+	utils.BSC_ROUTER:            bsc.NewHandler(),
+	utils.HECO_ROUTER:           heco.NewHecoHandler(),
+	utils.ZILLIQA_LEGACY_ROUTER: zilliqalegacy.NewHandler(),
+	utils.ZILLIQA_ROUTER:        zilliqa.NewHandler(),
+	utils.MSC_ROUTER:            msc.NewHandler(),
+	utils.OKEX_ROUTER:           okex.NewHandler(),
+	utils.POLYGON_BOR_ROUTER:    polygon.NewHandler(),
+	utils.PIXIECHAIN_ROUTER:     pixiechain.NewPixieHandler(),
+	utils.STARCOIN_ROUTER:       starcoin.NewHandler(),
+	utils.HSC_ROUTER:            hsc.NewHscHandler(),
+	utils.HARMONY_ROUTER:        harmony.NewHandler(),
+	utils.BYTOM_ROUTER:          bytom.NewHandler(),
+	utils.RIPPLE_ROUTER:         ripple.NewRippleHandler(),
+	utils.ETH_POS_ROUTER:        ethpos.NewETHPoSHandler(),
+	utils.COMETBFT_ROUTER:       cometbft.NewCometBFTHandler(),
+}
+
+func TestGetChainHandler(t *testing.T) {
+	assert.Equal(t, len(expectedHandlers), len(scom.ChainRouters()))
+	for router, expected := range expectedHandlers {
+		handler, err := GetChainHandler(router)
+		assert.Nil(t, err)
+		assert.Equal(t, reflect.TypeOf(expected), reflect.TypeOf(handler), "router %d", router)
+	}
+	for _, router := range []uint64{utils.NEO3_LEGACY_ROUTER, 13, utils.POLYGON_HEIMDALL_ROUTER, 100} {
+		_, err := GetChainHandler(router)
+		assert.EqualError(t, err, fmt.Sprintf("not a supported router:%d", router))
+	}
+}
+
+func TestChainHandlerOptions(t *testing.T) {
+	makeTx := map[uint64]bool{utils.BTC_ROUTER: true, utils.RIPPLE_ROUTER: true}
+	allowEmpty := map[uint64]bool{utils.VOTE_ROUTER: true, utils.RIPPLE_ROUTER: true}
+	forked := map[uint64]bool{utils.HSC_ROUTER: true, utils.HARMONY_ROUTER: true, utils.BYTOM_ROUTER: true}
+	unscheduled := map[uint64]bool{utils.ETH_POS_ROUTER: true, utils.COMETBFT_ROUTER: true}
+
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	for _, router := range scom.ChainRouters() {
+		info, err := scom.GetChainHandlerInfo(router)
+		assert.Nil(t, err)
+		assert.Equal(t, makeTx[router], info.MakeTransaction != nil, "router %d", router)
+		assert.Equal(t, allowEmpty[router], info.AllowEmptyProposal, "router %d", router)
+
+		if unscheduled[router] {
+			config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+			assert.Error(t, info.StartBlocks.Check(router, 18823000), "router %d", router)
+			config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_TEST_NET
+			assert.Error(t, info.StartBlocks.Check(router, 0), "router %d", router)
+			config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+			assert.Nil(t, info.StartBlocks.Check(router, 0))
+			continue
+		}
+		config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+		assert.Equal(t, forked[router], info.StartBlocks.Check(router, 18822999) != nil, "router %d", router)
+		assert.Nil(t, info.StartBlocks.Check(router, 18823000))
+		config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_TEST_NET
+		assert.Nil(t, info.StartBlocks.Check(router, 0))
+	}
+}
+
+func TestDelayPolicyOfBtcRouter(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	newNative := func(input []byte) *native.NativeService {
+		ns, _ := native.NewNativeService(db, new(types.Transaction), 0, 1, common.Uint256{}, 0, input, false)
+		return ns
+	}
+	assert.Nil(t, side_chain_manager.PutSideChain(newNative(nil), &side_chain_manager.SideChain{
+		ChainId: 3,
+		Router:  utils.BTC_ROUTER,
+		Name:    "btc",
+	}))
+	param := &scom.MakeTxParam{
+		TxHash:              []byte{9},
+		CrossChainID:        []byte{1},
+		FromContractAddress: []byte{2},
+		ToChainID:           3,
+		ToContractAddress:   []byte{1, 2, 3},
+		Method:              "unlock",
+		Args:                []byte{},
+	}
+
+	// without a policy the tx is made by the btc router
+	err := makeTargetTransaction(newNative(nil), param, 2, 0)
+	assert.EqualError(t, err, "btc MakeTransaction, deserialize toAddr error")
+
+	policy := &scom.DelayPolicy{Delay: 10, Threshold: big.NewInt(1000)}
+	scom.PutDelayPolicy(newNative(nil), 3, param.ToContractAddress, policy)
+	err = makeTargetTransaction(newNative(nil), param, 2, 0)
+	assert.EqualError(t, err, "ImportExTransfer, delayed tx to chain 3 is not supported by router 1")
+	count, err := scom.GetPendingTxCount(newNative(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), count)
+
+	sink := common.NewZeroCopySink(nil)
+	(&scom.SetDelayPolicyParam{ToChainID: 3, ToContract: param.ToContractAddress, Policy: policy}).Serialization(sink)
+	_, err = SetDelayPolicy(newNative(sink.Bytes()))
+	assert.EqualError(t, err, "SetDelayPolicy, delayed tx is not supported by router 1 of chain 3")
+}
@@ -22,11 +22,19 @@ import (
 	"github.com/polynetwork/poly/native"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type ETHHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.ETH_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewETHHandler() },
+	})
+}
+
 func NewETHHandler() *ETHHandler {
 	return &ETHHandler{}
 }
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	ceth "github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync/eth"
+	"github.com/polynetwork/poly/native/service/header_sync/ethpos"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type ETHPoSHandler struct {
+}
+
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:      utils.ETH_POS_ROUTER,
+		NewHandler:  func() scom.ChainHandler { return NewETHPoSHandler() },
+		StartBlocks: utils.EthPosRouterStartBlocks,
+	})
+}
+
+func NewETHPoSHandler() *ETHPoSHandler {
+	return &ETHPoSHandler{}
+}
+
+func (this *ETHPoSHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("ethpos MakeDepositProposal, contract params deserialize error: %s", err)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("ethpos MakeDepositProposal, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("ethpos MakeDepositProposal, side chain %d is not registered", params.SourceChainID)
+	}
+	value, err := verifyFromEthPoSTx(service, params.Proof, params.Extra, params.SourceChainID, params.Height, sideChain)
+	if err != nil {
+		return nil, fmt.Errorf("ethpos MakeDepositProposal, verifyFromEthPoSTx error: %s", err)
+	}
+	if err := scom.CheckDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("ethpos MakeDepositProposal, check done transaction error:%s", err)
+	}
+	if err := scom.PutDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("ethpos MakeDepositProposal, PutDoneTx error:%s", err)
+	}
+	return value, nil
+}
+
+//verifyFromEthPoSTx verifies the storage proof against the state root of a finalized execution block, headers of
+//the ethpos router are final so BlocksToWait of side chain is not needed
+func verifyFromEthPoSTx(native *native.NativeService, proof, extra []byte, fromChainID uint64, height uint32,
+	sideChain *side_chain_manager.SideChain) (*scom.MakeTxParam, error) {
+	header, err := ethpos.GetHeaderByHeight(native, uint64(height), fromChainID)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, get header by height, height:%d, error:%s", height, err)
+	}
+	ethProof := new(ceth.ETHProof)
+	if err := json.Unmarshal(proof, ethProof); err != nil {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, unmarshal proof error:%s", err)
+	}
+	if len(ethProof.StorageProofs) != 1 {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, incorrect proof format")
+	}
+	blockData := &eth.Header{
+		Root:   header.StateRoot,
+		Number: new(big.Int).SetUint64(uint64(header.BlockNumber)),
+	}
+	proofResult, err := ceth.VerifyMerkleProof(ethProof, blockData, sideChain.CCMCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, verifyMerkleProof error:%v", err)
+	}
+	if proofResult == nil {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, verifyMerkleProof failed!")
+	}
+	if !ceth.CheckProofResult(proofResult, extra) {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, verify proof value hash failed, proof result:%x, extra:%x", proofResult, extra)
+	}
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(extra)); err != nil {
+		return nil, fmt.Errorf("VerifyFromEthPoSProof, deserialize merkleValue error:%s", err)
+	}
+	return txParam, nil
+}
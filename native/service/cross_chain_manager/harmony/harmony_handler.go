@@ -34,10 +34,19 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/harmony"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type Handler struct {}
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:      utils.HARMONY_ROUTER,
+		NewHandler:  func() scom.ChainHandler { return NewHandler() },
+		StartBlocks: utils.HardForkRouterStartBlocks,
+	})
+}
+
 func NewHandler() *Handler {
 	return new(Handler)
 }
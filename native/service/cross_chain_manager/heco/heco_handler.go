@@ -36,12 +36,20 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/eth"
 	"github.com/polynetwork/poly/native/service/header_sync/heco"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // Handler ...
 type HecoHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.HECO_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewHecoHandler() },
+	})
+}
+
 // NewHandler ...
 func NewHecoHandler() *HecoHandler {
 	return &HecoHandler{}
@@ -36,12 +36,21 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/eth"
 	"github.com/polynetwork/poly/native/service/header_sync/hsc"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // Handler ...
 type HscHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:      utils.HSC_ROUTER,
+		NewHandler:  func() scom.ChainHandler { return NewHscHandler() },
+		StartBlocks: utils.HardForkRouterStartBlocks,
+	})
+}
+
 // NewHandler ...
 func NewHscHandler() *HscHandler {
 	return &HscHandler{}
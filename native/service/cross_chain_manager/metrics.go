@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"strconv"
+
+	"github.com/polynetwork/poly/common/metrics"
+	"github.com/polynetwork/poly/native"
+)
+
+var importCounter = metrics.NewCounterVec("poly_cross_chain_import_total",
+	"Count of ImportOuterTransfer transactions handled by the router of source chain", "chain", "router", "result")
+
+func init() {
+	metrics.DefaultRegistry.MustRegister(importCounter)
+}
+
+//observeImport counts the result of ImportOuterTransfer, pre execution is not counted
+func observeImport(native *native.NativeService, chainID, router uint64, err error) {
+	if native.IsPreExec() {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	importCounter.WithLabelValues(strconv.FormatUint(chainID, 10), strconv.FormatUint(router, 10), result).Inc()
+}
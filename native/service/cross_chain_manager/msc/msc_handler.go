@@ -35,12 +35,20 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/msc"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // Handler ...
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.MSC_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewHandler() },
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
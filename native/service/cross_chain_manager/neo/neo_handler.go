@@ -24,11 +24,19 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/neo"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type NEOHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.NEO_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewNEOHandler() },
+	})
+}
+
 func NewNEOHandler() *NEOHandler {
 	return &NEOHandler{}
 }
@@ -25,11 +25,19 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/neo3"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type Neo3Handler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.NEO3_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewNeo3Handler() },
+	})
+}
+
 func NewNeo3Handler() *Neo3Handler {
 	return &Neo3Handler{}
 }
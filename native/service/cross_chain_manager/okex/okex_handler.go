@@ -28,11 +28,19 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/okex"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/tendermint/tendermint/crypto/merkle"
 )
 
 type OKHandler struct{}
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.OKEX_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewHandler() },
+	})
+}
+
 func NewHandler() *OKHandler {
 	return &OKHandler{}
 }
@@ -28,11 +28,19 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/ont"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type ONTHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.ONT_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewONTHandler() },
+	})
+}
+
 func NewONTHandler() *ONTHandler {
 	return &ONTHandler{}
 }
@@ -36,8 +36,16 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/eth"
 	"github.com/polynetwork/poly/native/service/header_sync/pixiechain"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.PIXIECHAIN_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewPixieHandler() },
+	})
+}
+
 // NewPixieHandler ...
 func NewPixieHandler() *PixieHandler {
 	return &PixieHandler{}
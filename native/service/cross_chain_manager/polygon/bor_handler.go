@@ -35,12 +35,20 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/eth"
 	"github.com/polynetwork/poly/native/service/header_sync/polygon"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // BorHandler ...
 type BorHandler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.POLYGON_BOR_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewHandler() },
+	})
+}
+
 // NewHandler ...
 func NewHandler() *BorHandler {
 	return &BorHandler{}
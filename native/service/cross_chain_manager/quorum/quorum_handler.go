@@ -26,10 +26,18 @@ import (
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/quorum"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type QuorumHandler struct{}
 
+func init() {
+	common.RegisterChainHandler(&common.ChainHandlerInfo{
+		Router:     utils.QUORUM_ROUTER,
+		NewHandler: func() common.ChainHandler { return NewQuorumHandler() },
+	})
+}
+
 func NewQuorumHandler() *QuorumHandler {
 	return &QuorumHandler{}
 }
@@ -39,6 +39,17 @@ import (
 type RippleHandler struct {
 }
 
+func init() {
+	crosscommon.RegisterChainHandler(&crosscommon.ChainHandlerInfo{
+		Router:     utils.RIPPLE_ROUTER,
+		NewHandler: func() crosscommon.ChainHandler { return NewRippleHandler() },
+		MakeTransaction: func(service *native.NativeService, param *crosscommon.MakeTxParam, fromChainID uint64) error {
+			return NewRippleHandler().MakeTransaction(service, param, fromChainID)
+		},
+		AllowEmptyProposal: true,
+	})
+}
+
 func NewRippleHandler() *RippleHandler {
 	return &RippleHandler{}
 }
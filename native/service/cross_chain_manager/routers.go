@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+// Chain packages register their handlers in init, a new chain only needs to be imported here
+import (
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/bytom"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/cometbft"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/consensus_vote"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/cosmos"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/ethpos"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/harmony"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/hsc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/msc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/neo"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/neo3"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/ont"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/pixiechain"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/polygon"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/quorum"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/ripple"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/starcoin"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqalegacy"
+)
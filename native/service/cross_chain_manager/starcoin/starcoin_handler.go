@@ -24,12 +24,20 @@ import (
 	"github.com/polynetwork/poly/native"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	cmanager "github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // Handler ...
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterChainHandler(&scom.ChainHandlerInfo{
+		Router:     utils.STARCOIN_ROUTER,
+		NewHandler: func() scom.ChainHandler { return NewHandler() },
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
@@ -21,8 +21,7 @@ import (
 	"fmt"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
-	"github.com/polynetwork/poly/native/event"
-	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -84,31 +83,17 @@ func ApproveRegisterStateValidator(native *native.NativeService) ([]byte, error)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterStateValidator, checkWitness error: %v", err)
 	}
-	// get sv list
-	svListParam, err := getStateValidatorApply(native, params.ID)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterStateValidator, getStateValidatorApply error: %v", err)
+	if err := checkRegisterStateValidator(native, utils.GetUint64Bytes(params.ID)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterStateValidator, %v", err)
 	}
-	// check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_REGISTER_STATE_VALIDATOR, utils.GetUint64Bytes(params.ID), params.Address)
+	// vote for the proposal, it is executed once approved by consensus peers
+	ok, err := proposal_manager.Approve(native, proposal_manager.PROPOSAL_REGISTER_STATE_VALIDATOR, APPROVE_REGISTER_STATE_VALIDATOR, utils.GetUint64Bytes(params.ID), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterStateValidator, CheckConsensusSigns error: %v", err)
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterStateValidator, Approve error: %v", err)
 	}
 	if !ok {
 		return utils.BYTE_FALSE, nil
 	}
-	// put all the state validators in storage
-	err = putStateValidators(native, svListParam.StateValidators)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterStateValidator, putStateValidators error: %v", err)
-	}
-
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.Neo3StateManagerContractAddress, []byte(STATE_VALIDATOR_APPLY), utils.GetUint64Bytes(params.ID)))
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.Neo3StateManagerContractAddress,
-			States:          []interface{}{"ApproveRegisterStateValidator", params.ID},
-		})
 	return utils.BYTE_TRUE, nil
 }
 
@@ -138,30 +123,16 @@ func ApproveRemoveStateValidator(native *native.NativeService) ([]byte, error) {
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveStateValidator, checkWitness error: %v", err)
 	}
-	// get sv list
-	svListParam, err := getStateValidatorRemove(native, params.ID)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveStateValidator, getStateValidatorRemove error: %v", err)
+	if err := checkRemoveStateValidator(native, utils.GetUint64Bytes(params.ID)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveStateValidator, %v", err)
 	}
-	// check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_REMOVE_STATE_VALIDATOR, utils.GetUint64Bytes(params.ID), params.Address)
+	// vote for the proposal, it is executed once approved by consensus peers
+	ok, err := proposal_manager.Approve(native, proposal_manager.PROPOSAL_REMOVE_STATE_VALIDATOR, APPROVE_REMOVE_STATE_VALIDATOR, utils.GetUint64Bytes(params.ID), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveStateValidator, CheckConsensusSigns error: %v", err)
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveStateValidator, Approve error: %v", err)
 	}
 	if !ok {
 		return utils.BYTE_FALSE, nil
 	}
-	// remove svs
-	err = removeStateValidators(native, svListParam.StateValidators)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveStateValidator, removeStateValidators error: %v", err)
-	}
-
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.Neo3StateManagerContractAddress, []byte(STATE_VALIDATOR_REMOVE), utils.GetUint64Bytes(params.ID)))
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.Neo3StateManagerContractAddress,
-			States:          []interface{}{"ApproveRemoveStateValidator", params.ID},
-		})
 	return utils.BYTE_TRUE, nil
 }
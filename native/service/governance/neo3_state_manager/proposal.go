@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package neo3_state_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_REGISTER_STATE_VALIDATOR,
+		Check:   checkRegisterStateValidator,
+		Execute: executeRegisterStateValidator,
+	})
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_REMOVE_STATE_VALIDATOR,
+		Check:   checkRemoveStateValidator,
+		Execute: executeRemoveStateValidator,
+	})
+}
+
+func checkRegisterStateValidator(native *native.NativeService, content []byte) error {
+	applyID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	_, err = getStateValidatorApply(native, applyID)
+	return err
+}
+
+func executeRegisterStateValidator(native *native.NativeService, content []byte) error {
+	applyID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	svListParam, err := getStateValidatorApply(native, applyID)
+	if err != nil {
+		return err
+	}
+	// put all the state validators in storage
+	err = putStateValidators(native, svListParam.StateValidators)
+	if err != nil {
+		return fmt.Errorf("putStateValidators error: %v", err)
+	}
+
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.Neo3StateManagerContractAddress, []byte(STATE_VALIDATOR_APPLY), utils.GetUint64Bytes(applyID)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.Neo3StateManagerContractAddress,
+			States:          []interface{}{"ApproveRegisterStateValidator", applyID},
+		})
+	return nil
+}
+
+func checkRemoveStateValidator(native *native.NativeService, content []byte) error {
+	removeID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	_, err = getStateValidatorRemove(native, removeID)
+	return err
+}
+
+func executeRemoveStateValidator(native *native.NativeService, content []byte) error {
+	removeID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	svListParam, err := getStateValidatorRemove(native, removeID)
+	if err != nil {
+		return err
+	}
+	// remove svs
+	err = removeStateValidators(native, svListParam.StateValidators)
+	if err != nil {
+		return fmt.Errorf("removeStateValidators error: %v", err)
+	}
+
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.Neo3StateManagerContractAddress, []byte(STATE_VALIDATOR_REMOVE), utils.GetUint64Bytes(removeID)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.Neo3StateManagerContractAddress,
+			States:          []interface{}{"ApproveRemoveStateValidator", removeID},
+		})
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node_manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+//types of vbft consensus messages, the same as MsgType of vbft
+const (
+	VBFT_PROPOSAL_MSG uint8 = 0
+	VBFT_ENDORSE_MSG  uint8 = 1
+	VBFT_COMMIT_MSG   uint8 = 2
+)
+
+//vbftMsgPayload is the json envelope of vbft consensus messages
+type vbftMsgPayload struct {
+	Type    uint8  `json:"type"`
+	Len     uint32 `json:"len"`
+	Payload []byte `json:"payload"`
+}
+
+type vbftEndorseMsg struct {
+	Endorser          uint32         `json:"endorser"`
+	EndorsedProposer  uint32         `json:"endorsed_proposer"`
+	BlockNum          uint32         `json:"block_num"`
+	EndorsedBlockHash common.Uint256 `json:"endorsed_block_hash"`
+	EndorseForEmpty   bool           `json:"endorse_for_empty"`
+}
+
+type vbftCommitMsg struct {
+	Committer       uint32         `json:"committer"`
+	BlockProposer   uint32         `json:"block_proposer"`
+	BlockNum        uint32         `json:"block_num"`
+	CommitBlockHash common.Uint256 `json:"commit_block_hash"`
+	CommitForEmpty  bool           `json:"commit_for_empty"`
+}
+
+//SignedConsensusMsg is a vbft proposal, endorsement or commit signed by the consensus payload owner.
+//A validator signs at most one block for a proposer at a height in each step, Author is the index of the signer.
+type SignedConsensusMsg struct {
+	Owner    keypair.PublicKey
+	Type     uint8
+	Height   uint32
+	Author   uint32
+	Proposer uint32
+	ForEmpty bool
+	Hash     common.Uint256
+}
+
+//DecodeConsensusMsg verifies the signature of a serialized consensus payload and decodes the vbft message in it
+func DecodeConsensusMsg(data []byte) (*SignedConsensusMsg, error) {
+	payload := new(p2pmsg.ConsensusPayload)
+	if err := payload.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, fmt.Errorf("deserialize consensus payload error: %v", err)
+	}
+	if err := payload.Verify(); err != nil {
+		return nil, fmt.Errorf("verify consensus payload error: %v", err)
+	}
+	envelope := new(vbftMsgPayload)
+	if err := json.Unmarshal(payload.Data, envelope); err != nil {
+		return nil, fmt.Errorf("unmarshal vbft msg error: %v", err)
+	}
+	msg := &SignedConsensusMsg{Owner: payload.Owner, Type: envelope.Type}
+	switch envelope.Type {
+	case VBFT_PROPOSAL_MSG:
+		source := common.NewZeroCopySource(envelope.Payload)
+		raw, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("deserialize proposal block error")
+		}
+		block, err := types.BlockFromRawBytes(raw)
+		if err != nil {
+			return nil, fmt.Errorf("deserialize proposal block error: %v", err)
+		}
+		info := new(vconfig.VbftBlockInfo)
+		if err := json.Unmarshal(block.Header.ConsensusPayload, info); err != nil {
+			return nil, fmt.Errorf("unmarshal vbft block info error: %v", err)
+		}
+		//proposal may be relayed by other peers, the block must be signed by the owner too
+		hash := block.Hash()
+		if len(block.Header.SigData) == 0 {
+			return nil, fmt.Errorf("no signature in proposal block")
+		}
+		if err := signature.Verify(payload.Owner, hash[:], block.Header.SigData[0]); err != nil {
+			return nil, fmt.Errorf("verify proposal block signature error: %v", err)
+		}
+		msg.Height, msg.Author, msg.Proposer, msg.Hash = block.Header.Height, info.Proposer, info.Proposer, hash
+	case VBFT_ENDORSE_MSG:
+		endorse := new(vbftEndorseMsg)
+		if err := json.Unmarshal(envelope.Payload, endorse); err != nil {
+			return nil, fmt.Errorf("unmarshal endorse msg error: %v", err)
+		}
+		msg.Height, msg.Author, msg.Proposer = endorse.BlockNum, endorse.Endorser, endorse.EndorsedProposer
+		msg.ForEmpty, msg.Hash = endorse.EndorseForEmpty, endorse.EndorsedBlockHash
+	case VBFT_COMMIT_MSG:
+		commit := new(vbftCommitMsg)
+		if err := json.Unmarshal(envelope.Payload, commit); err != nil {
+			return nil, fmt.Errorf("unmarshal commit msg error: %v", err)
+		}
+		msg.Height, msg.Author, msg.Proposer = commit.BlockNum, commit.Committer, commit.BlockProposer
+		msg.ForEmpty, msg.Hash = commit.CommitForEmpty, commit.CommitBlockHash
+	default:
+		return nil, fmt.Errorf("msg type %d can not be evidence", envelope.Type)
+	}
+	return msg, nil
+}
+
+//CheckEvidenceHeight checks evidence of consensus msgs at msgHeight is accepted at height, it must be enabled and not
+//older than MAX_EVIDENCE_AGE blocks
+func CheckEvidenceHeight(height, msgHeight uint32) error {
+	if height < config.GetEvidenceHeight(config.DefConfig.P2PNode.NetworkId) {
+		return fmt.Errorf("evidence is not accepted yet")
+	}
+	if msgHeight > height {
+		return fmt.Errorf("evidence of height %d is ahead of current height %d", msgHeight, height)
+	}
+	if height-msgHeight > MAX_EVIDENCE_AGE {
+		return fmt.Errorf("evidence of height %d is expired at height %d", msgHeight, height)
+	}
+	return nil
+}
+
+//EquivocationEvidence is two conflicting consensus messages signed by the same validator. MsgA and MsgB are
+//serialized consensus payloads, sorted so that every node packages the same evidence.
+type EquivocationEvidence struct {
+	MsgA []byte
+	MsgB []byte
+}
+
+//NewEquivocationEvidence sorts the two consensus payloads and makes the evidence
+func NewEquivocationEvidence(msgA, msgB []byte) *EquivocationEvidence {
+	if bytes.Compare(msgA, msgB) > 0 {
+		msgA, msgB = msgB, msgA
+	}
+	return &EquivocationEvidence{MsgA: msgA, MsgB: msgB}
+}
+
+func (this *EquivocationEvidence) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.MsgA)
+	sink.WriteVarBytes(this.MsgB)
+}
+
+func (this *EquivocationEvidence) Deserialization(source *common.ZeroCopySource) error {
+	msgA, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize msgA error")
+	}
+	msgB, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize msgB error")
+	}
+	this.MsgA = msgA
+	this.MsgB = msgB
+	return nil
+}
+
+//Verify checks the two messages are signed by the same validator for different blocks of the same proposer at the
+//same height in the same step, returns the decoded messages
+func (this *EquivocationEvidence) Verify() (*SignedConsensusMsg, *SignedConsensusMsg, error) {
+	a, err := DecodeConsensusMsg(this.MsgA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("msgA: %v", err)
+	}
+	b, err := DecodeConsensusMsg(this.MsgB)
+	if err != nil {
+		return nil, nil, fmt.Errorf("msgB: %v", err)
+	}
+	if !bytes.Equal(keypair.SerializePublicKey(a.Owner), keypair.SerializePublicKey(b.Owner)) {
+		return nil, nil, fmt.Errorf("messages are signed by different peers")
+	}
+	if a.Type != b.Type || a.Height != b.Height || a.Author != b.Author || a.Proposer != b.Proposer ||
+		a.ForEmpty != b.ForEmpty {
+		return nil, nil, fmt.Errorf("messages are not for the same block")
+	}
+	if a.Hash == b.Hash {
+		return nil, nil, fmt.Errorf("messages sign the same block %s", a.Hash.ToHexString())
+	}
+	return a, b, nil
+}
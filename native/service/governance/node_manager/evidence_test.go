@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node_manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/stretchr/testify/assert"
+)
+
+//signPayload packs vbft msg in a consensus payload signed by acc
+func signPayload(t *testing.T, acc *account.Account, msgType uint8, msg []byte) []byte {
+	data, err := json.Marshal(&vbftMsgPayload{Type: msgType, Len: uint32(len(msg)), Payload: msg})
+	assert.Nil(t, err)
+	payload := &p2pmsg.ConsensusPayload{Data: data, Owner: acc.PublicKey}
+	buf := new(bytes.Buffer)
+	assert.Nil(t, payload.SerializeUnsigned(buf))
+	payload.Signature, err = signature.Sign(acc, buf.Bytes())
+	assert.Nil(t, err)
+	sink := common.NewZeroCopySink(nil)
+	assert.Nil(t, payload.Serialization(sink))
+	return sink.Bytes()
+}
+
+func endorseMsg(t *testing.T, acc *account.Account, endorser, proposer uint32, forEmpty bool, hash byte) []byte {
+	msg, err := json.Marshal(&vbftEndorseMsg{
+		Endorser:          endorser,
+		EndorsedProposer:  proposer,
+		BlockNum:          100,
+		EndorsedBlockHash: common.Uint256{hash},
+		EndorseForEmpty:   forEmpty,
+	})
+	assert.Nil(t, err)
+	return signPayload(t, acc, VBFT_ENDORSE_MSG, msg)
+}
+
+//proposalMsg makes a proposal of block signed by signer, and relayed by acc
+func proposalMsg(t *testing.T, acc, signer *account.Account, proposer uint32, timestamp uint32) []byte {
+	info, err := json.Marshal(&vconfig.VbftBlockInfo{Proposer: proposer})
+	assert.Nil(t, err)
+	block := &types.Block{
+		Header: &types.Header{
+			Height:           100,
+			Timestamp:        timestamp,
+			ConsensusPayload: info,
+		},
+	}
+	hash := block.Hash()
+	sig, err := signature.Sign(signer, hash[:])
+	assert.Nil(t, err)
+	block.Header.Bookkeepers = append(block.Header.Bookkeepers, signer.PublicKey)
+	block.Header.SigData = [][]byte{sig}
+	sink := common.NewZeroCopySink(nil)
+	assert.Nil(t, block.Serialization(sink))
+	payload := common.NewZeroCopySink(nil)
+	payload.WriteVarBytes(sink.Bytes())
+	return signPayload(t, acc, VBFT_PROPOSAL_MSG, payload.Bytes())
+}
+
+func TestEquivocationEvidence(t *testing.T) {
+	acc := account.NewAccount("SHA256withECDSA")
+	other := account.NewAccount("SHA256withECDSA")
+
+	evidence := NewEquivocationEvidence(endorseMsg(t, acc, 1, 2, false, 1), endorseMsg(t, acc, 1, 2, false, 2))
+	a, b, err := evidence.Verify()
+	assert.Nil(t, err)
+	assert.Equal(t, VBFT_ENDORSE_MSG, a.Type)
+	assert.Equal(t, uint32(100), a.Height)
+	assert.Equal(t, uint32(1), a.Author)
+	assert.Equal(t, uint32(2), a.Proposer)
+	assert.NotEqual(t, a.Hash, b.Hash)
+	//evidence is the same whatever the order of msgs
+	assert.Equal(t, evidence, NewEquivocationEvidence(evidence.MsgB, evidence.MsgA))
+
+	sink := common.NewZeroCopySink(nil)
+	evidence.Serialization(sink)
+	decoded := new(EquivocationEvidence)
+	assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, evidence, decoded)
+
+	for _, c := range []struct {
+		msgA, msgB []byte
+	}{
+		//same block
+		{endorseMsg(t, acc, 1, 2, false, 1), endorseMsg(t, acc, 1, 2, false, 1)},
+		//full block and empty block
+		{endorseMsg(t, acc, 1, 2, false, 1), endorseMsg(t, acc, 1, 2, true, 2)},
+		//blocks of different proposers
+		{endorseMsg(t, acc, 1, 2, false, 1), endorseMsg(t, acc, 1, 3, false, 2)},
+		//signed by different peers
+		{endorseMsg(t, acc, 1, 2, false, 1), endorseMsg(t, other, 1, 2, false, 2)},
+		//proposal and endorsement
+		{endorseMsg(t, acc, 1, 1, false, 1), proposalMsg(t, acc, acc, 1, 1)},
+		//proposals relayed by other peer
+		{proposalMsg(t, other, acc, 1, 1), proposalMsg(t, other, acc, 1, 2)},
+	} {
+		_, _, err := NewEquivocationEvidence(c.msgA, c.msgB).Verify()
+		assert.NotNil(t, err)
+	}
+
+	evidence = NewEquivocationEvidence(proposalMsg(t, acc, acc, 1, 1), proposalMsg(t, acc, acc, 1, 2))
+	a, _, err = evidence.Verify()
+	assert.Nil(t, err)
+	assert.Equal(t, VBFT_PROPOSAL_MSG, a.Type)
+	assert.Equal(t, uint32(1), a.Author)
+
+	//tampered payload
+	msg := endorseMsg(t, acc, 1, 2, false, 2)
+	msg[len(msg)-1] ^= 1
+	_, _, err = NewEquivocationEvidence(endorseMsg(t, acc, 1, 2, false, 1), msg).Verify()
+	assert.NotNil(t, err)
+}
+
+func TestCheckEvidenceHeight(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+	}()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	assert.NotNil(t, CheckEvidenceHeight(101, 100))
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	assert.Nil(t, CheckEvidenceHeight(100, 100))
+	assert.Nil(t, CheckEvidenceHeight(100+MAX_EVIDENCE_AGE, 100))
+	assert.NotNil(t, CheckEvidenceHeight(101+MAX_EVIDENCE_AGE, 100))
+	assert.NotNil(t, CheckEvidenceHeight(99, 100))
+}
@@ -18,11 +18,88 @@
 package node_manager
 
 import (
+	"encoding/hex"
 	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+//isActivePeer returns whether the peer is neither quiting nor blacked
+func isActivePeer(peerPoolItem *PeerPoolItem) bool {
+	return peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus ||
+		peerPoolItem.Status == StandbyStatus
+}
+
+//checkBlackPeers checks the peers can be blacked and enough peers are left
+func checkBlackPeers(peerPoolMap *PeerPoolMap, peerPubkeyList []string) error {
+	//check peers num
+	num := 0
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if isActivePeer(peerPoolItem) {
+			num = num + 1
+		}
+	}
+	if num <= MIN_PEER_NUM+len(peerPubkeyList)-1 {
+		return fmt.Errorf("num of peers is less than 4")
+	}
+	for _, peerPubkey := range peerPubkeyList {
+		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
+		if !ok {
+			return fmt.Errorf("peerPubkey: %s is not in peerPoolMap", peerPubkey)
+		}
+		if peerPoolItem.Status == BlackStatus {
+			return fmt.Errorf("peerPubkey: %s is already blacked", peerPubkey)
+		}
+	}
+	return nil
+}
+
+//blackPeers puts peers into black list and removes them from consensus at once
+func blackPeers(native *native.NativeService, peerPoolMap *PeerPoolMap, view uint32, peerPubkeyList []string) error {
+	contract := utils.NodeManagerContractAddress
+	commit := false
+	for _, peerPubkey := range peerPubkeyList {
+		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+		if err != nil {
+			return fmt.Errorf("peerPubkey format error: %v", err)
+		}
+		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
+		if !ok {
+			return fmt.Errorf("peerPubkey is not in peerPoolMap")
+		}
+
+		blackListItem := &BlackListItem{
+			PeerPubkey: peerPoolItem.PeerPubkey,
+			Address:    peerPoolItem.Address,
+		}
+		sink := common.NewZeroCopySink(nil)
+		blackListItem.Serialization(sink)
+		//put peer into black list
+		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
+
+		//change peerPool status
+		if peerPoolItem.Status == ConsensusStatus {
+			commit = true
+		}
+		peerPoolItem.Status = BlackStatus
+		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+	}
+	putPeerPoolMap(native, peerPoolMap, view)
+
+	//commitDpos
+	if commit {
+		if err := executeCommitDpos(native); err != nil {
+			return fmt.Errorf("executeCommitDpos error: %v", err)
+		}
+	}
+	return nil
+}
+
 func executeCommitDpos(native *native.NativeService) error {
 	governanceView, err := GetGovernanceView(native)
 	if err != nil {
@@ -40,6 +117,15 @@ func executeCommitDpos(native *native.NativeService) error {
 	if err != nil {
 		return fmt.Errorf("executeCommitDpos, get peerPoolMap error: %v", err)
 	}
+	stakingConfig, err := GetStakingConfig(native)
+	if err != nil {
+		return fmt.Errorf("executeCommitDpos, %v", err)
+	}
+	if stakingConfig != nil {
+		if err := distributeRewards(native, peerPoolMap); err != nil {
+			return fmt.Errorf("executeCommitDpos, distributeRewards error: %v", err)
+		}
+	}
 
 	for k, peerPoolItem := range peerPoolMap.PeerPoolMap {
 		if peerPoolItem.Status == QuitingStatus {
@@ -53,6 +139,11 @@ func executeCommitDpos(native *native.NativeService) error {
 			peerPoolMap.PeerPoolMap[k].Status = ConsensusStatus
 		}
 	}
+	if stakingConfig != nil {
+		if err := electPeers(native, stakingConfig, peerPoolMap); err != nil {
+			return fmt.Errorf("executeCommitDpos, electPeers error: %v", err)
+		}
+	}
 
 	putPeerPoolMap(native, peerPoolMap, newView)
 	oldView := view - 1
@@ -68,3 +159,105 @@ func executeCommitDpos(native *native.NativeService) error {
 	putGovernanceView(native, governanceView)
 	return nil
 }
+
+//distributeRewards splits the reward pool to the consensus peers of the ending view by their stake
+func distributeRewards(native *native.NativeService, peerPoolMap *PeerPoolMap) error {
+	pool, err := GetRewardPool(native)
+	if err != nil {
+		return err
+	}
+	if pool.Sign() == 0 {
+		return nil
+	}
+	validators := make([]*ValidatorStake, 0)
+	total := new(big.Int)
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Status != ConsensusStatus {
+			continue
+		}
+		validator, err := GetValidatorStake(native, peerPoolItem.PeerPubkey)
+		if err != nil {
+			return err
+		}
+		if validator == nil || validator.TotalStake.Sign() == 0 {
+			continue
+		}
+		validators = append(validators, validator)
+		total.Add(total, validator.TotalStake)
+	}
+	if total.Sign() == 0 {
+		return nil
+	}
+	distributed := new(big.Int)
+	for _, validator := range validators {
+		share := new(big.Int).Mul(pool, validator.TotalStake)
+		share.Div(share, total)
+		rewardPerShare := new(big.Int).Mul(share, REWARD_PRECISION)
+		rewardPerShare.Div(rewardPerShare, validator.TotalStake)
+		validator.RewardPerShare.Add(validator.RewardPerShare, rewardPerShare)
+		if err := putValidatorStake(native, validator); err != nil {
+			return err
+		}
+		distributed.Add(distributed, share)
+	}
+	putRewardPool(native, pool.Sub(pool, distributed))
+	return nil
+}
+
+type electItem struct {
+	peerPoolItem *PeerPoolItem
+	stake        *big.Int
+	eligible     bool
+}
+
+//electPeers makes the active peers with most stake consensus peers and the others standby, peers whose owner
+//bonds less than MinSelfStake are only elected when there are not MIN_PEER_NUM eligible peers
+func electPeers(native *native.NativeService, stakingConfig *StakingConfig, peerPoolMap *PeerPoolMap) error {
+	items := make([]*electItem, 0)
+	eligibleNum := 0
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if !isActivePeer(peerPoolItem) {
+			continue
+		}
+		validator, err := GetValidatorStake(native, peerPoolItem.PeerPubkey)
+		if err != nil {
+			return err
+		}
+		item := &electItem{peerPoolItem: peerPoolItem, stake: new(big.Int)}
+		if validator != nil {
+			item.stake = validator.TotalStake
+			item.eligible = validator.SelfStake.Cmp(stakingConfig.MinSelfStake) >= 0
+		} else {
+			item.eligible = stakingConfig.MinSelfStake.Sign() == 0
+		}
+		if item.eligible {
+			eligibleNum = eligibleNum + 1
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].eligible != items[j].eligible {
+			return items[i].eligible
+		}
+		if c := items[i].stake.Cmp(items[j].stake); c != 0 {
+			return c > 0
+		}
+		return items[i].peerPoolItem.Index < items[j].peerPoolItem.Index
+	})
+
+	num := eligibleNum
+	if num > int(stakingConfig.MaxValidators) {
+		num = int(stakingConfig.MaxValidators)
+	}
+	if num < MIN_PEER_NUM {
+		num = MIN_PEER_NUM
+	}
+	for i, item := range items {
+		if i < num {
+			item.peerPoolItem.Status = ConsensusStatus
+		} else {
+			item.peerPoolItem.Status = StandbyStatus
+		}
+	}
+	return nil
+}
@@ -20,6 +20,8 @@ package node_manager
 import (
 	"encoding/hex"
 	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/genesis"
@@ -35,16 +37,26 @@ const (
 	ConsensusStatus
 	QuitingStatus
 	BlackStatus
+	StandbyStatus //not elected in staking mode
 
 	//function name
 	REGISTER_CANDIDATE   = "registerCandidate"
 	UNREGISTER_CANDIDATE = "unRegisterCandidate"
 	APPROVE_CANDIDATE    = "approveCandidate"
 	BLACK_NODE           = "blackNode"
+	SUBMIT_EVIDENCE      = "submitEvidence"
 	WHITE_NODE           = "whiteNode"
 	QUIT_NODE            = "quitNode"
 	UPDATE_CONFIG        = "updateConfig"
 	COMMIT_DPOS          = "commitDpos"
+	SET_STAKING_CONFIG   = "setStakingConfig"
+	ALLOCATE_STAKE       = "allocateStake"
+	TRANSFER_STAKE       = "transferStake"
+	STAKE                = "stake"
+	UNSTAKE              = "unstake"
+	WITHDRAW_STAKE       = "withdrawStake"
+	WITHDRAW_REWARD      = "withdrawReward"
+	DEPOSIT_REWARD       = "depositReward"
 
 	//key prefix
 	GOVERNANCE_VIEW = "governanceView"
@@ -55,9 +67,16 @@ const (
 	PEER_INDEX      = "peerIndex"
 	BLACK_LIST      = "blackList"
 	CONSENSUS_SIGNS = "consensusSigns"
+	STAKING_CONFIG  = "stakingConfig"
+	STAKE_BALANCE   = "stakeBalance"
+	VALIDATOR_STAKE = "validatorStake"
+	DELEGATION      = "delegation"
+	UNBONDING       = "unbonding"
+	REWARD_POOL     = "rewardPool"
 
 	//const
-	MIN_PEER_NUM = 4
+	MIN_PEER_NUM     = 4
+	MAX_EVIDENCE_AGE = 1000 //evidence of consensus msgs older than this number of blocks is rejected
 )
 
 //Register methods of node_manager contract
@@ -68,9 +87,20 @@ func RegisterNodeManagerContract(native *native.NativeService) {
 	native.Register(QUIT_NODE, QuitNode)
 	native.Register(APPROVE_CANDIDATE, ApproveCandidate)
 	native.Register(BLACK_NODE, BlackNode)
+	native.Register(SUBMIT_EVIDENCE, SubmitEvidence)
 	native.Register(WHITE_NODE, WhiteNode)
 	native.Register(UPDATE_CONFIG, UpdateConfig)
 	native.Register(COMMIT_DPOS, CommitDpos)
+	if native.GetHeight() >= config.GetStakingHeight(config.DefConfig.P2PNode.NetworkId) {
+		native.Register(SET_STAKING_CONFIG, SetStakingConfig)
+		native.Register(ALLOCATE_STAKE, AllocateStake)
+		native.Register(TRANSFER_STAKE, TransferStake)
+		native.Register(STAKE, Stake)
+		native.Register(UNSTAKE, Unstake)
+		native.Register(WITHDRAW_STAKE, WithdrawStake)
+		native.Register(WITHDRAW_REWARD, WithdrawReward)
+		native.Register(DEPOSIT_REWARD, DepositReward)
+	}
 }
 
 //Init node_manager contract
@@ -363,7 +393,6 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("blackNode, contract params deserialize error: %v", err)
 	}
-	contract := utils.NodeManagerContractAddress
 
 	//check witness
 	err := utils.ValidateOwner(native, params.Address)
@@ -382,24 +411,8 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("blackNode, get peerPoolMap error: %v", err)
 	}
 
-	//check peers num
-	num := 0
-	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
-		if peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus {
-			num = num + 1
-		}
-	}
-	if num <= MIN_PEER_NUM+len(params.PeerPubkeyList)-1 {
-		return utils.BYTE_FALSE, fmt.Errorf("blackNode, num of peers is less than 4")
-	}
-	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
-		if !ok {
-			return utils.BYTE_FALSE, fmt.Errorf("blockNode, peerPubkey: %s is not in peerPoolMap", peerPubkey)
-		}
-		if peerPoolItem.Status == BlackStatus {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey: %s is already blacked", peerPubkey)
-		}
+	if err := checkBlackPeers(peerPoolMap, params.PeerPubkeyList); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackNode, %v", err)
 	}
 
 	input := []byte{}
@@ -415,46 +428,62 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_TRUE, nil
 	}
 
-	commit := false
-	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey format error: %v", err)
-		}
-		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
-		if !ok {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey is not in peerPoolMap")
-		}
+	if err := blackPeers(native, peerPoolMap, view, params.PeerPubkeyList); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackNode, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"blackNode", params.PeerPubkeyList},
+		})
+	return utils.BYTE_TRUE, nil
+}
 
-		blackListItem := &BlackListItem{
-			PeerPubkey: peerPoolItem.PeerPubkey,
-			Address:    peerPoolItem.Address,
-		}
-		sink := common.NewZeroCopySink(nil)
-		blackListItem.Serialization(sink)
-		//put peer into black list
-		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
-
-		//change peerPool status
-		if peerPoolItem.Status == ConsensusStatus {
-			commit = true
-		}
-		peerPoolItem.Status = BlackStatus
-		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+//Put a node signed conflicting consensus messages into black list, the evidence proves the misbehavior so
+//consensus signs are not needed
+func SubmitEvidence(native *native.NativeService) ([]byte, error) {
+	params := new(EquivocationEvidence)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, contract params deserialize error: %v", err)
+	}
+	msgA, msgB, err := params.Verify()
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, verify evidence error: %v", err)
+	}
+	if err := CheckEvidenceHeight(native.GetHeight(), msgA.Height); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, %v", err)
 	}
-	putPeerPoolMap(native, peerPoolMap, view)
 
-	//commitDpos
-	if commit {
-		err = executeCommitDpos(native)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, executeCommitDpos error: %v", err)
-		}
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, get peerPoolMap error: %v", err)
+	}
+	peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(msgA.Owner))
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, peerPubkey: %s is not in peerPoolMap", peerPubkey)
+	}
+	if peerPoolItem.Index != msgA.Author {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, msg author %d is not peer %s of index %d", msgA.Author,
+			peerPubkey, peerPoolItem.Index)
+	}
+	if err := checkBlackPeers(peerPoolMap, []string{peerPubkey}); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, %v", err)
+	}
+	if err := blackPeers(native, peerPoolMap, view, []string{peerPubkey}); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("submitEvidence, %v", err)
 	}
 	native.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"blackNode", params.PeerPubkeyList},
+			States: []interface{}{"equivocation", peerPubkey, msgA.Type, msgA.Height, msgA.Proposer,
+				msgA.Hash.ToHexString(), msgB.Hash.ToHexString()},
 		})
 	return utils.BYTE_TRUE, nil
 }
@@ -534,8 +563,8 @@ func QuitNode(native *native.NativeService) ([]byte, error) {
 	if !ok {
 		return utils.BYTE_FALSE, fmt.Errorf("quitNode, peerPubkey is not in peerPoolMap")
 	}
-	if peerPoolItem.Status != ConsensusStatus && peerPoolItem.Status != CandidateStatus {
-		return utils.BYTE_FALSE, fmt.Errorf("quitNode, peerPubkey is not CandidateStatus or ConsensusStatus")
+	if peerPoolItem.Status != ConsensusStatus && peerPoolItem.Status != CandidateStatus && peerPoolItem.Status != StandbyStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("quitNode, peerPubkey is not CandidateStatus, ConsensusStatus or StandbyStatus")
 	}
 	if params.Address != peerPoolItem.Address {
 		return utils.BYTE_FALSE, fmt.Errorf("quitNode, peerPubkey is not registered by this address")
@@ -544,7 +573,7 @@ func QuitNode(native *native.NativeService) ([]byte, error) {
 	//check peers num
 	num := 0
 	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
-		if peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus {
+		if isActivePeer(peerPoolItem) {
 			num = num + 1
 		}
 	}
@@ -626,24 +655,43 @@ func UpdateConfig(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("updateConfig, checkWitness error: %v", err)
 	}
 
-	if params.Configuration.BlockMsgDelay < 5000 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. BlockMsgDelay must >= 5000")
+	if native.GetHeight() >= config.GetGovernanceProposalHeight(config.DefConfig.P2PNode.NetworkId) {
+		return utils.BYTE_FALSE, fmt.Errorf("updateConfig, config is updated through governance proposals")
 	}
-	if params.Configuration.HashMsgDelay < 5000 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. HashMsgDelay must >= 5000")
+
+	if err := SetConfig(native, params.Configuration); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. %v", err)
 	}
-	if params.Configuration.PeerHandshakeTimeout < 10 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. PeerHandshakeTimeout must >= 10")
+	return utils.BYTE_TRUE, nil
+}
+
+//CheckConfiguration checks the limits of vbft config
+func CheckConfiguration(configuration *Configuration) error {
+	if configuration.BlockMsgDelay < 5000 {
+		return fmt.Errorf("BlockMsgDelay must >= 5000")
+	}
+	if configuration.HashMsgDelay < 5000 {
+		return fmt.Errorf("HashMsgDelay must >= 5000")
+	}
+	if configuration.PeerHandshakeTimeout < 10 {
+		return fmt.Errorf("PeerHandshakeTimeout must >= 10")
 	}
-	if params.Configuration.MaxBlockChangeView < 10000 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. MaxBlockChangeView must >= 10000")
+	if configuration.MaxBlockChangeView < 10000 {
+		return fmt.Errorf("MaxBlockChangeView must >= 10000")
 	}
+	return nil
+}
 
-	putConfig(native, params.Configuration)
+//SetConfig checks and stores the vbft config
+func SetConfig(native *native.NativeService, configuration *Configuration) error {
+	if err := CheckConfiguration(configuration); err != nil {
+		return err
+	}
+	putConfig(native, configuration)
 	native.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"updateConfig", params.Configuration},
+			States:          []interface{}{"updateConfig", configuration},
 		})
-	return utils.BYTE_TRUE, nil
+	return nil
 }
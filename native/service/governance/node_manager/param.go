@@ -19,6 +19,8 @@ package node_manager
 
 import (
 	"fmt"
+	"math/big"
+
 	"github.com/polynetwork/poly/common"
 )
 
@@ -137,3 +139,193 @@ func (this *UpdateConfigParam) Deserialization(source *common.ZeroCopySource) er
 	this.Configuration = configuration
 	return nil
 }
+
+type StakingConfigParam struct {
+	StakingConfig *StakingConfig
+	Address       common.Address
+}
+
+func (this *StakingConfigParam) Serialization(sink *common.ZeroCopySink) {
+	this.StakingConfig.Serialization(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *StakingConfigParam) Deserialization(source *common.ZeroCopySource) error {
+	stakingConfig := new(StakingConfig)
+	if err := stakingConfig.Deserialization(source); err != nil {
+		return fmt.Errorf("stakingConfig.Deserialization, deserialize stakingConfig error: %s", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.StakingConfig = stakingConfig
+	this.Address = addr
+	return nil
+}
+
+type TransferStakeParam struct {
+	From   common.Address
+	To     common.Address
+	Amount *big.Int
+}
+
+func (this *TransferStakeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.From[:])
+	sink.WriteVarBytes(this.To[:])
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *TransferStakeParam) Deserialization(source *common.ZeroCopySource) error {
+	from, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize from error")
+	}
+	to, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize to error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize amount error")
+	}
+	fromAddr, err := common.AddressParseFromBytes(from)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize from error: %s", err)
+	}
+	toAddr, err := common.AddressParseFromBytes(to)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize to error: %s", err)
+	}
+	this.From = fromAddr
+	this.To = toAddr
+	this.Amount = new(big.Int).SetBytes(amount)
+	return nil
+}
+
+type StakeParam struct {
+	PeerPubkey string
+	Address    common.Address
+	Amount     *big.Int
+}
+
+func (this *StakeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *StakeParam) Deserialization(source *common.ZeroCopySource) error {
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize amount error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.PeerPubkey = peerPubkey
+	this.Address = addr
+	this.Amount = new(big.Int).SetBytes(amount)
+	return nil
+}
+
+type AddressParam struct {
+	Address common.Address
+}
+
+func (this *AddressParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *AddressParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.Address = addr
+	return nil
+}
+
+type AmountParam struct {
+	Address common.Address
+	Amount  *big.Int
+}
+
+func (this *AmountParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *AmountParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize amount error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.Address = addr
+	this.Amount = new(big.Int).SetBytes(amount)
+	return nil
+}
+
+type AllocateStakeParam struct {
+	To      common.Address
+	Amount  *big.Int
+	Address common.Address //consensus peer owner approving the allocation
+}
+
+func (this *AllocateStakeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.To[:])
+	sink.WriteVarBytes(this.Amount.Bytes())
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *AllocateStakeParam) Deserialization(source *common.ZeroCopySource) error {
+	to, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize to error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize amount error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	toAddr, err := common.AddressParseFromBytes(to)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize to error: %s", err)
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.To = toAddr
+	this.Amount = new(big.Int).SetBytes(amount)
+	this.Address = addr
+	return nil
+}
@@ -0,0 +1,502 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node_manager
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//REWARD_PRECISION is the multiplier of ValidatorStake.RewardPerShare
+var REWARD_PRECISION = big.NewInt(1e12)
+
+func checkStakingEnabled(native *native.NativeService) (*StakingConfig, error) {
+	stakingConfig, err := GetStakingConfig(native)
+	if err != nil {
+		return nil, err
+	}
+	if stakingConfig == nil {
+		return nil, fmt.Errorf("staking is not enabled")
+	}
+	return stakingConfig, nil
+}
+
+func checkAmount(amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+//Set the staking config, enable the staking mode if it is not enabled yet.
+//In staking mode consensus peers are elected by stake at commitDpos.
+func SetStakingConfig(native *native.NativeService) ([]byte, error) {
+	params := new(StakingConfigParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setStakingConfig, contract params deserialize error: %v", err)
+	}
+	stakingConfig := params.StakingConfig
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setStakingConfig, checkWitness error: %v", err)
+	}
+
+	if stakingConfig.MaxValidators < MIN_PEER_NUM {
+		return utils.BYTE_FALSE, fmt.Errorf("setStakingConfig, MaxValidators must >= %d", MIN_PEER_NUM)
+	}
+	if stakingConfig.UnbondingViews < 1 {
+		return utils.BYTE_FALSE, fmt.Errorf("setStakingConfig, UnbondingViews must >= 1")
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	stakingConfig.Serialization(sink)
+	ok, err := CheckConsensusSigns(native, SET_STAKING_CONFIG, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setStakingConfig, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putStakingConfig(native, stakingConfig)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States: []interface{}{"setStakingConfig", stakingConfig.MinSelfStake.String(), stakingConfig.MaxValidators,
+				stakingConfig.UnbondingViews},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Allocate stake to an address, approved by consensus peers
+func AllocateStake(native *native.NativeService) ([]byte, error) {
+	params := new(AllocateStakeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("allocateStake, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("allocateStake, checkWitness error: %v", err)
+	}
+	if _, err := checkStakingEnabled(native); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("allocateStake, %v", err)
+	}
+	if err := checkAmount(params.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("allocateStake, %v", err)
+	}
+
+	//check consensus signs
+	input := append(params.To[:], params.Amount.Bytes()...)
+	ok, err := CheckConsensusSigns(native, ALLOCATE_STAKE, input, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("allocateStake, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	balance, err := GetStakeBalance(native, params.To)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("allocateStake, %v", err)
+	}
+	putStakeBalance(native, params.To, balance.Add(balance, params.Amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"allocateStake", params.To.ToBase58(), params.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Transfer stake balance to another address
+func TransferStake(native *native.NativeService) ([]byte, error) {
+	params := new(TransferStakeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.From)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, checkWitness error: %v", err)
+	}
+	if _, err := checkStakingEnabled(native); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, %v", err)
+	}
+	if err := checkAmount(params.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, %v", err)
+	}
+
+	fromBalance, err := GetStakeBalance(native, params.From)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, %v", err)
+	}
+	if fromBalance.Cmp(params.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, balance %s is not enough", fromBalance.String())
+	}
+	putStakeBalance(native, params.From, fromBalance.Sub(fromBalance, params.Amount))
+	toBalance, err := GetStakeBalance(native, params.To)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("transferStake, %v", err)
+	}
+	putStakeBalance(native, params.To, toBalance.Add(toBalance, params.Amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"transferStake", params.From.ToBase58(), params.To.ToBase58(), params.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Bond stake to a peer, stake of the peer owner is its self stake, stake of others is delegation.
+//Pending reward of the address on the peer is settled to its balance.
+func Stake(native *native.NativeService) ([]byte, error) {
+	params := new(StakeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, checkWitness error: %v", err)
+	}
+	if _, err := checkStakingEnabled(native); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+	if err := checkAmount(params.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, get peerPoolMap error: %v", err)
+	}
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok || !isActivePeer(peerPoolItem) {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, peerPubkey is not an active peer in peerPoolMap")
+	}
+
+	balance, err := GetStakeBalance(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+	if balance.Cmp(params.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, balance %s is not enough", balance.String())
+	}
+	validator, err := GetValidatorStake(native, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+	if validator == nil {
+		validator = NewValidatorStake(params.PeerPubkey, peerPoolItem.Address)
+	}
+	delegation, err := GetDelegation(native, params.PeerPubkey, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+
+	balance.Sub(balance, params.Amount)
+	balance.Add(balance, delegation.PendingReward(validator))
+	delegation.Amount.Add(delegation.Amount, params.Amount)
+	delegation.RewardDebt = validator.RewardOf(delegation.Amount)
+	validator.TotalStake.Add(validator.TotalStake, params.Amount)
+	if params.Address == validator.Owner {
+		validator.SelfStake.Add(validator.SelfStake, params.Amount)
+	}
+
+	putStakeBalance(native, params.Address, balance)
+	if err := putDelegation(native, params.PeerPubkey, params.Address, delegation); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+	if err := putValidatorStake(native, validator); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("stake, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"stake", params.PeerPubkey, params.Address.ToBase58(), params.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Unbond stake from a peer, the amount can be withdrawn after UnbondingViews views.
+//Pending reward of the address on the peer is settled to its balance.
+func Unstake(native *native.NativeService) ([]byte, error) {
+	params := new(StakeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, checkWitness error: %v", err)
+	}
+	stakingConfig, err := checkStakingEnabled(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+	if err := checkAmount(params.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+
+	validator, err := GetValidatorStake(native, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+	if validator == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, peerPubkey is not staked")
+	}
+	delegation, err := GetDelegation(native, params.PeerPubkey, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+	if delegation.Amount.Cmp(params.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, stake %s is not enough", delegation.Amount.String())
+	}
+	balance, err := GetStakeBalance(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, get view error: %v", err)
+	}
+	unbondingList, err := GetUnbondingList(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+
+	balance.Add(balance, delegation.PendingReward(validator))
+	delegation.Amount.Sub(delegation.Amount, params.Amount)
+	delegation.RewardDebt = validator.RewardOf(delegation.Amount)
+	validator.TotalStake.Sub(validator.TotalStake, params.Amount)
+	if params.Address == validator.Owner {
+		validator.SelfStake.Sub(validator.SelfStake, params.Amount)
+	}
+	releaseView := view + stakingConfig.UnbondingViews
+	unbondingList.Items = append(unbondingList.Items, &UnbondingItem{
+		Amount:      new(big.Int).Set(params.Amount),
+		ReleaseView: releaseView,
+	})
+
+	putStakeBalance(native, params.Address, balance)
+	if err := putDelegation(native, params.PeerPubkey, params.Address, delegation); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+	if err := putValidatorStake(native, validator); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unstake, %v", err)
+	}
+	putUnbondingList(native, params.Address, unbondingList)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States: []interface{}{"unstake", params.PeerPubkey, params.Address.ToBase58(), params.Amount.String(),
+				releaseView},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Withdraw the unbonding stake whose release view is reached to the balance
+func WithdrawStake(native *native.NativeService) ([]byte, error) {
+	params := new(AddressParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawStake, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawStake, checkWitness error: %v", err)
+	}
+
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawStake, get view error: %v", err)
+	}
+	unbondingList, err := GetUnbondingList(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawStake, %v", err)
+	}
+	amount := new(big.Int)
+	items := make([]*UnbondingItem, 0)
+	for _, item := range unbondingList.Items {
+		if item.ReleaseView <= view {
+			amount.Add(amount, item.Amount)
+		} else {
+			items = append(items, item)
+		}
+	}
+	if amount.Sign() == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawStake, no unbonding stake is released")
+	}
+	unbondingList.Items = items
+
+	balance, err := GetStakeBalance(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawStake, %v", err)
+	}
+	putStakeBalance(native, params.Address, balance.Add(balance, amount))
+	putUnbondingList(native, params.Address, unbondingList)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"withdrawStake", params.Address.ToBase58(), amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Settle the pending reward of an address on a peer to its balance
+func WithdrawReward(native *native.NativeService) ([]byte, error) {
+	params := new(PeerParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, checkWitness error: %v", err)
+	}
+
+	validator, err := GetValidatorStake(native, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, %v", err)
+	}
+	if validator == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, peerPubkey is not staked")
+	}
+	delegation, err := GetDelegation(native, params.PeerPubkey, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, %v", err)
+	}
+	reward := delegation.PendingReward(validator)
+	if reward.Sign() == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, no pending reward")
+	}
+	delegation.RewardDebt = validator.RewardOf(delegation.Amount)
+
+	balance, err := GetStakeBalance(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, %v", err)
+	}
+	putStakeBalance(native, params.Address, balance.Add(balance, reward))
+	if err := putDelegation(native, params.PeerPubkey, params.Address, delegation); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("withdrawReward, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"withdrawReward", params.PeerPubkey, params.Address.ToBase58(), reward.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Deposit collected fees into the reward pool, the pool is split to the stake of consensus peers at commitDpos
+func DepositReward(native *native.NativeService) ([]byte, error) {
+	params := new(AmountParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, checkWitness error: %v", err)
+	}
+	if _, err := checkStakingEnabled(native); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, %v", err)
+	}
+	if err := checkAmount(params.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, %v", err)
+	}
+
+	balance, err := GetStakeBalance(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, %v", err)
+	}
+	if balance.Cmp(params.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, balance %s is not enough", balance.String())
+	}
+	pool, err := GetRewardPool(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("depositReward, %v", err)
+	}
+	putStakeBalance(native, params.Address, balance.Sub(balance, params.Amount))
+	putRewardPool(native, pool.Add(pool, params.Amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"depositReward", params.Address.ToBase58(), params.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//ChargeGasFee deducts the gas fee from the stake balance of the payer of the transaction into the reward pool, it is
+//the native.GasFeeCharger. Nothing is charged if staking is not enabled.
+func ChargeGasFee(native *native.NativeService, fee *big.Int, partial bool) (*big.Int, error) {
+	if fee.Sign() <= 0 || native.GetHeight() < config.GetStakingHeight(config.DefConfig.P2PNode.NetworkId) {
+		return new(big.Int), nil
+	}
+	stakingConfig, err := GetStakingConfig(native)
+	if err != nil {
+		return nil, fmt.Errorf("ChargeGasFee, %v", err)
+	}
+	if stakingConfig == nil {
+		return new(big.Int), nil
+	}
+	payer := native.GetTx().Payer
+	if err := utils.ValidateOwner(native, payer); err != nil {
+		return nil, fmt.Errorf("ChargeGasFee, checkWitness of payer error: %v", err)
+	}
+	balance, err := GetStakeBalance(native, payer)
+	if err != nil {
+		return nil, fmt.Errorf("ChargeGasFee, %v", err)
+	}
+	if balance.Cmp(fee) < 0 {
+		if !partial {
+			return nil, fmt.Errorf("ChargeGasFee, balance %s of payer is less than gas fee %s", balance.String(), fee.String())
+		}
+		fee = new(big.Int).Set(balance)
+	}
+	pool, err := GetRewardPool(native)
+	if err != nil {
+		return nil, fmt.Errorf("ChargeGasFee, %v", err)
+	}
+	putStakeBalance(native, payer, balance.Sub(balance, fee))
+	putRewardPool(native, pool.Add(pool, fee))
+	return fee, nil
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node_manager
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type serializable interface {
+	Serialization(sink *common.ZeroCopySink)
+}
+
+func newNative(db *storage.CacheDB, height uint32, signer common.Address, params serializable) *native.NativeService {
+	var input []byte
+	if params != nil {
+		sink := common.NewZeroCopySink(nil)
+		params.Serialization(sink)
+		input = sink.Bytes()
+	}
+	tx := &types.Transaction{SignedAddr: []common.Address{signer}, Nonce: height}
+	ns, _ := native.NewNativeService(db, tx, 0, height, common.Uint256{}, 0, input, false)
+	return ns
+}
+
+//preparePeers puts 6 peers in view 1, the first 4 are consensus peers
+func preparePeers(t *testing.T) (*storage.CacheDB, []*account.Account, []string) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns := newNative(db, 0, common.ADDRESS_EMPTY, nil)
+
+	accs := make([]*account.Account, 0)
+	peers := make([]string, 0)
+	peerPoolMap := &PeerPoolMap{PeerPoolMap: make(map[string]*PeerPoolItem)}
+	for i := 0; i < 6; i++ {
+		acc := account.NewAccount("SHA256withECDSA")
+		peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey))
+		status := ConsensusStatus
+		if i >= 4 {
+			status = CandidateStatus
+		}
+		peerPoolMap.PeerPoolMap[peerPubkey] = &PeerPoolItem{
+			Index:      uint32(i + 1),
+			PeerPubkey: peerPubkey,
+			Address:    acc.Address,
+			Status:     status,
+		}
+		accs = append(accs, acc)
+		peers = append(peers, peerPubkey)
+	}
+	putPeerPoolMap(ns, peerPoolMap, 1)
+	putGovernanceView(ns, &GovernanceView{View: 1})
+	return db, accs, peers
+}
+
+func getStatus(t *testing.T, db *storage.CacheDB, view uint32, peers []string) []Status {
+	peerPoolMap, err := GetPeerPoolMap(newNative(db, 0, common.ADDRESS_EMPTY, nil), view)
+	assert.Nil(t, err)
+	status := make([]Status, 0)
+	for _, peer := range peers {
+		status = append(status, peerPoolMap.PeerPoolMap[peer].Status)
+	}
+	return status
+}
+
+func getBalance(t *testing.T, db *storage.CacheDB, address common.Address) int64 {
+	balance, err := GetStakeBalance(newNative(db, 0, common.ADDRESS_EMPTY, nil), address)
+	assert.Nil(t, err)
+	return balance.Int64()
+}
+
+func TestCommitDposWithoutStaking(t *testing.T) {
+	db, _, peers := preparePeers(t)
+	assert.Nil(t, executeCommitDpos(newNative(db, 1, common.ADDRESS_EMPTY, nil)))
+	for _, status := range getStatus(t, db, 2, peers) {
+		assert.Equal(t, ConsensusStatus, status)
+	}
+
+	//staking methods are not available
+	_, err := Stake(newNative(db, 2, common.ADDRESS_EMPTY, &StakeParam{PeerPubkey: peers[0], Amount: big.NewInt(1)}))
+	assert.NotNil(t, err)
+}
+
+func TestStakingHeight(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+	}()
+	db, accs, peers := preparePeers(t)
+	native.Contracts[utils.NodeManagerContractAddress] = RegisterNodeManagerContract
+	invokeStake := func() error {
+		args := common.NewZeroCopySink(nil)
+		(&StakeParam{peers[0], accs[0].Address, big.NewInt(1)}).Serialization(args)
+		sink := common.NewZeroCopySink(nil)
+		param := &states.ContractInvokeParam{Address: utils.NodeManagerContractAddress, Method: STAKE, Args: args.Bytes()}
+		param.Serialization(sink)
+		tx := &types.Transaction{SignedAddr: []common.Address{accs[0].Address}}
+		ns, _ := native.NewNativeService(db, tx, 0, 10, common.Uint256{}, 0, sink.Bytes(), false)
+		_, err := ns.Invoke()
+		return err
+	}
+
+	//staking methods are not registered before the staking height
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	err := invokeStake()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "doesn't support this function")
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	err = invokeStake()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "staking is not enabled")
+}
+
+func TestStaking(t *testing.T) {
+	db, accs, peers := preparePeers(t)
+	delegator := account.NewAccount("")
+	ns := newNative(db, 0, common.ADDRESS_EMPTY, nil)
+	putStakingConfig(ns, &StakingConfig{MinSelfStake: big.NewInt(100), MaxValidators: 4, UnbondingViews: 1})
+	for _, acc := range append(accs, delegator) {
+		putStakeBalance(ns, acc.Address, big.NewInt(1000))
+	}
+
+	//owners of peer 3 to 6 bond self stake, peer 6 has delegation
+	for i := 2; i < 6; i++ {
+		_, err := Stake(newNative(db, 0, accs[i].Address, &StakeParam{peers[i], accs[i].Address, big.NewInt(100)}))
+		assert.Nil(t, err)
+	}
+	_, err := Stake(newNative(db, 0, delegator.Address, &StakeParam{peers[5], delegator.Address, big.NewInt(300)}))
+	assert.Nil(t, err)
+	_, err = Stake(newNative(db, 0, delegator.Address, &StakeParam{peers[5], delegator.Address, big.NewInt(1000)}))
+	assert.NotNil(t, err)
+	_, err = Stake(newNative(db, 0, accs[0].Address, &StakeParam{peers[5], delegator.Address, big.NewInt(1)}))
+	assert.NotNil(t, err)
+	validator, err := GetValidatorStake(newNative(db, 0, common.ADDRESS_EMPTY, nil), peers[5])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), validator.SelfStake.Int64())
+	assert.Equal(t, int64(400), validator.TotalStake.Int64())
+
+	_, err = DepositReward(newNative(db, 0, delegator.Address, &AmountParam{delegator.Address, big.NewInt(101)}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(599), getBalance(t, db, delegator.Address))
+
+	//reward is split to consensus peer 3 and 4, peer 1 and 2 have no self stake and are not elected
+	assert.Nil(t, executeCommitDpos(newNative(db, 1, common.ADDRESS_EMPTY, nil)))
+	assert.Equal(t, []Status{StandbyStatus, StandbyStatus, ConsensusStatus, ConsensusStatus, ConsensusStatus,
+		ConsensusStatus}, getStatus(t, db, 2, peers))
+	pool, err := GetRewardPool(newNative(db, 1, common.ADDRESS_EMPTY, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), pool.Int64())
+
+	_, err = WithdrawReward(newNative(db, 1, accs[2].Address, &PeerParam{peers[2], accs[2].Address}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(950), getBalance(t, db, accs[2].Address))
+	_, err = WithdrawReward(newNative(db, 1, accs[2].Address, &PeerParam{peers[2], accs[2].Address}))
+	assert.NotNil(t, err)
+	_, err = WithdrawReward(newNative(db, 1, delegator.Address, &PeerParam{peers[5], delegator.Address}))
+	assert.NotNil(t, err)
+
+	//unstaked amount is locked for an epoch, and the peer without self stake is only elected to keep 4 consensus peers
+	_, err = Unstake(newNative(db, 1, accs[5].Address, &StakeParam{peers[5], accs[5].Address, big.NewInt(100)}))
+	assert.Nil(t, err)
+	_, err = WithdrawStake(newNative(db, 1, accs[5].Address, &AddressParam{accs[5].Address}))
+	assert.NotNil(t, err)
+	assert.Nil(t, executeCommitDpos(newNative(db, 2, common.ADDRESS_EMPTY, nil)))
+	assert.Equal(t, []Status{StandbyStatus, StandbyStatus, ConsensusStatus, ConsensusStatus, ConsensusStatus,
+		ConsensusStatus}, getStatus(t, db, 3, peers))
+	_, err = Stake(newNative(db, 2, accs[0].Address, &StakeParam{peers[0], accs[0].Address, big.NewInt(100)}))
+	assert.Nil(t, err)
+	assert.Nil(t, executeCommitDpos(newNative(db, 3, common.ADDRESS_EMPTY, nil)))
+	assert.Equal(t, []Status{ConsensusStatus, StandbyStatus, ConsensusStatus, ConsensusStatus, ConsensusStatus,
+		StandbyStatus}, getStatus(t, db, 4, peers))
+	_, err = WithdrawStake(newNative(db, 2, accs[5].Address, &AddressParam{accs[5].Address}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), getBalance(t, db, accs[5].Address))
+	unbondingList, err := GetUnbondingList(newNative(db, 2, common.ADDRESS_EMPTY, nil), accs[5].Address)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(unbondingList.Items))
+}
+
+func TestChargeGasFee(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+	}()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	db, accs, _ := preparePeers(t)
+	payer := accs[0].Address
+	newPayerNative := func(signer common.Address) *native.NativeService {
+		tx := &types.Transaction{SignedAddr: []common.Address{signer}, Payer: payer}
+		ns, _ := native.NewNativeService(db, tx, 0, 1, common.Uint256{}, 0, nil, false)
+		return ns
+	}
+
+	//nothing is charged if staking is not enabled
+	fee, err := ChargeGasFee(newPayerNative(accs[1].Address), big.NewInt(10), false)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), fee.Int64())
+
+	ns := newNative(db, 0, common.ADDRESS_EMPTY, nil)
+	putStakingConfig(ns, &StakingConfig{MinSelfStake: big.NewInt(100), MaxValidators: 4, UnbondingViews: 1})
+	putStakeBalance(ns, payer, big.NewInt(100))
+	_, err = ChargeGasFee(newPayerNative(accs[1].Address), big.NewInt(10), false)
+	assert.NotNil(t, err)
+	fee, err = ChargeGasFee(newPayerNative(payer), big.NewInt(30), false)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(30), fee.Int64())
+	assert.Equal(t, int64(70), getBalance(t, db, payer))
+
+	//fee over the balance is refused, or charged up to the balance for failed transaction
+	_, err = ChargeGasFee(newPayerNative(payer), big.NewInt(80), false)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(70), getBalance(t, db, payer))
+	fee, err = ChargeGasFee(newPayerNative(payer), big.NewInt(80), true)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(70), fee.Int64())
+	assert.Equal(t, int64(0), getBalance(t, db, payer))
+	pool, err := GetRewardPool(newNative(db, 1, common.ADDRESS_EMPTY, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), pool.Int64())
+}
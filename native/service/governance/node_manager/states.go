@@ -20,6 +20,7 @@ package node_manager
 import (
 	"fmt"
 	"io"
+	"math/big"
 	"sort"
 
 	"github.com/polynetwork/poly/common"
@@ -261,3 +262,178 @@ func (this *Configuration) Deserialization(source *common.ZeroCopySource) error
 	this.MaxBlockChangeView = maxBlockChangeView
 	return nil
 }
+
+//StakingConfig enables the staking mode, consensus peers are elected by stake at commitDpos once it is set
+type StakingConfig struct {
+	MinSelfStake   *big.Int //min stake bonded by the peer owner to be elected
+	MaxValidators  uint32   //max num of consensus peers elected
+	UnbondingViews uint32   //num of views unstaked amount is locked before it can be withdrawn
+}
+
+func (this *StakingConfig) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.MinSelfStake.Bytes())
+	sink.WriteUint32(this.MaxValidators)
+	sink.WriteUint32(this.UnbondingViews)
+}
+
+func (this *StakingConfig) Deserialization(source *common.ZeroCopySource) error {
+	minSelfStake, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize minSelfStake error")
+	}
+	maxValidators, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize maxValidators error")
+	}
+	unbondingViews, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize unbondingViews error")
+	}
+	this.MinSelfStake = new(big.Int).SetBytes(minSelfStake)
+	this.MaxValidators = maxValidators
+	this.UnbondingViews = unbondingViews
+	return nil
+}
+
+//ValidatorStake is the stake bonded to a peer, RewardPerShare is the accumulated reward of each staked unit
+//multiplied by REWARD_PRECISION
+type ValidatorStake struct {
+	PeerPubkey     string
+	Owner          common.Address
+	SelfStake      *big.Int
+	TotalStake     *big.Int
+	RewardPerShare *big.Int
+}
+
+func NewValidatorStake(peerPubkey string, owner common.Address) *ValidatorStake {
+	return &ValidatorStake{
+		PeerPubkey:     peerPubkey,
+		Owner:          owner,
+		SelfStake:      new(big.Int),
+		TotalStake:     new(big.Int),
+		RewardPerShare: new(big.Int),
+	}
+}
+
+//RewardOf returns the reward accumulated by amount of stake since the peer is staked
+func (this *ValidatorStake) RewardOf(amount *big.Int) *big.Int {
+	reward := new(big.Int).Mul(amount, this.RewardPerShare)
+	return reward.Div(reward, REWARD_PRECISION)
+}
+
+func (this *ValidatorStake) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteVarBytes(this.Owner[:])
+	sink.WriteVarBytes(this.SelfStake.Bytes())
+	sink.WriteVarBytes(this.TotalStake.Bytes())
+	sink.WriteVarBytes(this.RewardPerShare.Bytes())
+}
+
+func (this *ValidatorStake) Deserialization(source *common.ZeroCopySource) error {
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+	}
+	owner, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize owner error")
+	}
+	selfStake, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize selfStake error")
+	}
+	totalStake, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize totalStake error")
+	}
+	rewardPerShare, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize rewardPerShare error")
+	}
+	addr, err := common.AddressParseFromBytes(owner)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize owner error: %s", err)
+	}
+	this.PeerPubkey = peerPubkey
+	this.Owner = addr
+	this.SelfStake = new(big.Int).SetBytes(selfStake)
+	this.TotalStake = new(big.Int).SetBytes(totalStake)
+	this.RewardPerShare = new(big.Int).SetBytes(rewardPerShare)
+	return nil
+}
+
+//Delegation is the stake of an address on a peer, RewardDebt is the reward already settled for Amount
+type Delegation struct {
+	Amount     *big.Int
+	RewardDebt *big.Int
+}
+
+func NewDelegation() *Delegation {
+	return &Delegation{
+		Amount:     new(big.Int),
+		RewardDebt: new(big.Int),
+	}
+}
+
+func (this *Delegation) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Amount.Bytes())
+	sink.WriteVarBytes(this.RewardDebt.Bytes())
+}
+
+func (this *Delegation) Deserialization(source *common.ZeroCopySource) error {
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize amount error")
+	}
+	rewardDebt, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize rewardDebt error")
+	}
+	this.Amount = new(big.Int).SetBytes(amount)
+	this.RewardDebt = new(big.Int).SetBytes(rewardDebt)
+	return nil
+}
+
+//PendingReward returns the reward of the delegation not settled yet
+func (this *Delegation) PendingReward(validator *ValidatorStake) *big.Int {
+	reward := validator.RewardOf(this.Amount)
+	return reward.Sub(reward, this.RewardDebt)
+}
+
+type UnbondingItem struct {
+	Amount      *big.Int
+	ReleaseView uint32 //view from which the amount can be withdrawn
+}
+
+type UnbondingList struct {
+	Items []*UnbondingItem
+}
+
+func (this *UnbondingList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		sink.WriteVarBytes(v.Amount.Bytes())
+		sink.WriteUint32(v.ReleaseView)
+	}
+}
+
+func (this *UnbondingList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize UnbondingList length error")
+	}
+	items := make([]*UnbondingItem, 0)
+	for i := 0; uint64(i) < n; i++ {
+		amount, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize amount error")
+		}
+		releaseView, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize releaseView error")
+		}
+		items = append(items, &UnbondingItem{Amount: new(big.Int).SetBytes(amount), ReleaseView: releaseView})
+	}
+	this.Items = items
+	return nil
+}
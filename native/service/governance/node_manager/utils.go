@@ -22,6 +22,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math/big"
+
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/ontio/ontology-crypto/keypair"
@@ -268,6 +270,19 @@ func deleteConsensusSigns(native *native.NativeService, key common.Uint256) {
 	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(CONSENSUS_SIGNS), key.ToArray()))
 }
 
+//TakeConsensusSigns returns the signers collected by CheckConsensusSigns for method and input so far, and clears
+//the record so the signs can not be counted twice.
+func TakeConsensusSigns(native *native.NativeService, method string, input []byte) (map[common.Address]bool, error) {
+	message := append([]byte(method), input...)
+	key := sha256.Sum256(message)
+	consensusSigns, err := getConsensusSigns(native, key)
+	if err != nil {
+		return nil, fmt.Errorf("TakeConsensusSigns, GetConsensusSigns error: %v", err)
+	}
+	deleteConsensusSigns(native, key)
+	return consensusSigns.SignsMap, nil
+}
+
 func CheckConsensusSigns(native *native.NativeService, method string, input []byte, address common.Address) (bool, error) {
 	message := append([]byte(method), input...)
 	key := sha256.Sum256(message)
@@ -354,3 +369,192 @@ func GetCurConOperator(native *native.NativeService) (common.Address, error) {
 	}
 	return operator, nil
 }
+
+//StakeBalanceKey return the storage key of the stake balance of address, without contract address
+func StakeBalanceKey(address common.Address) []byte {
+	return append([]byte(STAKE_BALANCE), address[:]...)
+}
+
+//ValidatorStakeKey return the storage key of the stake bonded to peer, without contract address
+func ValidatorStakeKey(peerPubkey string) ([]byte, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("peerPubkey format error: %v", err)
+	}
+	return append([]byte(VALIDATOR_STAKE), peerPubkeyPrefix...), nil
+}
+
+//DelegationKey return the storage key of the stake of address on peer, without contract address
+func DelegationKey(peerPubkey string, address common.Address) ([]byte, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("peerPubkey format error: %v", err)
+	}
+	return append(append([]byte(DELEGATION), peerPubkeyPrefix...), address[:]...), nil
+}
+
+//UnbondingKey return the storage key of the unbonding stake of address, without contract address
+func UnbondingKey(address common.Address) []byte {
+	return append([]byte(UNBONDING), address[:]...)
+}
+
+func getValue(native *native.NativeService, key []byte) ([]byte, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, key))
+	if err != nil {
+		return nil, fmt.Errorf("get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize from raw storage item error: %v", err)
+	}
+	return value, nil
+}
+
+func putValue(native *native.NativeService, key []byte, value []byte) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, key), cstates.GenRawStorageItem(value))
+}
+
+//GetStakingConfig return the staking config, nil if staking mode is not enabled
+func GetStakingConfig(native *native.NativeService) (*StakingConfig, error) {
+	value, err := getValue(native, []byte(STAKING_CONFIG))
+	if err != nil {
+		return nil, fmt.Errorf("GetStakingConfig, %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	stakingConfig := new(StakingConfig)
+	if err := stakingConfig.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetStakingConfig, deserialize stakingConfig error: %v", err)
+	}
+	return stakingConfig, nil
+}
+
+func putStakingConfig(native *native.NativeService, stakingConfig *StakingConfig) {
+	sink := common.NewZeroCopySink(nil)
+	stakingConfig.Serialization(sink)
+	putValue(native, []byte(STAKING_CONFIG), sink.Bytes())
+}
+
+//GetStakeBalance return the stake balance of address which is not bonded to any peer
+func GetStakeBalance(native *native.NativeService, address common.Address) (*big.Int, error) {
+	value, err := getValue(native, StakeBalanceKey(address))
+	if err != nil {
+		return nil, fmt.Errorf("GetStakeBalance, %v", err)
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+func putStakeBalance(native *native.NativeService, address common.Address, balance *big.Int) {
+	if balance.Sign() == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, StakeBalanceKey(address)))
+		return
+	}
+	putValue(native, StakeBalanceKey(address), balance.Bytes())
+}
+
+//GetValidatorStake return the stake bonded to peer, nil if the peer is never staked
+func GetValidatorStake(native *native.NativeService, peerPubkey string) (*ValidatorStake, error) {
+	key, err := ValidatorStakeKey(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("GetValidatorStake, %v", err)
+	}
+	value, err := getValue(native, key)
+	if err != nil {
+		return nil, fmt.Errorf("GetValidatorStake, %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	validator := new(ValidatorStake)
+	if err := validator.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetValidatorStake, deserialize validatorStake error: %v", err)
+	}
+	return validator, nil
+}
+
+func putValidatorStake(native *native.NativeService, validator *ValidatorStake) error {
+	key, err := ValidatorStakeKey(validator.PeerPubkey)
+	if err != nil {
+		return fmt.Errorf("putValidatorStake, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	validator.Serialization(sink)
+	putValue(native, key, sink.Bytes())
+	return nil
+}
+
+//GetDelegation return the stake of address on peer, a zero delegation if address has no stake on it
+func GetDelegation(native *native.NativeService, peerPubkey string, address common.Address) (*Delegation, error) {
+	key, err := DelegationKey(peerPubkey, address)
+	if err != nil {
+		return nil, fmt.Errorf("GetDelegation, %v", err)
+	}
+	value, err := getValue(native, key)
+	if err != nil {
+		return nil, fmt.Errorf("GetDelegation, %v", err)
+	}
+	delegation := NewDelegation()
+	if value != nil {
+		if err := delegation.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, fmt.Errorf("GetDelegation, deserialize delegation error: %v", err)
+		}
+	}
+	return delegation, nil
+}
+
+func putDelegation(native *native.NativeService, peerPubkey string, address common.Address, delegation *Delegation) error {
+	key, err := DelegationKey(peerPubkey, address)
+	if err != nil {
+		return fmt.Errorf("putDelegation, %v", err)
+	}
+	if delegation.Amount.Sign() == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, key))
+		return nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	delegation.Serialization(sink)
+	putValue(native, key, sink.Bytes())
+	return nil
+}
+
+//GetUnbondingList return the unstaked amounts of address waiting to be withdrawn
+func GetUnbondingList(native *native.NativeService, address common.Address) (*UnbondingList, error) {
+	value, err := getValue(native, UnbondingKey(address))
+	if err != nil {
+		return nil, fmt.Errorf("GetUnbondingList, %v", err)
+	}
+	unbondingList := new(UnbondingList)
+	if value != nil {
+		if err := unbondingList.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, fmt.Errorf("GetUnbondingList, deserialize unbondingList error: %v", err)
+		}
+	}
+	return unbondingList, nil
+}
+
+func putUnbondingList(native *native.NativeService, address common.Address, unbondingList *UnbondingList) {
+	if len(unbondingList.Items) == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, UnbondingKey(address)))
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	unbondingList.Serialization(sink)
+	putValue(native, UnbondingKey(address), sink.Bytes())
+}
+
+//GetRewardPool return the deposited reward which is not distributed yet
+func GetRewardPool(native *native.NativeService) (*big.Int, error) {
+	value, err := getValue(native, []byte(REWARD_POOL))
+	if err != nil {
+		return nil, fmt.Errorf("GetRewardPool, %v", err)
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+func putRewardPool(native *native.NativeService, pool *big.Int) {
+	putValue(native, []byte(REWARD_POOL), pool.Bytes())
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type ProposeParam struct {
+	Type         ProposalType
+	Content      []byte
+	VotingPeriod uint32 //seconds of block time to vote, DEFAULT_VOTING_PERIOD if zero
+	Address      common.Address
+}
+
+func (this *ProposeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint8(uint8(this.Type))
+	sink.WriteVarBytes(this.Content)
+	sink.WriteUint32(this.VotingPeriod)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *ProposeParam) Deserialization(source *common.ZeroCopySource) error {
+	proposalType, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize type error")
+	}
+	content, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize content error")
+	}
+	votingPeriod, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize votingPeriod error")
+	}
+	address, err := readAddress(source)
+	if err != nil {
+		return err
+	}
+	this.Type = ProposalType(proposalType)
+	this.Content = content
+	this.VotingPeriod = votingPeriod
+	this.Address = address
+	return nil
+}
+
+type VoteParam struct {
+	ID      uint64
+	Approve bool
+	Address common.Address
+}
+
+func (this *VoteParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ID)
+	sink.WriteBool(this.Approve)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *VoteParam) Deserialization(source *common.ZeroCopySource) error {
+	id, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("source.NextUint64, deserialize id error")
+	}
+	approve, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("source.NextBool, deserialize approve error")
+	}
+	address, err := readAddress(source)
+	if err != nil {
+		return err
+	}
+	this.ID = id
+	this.Approve = approve
+	this.Address = address
+	return nil
+}
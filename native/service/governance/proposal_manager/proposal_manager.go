@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	//function name
+	PROPOSE = "propose"
+	VOTE    = "vote"
+
+	//key prefix
+	PROPOSAL        = "proposal"
+	PROPOSAL_ID     = "proposalID"
+	ACTIVE_PROPOSAL = "activeProposal"
+
+	//voting period in seconds of block time
+	MIN_VOTING_PERIOD     = 60 * 60
+	MAX_VOTING_PERIOD     = 30 * 24 * 60 * 60
+	DEFAULT_VOTING_PERIOD = 7 * 24 * 60 * 60
+)
+
+//Register methods of proposal_manager contract
+func RegisterProposalManagerContract(native *native.NativeService) {
+	native.Register(PROPOSE, Propose)
+	native.Register(VOTE, Vote)
+}
+
+func Propose(native *native.NativeService) ([]byte, error) {
+	params := new(ProposeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, contract params deserialize error: %v", err)
+	}
+	if !isProposalEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, governance proposals are not enabled yet")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, checkWitness error: %v", err)
+	}
+	voters, err := getConsensusVoters(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, %v", err)
+	}
+	if !voters[params.Address] {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, %s is not a consensus peer", params.Address.ToBase58())
+	}
+
+	info, err := GetProposalHandlerInfo(params.Type)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, %v", err)
+	}
+	votingPeriod := params.VotingPeriod
+	if votingPeriod == 0 {
+		votingPeriod = DEFAULT_VOTING_PERIOD
+	}
+	if votingPeriod < MIN_VOTING_PERIOD || votingPeriod > MAX_VOTING_PERIOD {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, voting period must be between %d and %d seconds",
+			MIN_VOTING_PERIOD, MAX_VOTING_PERIOD)
+	}
+	if info.Check != nil {
+		if err := info.Check(native, params.Content); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("Propose, check content of %s proposal error: %v", params.Type, err)
+		}
+	}
+	active, err := getActiveProposal(native, params.Type, params.Content)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, %v", err)
+	}
+	if active != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, proposal %d of the same content is active", active.ID)
+	}
+
+	proposal, err := newProposal(native, params.Type, params.Content, params.Address, votingPeriod)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, %v", err)
+	}
+	if _, err := castVote(native, proposal, params.Address, true, voters); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Propose, %v", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func Vote(native *native.NativeService) ([]byte, error) {
+	params := new(VoteParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, contract params deserialize error: %v", err)
+	}
+	if !isProposalEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, governance proposals are not enabled yet")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, checkWitness error: %v", err)
+	}
+	voters, err := getConsensusVoters(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, %v", err)
+	}
+	if !voters[params.Address] {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, %s is not a consensus peer", params.Address.ToBase58())
+	}
+
+	proposal, err := GetProposal(native, params.ID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, %v", err)
+	}
+	if proposal == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, proposal %d does not exist", params.ID)
+	}
+	if status := proposal.State(native.GetTime()); status != ActiveStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, proposal %d is %s", params.ID, status)
+	}
+	if _, err := castVote(native, proposal, params.Address, params.Approve, voters); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Vote, %v", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+//Approve votes yes for the proposal of the type and content on behalf of an approve method of another contract, and
+//return whether the proposal passed and got executed by its handler. The first vote makes the proposal, and votes
+//of addresses which are not consensus peers are ignored. Before the governance proposal height consensus signs of
+//method are collected as before, and the signs still open at the switch count as votes of the first proposal.
+func Approve(native *native.NativeService, proposalType ProposalType, method string, content []byte,
+	voter common.Address) (bool, error) {
+	info, err := GetProposalHandlerInfo(proposalType)
+	if err != nil {
+		return false, err
+	}
+	if !isProposalEnabled(native) {
+		ok, err := node_manager.CheckConsensusSigns(native, method, content, voter)
+		if err != nil || !ok {
+			return false, err
+		}
+		return true, execute(native, info, content)
+	}
+
+	voters, err := getConsensusVoters(native)
+	if err != nil {
+		return false, err
+	}
+	if !voters[voter] {
+		return false, nil
+	}
+	proposal, err := getActiveProposal(native, proposalType, content)
+	if err != nil {
+		return false, err
+	}
+	if proposal == nil {
+		if proposal, err = newProposal(native, proposalType, content, voter, DEFAULT_VOTING_PERIOD); err != nil {
+			return false, err
+		}
+		//carry over the consensus signs still open from before the switch
+		signs, err := node_manager.TakeConsensusSigns(native, method, content)
+		if err != nil {
+			return false, err
+		}
+		for signer := range signs {
+			if voters[signer] {
+				proposal.Votes[signer] = true
+			}
+		}
+	}
+	return castVote(native, proposal, voter, true, voters)
+}
+
+func newProposal(native *native.NativeService, proposalType ProposalType, content []byte, proposer common.Address,
+	votingPeriod uint32) (*Proposal, error) {
+	count, err := GetProposalCount(native)
+	if err != nil {
+		return nil, err
+	}
+	proposal := &Proposal{
+		ID:       count + 1,
+		Type:     proposalType,
+		Content:  content,
+		Proposer: proposer,
+		Height:   native.GetHeight(),
+		EndTime:  native.GetTime() + votingPeriod,
+		Status:   ActiveStatus,
+		Votes:    make(map[common.Address]bool),
+	}
+	putValue(native, []byte(PROPOSAL_ID), utils.GetUint64Bytes(proposal.ID))
+	putValue(native, activeProposalKey(proposalType, content), utils.GetUint64Bytes(proposal.ID))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.ProposalManagerContractAddress,
+			States:          []interface{}{"propose", proposal.ID, proposalType.String(), proposer.ToBase58(), proposal.EndTime},
+		})
+	return proposal, nil
+}
+
+//castVote records the vote of voter, and closes the proposal once the votes decide it. It return true if the
+//proposal passed and got executed.
+func castVote(native *native.NativeService, proposal *Proposal, voter common.Address, approve bool,
+	voters map[common.Address]bool) (bool, error) {
+	proposal.Votes[voter] = approve
+	yes, no, threshold := proposal.Tally(voters)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.ProposalManagerContractAddress,
+			States:          []interface{}{"vote", proposal.ID, voter.ToBase58(), approve, yes, no},
+		})
+	switch {
+	case yes >= threshold:
+		proposal.Status = ExecutedStatus
+	case no > len(voters)-threshold:
+		proposal.Status = RejectedStatus
+	}
+	putProposal(native, proposal)
+	if proposal.Status == ActiveStatus {
+		return false, nil
+	}
+	deleteValue(native, activeProposalKey(proposal.Type, proposal.Content))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.ProposalManagerContractAddress,
+			States:          []interface{}{proposal.Status.String(), proposal.ID},
+		})
+	if proposal.Status == RejectedStatus {
+		return false, nil
+	}
+	info, err := GetProposalHandlerInfo(proposal.Type)
+	if err != nil {
+		return false, err
+	}
+	if err := execute(native, info, proposal.Content); err != nil {
+		return false, fmt.Errorf("execute proposal %d error: %v", proposal.ID, err)
+	}
+	return true, nil
+}
+
+func execute(native *native.NativeService, info *ProposalHandlerInfo, content []byte) error {
+	if info.Execute == nil {
+		return nil
+	}
+	return info.Execute(native, content)
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal_manager
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProposal ProposalType = 200
+
+var (
+	conAccts = func() []*account.Account {
+		accts := make([]*account.Account, 0)
+		for i := 0; i < 4; i++ {
+			accts = append(accts, account.NewAccount(strconv.FormatUint(uint64(i), 10)))
+		}
+		return accts
+	}()
+	executed [][]byte
+)
+
+func init() {
+	RegisterProposalHandler(&ProposalHandlerInfo{
+		Type: testProposal,
+		Execute: func(native *native.NativeService, content []byte) error {
+			executed = append(executed, content)
+			return nil
+		},
+	})
+}
+
+func enableProposal(t *testing.T) {
+	networkID := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	t.Cleanup(func() {
+		config.DefConfig.P2PNode.NetworkId = networkID
+	})
+}
+
+func newDB() *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	peerPoolMap := &node_manager.PeerPoolMap{PeerPoolMap: make(map[string]*node_manager.PeerPoolItem)}
+	for i, conAcct := range conAccts {
+		pkStr := vconfig.PubkeyID(conAcct.PublicKey)
+		peerPoolMap.PeerPoolMap[pkStr] = &node_manager.PeerPoolItem{
+			Index:      uint32(i),
+			PeerPubkey: pkStr,
+			Address:    conAcct.Address,
+			Status:     node_manager.ConsensusStatus,
+		}
+	}
+	sink := common.NewZeroCopySink(nil)
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	govView := &node_manager.GovernanceView{View: 0, Height: 10, TxHash: common.UINT256_EMPTY}
+	sink = common.NewZeroCopySink(nil)
+	govView.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+	return db
+}
+
+func newNative(args []byte, signer common.Address, db *storage.CacheDB, time uint32) *native.NativeService {
+	tx := &types.Transaction{SignedAddr: []common.Address{signer}}
+	ns, _ := native.NewNativeService(db, tx, time, 100, common.Uint256{}, 0, args, false)
+	return ns
+}
+
+func propose(db *storage.CacheDB, proposalType ProposalType, content []byte, period uint32, proposer *account.Account,
+	time uint32) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	(&ProposeParam{Type: proposalType, Content: content, VotingPeriod: period, Address: proposer.Address}).Serialization(sink)
+	return Propose(newNative(sink.Bytes(), proposer.Address, db, time))
+}
+
+func vote(db *storage.CacheDB, id uint64, approve bool, voter *account.Account, time uint32) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	(&VoteParam{ID: id, Approve: approve, Address: voter.Address}).Serialization(sink)
+	return Vote(newNative(sink.Bytes(), voter.Address, db, time))
+}
+
+func getProposal(t *testing.T, db *storage.CacheDB, id uint64) *Proposal {
+	proposal, err := GetProposal(newNative(nil, common.ADDRESS_EMPTY, db, 0), id)
+	assert.Nil(t, err)
+	return proposal
+}
+
+func TestProposalSerialization(t *testing.T) {
+	proposal := &Proposal{
+		ID:       3,
+		Type:     PROPOSAL_REGISTER_RELAYER,
+		Content:  utils.GetUint64Bytes(7),
+		Proposer: conAccts[0].Address,
+		Height:   100,
+		EndTime:  1000,
+		Status:   RejectedStatus,
+		Votes:    map[common.Address]bool{conAccts[0].Address: true, conAccts[1].Address: false},
+	}
+	sink := common.NewZeroCopySink(nil)
+	proposal.Serialization(sink)
+	result := new(Proposal)
+	assert.Nil(t, result.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, proposal, result)
+	assert.Equal(t, "registerRelayer", result.Type.String())
+	assert.Equal(t, "rejected", result.Status.String())
+}
+
+func TestProposeDisabled(t *testing.T) {
+	db := newDB()
+	_, err := propose(db, PROPOSAL_TEXT, []byte("text"), 0, conAccts[0], 0)
+	assert.NotNil(t, err)
+	_, err = vote(db, 1, true, conAccts[0], 0)
+	assert.NotNil(t, err)
+}
+
+func TestProposeAndVote(t *testing.T) {
+	enableProposal(t)
+	db := newDB()
+	executed = nil
+
+	_, err := propose(db, testProposal, []byte("content"), 0, account.NewAccount("x"), 0)
+	assert.NotNil(t, err, "only consensus peers can propose")
+	_, err = propose(db, ProposalType(201), []byte("content"), 0, conAccts[0], 0)
+	assert.NotNil(t, err, "unknown proposal type")
+	_, err = propose(db, testProposal, []byte("content"), 60, conAccts[0], 0)
+	assert.NotNil(t, err, "voting period too short")
+
+	_, err = propose(db, testProposal, []byte("content"), 0, conAccts[0], 0)
+	assert.Nil(t, err)
+	_, err = propose(db, testProposal, []byte("content"), 0, conAccts[1], 0)
+	assert.NotNil(t, err, "same content is active")
+	proposal := getProposal(t, db, 1)
+	assert.Equal(t, ActiveStatus, proposal.Status)
+	assert.Equal(t, uint32(DEFAULT_VOTING_PERIOD), proposal.EndTime)
+	assert.Equal(t, map[common.Address]bool{conAccts[0].Address: true}, proposal.Votes)
+
+	_, err = vote(db, 2, true, conAccts[1], 10)
+	assert.NotNil(t, err, "proposal does not exist")
+	_, err = vote(db, 1, true, conAccts[1], 10)
+	assert.Nil(t, err)
+	assert.Nil(t, executed)
+	_, err = vote(db, 1, true, conAccts[2], 20)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("content")}, executed)
+	assert.Equal(t, ExecutedStatus, getProposal(t, db, 1).Status)
+
+	_, err = vote(db, 1, true, conAccts[3], 30)
+	assert.NotNil(t, err, "proposal is executed")
+	_, err = propose(db, testProposal, []byte("content"), 0, conAccts[1], 40)
+	assert.Nil(t, err, "same content can be proposed again")
+	assert.Equal(t, uint64(2), getProposal(t, db, 2).ID)
+}
+
+func TestRejectAndExpire(t *testing.T) {
+	enableProposal(t)
+	db := newDB()
+	executed = nil
+
+	_, err := propose(db, PROPOSAL_TEXT, []byte("reject"), 0, conAccts[0], 0)
+	assert.Nil(t, err)
+	_, err = vote(db, 1, false, conAccts[1], 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ActiveStatus, getProposal(t, db, 1).Status)
+	_, err = vote(db, 1, false, conAccts[2], 0)
+	assert.Nil(t, err)
+	assert.Equal(t, RejectedStatus, getProposal(t, db, 1).Status)
+
+	_, err = propose(db, testProposal, []byte("expire"), MIN_VOTING_PERIOD, conAccts[0], 0)
+	assert.Nil(t, err)
+	proposal := getProposal(t, db, 2)
+	assert.Equal(t, ActiveStatus, proposal.State(MIN_VOTING_PERIOD))
+	assert.Equal(t, ExpiredStatus, proposal.State(MIN_VOTING_PERIOD+1))
+	_, err = vote(db, 2, true, conAccts[1], MIN_VOTING_PERIOD+1)
+	assert.NotNil(t, err, "proposal is expired")
+	_, err = propose(db, testProposal, []byte("expire"), 0, conAccts[1], MIN_VOTING_PERIOD+1)
+	assert.Nil(t, err, "content of expired proposal can be proposed again")
+	assert.Nil(t, executed)
+}
+
+func TestUpdateConfigProposal(t *testing.T) {
+	enableProposal(t)
+	db := newDB()
+
+	configuration := &node_manager.Configuration{
+		BlockMsgDelay:        5000,
+		HashMsgDelay:         5000,
+		PeerHandshakeTimeout: 10,
+		MaxBlockChangeView:   1000,
+	}
+	sink := common.NewZeroCopySink(nil)
+	configuration.Serialization(sink)
+	_, err := propose(db, PROPOSAL_UPDATE_CONFIG, sink.Bytes(), 0, conAccts[0], 0)
+	assert.NotNil(t, err, "MaxBlockChangeView is too small")
+
+	configuration.MaxBlockChangeView = 20000
+	sink = common.NewZeroCopySink(nil)
+	configuration.Serialization(sink)
+	_, err = propose(db, PROPOSAL_UPDATE_CONFIG, sink.Bytes(), 0, conAccts[0], 0)
+	assert.Nil(t, err)
+	for _, conAcct := range conAccts[1:3] {
+		_, err = vote(db, 1, true, conAcct, 0)
+		assert.Nil(t, err)
+	}
+	result, err := node_manager.GetConfig(newNative(nil, common.ADDRESS_EMPTY, db, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, configuration, result)
+}
+
+func TestApprove(t *testing.T) {
+	approve := func(db *storage.CacheDB, voter common.Address) bool {
+		ok, err := Approve(newNative(nil, voter, db, 0), testProposal, "approveTest", []byte("approve"), voter)
+		assert.Nil(t, err)
+		return ok
+	}
+
+	// consensus signs are collected before the governance proposal height
+	executed = nil
+	db := newDB()
+	assert.False(t, approve(db, conAccts[0].Address))
+	assert.False(t, approve(db, conAccts[1].Address))
+	assert.True(t, approve(db, conAccts[2].Address))
+	assert.Equal(t, [][]byte{[]byte("approve")}, executed)
+	count, err := GetProposalCount(newNative(nil, common.ADDRESS_EMPTY, db, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), count)
+
+	enableProposal(t)
+	executed = nil
+	db = newDB()
+	assert.False(t, approve(db, account.NewAccount("x").Address))
+	assert.Nil(t, getProposal(t, db, 1), "votes of other addresses are ignored")
+	assert.False(t, approve(db, conAccts[0].Address))
+	assert.False(t, approve(db, conAccts[1].Address))
+	assert.True(t, approve(db, conAccts[2].Address))
+	assert.Equal(t, [][]byte{[]byte("approve")}, executed)
+	proposal := getProposal(t, db, 1)
+	assert.Equal(t, ExecutedStatus, proposal.Status)
+	assert.Equal(t, conAccts[0].Address, proposal.Proposer)
+	assert.Equal(t, 3, len(proposal.Votes))
+}
+
+func TestApproveAcrossSwitch(t *testing.T) {
+	approve := func(db *storage.CacheDB, voter common.Address) bool {
+		ok, err := Approve(newNative(nil, voter, db, 0), testProposal, "approveTest", []byte("switch"), voter)
+		assert.Nil(t, err)
+		return ok
+	}
+
+	executed = nil
+	db := newDB()
+	assert.False(t, approve(db, conAccts[0].Address))
+	assert.False(t, approve(db, conAccts[1].Address))
+
+	// the consensus signs still open at the switch become votes of the first proposal
+	enableProposal(t)
+	assert.True(t, approve(db, conAccts[2].Address))
+	assert.Equal(t, [][]byte{[]byte("switch")}, executed)
+	proposal := getProposal(t, db, 1)
+	assert.Equal(t, ExecutedStatus, proposal.Status)
+	assert.Equal(t, conAccts[2].Address, proposal.Proposer)
+	assert.Equal(t, 3, len(proposal.Votes))
+	signs, err := node_manager.TakeConsensusSigns(newNative(nil, common.ADDRESS_EMPTY, db, 0), "approveTest", []byte("switch"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(signs))
+}
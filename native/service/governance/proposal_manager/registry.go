@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type ProposalHandlerInfo struct {
+	Type ProposalType
+	// Check validates the content when the proposal is made, nil if any content is accepted
+	Check func(native *native.NativeService, content []byte) error
+	// Execute applies the content when the proposal passes, nil if passing it changes nothing
+	Execute func(native *native.NativeService, content []byte) error
+}
+
+var proposalHandlers = make(map[ProposalType]*ProposalHandlerInfo)
+
+//RegisterProposalHandler register the handler of a proposal type, it should be called in init of the contract package
+func RegisterProposalHandler(info *ProposalHandlerInfo) {
+	if _, present := proposalHandlers[info.Type]; present {
+		panic(fmt.Sprintf("proposal handler of type %s registered twice", info.Type))
+	}
+	proposalHandlers[info.Type] = info
+}
+
+func GetProposalHandlerInfo(proposalType ProposalType) (*ProposalHandlerInfo, error) {
+	info, present := proposalHandlers[proposalType]
+	if !present {
+		return nil, fmt.Errorf("not a supported proposal type:%s", proposalType)
+	}
+	return info, nil
+}
+
+//ParseIDContent return the chain id or apply id in the content of a proposal
+func ParseIDContent(content []byte) (uint64, error) {
+	if len(content) != 8 {
+		return 0, fmt.Errorf("content length %d is not of an id", len(content))
+	}
+	return utils.GetBytesUint64(content), nil
+}
+
+func init() {
+	RegisterProposalHandler(&ProposalHandlerInfo{Type: PROPOSAL_TEXT})
+	RegisterProposalHandler(&ProposalHandlerInfo{
+		Type: PROPOSAL_UPDATE_CONFIG,
+		Check: func(native *native.NativeService, content []byte) error {
+			configuration := new(node_manager.Configuration)
+			if err := configuration.Deserialization(common.NewZeroCopySource(content)); err != nil {
+				return fmt.Errorf("deserialize configuration error: %v", err)
+			}
+			return node_manager.CheckConfiguration(configuration)
+		},
+		Execute: func(native *native.NativeService, content []byte) error {
+			configuration := new(node_manager.Configuration)
+			if err := configuration.Deserialization(common.NewZeroCopySource(content)); err != nil {
+				return fmt.Errorf("deserialize configuration error: %v", err)
+			}
+			return node_manager.SetConfig(native, configuration)
+		},
+	})
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type ProposalType uint8
+
+const (
+	PROPOSAL_TEXT ProposalType = iota
+	PROPOSAL_REGISTER_SIDE_CHAIN
+	PROPOSAL_UPDATE_SIDE_CHAIN
+	PROPOSAL_QUIT_SIDE_CHAIN
+	PROPOSAL_REGISTER_RELAYER
+	PROPOSAL_REMOVE_RELAYER
+	PROPOSAL_REGISTER_STATE_VALIDATOR
+	PROPOSAL_REMOVE_STATE_VALIDATOR
+	PROPOSAL_UPDATE_CONFIG
+)
+
+var proposalTypeNames = map[ProposalType]string{
+	PROPOSAL_TEXT:                     "text",
+	PROPOSAL_REGISTER_SIDE_CHAIN:      "registerSideChain",
+	PROPOSAL_UPDATE_SIDE_CHAIN:        "updateSideChain",
+	PROPOSAL_QUIT_SIDE_CHAIN:          "quitSideChain",
+	PROPOSAL_REGISTER_RELAYER:         "registerRelayer",
+	PROPOSAL_REMOVE_RELAYER:           "removeRelayer",
+	PROPOSAL_REGISTER_STATE_VALIDATOR: "registerStateValidator",
+	PROPOSAL_REMOVE_STATE_VALIDATOR:   "removeStateValidator",
+	PROPOSAL_UPDATE_CONFIG:            "updateConfig",
+}
+
+func (this ProposalType) String() string {
+	if name, ok := proposalTypeNames[this]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", uint8(this))
+}
+
+type ProposalStatus uint8
+
+const (
+	ActiveStatus ProposalStatus = iota
+	ExecutedStatus
+	RejectedStatus
+	ExpiredStatus //active proposal past its end time, never stored
+)
+
+var proposalStatusNames = map[ProposalStatus]string{
+	ActiveStatus:   "active",
+	ExecutedStatus: "executed",
+	RejectedStatus: "rejected",
+	ExpiredStatus:  "expired",
+}
+
+func (this ProposalStatus) String() string {
+	if name, ok := proposalStatusNames[this]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", uint8(this))
+}
+
+type Proposal struct {
+	ID       uint64
+	Type     ProposalType
+	Content  []byte
+	Proposer common.Address
+	Height   uint32 //height of the block proposing it
+	EndTime  uint32 //block time after which votes are not accepted
+	Status   ProposalStatus
+	Votes    map[common.Address]bool //true for yes and false for no
+}
+
+//State return the status of proposal at block time, an active proposal past its end time is expired
+func (this *Proposal) State(time uint32) ProposalStatus {
+	if this.Status == ActiveStatus && time > this.EndTime {
+		return ExpiredStatus
+	}
+	return this.Status
+}
+
+//Tally counts the votes of voters for and against the proposal, and the number of yes votes it needs to pass
+func (this *Proposal) Tally(voters map[common.Address]bool) (yes, no, threshold int) {
+	for address, approve := range this.Votes {
+		if !voters[address] {
+			continue
+		}
+		if approve {
+			yes++
+		} else {
+			no++
+		}
+	}
+	return yes, no, (2*len(voters) + 2) / 3
+}
+
+func (this *Proposal) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ID)
+	sink.WriteUint8(uint8(this.Type))
+	sink.WriteVarBytes(this.Content)
+	sink.WriteVarBytes(this.Proposer[:])
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.EndTime)
+	sink.WriteUint8(uint8(this.Status))
+	sink.WriteVarUint(uint64(len(this.Votes)))
+	voters := make([]common.Address, 0, len(this.Votes))
+	for address := range this.Votes {
+		voters = append(voters, address)
+	}
+	sort.SliceStable(voters, func(i, j int) bool {
+		return voters[i].ToHexString() > voters[j].ToHexString()
+	})
+	for _, address := range voters {
+		sink.WriteVarBytes(address[:])
+		sink.WriteBool(this.Votes[address])
+	}
+}
+
+func (this *Proposal) Deserialization(source *common.ZeroCopySource) error {
+	id, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("source.NextUint64, deserialize id error")
+	}
+	proposalType, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize type error")
+	}
+	content, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize content error")
+	}
+	proposer, err := readAddress(source)
+	if err != nil {
+		return fmt.Errorf("deserialize proposer error: %v", err)
+	}
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize height error")
+	}
+	endTime, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize endTime error")
+	}
+	status, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize status error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of votes error")
+	}
+	votes := make(map[common.Address]bool)
+	for i := uint64(0); i < n; i++ {
+		address, err := readAddress(source)
+		if err != nil {
+			return fmt.Errorf("deserialize voter error: %v", err)
+		}
+		approve, eof := source.NextBool()
+		if eof {
+			return fmt.Errorf("source.NextBool, deserialize vote error")
+		}
+		votes[address] = approve
+	}
+
+	this.ID = id
+	this.Type = ProposalType(proposalType)
+	this.Content = content
+	this.Proposer = proposer
+	this.Height = height
+	this.EndTime = endTime
+	this.Status = ProposalStatus(status)
+	this.Votes = votes
+	return nil
+}
+
+func readAddress(source *common.ZeroCopySource) (common.Address, error) {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return common.ADDRESS_EMPTY, fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return common.ADDRESS_EMPTY, fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %v", err)
+	}
+	return addr, nil
+}
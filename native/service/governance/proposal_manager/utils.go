@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal_manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//ProposalKey return the storage key of proposal of id, without contract address
+func ProposalKey(id uint64) []byte {
+	return append([]byte(PROPOSAL), utils.GetUint64Bytes(id)...)
+}
+
+func activeProposalKey(proposalType ProposalType, content []byte) []byte {
+	hash := sha256.Sum256(content)
+	return append(append([]byte(ACTIVE_PROPOSAL), byte(proposalType)), hash[:]...)
+}
+
+func getValue(native *native.NativeService, key []byte) ([]byte, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.ProposalManagerContractAddress, key))
+	if err != nil {
+		return nil, fmt.Errorf("get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize from raw storage item error: %v", err)
+	}
+	return value, nil
+}
+
+func putValue(native *native.NativeService, key []byte, value []byte) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.ProposalManagerContractAddress, key), cstates.GenRawStorageItem(value))
+}
+
+func deleteValue(native *native.NativeService, key []byte) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.ProposalManagerContractAddress, key))
+}
+
+//isProposalEnabled return whether approve methods vote through proposals at the height of the native service
+func isProposalEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetGovernanceProposalHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//GetProposalCount return the id of the latest proposal, zero if no proposal is made yet
+func GetProposalCount(native *native.NativeService) (uint64, error) {
+	value, err := getValue(native, []byte(PROPOSAL_ID))
+	if err != nil {
+		return 0, fmt.Errorf("GetProposalCount, %v", err)
+	}
+	return utils.GetBytesUint64(value), nil
+}
+
+//GetProposal return the proposal of id, nil if it does not exist
+func GetProposal(native *native.NativeService, id uint64) (*Proposal, error) {
+	value, err := getValue(native, ProposalKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("GetProposal, %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	proposal := new(Proposal)
+	if err := proposal.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetProposal, deserialize proposal error: %v", err)
+	}
+	return proposal, nil
+}
+
+func putProposal(native *native.NativeService, proposal *Proposal) {
+	sink := common.NewZeroCopySink(nil)
+	proposal.Serialization(sink)
+	putValue(native, ProposalKey(proposal.ID), sink.Bytes())
+}
+
+//getActiveProposal return the active proposal of the type and content, nil if there is none
+func getActiveProposal(native *native.NativeService, proposalType ProposalType, content []byte) (*Proposal, error) {
+	value, err := getValue(native, activeProposalKey(proposalType, content))
+	if err != nil {
+		return nil, fmt.Errorf("getActiveProposal, %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	proposal, err := GetProposal(native, utils.GetBytesUint64(value))
+	if err != nil {
+		return nil, fmt.Errorf("getActiveProposal, %v", err)
+	}
+	if proposal == nil || proposal.State(native.GetTime()) != ActiveStatus {
+		return nil, nil
+	}
+	return proposal, nil
+}
+
+//ConsensusVoters return the addresses of consensus peers in peer pool, which are the voters of proposals
+func ConsensusVoters(peerPoolMap *node_manager.PeerPoolMap) (map[common.Address]bool, error) {
+	voters := make(map[common.Address]bool)
+	for key, v := range peerPoolMap.PeerPoolMap {
+		if v.Status != node_manager.ConsensusStatus {
+			continue
+		}
+		k, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("hex.DecodeString public key error: %v", err)
+		}
+		publicKey, err := keypair.DeserializePublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("keypair.DeserializePublicKey error: %v", err)
+		}
+		voters[types.AddressFromPubKey(publicKey)] = true
+	}
+	return voters, nil
+}
+
+func getConsensusVoters(native *native.NativeService) (map[common.Address]bool, error) {
+	view, err := node_manager.GetView(native)
+	if err != nil {
+		return nil, fmt.Errorf("getConsensusVoters, GetView error: %v", err)
+	}
+	peerPoolMap, err := node_manager.GetPeerPoolMap(native, view)
+	if err != nil {
+		return nil, fmt.Errorf("getConsensusVoters, GetPeerPoolMap error: %v", err)
+	}
+	return ConsensusVoters(peerPoolMap)
+}
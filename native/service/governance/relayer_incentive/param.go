@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_incentive
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type SetRewardContractParam struct {
+	ChainID  uint64
+	Contract []byte
+	Address  common.Address
+}
+
+func (this *SetRewardContractParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteVarBytes(this.Contract)
+	sink.WriteAddress(this.Address)
+}
+
+func (this *SetRewardContractParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetRewardContractParam deserialize chain id error")
+	}
+	contract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetRewardContractParam deserialize contract error")
+	}
+	address, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("SetRewardContractParam deserialize address error")
+	}
+	this.ChainID = chainID
+	this.Contract = contract
+	this.Address = address
+	return nil
+}
+
+type FundRewardPoolParam struct {
+	ChainID uint64
+	FundID  uint64
+	Amount  *big.Int
+	Address common.Address
+}
+
+func (this *FundRewardPoolParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteUint64(this.FundID)
+	sink.WriteVarBytes(this.Amount.Bytes())
+	sink.WriteAddress(this.Address)
+}
+
+func (this *FundRewardPoolParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("FundRewardPoolParam deserialize chain id error")
+	}
+	fundID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("FundRewardPoolParam deserialize fund id error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("FundRewardPoolParam deserialize amount error")
+	}
+	address, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("FundRewardPoolParam deserialize address error")
+	}
+	this.ChainID = chainID
+	this.FundID = fundID
+	this.Amount = new(big.Int).SetBytes(amount)
+	this.Address = address
+	return nil
+}
+
+type WithdrawRewardParam struct {
+	Relayer   common.Address
+	ChainID   uint64
+	ToAddress []byte
+	Amount    *big.Int
+}
+
+func (this *WithdrawRewardParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteAddress(this.Relayer)
+	sink.WriteUint64(this.ChainID)
+	sink.WriteVarBytes(this.ToAddress)
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *WithdrawRewardParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("WithdrawRewardParam deserialize relayer error")
+	}
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("WithdrawRewardParam deserialize chain id error")
+	}
+	toAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("WithdrawRewardParam deserialize to address error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("WithdrawRewardParam deserialize amount error")
+	}
+	this.Relayer = relayer
+	this.ChainID = chainID
+	this.ToAddress = toAddress
+	this.Amount = new(big.Int).SetBytes(amount)
+	return nil
+}
+
+type GetRewardParam struct {
+	Relayer common.Address
+	ChainID uint64
+}
+
+func (this *GetRewardParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteAddress(this.Relayer)
+	sink.WriteUint64(this.ChainID)
+}
+
+func (this *GetRewardParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("GetRewardParam deserialize relayer error")
+	}
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("GetRewardParam deserialize chain id error")
+	}
+	this.Relayer = relayer
+	this.ChainID = chainID
+	return nil
+}
+
+//UnlockArgs is the args of the unlock method of reward contract on target chain
+type UnlockArgs struct {
+	WithdrawID uint64
+	ToAddress  []byte
+	Amount     *big.Int
+}
+
+func (this *UnlockArgs) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.WithdrawID)
+	sink.WriteVarBytes(this.ToAddress)
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *UnlockArgs) Deserialization(source *common.ZeroCopySource) error {
+	withdrawID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("UnlockArgs deserialize withdraw id error")
+	}
+	toAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("UnlockArgs deserialize to address error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("UnlockArgs deserialize amount error")
+	}
+	this.WithdrawID = withdrawID
+	this.ToAddress = toAddress
+	this.Amount = new(big.Int).SetBytes(amount)
+	return nil
+}
@@ -0,0 +1,361 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_incentive
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	//function name
+	SET_REWARD_CONTRACT = "setRewardContract"
+	FUND_REWARD_POOL    = "fundRewardPool"
+	WITHDRAW_REWARD     = "withdrawReward"
+	GET_REWARD          = "getReward"
+
+	//key prefix
+	REWARD          = "reward"
+	REWARD_CHAINS   = "rewardChains"
+	REWARD_CONTRACT = "rewardContract"
+	REWARD_POOL     = "rewardPool"
+	FUND_ID         = "fundID"
+	WITHDRAW_ID     = "withdrawID"
+
+	//method of the reward contract on side chain called by withdraw
+	UNLOCK_REWARD = "unlockReward"
+
+	//kind of rewarded relayer work
+	HEADER_SYNC     = "headerSync"
+	IMPORT_TRANSFER = "importTransfer"
+)
+
+//Register methods of relayer_incentive contract
+func RegisterRelayerIncentiveContract(native *native.NativeService) {
+	native.Register(SET_REWARD_CONTRACT, SetRewardContract)
+	native.Register(FUND_REWARD_POOL, FundRewardPool)
+	native.Register(WITHDRAW_REWARD, WithdrawReward)
+	native.Register(GET_REWARD, GetRewardInfo)
+}
+
+//isIncentiveEnabled return whether relayers are rewarded at the height of the native service
+func isIncentiveEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetRelayerIncentiveHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//CreditHeaderSync credits the relayer of a successful SyncBlockHeader with the voted fee of the side chain for each
+//header newly stored by the tx, known headers skipped by the handler earn nothing
+func CreditHeaderSync(native *native.NativeService, chainID uint64) error {
+	return credit(native, chainID, HEADER_SYNC, uint64(native.GetStoredHeaders()))
+}
+
+//CreditImportTransfer credits the relayer of a successful ImportOuterTransfer with the voted fee of the source chain
+//for the newly imported cross chain tx
+func CreditImportTransfer(native *native.NativeService, chainID uint64) error {
+	return credit(native, chainID, IMPORT_TRANSFER, 1)
+}
+
+//credit pays the fee of count works to the relayer of tx from the reward pool of the side chain, the payment is
+//capped by the pool balance
+func credit(native *native.NativeService, chainID uint64, kind string, count uint64) error {
+	if !isIncentiveEnabled(native) || count == 0 {
+		return nil
+	}
+	relayer, ok, err := getTxRelayer(native)
+	if err != nil {
+		return fmt.Errorf("credit, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+	fee, err := side_chain_manager.GetFee(native, chainID)
+	if err != nil {
+		return fmt.Errorf("credit, side_chain_manager.GetFee error: %v", err)
+	}
+	if fee.Fee.Sign() <= 0 {
+		return nil
+	}
+	pool, err := GetRewardPool(native, chainID)
+	if err != nil {
+		return fmt.Errorf("credit, %v", err)
+	}
+	amount := new(big.Int).Mul(fee.Fee, new(big.Int).SetUint64(count))
+	if amount.Cmp(pool) > 0 {
+		amount.Set(pool)
+	}
+	if amount.Sign() <= 0 {
+		return nil
+	}
+
+	reward, err := GetReward(native, relayer, chainID)
+	if err != nil {
+		return fmt.Errorf("credit, %v", err)
+	}
+	reward.Claimable.Add(reward.Claimable, amount)
+	reward.Earned.Add(reward.Earned, amount)
+	if kind == HEADER_SYNC {
+		reward.Headers += count
+	} else {
+		reward.Transfers += count
+	}
+	if err := putReward(native, relayer, chainID, reward); err != nil {
+		return fmt.Errorf("credit, %v", err)
+	}
+	putRewardPool(native, chainID, pool.Sub(pool, amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerIncentiveContractAddress,
+			States:          []interface{}{"creditReward", relayer.ToBase58(), chainID, kind, count, amount.String()},
+		})
+	return nil
+}
+
+//getTxRelayer return the first signer of the tx which is a registered relayer
+func getTxRelayer(native *native.NativeService) (common.Address, bool, error) {
+	for _, address := range native.GetTx().SignedAddr {
+		ok, err := relayer_manager.IsRelayer(native, address)
+		if err != nil {
+			return common.ADDRESS_EMPTY, false, err
+		}
+		if ok {
+			return address, true, nil
+		}
+	}
+	return common.ADDRESS_EMPTY, false, nil
+}
+
+func SetRewardContract(native *native.NativeService) ([]byte, error) {
+	params := new(SetRewardContractParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, contract params deserialize error: %v", err)
+	}
+	if !isIncentiveEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, relayer incentive is not enabled")
+	}
+	if len(params.Contract) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, contract is empty")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, checkWitness error: %v", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, side chain %d is not registered", params.ChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.ChainID)
+	sink.WriteVarBytes(params.Contract)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_REWARD_CONTRACT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRewardContract, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putRewardContract(native, params.ChainID, params.Contract)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerIncentiveContractAddress,
+			States:          []interface{}{"setRewardContract", params.ChainID, hex.EncodeToString(params.Contract)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//FundRewardPool adds the amount to the reward pool of side chain once the consensus nodes approve it. The amount is
+//the fee locked in the reward contract of the side chain, FundID must be the next fund id of the chain so that an
+//approved funding can not be voted again.
+func FundRewardPool(native *native.NativeService) ([]byte, error) {
+	params := new(FundRewardPoolParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, contract params deserialize error: %v", err)
+	}
+	if !isIncentiveEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, relayer incentive is not enabled")
+	}
+	if params.Amount.Sign() <= 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, amount should be positive")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, checkWitness error: %v", err)
+	}
+
+	fundID, err := getFundID(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, %v", err)
+	}
+	if params.FundID != fundID {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, fund id %d of chain %d is not the next id %d",
+			params.FundID, params.ChainID, fundID)
+	}
+	contract, err := GetRewardContract(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, %v", err)
+	}
+	if len(contract) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, reward contract of chain %d is not set", params.ChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.ChainID)
+	sink.WriteUint64(params.FundID)
+	sink.WriteVarBytes(params.Amount.Bytes())
+	ok, err := node_manager.CheckConsensusSigns(native, FUND_REWARD_POOL, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	pool, err := GetRewardPool(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("FundRewardPool, %v", err)
+	}
+	putRewardPool(native, params.ChainID, pool.Add(pool, params.Amount))
+	putFundID(native, params.ChainID, fundID+1)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerIncentiveContractAddress,
+			States:          []interface{}{"fundRewardPool", params.ChainID, params.FundID, params.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//WithdrawReward deducts the claimable reward of relayer and makes a cross chain tx to the reward contract of
+//the side chain, which unlocks the amount to the address on the side chain
+func WithdrawReward(native *native.NativeService) ([]byte, error) {
+	params := new(WithdrawRewardParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, contract params deserialize error: %v", err)
+	}
+	if !isIncentiveEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, relayer incentive is not enabled")
+	}
+	if params.Amount.Sign() <= 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, amount should be positive")
+	}
+	if len(params.ToAddress) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, to address is empty")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Relayer); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, checkWitness error: %v", err)
+	}
+
+	reward, err := GetReward(native, params.Relayer, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, %v", err)
+	}
+	if reward.Claimable.Cmp(params.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, claimable reward %s is less than amount %s",
+			reward.Claimable.String(), params.Amount.String())
+	}
+	contract, err := GetRewardContract(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, %v", err)
+	}
+	if len(contract) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, reward contract of chain %d is not set", params.ChainID)
+	}
+	blacked, err := scom.CheckIfChainBlacked(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, CheckIfChainBlacked error: %v", err)
+	}
+	if blacked {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, chain %d is blacked", params.ChainID)
+	}
+
+	withdrawID, err := getWithdrawID(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, %v", err)
+	}
+	putWithdrawID(native, withdrawID+1)
+
+	reward.Claimable.Sub(reward.Claimable, params.Amount)
+	reward.Withdrawn.Add(reward.Withdrawn, params.Amount)
+	if err := putReward(native, params.Relayer, params.ChainID, reward); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, %v", err)
+	}
+
+	args := &UnlockArgs{
+		WithdrawID: withdrawID,
+		ToAddress:  params.ToAddress,
+		Amount:     new(big.Int).Set(params.Amount),
+	}
+	sink := common.NewZeroCopySink(nil)
+	args.Serialization(sink)
+	txHash := native.GetTx().Hash()
+	txParam := &scom.MakeTxParam{
+		TxHash:              txHash.ToArray(),
+		CrossChainID:        utils.GetUint64Bytes(withdrawID),
+		FromContractAddress: utils.RelayerIncentiveContractAddress[:],
+		ToChainID:           params.ChainID,
+		ToContractAddress:   contract,
+		Method:              UNLOCK_REWARD,
+		Args:                sink.Bytes(),
+	}
+	if err := scom.MakeTransaction(native, txParam, native.GetChainID()); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("WithdrawReward, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerIncentiveContractAddress,
+			States: []interface{}{"withdrawReward", params.Relayer.ToBase58(), params.ChainID, withdrawID,
+				hex.EncodeToString(params.ToAddress), params.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//GetRewardInfo return the serialized reward of relayer on side chain, used by pre execution
+func GetRewardInfo(native *native.NativeService) ([]byte, error) {
+	params := new(GetRewardParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRewardInfo, contract params deserialize error: %v", err)
+	}
+	reward, err := GetReward(native, params.Relayer, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRewardInfo, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	reward.Serialization(sink)
+	return sink.Bytes(), nil
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_incentive
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	relayerAcct = account.NewAccount("")
+	otherAcct   = account.NewAccount("")
+)
+
+const testChainID = 2
+
+func NewNative(args []byte, tx *types.Transaction, db *storage.CacheDB) *native.NativeService {
+	if db == nil {
+		store, _ := leveldbstore.NewMemLevelDBStore()
+		db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	}
+	ns, _ := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, args, false)
+	return ns
+}
+
+func enableIncentive(t *testing.T) {
+	networkID := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	t.Cleanup(func() {
+		config.DefConfig.P2PNode.NetworkId = networkID
+	})
+}
+
+func prepare(db *storage.CacheDB, fee, pool int64) {
+	ns := NewNative(nil, new(types.Transaction), db)
+	db.Put(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(relayer_manager.RELAYER), relayerAcct.Address[:]),
+		cstates.GenRawStorageItem(relayerAcct.Address[:]))
+	side_chain_manager.PutFee(ns, testChainID, &side_chain_manager.Fee{View: 1, Fee: big.NewInt(fee)})
+	putRewardPool(ns, testChainID, big.NewInt(pool))
+}
+
+func putPeerMapPoolAndView(db *storage.CacheDB, conAccts []*account.Account) {
+	peerPoolMap := new(node_manager.PeerPoolMap)
+	peerPoolMap.PeerPoolMap = make(map[string]*node_manager.PeerPoolItem)
+	for i, conAcct := range conAccts {
+		pkStr := vconfig.PubkeyID(conAcct.PublicKey)
+		peerPoolMap.PeerPoolMap[pkStr] = &node_manager.PeerPoolItem{
+			Index:      uint32(i),
+			PeerPubkey: pkStr,
+			Address:    conAcct.Address,
+			Status:     node_manager.ConsensusStatus,
+		}
+	}
+	sink := common.NewZeroCopySink(nil)
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	govView := node_manager.GovernanceView{View: 0, Height: 10, TxHash: common.UINT256_EMPTY}
+	sink = common.NewZeroCopySink(nil)
+	govView.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func TestCredit(t *testing.T) {
+	enableIncentive(t)
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	prepare(db, 10, 100)
+
+	tx := &types.Transaction{SignedAddr: []common.Address{otherAcct.Address, relayerAcct.Address}}
+	ns := NewNative(nil, tx, db)
+	// no header is newly stored
+	assert.Nil(t, CreditHeaderSync(ns, testChainID))
+	assert.Equal(t, 0, len(ns.GetNotify()))
+	ns.AddStoredHeader()
+	ns.AddStoredHeader()
+	assert.Nil(t, CreditHeaderSync(ns, testChainID))
+	assert.Nil(t, CreditImportTransfer(ns, testChainID))
+	assert.Equal(t, 2, len(ns.GetNotify()))
+
+	reward, err := GetReward(ns, relayerAcct.Address, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(30), reward.Claimable.Int64())
+	assert.Equal(t, int64(30), reward.Earned.Int64())
+	assert.Equal(t, int64(0), reward.Withdrawn.Int64())
+	assert.Equal(t, uint64(2), reward.Headers)
+	assert.Equal(t, uint64(1), reward.Transfers)
+
+	pool, err := GetRewardPool(ns, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(70), pool.Int64())
+
+	chains, err := GetRewardChains(ns, relayerAcct.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{testChainID}, chains.ChainIDs)
+
+	// signer which is not a relayer is not rewarded
+	ns = NewNative(nil, &types.Transaction{SignedAddr: []common.Address{otherAcct.Address}}, db)
+	ns.AddStoredHeader()
+	assert.Nil(t, CreditHeaderSync(ns, testChainID))
+	assert.Equal(t, 0, len(ns.GetNotify()))
+	reward, err = GetReward(ns, otherAcct.Address, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), reward.Earned.Int64())
+}
+
+func TestCreditNotEnabled(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	prepare(db, 10, 100)
+
+	networkID := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkID }()
+
+	ns := NewNative(nil, &types.Transaction{SignedAddr: []common.Address{relayerAcct.Address}}, db)
+	ns.AddStoredHeader()
+	assert.Nil(t, CreditHeaderSync(ns, testChainID))
+	reward, err := GetReward(ns, relayerAcct.Address, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), reward.Earned.Int64())
+}
+
+func TestCreditPool(t *testing.T) {
+	enableIncentive(t)
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	prepare(db, 10, 15)
+
+	// credit is capped by the pool, nothing is credited once the pool is empty
+	ns := NewNative(nil, &types.Transaction{SignedAddr: []common.Address{relayerAcct.Address}}, db)
+	ns.AddStoredHeader()
+	ns.AddStoredHeader()
+	assert.Nil(t, CreditHeaderSync(ns, testChainID))
+	assert.Nil(t, CreditImportTransfer(ns, testChainID))
+	assert.Equal(t, 1, len(ns.GetNotify()))
+	reward, err := GetReward(ns, relayerAcct.Address, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(15), reward.Earned.Int64())
+	assert.Equal(t, uint64(2), reward.Headers)
+	assert.Equal(t, uint64(0), reward.Transfers)
+
+	fund := func(fundID uint64, amount int64) error {
+		params := &FundRewardPoolParam{
+			ChainID: testChainID,
+			FundID:  fundID,
+			Amount:  big.NewInt(amount),
+			Address: otherAcct.Address,
+		}
+		sink := common.NewZeroCopySink(nil)
+		params.Serialization(sink)
+		_, err := FundRewardPool(NewNative(sink.Bytes(), &types.Transaction{SignedAddr: []common.Address{otherAcct.Address}}, db))
+		return err
+	}
+	putPeerMapPoolAndView(db, []*account.Account{otherAcct})
+	// reward contract of the side chain is not set
+	assert.NotNil(t, fund(0, 50))
+	putRewardContract(ns, testChainID, []byte{4, 5, 6})
+	assert.Nil(t, fund(0, 50))
+	// approved funding can not be voted again
+	assert.NotNil(t, fund(0, 50))
+	pool, err := GetRewardPool(ns, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), pool.Int64())
+
+	ns = NewNative(nil, &types.Transaction{SignedAddr: []common.Address{relayerAcct.Address}}, db)
+	assert.Nil(t, CreditImportTransfer(ns, testChainID))
+	reward, err = GetReward(ns, relayerAcct.Address, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(25), reward.Earned.Int64())
+	assert.Equal(t, uint64(1), reward.Transfers)
+}
+
+func TestWithdrawReward(t *testing.T) {
+	enableIncentive(t)
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	prepare(db, 10, 100)
+
+	tx := &types.Transaction{SignedAddr: []common.Address{relayerAcct.Address}}
+	ns := NewNative(nil, tx, db)
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, CreditImportTransfer(ns, testChainID))
+	}
+
+	withdraw := func(amount int64) (*native.NativeService, error) {
+		params := &WithdrawRewardParam{
+			Relayer:   relayerAcct.Address,
+			ChainID:   testChainID,
+			ToAddress: []byte{1, 2, 3},
+			Amount:    big.NewInt(amount),
+		}
+		sink := common.NewZeroCopySink(nil)
+		params.Serialization(sink)
+		ns := NewNative(sink.Bytes(), tx, db)
+		_, err := WithdrawReward(ns)
+		return ns, err
+	}
+
+	// reward contract of the side chain is not set
+	_, err := withdraw(20)
+	assert.NotNil(t, err)
+
+	putRewardContract(ns, testChainID, []byte{4, 5, 6})
+	_, err = withdraw(60)
+	assert.NotNil(t, err)
+
+	ns, err = withdraw(20)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ns.GetCrossHashes()))
+
+	reward, err := GetReward(ns, relayerAcct.Address, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(30), reward.Claimable.Int64())
+	assert.Equal(t, int64(50), reward.Earned.Int64())
+	assert.Equal(t, int64(20), reward.Withdrawn.Int64())
+
+	txHash := tx.Hash()
+	value, err := ns.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST),
+		utils.GetUint64Bytes(testChainID), txHash.ToArray()))
+	assert.Nil(t, err)
+	raw, err := cstates.GetValueFromRawStorageItem(value)
+	assert.Nil(t, err)
+	merkleValue := new(scom.ToMerkleValue)
+	assert.Nil(t, merkleValue.Deserialization(common.NewZeroCopySource(raw)))
+	assert.Equal(t, []byte{4, 5, 6}, merkleValue.MakeTxParam.ToContractAddress)
+	assert.Equal(t, UNLOCK_REWARD, merkleValue.MakeTxParam.Method)
+
+	args := new(UnlockArgs)
+	assert.Nil(t, args.Deserialization(common.NewZeroCopySource(merkleValue.MakeTxParam.Args)))
+	assert.Equal(t, uint64(0), args.WithdrawID)
+	assert.Equal(t, []byte{1, 2, 3}, args.ToAddress)
+	assert.Equal(t, int64(20), args.Amount.Int64())
+
+	// only relayer itself is able to withdraw
+	tx = &types.Transaction{SignedAddr: []common.Address{otherAcct.Address}}
+	_, err = withdraw(10)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_incentive
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+)
+
+//RelayerReward is the reward account of a relayer on a side chain, amounts are in the fee unit of the side chain
+type RelayerReward struct {
+	Claimable *big.Int
+	Earned    *big.Int
+	Withdrawn *big.Int
+	Headers   uint64 //count of rewarded headers newly stored by SyncBlockHeader
+	Transfers uint64 //count of rewarded ImportOuterTransfer
+}
+
+func NewRelayerReward() *RelayerReward {
+	return &RelayerReward{
+		Claimable: new(big.Int),
+		Earned:    new(big.Int),
+		Withdrawn: new(big.Int),
+	}
+}
+
+func (this *RelayerReward) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Claimable.Bytes())
+	sink.WriteVarBytes(this.Earned.Bytes())
+	sink.WriteVarBytes(this.Withdrawn.Bytes())
+	sink.WriteUint64(this.Headers)
+	sink.WriteUint64(this.Transfers)
+}
+
+func (this *RelayerReward) Deserialization(source *common.ZeroCopySource) error {
+	claimable, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerReward deserialize claimable error")
+	}
+	earned, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerReward deserialize earned error")
+	}
+	withdrawn, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerReward deserialize withdrawn error")
+	}
+	headers, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("RelayerReward deserialize headers error")
+	}
+	transfers, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("RelayerReward deserialize transfers error")
+	}
+	this.Claimable = new(big.Int).SetBytes(claimable)
+	this.Earned = new(big.Int).SetBytes(earned)
+	this.Withdrawn = new(big.Int).SetBytes(withdrawn)
+	this.Headers = headers
+	this.Transfers = transfers
+	return nil
+}
+
+//RewardChains is the side chains a relayer has reward on
+type RewardChains struct {
+	ChainIDs []uint64
+}
+
+func (this *RewardChains) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.ChainIDs)))
+	for _, id := range this.ChainIDs {
+		sink.WriteUint64(id)
+	}
+}
+
+func (this *RewardChains) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("RewardChains deserialize length error")
+	}
+	chainIDs := make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		id, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("RewardChains deserialize no.%d chain id error", i+1)
+		}
+		chainIDs = append(chainIDs, id)
+	}
+	this.ChainIDs = chainIDs
+	return nil
+}
+
+//Contains return whether the chain is in the list
+func (this *RewardChains) Contains(chainID uint64) bool {
+	for _, id := range this.ChainIDs {
+		if id == chainID {
+			return true
+		}
+	}
+	return false
+}
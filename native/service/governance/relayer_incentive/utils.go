@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_incentive
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//RewardKey return the storage key of the reward of relayer on side chain, without contract address
+func RewardKey(relayer common.Address, chainID uint64) []byte {
+	return append(append([]byte(REWARD), relayer[:]...), utils.GetUint64Bytes(chainID)...)
+}
+
+//RewardChainsKey return the storage key of the side chains relayer has reward on, without contract address
+func RewardChainsKey(relayer common.Address) []byte {
+	return append([]byte(REWARD_CHAINS), relayer[:]...)
+}
+
+func getValue(native *native.NativeService, key []byte) ([]byte, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerIncentiveContractAddress, key))
+	if err != nil {
+		return nil, fmt.Errorf("get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize from raw storage item error: %v", err)
+	}
+	return value, nil
+}
+
+func putValue(native *native.NativeService, key []byte, value []byte) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.RelayerIncentiveContractAddress, key), cstates.GenRawStorageItem(value))
+}
+
+//GetReward return the reward of relayer on side chain, a zero reward if the relayer has no reward yet
+func GetReward(native *native.NativeService, relayer common.Address, chainID uint64) (*RelayerReward, error) {
+	value, err := getValue(native, RewardKey(relayer, chainID))
+	if err != nil {
+		return nil, fmt.Errorf("GetReward, %v", err)
+	}
+	reward := NewRelayerReward()
+	if value != nil {
+		if err := reward.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, fmt.Errorf("GetReward, deserialize reward error: %v", err)
+		}
+	}
+	return reward, nil
+}
+
+func putReward(native *native.NativeService, relayer common.Address, chainID uint64, reward *RelayerReward) error {
+	chains, err := GetRewardChains(native, relayer)
+	if err != nil {
+		return fmt.Errorf("putReward, %v", err)
+	}
+	if !chains.Contains(chainID) {
+		chains.ChainIDs = append(chains.ChainIDs, chainID)
+		sink := common.NewZeroCopySink(nil)
+		chains.Serialization(sink)
+		putValue(native, RewardChainsKey(relayer), sink.Bytes())
+	}
+	sink := common.NewZeroCopySink(nil)
+	reward.Serialization(sink)
+	putValue(native, RewardKey(relayer, chainID), sink.Bytes())
+	return nil
+}
+
+//GetRewardChains return the side chains relayer has reward on
+func GetRewardChains(native *native.NativeService, relayer common.Address) (*RewardChains, error) {
+	value, err := getValue(native, RewardChainsKey(relayer))
+	if err != nil {
+		return nil, fmt.Errorf("GetRewardChains, %v", err)
+	}
+	chains := new(RewardChains)
+	if value != nil {
+		if err := chains.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, fmt.Errorf("GetRewardChains, deserialize chains error: %v", err)
+		}
+	}
+	return chains, nil
+}
+
+//GetRewardContract return the reward contract on side chain which unlocks the withdrawn reward
+func GetRewardContract(native *native.NativeService, chainID uint64) ([]byte, error) {
+	value, err := getValue(native, append([]byte(REWARD_CONTRACT), utils.GetUint64Bytes(chainID)...))
+	if err != nil {
+		return nil, fmt.Errorf("GetRewardContract, %v", err)
+	}
+	return value, nil
+}
+
+func putRewardContract(native *native.NativeService, chainID uint64, contract []byte) {
+	putValue(native, append([]byte(REWARD_CONTRACT), utils.GetUint64Bytes(chainID)...), contract)
+}
+
+//GetRewardPool return the balance of the reward pool of side chain which credits are paid from
+func GetRewardPool(native *native.NativeService, chainID uint64) (*big.Int, error) {
+	value, err := getValue(native, append([]byte(REWARD_POOL), utils.GetUint64Bytes(chainID)...))
+	if err != nil {
+		return nil, fmt.Errorf("GetRewardPool, %v", err)
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+func putRewardPool(native *native.NativeService, chainID uint64, pool *big.Int) {
+	putValue(native, append([]byte(REWARD_POOL), utils.GetUint64Bytes(chainID)...), pool.Bytes())
+}
+
+func getFundID(native *native.NativeService, chainID uint64) (uint64, error) {
+	value, err := getValue(native, append([]byte(FUND_ID), utils.GetUint64Bytes(chainID)...))
+	if err != nil {
+		return 0, fmt.Errorf("getFundID, %v", err)
+	}
+	return utils.GetBytesUint64(value), nil
+}
+
+func putFundID(native *native.NativeService, chainID uint64, fundID uint64) {
+	putValue(native, append([]byte(FUND_ID), utils.GetUint64Bytes(chainID)...), utils.GetUint64Bytes(fundID))
+}
+
+func getWithdrawID(native *native.NativeService) (uint64, error) {
+	value, err := getValue(native, []byte(WITHDRAW_ID))
+	if err != nil {
+		return 0, fmt.Errorf("getWithdrawID, %v", err)
+	}
+	return utils.GetBytesUint64(value), nil
+}
+
+func putWithdrawID(native *native.NativeService, withdrawID uint64) {
+	putValue(native, []byte(WITHDRAW_ID), utils.GetUint64Bytes(withdrawID))
+}
@@ -25,6 +25,8 @@ import (
 type RelayerListParam struct {
 	AddressList []common.Address
 	Address     common.Address
+	//Scope is optional, the relayers are not restricted if nil
+	Scope *RelayerScope
 }
 
 func (this *RelayerListParam) Serialization(sink *common.ZeroCopySink) {
@@ -33,6 +35,9 @@ func (this *RelayerListParam) Serialization(sink *common.ZeroCopySink) {
 		sink.WriteVarBytes(v[:])
 	}
 	sink.WriteVarBytes(this.Address[:])
+	if this.Scope != nil {
+		this.Scope.Serialization(sink)
+	}
 }
 
 func (this *RelayerListParam) Deserialization(source *common.ZeroCopySource) error {
@@ -61,8 +66,16 @@ func (this *RelayerListParam) Deserialization(source *common.ZeroCopySource) err
 	if err != nil {
 		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
 	}
+	var scope *RelayerScope
+	if source.Len() > 0 {
+		scope = new(RelayerScope)
+		if err := scope.Deserialization(source); err != nil {
+			return fmt.Errorf("RelayerScope.Deserialization, deserialize scope error: %s", err)
+		}
+	}
 	this.AddressList = addressList
 	this.Address = addr
+	this.Scope = scope
 	return nil
 }
 
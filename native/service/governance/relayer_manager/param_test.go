@@ -33,3 +33,22 @@ func TestRelayerListParam_Serialization(t *testing.T) {
 	err := p.Deserialization(source)
 	assert.Nil(t, err)
 }
+
+func TestRelayerListParamWithScope(t *testing.T) {
+	params := &RelayerListParam{
+		AddressList: []common.Address{{1, 2, 4, 6}},
+		Address:     common.Address{1},
+		Scope: &RelayerScope{
+			ChainIDs: []uint64{2, 7},
+			Methods:  []string{"syncBlockHeader"},
+			Quota:    5,
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+
+	var p RelayerListParam
+	err := p.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, params, &p)
+}
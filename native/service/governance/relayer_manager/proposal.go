@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_REGISTER_RELAYER,
+		Check:   checkRegisterRelayer,
+		Execute: executeRegisterRelayer,
+	})
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_REMOVE_RELAYER,
+		Check:   checkRemoveRelayer,
+		Execute: executeRemoveRelayer,
+	})
+}
+
+func checkRegisterRelayer(native *native.NativeService, content []byte) error {
+	applyID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	_, err = getRelayerApply(native, applyID)
+	return err
+}
+
+func executeRegisterRelayer(native *native.NativeService, content []byte) error {
+	applyID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	relayerListParam, err := getRelayerApply(native, applyID)
+	if err != nil {
+		return err
+	}
+	for _, address := range relayerListParam.AddressList {
+		err = putRelayer(native, address)
+		if err != nil {
+			return fmt.Errorf("putRelayer error: %v", err)
+		}
+		if !isScopeEnabled(native) {
+			continue
+		}
+		if relayerListParam.Scope != nil {
+			putRelayerScope(native, address, relayerListParam.Scope)
+		} else {
+			deleteRelayerScope(native, address)
+		}
+	}
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_APPLY), utils.GetUint64Bytes(applyID)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States:          []interface{}{"ApproveRegisterRelayer", applyID},
+		})
+	return nil
+}
+
+func checkRemoveRelayer(native *native.NativeService, content []byte) error {
+	removeID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	_, err = getRelayerRemove(native, removeID)
+	return err
+}
+
+func executeRemoveRelayer(native *native.NativeService, content []byte) error {
+	removeID, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	relayerListParam, err := getRelayerRemove(native, removeID)
+	if err != nil {
+		return err
+	}
+	for _, address := range relayerListParam.AddressList {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), address[:]))
+		if isScopeEnabled(native) {
+			deleteRelayerScope(native, address)
+		}
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States:          []interface{}{"ApproveRemoveRelayer", removeID},
+		})
+	return nil
+}
@@ -19,11 +19,11 @@ package relayer_manager
 
 import (
 	"fmt"
-	"github.com/polynetwork/poly/native/event"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
-	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -40,6 +40,8 @@ const (
 	RELAYER_REMOVE = "relayerRemove"
 	APPLY_ID       = "applyID"
 	REMOVE_ID      = "removeID"
+	RELAYER_SCOPE  = "relayerScope"
+	RELAYER_USAGE  = "relayerUsage"
 )
 
 //Register methods of node_manager contract
@@ -59,6 +61,9 @@ func RegisterRelayer(native *native.NativeService) ([]byte, error) {
 	if err := utils.ValidateOwner(native, params.Address); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRelayer, checkWitness: %s, error: %v", params.Address.ToBase58(), err)
 	}
+	if params.Scope != nil && !isScopeEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterRelayer, relayer scope is not enabled")
+	}
 	if err := putRelayerApply(native, params); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRelayer, putRelayer error: %v", err)
 	}
@@ -77,32 +82,16 @@ func ApproveRegisterRelayer(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, checkWitness error: %v", err)
 	}
 
-	relayerListParam, err := getRelayerApply(native, params.ID)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, getRelayerApply error: %v", err)
+	if err := checkRegisterRelayer(native, utils.GetUint64Bytes(params.ID)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, %v", err)
 	}
 
-	//check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_REGISTER_RELAYER, utils.GetUint64Bytes(params.ID), params.Address)
+	//vote for the proposal, it is executed once approved by consensus peers
+	_, err = proposal_manager.Approve(native, proposal_manager.PROPOSAL_REGISTER_RELAYER, APPROVE_REGISTER_RELAYER,
+		utils.GetUint64Bytes(params.ID), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, CheckConsensusSigns error: %v", err)
-	}
-	if !ok {
-		return utils.BYTE_TRUE, nil
-	}
-
-	for _, address := range relayerListParam.AddressList {
-		err = putRelayer(native, address)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, putRelayer error: %v", err)
-		}
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, Approve error: %v", err)
 	}
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_APPLY), utils.GetUint64Bytes(params.ID)))
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.RelayerManagerContractAddress,
-			States:          []interface{}{"ApproveRegisterRelayer", params.ID},
-		})
 	return utils.BYTE_TRUE, nil
 }
 
@@ -134,27 +123,70 @@ func ApproveRemoveRelayer(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveRelayer, checkWitness error: %v", err)
 	}
 
-	relayerListParam, err := getRelayerRemove(native, params.ID)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveRelayer, getRelayerRemove error: %v", err)
+	if err := checkRemoveRelayer(native, utils.GetUint64Bytes(params.ID)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveRelayer, %v", err)
 	}
 
-	//check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_REMOVE_RELAYER, utils.GetUint64Bytes(params.ID), params.Address)
+	//vote for the proposal, it is executed once approved by consensus peers
+	_, err = proposal_manager.Approve(native, proposal_manager.PROPOSAL_REMOVE_RELAYER, APPROVE_REMOVE_RELAYER,
+		utils.GetUint64Bytes(params.ID), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveRelayer, CheckConsensusSigns error: %v", err)
-	}
-	if !ok {
-		return utils.BYTE_TRUE, nil
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveRelayer, Approve error: %v", err)
 	}
+	return utils.BYTE_TRUE, nil
+}
 
-	for _, address := range relayerListParam.AddressList {
-		native.GetCacheDB().Delete(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), address[:]))
+//isScopeEnabled return whether the scope of relayers is enforced at the height of the native service
+func isScopeEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetRelayerScopeHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//CheckRelayerScope checks that a relayer signing the tx is allowed to call method for chainID and has quota left
+//in the current block. Txs without any relayer signer are not restricted here, the tx pool only accepts them from
+//permitted addresses.
+func CheckRelayerScope(native *native.NativeService, chainID uint64, method string) error {
+	if !isScopeEnabled(native) {
+		return nil
 	}
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.RelayerManagerContractAddress,
-			States:          []interface{}{"ApproveRemoveRelayer", params.ID},
-		})
-	return utils.BYTE_TRUE, nil
+	hasRelayer := false
+	for _, address := range native.GetTx().SignedAddr {
+		ok, err := IsRelayer(native, address)
+		if err != nil {
+			return fmt.Errorf("CheckRelayerScope, %v", err)
+		}
+		if !ok {
+			continue
+		}
+		hasRelayer = true
+		scope, err := GetRelayerScope(native, address)
+		if err != nil {
+			return fmt.Errorf("CheckRelayerScope, %v", err)
+		}
+		if scope == nil {
+			return nil
+		}
+		if !scope.Allow(chainID, method) {
+			continue
+		}
+		if scope.Quota == 0 {
+			return nil
+		}
+		usage, err := getRelayerUsage(native, address)
+		if err != nil {
+			return fmt.Errorf("CheckRelayerScope, %v", err)
+		}
+		if usage.Height != native.GetHeight() {
+			usage = &RelayerUsage{Height: native.GetHeight()}
+		}
+		if usage.Count >= scope.Quota {
+			continue
+		}
+		usage.Count++
+		putRelayerUsage(native, address, usage)
+		return nil
+	}
+	if hasRelayer {
+		return fmt.Errorf("CheckRelayerScope, no relayer of tx is allowed to call %s for chain %d or quota exceeded", method, chainID)
+	}
+	return nil
 }
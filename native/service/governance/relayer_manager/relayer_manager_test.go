@@ -20,6 +20,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
 	cstates "github.com/polynetwork/poly/core/states"
@@ -197,3 +198,63 @@ func TestRemoveRelayer(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckRelayerScope(t *testing.T) {
+	networkID := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkID }()
+
+	scoped := account.NewAccount("scoped")
+	params := &RelayerListParam{
+		AddressList: []common.Address{scoped.Address},
+		Address:     acct.Address,
+		Scope:       &RelayerScope{ChainIDs: []uint64{2}, Methods: []string{"syncBlockHeader"}, Quota: 2},
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	consensus := conAccts()
+	nativeService = NewNative(sink.Bytes(), tx, nil)
+	putPeerMapPoolAndView(nativeService.GetCacheDB(), consensus)
+	_, err := RegisterRelayer(nativeService)
+	assert.Nil(t, err)
+
+	for _, conAcct := range consensus[:(2*len(consensus)+2)/3] {
+		sink := common.NewZeroCopySink(nil)
+		(&ApproveRelayerParam{0, conAcct.Address}).Serialization(sink)
+		tx := &types.Transaction{
+			SignedAddr: []common.Address{conAcct.Address},
+		}
+		nativeService = NewNative(sink.Bytes(), tx, nativeService.GetCacheDB())
+		_, err := ApproveRegisterRelayer(nativeService)
+		assert.Nil(t, err)
+	}
+	scope, err := GetRelayerScope(nativeService, scoped.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, params.Scope, scope)
+
+	tx = &types.Transaction{
+		SignedAddr: []common.Address{scoped.Address},
+	}
+	db := nativeService.GetCacheDB()
+	newNative := func(height uint32) *native.NativeService {
+		ns, _ := native.NewNativeService(db, tx, 0, height, common.Uint256{0}, 0, nil, false)
+		return ns
+	}
+	assert.Nil(t, CheckRelayerScope(newNative(1), 2, "syncBlockHeader"))
+	assert.NotNil(t, CheckRelayerScope(newNative(1), 3, "syncBlockHeader"))
+	assert.NotNil(t, CheckRelayerScope(newNative(1), 2, "ImportOuterTransfer"))
+
+	// quota of a block
+	assert.Nil(t, CheckRelayerScope(newNative(1), 2, "syncBlockHeader"))
+	assert.NotNil(t, CheckRelayerScope(newNative(1), 2, "syncBlockHeader"))
+	assert.Nil(t, CheckRelayerScope(newNative(2), 2, "syncBlockHeader"))
+
+	// signer which is not relayer is not restricted by scope
+	tx = &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	assert.Nil(t, CheckRelayerScope(newNative(2), 3, "syncBlockHeader"))
+}
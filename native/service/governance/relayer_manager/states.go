@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+//RelayerScope restricts the source chains and methods a relayer is allowed to call, and how many
+//times per block. Empty ChainIDs or Methods allows any chain or method, zero Quota is unlimited.
+type RelayerScope struct {
+	ChainIDs []uint64
+	Methods  []string
+	Quota    uint64
+}
+
+//Allow return whether the scope allows calling method for chain
+func (this *RelayerScope) Allow(chainID uint64, method string) bool {
+	return (len(this.ChainIDs) == 0 || containsChainID(this.ChainIDs, chainID)) &&
+		(len(this.Methods) == 0 || containsMethod(this.Methods, method))
+}
+
+func containsChainID(chainIDs []uint64, chainID uint64) bool {
+	for _, v := range chainIDs {
+		if v == chainID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, v := range methods {
+		if v == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *RelayerScope) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.ChainIDs)))
+	for _, v := range this.ChainIDs {
+		sink.WriteUint64(v)
+	}
+	sink.WriteVarUint(uint64(len(this.Methods)))
+	for _, v := range this.Methods {
+		sink.WriteString(v)
+	}
+	sink.WriteUint64(this.Quota)
+}
+
+func (this *RelayerScope) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize ChainIDs length error")
+	}
+	chainIDs := make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		chainID, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("source.NextUint64, deserialize chainID error")
+		}
+		chainIDs = append(chainIDs, chainID)
+	}
+	n, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Methods length error")
+	}
+	methods := make([]string, 0, n)
+	for i := uint64(0); i < n; i++ {
+		method, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize method error")
+		}
+		methods = append(methods, method)
+	}
+	quota, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("source.NextUint64, deserialize Quota error")
+	}
+	this.ChainIDs = chainIDs
+	this.Methods = methods
+	this.Quota = quota
+	return nil
+}
+
+//RelayerUsage counts the scoped calls of a relayer in the block at Height
+type RelayerUsage struct {
+	Height uint32
+	Count  uint64
+}
+
+func (this *RelayerUsage) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint64(this.Count)
+}
+
+func (this *RelayerUsage) Deserialization(source *common.ZeroCopySource) error {
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize Height error")
+	}
+	count, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("source.NextUint64, deserialize Count error")
+	}
+	this.Height = height
+	this.Count = count
+	return nil
+}
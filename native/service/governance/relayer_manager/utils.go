@@ -33,6 +33,77 @@ func putRelayer(native *native.NativeService, relayer common.Address) error {
 	return nil
 }
 
+//IsRelayer return whether the address is a registered relayer
+func IsRelayer(native *native.NativeService, address common.Address) (bool, error) {
+	contract := utils.RelayerManagerContractAddress
+	value, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RELAYER), address[:]))
+	if err != nil {
+		return false, fmt.Errorf("IsRelayer, get relayer error: %v", err)
+	}
+	return value != nil, nil
+}
+
+//GetRelayerScope return the scope of relayer, nil if the relayer is not restricted
+func GetRelayerScope(native *native.NativeService, relayer common.Address) (*RelayerScope, error) {
+	contract := utils.RelayerManagerContractAddress
+	scopeStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RELAYER_SCOPE), relayer[:]))
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerScope, get scopeStore error: %v", err)
+	}
+	if scopeStore == nil {
+		return nil, nil
+	}
+	scopeBytes, err := cstates.GetValueFromRawStorageItem(scopeStore)
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerScope, deserialize from raw storage item err:%v", err)
+	}
+	scope := new(RelayerScope)
+	if err := scope.Deserialization(common.NewZeroCopySource(scopeBytes)); err != nil {
+		return nil, fmt.Errorf("GetRelayerScope, deserialize scope error: %v", err)
+	}
+	return scope, nil
+}
+
+func putRelayerScope(native *native.NativeService, relayer common.Address, scope *RelayerScope) {
+	contract := utils.RelayerManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	scope.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(RELAYER_SCOPE), relayer[:]), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func deleteRelayerScope(native *native.NativeService, relayer common.Address) {
+	contract := utils.RelayerManagerContractAddress
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(RELAYER_SCOPE), relayer[:]))
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(RELAYER_USAGE), relayer[:]))
+}
+
+func getRelayerUsage(native *native.NativeService, relayer common.Address) (*RelayerUsage, error) {
+	contract := utils.RelayerManagerContractAddress
+	usage := new(RelayerUsage)
+	usageStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RELAYER_USAGE), relayer[:]))
+	if err != nil {
+		return nil, fmt.Errorf("getRelayerUsage, get usageStore error: %v", err)
+	}
+	if usageStore == nil {
+		return usage, nil
+	}
+	usageBytes, err := cstates.GetValueFromRawStorageItem(usageStore)
+	if err != nil {
+		return nil, fmt.Errorf("getRelayerUsage, deserialize from raw storage item err:%v", err)
+	}
+	if err := usage.Deserialization(common.NewZeroCopySource(usageBytes)); err != nil {
+		return nil, fmt.Errorf("getRelayerUsage, deserialize usage error: %v", err)
+	}
+	return usage, nil
+}
+
+func putRelayerUsage(native *native.NativeService, relayer common.Address, usage *RelayerUsage) {
+	contract := utils.RelayerManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	usage.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(RELAYER_USAGE), relayer[:]), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
 func putRelayerApply(native *native.NativeService, relayerListParam *RelayerListParam) error {
 	contract := utils.RelayerManagerContractAddress
 	applyID, err := getApplyID(native)
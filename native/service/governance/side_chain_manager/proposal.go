@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package side_chain_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_REGISTER_SIDE_CHAIN,
+		Check:   checkRegisterSideChain,
+		Execute: executeRegisterSideChain,
+	})
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_UPDATE_SIDE_CHAIN,
+		Check:   checkUpdateSideChain,
+		Execute: executeUpdateSideChain,
+	})
+	proposal_manager.RegisterProposalHandler(&proposal_manager.ProposalHandlerInfo{
+		Type:    proposal_manager.PROPOSAL_QUIT_SIDE_CHAIN,
+		Check:   checkQuitSideChain,
+		Execute: executeQuitSideChain,
+	})
+}
+
+func getSideChainApplyOfProposal(native *native.NativeService, content []byte) (uint64, *SideChain, error) {
+	chainid, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return 0, nil, err
+	}
+	registerSideChain, err := getSideChainApply(native, chainid)
+	if err != nil {
+		return 0, nil, fmt.Errorf("getRegisterSideChain error: %v", err)
+	}
+	if registerSideChain == nil {
+		return 0, nil, fmt.Errorf("chainid is not requested")
+	}
+	return chainid, registerSideChain, nil
+}
+
+func checkRegisterSideChain(native *native.NativeService, content []byte) error {
+	_, _, err := getSideChainApplyOfProposal(native, content)
+	return err
+}
+
+func executeRegisterSideChain(native *native.NativeService, content []byte) error {
+	chainid, registerSideChain, err := getSideChainApplyOfProposal(native, content)
+	if err != nil {
+		return err
+	}
+	err = PutSideChain(native, registerSideChain)
+	if err != nil {
+		return fmt.Errorf("putSideChain error: %v", err)
+	}
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN_APPLY), utils.GetUint64Bytes(chainid)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"ApproveRegisterSideChain", chainid},
+		})
+	return nil
+}
+
+func getUpdateSideChainOfProposal(native *native.NativeService, content []byte) (uint64, *SideChain, error) {
+	chainid, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return 0, nil, err
+	}
+	sideChain, err := getUpdateSideChain(native, chainid)
+	if err != nil {
+		return 0, nil, fmt.Errorf("getUpdateSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return 0, nil, fmt.Errorf("chainid is not requested update")
+	}
+	return chainid, sideChain, nil
+}
+
+func checkUpdateSideChain(native *native.NativeService, content []byte) error {
+	_, _, err := getUpdateSideChainOfProposal(native, content)
+	return err
+}
+
+func executeUpdateSideChain(native *native.NativeService, content []byte) error {
+	chainid, sideChain, err := getUpdateSideChainOfProposal(native, content)
+	if err != nil {
+		return err
+	}
+	err = PutSideChain(native, sideChain)
+	if err != nil {
+		return fmt.Errorf("putSideChain error: %v", err)
+	}
+	chainidByte := utils.GetUint64Bytes(chainid)
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(UPDATE_SIDE_CHAIN_REQUEST), chainidByte))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"ApproveUpdateSideChain", chainid},
+		})
+	return nil
+}
+
+func checkQuitSideChain(native *native.NativeService, content []byte) error {
+	chainid, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	return getQuitSideChain(native, chainid)
+}
+
+func executeQuitSideChain(native *native.NativeService, content []byte) error {
+	chainid, err := proposal_manager.ParseIDContent(content)
+	if err != nil {
+		return err
+	}
+	if err := getQuitSideChain(native, chainid); err != nil {
+		return err
+	}
+	chainidByte := utils.GetUint64Bytes(chainid)
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(QUIT_SIDE_CHAIN), chainidByte))
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN), chainidByte))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"ApproveQuitSideChain", chainid},
+		})
+	return nil
+}
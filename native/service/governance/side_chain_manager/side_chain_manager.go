@@ -30,7 +30,7 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
-	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -137,34 +137,16 @@ func ApproveRegisterSideChain(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, checkWitness error: %v", err)
 	}
 
-	registerSideChain, err := getSideChainApply(native, params.Chainid)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, getRegisterSideChain error: %v", err)
-	}
-	if registerSideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, chainid is not requested")
-	}
-
-	//check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_REGISTER_SIDE_CHAIN, utils.GetUint64Bytes(params.Chainid),
-		params.Address)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, CheckConsensusSigns error: %v", err)
-	}
-	if !ok {
-		return utils.BYTE_TRUE, nil
+	if err := checkRegisterSideChain(native, utils.GetUint64Bytes(params.Chainid)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, %v", err)
 	}
 
-	err = PutSideChain(native, registerSideChain)
+	//vote for the proposal, it is executed once approved by consensus peers
+	_, err = proposal_manager.Approve(native, proposal_manager.PROPOSAL_REGISTER_SIDE_CHAIN, APPROVE_REGISTER_SIDE_CHAIN,
+		utils.GetUint64Bytes(params.Chainid), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, putSideChain error: %v", err)
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterSideChain, Approve error: %v", err)
 	}
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN_APPLY), utils.GetUint64Bytes(params.Chainid)))
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"ApproveRegisterSideChain", params.Chainid},
-		})
 	return utils.BYTE_TRUE, nil
 }
 
@@ -223,35 +205,16 @@ func ApproveUpdateSideChain(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, checkWitness error: %v", err)
 	}
 
-	sideChain, err := getUpdateSideChain(native, params.Chainid)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, getUpdateSideChain error: %v", err)
-	}
-	if sideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, chainid is not requested update")
-	}
-
-	//check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_UPDATE_SIDE_CHAIN, utils.GetUint64Bytes(params.Chainid),
-		params.Address)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, CheckConsensusSigns error: %v", err)
-	}
-	if !ok {
-		return utils.BYTE_TRUE, nil
+	if err := checkUpdateSideChain(native, utils.GetUint64Bytes(params.Chainid)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, %v", err)
 	}
 
-	err = PutSideChain(native, sideChain)
+	//vote for the proposal, it is executed once approved by consensus peers
+	_, err = proposal_manager.Approve(native, proposal_manager.PROPOSAL_UPDATE_SIDE_CHAIN, APPROVE_UPDATE_SIDE_CHAIN,
+		utils.GetUint64Bytes(params.Chainid), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, putSideChain error: %v", err)
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveUpdateSideChain, Approve error: %v", err)
 	}
-	chainidByte := utils.GetUint64Bytes(params.Chainid)
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(UPDATE_SIDE_CHAIN_REQUEST), chainidByte))
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"ApproveUpdateSideChain", params.Chainid},
-		})
 	return utils.BYTE_TRUE, nil
 }
 
@@ -307,24 +270,12 @@ func ApproveQuitSideChain(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("ApproveQuitSideChain, getQuitSideChain error: %v", err)
 	}
 
-	//check consensus signs
-	ok, err := node_manager.CheckConsensusSigns(native, QUIT_SIDE_CHAIN, utils.GetUint64Bytes(params.Chainid),
-		params.Address)
+	//vote for the proposal, it is executed once approved by consensus peers
+	_, err = proposal_manager.Approve(native, proposal_manager.PROPOSAL_QUIT_SIDE_CHAIN, QUIT_SIDE_CHAIN,
+		utils.GetUint64Bytes(params.Chainid), params.Address)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ApproveQuitSideChain, CheckConsensusSigns error: %v", err)
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveQuitSideChain, Approve error: %v", err)
 	}
-	if !ok {
-		return utils.BYTE_TRUE, nil
-	}
-
-	chainidByte := utils.GetUint64Bytes(params.Chainid)
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(QUIT_SIDE_CHAIN), chainidByte))
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN), chainidByte))
-	native.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"ApproveQuitSideChain", params.Chainid},
-		})
 	return utils.BYTE_TRUE, nil
 }
 
@@ -22,21 +22,34 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
+	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"strings"
 	"testing"
 )
 
 var (
-	acct          *account.Account = account.NewAccount("")
-	getNativeFunc                  = func(input []byte) *native.NativeService {
+	acct     *account.Account = account.NewAccount("")
+	conAccts                  = func() []*account.Account {
+		accts := make([]*account.Account, 0)
+		for i := 0; i < 4; i++ {
+			accts = append(accts, account.NewAccount(strconv.FormatUint(uint64(i), 10)))
+		}
+		return accts
+	}()
+	getNativeFunc = func(input []byte) *native.NativeService {
 		store, _ :=
 			leveldbstore.NewMemLevelDBStore()
 		cacheDB := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
@@ -95,26 +108,9 @@ func TestRegisterSideChainManager(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
-func TestApproveRegisterSideChain(t *testing.T) {
-	param := new(ChainidParam)
-	param.Chainid = 8
-
-	sink := common.NewZeroCopySink(nil)
-	param.Serialization(sink)
-
-	tx := &types.Transaction{
-		SignedAddr: []common.Address{acct.Address},
-	}
-
-	nativeService = NewNative(sink.Bytes(), tx, nativeService.GetCacheDB())
-	res, err := ApproveRegisterSideChain(nativeService)
-	assert.Equal(t, res, []byte{1})
-	assert.Nil(t, err)
-}
-
 func TestUpdateSideChain(t *testing.T) {
 	param := new(RegisterSideChainParam)
-	param.Address = common.Address{1, 2, 3}
+	param.Address = acct.Address
 	param.BlocksToWait = 10
 	param.ChainId = 8
 	param.Name = "own"
@@ -128,30 +124,128 @@ func TestUpdateSideChain(t *testing.T) {
 		SignedAddr: []common.Address{acct.Address},
 	}
 
-	nativeService = NewNative(sink.Bytes(), tx, nativeService.GetCacheDB())
+	db := nativeService.GetCacheDB()
+	sideChain, err := getSideChainApply(nativeService, 8)
+	assert.Nil(t, err)
+	assert.Nil(t, PutSideChain(nativeService, sideChain))
+
+	nativeService = NewNative(sink.Bytes(), tx, db)
 	res, err := UpdateSideChain(nativeService)
 	assert.Equal(t, res, []byte{1})
 	assert.Nil(t, err)
+
+	sideChain, err = getUpdateSideChain(nativeService, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, sideChain.Name, "own")
 }
 
-func TestApproveUpdateSideChain(t *testing.T) {
-	param := new(ChainidParam)
-	param.Chainid = 8
+func newGovernanceDB() *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	peerPoolMap := &node_manager.PeerPoolMap{PeerPoolMap: make(map[string]*node_manager.PeerPoolItem)}
+	for i, conAcct := range conAccts {
+		pkStr := vconfig.PubkeyID(conAcct.PublicKey)
+		peerPoolMap.PeerPoolMap[pkStr] = &node_manager.PeerPoolItem{
+			Index:      uint32(i),
+			PeerPubkey: pkStr,
+			Address:    conAcct.Address,
+			Status:     node_manager.ConsensusStatus,
+		}
+	}
 	sink := common.NewZeroCopySink(nil)
-	param.Serialization(sink)
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	govView := &node_manager.GovernanceView{View: 0, Height: 10, TxHash: common.UINT256_EMPTY}
+	sink = common.NewZeroCopySink(nil)
+	govView.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+	return db
+}
 
+func invoke(t *testing.T, db *storage.CacheDB, method func(*native.NativeService) ([]byte, error), args []byte,
+	signer common.Address) {
 	tx := &types.Transaction{
-		SignedAddr: []common.Address{acct.Address},
+		SignedAddr: []common.Address{signer},
 	}
+	res, err := method(NewNative(args, tx, db))
+	assert.Nil(t, err)
+	assert.Equal(t, utils.BYTE_TRUE, res)
+}
 
-	nativeService = NewNative(sink.Bytes(), tx, nativeService.GetCacheDB())
-	res, err := ApproveUpdateSideChain(nativeService)
-	assert.Equal(t, res, []byte{1})
+// testApproveSideChain registers, updates and quits a side chain, each request approved by three of the four
+// consensus peers
+func testApproveSideChain(t *testing.T) *native.NativeService {
+	db := newGovernanceDB()
+	ns := NewNative(nil, new(types.Transaction), db)
+	owner := conAccts[0].Address
+	register := &RegisterSideChainParam{Address: owner, ChainId: 8, Router: 3, Name: "mychain", BlocksToWait: 4}
+	sink := common.NewZeroCopySink(nil)
+	assert.Nil(t, register.Serialization(sink))
+	invoke(t, db, RegisterSideChain, sink.Bytes(), owner)
+
+	name := func() string {
+		sideChain, err := GetSideChain(ns, 8)
+		assert.Nil(t, err)
+		if sideChain == nil {
+			return ""
+		}
+		return sideChain.Name
+	}
+	approve := func(method func(*native.NativeService) ([]byte, error), before, after string) {
+		for _, conAcct := range conAccts[:3] {
+			assert.Equal(t, before, name())
+			sink := common.NewZeroCopySink(nil)
+			(&ChainidParam{Chainid: 8, Address: conAcct.Address}).Serialization(sink)
+			invoke(t, db, method, sink.Bytes(), conAcct.Address)
+		}
+		assert.Equal(t, after, name())
+	}
+
+	approve(ApproveRegisterSideChain, "", "mychain")
+	sideChain, err := getSideChainApply(ns, 8)
+	assert.Nil(t, err)
+	assert.Nil(t, sideChain)
+
+	update := &RegisterSideChainParam{Address: owner, ChainId: 8, Router: 3, Name: "own", BlocksToWait: 10}
+	sink = common.NewZeroCopySink(nil)
+	assert.Nil(t, update.Serialization(sink))
+	invoke(t, db, UpdateSideChain, sink.Bytes(), owner)
+	approve(ApproveUpdateSideChain, "mychain", "own")
+	sideChain, err = GetSideChain(ns, 8)
 	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), sideChain.BlocksToWait)
+	sideChain, err = getUpdateSideChain(ns, 8)
+	assert.Nil(t, err)
+	assert.Nil(t, sideChain)
 
-	sideChain, err := GetSideChain(nativeService, 8)
-	assert.Equal(t, sideChain.Name, "own")
+	sink = common.NewZeroCopySink(nil)
+	(&ChainidParam{Chainid: 8, Address: owner}).Serialization(sink)
+	invoke(t, db, QuitSideChain, sink.Bytes(), owner)
+	approve(ApproveQuitSideChain, "own", "")
+	return ns
+}
+
+func TestApproveSideChain(t *testing.T) {
+	count, err := proposal_manager.GetProposalCount(testApproveSideChain(t))
 	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), count)
+}
+
+func TestApproveSideChainProposal(t *testing.T) {
+	networkID := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkID }()
+
+	ns := testApproveSideChain(t)
+	count, err := proposal_manager.GetProposalCount(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), count)
+	for id := uint64(1); id <= count; id++ {
+		proposal, err := proposal_manager.GetProposal(ns, id)
+		assert.Nil(t, err)
+		assert.Equal(t, proposal_manager.ExecutedStatus, proposal.Status)
+	}
 }
 
 //func TestRemoveSideChain(t *testing.T) {
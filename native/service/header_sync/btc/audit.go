@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func getStoredGenesisHeader(native *native.NativeService, chainID uint64) (*StoredHeader, error) {
+	genesisStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.GENESIS_HEADER), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, get genesisStore error: %v", err)
+	}
+	if genesisStore == nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, can not find any genesis header records")
+	}
+	genesisBs, err := cstates.GetValueFromRawStorageItem(genesisStore)
+	if err != nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, deserialize genesis header from raw storage item err: %v", err)
+	}
+	sh := new(StoredHeader)
+	if err := sh.Deserialization(common.NewZeroCopySource(genesisBs)); err != nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, deserialize storedHeader error: %v", err)
+	}
+	return sh, nil
+}
+
+//auditHeaders walk the height index from genesis to best header, check the parent links and total work,
+//then report the stored headers which are not indexed. Index left above best header by ReIndexHeaderHeight is an issue
+func auditHeaders(native *native.NativeService, audit *scom.HeaderAudit) error {
+	chainID := audit.ChainID
+	genesis, err := getStoredGenesisHeader(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, %v", err)
+	}
+	best, err := GetBestBlockHeader(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, GetBestBlockHeader error: %v", err)
+	}
+	audit.GenesisHeight, audit.CurrentHeight = uint64(genesis.Height), uint64(best.Height)
+
+	mainChain := make(map[chainhash.Hash]bool)
+	var parent *StoredHeader
+	for height := genesis.Height; height <= best.Height; height++ {
+		hash, err := GetBlockHashByHeight(native, chainID, height)
+		if err != nil {
+			audit.AddIssue(uint64(height), "", "header index is missing: %v", err)
+			parent = nil
+			continue
+		}
+		mainChain[*hash] = true
+		sh, err := GetHeaderByHash(native, chainID, *hash)
+		if err != nil {
+			audit.AddIssue(uint64(height), hash.String(), "header of index is not stored: %v", err)
+			parent = nil
+			continue
+		}
+		audit.MainChain = append(audit.MainChain, &scom.AuditHeader{
+			Height:     uint64(height),
+			Hash:       hash.String(),
+			ParentHash: sh.Header.PrevBlock.String(),
+			Work:       sh.totalWork.String(),
+		})
+		if blockHash := sh.Header.BlockHash(); !blockHash.IsEqual(hash) {
+			audit.AddIssue(uint64(height), hash.String(), "stored header has hash %s", blockHash.String())
+		}
+		if sh.Height != height {
+			audit.AddIssue(uint64(height), hash.String(), "stored header has height %d", sh.Height)
+		}
+		if height == genesis.Height {
+			if genesisHash := genesis.Header.BlockHash(); !genesisHash.IsEqual(hash) {
+				audit.AddIssue(uint64(height), hash.String(), "index doesn't start from genesis header %s", genesisHash.String())
+			}
+		} else if parent != nil {
+			if parentHash := parent.Header.BlockHash(); !parentHash.IsEqual(&sh.Header.PrevBlock) {
+				audit.AddIssue(uint64(height), hash.String(), "parent %s is not the indexed header %s",
+					sh.Header.PrevBlock.String(), parentHash.String())
+			}
+			if expected := new(big.Int).Add(parent.totalWork, blockchain.CalcWork(sh.Header.Bits)); sh.totalWork.Cmp(expected) != 0 {
+				audit.AddIssue(uint64(height), hash.String(), "total work %s should be %s", sh.totalWork, expected)
+			}
+		}
+		parent = sh
+	}
+	if bestHash := best.Header.BlockHash(); !mainChain[bestHash] {
+		audit.AddIssue(uint64(best.Height), bestHash.String(), "best header is not indexed")
+	}
+	if hash, err := GetBlockHashByHeight(native, chainID, best.Height+1); err == nil {
+		audit.AddIssue(uint64(best.Height+1), hash.String(), "header index above best header is not removed")
+	}
+
+	iter := native.GetCacheDB().NewIterator(utils.ConcatKey(utils.HeaderSyncContractAddress,
+		[]byte(scom.BLOCK_HEADER), utils.GetUint64Bytes(chainID)))
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		hash, err := chainhash.NewHash(iter.Key()[len(iter.Key())-chainhash.HashSize:])
+		if err != nil {
+			return fmt.Errorf("auditHeaders, decode header hash error: %v", err)
+		}
+		if mainChain[*hash] {
+			continue
+		}
+		shBs, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return fmt.Errorf("auditHeaders, deserialize header %s from raw storage item err: %v", hash.String(), err)
+		}
+		sh := new(StoredHeader)
+		if err := sh.Deserialization(common.NewZeroCopySource(shBs)); err != nil {
+			audit.AddIssue(0, hash.String(), "deserialize orphan header error: %v", err)
+			continue
+		}
+		audit.Orphans = append(audit.Orphans, &scom.AuditHeader{
+			Height:     uint64(sh.Height),
+			Hash:       hash.String(),
+			ParentHash: sh.Header.PrevBlock.String(),
+			Work:       sh.totalWork.String(),
+		})
+		if sh.Height > genesis.Height {
+			if _, err := GetHeaderByHash(native, chainID, sh.Header.PrevBlock); err != nil {
+				audit.AddIssue(uint64(sh.Height), hash.String(), "parent %s of orphan header is not stored", sh.Header.PrevBlock.String())
+			}
+		}
+	}
+	return iter.Error()
+}
@@ -37,6 +37,14 @@ import (
 type BTCHandler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.BTC_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewBTCHandler() },
+		Audit:      auditHeaders,
+	})
+}
+
 func NewBTCHandler() *BTCHandler {
 	return &BTCHandler{}
 }
@@ -176,7 +184,7 @@ func commitHeader(native *native.NativeService, chainID uint64, header wire.Bloc
 			if err != nil {
 				return newTip, commonAncestor, 0, fmt.Errorf("Error calculating common ancestor: %s", err.Error())
 			}
-			log.Warnf("REORG! At block %d, Wiped out %d blocks", int(bestHeader.Height),
+			log.WithFields(log.Fields{"chainID": chainID, "height": bestHeader.Height}).Warnf("REORG! Wiped out %d blocks",
 				int(bestHeader.Height-commonAncestor.Height))
 		}
 	}
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"time"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -44,6 +43,14 @@ import (
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:      utils.BYTOM_ROUTER,
+		NewHandler:  func() scom.HeaderSyncHandler { return NewHandler() },
+		StartBlocks: utils.HardForkRouterStartBlocks,
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
@@ -680,7 +687,7 @@ var (
 func verifyHeader(native *native.NativeService, header *types.Header, ctx *Context) (signer ecommon.Address, err error) {
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(native.Now().Unix()) {
 		err = errors.New("block in the future")
 		return
 	}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//Handler follows cometbft chains with protobuf encoded light blocks, validator set changes between the synced
+//headers are skipped with trust level verification
+type Handler struct {
+}
+
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:      utils.COMETBFT_ROUTER,
+		NewHandler:  func() scom.HeaderSyncHandler { return NewHandler() },
+		StartBlocks: utils.CometBFTRouterStartBlocks,
+	})
+}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+//GetContext returns the consensus config stored in ExtraInfo of side chain
+func GetContext(native *native.NativeService, chainID uint64) (*Context, error) {
+	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("get side chain error %s", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("side chain %d is not registered", chainID)
+	}
+	return DecodeContext(sideChain.ExtraInfo)
+}
+
+//SyncGenesisHeader stores a trusted light block as the start point of the chain
+func (this *Handler) SyncGenesisHeader(native *native.NativeService) error {
+	params := new(scom.SyncGenesisHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, contract params deserialize error: %v", err)
+	}
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, get current consensus operator address error: %v", err)
+	}
+	if err := utils.ValidateOwner(native, operatorAddress); err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, checkWitness error: %v", err)
+	}
+	trusted, err := GetTrustedState(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, get trusted state error: %v", err)
+	}
+	if trusted != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, genesis header had been initialized")
+	}
+	ctx, err := GetContext(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, %v", err)
+	}
+	block, err := DecodeLightBlock(params.GenesisHeader)
+	if err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, %v", err)
+	}
+	if err := ctx.VerifyGenesis(block); err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, %v", err)
+	}
+	state, err := NewTrustedState(block)
+	if err != nil {
+		return fmt.Errorf("CometBFTHandler SyncGenesisHeader, %v", err)
+	}
+	putTrustedState(native, params.ChainID, state)
+	return nil
+}
+
+//SyncBlockHeader verifies light blocks in ascending height order, each header of params is a protobuf encoded
+//LightBlock
+func (this *Handler) SyncBlockHeader(native *native.NativeService) error {
+	params := new(scom.SyncBlockHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("CometBFTHandler SyncBlockHeader, contract params deserialize error: %v", err)
+	}
+	ctx, err := GetContext(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("CometBFTHandler SyncBlockHeader, %v", err)
+	}
+	for i, v := range params.Headers {
+		if err := SyncLightBlock(native, ctx, params.ChainID, v); err != nil {
+			return fmt.Errorf("CometBFTHandler SyncBlockHeader, header %d error: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (this *Handler) SyncCrossChainMsg(native *native.NativeService) error {
+	return nil
+}
+
+//SyncLightBlock verifies the protobuf encoded light block against trusted state and makes it the new trusted state
+func SyncLightBlock(native *native.NativeService, ctx *Context, chainID uint64, data []byte) error {
+	trusted, err := GetTrustedState(native, chainID)
+	if err != nil {
+		return fmt.Errorf("get trusted state error: %v", err)
+	}
+	if trusted == nil {
+		return fmt.Errorf("genesis header of chain %d is not synced", chainID)
+	}
+	block, err := DecodeLightBlock(data)
+	if err != nil {
+		return err
+	}
+	if err := ctx.VerifyLightBlock(trusted, block, time.Unix(int64(native.GetTime()), 0)); err != nil {
+		return err
+	}
+	state, err := NewTrustedState(block)
+	if err != nil {
+		return err
+	}
+	putTrustedState(native, chainID, state)
+	return nil
+}
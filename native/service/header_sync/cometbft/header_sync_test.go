@@ -0,0 +1,297 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	pcom "github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	ptypes "github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/switcheo/tendermint/crypto/ed25519"
+	"github.com/switcheo/tendermint/crypto/tmhash"
+	tmmath "github.com/switcheo/tendermint/libs/math"
+	tmproto "github.com/switcheo/tendermint/proto/tendermint/types"
+	tmversion "github.com/switcheo/tendermint/proto/tendermint/version"
+	"github.com/switcheo/tendermint/types"
+	"github.com/switcheo/tendermint/version"
+)
+
+const TEST_CHAIN_ID = "cometbft-test"
+
+var (
+	acct        = account.NewAccount("")
+	testChainID = uint64(25)
+	testContext = &Context{ChainID: TEST_CHAIN_ID, TrustingPeriod: 14 * 24 * 3600, TrustLevel: DefaultTrustLevel, StoreName: "ccm"}
+	genesisTime = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testPVs     = map[string]types.PrivValidator{}
+)
+
+func init() {
+	genesis.GenesisBookkeepers = []keypair.PublicKey{acct.PublicKey}
+}
+
+//testValidators returns the validator set of validators with index in [from, to)
+func testValidators(from, to int) *types.ValidatorSet {
+	var valz []*types.Validator
+	for i := from; i < to; i++ {
+		privKey := ed25519.GenPrivKeyFromSecret([]byte(fmt.Sprintf("validator%d", i)))
+		val := types.NewValidator(privKey.PubKey(), 10)
+		testPVs[string(val.Address)] = types.NewMockPVWithParams(privKey, false, false)
+		valz = append(valz, val)
+	}
+	return types.NewValidatorSet(valz)
+}
+
+//testLightBlock builds a light block signed by the first signers of vals
+func testLightBlock(chainID string, height int64, blockTime time.Time, vals, nextVals *types.ValidatorSet,
+	appHash []byte, signers int) *types.LightBlock {
+	hash := tmhash.Sum([]byte(fmt.Sprintf("height%d", height)))
+	header := &types.Header{
+		Version:            tmversion.Consensus{Block: version.BlockProtocol},
+		ChainID:            chainID,
+		Height:             height,
+		Time:               blockTime,
+		LastBlockID:        types.BlockID{Hash: hash, PartSetHeader: types.PartSetHeader{Total: 1, Hash: hash}},
+		LastCommitHash:     hash,
+		DataHash:           hash,
+		ValidatorsHash:     vals.Hash(),
+		NextValidatorsHash: nextVals.Hash(),
+		ConsensusHash:      hash,
+		AppHash:            appHash,
+		LastResultsHash:    hash,
+		EvidenceHash:       hash,
+		ProposerAddress:    vals.Validators[0].Address,
+	}
+	blockID := types.BlockID{Hash: header.Hash(), PartSetHeader: types.PartSetHeader{Total: 1, Hash: hash}}
+	sigs := make([]types.CommitSig, len(vals.Validators))
+	for i, val := range vals.Validators {
+		if i >= signers {
+			sigs[i] = types.NewCommitSigAbsent()
+			continue
+		}
+		vote := &types.Vote{
+			Type:             tmproto.PrecommitType,
+			Height:           height,
+			Round:            1,
+			BlockID:          blockID,
+			Timestamp:        blockTime,
+			ValidatorAddress: val.Address,
+			ValidatorIndex:   int32(i),
+		}
+		pb := vote.ToProto()
+		if err := testPVs[string(val.Address)].SignVote(chainID, pb); err != nil {
+			panic(err)
+		}
+		sigs[i] = types.NewCommitSigForBlock(pb.Signature, val.Address, blockTime)
+	}
+	return &types.LightBlock{
+		SignedHeader: &types.SignedHeader{Header: header, Commit: types.NewCommit(height, 1, blockID, sigs)},
+		ValidatorSet: vals,
+	}
+}
+
+func encodeLightBlock(block *types.LightBlock) []byte {
+	pb, err := block.ToProto()
+	if err != nil {
+		panic(err)
+	}
+	data, err := pb.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestDecodeContext(t *testing.T) {
+	ctx, err := DecodeContext([]byte(`{"chain_id":"cosmoshub-4","trusting_period":1209600}`))
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultTrustLevel, ctx.TrustLevel)
+	assert.Equal(t, 14*24*time.Hour, ctx.trustingPeriod())
+
+	ctx, err = DecodeContext([]byte(`{"chain_id":"cosmoshub-4","trusting_period":1209600,"trust_level":{"numerator":2,"denominator":3}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, tmmath.Fraction{Numerator: 2, Denominator: 3}, ctx.TrustLevel)
+
+	for _, data := range []string{
+		`{"trusting_period":1209600}`,
+		`{"chain_id":"cosmoshub-4"}`,
+		`{"chain_id":"cosmoshub-4","trusting_period":1209600,"trust_level":{"numerator":1,"denominator":4}}`,
+		`{"chain_id":"cosmoshub-4","trusting_period":1209600,"trust_level":{"numerator":4,"denominator":3}}`,
+		`{"chain_id":"cosmoshub-4","trusting_period":1209600,"trust_level":{"numerator":1,"denominator":0}}`,
+	} {
+		_, err = DecodeContext([]byte(data))
+		assert.NotNil(t, err, data)
+	}
+}
+
+func TestVerifyLightBlock(t *testing.T) {
+	ctx := testContext
+	valsA, valsB, valsC := testValidators(0, 4), testValidators(2, 6), testValidators(4, 8)
+	appHash := tmhash.Sum([]byte("app"))
+
+	genesisBlock := testLightBlock(TEST_CHAIN_ID, 10, genesisTime, valsA, valsA, appHash, 4)
+	assert.Nil(t, ctx.VerifyGenesis(genesisBlock))
+	assert.NotNil(t, ctx.VerifyGenesis(testLightBlock(TEST_CHAIN_ID, 10, genesisTime, valsA, valsA, appHash, 2)))
+	assert.NotNil(t, ctx.VerifyGenesis(testLightBlock("other", 10, genesisTime, valsA, valsA, appHash, 4)))
+
+	trusted, err := NewTrustedState(genesisBlock)
+	assert.Nil(t, err)
+	now := genesisTime.Add(2 * time.Hour)
+	vals, err := trusted.ValidatorSet()
+	assert.Nil(t, err)
+	assert.Equal(t, valsA.Hash(), vals.Hash())
+
+	//adjacent block is signed by trusted next validators
+	assert.Nil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 11, genesisTime.Add(time.Second), valsA,
+		valsB, appHash, 3), now))
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 11, genesisTime.Add(time.Second), valsA,
+		valsB, appHash, 2), now))
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 11, genesisTime.Add(time.Second), valsB,
+		valsB, appHash, 4), now))
+
+	//skipping verification needs trust level of trusted validators
+	assert.Nil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 100, genesisTime.Add(time.Hour), valsB,
+		valsB, appHash, 4), now))
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 100, genesisTime.Add(time.Hour), valsC,
+		valsC, appHash, 4), now))
+	strict := &Context{ChainID: TEST_CHAIN_ID, TrustingPeriod: ctx.TrustingPeriod, TrustLevel: tmmath.Fraction{Numerator: 2, Denominator: 3}}
+	assert.NotNil(t, strict.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 100, genesisTime.Add(time.Hour), valsB,
+		valsB, appHash, 4), now))
+
+	//trusted header expires after trusting period of poly time, whatever the time of block is
+	expired := genesisTime.Add(ctx.trustingPeriod())
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 100, genesisTime.Add(time.Hour), valsB,
+		valsB, appHash, 4), expired))
+	//block time can't be too far ahead of poly time
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 100, now.Add(time.Minute), valsB,
+		valsB, appHash, 4), now))
+	//height and time must move forward
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 10, genesisTime.Add(time.Second), valsA,
+		valsA, appHash, 4), now))
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock(TEST_CHAIN_ID, 11, genesisTime, valsA, valsA, appHash, 4), now))
+	assert.NotNil(t, ctx.VerifyLightBlock(trusted, testLightBlock("other", 11, genesisTime.Add(time.Second), valsA,
+		valsA, appHash, 4), now))
+}
+
+func newNative(args []byte, tx *ptypes.Transaction, db *storage.CacheDB, ctx *Context) *native.NativeService {
+	if db == nil {
+		store, _ := leveldbstore.NewMemLevelDBStore()
+		db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+		sink := pcom.NewZeroCopySink(nil)
+		view := &node_manager.GovernanceView{TxHash: pcom.UINT256_EMPTY}
+		view.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)), states.GenRawStorageItem(sink.Bytes()))
+		peerPoolMap := &node_manager.PeerPoolMap{
+			PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+				vconfig.PubkeyID(acct.PublicKey): {
+					Address:    acct.Address,
+					Status:     node_manager.ConsensusStatus,
+					PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+				},
+			},
+		}
+		sink.Reset()
+		peerPoolMap.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+			states.GenRawStorageItem(sink.Bytes()))
+	}
+	service, _ := native.NewNativeService(db, tx, uint32(genesisTime.Add(2*time.Hour).Unix()), 0, pcom.Uint256{0}, 0,
+		args, false)
+	if ctx != nil {
+		extraInfo, _ := json.Marshal(ctx)
+		_ = side_chain_manager.PutSideChain(service, &side_chain_manager.SideChain{
+			ChainId:   testChainID,
+			Router:    utils.COMETBFT_ROUTER,
+			ExtraInfo: extraInfo,
+		})
+	}
+	return service
+}
+
+func TestSyncHeader(t *testing.T) {
+	handler := NewHandler()
+	valsA, valsB := testValidators(0, 4), testValidators(2, 6)
+
+	genesisBlock := testLightBlock(TEST_CHAIN_ID, 10, genesisTime, valsA, valsA, tmhash.Sum([]byte("app10")), 4)
+	param := &scom.SyncGenesisHeaderParam{ChainID: testChainID, GenesisHeader: encodeLightBlock(genesisBlock)}
+	sink := pcom.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	service := newNative(sink.Bytes(), &ptypes.Transaction{}, nil, testContext)
+	assert.NotNil(t, handler.SyncGenesisHeader(service))
+
+	tx := &ptypes.Transaction{SignedAddr: []pcom.Address{acct.Address}}
+	service = newNative(sink.Bytes(), tx, service.GetCacheDB(), nil)
+	assert.Nil(t, handler.SyncGenesisHeader(service))
+	height, err := GetCurrentHeaderHeight(service, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), height)
+	assert.NotNil(t, handler.SyncGenesisHeader(service))
+
+	blocks := []*types.LightBlock{
+		testLightBlock(TEST_CHAIN_ID, 11, genesisTime.Add(time.Second), valsA, valsB, tmhash.Sum([]byte("app11")), 4),
+		testLightBlock(TEST_CHAIN_ID, 12, genesisTime.Add(2*time.Second), valsB, valsB, tmhash.Sum([]byte("app12")), 4),
+		testLightBlock(TEST_CHAIN_ID, 500, genesisTime.Add(time.Hour), valsB, valsB, tmhash.Sum([]byte("app500")), 3),
+	}
+	blockParam := &scom.SyncBlockHeaderParam{ChainID: testChainID}
+	for _, block := range blocks {
+		blockParam.Headers = append(blockParam.Headers, encodeLightBlock(block))
+	}
+	sink.Reset()
+	blockParam.Serialization(sink)
+	service = newNative(sink.Bytes(), &ptypes.Transaction{}, service.GetCacheDB(), nil)
+	assert.Nil(t, handler.SyncBlockHeader(service))
+	assert.Equal(t, uint32(len(blocks)), service.GetStoredHeaders())
+
+	height, err = GetCurrentHeaderHeight(service, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), height)
+	for _, block := range blocks {
+		header, err := GetHeaderByHeight(service, uint64(block.Height), testChainID)
+		assert.Nil(t, err)
+		assert.Equal(t, block.AppHash, header.AppHash)
+		assert.Equal(t, block.Hash(), header.Hash)
+		assert.True(t, block.Time.Equal(header.Time))
+	}
+	_, err = GetHeaderByHeight(service, 100, testChainID)
+	assert.NotNil(t, err)
+	trusted, err := GetTrustedState(service, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(500), trusted.Height)
+	assert.Equal(t, valsB.Hash(), []byte(trusted.NextValidatorsHash))
+
+	//headers are not accepted twice
+	service = newNative(sink.Bytes(), &ptypes.Transaction{}, service.GetCacheDB(), nil)
+	assert.NotNil(t, handler.SyncBlockHeader(service))
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func keyForTrustedState(chainID uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.TRUSTED_STATE), utils.GetUint64Bytes(chainID))
+}
+
+func keyForHeader(chainID, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.BLOCK_HEADER), utils.GetUint64Bytes(chainID),
+		utils.GetUint64Bytes(height))
+}
+
+func keyForHeaderHeight(chainID uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID))
+}
+
+func getStorage(native *native.NativeService, key []byte, value interface{}) (bool, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return false, fmt.Errorf("get storage error %s", err)
+	}
+	if store == nil {
+		return false, nil
+	}
+	data, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return false, fmt.Errorf("deserialize from raw storage item error %s", err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("unmarshal storage error %s", err)
+	}
+	return true, nil
+}
+
+func putStorage(native *native.NativeService, key []byte, value interface{}) {
+	data, _ := json.Marshal(value)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(data))
+}
+
+//GetTrustedState returns the latest verified header with its validators, nil if genesis header is not synced
+func GetTrustedState(native *native.NativeService, chainID uint64) (*TrustedState, error) {
+	state := new(TrustedState)
+	exist, err := getStorage(native, keyForTrustedState(chainID), state)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return state, nil
+}
+
+//putTrustedState moves the trusted state forward and keeps its header for proof verification
+func putTrustedState(native *native.NativeService, chainID uint64, state *TrustedState) {
+	putStorage(native, keyForTrustedState(chainID), state)
+	height := uint64(state.Height)
+	putStorage(native, keyForHeader(chainID, height), &state.Header)
+	native.GetCacheDB().Put(keyForHeaderHeight(chainID), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
+	scom.NotifyPutHeader(native, chainID, height, state.Hash.String())
+}
+
+//GetCurrentHeaderHeight returns the height of the latest verified header
+func GetCurrentHeaderHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(keyForHeaderHeight(chainID))
+	if err != nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight error %s", err)
+	}
+	if store == nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight, current header height of chain %d not found", chainID)
+	}
+	heightBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight, deserialize from raw storage item error %s", err)
+	}
+	return utils.GetBytesUint64(heightBytes), nil
+}
+
+//GetHeaderByHeight returns the verified header at height
+func GetHeaderByHeight(native *native.NativeService, height, chainID uint64) (*Header, error) {
+	header := new(Header)
+	exist, err := getStorage(native, keyForHeader(chainID, height), header)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHeight error %s", err)
+	}
+	if !exist {
+		return nil, fmt.Errorf("GetHeaderByHeight, header of chain %d at height %d not found", chainID, height)
+	}
+	return header, nil
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	tmbytes "github.com/switcheo/tendermint/libs/bytes"
+	tmmath "github.com/switcheo/tendermint/libs/math"
+	tmproto "github.com/switcheo/tendermint/proto/tendermint/types"
+	"github.com/switcheo/tendermint/types"
+)
+
+//MAX_CLOCK_DRIFT is how far the time of light block may be ahead of the poly block time
+const MAX_CLOCK_DRIFT = 10 * time.Second
+
+//DefaultTrustLevel is the minimum trust level which does not break the security model of skipping verification
+var DefaultTrustLevel = tmmath.Fraction{Numerator: 1, Denominator: 3}
+
+//Context is the consensus config of side chain, stored in ExtraInfo of side chain
+type Context struct {
+	ChainID        string          `json:"chain_id"`
+	TrustingPeriod uint64          `json:"trusting_period"` // in seconds, should be shorter than the unbonding period
+	TrustLevel     tmmath.Fraction `json:"trust_level"`
+	StoreName      string          `json:"store_name"` // store of cross chain manager module in the multi store
+}
+
+//DecodeContext decodes the side chain context and fills the default trust level if it is missing
+func DecodeContext(data []byte) (*Context, error) {
+	ctx := new(Context)
+	if err := json.Unmarshal(data, ctx); err != nil {
+		return nil, fmt.Errorf("unmarshal context error %s", err)
+	}
+	if ctx.ChainID == "" {
+		return nil, fmt.Errorf("chain id is missing in context")
+	}
+	if ctx.TrustingPeriod == 0 || ctx.TrustingPeriod > uint64(math.MaxInt64/int64(time.Second)) {
+		return nil, fmt.Errorf("invalid trusting period %d", ctx.TrustingPeriod)
+	}
+	if ctx.TrustLevel.Denominator == 0 && ctx.TrustLevel.Numerator == 0 {
+		ctx.TrustLevel = DefaultTrustLevel
+	}
+	lvl := ctx.TrustLevel
+	if lvl.Denominator == 0 || lvl.Numerator > lvl.Denominator || lvl.Numerator*3 < lvl.Denominator {
+		return nil, fmt.Errorf("trust level %s should be within [1/3, 1]", lvl)
+	}
+	return ctx, nil
+}
+
+func (ctx *Context) trustingPeriod() time.Duration {
+	return time.Duration(ctx.TrustingPeriod) * time.Second
+}
+
+//Header is the verified block header kept for proof verification, the app hash of header at height H commits the
+//application state after block H-1
+type Header struct {
+	Height  int64            `json:"height"`
+	Time    time.Time        `json:"time"`
+	Hash    tmbytes.HexBytes `json:"hash"`
+	AppHash tmbytes.HexBytes `json:"app_hash"`
+}
+
+//TrustedState is the latest verified header with its validators, the start point of next verification
+type TrustedState struct {
+	Header
+	NextValidatorsHash tmbytes.HexBytes `json:"next_validators_hash"`
+	Validators         []byte           `json:"validators"` // protobuf encoded validator set of the header
+}
+
+//NewTrustedState returns the trusted state of a verified light block
+func NewTrustedState(block *types.LightBlock) (*TrustedState, error) {
+	vals, err := block.ValidatorSet.ToProto()
+	if err != nil {
+		return nil, fmt.Errorf("validator set to proto error %s", err)
+	}
+	data, err := vals.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshal validator set error %s", err)
+	}
+	return &TrustedState{
+		Header:             *NewHeader(block.SignedHeader),
+		NextValidatorsHash: block.NextValidatorsHash,
+		Validators:         data,
+	}, nil
+}
+
+//NewHeader returns the fields of signed header needed by proof verification
+func NewHeader(header *types.SignedHeader) *Header {
+	return &Header{
+		Height:  header.Height,
+		Time:    header.Time,
+		Hash:    header.Hash(),
+		AppHash: header.AppHash,
+	}
+}
+
+//ValidatorSet decodes the validators of trusted state
+func (s *TrustedState) ValidatorSet() (*types.ValidatorSet, error) {
+	pb := new(tmproto.ValidatorSet)
+	if err := pb.Unmarshal(s.Validators); err != nil {
+		return nil, fmt.Errorf("unmarshal validator set error %s", err)
+	}
+	vals, err := types.ValidatorSetFromProto(pb)
+	if err != nil {
+		return nil, fmt.Errorf("validator set from proto error %s", err)
+	}
+	return vals, nil
+}
+
+//DecodeLightBlock decodes a protobuf encoded LightBlock, which is a SignedHeader with the ValidatorSet of its height
+func DecodeLightBlock(data []byte) (*types.LightBlock, error) {
+	pb := new(tmproto.LightBlock)
+	if err := pb.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("unmarshal light block error %s", err)
+	}
+	if pb.SignedHeader == nil || pb.ValidatorSet == nil {
+		return nil, fmt.Errorf("signed header or validator set is missing in light block")
+	}
+	block, err := types.LightBlockFromProto(pb)
+	if err != nil {
+		return nil, fmt.Errorf("light block from proto error %s", err)
+	}
+	return block, nil
+}
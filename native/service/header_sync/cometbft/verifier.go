@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cometbft
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/switcheo/tendermint/types"
+)
+
+//VerifyGenesis checks the genesis light block is consistent and signed by more than 2/3 of its validators
+func (ctx *Context) VerifyGenesis(block *types.LightBlock) error {
+	if err := block.ValidateBasic(ctx.ChainID); err != nil {
+		return fmt.Errorf("invalid light block: %s", err)
+	}
+	if err := block.ValidatorSet.VerifyCommitLight(ctx.ChainID, block.Commit.BlockID, block.Height, block.Commit); err != nil {
+		return fmt.Errorf("verify commit error: %s", err)
+	}
+	return nil
+}
+
+//VerifyLightBlock verifies block against trusted state. An adjacent block must be signed by more than 2/3 of the next
+//validators of trusted state. A non adjacent block skips the headers in between, at least trust level of trusted
+//validators and more than 2/3 of its own validators must have signed it. The trusting period and clock drift are
+//measured with now, which is the time of poly block instead of local clock, so that the verification is deterministic.
+func (ctx *Context) VerifyLightBlock(trusted *TrustedState, block *types.LightBlock, now time.Time) error {
+	if err := block.ValidateBasic(ctx.ChainID); err != nil {
+		return fmt.Errorf("invalid light block: %s", err)
+	}
+	if block.Height <= trusted.Height {
+		return fmt.Errorf("height %d is not greater than trusted height %d", block.Height, trusted.Height)
+	}
+	if !block.Time.After(trusted.Time) {
+		return fmt.Errorf("time %v is not after trusted time %v", block.Time, trusted.Time)
+	}
+	if expiration := trusted.Time.Add(ctx.trustingPeriod()); !now.Before(expiration) {
+		return fmt.Errorf("trusted header at height %d expired at %v, now %v", trusted.Height, expiration, now)
+	}
+	if block.Time.After(now.Add(MAX_CLOCK_DRIFT)) {
+		return fmt.Errorf("time %v is too far in the future, now %v", block.Time, now)
+	}
+
+	if block.Height == trusted.Height+1 {
+		if !bytes.Equal(block.ValidatorsHash, trusted.NextValidatorsHash) {
+			return fmt.Errorf("validators hash %s mismatch with trusted next validators hash %s", block.ValidatorsHash,
+				trusted.NextValidatorsHash)
+		}
+	} else {
+		trustedVals, err := trusted.ValidatorSet()
+		if err != nil {
+			return fmt.Errorf("trusted validators error: %s", err)
+		}
+		if err := trustedVals.VerifyCommitLightTrusting(ctx.ChainID, block.Commit, ctx.TrustLevel); err != nil {
+			return fmt.Errorf("verify commit with trusted validators error: %s", err)
+		}
+	}
+	//the validator set of a non adjacent block can be made very large, so it is checked at last
+	if err := block.ValidatorSet.VerifyCommitLight(ctx.ChainID, block.Commit.BlockID, block.Height, block.Commit); err != nil {
+		return fmt.Errorf("verify commit error: %s", err)
+	}
+	return nil
+}
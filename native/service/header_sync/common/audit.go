@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/native"
+)
+
+//AuditHeader is a header found in the header store of a side chain
+type AuditHeader struct {
+	Height     uint64   `json:"height"`
+	Hash       string   `json:"hash"`
+	ParentHash string   `json:"parentHash,omitempty"`
+	Work       string   `json:"work,omitempty"`       // difficulty sum of eth, total work of btc
+	Validators []string `json:"validators,omitempty"` // consensus peers changed by the header
+}
+
+type AuditIssue struct {
+	Height  uint64 `json:"height"`
+	Hash    string `json:"hash,omitempty"`
+	Message string `json:"message"`
+}
+
+//HeaderAudit is the report of checking the stored main chain index and headers of a side chain end to end
+type HeaderAudit struct {
+	ChainID       uint64         `json:"chainId"`
+	Router        uint64         `json:"router"`
+	GenesisHeight uint64         `json:"genesisHeight"`
+	CurrentHeight uint64         `json:"currentHeight"`
+	PrunedHeight  uint64         `json:"prunedHeight,omitempty"` // main chain headers below are pruned except pinned ones
+	MainChain     []*AuditHeader `json:"mainChain"`
+	Orphans       []*AuditHeader `json:"orphans"` // stored headers not in the main chain index
+	Issues        []*AuditIssue  `json:"issues"`
+	Consistent    bool           `json:"consistent"`
+}
+
+func (this *HeaderAudit) AddIssue(height uint64, hash string, format string, args ...interface{}) {
+	this.Issues = append(this.Issues, &AuditIssue{
+		Height:  height,
+		Hash:    hash,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+//AuditHeaders check the header store of chain with the audit of router, the cache db of native is only read
+func AuditHeaders(native *native.NativeService, router, chainID uint64) (*HeaderAudit, error) {
+	info, err := GetHandlerInfo(router)
+	if err != nil {
+		return nil, err
+	}
+	if info.Audit == nil {
+		return nil, fmt.Errorf("header store audit of router %d is not supported", router)
+	}
+	audit := &HeaderAudit{
+		ChainID:   chainID,
+		Router:    router,
+		MainChain: make([]*AuditHeader, 0),
+		Orphans:   make([]*AuditHeader, 0),
+		Issues:    make([]*AuditIssue, 0),
+	}
+	if err := info.Audit(native, audit); err != nil {
+		return nil, err
+	}
+	audit.Consistent = len(audit.Issues) == 0
+	return audit, nil
+}
@@ -44,12 +44,21 @@ const (
 	SYNC_HEADER_NAME            = "syncHeader"
 	SYNC_CROSSCHAIN_MSG         = "syncCrossChainMsg"
 	POLYGON_SPAN                = "polygonSpan"
+	SYNC_COMMITTEE              = "syncCommittee"
+	FINALIZED_BEACON_HEADER     = "finalizedBeaconHeader"
+	HEADER_RETENTION            = "headerRetention"
+	PRUNED_HEIGHT               = "prunedHeight"
+	HEADER_HASHES               = "headerHashes"
+	HEADER_REFERENCE            = "headerReference"
+	PRUNE_HEADERS_NAME          = "pruneHeaders"
+	TRUSTED_STATE               = "trustedState"
 )
 
 const (
 	SYNC_GENESIS_HEADER  = "syncGenesisHeader"
 	SYNC_BLOCK_HEADER    = "syncBlockHeader"
 	SYNC_CROSS_CHAIN_MSG = "syncCrossChainMsg"
+	SET_HEADER_RETENTION = "setHeaderRetention"
 )
 
 type HeaderSyncHandler interface {
@@ -168,7 +177,70 @@ func (this *SyncCrossChainMsgParam) Deserialization(source *common.ZeroCopySourc
 	return nil
 }
 
+type SetHeaderRetentionParam struct {
+	ChainID     uint64
+	Keep        uint64   // number of latest headers to keep, 0 to stop pruning
+	StartHeight uint64   // height to start pruning from if the chain is never pruned, usually the genesis header height
+	Pinned      []uint64 // heights of headers kept regardless of Keep, like the ones referenced by pending proofs
+	Address     common.Address
+}
+
+func (this *SetHeaderRetentionParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteUint64(this.Keep)
+	sink.WriteUint64(this.StartHeight)
+	sink.WriteVarUint(uint64(len(this.Pinned)))
+	for _, v := range this.Pinned {
+		sink.WriteUint64(v)
+	}
+	sink.WriteAddress(this.Address)
+}
+
+func (this *SetHeaderRetentionParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetHeaderRetentionParam deserialize chainID error")
+	}
+	keep, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetHeaderRetentionParam deserialize keep error")
+	}
+	startHeight, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetHeaderRetentionParam deserialize start height error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SetHeaderRetentionParam deserialize pinned count error")
+	}
+	pinned := make([]uint64, 0)
+	for i := uint64(0); i < n; i++ {
+		height, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("SetHeaderRetentionParam deserialize pinned height error")
+		}
+		pinned = append(pinned, height)
+	}
+	address, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("SetHeaderRetentionParam deserialize address error")
+	}
+	this.ChainID = chainID
+	this.Keep = keep
+	this.StartHeight = startHeight
+	this.Pinned = pinned
+	this.Address = address
+	return nil
+}
+
+//NotifyPutHeader counts a header newly stored by the transaction and notifies it
 func NotifyPutHeader(native *native.NativeService, chainID uint64, height uint64, blockHash string) {
+	native.AddStoredHeader()
+	NotifyAppendHeader(native, chainID, height, blockHash)
+}
+
+//NotifyAppendHeader notifies a header stored before is appended to the main chain
+func NotifyAppendHeader(native *native.NativeService, chainID uint64, height uint64, blockHash string) {
 	if !config.DefConfig.Common.EnableEventLog {
 		return
 	}
@@ -56,3 +56,23 @@ func TestSyncBlockHeaderParam(t *testing.T) {
 
 	assert.Equal(t, p, param)
 }
+
+func TestSetHeaderRetentionParam(t *testing.T) {
+	p := SetHeaderRetentionParam{
+		ChainID:     123,
+		Keep:        20000,
+		StartHeight: 100,
+		Pinned:      []uint64{150, 160},
+		Address:     common.ADDRESS_EMPTY,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param SetHeaderRetentionParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type HandlerInfo struct {
+	Router      uint64
+	NewHandler  func() HeaderSyncHandler
+	StartBlocks utils.RouterStartBlocks // nil if the router is supported from genesis
+	//Audit check the stored headers of a chain of the router offline, nil if not supported
+	Audit func(native *native.NativeService, audit *HeaderAudit) error
+	//Prunable is true if headers of the router are indexed in MAIN_CHAIN and HEADER_INDEX, and can be pruned by PruneHeaders
+	Prunable bool
+}
+
+var handlers = make(map[uint64]*HandlerInfo)
+
+//RegisterHandler register the header sync handler of a router, it should be called in init of the chain package
+func RegisterHandler(info *HandlerInfo) {
+	if _, present := handlers[info.Router]; present {
+		panic(fmt.Sprintf("header sync handler of router %d registered twice", info.Router))
+	}
+	handlers[info.Router] = info
+}
+
+func GetHandlerInfo(router uint64) (*HandlerInfo, error) {
+	info, present := handlers[router]
+	if !present {
+		return nil, fmt.Errorf("not a supported router:%d", router)
+	}
+	return info, nil
+}
+
+//GetHandler return the handler of router if the router is supported at block height
+func GetHandler(router uint64, height uint32) (HeaderSyncHandler, error) {
+	info, err := GetHandlerInfo(router)
+	if err != nil {
+		return nil, err
+	}
+	if err := info.StartBlocks.Check(router, height); err != nil {
+		return nil, err
+	}
+	return info.NewHandler(), nil
+}
+
+//Routers return all registered routers in ascending order
+func Routers() []uint64 {
+	routers := make([]uint64, 0, len(handlers))
+	for router := range handlers {
+		routers = append(routers, router)
+	}
+	sort.Slice(routers, func(i, j int) bool { return routers[i] < routers[j] })
+	return routers
+}
@@ -0,0 +1,412 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	MIN_HEADER_RETENTION = 10000 //Least headers to keep, handlers like bsc and polygon look back recent headers for validators
+	HEADER_PRUNE_LIMIT   = 1000  //Most headers pruned in one SyncBlockHeader, the rest are pruned by the following ones
+)
+
+//HeaderRetention is the governance policy of keeping the headers of a chain, which are indexed
+//by height in MAIN_CHAIN and by hash in HEADER_INDEX like eth
+type HeaderRetention struct {
+	Keep   uint64
+	Pinned []uint64
+}
+
+func (this *HeaderRetention) IsPinned(height uint64) bool {
+	for _, v := range this.Pinned {
+		if v == height {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *HeaderRetention) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Keep)
+	sink.WriteVarUint(uint64(len(this.Pinned)))
+	for _, v := range this.Pinned {
+		sink.WriteUint64(v)
+	}
+}
+
+func (this *HeaderRetention) Deserialization(source *common.ZeroCopySource) error {
+	keep, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("HeaderRetention deserialize keep error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("HeaderRetention deserialize pinned count error")
+	}
+	pinned := make([]uint64, 0)
+	for i := uint64(0); i < n; i++ {
+		height, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("HeaderRetention deserialize pinned height error")
+		}
+		pinned = append(pinned, height)
+	}
+	this.Keep = keep
+	this.Pinned = pinned
+	return nil
+}
+
+//IsHeaderRetentionEnabled return whether headers of prunable routers are indexed by height and pruned by retention policy
+func IsHeaderRetentionEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetHeaderRetentionHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//GetHeaderRetention return nil if headers of the chain are kept forever
+func GetHeaderRetention(native *native.NativeService, chainID uint64) (*HeaderRetention, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_RETENTION), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderRetention, get retention store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	retentionBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderRetention, deserialize from raw storage item err:%v", err)
+	}
+	retention := new(HeaderRetention)
+	if err := retention.Deserialization(common.NewZeroCopySource(retentionBytes)); err != nil {
+		return nil, fmt.Errorf("GetHeaderRetention, deserialize retention error: %v", err)
+	}
+	return retention, nil
+}
+
+func PutHeaderRetention(native *native.NativeService, chainID uint64, retention *HeaderRetention) {
+	sink := common.NewZeroCopySink(nil)
+	retention.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_RETENTION), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func RemoveHeaderRetention(native *native.NativeService, chainID uint64) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_RETENTION), utils.GetUint64Bytes(chainID)))
+}
+
+//GetPrunedHeight return the height below which the main chain headers are pruned except the pinned ones,
+//0 if the chain is never pruned
+func GetPrunedHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(PRUNED_HEIGHT), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetPrunedHeight, get pruned height store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	heightBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetPrunedHeight, deserialize from raw storage item err:%v", err)
+	}
+	return utils.GetBytesUint64(heightBytes), nil
+}
+
+func PutPrunedHeight(native *native.NativeService, chainID uint64, height uint64) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(PRUNED_HEIGHT), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
+}
+
+//CheckHeaderRetained return error if the main chain header of height is pruned
+func CheckHeaderRetained(native *native.NativeService, chainID uint64, height uint64) error {
+	prunedHeight, err := GetPrunedHeight(native, chainID)
+	if err != nil {
+		return err
+	}
+	if height >= prunedHeight {
+		return nil
+	}
+	retention, err := GetHeaderRetention(native, chainID)
+	if err != nil {
+		return err
+	}
+	if retention != nil && retention.IsPinned(height) {
+		return nil
+	}
+	references, err := getHeaderReferences(native, chainID, height)
+	if err != nil {
+		return err
+	}
+	if references != 0 {
+		return nil
+	}
+	return fmt.Errorf("header of height %d of chain %d is pruned, only headers from height %d are kept", height, chainID, prunedHeight)
+}
+
+func getMainChainHash(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	hashStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(MAIN_CHAIN),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+	if err != nil {
+		return nil, fmt.Errorf("get main chain hash error: %v", err)
+	}
+	if hashStore == nil {
+		return nil, nil
+	}
+	hashBytes, err := cstates.GetValueFromRawStorageItem(hashStore)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize main chain hash from raw storage item err:%v", err)
+	}
+	return hashBytes, nil
+}
+
+//DeleteMainChainHeader delete the header of height from MAIN_CHAIN and HEADER_INDEX
+func DeleteMainChainHeader(native *native.NativeService, chainID uint64, height uint64) error {
+	contract := utils.HeaderSyncContractAddress
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	hashBytes, err := getMainChainHash(native, chainID, height)
+	if err != nil {
+		return fmt.Errorf("DeleteMainChainHeader, %v", err)
+	}
+	if hashBytes == nil {
+		return nil
+	}
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(HEADER_INDEX), chainIDBytes, hashBytes))
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(MAIN_CHAIN), chainIDBytes, utils.GetUint64Bytes(height)))
+	return nil
+}
+
+//DeletePrunedHeader delete the main chain header of height below pruned height, unless it is pinned by retention
+//or referenced
+func DeletePrunedHeader(native *native.NativeService, chainID uint64, height uint64, retention *HeaderRetention) error {
+	if retention != nil && retention.IsPinned(height) {
+		return nil
+	}
+	references, err := getHeaderReferences(native, chainID, height)
+	if err != nil {
+		return err
+	}
+	if references != 0 {
+		return nil
+	}
+	return DeleteMainChainHeader(native, chainID, height)
+}
+
+func headerHashesKey(chainID uint64, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_HASHES), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height))
+}
+
+func getHeaderHashes(native *native.NativeService, chainID uint64, height uint64) ([][]byte, error) {
+	store, err := native.GetCacheDB().Get(headerHashesKey(chainID, height))
+	if err != nil {
+		return nil, fmt.Errorf("get header hashes error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize header hashes from raw storage item err:%v", err)
+	}
+	source := common.NewZeroCopySource(value)
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("deserialize header hashes count error")
+	}
+	hashes := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		hash, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("deserialize header hash error")
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+//PutHeaderHash index the hash of a header stored in HEADER_INDEX by its height, so headers off the main chain
+//are pruned with the height. Headers stored before header retention is enabled are not indexed
+func PutHeaderHash(native *native.NativeService, chainID uint64, height uint64, hash []byte) error {
+	if !IsHeaderRetentionEnabled(native) {
+		return nil
+	}
+	hashes, err := getHeaderHashes(native, chainID, height)
+	if err != nil {
+		return fmt.Errorf("PutHeaderHash, %v", err)
+	}
+	for _, v := range hashes {
+		if bytes.Equal(v, hash) {
+			return nil
+		}
+	}
+	hashes = append(hashes, hash)
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(hashes)))
+	for _, v := range hashes {
+		sink.WriteVarBytes(v)
+	}
+	native.GetCacheDB().Put(headerHashesKey(chainID, height), cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+func headerReferenceKey(chainID uint64, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_REFERENCE), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height))
+}
+
+func getHeaderReferences(native *native.NativeService, chainID uint64, height uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(headerReferenceKey(chainID, height))
+	if err != nil {
+		return 0, fmt.Errorf("get header references error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("deserialize header references from raw storage item err:%v", err)
+	}
+	return utils.GetBytesUint64(value), nil
+}
+
+//ReferenceHeader keep the main chain header of height from pruning until it is released by ReleaseHeader, like the
+//header a pending cross chain tx is proved with. References are counted
+func ReferenceHeader(native *native.NativeService, chainID uint64, height uint64) error {
+	if !IsHeaderRetentionEnabled(native) {
+		return nil
+	}
+	references, err := getHeaderReferences(native, chainID, height)
+	if err != nil {
+		return fmt.Errorf("ReferenceHeader, %v", err)
+	}
+	native.GetCacheDB().Put(headerReferenceKey(chainID, height), cstates.GenRawStorageItem(utils.GetUint64Bytes(references+1)))
+	return nil
+}
+
+//ReleaseHeader drop a reference of ReferenceHeader, the header is deleted at once if it is no longer referenced
+//and its height is pruned
+func ReleaseHeader(native *native.NativeService, chainID uint64, height uint64) error {
+	references, err := getHeaderReferences(native, chainID, height)
+	if err != nil {
+		return fmt.Errorf("ReleaseHeader, %v", err)
+	}
+	if references == 0 {
+		return nil
+	}
+	if references > 1 {
+		native.GetCacheDB().Put(headerReferenceKey(chainID, height), cstates.GenRawStorageItem(utils.GetUint64Bytes(references-1)))
+		return nil
+	}
+	native.GetCacheDB().Delete(headerReferenceKey(chainID, height))
+	prunedHeight, err := GetPrunedHeight(native, chainID)
+	if err != nil {
+		return fmt.Errorf("ReleaseHeader, %v", err)
+	}
+	if height >= prunedHeight {
+		return nil
+	}
+	retention, err := GetHeaderRetention(native, chainID)
+	if err != nil {
+		return fmt.Errorf("ReleaseHeader, %v", err)
+	}
+	if err := DeletePrunedHeader(native, chainID, height, retention); err != nil {
+		return fmt.Errorf("ReleaseHeader, %v", err)
+	}
+	return nil
+}
+
+//pruneHeaderHeight delete the headers of height off the main chain, and the main chain one unless it is pinned
+//or referenced
+func pruneHeaderHeight(native *native.NativeService, chainID uint64, height uint64, retention *HeaderRetention) error {
+	mainHash, err := getMainChainHash(native, chainID, height)
+	if err != nil {
+		return err
+	}
+	hashes, err := getHeaderHashes(native, chainID, height)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if !bytes.Equal(hash, mainHash) {
+			native.GetCacheDB().Delete(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_INDEX), utils.GetUint64Bytes(chainID), hash))
+		}
+	}
+	native.GetCacheDB().Delete(headerHashesKey(chainID, height))
+	return DeletePrunedHeader(native, chainID, height, retention)
+}
+
+//PruneHeaders delete the headers older than the latest Keep ones of the retention policy, at most HEADER_PRUNE_LIMIT
+//heights are pruned in one call. Main chain headers pinned or referenced are kept, fork headers are always deleted
+func PruneHeaders(native *native.NativeService, chainID uint64) error {
+	retention, err := GetHeaderRetention(native, chainID)
+	if err != nil {
+		return fmt.Errorf("PruneHeaders, %v", err)
+	}
+	if retention == nil || retention.Keep == 0 {
+		return nil
+	}
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return fmt.Errorf("PruneHeaders, get current header height error: %v", err)
+	}
+	if store == nil {
+		return nil
+	}
+	heightBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return fmt.Errorf("PruneHeaders, deserialize current header height err:%v", err)
+	}
+	currentHeight := utils.GetBytesUint64(heightBytes)
+	if currentHeight < retention.Keep {
+		return nil
+	}
+	prunedHeight, err := GetPrunedHeight(native, chainID)
+	if err != nil {
+		return fmt.Errorf("PruneHeaders, %v", err)
+	}
+	end := currentHeight - retention.Keep + 1
+	if end > prunedHeight+HEADER_PRUNE_LIMIT {
+		end = prunedHeight + HEADER_PRUNE_LIMIT
+	}
+	if end <= prunedHeight {
+		return nil
+	}
+	for height := prunedHeight; height < end; height++ {
+		if err := pruneHeaderHeight(native, chainID, height, retention); err != nil {
+			return fmt.Errorf("PruneHeaders, %v", err)
+		}
+	}
+	PutPrunedHeight(native, chainID, end)
+	NotifyPruneHeaders(native, chainID, prunedHeight, end)
+	return nil
+}
+
+func NotifyPruneHeaders(native *native.NativeService, chainID uint64, from, to uint64) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.HeaderSyncContractAddress,
+			States:          []interface{}{PRUNE_HEADERS_NAME, chainID, from, to, native.GetHeight()},
+		})
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRetentionNative(t *testing.T, chainID, from, to uint64) *native.NativeService {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	contract := utils.HeaderSyncContractAddress
+	for height := from; height <= to; height++ {
+		hash := utils.GetUint64Bytes(height)
+		db.Put(utils.ConcatKey(contract, []byte(MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
+			cstates.GenRawStorageItem(hash))
+		db.Put(utils.ConcatKey(contract, []byte(HEADER_INDEX), utils.GetUint64Bytes(chainID), hash), cstates.GenRawStorageItem([]byte{1}))
+	}
+	db.Put(utils.ConcatKey(contract, []byte(CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(to)))
+	ns, err := native.NewNativeService(db, &types.Transaction{}, 0, 0, common.Uint256{}, 0, nil, false)
+	assert.Nil(t, err)
+	return ns
+}
+
+func isHeaderIndexed(ns *native.NativeService, chainID, height uint64) bool {
+	return isHashIndexed(ns, chainID, utils.GetUint64Bytes(height))
+}
+
+func isHashIndexed(ns *native.NativeService, chainID uint64, hash []byte) bool {
+	store, _ := ns.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_INDEX),
+		utils.GetUint64Bytes(chainID), hash))
+	return store != nil
+}
+
+func TestPruneHeaders(t *testing.T) {
+	chainID := uint64(2)
+	ns := newRetentionNative(t, chainID, 100, 120)
+
+	//no retention policy
+	assert.Nil(t, PruneHeaders(ns, chainID))
+	assert.True(t, isHeaderIndexed(ns, chainID, 100))
+	assert.Nil(t, CheckHeaderRetained(ns, chainID, 100))
+
+	PutHeaderRetention(ns, chainID, &HeaderRetention{Keep: 5, Pinned: []uint64{103}})
+	PutPrunedHeight(ns, chainID, 100)
+	assert.Nil(t, PruneHeaders(ns, chainID))
+
+	prunedHeight, err := GetPrunedHeight(ns, chainID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(116), prunedHeight)
+	for height := uint64(100); height <= 120; height++ {
+		retained := height >= 116 || height == 103
+		assert.Equal(t, retained, isHeaderIndexed(ns, chainID, height), "height %d", height)
+		if retained {
+			assert.Nil(t, CheckHeaderRetained(ns, chainID, height))
+		} else {
+			assert.NotNil(t, CheckHeaderRetained(ns, chainID, height))
+		}
+	}
+
+	//nothing more to prune until current height grows
+	assert.Nil(t, PruneHeaders(ns, chainID))
+	prunedHeight, _ = GetPrunedHeight(ns, chainID)
+	assert.Equal(t, uint64(116), prunedHeight)
+}
+
+func TestPruneHeadersLimit(t *testing.T) {
+	chainID := uint64(2)
+	ns := newRetentionNative(t, chainID, 0, HEADER_PRUNE_LIMIT+100)
+	PutHeaderRetention(ns, chainID, &HeaderRetention{Keep: 10})
+
+	assert.Nil(t, PruneHeaders(ns, chainID))
+	prunedHeight, _ := GetPrunedHeight(ns, chainID)
+	assert.Equal(t, uint64(HEADER_PRUNE_LIMIT), prunedHeight)
+	assert.False(t, isHeaderIndexed(ns, chainID, HEADER_PRUNE_LIMIT-1))
+	assert.True(t, isHeaderIndexed(ns, chainID, HEADER_PRUNE_LIMIT))
+
+	assert.Nil(t, PruneHeaders(ns, chainID))
+	prunedHeight, _ = GetPrunedHeight(ns, chainID)
+	assert.Equal(t, uint64(HEADER_PRUNE_LIMIT+91), prunedHeight)
+}
+
+func TestPruneForkHeaders(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	chainID := uint64(2)
+	ns := newRetentionNative(t, chainID, 100, 120)
+	forks := make(map[uint64][]byte)
+	for height := uint64(100); height <= 120; height++ {
+		fork := append([]byte{0xff}, utils.GetUint64Bytes(height)...)
+		ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_INDEX), utils.GetUint64Bytes(chainID), fork),
+			cstates.GenRawStorageItem([]byte{1}))
+		assert.Nil(t, PutHeaderHash(ns, chainID, height, utils.GetUint64Bytes(height)))
+		assert.Nil(t, PutHeaderHash(ns, chainID, height, fork))
+		assert.Nil(t, PutHeaderHash(ns, chainID, height, fork))
+		forks[height] = fork
+	}
+	hashes, err := getHeaderHashes(ns, chainID, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(hashes))
+
+	PutHeaderRetention(ns, chainID, &HeaderRetention{Keep: 5, Pinned: []uint64{103}})
+	PutPrunedHeight(ns, chainID, 100)
+	assert.Nil(t, PruneHeaders(ns, chainID))
+	for height := uint64(100); height <= 120; height++ {
+		assert.Equal(t, height >= 116, isHashIndexed(ns, chainID, forks[height]), "height %d", height)
+		assert.Equal(t, height >= 116 || height == 103, isHeaderIndexed(ns, chainID, height), "height %d", height)
+	}
+	hashes, err = getHeaderHashes(ns, chainID, 103)
+	assert.Nil(t, err)
+	assert.Nil(t, hashes)
+}
+
+func TestHeaderReference(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	chainID := uint64(2)
+	ns := newRetentionNative(t, chainID, 100, 120)
+	assert.Nil(t, ReferenceHeader(ns, chainID, 104))
+	assert.Nil(t, ReferenceHeader(ns, chainID, 104))
+	assert.Nil(t, ReferenceHeader(ns, chainID, 118))
+	PutHeaderRetention(ns, chainID, &HeaderRetention{Keep: 5})
+	PutPrunedHeight(ns, chainID, 100)
+	assert.Nil(t, PruneHeaders(ns, chainID))
+	assert.True(t, isHeaderIndexed(ns, chainID, 104))
+	assert.Nil(t, CheckHeaderRetained(ns, chainID, 104))
+	assert.False(t, isHeaderIndexed(ns, chainID, 105))
+
+	//the header is deleted once the last reference is released
+	assert.Nil(t, ReleaseHeader(ns, chainID, 104))
+	assert.True(t, isHeaderIndexed(ns, chainID, 104))
+	assert.Nil(t, ReleaseHeader(ns, chainID, 104))
+	assert.False(t, isHeaderIndexed(ns, chainID, 104))
+	assert.NotNil(t, CheckHeaderRetained(ns, chainID, 104))
+	assert.Nil(t, ReleaseHeader(ns, chainID, 104))
+
+	//headers not pruned yet are left to PruneHeaders
+	assert.Nil(t, ReleaseHeader(ns, chainID, 118))
+	assert.True(t, isHeaderIndexed(ns, chainID, 118))
+	references, err := getHeaderReferences(ns, chainID, 118)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), references)
+}
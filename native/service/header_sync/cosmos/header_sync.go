@@ -37,6 +37,13 @@ import (
 
 type CosmosHandler struct{}
 
+func init() {
+	hscommon.RegisterHandler(&hscommon.HandlerInfo{
+		Router:     utils.COSMOS_ROUTER,
+		NewHandler: func() hscommon.HeaderSyncHandler { return NewCosmosHandler() },
+	})
+}
+
 func NewCosmosHandler() *CosmosHandler {
 	return &CosmosHandler{}
 }
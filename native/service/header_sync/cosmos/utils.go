@@ -78,6 +78,7 @@ func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *Cos
 	service.GetCacheDB().Put(
 		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), utils.GetUint64Bytes(chainId)),
 		cstates.GenRawStorageItem(sink.Bytes()))
+	service.AddStoredHeader()
 	notifyEpochSwitchInfo(service, chainId, info)
 }
 
@@ -22,85 +22,52 @@ import (
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_incentive"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
-	"github.com/polynetwork/poly/native/service/header_sync/bsc"
-	"github.com/polynetwork/poly/native/service/header_sync/btc"
-	"github.com/polynetwork/poly/native/service/header_sync/bytom"
 	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
-	"github.com/polynetwork/poly/native/service/header_sync/cosmos"
-	"github.com/polynetwork/poly/native/service/header_sync/eth"
-	"github.com/polynetwork/poly/native/service/header_sync/harmony"
-	"github.com/polynetwork/poly/native/service/header_sync/heco"
-	"github.com/polynetwork/poly/native/service/header_sync/hsc"
-	"github.com/polynetwork/poly/native/service/header_sync/msc"
-	"github.com/polynetwork/poly/native/service/header_sync/neo"
-	"github.com/polynetwork/poly/native/service/header_sync/neo3"
-	"github.com/polynetwork/poly/native/service/header_sync/neo3legacy"
-	"github.com/polynetwork/poly/native/service/header_sync/okex"
-	"github.com/polynetwork/poly/native/service/header_sync/ont"
-	"github.com/polynetwork/poly/native/service/header_sync/pixiechain"
-	"github.com/polynetwork/poly/native/service/header_sync/polygon"
-	"github.com/polynetwork/poly/native/service/header_sync/quorum"
-	"github.com/polynetwork/poly/native/service/header_sync/starcoin"
-	"github.com/polynetwork/poly/native/service/header_sync/zilliqa"
-	"github.com/polynetwork/poly/native/service/header_sync/zilliqalegacy"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+const (
+	SYNC_HEADER_GAS = 100000 //Gas charged for each header of SyncBlockHeader, header verification like ethash is expensive
+)
+
+var (
+	SyncBlockHeaderGas   = native.MethodGas{Base: 20000, PerByte: 20}
+	SyncCrossChainMsgGas = native.MethodGas{Base: 20000, PerByte: 20}
+)
+
 //Register methods of node_manager contract
 func RegisterHeaderSyncContract(native *native.NativeService) {
 	native.Register(hscommon.SYNC_GENESIS_HEADER, SyncGenesisHeader)
 	native.Register(hscommon.SYNC_BLOCK_HEADER, SyncBlockHeader)
 	native.Register(hscommon.SYNC_CROSS_CHAIN_MSG, SyncCrossChainMsg)
+	native.Register(hscommon.SET_HEADER_RETENTION, SetHeaderRetention)
+
+	native.RegisterGas(hscommon.SYNC_BLOCK_HEADER, SyncBlockHeaderGas)
+	native.RegisterGas(hscommon.SYNC_CROSS_CHAIN_MSG, SyncCrossChainMsgGas)
 }
 
 func GetChainHandler(router uint64) (hscommon.HeaderSyncHandler, error) {
-	switch router {
-	case utils.BTC_ROUTER:
-		return btc.NewBTCHandler(), nil
-	case utils.ETH_ROUTER:
-		return eth.NewETHHandler(), nil
-	case utils.ONT_ROUTER:
-		return ont.NewONTHandler(), nil
-	case utils.NEO_ROUTER:
-		return neo.NewNEOHandler(), nil
-	case utils.NEO3_ROUTER:
-		return neo3.NewNeo3Handler(), nil
-	case utils.NEO3_LEGACY_ROUTER:
-		return neo3legacy.NewNeo3Handler(), nil
-	case utils.COSMOS_ROUTER:
-		return cosmos.NewCosmosHandler(), nil
-	case utils.QUORUM_ROUTER:
-		return quorum.NewQuorumHandler(), nil
-	case utils.BSC_ROUTER:
-		return bsc.NewHandler(), nil
-	case utils.HECO_ROUTER:
-		return heco.NewHecoHandler(), nil
-	case utils.ZILLIQA_LEGACY_ROUTER:
-		return zilliqalegacy.NewHandler(), nil
-	case utils.ZILLIQA_ROUTER:
-		return zilliqa.NewHandler(), nil
-	case utils.MSC_ROUTER:
-		return msc.NewHandler(), nil
-	case utils.OKEX_ROUTER:
-		return okex.NewHandler(), nil
-	case utils.POLYGON_HEIMDALL_ROUTER:
-		return polygon.NewHeimdallHandler(), nil
-	case utils.POLYGON_BOR_ROUTER:
-		return polygon.NewBorHandler(), nil
-	case utils.PIXIECHAIN_ROUTER:
-		return pixiechain.NewPixieHandler(), nil
-	case utils.STARCOIN_ROUTER:
-		return starcoin.NewSTCHandler(), nil
-	case utils.HSC_ROUTER:
-		return hsc.NewHscHandler(), nil
-	case utils.HARMONY_ROUTER:
-		return harmony.NewHandler(), nil
-	case utils.BYTOM_ROUTER:
-		return bytom.NewHandler(), nil
-	default:
-		return nil, fmt.Errorf("not a supported router:%d", router)
+	info, err := hscommon.GetHandlerInfo(router)
+	if err != nil {
+		return nil, err
 	}
+	return info.NewHandler(), nil
+}
+
+//AuditHeaders check the header store of a registered side chain with the audit of its router
+func AuditHeaders(native *native.NativeService, chainID uint64) (*hscommon.HeaderAudit, error) {
+	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("AuditHeaders, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("AuditHeaders, side chain %d is not registered", chainID)
+	}
+	return hscommon.AuditHeaders(native, sideChain.Router, chainID)
 }
 
 func SyncGenesisHeader(native *native.NativeService) ([]byte, error) {
@@ -119,16 +86,13 @@ func SyncGenesisHeader(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncGenesisHeader, side chain is not registered")
 	}
 
-	handler, err := GetChainHandler(sideChain.Router)
-	if err != nil {
-		return utils.BYTE_FALSE, err
-	}
-	err = utils.CheckRouterStartBlock(sideChain.Router, native.GetHeight())
+	handler, err := hscommon.GetHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
 
 	err = handler.SyncGenesisHeader(native)
+	observeHeaderSync(native, hscommon.SYNC_GENESIS_HEADER, chainID, sideChain.Router, err)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
@@ -140,6 +104,9 @@ func SyncBlockHeader(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
+	if err := native.ChargeGas(uint64(len(params.Headers)) * SYNC_HEADER_GAS); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, charge gas error: %v", err)
+	}
 	chainID := params.ChainID
 
 	//check if chainid exist
@@ -150,17 +117,23 @@ func SyncBlockHeader(native *native.NativeService) ([]byte, error) {
 	if sideChain == nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, side chain is not registered")
 	}
-
-	handler, err := GetChainHandler(sideChain.Router)
-	if err != nil {
-		return utils.BYTE_FALSE, err
+	if err := relayer_manager.CheckRelayerScope(native, chainID, hscommon.SYNC_BLOCK_HEADER); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, %v", err)
 	}
-	err = utils.CheckRouterStartBlock(sideChain.Router, native.GetHeight())
+
+	handler, err := hscommon.GetHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
 
 	err = handler.SyncBlockHeader(native)
+	if err == nil {
+		err = relayer_incentive.CreditHeaderSync(native, chainID)
+	}
+	if err == nil {
+		err = pruneHeaders(native, chainID, sideChain.Router)
+	}
+	observeHeaderSync(native, hscommon.SYNC_BLOCK_HEADER, chainID, sideChain.Router, err)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
@@ -182,19 +155,110 @@ func SyncCrossChainMsg(native *native.NativeService) ([]byte, error) {
 	if sideChain == nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, side chain is not registered")
 	}
+	if err := relayer_manager.CheckRelayerScope(native, chainID, hscommon.SYNC_CROSS_CHAIN_MSG); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, %v", err)
+	}
 
-	handler, err := GetChainHandler(sideChain.Router)
+	handler, err := hscommon.GetHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
-	err = utils.CheckRouterStartBlock(sideChain.Router, native.GetHeight())
+
+	err = handler.SyncCrossChainMsg(native)
+	observeHeaderSync(native, hscommon.SYNC_CROSS_CHAIN_MSG, chainID, sideChain.Router, err)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
+	return utils.BYTE_TRUE, nil
+}
 
-	err = handler.SyncCrossChainMsg(native)
+//pruneHeaders prune the synced headers of chain by its retention policy
+func pruneHeaders(native *native.NativeService, chainID, router uint64) error {
+	if !hscommon.IsHeaderRetentionEnabled(native) {
+		return nil
+	}
+	info, err := hscommon.GetHandlerInfo(router)
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return err
+	}
+	if !info.Prunable {
+		return nil
+	}
+	return hscommon.PruneHeaders(native, chainID)
+}
+
+//SetHeaderRetention set how many latest headers of a chain are kept and the pinned ones, approved by consensus nodes
+func SetHeaderRetention(native *native.NativeService) ([]byte, error) {
+	if !hscommon.IsHeaderRetentionEnabled(native) {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, header retention is not enabled")
+	}
+	params := new(hscommon.SetHeaderRetentionParam)
+	inputBytes := native.GetInput()
+	if err := params.Deserialization(common.NewZeroCopySource(inputBytes)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, contract params deserialize error: %v", err)
+	}
+	if params.Keep != 0 && params.Keep < hscommon.MIN_HEADER_RETENTION {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, at least %d headers should be kept", hscommon.MIN_HEADER_RETENTION)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, side chain is not registered")
+	}
+	info, err := hscommon.GetHandlerInfo(sideChain.Router)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, %v", err)
+	}
+	if !info.Prunable {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, headers of router %d can not be pruned", sideChain.Router)
+	}
+
+	prunedHeight, err := hscommon.GetPrunedHeight(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, %v", err)
+	}
+	old, err := hscommon.GetHeaderRetention(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, %v", err)
+	}
+	retention := &hscommon.HeaderRetention{Keep: params.Keep, Pinned: params.Pinned}
+	for _, height := range retention.Pinned {
+		if err := hscommon.CheckHeaderRetained(native, params.ChainID, height); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, %v", err)
+		}
+	}
+
+	err = utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, checkWitness error: %v", err)
+	}
+	ok, err := node_manager.CheckConsensusSigns(native, hscommon.SET_HEADER_RETENTION, inputBytes, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	//headers unpinned below pruned height are deleted at once unless referenced
+	if old != nil {
+		for _, height := range old.Pinned {
+			if height < prunedHeight {
+				if err := hscommon.DeletePrunedHeader(native, params.ChainID, height, retention); err != nil {
+					return utils.BYTE_FALSE, fmt.Errorf("SetHeaderRetention, %v", err)
+				}
+			}
+		}
+	}
+	if retention.Keep == 0 && len(retention.Pinned) == 0 {
+		hscommon.RemoveHeaderRetention(native, params.ChainID)
+	} else {
+		hscommon.PutHeaderRetention(native, params.ChainID, retention)
+	}
+	if prunedHeight == 0 && retention.Keep != 0 {
+		hscommon.PutPrunedHeight(native, params.ChainID, params.StartHeight)
 	}
 	return utils.BYTE_TRUE, nil
 }
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package header_sync
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/native/service/header_sync/bsc"
+	"github.com/polynetwork/poly/native/service/header_sync/btc"
+	"github.com/polynetwork/poly/native/service/header_sync/bytom"
+	"github.com/polynetwork/poly/native/service/header_sync/cometbft"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	"github.com/polynetwork/poly/native/service/header_sync/eth"
+	"github.com/polynetwork/poly/native/service/header_sync/ethpos"
+	"github.com/polynetwork/poly/native/service/header_sync/harmony"
+	"github.com/polynetwork/poly/native/service/header_sync/heco"
+	"github.com/polynetwork/poly/native/service/header_sync/hsc"
+	"github.com/polynetwork/poly/native/service/header_sync/msc"
+	"github.com/polynetwork/poly/native/service/header_sync/neo"
+	"github.com/polynetwork/poly/native/service/header_sync/neo3"
+	"github.com/polynetwork/poly/native/service/header_sync/neo3legacy"
+	"github.com/polynetwork/poly/native/service/header_sync/okex"
+	"github.com/polynetwork/poly/native/service/header_sync/ont"
+	"github.com/polynetwork/poly/native/service/header_sync/pixiechain"
+	"github.com/polynetwork/poly/native/service/header_sync/polygon"
+	"github.com/polynetwork/poly/native/service/header_sync/quorum"
+	"github.com/polynetwork/poly/native/service/header_sync/starcoin"
+	"github.com/polynetwork/poly/native/service/header_sync/zilliqa"
+	"github.com/polynetwork/poly/native/service/header_sync/zilliqalegacy"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// handlers of all supported routers
+var expectedHandlers = map[uint64]hscommon.HeaderSyncHandler{
+	utils.BTC_ROUTER:              btc.NewBTCHandler(),
+	utils.ETH_ROUTER:              eth.NewETHHandler(),
+	utils.ONT_ROUTER:              ont.NewONTHandler(),
+	utils.NEO_ROUTER:              neo.NewNEOHandler(),
+	utils.NEO3_ROUTER:             neo3.NewNeo3Handler(),
+	utils.NEO3_LEGACY_ROUTER:      neo3legacy.NewNeo3Handler(),
+	utils.COSMOS_ROUTER:           cosmos.NewCosmosHandler(),
+	utils.QUORUM_ROUTER:           quorum.NewQuorumHandler(),
+	utils.BSC_ROUTER:              bsc.NewHandler(),
+	utils.HECO_ROUTER:             heco.NewHecoHandler(),
+	utils.ZILLIQA_LEGACY_ROUTER:   zilliqalegacy.NewHandler(),
+	utils.ZILLIQA_ROUTER:          zilliqa.NewHandler(),
+	utils.MSC_ROUTER:              msc.NewHandler(),
+	utils.OKEX_ROUTER:             okex.NewHandler(),
+	utils.POLYGON_HEIMDALL_ROUTER: polygon.NewHeimdallHandler(),
+	utils.POLYGON_BOR_ROUTER:      polygon.NewBorHandler(),
+	utils.PIXIECHAIN_ROUTER:       pixiechain.NewPixieHandler(),
+	utils.STARCOIN_ROUTER:         starcoin.NewSTCHandler(),
+	utils.HSC_ROUTER:              hsc.NewHscHandler(),
+	utils.HARMONY_ROUTER:          harmony.NewHandler(),
+	utils.BYTOM_ROUTER:            bytom.NewHandler(),
+	utils.ETH_POS_ROUTER:          ethpos.NewHandler(),
+	utils.COMETBFT_ROUTER:         cometbft.NewHandler(),
+}
+
+func TestGetChainHandler(t *testing.T) {
+	assert.Equal(t, len(expectedHandlers), len(hscommon.Routers()))
+	for router, expected := range expectedHandlers {
+		handler, err := GetChainHandler(router)
+		assert.Nil(t, err)
+		assert.Equal(t, reflect.TypeOf(expected), reflect.TypeOf(handler), "router %d", router)
+	}
+	for _, router := range []uint64{utils.VOTE_ROUTER, 13, utils.RIPPLE_ROUTER, 100} {
+		_, err := GetChainHandler(router)
+		assert.EqualError(t, err, fmt.Sprintf("not a supported router:%d", router))
+	}
+}
+
+func TestPrunableRouters(t *testing.T) {
+	prunable := map[uint64]bool{
+		utils.ETH_ROUTER:         true,
+		utils.BSC_ROUTER:         true,
+		utils.HECO_ROUTER:        true,
+		utils.MSC_ROUTER:         true,
+		utils.POLYGON_BOR_ROUTER: true,
+	}
+	for _, router := range hscommon.Routers() {
+		info, err := hscommon.GetHandlerInfo(router)
+		assert.Nil(t, err)
+		assert.Equal(t, prunable[router], info.Prunable, "router %d", router)
+	}
+}
+
+func TestRouterStartBlock(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	forked := map[uint64]bool{utils.HSC_ROUTER: true, utils.HARMONY_ROUTER: true, utils.BYTOM_ROUTER: true}
+	unscheduled := map[uint64]bool{utils.ETH_POS_ROUTER: true, utils.COMETBFT_ROUTER: true}
+	for router := range expectedHandlers {
+		if unscheduled[router] {
+			for _, networkId := range []uint32{config.NETWORK_ID_MAIN_NET, config.NETWORK_ID_TEST_NET} {
+				config.DefConfig.P2PNode.NetworkId = networkId
+				_, err := hscommon.GetHandler(router, 18823000)
+				assert.Error(t, err, "router %d", router)
+			}
+			config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+			_, err := hscommon.GetHandler(router, 0)
+			assert.Nil(t, err)
+			continue
+		}
+		config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+		_, err := hscommon.GetHandler(router, 18822999)
+		assert.Equal(t, forked[router], err != nil, "router %d", router)
+		_, err = hscommon.GetHandler(router, 18823000)
+		assert.Nil(t, err)
+
+		config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_TEST_NET
+		_, err = hscommon.GetHandler(router, 0)
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func getStoredGenesisHeader(native *native.NativeService, chainID uint64) (*Header, error) {
+	genesisStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress,
+		[]byte(scom.GENESIS_HEADER), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, get genesisStore error: %v", err)
+	}
+	if genesisStore == nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, can not find any genesis header records")
+	}
+	storeBytes, err := cstates.GetValueFromRawStorageItem(genesisStore)
+	if err != nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, deserialize genesis header from raw storage item err:%v", err)
+	}
+	var headerWithDifficultySum HeaderWithDifficultySum
+	if err := json.Unmarshal(storeBytes, &headerWithDifficultySum); err != nil {
+		return nil, fmt.Errorf("getStoredGenesisHeader, deserialize header error: %v", err)
+	}
+	return &headerWithDifficultySum.Header, nil
+}
+
+//auditHeaders walk the main chain from genesis or pruned height to current height, check the parent links and difficulty sums,
+//then report the headers in HEADER_INDEX which are not in the main chain
+func auditHeaders(native *native.NativeService, audit *scom.HeaderAudit) error {
+	chainID := audit.ChainID
+	genesis, err := getStoredGenesisHeader(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, %v", err)
+	}
+	current, err := GetCurrentHeaderHeight(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, GetCurrentHeaderHeight error: %v", err)
+	}
+	audit.GenesisHeight, audit.CurrentHeight = genesis.Number.Uint64(), current
+	audit.PrunedHeight, err = scom.GetPrunedHeight(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, %v", err)
+	}
+	retention, err := scom.GetHeaderRetention(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, %v", err)
+	}
+	start := audit.GenesisHeight
+	if audit.PrunedHeight > start {
+		start = audit.PrunedHeight
+	}
+
+	mainChain := make(map[common.Hash]bool)
+	if retention != nil {
+		for _, height := range retention.Pinned {
+			if height >= start {
+				continue
+			}
+			hashStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress,
+				[]byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+			if err != nil {
+				return fmt.Errorf("auditHeaders, get main chain index of height %d error: %v", height, err)
+			}
+			if hashStore == nil {
+				audit.AddIssue(height, "", "main chain index of pinned header is missing")
+				continue
+			}
+			hashBytes, err := cstates.GetValueFromRawStorageItem(hashStore)
+			if err != nil {
+				return fmt.Errorf("auditHeaders, deserialize main chain index of height %d error: %v", height, err)
+			}
+			mainChain[common.BytesToHash(hashBytes)] = true
+		}
+	}
+	var parent *Header
+	var parentSum *big.Int
+	for height := start; height <= current; height++ {
+		hashStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress,
+			[]byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+		if err != nil {
+			return fmt.Errorf("auditHeaders, get main chain index of height %d error: %v", height, err)
+		}
+		if hashStore == nil {
+			audit.AddIssue(height, "", "main chain index is missing")
+			parent = nil
+			continue
+		}
+		hashBytes, err := cstates.GetValueFromRawStorageItem(hashStore)
+		if err != nil {
+			return fmt.Errorf("auditHeaders, deserialize main chain index of height %d error: %v", height, err)
+		}
+		hash := common.BytesToHash(hashBytes)
+		mainChain[hash] = true
+		header, sum, err := GetHeaderByHash(native, hashBytes, chainID)
+		if err != nil {
+			audit.AddIssue(height, hash.String(), "header of main chain index is not stored: %v", err)
+			parent = nil
+			continue
+		}
+		audit.MainChain = append(audit.MainChain, &scom.AuditHeader{
+			Height:     height,
+			Hash:       hash.String(),
+			ParentHash: header.ParentHash.String(),
+			Work:       sum.String(),
+		})
+		if header.Hash() != hash {
+			audit.AddIssue(height, hash.String(), "stored header has hash %s", header.Hash().String())
+		}
+		if header.Number.Uint64() != height {
+			audit.AddIssue(height, hash.String(), "stored header has height %d", header.Number.Uint64())
+		}
+		if sum == nil {
+			audit.AddIssue(height, hash.String(), "difficulty sum is missing")
+			parent = nil
+			continue
+		}
+		if height == audit.GenesisHeight {
+			if hash != genesis.Hash() {
+				audit.AddIssue(height, hash.String(), "main chain doesn't start from genesis header %s", genesis.Hash().String())
+			}
+			if sum.Cmp(genesis.Difficulty) != 0 {
+				audit.AddIssue(height, hash.String(), "difficulty sum %s of genesis header should be %s", sum, genesis.Difficulty)
+			}
+		} else if parent != nil {
+			if header.ParentHash != parent.Hash() {
+				audit.AddIssue(height, hash.String(), "parent %s is not the main chain header %s",
+					header.ParentHash.String(), parent.Hash().String())
+			}
+			if expected := new(big.Int).Add(parentSum, header.Difficulty); sum.Cmp(expected) != 0 {
+				audit.AddIssue(height, hash.String(), "difficulty sum %s should be %s", sum, expected)
+			}
+		}
+		parent, parentSum = header, sum
+	}
+
+	iter := native.GetCacheDB().NewIterator(utils.ConcatKey(utils.HeaderSyncContractAddress,
+		[]byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID)))
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		hash := common.BytesToHash(iter.Key()[len(iter.Key())-common.HashLength:])
+		if mainChain[hash] {
+			continue
+		}
+		storeBytes, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return fmt.Errorf("auditHeaders, deserialize header %s from raw storage item err:%v", hash.String(), err)
+		}
+		var headerWithDifficultySum HeaderWithDifficultySum
+		if err := json.Unmarshal(storeBytes, &headerWithDifficultySum); err != nil {
+			audit.AddIssue(0, hash.String(), "deserialize orphan header error: %v", err)
+			continue
+		}
+		header := &headerWithDifficultySum.Header
+		height := header.Number.Uint64()
+		audit.Orphans = append(audit.Orphans, &scom.AuditHeader{
+			Height:     height,
+			Hash:       hash.String(),
+			ParentHash: header.ParentHash.String(),
+			Work:       headerWithDifficultySum.DifficultySum.String(),
+		})
+		if header.Hash() != hash {
+			audit.AddIssue(height, hash.String(), "stored header has hash %s", header.Hash().String())
+		}
+		if height > start {
+			exist, err := IsHeaderExist(native, header.ParentHash.Bytes(), chainID)
+			if err != nil {
+				return fmt.Errorf("auditHeaders, IsHeaderExist error: %v", err)
+			}
+			if !exist {
+				audit.AddIssue(height, hash.String(), "parent %s of orphan header is not stored", header.ParentHash.String())
+			}
+		}
+	}
+	return iter.Error()
+}
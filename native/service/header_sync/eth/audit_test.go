@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/core/types"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func auditTestHeader(parent *Header, difficulty int64, extra string) *Header {
+	header := &Header{
+		Difficulty: big.NewInt(difficulty),
+		Number:     big.NewInt(100),
+		Extra:      []byte(extra),
+	}
+	if parent != nil {
+		header.ParentHash = parent.Hash()
+		header.Number = new(big.Int).Add(parent.Number, big.NewInt(1))
+	}
+	return header
+}
+
+func TestAuditHeaders(t *testing.T) {
+	native := NewNative(nil, &types.Transaction{}, nil)
+	chainID := uint64(2)
+
+	genesis := auditTestHeader(nil, 10, "")
+	assert.Nil(t, putGenesisBlockHeader(native, *genesis, chainID))
+	sum := new(big.Int).Set(genesis.Difficulty)
+	parent := genesis
+	for i := 0; i < 3; i++ {
+		header := auditTestHeader(parent, 10, "")
+		sum = new(big.Int).Add(sum, header.Difficulty)
+		assert.Nil(t, putBlockHeader(native, *header, sum, chainID))
+		assert.Nil(t, appendHeader2Main(native, header.Number.Uint64(), header.Hash(), chainID))
+		parent = header
+	}
+	fork := auditTestHeader(genesis, 5, "fork")
+	assert.Nil(t, putBlockHeader(native, *fork, big.NewInt(15), chainID))
+
+	audit, err := scom.AuditHeaders(native, utils.ETH_ROUTER, chainID)
+	assert.Nil(t, err)
+	assert.True(t, audit.Consistent)
+	assert.Equal(t, uint64(100), audit.GenesisHeight)
+	assert.Equal(t, uint64(103), audit.CurrentHeight)
+	assert.Equal(t, 4, len(audit.MainChain))
+	assert.Equal(t, parent.Hash().String(), audit.MainChain[3].Hash)
+	assert.Equal(t, "40", audit.MainChain[3].Work)
+	assert.Equal(t, 1, len(audit.Orphans))
+	assert.Equal(t, fork.Hash().String(), audit.Orphans[0].Hash)
+
+	//index the fork at height 101 without restructing the chain, header 102 loses its parent
+	assert.Nil(t, appendHeader2Main(native, 101, fork.Hash(), chainID))
+	assert.Nil(t, appendHeader2Main(native, 103, parent.Hash(), chainID))
+	//a header whose parent is not stored
+	dangling := auditTestHeader(auditTestHeader(parent, 10, "missing"), 10, "")
+	assert.Nil(t, putBlockHeader(native, *dangling, big.NewInt(60), chainID))
+
+	audit, err = scom.AuditHeaders(native, utils.ETH_ROUTER, chainID)
+	assert.Nil(t, err)
+	assert.False(t, audit.Consistent)
+	assert.Equal(t, 2, len(audit.Orphans))
+	assert.Equal(t, 3, len(audit.Issues))
+	assert.Equal(t, uint64(102), audit.Issues[0].Height)
+	assert.Contains(t, audit.Issues[0].Message, "is not the main chain header")
+	assert.Equal(t, uint64(102), audit.Issues[1].Height)
+	assert.Contains(t, audit.Issues[1].Message, "difficulty sum 30 should be 25")
+	assert.Equal(t, uint64(105), audit.Issues[2].Height)
+	assert.Contains(t, audit.Issues[2].Message, "of orphan header is not stored")
+}
+
+func TestAuditHeadersNoGenesis(t *testing.T) {
+	native := NewNative(nil, &types.Transaction{}, nil)
+	_, err := scom.AuditHeaders(native, utils.ETH_ROUTER, 2)
+	assert.NotNil(t, err)
+}
@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"hash"
 	"math/big"
-	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/polynetwork/poly/common/log"
@@ -54,6 +53,15 @@ var (
 type ETHHandler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.ETH_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewETHHandler() },
+		Audit:      auditHeaders,
+		Prunable:   true,
+	})
+}
+
 func NewETHHandler() *ETHHandler {
 	return &ETHHandler{}
 }
@@ -143,8 +151,8 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("SyncBlockHeader, SyncBlockHeader extra-data too long: %d > %d, header: %s", len(header.Extra), params.MaximumExtraDataSize, string(v))
 		}
 		//verify current time validity
-		if header.Time > uint64(time.Now().Add(allowedFutureBlockTime).Unix()) {
-			return fmt.Errorf("SyncBlockHeader,  verify header time error:%s, checktime: %d, header: %s", consensus.ErrFutureBlock, time.Now().Add(allowedFutureBlockTime).Unix(), string(v))
+		if header.Time > uint64(native.Now().Add(allowedFutureBlockTime).Unix()) {
+			return fmt.Errorf("SyncBlockHeader,  verify header time error:%s, checktime: %d, header: %s", consensus.ErrFutureBlock, native.Now().Add(allowedFutureBlockTime).Unix(), string(v))
 		}
 		//verify whether current header time and prevent header time validity
 		if header.Time <= parentHeader.Time {
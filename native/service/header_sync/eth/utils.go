@@ -87,6 +87,9 @@ func putBlockHeader(native *native.NativeService, blockHeader Header, difficulty
 	//HEADER_INDEX => the mapping of header hash and block header byte code, for querying block header by its hash
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), blockHeader.Hash().Bytes()),
 		cstates.GenRawStorageItem(storeBytes))
+	if err := scom.PutHeaderHash(native, chainID, blockHeader.Number.Uint64(), blockHeader.Hash().Bytes()); err != nil {
+		return err
+	}
 	scom.NotifyPutHeader(native, chainID, blockHeader.Number.Uint64(), blockHeader.Hash().String())
 	return nil
 }
@@ -97,7 +100,7 @@ func appendHeader2Main(native *native.NativeService, height uint64, txhash commo
 		cstates.GenRawStorageItem(txhash.Bytes()))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
-	scom.NotifyPutHeader(native, chainID, height, txhash.String())
+	scom.NotifyAppendHeader(native, chainID, height, txhash.String())
 	return nil
 }
 func GetCurrentHeader(native *native.NativeService, chainID uint64) (*Header, *big.Int, error) {
@@ -134,6 +137,9 @@ func GetHeaderByHeight(native *native.NativeService, height, chainID uint64) (*H
 	if height > latestHeight {
 		return nil, big.NewInt(0), fmt.Errorf("GetHeaderByHeight, height is too big")
 	}
+	if err := scom.CheckHeaderRetained(native, chainID, height); err != nil {
+		return nil, big.NewInt(0), fmt.Errorf("GetHeaderByHeight, %v", err)
+	}
 	headerStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress,
 		[]byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
 	if err != nil {
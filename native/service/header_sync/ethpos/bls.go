@@ -0,0 +1,277 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+const (
+	BLS_PUBKEY_LENGTH    = 48
+	BLS_SIGNATURE_LENGTH = 96
+)
+
+//domain separation tag of the proof of possession scheme used by ethereum consensus
+var BLS_DST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+var (
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+	//(p-1)/2, a coordinate greater than it is lexicographically largest
+	halfModulus = new(big.Int).Rsh(fieldModulus, 1)
+	//(p+1)/4, square root exponent as p = 3 mod 4
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(fieldModulus, big.NewInt(1)), 2)
+	//(p-3)/4
+	sqrtExp2 = new(big.Int).Rsh(new(big.Int).Sub(fieldModulus, big.NewInt(3)), 2)
+)
+
+//fp2Element is a + b*i with i^2 = -1
+type fp2Element struct {
+	a, b *big.Int
+}
+
+func newFp2(a, b *big.Int) *fp2Element {
+	return &fp2Element{a: new(big.Int).Mod(a, fieldModulus), b: new(big.Int).Mod(b, fieldModulus)}
+}
+
+func (x *fp2Element) mul(y *fp2Element) *fp2Element {
+	ac := new(big.Int).Mul(x.a, y.a)
+	bd := new(big.Int).Mul(x.b, y.b)
+	ad := new(big.Int).Mul(x.a, y.b)
+	bc := new(big.Int).Mul(x.b, y.a)
+	return newFp2(ac.Sub(ac, bd), ad.Add(ad, bc))
+}
+
+func (x *fp2Element) exp(e *big.Int) *fp2Element {
+	r := newFp2(big.NewInt(1), big.NewInt(0))
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = r.mul(r)
+		if e.Bit(i) == 1 {
+			r = r.mul(x)
+		}
+	}
+	return r
+}
+
+func (x *fp2Element) equal(y *fp2Element) bool {
+	return x.a.Cmp(y.a) == 0 && x.b.Cmp(y.b) == 0
+}
+
+//sqrt of fp2 element for p = 3 mod 4, algorithm 9 of https://eprint.iacr.org/2012/685.pdf
+func (x *fp2Element) sqrt() (*fp2Element, bool) {
+	a1 := x.exp(sqrtExp2)
+	alpha := a1.mul(a1).mul(x)
+	x0 := a1.mul(x)
+	var r *fp2Element
+	if alpha.equal(newFp2(big.NewInt(-1), big.NewInt(0))) {
+		r = x0.mul(newFp2(big.NewInt(0), big.NewInt(1)))
+	} else {
+		b := newFp2(new(big.Int).Add(alpha.a, big.NewInt(1)), alpha.b).exp(halfModulus)
+		r = b.mul(x0)
+	}
+	return r, r.mul(r).equal(x)
+}
+
+//parseFlags returns the field element bytes with the zcash serialization flags cleared
+func parseFlags(in []byte) (data []byte, infinity, sign bool, err error) {
+	if in[0]&0x80 == 0 {
+		return nil, false, false, errors.New("point is not compressed")
+	}
+	infinity, sign = in[0]&0x40 != 0, in[0]&0x20 != 0
+	data = make([]byte, len(in))
+	copy(data, in)
+	data[0] &= 0x1f
+	return
+}
+
+func fieldBytes(x *big.Int) []byte {
+	return math.PaddedBigBytes(x, 48)
+}
+
+//DecompressG1 decodes a compressed bls public key, the key must be in G1 subgroup and not infinity
+func DecompressG1(in []byte) (*bls12381.PointG1, error) {
+	if len(in) != BLS_PUBKEY_LENGTH {
+		return nil, fmt.Errorf("invalid public key length %d", len(in))
+	}
+	data, infinity, sign, err := parseFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	if infinity {
+		return nil, errors.New("public key is infinity")
+	}
+	x := new(big.Int).SetBytes(data)
+	if x.Cmp(fieldModulus) >= 0 {
+		return nil, errors.New("x coordinate is not in field")
+	}
+	//y^2 = x^3 + 4
+	rhs := new(big.Int).Exp(x, big.NewInt(3), fieldModulus)
+	rhs.Add(rhs, big.NewInt(4)).Mod(rhs, fieldModulus)
+	y := new(big.Int).Exp(rhs, sqrtExp, fieldModulus)
+	if new(big.Int).Exp(y, big.NewInt(2), fieldModulus).Cmp(rhs) != 0 {
+		return nil, errors.New("point is not on curve")
+	}
+	if (y.Cmp(halfModulus) > 0) != sign {
+		y.Sub(fieldModulus, y)
+	}
+	g1 := bls12381.NewG1()
+	p, err := g1.FromBytes(append(fieldBytes(x), fieldBytes(y)...))
+	if err != nil {
+		return nil, err
+	}
+	if !g1.InCorrectSubgroup(p) {
+		return nil, errors.New("public key is not in correct subgroup")
+	}
+	return p, nil
+}
+
+//DecompressG2 decodes a compressed bls signature, the signature must be in G2 subgroup
+func DecompressG2(in []byte) (*bls12381.PointG2, error) {
+	if len(in) != BLS_SIGNATURE_LENGTH {
+		return nil, fmt.Errorf("invalid signature length %d", len(in))
+	}
+	data, infinity, sign, err := parseFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	g2 := bls12381.NewG2()
+	if infinity {
+		return g2.Zero(), nil
+	}
+	//x = x0 + x1*i, serialized as x1 || x0
+	x1, x0 := new(big.Int).SetBytes(data[:48]), new(big.Int).SetBytes(data[48:])
+	if x0.Cmp(fieldModulus) >= 0 || x1.Cmp(fieldModulus) >= 0 {
+		return nil, errors.New("x coordinate is not in field")
+	}
+	//y^2 = x^3 + 4(1+i)
+	x := newFp2(x0, x1)
+	rhs := x.mul(x).mul(x)
+	rhs = newFp2(new(big.Int).Add(rhs.a, big.NewInt(4)), new(big.Int).Add(rhs.b, big.NewInt(4)))
+	y, ok := rhs.sqrt()
+	if !ok {
+		return nil, errors.New("point is not on curve")
+	}
+	largest := y.b.Cmp(halfModulus) > 0
+	if y.b.Sign() == 0 {
+		largest = y.a.Cmp(halfModulus) > 0
+	}
+	if largest != sign {
+		y = newFp2(new(big.Int).Neg(y.a), new(big.Int).Neg(y.b))
+	}
+	raw := make([]byte, 0, 192)
+	raw = append(raw, fieldBytes(x1)...)
+	raw = append(raw, fieldBytes(x0)...)
+	raw = append(raw, fieldBytes(y.b)...)
+	raw = append(raw, fieldBytes(y.a)...)
+	p, err := g2.FromBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !g2.InCorrectSubgroup(p) {
+		return nil, errors.New("signature is not in correct subgroup")
+	}
+	return p, nil
+}
+
+//expandMessageXMD implements expand_message_xmd of RFC 9380 with sha256
+func expandMessageXMD(msg, dst []byte, length int) ([]byte, error) {
+	const bInBytes, rInBytes = 32, 64
+	ell := (length + bInBytes - 1) / bInBytes
+	if ell > 255 || len(dst) > 255 {
+		return nil, errors.New("invalid expand message length")
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	h.Write(make([]byte, rInBytes))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+	out := append(make([]byte, 0, ell*bInBytes), bi...)
+	for i := 2; i <= ell; i++ {
+		tmp := make([]byte, bInBytes)
+		for j := range tmp {
+			tmp[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(tmp)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length], nil
+}
+
+//HashToG2 implements hash_to_curve of suite BLS12381G2_XMD:SHA-256_SSWU_RO_
+func HashToG2(msg, dst []byte) (*bls12381.PointG2, error) {
+	//two fp2 elements, each of two 64 bytes chunks
+	uniform, err := expandMessageXMD(msg, dst, 256)
+	if err != nil {
+		return nil, err
+	}
+	g2 := bls12381.NewG2()
+	var u [2][]byte
+	for i := range u {
+		c0 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[i*128:i*128+64]), fieldModulus)
+		c1 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[i*128+64:i*128+128]), fieldModulus)
+		u[i] = append(fieldBytes(c1), fieldBytes(c0)...)
+	}
+	//cofactor clearing is a scalar multiplication, so it can be done on each mapped point before adding them
+	q0, err := g2.MapToCurve(u[0])
+	if err != nil {
+		return nil, err
+	}
+	q1, err := g2.MapToCurve(u[1])
+	if err != nil {
+		return nil, err
+	}
+	return g2.Affine(g2.Add(g2.New(), q0, q1)), nil
+}
+
+//FastAggregateVerify verifies an aggregate signature of the same message signed by all public keys
+func FastAggregateVerify(pubKeys []*bls12381.PointG1, msg []byte, sig *bls12381.PointG2) (bool, error) {
+	if len(pubKeys) == 0 {
+		return false, errors.New("no public key")
+	}
+	g1 := bls12381.NewG1()
+	aggPubKey := g1.Zero()
+	for _, pk := range pubKeys {
+		g1.Add(aggPubKey, aggPubKey, pk)
+	}
+	h, err := HashToG2(msg, BLS_DST)
+	if err != nil {
+		return false, fmt.Errorf("hash to curve error: %s", err)
+	}
+	//e(pk, H(m)) == e(g1, sig)
+	engine := bls12381.NewPairingEngine()
+	engine.AddPair(g1.Affine(aggPubKey), h)
+	engine.AddPairInv(g1.One(), sig)
+	return engine.Check(), nil
+}
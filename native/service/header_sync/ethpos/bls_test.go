@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/stretchr/testify/assert"
+)
+
+func compressG1(p *bls12381.PointG1) []byte {
+	g1 := bls12381.NewG1()
+	raw := g1.ToBytes(g1.Affine(p))
+	out := append([]byte{}, raw[:48]...)
+	out[0] |= 0x80
+	if new(big.Int).SetBytes(raw[48:]).Cmp(halfModulus) > 0 {
+		out[0] |= 0x20
+	}
+	return out
+}
+
+func compressG2(p *bls12381.PointG2) []byte {
+	g2 := bls12381.NewG2()
+	raw := g2.ToBytes(g2.Affine(p))
+	out := append([]byte{}, raw[:96]...)
+	y1, y0 := new(big.Int).SetBytes(raw[96:144]), new(big.Int).SetBytes(raw[144:])
+	largest := y1.Cmp(halfModulus) > 0
+	if y1.Sign() == 0 {
+		largest = y0.Cmp(halfModulus) > 0
+	}
+	out[0] |= 0x80
+	if largest {
+		out[0] |= 0x20
+	}
+	return out
+}
+
+func mustDecode(s string) []byte {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	//RFC 9380 K.1
+	out, err := expandMessageXMD([]byte(""), []byte("QUUX-V01-CS02-with-expander-SHA256-128"), 0x20)
+	assert.Nil(t, err)
+	assert.Equal(t, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235", hex.EncodeToString(out))
+}
+
+func TestHashToG2(t *testing.T) {
+	//RFC 9380 J.10.1, msg is empty
+	p, err := HashToG2([]byte(""), []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"))
+	assert.Nil(t, err)
+	raw := bls12381.NewG2().ToBytes(p)
+	assert.Equal(t, "05cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d", hex.EncodeToString(raw[:48]))
+	assert.Equal(t, "0141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a", hex.EncodeToString(raw[48:96]))
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	//sign case of ethereum consensus bls spec tests
+	pubKey := mustDecode("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a")
+	sig := mustDecode("b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55")
+	msg := make([]byte, 32)
+
+	pk, err := DecompressG1(pubKey)
+	assert.Nil(t, err)
+	assert.Equal(t, pubKey, compressG1(pk))
+	s, err := DecompressG2(sig)
+	assert.Nil(t, err)
+	assert.Equal(t, sig, compressG2(s))
+
+	ok, err := FastAggregateVerify([]*bls12381.PointG1{pk}, msg, s)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	msg[0] = 1
+	ok, err = FastAggregateVerify([]*bls12381.PointG1{pk}, msg, s)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	//aggregate of two keys
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	h, err := HashToG2(msg, BLS_DST)
+	assert.Nil(t, err)
+	sk1, sk2 := big.NewInt(12345), big.NewInt(67890)
+	pk1 := g1.MulScalar(g1.New(), g1.One(), sk1)
+	pk2 := g1.MulScalar(g1.New(), g1.One(), sk2)
+	agg := g2.MulScalar(g2.New(), h, new(big.Int).Add(sk1, sk2))
+	ok, err = FastAggregateVerify([]*bls12381.PointG1{pk1, pk2}, msg, agg)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = FastAggregateVerify([]*bls12381.PointG1{pk1}, msg, agg)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestDecompressInvalid(t *testing.T) {
+	pubKey := mustDecode("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a")
+	_, err := DecompressG1(pubKey[1:])
+	assert.NotNil(t, err)
+
+	uncompressed := append([]byte{}, pubKey...)
+	uncompressed[0] &= 0x7f
+	_, err = DecompressG1(uncompressed)
+	assert.NotNil(t, err)
+
+	infinity := make([]byte, BLS_PUBKEY_LENGTH)
+	infinity[0] = 0xc0
+	_, err = DecompressG1(infinity)
+	assert.NotNil(t, err)
+
+	//x = 2 is not the x coordinate of a G1 point
+	notOnCurve := make([]byte, BLS_PUBKEY_LENGTH)
+	notOnCurve[0] = 0x80
+	notOnCurve[BLS_PUBKEY_LENGTH-1] = 2
+	_, err = DecompressG1(notOnCurve)
+	assert.NotNil(t, err)
+
+	_, err = DecompressG2(make([]byte, BLS_SIGNATURE_LENGTH))
+	assert.NotNil(t, err)
+}
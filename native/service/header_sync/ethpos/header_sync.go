@@ -0,0 +1,281 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//Handler follows the beacon chain with light client updates signed by sync committees,
+//and keeps the execution payload headers of finalized beacon blocks
+type Handler struct {
+}
+
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:      utils.ETH_POS_ROUTER,
+		NewHandler:  func() scom.HeaderSyncHandler { return NewHandler() },
+		StartBlocks: utils.EthPosRouterStartBlocks,
+	})
+}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+func getContext(native *native.NativeService, chainID uint64) (*Context, error) {
+	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("get side chain error %s", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("side chain %d is not registered", chainID)
+	}
+	return DecodeContext(sideChain.ExtraInfo)
+}
+
+//SyncGenesisHeader stores a trusted light client bootstrap as the start point of the chain
+func (this *Handler) SyncGenesisHeader(native *native.NativeService) error {
+	params := new(scom.SyncGenesisHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, contract params deserialize error: %v", err)
+	}
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, get current consensus operator address error: %v", err)
+	}
+	if err := utils.ValidateOwner(native, operatorAddress); err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, checkWitness error: %v", err)
+	}
+	genesis, err := getGenesisHeader(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, get genesis header error: %v", err)
+	}
+	if genesis != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, genesis header had been initialized")
+	}
+	ctx, err := getContext(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, %v", err)
+	}
+	bootstrap := new(LightClientBootstrap)
+	if err := json.Unmarshal(params.GenesisHeader, bootstrap); err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, unmarshal bootstrap error: %v", err)
+	}
+	committee, err := ctx.VerifyBootstrap(bootstrap)
+	if err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncGenesisHeader, %v", err)
+	}
+	header := &bootstrap.Header
+	putGenesisHeader(native, params.ChainID, header)
+	putSyncCommittee(native, params.ChainID, ctx.Period(uint64(header.Beacon.Slot)), committee)
+	putFinalizedHeader(native, params.ChainID, &header.Beacon)
+	putHeader(native, params.ChainID, header.Execution)
+	return nil
+}
+
+//SyncBlockHeader applies light client updates, each header of params is a json encoded LightClientUpdate
+func (this *Handler) SyncBlockHeader(native *native.NativeService) error {
+	params := new(scom.SyncBlockHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncBlockHeader, contract params deserialize error: %v", err)
+	}
+	ctx, err := getContext(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ETHPoSHandler SyncBlockHeader, %v", err)
+	}
+	for i, v := range params.Headers {
+		update := new(LightClientUpdate)
+		if err := json.Unmarshal(v, update); err != nil {
+			return fmt.Errorf("ETHPoSHandler SyncBlockHeader, unmarshal update %d error: %v", i, err)
+		}
+		if err := processUpdate(native, ctx, params.ChainID, update); err != nil {
+			return fmt.Errorf("ETHPoSHandler SyncBlockHeader, update %d error: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (this *Handler) SyncCrossChainMsg(native *native.NativeService) error {
+	return nil
+}
+
+func processUpdate(native *native.NativeService, ctx *Context, chainID uint64, update *LightClientUpdate) error {
+	finalized, err := GetFinalizedHeader(native, chainID)
+	if err != nil {
+		return err
+	}
+	getCommittee := func(period uint64) (*SyncCommitteeKeys, error) {
+		return GetSyncCommittee(native, chainID, period)
+	}
+	next, err := ctx.VerifyUpdate(finalized, getCommittee, update)
+	if err != nil {
+		return err
+	}
+	if next != nil {
+		putSyncCommittee(native, chainID, ctx.Period(uint64(update.AttestedHeader.Beacon.Slot))+1, next)
+	}
+	header := update.FinalizedHeader
+	if header.Beacon.Slot <= finalized.Slot {
+		return nil
+	}
+	putFinalizedHeader(native, chainID, &header.Beacon)
+	height, err := GetCurrentHeaderHeight(native, chainID)
+	if err != nil {
+		return err
+	}
+	if uint64(header.Execution.BlockNumber) > height {
+		putHeader(native, chainID, header.Execution)
+	}
+	return nil
+}
+
+//VerifyBootstrap checks the current sync committee of bootstrap header and returns its keys
+func (ctx *Context) VerifyBootstrap(bootstrap *LightClientBootstrap) (*SyncCommitteeKeys, error) {
+	header := &bootstrap.Header
+	if err := header.Verify(); err != nil {
+		return nil, fmt.Errorf("verify bootstrap header error: %s", err)
+	}
+	if bootstrap.CurrentSyncCommittee == nil {
+		return nil, fmt.Errorf("current sync committee is missing")
+	}
+	committee, err := NewSyncCommitteeKeys(bootstrap.CurrentSyncCommittee, ctx.SyncCommitteeSize)
+	if err != nil {
+		return nil, fmt.Errorf("current sync committee error: %s", err)
+	}
+	slot := uint64(header.Beacon.Slot)
+	if !isValidMerkleBranch(committee.Root, bootstrap.CurrentSyncCommitteeBranch, ctx.currentSyncCommitteeGindex(slot),
+		header.Beacon.StateRoot) {
+		return nil, fmt.Errorf("invalid current sync committee branch")
+	}
+	return committee, nil
+}
+
+//VerifyUpdate validates a light client update against the finalized header of store, the update must be signed by
+//at least 2/3 of the sync committee and prove a finalized header. It returns the keys of next sync committee of the
+//attested period if the update brings a new one.
+func (ctx *Context) VerifyUpdate(store *BeaconBlockHeader, getCommittee func(period uint64) (*SyncCommitteeKeys, error),
+	update *LightClientUpdate) (*SyncCommitteeKeys, error) {
+	if !update.IsFinalityUpdate() {
+		return nil, fmt.Errorf("update does not prove finality")
+	}
+	attested, finalized := &update.AttestedHeader, update.FinalizedHeader
+	signatureSlot, attestedSlot, finalizedSlot := uint64(update.SignatureSlot), uint64(attested.Beacon.Slot), uint64(finalized.Beacon.Slot)
+	if signatureSlot <= attestedSlot || attestedSlot < finalizedSlot {
+		return nil, fmt.Errorf("invalid slots, signature slot %d, attested slot %d, finalized slot %d", signatureSlot,
+			attestedSlot, finalizedSlot)
+	}
+
+	storePeriod := ctx.Period(uint64(store.Slot))
+	signaturePeriod := ctx.Period(signatureSlot)
+	var committee *SyncCommitteeKeys
+	var err error
+	switch signaturePeriod {
+	case storePeriod, storePeriod + 1:
+		committee, err = getCommittee(signaturePeriod)
+	default:
+		return nil, fmt.Errorf("signature period %d is not current or next period of store period %d", signaturePeriod,
+			storePeriod)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get sync committee of period %d error: %s", signaturePeriod, err)
+	}
+	if committee == nil {
+		return nil, fmt.Errorf("sync committee of period %d is unknown", signaturePeriod)
+	}
+
+	//next sync committee is accepted only when the attested and finalized headers are in the same period
+	attestedPeriod := ctx.Period(attestedSlot)
+	hasNext := false
+	if update.IsSyncCommitteeUpdate() && ctx.Period(finalizedSlot) == attestedPeriod {
+		known, err := getCommittee(attestedPeriod + 1)
+		if err != nil {
+			return nil, fmt.Errorf("get sync committee of period %d error: %s", attestedPeriod+1, err)
+		}
+		root, err := update.NextSyncCommittee.HashTreeRoot(ctx.SyncCommitteeSize)
+		if err != nil {
+			return nil, fmt.Errorf("next sync committee error: %s", err)
+		}
+		if !isValidMerkleBranch(root, update.NextSyncCommitteeBranch, ctx.nextSyncCommitteeGindex(attestedSlot),
+			attested.Beacon.StateRoot) {
+			return nil, fmt.Errorf("invalid next sync committee branch")
+		}
+		if known != nil && known.Root != root {
+			return nil, fmt.Errorf("next sync committee %s mismatch with known %s", root.Hex(), known.Root.Hex())
+		}
+		hasNext = known == nil
+	}
+	if finalizedSlot <= uint64(store.Slot) && !hasNext {
+		return nil, fmt.Errorf("update is not newer than finalized slot %d", store.Slot)
+	}
+
+	if err := attested.Verify(); err != nil {
+		return nil, fmt.Errorf("verify attested header error: %s", err)
+	}
+	if err := finalized.Verify(); err != nil {
+		return nil, fmt.Errorf("verify finalized header error: %s", err)
+	}
+	if !isValidMerkleBranch(finalized.Beacon.HashTreeRoot(), update.FinalityBranch, ctx.finalizedRootGindex(attestedSlot),
+		attested.Beacon.StateRoot) {
+		return nil, fmt.Errorf("invalid finality branch")
+	}
+
+	participants := 0
+	for _, b := range update.SyncAggregate.SyncCommitteeBits {
+		participants += bits.OnesCount8(b)
+	}
+	if uint64(participants)*3 < ctx.SyncCommitteeSize*2 {
+		return nil, fmt.Errorf("not enough sync committee participants %d", participants)
+	}
+	pubKeys, err := committee.Participants(update.SyncAggregate.SyncCommitteeBits)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := DecompressG2(update.SyncAggregate.SyncCommitteeSignature)
+	if err != nil {
+		return nil, fmt.Errorf("sync committee signature error: %s", err)
+	}
+	//signature slot is greater than attested slot so it is never zero
+	domain := computeDomain(DOMAIN_SYNC_COMMITTEE, ctx.forkVersion(ctx.epoch(signatureSlot-1)), ctx.GenesisValidatorsRoot)
+	signingRoot := computeSigningRoot(attested.Beacon.HashTreeRoot(), domain)
+	ok, err := FastAggregateVerify(pubKeys, signingRoot[:], sig)
+	if err != nil {
+		return nil, fmt.Errorf("verify sync committee signature error: %s", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid sync committee signature")
+	}
+
+	if !hasNext {
+		return nil, nil
+	}
+	next, err := NewSyncCommitteeKeys(update.NextSyncCommittee, ctx.SyncCommitteeSize)
+	if err != nil {
+		return nil, fmt.Errorf("next sync committee error: %s", err)
+	}
+	return next, nil
+}
@@ -0,0 +1,622 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	pcom "github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	updateFixtures = flag.Bool("update", false, "regenerate light client fixtures in testdata")
+	recordBeacon   = flag.String("record-beacon", "", "beacon node `url` to record the mainnet light client fixture from")
+)
+
+const (
+	FIXTURE_FILE          = "testdata/light_client.json"
+	MAINNET_FIXTURE_FILE  = "testdata/mainnet_light_client.json"
+	TEST_COMMITTEE_SIZE   = 32
+	FAR_FUTURE_FORK_EPOCH = "18446744073709551615"
+)
+
+var blsGroupOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+//lightClientFixtures are light client data in beacon api json format. FIXTURE_FILE is signed by test keys of a
+//minimal preset chain to cover the error cases, MAINNET_FIXTURE_FILE is recorded from a mainnet beacon node.
+type lightClientFixtures struct {
+	Context   *Context              `json:"context"`
+	Bootstrap *LightClientBootstrap `json:"bootstrap"`
+	Updates   []*LightClientUpdate  `json:"updates"`
+}
+
+func testHash(items ...uint64) common.Hash {
+	h := sha256.New()
+	for _, item := range items {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], item)
+		h.Write(b[:])
+	}
+	return common.BytesToHash(h.Sum(nil))
+}
+
+//merkleBranch returns the siblings from leaf index to root of a full tree
+func merkleBranch(leaves []common.Hash, index int) []common.Hash {
+	var branch []common.Hash
+	layer := leaves
+	for len(layer) > 1 {
+		branch = append(branch, layer[index^1])
+		next := make([]common.Hash, len(layer)/2)
+		for i := range next {
+			next[i] = hashTwo(layer[2*i], layer[2*i+1])
+		}
+		layer, index = next, index/2
+	}
+	return branch
+}
+
+func testSecretKeys(period uint64) []*big.Int {
+	keys := make([]*big.Int, TEST_COMMITTEE_SIZE)
+	for i := range keys {
+		keys[i] = new(big.Int).Mod(testHash(period, uint64(i)).Big(), blsGroupOrder)
+	}
+	return keys
+}
+
+func testSyncCommittee(period uint64) *SyncCommittee {
+	g1 := bls12381.NewG1()
+	committee := &SyncCommittee{}
+	agg := g1.Zero()
+	for _, sk := range testSecretKeys(period) {
+		pk := g1.MulScalar(g1.New(), g1.One(), sk)
+		g1.Add(agg, agg, pk)
+		committee.PubKeys = append(committee.PubKeys, compressG1(pk))
+	}
+	committee.AggregatePubKey = compressG1(agg)
+	return committee
+}
+
+//testHeader builds a light client header whose beacon state has the given leaves at depth 5
+func testHeader(slot uint64, stateLeaves map[int]common.Hash) *LightClientHeader {
+	blobGasUsed, excessBlobGas := Uint64(131072), Uint64(0)
+	execution := &ExecutionPayloadHeader{
+		ParentHash:       testHash(slot, 1),
+		FeeRecipient:     common.BytesToAddress(testHash(slot, 2).Bytes()),
+		StateRoot:        testHash(slot, 3),
+		ReceiptsRoot:     testHash(slot, 4),
+		LogsBloom:        make([]byte, LOGS_BLOOM_LENGTH),
+		PrevRandao:       testHash(slot, 5),
+		BlockNumber:      Uint64(1000000 + slot),
+		GasLimit:         30000000,
+		GasUsed:          21000,
+		Timestamp:        Uint64(1600000000 + 12*slot),
+		ExtraData:        []byte("ethpos"),
+		BaseFeePerGas:    (*Uint256)(big.NewInt(7)),
+		BlockHash:        testHash(slot, 6),
+		TransactionsRoot: testHash(slot, 7),
+		WithdrawalsRoot:  testHash(slot, 8),
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+	}
+	executionRoot, err := execution.HashTreeRoot()
+	if err != nil {
+		panic(err)
+	}
+	body := make([]common.Hash, 16)
+	for i := range body {
+		body[i] = testHash(slot, 100+uint64(i))
+	}
+	body[EXECUTION_PAYLOAD_GINDEX-16] = executionRoot
+	state := make([]common.Hash, 32)
+	for i := range state {
+		state[i] = testHash(slot, 200+uint64(i))
+		if leaf, ok := stateLeaves[i]; ok {
+			state[i] = leaf
+		}
+	}
+	return &LightClientHeader{
+		Beacon: BeaconBlockHeader{
+			Slot:          Uint64(slot),
+			ProposerIndex: Uint64(slot % 7),
+			ParentRoot:    testHash(slot, 9),
+			StateRoot:     merkleize(state, len(state)),
+			BodyRoot:      merkleize(body, len(body)),
+		},
+		Execution:       execution,
+		ExecutionBranch: merkleBranch(body, int(EXECUTION_PAYLOAD_GINDEX-16)),
+	}
+}
+
+func committeeRoot(committee *SyncCommittee) common.Hash {
+	root, err := committee.HashTreeRoot(TEST_COMMITTEE_SIZE)
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+func testBootstrap(ctx *Context, slot uint64) *LightClientBootstrap {
+	committee := testSyncCommittee(ctx.Period(slot))
+	leaves := map[int]common.Hash{int(CURRENT_SYNC_COMMITTEE_GINDEX - 32): committeeRoot(committee)}
+	header := testHeader(slot, leaves)
+	state := make([]common.Hash, 32)
+	for i := range state {
+		state[i] = testHash(slot, 200+uint64(i))
+	}
+	state[CURRENT_SYNC_COMMITTEE_GINDEX-32] = leaves[int(CURRENT_SYNC_COMMITTEE_GINDEX-32)]
+	return &LightClientBootstrap{
+		Header:                     *header,
+		CurrentSyncCommittee:       committee,
+		CurrentSyncCommitteeBranch: merkleBranch(state, int(CURRENT_SYNC_COMMITTEE_GINDEX-32)),
+	}
+}
+
+//testUpdate builds an update signed by the first participants of the committee of signature period
+func testUpdate(ctx *Context, attestedSlot, finalizedSlot, signatureSlot uint64, withNext bool, participants int) *LightClientUpdate {
+	finalized := testHeader(finalizedSlot, nil)
+	finalizedEpoch := uint64Root(ctx.epoch(finalizedSlot))
+	//finalized checkpoint is field 20 of beacon state, its root is the second field of checkpoint
+	checkpointIndex := int(FINALIZED_ROOT_GINDEX/2 - 32)
+	nextIndex := int(NEXT_SYNC_COMMITTEE_GINDEX - 32)
+	leaves := map[int]common.Hash{checkpointIndex: hashTwo(finalizedEpoch, finalized.Beacon.HashTreeRoot())}
+	next := &SyncCommittee{PubKeys: make([]hexutil.Bytes, TEST_COMMITTEE_SIZE), AggregatePubKey: make([]byte, BLS_PUBKEY_LENGTH)}
+	for i := range next.PubKeys {
+		next.PubKeys[i] = make([]byte, BLS_PUBKEY_LENGTH)
+	}
+	if withNext {
+		next = testSyncCommittee(ctx.Period(attestedSlot) + 1)
+		leaves[nextIndex] = committeeRoot(next)
+	}
+	attested := testHeader(attestedSlot, leaves)
+	state := make([]common.Hash, 32)
+	for i := range state {
+		state[i] = testHash(attestedSlot, 200+uint64(i))
+		if leaf, ok := leaves[i]; ok {
+			state[i] = leaf
+		}
+	}
+	update := &LightClientUpdate{
+		AttestedHeader:          *attested,
+		NextSyncCommittee:       next,
+		NextSyncCommitteeBranch: make([]common.Hash, 5),
+		FinalizedHeader:         finalized,
+		FinalityBranch:          append([]common.Hash{finalizedEpoch}, merkleBranch(state, checkpointIndex)...),
+		SignatureSlot:           Uint64(signatureSlot),
+	}
+	if withNext {
+		update.NextSyncCommitteeBranch = merkleBranch(state, nextIndex)
+	}
+
+	bits := make([]byte, TEST_COMMITTEE_SIZE/8)
+	sk := new(big.Int)
+	for i, key := range testSecretKeys(ctx.Period(signatureSlot)) {
+		if i < participants {
+			bits[i/8] |= 1 << uint(i%8)
+			sk.Add(sk, key)
+		}
+	}
+	domain := computeDomain(DOMAIN_SYNC_COMMITTEE, ctx.forkVersion(ctx.epoch(signatureSlot-1)), ctx.GenesisValidatorsRoot)
+	signingRoot := computeSigningRoot(attested.Beacon.HashTreeRoot(), domain)
+	h, err := HashToG2(signingRoot[:], BLS_DST)
+	if err != nil {
+		panic(err)
+	}
+	g2 := bls12381.NewG2()
+	update.SyncAggregate = SyncAggregate{
+		SyncCommitteeBits:      bits,
+		SyncCommitteeSignature: compressG2(g2.MulScalar(g2.New(), h, sk.Mod(sk, blsGroupOrder))),
+	}
+	return update
+}
+
+func generateFixtures() *lightClientFixtures {
+	ctx := &Context{
+		GenesisValidatorsRoot: testHash(0),
+		Forks: []*Fork{
+			{Epoch: 0, Version: []byte{0x00, 0x00, 0x00, 0x01}},
+			{Epoch: 85, Version: []byte{0x01, 0x00, 0x00, 0x01}},
+		},
+		SyncCommitteeSize:            TEST_COMMITTEE_SIZE,
+		SlotsPerEpoch:                8,
+		EpochsPerSyncCommitteePeriod: 8,
+	}
+	//a sync committee period is 64 slots, bootstrap is in period 10
+	return &lightClientFixtures{
+		Context:   ctx,
+		Bootstrap: testBootstrap(ctx, 640),
+		Updates: []*LightClientUpdate{
+			//next sync committee of period 10
+			testUpdate(ctx, 650, 645, 651, true, TEST_COMMITTEE_SIZE),
+			//signed by committee of period 11 with a new fork version
+			testUpdate(ctx, 710, 705, 711, true, 30),
+			//finality update without sync committee
+			testUpdate(ctx, 720, 715, 721, false, 22),
+		},
+	}
+}
+
+func loadFixtures(t *testing.T) *lightClientFixtures {
+	if *updateFixtures {
+		data, err := json.MarshalIndent(generateFixtures(), "", "  ")
+		assert.Nil(t, err)
+		assert.Nil(t, ioutil.WriteFile(FIXTURE_FILE, data, 0644))
+	}
+	data, err := ioutil.ReadFile(FIXTURE_FILE)
+	assert.Nil(t, err)
+	fixtures := new(lightClientFixtures)
+	assert.Nil(t, json.Unmarshal(data, fixtures))
+	return fixtures
+}
+
+func copyUpdate(update *LightClientUpdate) *LightClientUpdate {
+	data, _ := json.Marshal(update)
+	res := new(LightClientUpdate)
+	_ = json.Unmarshal(data, res)
+	return res
+}
+
+func TestVerifyUpdate(t *testing.T) {
+	fixtures := loadFixtures(t)
+	ctx, updates := fixtures.Context, fixtures.Updates
+
+	committee, err := ctx.VerifyBootstrap(fixtures.Bootstrap)
+	assert.Nil(t, err)
+	committees := map[uint64]*SyncCommitteeKeys{10: committee}
+	getCommittee := func(period uint64) (*SyncCommitteeKeys, error) {
+		return committees[period], nil
+	}
+	store := fixtures.Bootstrap.Header.Beacon
+
+	//period 11 is unknown
+	_, err = ctx.VerifyUpdate(&store, getCommittee, updates[1])
+	assert.EqualError(t, err, "sync committee of period 11 is unknown")
+
+	//not enough participants
+	update := copyUpdate(updates[0])
+	update.SyncAggregate.SyncCommitteeBits = []byte{0xff, 0xff, 0x1f, 0x00}
+	_, err = ctx.VerifyUpdate(&store, getCommittee, update)
+	assert.EqualError(t, err, "not enough sync committee participants 21")
+
+	//signature of other participants
+	update = copyUpdate(updates[0])
+	update.SyncAggregate.SyncCommitteeBits[0] = 0xfe
+	_, err = ctx.VerifyUpdate(&store, getCommittee, update)
+	assert.EqualError(t, err, "invalid sync committee signature")
+
+	//finalized header not in attested state
+	update = copyUpdate(updates[0])
+	update.FinalizedHeader.Beacon.ProposerIndex++
+	_, err = ctx.VerifyUpdate(&store, getCommittee, update)
+	assert.EqualError(t, err, "invalid finality branch")
+	update = copyUpdate(updates[0])
+	update.FinalityBranch[0][0] ^= 1
+	_, err = ctx.VerifyUpdate(&store, getCommittee, update)
+	assert.EqualError(t, err, "invalid finality branch")
+
+	//execution payload not in finalized block
+	update = copyUpdate(updates[0])
+	update.FinalizedHeader.Execution.StateRoot[0] ^= 1
+	_, err = ctx.VerifyUpdate(&store, getCommittee, update)
+	assert.EqualError(t, err, "verify finalized header error: invalid execution branch")
+
+	next, err := ctx.VerifyUpdate(&store, getCommittee, updates[0])
+	assert.Nil(t, err)
+	assert.NotNil(t, next)
+	committees[11] = next
+	store = updates[0].FinalizedHeader.Beacon
+
+	//replay is rejected
+	_, err = ctx.VerifyUpdate(&store, getCommittee, updates[0])
+	assert.EqualError(t, err, "update is not newer than finalized slot 645")
+
+	//rotate to period 11
+	next, err = ctx.VerifyUpdate(&store, getCommittee, updates[1])
+	assert.Nil(t, err)
+	assert.NotNil(t, next)
+	committees[12] = next
+	store = updates[1].FinalizedHeader.Beacon
+
+	//signature period 10 is too old
+	_, err = ctx.VerifyUpdate(&store, getCommittee, updates[0])
+	assert.EqualError(t, err, "signature period 10 is not current or next period of store period 11")
+
+	next, err = ctx.VerifyUpdate(&store, getCommittee, updates[2])
+	assert.Nil(t, err)
+	assert.Nil(t, next)
+}
+
+func TestHashTreeRoot(t *testing.T) {
+	header := testHeader(100, nil).Execution
+	deneb, err := header.HashTreeRoot()
+	assert.Nil(t, err)
+	header.BlobGasUsed, header.ExcessBlobGas = nil, nil
+	capella, err := header.HashTreeRoot()
+	assert.Nil(t, err)
+	assert.NotEqual(t, deneb, capella)
+	header.ExtraData = make([]byte, MAX_EXTRA_DATA_LENGTH+1)
+	_, err = header.HashTreeRoot()
+	assert.NotNil(t, err)
+
+	//empty list of 32 bytes limit
+	assert.Equal(t, hashTwo(common.Hash{}, common.Hash{}), bytesListRoot(nil, 32))
+	assert.Equal(t, zeroHashes[3], merkleize(nil, 5))
+	leaves := []common.Hash{testHash(1), testHash(2), testHash(3)}
+	root := merkleize(leaves, 4)
+	for i, leaf := range leaves {
+		assert.True(t, isValidMerkleBranch(leaf, merkleBranch(append(leaves, common.Hash{}), i), uint64(4+i), root))
+	}
+	assert.False(t, isValidMerkleBranch(leaves[0], merkleBranch(append(leaves, common.Hash{}), 0), 5, root))
+}
+
+var (
+	acct        = account.NewAccount("")
+	testChainID = uint64(24)
+)
+
+func init() {
+	genesis.GenesisBookkeepers = []keypair.PublicKey{acct.PublicKey}
+}
+
+func newNative(args []byte, tx *types.Transaction, db *storage.CacheDB, ctx *Context) *native.NativeService {
+	if db == nil {
+		store, _ := leveldbstore.NewMemLevelDBStore()
+		db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+		sink := pcom.NewZeroCopySink(nil)
+		view := &node_manager.GovernanceView{TxHash: pcom.UINT256_EMPTY}
+		view.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)), states.GenRawStorageItem(sink.Bytes()))
+		peerPoolMap := &node_manager.PeerPoolMap{
+			PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+				vconfig.PubkeyID(acct.PublicKey): {
+					Address:    acct.Address,
+					Status:     node_manager.ConsensusStatus,
+					PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+				},
+			},
+		}
+		sink.Reset()
+		peerPoolMap.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+			states.GenRawStorageItem(sink.Bytes()))
+	}
+	service, _ := native.NewNativeService(db, tx, 0, 0, pcom.Uint256{0}, 0, args, false)
+	if ctx != nil {
+		extraInfo, _ := json.Marshal(ctx)
+		_ = side_chain_manager.PutSideChain(service, &side_chain_manager.SideChain{
+			ChainId:   testChainID,
+			Router:    utils.ETH_POS_ROUTER,
+			ExtraInfo: extraInfo,
+		})
+	}
+	return service
+}
+
+func TestSyncHeader(t *testing.T) {
+	fixtures := loadFixtures(t)
+	handler := NewHandler()
+
+	genesisHeader, _ := json.Marshal(fixtures.Bootstrap)
+	param := &scom.SyncGenesisHeaderParam{ChainID: testChainID, GenesisHeader: genesisHeader}
+	sink := pcom.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	service := newNative(sink.Bytes(), &types.Transaction{}, nil, fixtures.Context)
+	assert.NotNil(t, handler.SyncGenesisHeader(service))
+
+	tx := &types.Transaction{SignedAddr: []pcom.Address{acct.Address}}
+	service = newNative(sink.Bytes(), tx, service.GetCacheDB(), nil)
+	assert.Nil(t, handler.SyncGenesisHeader(service))
+	height, err := GetCurrentHeaderHeight(service, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(fixtures.Bootstrap.Header.Execution.BlockNumber), height)
+	assert.NotNil(t, handler.SyncGenesisHeader(service))
+
+	blockParam := &scom.SyncBlockHeaderParam{ChainID: testChainID}
+	for _, update := range fixtures.Updates {
+		data, _ := json.Marshal(update)
+		blockParam.Headers = append(blockParam.Headers, data)
+	}
+	sink.Reset()
+	blockParam.Serialization(sink)
+	service = newNative(sink.Bytes(), &types.Transaction{}, service.GetCacheDB(), nil)
+	assert.Nil(t, handler.SyncBlockHeader(service))
+
+	last := fixtures.Updates[len(fixtures.Updates)-1].FinalizedHeader
+	height, err = GetCurrentHeaderHeight(service, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(last.Execution.BlockNumber), height)
+	header, err := GetHeaderByHeight(service, height, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, last.Execution.StateRoot, header.StateRoot)
+	for _, update := range fixtures.Updates[:2] {
+		header, err = GetHeaderByHeight(service, uint64(update.FinalizedHeader.Execution.BlockNumber), testChainID)
+		assert.Nil(t, err)
+		assert.Equal(t, update.FinalizedHeader.Execution.BlockHash, header.BlockHash)
+	}
+	finalized, err := GetFinalizedHeader(service, testChainID)
+	assert.Nil(t, err)
+	assert.Equal(t, last.Beacon, *finalized)
+	committee, err := GetSyncCommittee(service, testChainID, 12)
+	assert.Nil(t, err)
+	assert.NotNil(t, committee)
+
+	//updates are not accepted twice
+	service = newNative(sink.Bytes(), &types.Transaction{}, service.GetCacheDB(), nil)
+	assert.NotNil(t, handler.SyncBlockHeader(service))
+}
+
+//beaconGet decodes the response of the beacon api at path into v
+func beaconGet(t *testing.T, path string, v interface{}) bool {
+	resp, err := http.Get(*recordBeacon + path)
+	if err != nil {
+		t.Fatalf("get %s error: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get %s status: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s error: %v", path, err)
+	}
+	return true
+}
+
+//TestRecordMainnetFixture records testdata/mainnet_light_client.json from the light client api of a mainnet beacon
+//node with `go test -run TestRecordMainnetFixture -record-beacon <url>`. The fixture has a bootstrap at the start
+//of the previous sync committee period, the best update of that period bringing the current committee, and the
+//latest finality update signed by the current committee.
+func TestRecordMainnetFixture(t *testing.T) {
+	if *recordBeacon == "" {
+		t.Skip("run with -record-beacon to record the mainnet fixture")
+	}
+	genesis := &struct {
+		Data struct {
+			GenesisValidatorsRoot common.Hash `json:"genesis_validators_root"`
+		} `json:"data"`
+	}{}
+	beaconGet(t, "/eth/v1/beacon/genesis", genesis)
+	schedule := &struct {
+		Data []struct {
+			CurrentVersion hexutil.Bytes `json:"current_version"`
+			Epoch          Uint64        `json:"epoch"`
+		} `json:"data"`
+	}{}
+	beaconGet(t, "/eth/v1/config/fork_schedule", schedule)
+	spec := &struct {
+		Data map[string]string `json:"data"`
+	}{}
+	beaconGet(t, "/eth/v1/config/spec", spec)
+
+	ctx := &Context{GenesisValidatorsRoot: genesis.Data.GenesisValidatorsRoot}
+	for _, fork := range schedule.Data {
+		if len(ctx.Forks) > 0 && ctx.Forks[len(ctx.Forks)-1].Epoch >= fork.Epoch {
+			continue
+		}
+		ctx.Forks = append(ctx.Forks, &Fork{Epoch: fork.Epoch, Version: fork.CurrentVersion})
+	}
+	if epoch, ok := spec.Data["ELECTRA_FORK_EPOCH"]; ok && epoch != FAR_FUTURE_FORK_EPOCH {
+		electra := new(Uint64)
+		assert.Nil(t, json.Unmarshal([]byte(`"`+epoch+`"`), electra))
+		ctx.ElectraForkEpoch = electra
+	}
+	data, err := json.Marshal(ctx)
+	assert.Nil(t, err)
+	ctx, err = DecodeContext(data)
+	assert.Nil(t, err)
+
+	finality := &struct {
+		Data *LightClientUpdate `json:"data"`
+	}{}
+	beaconGet(t, "/eth/v1/beacon/light_client/finality_update", finality)
+	period := ctx.Period(uint64(finality.Data.SignatureSlot)) - 1
+
+	//bootstrap is served for the epoch boundary blocks only
+	fixtures := &lightClientFixtures{Context: ctx}
+	slotsPerPeriod := ctx.SlotsPerEpoch * ctx.EpochsPerSyncCommitteePeriod
+	for slot := period * slotsPerPeriod; fixtures.Bootstrap == nil; slot += ctx.SlotsPerEpoch {
+		if ctx.Period(slot) != period {
+			t.Fatalf("no bootstrap in period %d", period)
+		}
+		root := &struct {
+			Data struct {
+				Root common.Hash `json:"root"`
+			} `json:"data"`
+		}{}
+		if !beaconGet(t, fmt.Sprintf("/eth/v1/beacon/blocks/%d/root", slot), root) {
+			continue
+		}
+		bootstrap := &struct {
+			Data *LightClientBootstrap `json:"data"`
+		}{}
+		if beaconGet(t, "/eth/v1/beacon/light_client/bootstrap/"+root.Data.Root.Hex(), bootstrap) {
+			fixtures.Bootstrap = bootstrap.Data
+		}
+	}
+	var updates []struct {
+		Data *LightClientUpdate `json:"data"`
+	}
+	beaconGet(t, fmt.Sprintf("/eth/v1/beacon/light_client/updates?start_period=%d&count=1", period), &updates)
+	if len(updates) != 1 {
+		t.Fatalf("no update of period %d", period)
+	}
+	fixtures.Updates = []*LightClientUpdate{updates[0].Data, finality.Data}
+
+	data, err = json.MarshalIndent(fixtures, "", "  ")
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(MAINNET_FIXTURE_FILE, data, 0644))
+}
+
+func TestVerifyMainnetUpdates(t *testing.T) {
+	data, err := ioutil.ReadFile(MAINNET_FIXTURE_FILE)
+	if os.IsNotExist(err) {
+		t.Skip("record the mainnet fixture with TestRecordMainnetFixture")
+	}
+	assert.Nil(t, err)
+	fixtures := new(lightClientFixtures)
+	assert.Nil(t, json.Unmarshal(data, fixtures))
+	ctx := fixtures.Context
+
+	committee, err := ctx.VerifyBootstrap(fixtures.Bootstrap)
+	assert.Nil(t, err)
+	store := fixtures.Bootstrap.Header.Beacon
+	committees := map[uint64]*SyncCommitteeKeys{ctx.Period(uint64(store.Slot)): committee}
+	getCommittee := func(period uint64) (*SyncCommitteeKeys, error) {
+		return committees[period], nil
+	}
+	for _, update := range fixtures.Updates {
+		next, err := ctx.VerifyUpdate(&store, getCommittee, update)
+		assert.Nil(t, err)
+		if next != nil {
+			committees[ctx.Period(uint64(update.AttestedHeader.Beacon.Slot))+1] = next
+		}
+		if update.FinalizedHeader.Beacon.Slot > store.Slot {
+			store = update.FinalizedHeader.Beacon
+		}
+	}
+	last := fixtures.Updates[len(fixtures.Updates)-1]
+	assert.Equal(t, last.FinalizedHeader.Beacon, store)
+}
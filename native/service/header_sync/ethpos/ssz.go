@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//zeroHashes[i] is the root of a merkle tree of depth i with all leaves zero
+var zeroHashes [64]common.Hash
+
+func init() {
+	for i := 1; i < len(zeroHashes); i++ {
+		zeroHashes[i] = hashTwo(zeroHashes[i-1], zeroHashes[i-1])
+	}
+}
+
+func hashTwo(a, b common.Hash) common.Hash {
+	h := sha256.New()
+	h.Write(a[:])
+	h.Write(b[:])
+	var out common.Hash
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+//merkleize computes the ssz merkle root of chunks padded with zero chunks to limit leaves
+func merkleize(chunks []common.Hash, limit int) common.Hash {
+	depth := 0
+	for 1<<uint(depth) < limit {
+		depth++
+	}
+	layer := append([]common.Hash{}, chunks...)
+	for i := 0; i < depth; i++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHashes[i])
+		}
+		next := make([]common.Hash, len(layer)/2)
+		for j := range next {
+			next[j] = hashTwo(layer[2*j], layer[2*j+1])
+		}
+		layer = next
+	}
+	if len(layer) == 0 {
+		return zeroHashes[depth]
+	}
+	return layer[0]
+}
+
+//packBytes splits bytes into zero padded 32 bytes chunks
+func packBytes(data []byte) []common.Hash {
+	chunks := make([]common.Hash, (len(data)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], data[i*32:])
+	}
+	return chunks
+}
+
+func uint64Root(v uint64) common.Hash {
+	var out common.Hash
+	binary.LittleEndian.PutUint64(out[:], v)
+	return out
+}
+
+func mixInLength(root common.Hash, length uint64) common.Hash {
+	return hashTwo(root, uint64Root(length))
+}
+
+//bytesVectorRoot is the root of ByteVector[len(data)]
+func bytesVectorRoot(data []byte) common.Hash {
+	chunks := packBytes(data)
+	return merkleize(chunks, len(chunks))
+}
+
+//bytesListRoot is the root of ByteList[limit]
+func bytesListRoot(data []byte, limit int) common.Hash {
+	return mixInLength(merkleize(packBytes(data), (limit+31)/32), uint64(len(data)))
+}
+
+//computeDomain returns the signature domain of domain type at fork version
+func computeDomain(domainType [4]byte, forkVersion [4]byte, genesisValidatorsRoot common.Hash) common.Hash {
+	var version common.Hash
+	copy(version[:], forkVersion[:])
+	forkDataRoot := hashTwo(version, genesisValidatorsRoot)
+	var domain common.Hash
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+//computeSigningRoot is the root of SigningData container
+func computeSigningRoot(objectRoot, domain common.Hash) common.Hash {
+	return hashTwo(objectRoot, domain)
+}
+
+//isValidMerkleBranch checks the leaf is at generalized index gindex of the tree with root
+func isValidMerkleBranch(leaf common.Hash, branch []common.Hash, gindex uint64, root common.Hash) bool {
+	depth := 0
+	for gindex>>uint(depth+1) > 0 {
+		depth++
+	}
+	if len(branch) != depth {
+		return false
+	}
+	value := leaf
+	for i := 0; i < depth; i++ {
+		if (gindex>>uint(i))&1 == 1 {
+			value = hashTwo(branch[i], value)
+		} else {
+			value = hashTwo(value, branch[i])
+		}
+	}
+	return value == root
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func keyForGenesisHeader(chainID uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.GENESIS_HEADER), utils.GetUint64Bytes(chainID))
+}
+
+func keyForFinalizedHeader(chainID uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.FINALIZED_BEACON_HEADER), utils.GetUint64Bytes(chainID))
+}
+
+func keyForSyncCommittee(chainID, period uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.SYNC_COMMITTEE), utils.GetUint64Bytes(chainID),
+		utils.GetUint64Bytes(period))
+}
+
+func keyForHeader(chainID, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.BLOCK_HEADER), utils.GetUint64Bytes(chainID),
+		utils.GetUint64Bytes(height))
+}
+
+func keyForHeaderHeight(chainID uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID))
+}
+
+//SyncCommitteeKeys is the verified sync committee of a period
+type SyncCommitteeKeys struct {
+	Root    ethcommon.Hash  `json:"root"`
+	PubKeys []hexutil.Bytes `json:"pubkeys"` // uncompressed public keys checked to be in G1 subgroup
+}
+
+//NewSyncCommitteeKeys decompresses and checks all public keys of the committee
+func NewSyncCommitteeKeys(committee *SyncCommittee, size uint64) (*SyncCommitteeKeys, error) {
+	root, err := committee.HashTreeRoot(size)
+	if err != nil {
+		return nil, err
+	}
+	g1 := bls12381.NewG1()
+	keys := &SyncCommitteeKeys{Root: root, PubKeys: make([]hexutil.Bytes, len(committee.PubKeys))}
+	for i, pubKey := range committee.PubKeys {
+		p, err := DecompressG1(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d error %s", i, err)
+		}
+		keys.PubKeys[i] = g1.ToBytes(p)
+	}
+	return keys, nil
+}
+
+//Participants returns the public keys of committee members set in bits
+func (k *SyncCommitteeKeys) Participants(bits []byte) ([]*bls12381.PointG1, error) {
+	if len(bits)*8 != len(k.PubKeys) {
+		return nil, fmt.Errorf("sync committee bits length %d, committee size %d", len(bits), len(k.PubKeys))
+	}
+	g1 := bls12381.NewG1()
+	pubKeys := make([]*bls12381.PointG1, 0, len(k.PubKeys))
+	for i, pubKey := range k.PubKeys {
+		if bits[i/8]>>uint(i%8)&1 == 0 {
+			continue
+		}
+		p, err := g1.FromBytes(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d error %s", i, err)
+		}
+		pubKeys = append(pubKeys, p)
+	}
+	return pubKeys, nil
+}
+
+func getStorage(native *native.NativeService, key []byte, value interface{}) (bool, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return false, fmt.Errorf("get storage error %s", err)
+	}
+	if store == nil {
+		return false, nil
+	}
+	data, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return false, fmt.Errorf("deserialize from raw storage item error %s", err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("unmarshal storage error %s", err)
+	}
+	return true, nil
+}
+
+func putStorage(native *native.NativeService, key []byte, value interface{}) {
+	data, _ := json.Marshal(value)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(data))
+}
+
+func getGenesisHeader(native *native.NativeService, chainID uint64) (*LightClientHeader, error) {
+	header := new(LightClientHeader)
+	exist, err := getStorage(native, keyForGenesisHeader(chainID), header)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return header, nil
+}
+
+func putGenesisHeader(native *native.NativeService, chainID uint64, header *LightClientHeader) {
+	putStorage(native, keyForGenesisHeader(chainID), header)
+}
+
+//GetFinalizedHeader returns the latest finalized beacon block header
+func GetFinalizedHeader(native *native.NativeService, chainID uint64) (*BeaconBlockHeader, error) {
+	header := new(BeaconBlockHeader)
+	exist, err := getStorage(native, keyForFinalizedHeader(chainID), header)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("finalized header of chain %d not found", chainID)
+	}
+	return header, nil
+}
+
+func putFinalizedHeader(native *native.NativeService, chainID uint64, header *BeaconBlockHeader) {
+	putStorage(native, keyForFinalizedHeader(chainID), header)
+}
+
+//GetSyncCommittee returns the sync committee of period, nil if it is unknown yet
+func GetSyncCommittee(native *native.NativeService, chainID, period uint64) (*SyncCommitteeKeys, error) {
+	keys := new(SyncCommitteeKeys)
+	exist, err := getStorage(native, keyForSyncCommittee(chainID, period), keys)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func putSyncCommittee(native *native.NativeService, chainID, period uint64, keys *SyncCommitteeKeys) {
+	putStorage(native, keyForSyncCommittee(chainID, period), keys)
+}
+
+//GetCurrentHeaderHeight returns the height of the latest finalized execution block
+func GetCurrentHeaderHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(keyForHeaderHeight(chainID))
+	if err != nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight error %s", err)
+	}
+	if store == nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight, current header height of chain %d not found", chainID)
+	}
+	heightBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight, deserialize from raw storage item error %s", err)
+	}
+	return utils.GetBytesUint64(heightBytes), nil
+}
+
+//GetHeaderByHeight returns the finalized execution payload header at height
+func GetHeaderByHeight(native *native.NativeService, height, chainID uint64) (*ExecutionPayloadHeader, error) {
+	header := new(ExecutionPayloadHeader)
+	exist, err := getStorage(native, keyForHeader(chainID, height), header)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHeight error %s", err)
+	}
+	if !exist {
+		return nil, fmt.Errorf("GetHeaderByHeight, header of chain %d at height %d not found", chainID, height)
+	}
+	return header, nil
+}
+
+//putHeader stores the finalized execution payload header and moves the current header height forward
+func putHeader(native *native.NativeService, chainID uint64, header *ExecutionPayloadHeader) {
+	height := uint64(header.BlockNumber)
+	putStorage(native, keyForHeader(chainID, height), header)
+	native.GetCacheDB().Put(keyForHeaderHeight(chainID), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
+	scom.NotifyPutHeader(native, chainID, height, header.BlockHash.Hex())
+}
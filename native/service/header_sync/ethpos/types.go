@@ -0,0 +1,354 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ethpos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	//generalized indices of the light client proofs, see ethereum consensus specs altair/light-client/sync-protocol.md
+	FINALIZED_ROOT_GINDEX          = uint64(105)
+	CURRENT_SYNC_COMMITTEE_GINDEX  = uint64(54)
+	NEXT_SYNC_COMMITTEE_GINDEX     = uint64(55)
+	EXECUTION_PAYLOAD_GINDEX       = uint64(25)
+	FINALIZED_ROOT_GINDEX_ELECTRA  = uint64(169)
+	CURRENT_SYNC_COMMITTEE_ELECTRA = uint64(86)
+	NEXT_SYNC_COMMITTEE_ELECTRA    = uint64(87)
+
+	LOGS_BLOOM_LENGTH     = 256
+	MAX_EXTRA_DATA_LENGTH = 32
+
+	//mainnet preset
+	DEFAULT_SYNC_COMMITTEE_SIZE              = 512
+	DEFAULT_SLOTS_PER_EPOCH                  = 32
+	DEFAULT_EPOCHS_PER_SYNC_COMMITTEE_PERIOD = 256
+)
+
+var DOMAIN_SYNC_COMMITTEE = [4]byte{0x07, 0x00, 0x00, 0x00}
+
+//Uint64 is encoded as decimal string in beacon api
+type Uint64 uint64
+
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(u), 10))
+}
+
+func (u *Uint64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Uint64 should be decimal string, error %s", err)
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*u = Uint64(v)
+	return nil
+}
+
+//Uint256 is encoded as decimal string in beacon api
+type Uint256 big.Int
+
+func (u *Uint256) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*big.Int)(u).String())
+}
+
+func (u *Uint256) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Uint256 should be decimal string, error %s", err)
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 || v.BitLen() > 256 {
+		return fmt.Errorf("invalid uint256 %s", s)
+	}
+	*u = Uint256(*v)
+	return nil
+}
+
+//Fork is the fork version of beacon chain activated at epoch
+type Fork struct {
+	Epoch   Uint64        `json:"epoch"`
+	Version hexutil.Bytes `json:"version"`
+}
+
+//Context is the beacon chain config of side chain, stored in ExtraInfo of side chain
+type Context struct {
+	GenesisValidatorsRoot        common.Hash `json:"genesis_validators_root"`
+	Forks                        []*Fork     `json:"forks"`
+	ElectraForkEpoch             *Uint64     `json:"electra_fork_epoch,omitempty"`
+	SyncCommitteeSize            uint64      `json:"sync_committee_size,omitempty"`
+	SlotsPerEpoch                uint64      `json:"slots_per_epoch,omitempty"`
+	EpochsPerSyncCommitteePeriod uint64      `json:"epochs_per_sync_committee_period,omitempty"`
+}
+
+//DecodeContext decodes the side chain context and fills the mainnet preset for missing values
+func DecodeContext(data []byte) (*Context, error) {
+	ctx := new(Context)
+	if err := json.Unmarshal(data, ctx); err != nil {
+		return nil, fmt.Errorf("unmarshal context error %s", err)
+	}
+	if len(ctx.Forks) == 0 {
+		return nil, fmt.Errorf("no fork in context")
+	}
+	for i, fork := range ctx.Forks {
+		if len(fork.Version) != 4 {
+			return nil, fmt.Errorf("invalid version of fork %d", i)
+		}
+		if i > 0 && fork.Epoch <= ctx.Forks[i-1].Epoch {
+			return nil, fmt.Errorf("forks should be in ascending epoch order")
+		}
+	}
+	if ctx.SyncCommitteeSize == 0 {
+		ctx.SyncCommitteeSize = DEFAULT_SYNC_COMMITTEE_SIZE
+	}
+	if ctx.SlotsPerEpoch == 0 {
+		ctx.SlotsPerEpoch = DEFAULT_SLOTS_PER_EPOCH
+	}
+	if ctx.EpochsPerSyncCommitteePeriod == 0 {
+		ctx.EpochsPerSyncCommitteePeriod = DEFAULT_EPOCHS_PER_SYNC_COMMITTEE_PERIOD
+	}
+	return ctx, nil
+}
+
+func (ctx *Context) epoch(slot uint64) uint64 {
+	return slot / ctx.SlotsPerEpoch
+}
+
+//Period returns the sync committee period of slot
+func (ctx *Context) Period(slot uint64) uint64 {
+	return ctx.epoch(slot) / ctx.EpochsPerSyncCommitteePeriod
+}
+
+func (ctx *Context) forkVersion(epoch uint64) [4]byte {
+	var version [4]byte
+	for _, fork := range ctx.Forks {
+		if uint64(fork.Epoch) > epoch {
+			break
+		}
+		copy(version[:], fork.Version)
+	}
+	return version
+}
+
+func (ctx *Context) isElectra(slot uint64) bool {
+	return ctx.ElectraForkEpoch != nil && ctx.epoch(slot) >= uint64(*ctx.ElectraForkEpoch)
+}
+
+func (ctx *Context) finalizedRootGindex(slot uint64) uint64 {
+	if ctx.isElectra(slot) {
+		return FINALIZED_ROOT_GINDEX_ELECTRA
+	}
+	return FINALIZED_ROOT_GINDEX
+}
+
+func (ctx *Context) currentSyncCommitteeGindex(slot uint64) uint64 {
+	if ctx.isElectra(slot) {
+		return CURRENT_SYNC_COMMITTEE_ELECTRA
+	}
+	return CURRENT_SYNC_COMMITTEE_GINDEX
+}
+
+func (ctx *Context) nextSyncCommitteeGindex(slot uint64) uint64 {
+	if ctx.isElectra(slot) {
+		return NEXT_SYNC_COMMITTEE_ELECTRA
+	}
+	return NEXT_SYNC_COMMITTEE_GINDEX
+}
+
+type BeaconBlockHeader struct {
+	Slot          Uint64      `json:"slot"`
+	ProposerIndex Uint64      `json:"proposer_index"`
+	ParentRoot    common.Hash `json:"parent_root"`
+	StateRoot     common.Hash `json:"state_root"`
+	BodyRoot      common.Hash `json:"body_root"`
+}
+
+func (h *BeaconBlockHeader) HashTreeRoot() common.Hash {
+	return merkleize([]common.Hash{
+		uint64Root(uint64(h.Slot)),
+		uint64Root(uint64(h.ProposerIndex)),
+		h.ParentRoot,
+		h.StateRoot,
+		h.BodyRoot,
+	}, 5)
+}
+
+//ExecutionPayloadHeader of capella, blob gas fields are set since deneb
+type ExecutionPayloadHeader struct {
+	ParentHash       common.Hash    `json:"parent_hash"`
+	FeeRecipient     common.Address `json:"fee_recipient"`
+	StateRoot        common.Hash    `json:"state_root"`
+	ReceiptsRoot     common.Hash    `json:"receipts_root"`
+	LogsBloom        hexutil.Bytes  `json:"logs_bloom"`
+	PrevRandao       common.Hash    `json:"prev_randao"`
+	BlockNumber      Uint64         `json:"block_number"`
+	GasLimit         Uint64         `json:"gas_limit"`
+	GasUsed          Uint64         `json:"gas_used"`
+	Timestamp        Uint64         `json:"timestamp"`
+	ExtraData        hexutil.Bytes  `json:"extra_data"`
+	BaseFeePerGas    *Uint256       `json:"base_fee_per_gas"`
+	BlockHash        common.Hash    `json:"block_hash"`
+	TransactionsRoot common.Hash    `json:"transactions_root"`
+	WithdrawalsRoot  common.Hash    `json:"withdrawals_root"`
+	BlobGasUsed      *Uint64        `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas    *Uint64        `json:"excess_blob_gas,omitempty"`
+}
+
+func (h *ExecutionPayloadHeader) HashTreeRoot() (common.Hash, error) {
+	if len(h.LogsBloom) != LOGS_BLOOM_LENGTH {
+		return common.Hash{}, fmt.Errorf("invalid logs bloom length %d", len(h.LogsBloom))
+	}
+	if len(h.ExtraData) > MAX_EXTRA_DATA_LENGTH {
+		return common.Hash{}, fmt.Errorf("invalid extra data length %d", len(h.ExtraData))
+	}
+	if h.BaseFeePerGas == nil {
+		return common.Hash{}, fmt.Errorf("base fee per gas is missing")
+	}
+	if (h.BlobGasUsed == nil) != (h.ExcessBlobGas == nil) {
+		return common.Hash{}, fmt.Errorf("incomplete blob gas fields")
+	}
+	var feeRecipient, baseFee common.Hash
+	copy(feeRecipient[:], h.FeeRecipient[:])
+	//uint256 is little endian in ssz
+	be := (*big.Int)(h.BaseFeePerGas).Bytes()
+	for i, b := range be {
+		baseFee[len(be)-1-i] = b
+	}
+	fields := []common.Hash{
+		h.ParentHash,
+		feeRecipient,
+		h.StateRoot,
+		h.ReceiptsRoot,
+		bytesVectorRoot(h.LogsBloom),
+		h.PrevRandao,
+		uint64Root(uint64(h.BlockNumber)),
+		uint64Root(uint64(h.GasLimit)),
+		uint64Root(uint64(h.GasUsed)),
+		uint64Root(uint64(h.Timestamp)),
+		bytesListRoot(h.ExtraData, MAX_EXTRA_DATA_LENGTH),
+		baseFee,
+		h.BlockHash,
+		h.TransactionsRoot,
+		h.WithdrawalsRoot,
+	}
+	if h.BlobGasUsed != nil {
+		fields = append(fields, uint64Root(uint64(*h.BlobGasUsed)), uint64Root(uint64(*h.ExcessBlobGas)))
+	}
+	return merkleize(fields, len(fields)), nil
+}
+
+type LightClientHeader struct {
+	Beacon          BeaconBlockHeader       `json:"beacon"`
+	Execution       *ExecutionPayloadHeader `json:"execution"`
+	ExecutionBranch []common.Hash           `json:"execution_branch"`
+}
+
+//Verify checks the execution payload header is in the beacon block body
+func (h *LightClientHeader) Verify() error {
+	if h.Execution == nil {
+		return fmt.Errorf("execution payload header is missing")
+	}
+	root, err := h.Execution.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("execution payload header root error %s", err)
+	}
+	if !isValidMerkleBranch(root, h.ExecutionBranch, EXECUTION_PAYLOAD_GINDEX, h.Beacon.BodyRoot) {
+		return fmt.Errorf("invalid execution branch")
+	}
+	return nil
+}
+
+type SyncCommittee struct {
+	PubKeys         []hexutil.Bytes `json:"pubkeys"`
+	AggregatePubKey hexutil.Bytes   `json:"aggregate_pubkey"`
+}
+
+func pubKeyRoot(pubKey []byte) (common.Hash, error) {
+	if len(pubKey) != BLS_PUBKEY_LENGTH {
+		return common.Hash{}, fmt.Errorf("invalid public key length %d", len(pubKey))
+	}
+	return bytesVectorRoot(pubKey), nil
+}
+
+func (c *SyncCommittee) HashTreeRoot(size uint64) (common.Hash, error) {
+	if uint64(len(c.PubKeys)) != size {
+		return common.Hash{}, fmt.Errorf("sync committee size %d, expect %d", len(c.PubKeys), size)
+	}
+	roots := make([]common.Hash, len(c.PubKeys))
+	for i, pubKey := range c.PubKeys {
+		root, err := pubKeyRoot(pubKey)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("public key %d error %s", i, err)
+		}
+		roots[i] = root
+	}
+	aggRoot, err := pubKeyRoot(c.AggregatePubKey)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("aggregate public key error %s", err)
+	}
+	return hashTwo(merkleize(roots, len(roots)), aggRoot), nil
+}
+
+type SyncAggregate struct {
+	SyncCommitteeBits      hexutil.Bytes `json:"sync_committee_bits"`
+	SyncCommitteeSignature hexutil.Bytes `json:"sync_committee_signature"`
+}
+
+//LightClientBootstrap is the trusted checkpoint to start light client sync
+type LightClientBootstrap struct {
+	Header                     LightClientHeader `json:"header"`
+	CurrentSyncCommittee       *SyncCommittee    `json:"current_sync_committee"`
+	CurrentSyncCommitteeBranch []common.Hash     `json:"current_sync_committee_branch"`
+}
+
+type LightClientUpdate struct {
+	AttestedHeader          LightClientHeader  `json:"attested_header"`
+	NextSyncCommittee       *SyncCommittee     `json:"next_sync_committee"`
+	NextSyncCommitteeBranch []common.Hash      `json:"next_sync_committee_branch"`
+	FinalizedHeader         *LightClientHeader `json:"finalized_header"`
+	FinalityBranch          []common.Hash      `json:"finality_branch"`
+	SyncAggregate           SyncAggregate      `json:"sync_aggregate"`
+	SignatureSlot           Uint64             `json:"signature_slot"`
+}
+
+func isEmptyBranch(branch []common.Hash) bool {
+	for _, node := range branch {
+		if node != (common.Hash{}) {
+			return false
+		}
+	}
+	return true
+}
+
+//IsSyncCommitteeUpdate returns whether the update carries the next sync committee
+func (u *LightClientUpdate) IsSyncCommitteeUpdate() bool {
+	return u.NextSyncCommittee != nil && !isEmptyBranch(u.NextSyncCommitteeBranch)
+}
+
+//IsFinalityUpdate returns whether the update carries the finalized header
+func (u *LightClientUpdate) IsFinalityUpdate() bool {
+	return u.FinalizedHeader != nil && !isEmptyBranch(u.FinalityBranch)
+}
@@ -31,6 +31,14 @@ import (
 // Harmony Header Sync Handler
 type Handler struct {}
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:      utils.HARMONY_ROUTER,
+		NewHandler:  func() scom.HeaderSyncHandler { return NewHandler() },
+		StartBlocks: utils.HardForkRouterStartBlocks,
+	})
+}
+
 func NewHandler() *Handler {
 	return new(Handler)
 }
@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"time"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -48,6 +47,14 @@ var TestFlagNoCheckHecoHeaderSig bool
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.HECO_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewHecoHandler() },
+		Prunable:   true,
+	})
+}
+
 // NewHandler ...
 func NewHecoHandler() *Handler {
 	return &Handler{}
@@ -358,6 +365,9 @@ func GetCanonicalHeight(native *native.NativeService, chainID uint64) (height ui
 
 // GetCanonicalHeader ...
 func GetCanonicalHeader(native *native.NativeService, chainID uint64, height uint64) (headerWithSum *HeaderWithDifficultySum, err error) {
+	if err = scom.CheckHeaderRetained(native, chainID, height); err != nil {
+		return
+	}
 	hash, err := getCanonicalHash(native, chainID, height)
 	if err != nil {
 		return
@@ -410,6 +420,7 @@ func putHeaderWithSum(native *native.NativeService, chainID uint64, headerWithSu
 	native.GetCacheDB().Put(
 		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), headerWithSum.Header.Hash().Bytes()),
 		cstates.GenRawStorageItem(headerBytes))
+	err = scom.PutHeaderHash(native, chainID, headerWithSum.Header.Number.Uint64(), headerWithSum.Header.Hash().Bytes())
 	return
 }
 
@@ -671,7 +682,7 @@ var (
 func verifyHeader(native *native.NativeService, header *eth.Header, ctx *Context) (signer ecommon.Address, err error) {
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(native.Now().Unix()) {
 		err = errors.New("block in the future")
 		return
 	}
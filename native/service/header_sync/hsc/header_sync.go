@@ -22,7 +22,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"time"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -47,6 +46,14 @@ var TestFlagNoCheckHscHeaderSig bool
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:      utils.HSC_ROUTER,
+		NewHandler:  func() scom.HeaderSyncHandler { return NewHscHandler() },
+		StartBlocks: utils.HardForkRouterStartBlocks,
+	})
+}
+
 // NewHandler ...
 func NewHscHandler() *Handler {
 	return &Handler{}
@@ -666,7 +673,7 @@ var (
 func verifyHeader(native *native.NativeService, header *eth.Header, ctx *Context) (signer ecommon.Address, err error) {
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(native.Now().Unix()) {
 		err = errors.New("block in the future")
 		return
 	}
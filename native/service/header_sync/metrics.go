@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package header_sync
+
+import (
+	"strconv"
+
+	"github.com/polynetwork/poly/common/metrics"
+	"github.com/polynetwork/poly/native"
+)
+
+var headerSyncCounter = metrics.NewCounterVec("poly_header_sync_total",
+	"Count of header sync transactions handled by the router of side chain", "method", "chain", "router", "result")
+
+func init() {
+	metrics.DefaultRegistry.MustRegister(headerSyncCounter)
+}
+
+//observeHeaderSync counts the result of a header sync method, pre execution is not counted
+func observeHeaderSync(native *native.NativeService, method string, chainID, router uint64, err error) {
+	if native.IsPreExec() {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	headerSyncCounter.WithLabelValues(method, strconv.FormatUint(chainID, 10), strconv.FormatUint(router, 10), result).Inc()
+}
@@ -22,7 +22,6 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"time"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -43,6 +42,14 @@ import (
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.MSC_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewHandler() },
+		Prunable:   true,
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
@@ -290,6 +297,9 @@ func GetCanonicalHeight(native *native.NativeService, chainID uint64) (height ui
 
 // GetCanonicalHeader ...
 func GetCanonicalHeader(native *native.NativeService, chainID uint64, height uint64) (headerWithSum *HeaderWithDifficultySum, err error) {
+	if err = scom.CheckHeaderRetained(native, chainID, height); err != nil {
+		return
+	}
 	hash, err := getCanonicalHash(native, chainID, height)
 	if err != nil {
 		return
@@ -342,6 +352,7 @@ func putHeaderWithSum(native *native.NativeService, chainID uint64, headerWithSu
 	native.GetCacheDB().Put(
 		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), headerWithSum.Header.Hash().Bytes()),
 		cstates.GenRawStorageItem(headerBytes))
+	err = scom.PutHeaderHash(native, chainID, headerWithSum.Header.Number.Uint64(), headerWithSum.Header.Hash().Bytes())
 	return
 }
 
@@ -645,7 +656,7 @@ func verifyHeader(native *native.NativeService, header *types.Header, ctx *Conte
 	number := header.Number.Uint64()
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(native.Now().Unix()) {
 		err = errFutureBlock
 		return
 	}
@@ -30,6 +30,13 @@ import (
 type NEOHandler struct {
 }
 
+func init() {
+	hscommon.RegisterHandler(&hscommon.HandlerInfo{
+		Router:     utils.NEO_ROUTER,
+		NewHandler: func() hscommon.HeaderSyncHandler { return NewNEOHandler() },
+	})
+}
+
 func NewNEOHandler() *NEOHandler {
 	return &NEOHandler{}
 }
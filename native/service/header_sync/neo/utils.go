@@ -104,5 +104,6 @@ func putConsensusValByChainId(native *native.NativeService, neoConsensus *NeoCon
 	neoConsensus.Serialization(sink)
 	chainIDBytes := utils.GetUint64Bytes(neoConsensus.ChainID)
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(hscommon.CONSENSUS_PEER), chainIDBytes), cstates.GenRawStorageItem(sink.Bytes()))
+	native.AddStoredHeader()
 	return nil
 }
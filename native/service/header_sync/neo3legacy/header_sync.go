@@ -32,6 +32,13 @@ import (
 type Neo3Handler struct {
 }
 
+func init() {
+	hscommon.RegisterHandler(&hscommon.HandlerInfo{
+		Router:     utils.NEO3_LEGACY_ROUTER,
+		NewHandler: func() hscommon.HeaderSyncHandler { return NewNeo3Handler() },
+	})
+}
+
 func NewNeo3Handler() *Neo3Handler {
 	return &Neo3Handler{}
 }
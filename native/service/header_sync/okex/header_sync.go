@@ -42,6 +42,13 @@ import (
 type Handler struct {
 }
 
+func init() {
+	hscommon.RegisterHandler(&hscommon.HandlerInfo{
+		Router:     utils.OKEX_ROUTER,
+		NewHandler: func() hscommon.HeaderSyncHandler { return NewHandler() },
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
@@ -166,6 +173,7 @@ func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *Cos
 	service.GetCacheDB().Put(
 		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), utils.GetUint64Bytes(chainId)),
 		cstates.GenRawStorageItem(sink.Bytes()))
+	service.AddStoredHeader()
 	notifyEpochSwitchInfo(service, chainId, info)
 }
 
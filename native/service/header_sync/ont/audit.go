@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ont
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	otypes "github.com/ontio/ontology/core/types"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//auditHeaders check every indexed header against the consensus peers of its key height, and the consensus peers
+//stored by UpdateConsensusPeer against the chain config of key headers. Only key headers are synced for ont,
+//so parent links are checked for headers of adjacent heights only
+func auditHeaders(native *native.NativeService, audit *hscommon.HeaderAudit) error {
+	contract := utils.HeaderSyncContractAddress
+	chainID := audit.ChainID
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	currentStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(hscommon.CURRENT_HEADER_HEIGHT), chainIDBytes))
+	if err != nil {
+		return fmt.Errorf("auditHeaders, get current header height error: %v", err)
+	}
+	if currentStore == nil {
+		return fmt.Errorf("auditHeaders, can not find current header height")
+	}
+	currentBytes, err := cstates.GetValueFromRawStorageItem(currentStore)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, deserialize current header height from raw storage item err:%v", err)
+	}
+	audit.CurrentHeight = uint64(utils.GetBytesUint32(currentBytes))
+
+	indexPrefix := utils.ConcatKey(contract, []byte(hscommon.HEADER_INDEX), chainIDBytes)
+	heights := make([]uint32, 0)
+	iter := native.GetCacheDB().NewIterator(indexPrefix)
+	for has := iter.First(); has; has = iter.Next() {
+		heights = append(heights, utils.GetBytesUint32(iter.Key()[len(indexPrefix):]))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("auditHeaders, iterate header index error: %v", err)
+	}
+	if len(heights) == 0 {
+		return fmt.Errorf("auditHeaders, can not find any header index records")
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	audit.GenesisHeight = uint64(heights[0])
+
+	keyHeights, err := GetKeyHeights(native, chainID)
+	if err != nil {
+		return fmt.Errorf("auditHeaders, GetKeyHeights error: %v", err)
+	}
+	isKeyHeight := make(map[uint32]bool)
+	for _, height := range keyHeights.HeightList {
+		isKeyHeight[height] = true
+	}
+
+	indexed := make(map[common.Uint256]bool)
+	headers := make(map[uint32]*otypes.Header)
+	for _, height := range heights {
+		header, err := GetHeaderByHeight(native, chainID, height)
+		if err != nil {
+			audit.AddIssue(uint64(height), "", "header of index is not stored: %v", err)
+			continue
+		}
+		hash := common.Uint256(header.Hash())
+		indexed[hash] = true
+		headers[height] = header
+		auditHeader := &hscommon.AuditHeader{
+			Height:     uint64(height),
+			Hash:       hash.ToHexString(),
+			ParentHash: header.PrevBlockHash.ToHexString(),
+		}
+		audit.MainChain = append(audit.MainChain, auditHeader)
+		if header.Height != height {
+			audit.AddIssue(uint64(height), hash.ToHexString(), "stored header has height %d", header.Height)
+		}
+		if parent, present := headers[height-1]; present && height > 0 {
+			if parentHash := parent.Hash(); parentHash != header.PrevBlockHash {
+				audit.AddIssue(uint64(height), hash.ToHexString(), "parent %s is not the indexed header %s",
+					header.PrevBlockHash.ToHexString(), parentHash.ToHexString())
+			}
+		}
+		if height != heights[0] {
+			if err := verifyHeader(native, chainID, header); err != nil {
+				audit.AddIssue(uint64(height), hash.ToHexString(), "header is not signed by the consensus peers: %v", err)
+			}
+		}
+
+		blkInfo := &vconfig.VbftBlockInfo{}
+		if err := json.Unmarshal(header.ConsensusPayload, blkInfo); err != nil {
+			audit.AddIssue(uint64(height), hash.ToHexString(), "unmarshal blockInfo error: %v", err)
+			continue
+		}
+		if blkInfo.NewChainConfig == nil {
+			if isKeyHeight[height] {
+				audit.AddIssue(uint64(height), hash.ToHexString(), "key height header doesn't change consensus peers")
+			}
+			continue
+		}
+		for _, p := range blkInfo.NewChainConfig.Peers {
+			auditHeader.Validators = append(auditHeader.Validators, p.ID)
+		}
+		sort.Strings(auditHeader.Validators)
+		if !isKeyHeight[height] {
+			audit.AddIssue(uint64(height), hash.ToHexString(), "height of header changing consensus peers is not a key height")
+		}
+		consensusPeers, err := getConsensusPeersByHeight(native, chainID, height)
+		if err != nil {
+			audit.AddIssue(uint64(height), hash.ToHexString(), "consensus peers are not stored: %v", err)
+			continue
+		}
+		if !samePeers(consensusPeers, auditHeader.Validators) {
+			audit.AddIssue(uint64(height), hash.ToHexString(), "stored consensus peers don't match the chain config of header")
+		}
+	}
+	for _, height := range keyHeights.HeightList {
+		if _, present := headers[height]; !present {
+			audit.AddIssue(uint64(height), "", "header of key height is not stored")
+		}
+	}
+
+	iter = native.GetCacheDB().NewIterator(utils.ConcatKey(contract, []byte(hscommon.BLOCK_HEADER), chainIDBytes))
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		hash, err := common.Uint256ParseFromBytes(iter.Key()[len(iter.Key())-common.UINT256_SIZE:])
+		if err != nil {
+			return fmt.Errorf("auditHeaders, decode header hash error: %v", err)
+		}
+		if indexed[hash] {
+			continue
+		}
+		header, err := GetHeaderByHash(native, chainID, hash)
+		if err != nil {
+			audit.AddIssue(0, hash.ToHexString(), "deserialize orphan header error: %v", err)
+			continue
+		}
+		audit.Orphans = append(audit.Orphans, &hscommon.AuditHeader{
+			Height:     uint64(header.Height),
+			Hash:       hash.ToHexString(),
+			ParentHash: header.PrevBlockHash.ToHexString(),
+		})
+	}
+	return iter.Error()
+}
+
+func samePeers(consensusPeers *ConsensusPeers, ids []string) bool {
+	if len(consensusPeers.PeerMap) != len(ids) {
+		return false
+	}
+	for _, id := range ids {
+		if _, present := consensusPeers.PeerMap[id]; !present {
+			return false
+		}
+	}
+	return true
+}
@@ -33,6 +33,14 @@ import (
 type ONTHandler struct {
 }
 
+func init() {
+	hscommon.RegisterHandler(&hscommon.HandlerInfo{
+		Router:     utils.ONT_ROUTER,
+		NewHandler: func() hscommon.HeaderSyncHandler { return NewONTHandler() },
+		Audit:      auditHeaders,
+	})
+}
+
 func NewONTHandler() *ONTHandler {
 	return &ONTHandler{}
 }
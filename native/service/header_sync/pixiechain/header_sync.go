@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"time"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -44,6 +43,13 @@ import (
 // only for testing purpose to check if Pixie Chain can be normal back after fork happens
 var TestFlagNoCheckPixieHeaderSig bool
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.PIXIECHAIN_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewPixieHandler() },
+	})
+}
+
 // NewPixieHandler ...
 func NewPixieHandler() *Handler {
 	return &Handler{}
@@ -611,7 +617,7 @@ func getHeader(native *native.NativeService, hash ecommon.Hash, chainID uint64)
 
 func verifyHeader(native *native.NativeService, header *eth.Header, ctx *Context) (signer ecommon.Address, err error) {
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(native.Now().Unix()) {
 		err = errors.New("block in the future")
 		return
 	}
@@ -25,7 +25,6 @@ import (
 	"io"
 	"math/big"
 	"sort"
-	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	ecommon "github.com/ethereum/go-ethereum/common"
@@ -51,6 +50,14 @@ import (
 type BorHandler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.POLYGON_BOR_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewBorHandler() },
+		Prunable:   true,
+	})
+}
+
 // NewHandler ...
 func NewBorHandler() *BorHandler {
 	return &BorHandler{}
@@ -303,6 +310,9 @@ func GetCanonicalHeight(native *native.NativeService, chainID uint64) (height ui
 
 // GetCanonicalHeader ...
 func GetCanonicalHeader(native *native.NativeService, chainID uint64, height uint64) (headerWithSum *HeaderWithDifficultySum, err error) {
+	if err = scom.CheckHeaderRetained(native, chainID, height); err != nil {
+		return
+	}
 	hash, err := getCanonicalHash(native, chainID, height)
 	if err != nil {
 		return
@@ -355,6 +365,7 @@ func putHeaderWithSum(native *native.NativeService, chainID uint64, headerWithSu
 	native.GetCacheDB().Put(
 		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), headerWithSum.HeaderWithOptionalSnap.Header.Hash().Bytes()),
 		cstates.GenRawStorageItem(headerBytes))
+	err = scom.PutHeaderHash(native, chainID, headerWithSum.HeaderWithOptionalSnap.Header.Number.Uint64(), headerWithSum.HeaderWithOptionalSnap.Header.Hash().Bytes())
 	return
 }
 
@@ -486,7 +497,7 @@ func verifyHeader(native *native.NativeService, headerWOP *HeaderWithOptionalPro
 	}
 	number := header.Number.Uint64()
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(native.Now().Unix()) {
 		err = errors.New("block in the future")
 		return
 	}
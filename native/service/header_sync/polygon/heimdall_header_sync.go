@@ -39,6 +39,13 @@ import (
 type HeimdallHandler struct {
 }
 
+func init() {
+	hscommon.RegisterHandler(&hscommon.HandlerInfo{
+		Router:     utils.POLYGON_HEIMDALL_ROUTER,
+		NewHandler: func() hscommon.HeaderSyncHandler { return NewHeimdallHandler() },
+	})
+}
+
 // NewHeimdallHandler ...
 func NewHeimdallHandler() *HeimdallHandler {
 	return &HeimdallHandler{}
@@ -155,6 +162,7 @@ func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *Cos
 	service.GetCacheDB().Put(
 		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), utils.GetUint64Bytes(chainId)),
 		cstates.GenRawStorageItem(sink.Bytes()))
+	service.AddStoredHeader()
 	notifyEpochSwitchInfo(service, chainId, info)
 }
 
@@ -30,6 +30,13 @@ import (
 
 type QuorumHandler struct{}
 
+func init() {
+	common.RegisterHandler(&common.HandlerInfo{
+		Router:     utils.QUORUM_ROUTER,
+		NewHandler: func() common.HeaderSyncHandler { return NewQuorumHandler() },
+	})
+}
+
 func NewQuorumHandler() *QuorumHandler {
 	return &QuorumHandler{}
 }
@@ -41,6 +41,7 @@ func putValSet(ns *native.NativeService, chainID, height uint64, vals []ecom.Add
 	ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER), rawChainID), states.GenRawStorageItem(sink.Bytes()))
 	ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER_BLOCK_HEIGHT), rawChainID),
 		states.GenRawStorageItem(rawHeight))
+	ns.AddStoredHeader()
 }
 
 func GetValSet(ns *native.NativeService, chainID uint64) (QuorumValSet, error) {
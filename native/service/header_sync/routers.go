@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package header_sync
+
+// Chain packages register their handlers in init, a new chain only needs to be imported here
+import (
+	_ "github.com/polynetwork/poly/native/service/header_sync/bsc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/btc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/bytom"
+	_ "github.com/polynetwork/poly/native/service/header_sync/cometbft"
+	_ "github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	_ "github.com/polynetwork/poly/native/service/header_sync/eth"
+	_ "github.com/polynetwork/poly/native/service/header_sync/ethpos"
+	_ "github.com/polynetwork/poly/native/service/header_sync/harmony"
+	_ "github.com/polynetwork/poly/native/service/header_sync/heco"
+	_ "github.com/polynetwork/poly/native/service/header_sync/hsc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/msc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/neo"
+	_ "github.com/polynetwork/poly/native/service/header_sync/neo3"
+	_ "github.com/polynetwork/poly/native/service/header_sync/neo3legacy"
+	_ "github.com/polynetwork/poly/native/service/header_sync/okex"
+	_ "github.com/polynetwork/poly/native/service/header_sync/ont"
+	_ "github.com/polynetwork/poly/native/service/header_sync/pixiechain"
+	_ "github.com/polynetwork/poly/native/service/header_sync/polygon"
+	_ "github.com/polynetwork/poly/native/service/header_sync/quorum"
+	_ "github.com/polynetwork/poly/native/service/header_sync/starcoin"
+	_ "github.com/polynetwork/poly/native/service/header_sync/zilliqa"
+	_ "github.com/polynetwork/poly/native/service/header_sync/zilliqalegacy"
+)
@@ -25,7 +25,6 @@ import (
 	"fmt"
 	"github.com/matthewhartstonge/argon2"
 	"math/big"
-	"time"
 
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
@@ -59,6 +58,13 @@ func init() {
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.STARCOIN_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewSTCHandler() },
+	})
+}
+
 // NewSTCHandler ...
 func NewSTCHandler() *Handler {
 	return &Handler{}
@@ -190,8 +196,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return errors.Errorf("SyncBlockHeader, SyncBlockHeader extra-data too long: %d > %d, header: %s", len(header.BlockHeader.Extra), params.MaximumExtraDataSize, string(v))
 		}
 		//verify current time validity
-		if header.BlockHeader.Timestamp > uint64(time.Now().Add(allowedFutureBlockTime).Unix()*1000) {
-			return errors.Errorf("SyncBlockHeader,  verify header time error: checktime: %d, header: %s", time.Now().Add(allowedFutureBlockTime).Unix(), string(v))
+		if header.BlockHeader.Timestamp > uint64(native.Now().Add(allowedFutureBlockTime).Unix()*1000) {
+			return errors.Errorf("SyncBlockHeader,  verify header time error: checktime: %d, header: %s", native.Now().Add(allowedFutureBlockTime).Unix(), string(v))
 		}
 		//verify whether current header time and prevent header time validity
 		if header.BlockHeader.Timestamp <= parentHeader.BlockHeader.Timestamp {
@@ -247,7 +247,7 @@ func appendHeader2Main(native *native.NativeService, height uint64, txhash types
 		states.GenRawStorageItem(txhash))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), states.GenRawStorageItem(utils.GetUint64Bytes(height)))
-	scom.NotifyPutHeader(native, chainID, height, stc.BytesToHexString(txhash))
+	scom.NotifyAppendHeader(native, chainID, height, stc.BytesToHexString(txhash))
 	return nil
 }
 
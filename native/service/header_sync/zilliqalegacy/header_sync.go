@@ -36,6 +36,13 @@ import (
 type Handler struct {
 }
 
+func init() {
+	scom.RegisterHandler(&scom.HandlerInfo{
+		Router:     utils.ZILLIQA_LEGACY_ROUTER,
+		NewHandler: func() scom.HeaderSyncHandler { return NewHandler() },
+	})
+}
+
 // NewHandler ...
 func NewHandler() *Handler {
 	return &Handler{}
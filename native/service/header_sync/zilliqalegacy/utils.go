@@ -111,7 +111,7 @@ func AppendHeader2Main(native *native.NativeService, height uint64, txHash []byt
 		cstates.GenRawStorageItem(txHash))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
-	scom.NotifyPutHeader(native, chainID, height, util.EncodeHex(txHash))
+	scom.NotifyAppendHeader(native, chainID, height, util.EncodeHex(txHash))
 	return nil
 }
 
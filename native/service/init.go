@@ -23,6 +23,8 @@ import (
 	"github.com/polynetwork/poly/native/service/cross_chain_manager"
 	"github.com/polynetwork/poly/native/service/governance/neo3_state_manager"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/proposal_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_incentive"
 	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	"github.com/polynetwork/poly/native/service/governance/replenish"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
@@ -40,6 +42,11 @@ func init() {
 	native.Contracts[utils.Neo3StateManagerContractAddress] = neo3_state_manager.RegisterStateValidatorManagerContract
 	native.Contracts[utils.SignatureManagerContractAddress] = signature_manager.RegisterSignatureManagerContract
 	native.Contracts[utils.ReplenishContractAddress] = replenish.RegisterReplenishContract
+	native.Contracts[utils.RelayerIncentiveContractAddress] = relayer_incentive.RegisterRelayerIncentiveContract
+	native.Contracts[utils.ProposalManagerContractAddress] = proposal_manager.RegisterProposalManagerContract
+
+	native.GasFeeContract = utils.NodeManagerContractAddress
+	native.GasFeeCharger = node_manager.ChargeGasFee
 
 	config.EXTRA_INFO_HEIGHT_FORK_CHECK = true
 }
@@ -19,6 +19,8 @@ package utils
 
 import (
 	"fmt"
+	"math"
+
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 )
@@ -44,6 +46,8 @@ var (
 	Neo3StateManagerContractAddress, _  = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07})
 	SignatureManagerContractAddress, _  = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08})
 	ReplenishContractAddress, _         = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09})
+	RelayerIncentiveContractAddress, _  = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a})
+	ProposalManagerContractAddress, _   = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0b})
 
 	VOTE_ROUTER             = uint64(0)
 	BTC_ROUTER              = uint64(1)
@@ -68,19 +72,33 @@ var (
 	HARMONY_ROUTER          = uint64(21)
 	BYTOM_ROUTER            = uint64(22)
 	RIPPLE_ROUTER           = uint64(23)
+	ETH_POS_ROUTER          = uint64(24)
+	COMETBFT_ROUTER         = uint64(25)
 )
 
-//Check router StartBlock to prevent hard forks
-func CheckRouterStartBlock(router uint64, block uint32) (err error) {
-	var startBLock uint32
-	switch router {
-	case HARMONY_ROUTER, HSC_ROUTER, BYTOM_ROUTER:
-		if config.DefConfig.P2PNode.NetworkId == config.NETWORK_ID_MAIN_NET {
-			startBLock = 18823000
-		}
-	}
-	if startBLock > 0 && block < startBLock {
+//RouterStartBlocks maps network id to the first block height a router is supported at, to prevent hard forks
+type RouterStartBlocks map[uint32]uint32
+
+//routers added by the mainnet hard fork at block 18823000
+var HardForkRouterStartBlocks = RouterStartBlocks{config.NETWORK_ID_MAIN_NET: 18823000}
+
+//ethereum pos router is not scheduled on mainnet and testnet yet
+var EthPosRouterStartBlocks = RouterStartBlocks{
+	config.NETWORK_ID_MAIN_NET: math.MaxUint32,
+	config.NETWORK_ID_TEST_NET: math.MaxUint32,
+}
+
+//cometbft router is not scheduled on mainnet and testnet yet
+var CometBFTRouterStartBlocks = RouterStartBlocks{
+	config.NETWORK_ID_MAIN_NET: math.MaxUint32,
+	config.NETWORK_ID_TEST_NET: math.MaxUint32,
+}
+
+//Check router start block of current network
+func (self RouterStartBlocks) Check(router uint64, block uint32) error {
+	startBlock := self[config.DefConfig.P2PNode.NetworkId]
+	if startBlock > 0 && block < startBlock {
 		return fmt.Errorf("not a supported router:%d", router)
 	}
-	return
-}
\ No newline at end of file
+	return nil
+}
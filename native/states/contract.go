@@ -79,4 +79,5 @@ type PreExecResult struct {
 	State  byte
 	Result interface{}
 	Notify []*event.NotifyEventInfo
+	Gas    uint64
 }
@@ -72,6 +72,11 @@ func (self *CacheDB) Commit() {
 	})
 }
 
+// ForEach iterate the changes of current transaction cache, a deleted item has empty value
+func (self *CacheDB) ForEach(f func(key, val []byte)) {
+	self.memdb.ForEach(f)
+}
+
 func (self *CacheDB) Put(key []byte, value []byte) {
 	self.put(common.ST_STORAGE, key, value)
 }
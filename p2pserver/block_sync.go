@@ -19,7 +19,9 @@
 package p2pserver
 
 import (
+	"fmt"
 	"math"
+	"runtime"
 	"sort"
 	"sync"
 	"time"
@@ -28,6 +30,8 @@ import (
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/core/validation"
+	"github.com/polynetwork/poly/errors"
 	p2pComm "github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
 	"github.com/polynetwork/poly/p2pserver/peer"
@@ -47,98 +51,11 @@ const (
 	SYNC_NODE_SPEED_INIT         = 100 * 1024 //Init a big speed (100MB/s) for every node in first round
 	SYNC_MAX_ERROR_RESP_TIMES    = 5          //Max error headers/blocks response times, if reaches, delete it
 	SYNC_MAX_HEIGHT_OFFSET       = 5          //Offset of the max height and current height
+	SYNC_TIMEOUT_PENALTY         = 1          //Weight penalty of each timeout of the default peer scorer
+	SYNC_INVALID_DATA_PENALTY    = 10         //Weight penalty of each invalid header/block of the default peer scorer
+	SYNC_VERIFY_QUEUE_SIZE       = 500        //Max number of received blocks waiting for pre-verification
 )
 
-//NodeWeight record some params of node, using for sort
-type NodeWeight struct {
-	id           uint64    //NodeID
-	speed        []float32 //Record node request-response speed, using for calc the avg speed, unit kB/s
-	timeoutCnt   int       //Node response timeout count
-	errorRespCnt int       //Node response error data count
-	reqTime      []int64   //Record request time, using for calc the avg req time interval, unit millisecond
-}
-
-//NewNodeWeight new a nodeweight
-func NewNodeWeight(id uint64) *NodeWeight {
-	s := make([]float32, 0, SYNC_NODE_RECORD_SPEED_CNT)
-	for i := 0; i < SYNC_NODE_RECORD_SPEED_CNT; i++ {
-		s = append(s, float32(SYNC_NODE_SPEED_INIT))
-	}
-	r := make([]int64, 0, SYNC_NODE_RECORD_TIME_CNT)
-	now := time.Now().UnixNano() / int64(time.Millisecond)
-	for i := 0; i < SYNC_NODE_RECORD_TIME_CNT; i++ {
-		r = append(r, now)
-	}
-	return &NodeWeight{
-		id:           id,
-		speed:        s,
-		timeoutCnt:   0,
-		errorRespCnt: 0,
-		reqTime:      r,
-	}
-}
-
-//AddTimeoutCnt incre timeout count
-func (this *NodeWeight) AddTimeoutCnt() {
-	this.timeoutCnt++
-}
-
-//AddErrorRespCnt incre receive error header/block count
-func (this *NodeWeight) AddErrorRespCnt() {
-	this.errorRespCnt++
-}
-
-//GetErrorRespCnt get the error response count
-func (this *NodeWeight) GetErrorRespCnt() int {
-	return this.errorRespCnt
-}
-
-//AppendNewReqTime append new request time
-func (this *NodeWeight) AppendNewReqtime() {
-	copy(this.reqTime[0:SYNC_NODE_RECORD_TIME_CNT-1], this.reqTime[1:])
-	this.reqTime[SYNC_NODE_RECORD_TIME_CNT-1] = time.Now().UnixNano() / int64(time.Millisecond)
-}
-
-//addNewSpeed apend the new speed to tail, remove the oldest one
-func (this *NodeWeight) AppendNewSpeed(s float32) {
-	copy(this.speed[0:SYNC_NODE_RECORD_SPEED_CNT-1], this.speed[1:])
-	this.speed[SYNC_NODE_RECORD_SPEED_CNT-1] = s
-}
-
-//Weight calculate node's weight for sort. Highest weight node will be accessed first for next request.
-func (this *NodeWeight) Weight() float32 {
-	avgSpeed := float32(0.0)
-	for _, s := range this.speed {
-		avgSpeed += s
-	}
-	avgSpeed = avgSpeed / float32(len(this.speed))
-
-	avgInterval := float32(0.0)
-	now := time.Now().UnixNano() / int64(time.Millisecond)
-	for _, t := range this.reqTime {
-		avgInterval += float32(now - t)
-	}
-	avgInterval = avgInterval / float32(len(this.reqTime))
-	w := avgSpeed + avgInterval
-	return w
-}
-
-//NodeWeights implement sorting
-type NodeWeights []*NodeWeight
-
-func (nws NodeWeights) Len() int {
-	return len(nws)
-}
-
-func (nws NodeWeights) Swap(i, j int) {
-	nws[i], nws[j] = nws[j], nws[i]
-}
-func (nws NodeWeights) Less(i, j int) bool {
-	ni := nws[i]
-	nj := nws[j]
-	return ni.Weight() < nj.Weight() && ni.errorRespCnt >= nj.errorRespCnt && ni.timeoutCnt >= nj.timeoutCnt
-}
-
 //SyncFlightInfo record the info of fight object(header or block)
 type SyncFlightInfo struct {
 	Height      uint32         //BlockHeight of HeaderHeight
@@ -220,36 +137,64 @@ type BlockInfo struct {
 	merkleRoot common.Uint256
 }
 
-//BlockSyncMgr is the manager class to deal with block sync
+//BlockSyncMgr is the manager class to deal with block sync.
+//Headers are synced and verified first, then the blocks of the verified header range are downloaded from
+//multiple nodes in parallel, pre-verified concurrently against the headers, and committed to ledger in order
 type BlockSyncMgr struct {
-	flightBlocks   map[common.Uint256][]*SyncFlightInfo //Map BlockHash => []SyncFlightInfo, using for manager all of those block flights
-	flightHeaders  map[uint32]*SyncFlightInfo           //Map HeaderHeight => SyncFlightInfo, using for manager all of those header flights
-	blocksCache    map[uint32]*BlockInfo                //Map BlockHash => BlockInfo, using for cache the blocks receive from net, and waiting for commit to ledger
-	server         *P2PServer                           //Pointer to the local node
-	syncBlockLock  bool                                 //Help to avoid send block sync request duplicate
-	syncHeaderLock bool                                 //Help to avoid send header sync request duplicate
-	saveBlockLock  bool                                 //Help to avoid saving block concurrently
-	exitCh         chan interface{}                     //ExitCh to receive exit signal
-	ledger         *ledger.Ledger                       //ledger
-	lock           sync.RWMutex                         //lock
-	nodeWeights    map[uint64]*NodeWeight               //Map NodeID => NodeStatus, using for getNextNode
+	flightBlocks    map[common.Uint256][]*SyncFlightInfo //Map BlockHash => []SyncFlightInfo, using for manager all of those block flights
+	flightHeaders   map[uint32]*SyncFlightInfo           //Map HeaderHeight => SyncFlightInfo, using for manager all of those header flights
+	blocksCache     map[uint32]*BlockInfo                //Map BlockHash => BlockInfo, using for cache the blocks receive from net, and waiting for commit to ledger
+	verifyingBlocks map[uint32]bool                      //Map BlockHeight => true, blocks received and waiting for pre-verification
+	verifyCh        chan *BlockInfo                      //Queue of the blocks waiting for pre-verification
+	server          *P2PServer                           //Pointer to the local node
+	syncBlockLock   bool                                 //Help to avoid send block sync request duplicate
+	syncHeaderLock  bool                                 //Help to avoid send header sync request duplicate
+	saveBlockLock   bool                                 //Help to avoid saving block concurrently
+	exitCh          chan interface{}                     //ExitCh to receive exit signal
+	ledger          *ledger.Ledger                       //ledger
+	lock            sync.RWMutex                         //lock
+	syncNodes       map[uint64]bool                      //Map NodeID => true, nodes using for sync
+	scorer          PeerScorer                           //Score the sync nodes, using for getNextNode
 }
 
 //NewBlockSyncMgr return a BlockSyncMgr instance
 func NewBlockSyncMgr(server *P2PServer) *BlockSyncMgr {
 	return &BlockSyncMgr{
-		flightBlocks:  make(map[common.Uint256][]*SyncFlightInfo, 0),
-		flightHeaders: make(map[uint32]*SyncFlightInfo, 0),
-		blocksCache:   make(map[uint32]*BlockInfo, 0),
-		server:        server,
-		ledger:        server.ledger,
-		exitCh:        make(chan interface{}, 1),
-		nodeWeights:   make(map[uint64]*NodeWeight, 0),
+		flightBlocks:    make(map[common.Uint256][]*SyncFlightInfo, 0),
+		flightHeaders:   make(map[uint32]*SyncFlightInfo, 0),
+		blocksCache:     make(map[uint32]*BlockInfo, 0),
+		verifyingBlocks: make(map[uint32]bool, 0),
+		verifyCh:        make(chan *BlockInfo, SYNC_VERIFY_QUEUE_SIZE),
+		server:          server,
+		ledger:          server.ledger,
+		exitCh:          make(chan interface{}, 1),
+		syncNodes:       make(map[uint64]bool, 0),
+		scorer:          NewDefaultPeerScorer(),
+	}
+}
+
+//SetPeerScorer replace the scorer of sync nodes. The nodes already in sync are added to the new scorer
+func (this *BlockSyncMgr) SetPeerScorer(scorer PeerScorer) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for id := range this.syncNodes {
+		scorer.AddPeer(id)
 	}
+	this.scorer = scorer
+}
+
+//getScorer return the current peer scorer
+func (this *BlockSyncMgr) getScorer() PeerScorer {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.scorer
 }
 
 //Start to sync
 func (this *BlockSyncMgr) Start() {
+	for i := 0; i < runtime.NumCPU(); i++ {
+		go this.verifyBlocks()
+	}
 	go this.sync()
 	ticker := time.NewTicker(time.Second)
 	for {
@@ -285,9 +230,10 @@ func (this *BlockSyncMgr) checkTimeout() {
 
 	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
+	scorer := this.getScorer()
 
 	for height, flightInfo := range headerTimeoutFlights {
-		this.addTimeoutCnt(flightInfo.GetNodeId())
+		scorer.OnTimeout(flightInfo.GetNodeId())
 		if height <= curHeaderHeight {
 			this.delFlightHeader(height)
 			continue
@@ -307,12 +253,12 @@ func (this *BlockSyncMgr) checkTimeout() {
 		if err != nil {
 			log.Warn("[p2p]checkTimeout failed to send a new headersReq:s", err)
 		} else {
-			this.appendReqTime(reqNode.GetID())
+			scorer.OnRequest(reqNode.GetID())
 		}
 	}
 	for blockHash, flightInfos := range blockTimeoutFlights {
 		for _, flightInfo := range flightInfos {
-			this.addTimeoutCnt(flightInfo.GetNodeId())
+			scorer.OnTimeout(flightInfo.GetNodeId())
 			if flightInfo.Height <= curBlockHeight {
 				this.delFlightBlock(blockHash)
 				continue
@@ -320,7 +266,7 @@ func (this *BlockSyncMgr) checkTimeout() {
 			flightInfo.ResetStartTime()
 			flightInfo.MarkFailedNode()
 			log.Tracef("[p2p]checkTimeout sync height:%d block:0x%x timeout after:%d s times:%d", flightInfo.Height, blockHash, SYNC_BLOCK_REQUEST_TIMEOUT, flightInfo.GetTotalFailedTimes())
-			reqNode := this.getNodeWithMinFailedTimes(flightInfo, curBlockHeight)
+			reqNode := this.getNodeWithMinFailedTimes(flightInfo, flightInfo.Height-1)
 			if reqNode == nil {
 				break
 			}
@@ -332,7 +278,7 @@ func (this *BlockSyncMgr) checkTimeout() {
 				log.Warnf("[p2p]checkTimeout reqNode ID:%d Send error:%s", reqNode.GetID(), err)
 				continue
 			} else {
-				this.appendReqTime(reqNode.GetID())
+				scorer.OnRequest(reqNode.GetID())
 			}
 		}
 	}
@@ -375,12 +321,13 @@ func (this *BlockSyncMgr) syncHeader() {
 	if err != nil {
 		log.Warn("[p2p]syncHeader failed to send a new headersReq")
 	} else {
-		this.appendReqTime(reqNode.GetID())
+		this.getScorer().OnRequest(reqNode.GetID())
 	}
 
 	log.Infof("Header sync request height:%d", NextHeaderId)
 }
 
+//syncBlock request the blocks of verified headers, the requests are spread over the sync nodes by their scores
 func (this *BlockSyncMgr) syncBlock() {
 	if this.tryGetSyncBlockLock() {
 		return
@@ -404,6 +351,8 @@ func (this *BlockSyncMgr) syncBlock() {
 	if count > cacheCap {
 		count = cacheCap
 	}
+	selector := newNodeSelector(this.getSyncNodes(curBlockHeight+1), this.getScorer())
+	scorer := this.getScorer()
 
 	counter := 1
 	i := uint32(0)
@@ -426,17 +375,19 @@ func (this *BlockSyncMgr) syncBlock() {
 				continue
 			}
 		}
-		if this.isInBlockCache(nextBlockHeight) {
+		if this.isInBlockCache(nextBlockHeight) || this.isBlockVerifying(nextBlockHeight) {
 			continue
 		}
 		if nextBlockHeight <= curBlockHeight+SYNC_NEXT_BLOCKS_HEIGHT {
 			reqTimes = SYNC_NEXT_BLOCK_TIMES
 		}
+		reqNodes := make(map[uint64]bool, reqTimes)
 		for t := 0; t < reqTimes; t++ {
-			reqNode := this.getNextNode(nextBlockHeight)
+			reqNode := selector.next(nextBlockHeight, reqNodes)
 			if reqNode == nil {
-				return
+				break
 			}
+			reqNodes[reqNode.GetID()] = true
 			this.addFlightBlock(reqNode.GetID(), nextBlockHeight, nextBlockHash)
 			msg := msgpack.NewBlkDataReq(nextBlockHash)
 			err := this.server.Send(reqNode, msg, false)
@@ -444,9 +395,12 @@ func (this *BlockSyncMgr) syncBlock() {
 				log.Warnf("[p2p]syncBlock Height:%d ReqBlkData error:%s", nextBlockHeight, err)
 				return
 			} else {
-				this.appendReqTime(reqNode.GetID())
+				scorer.OnRequest(reqNode.GetID())
 			}
 		}
+		if len(reqNodes) == 0 {
+			return
+		}
 		counter++
 		reqTimes = 1
 	}
@@ -471,11 +425,7 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID uint64, headers []*types.Header
 	err := this.ledger.AddHeaders(headers)
 	this.delFlightHeader(height)
 	if err != nil {
-		this.addErrorRespCnt(fromID)
-		n := this.getNodeWeight(fromID)
-		if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
-			this.delNode(fromID)
-		}
+		this.onInvalidData(fromID, err)
 		log.Warnf("[p2p]OnHeaderReceive AddHeaders error:%s", err)
 		return
 	}
@@ -487,12 +437,10 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID uint64, blockSize uint32, block
 	merkleRoot common.Uint256) {
 	height := block.Header.Height
 	blockHash := block.Hash()
-	log.Trace("[p2p]OnBlockReceive Height:%d", height)
+	log.Tracef("[p2p]OnBlockReceive Height:%d", height)
 	flightInfo := this.getFlightBlock(blockHash, fromID)
 	if flightInfo != nil {
-		t := (time.Now().UnixNano() - flightInfo.GetStartTime().UnixNano()) / int64(time.Millisecond)
-		s := float32(blockSize) / float32(t) * 1000.0 / 1024.0
-		this.addNewSpeed(fromID, s)
+		this.getScorer().OnResponse(fromID, blockSize, time.Since(flightInfo.GetStartTime()))
 	}
 
 	this.delFlightBlock(blockHash)
@@ -506,18 +454,82 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID uint64, blockSize uint32, block
 		return
 	}
 
-	this.addBlockCache(fromID, block, merkleRoot)
-	go this.saveBlock()
+	if this.isInBlockCache(height) || !this.addBlockVerifying(height) {
+		return
+	}
+	select {
+	case this.verifyCh <- &BlockInfo{nodeID: fromID, block: block, merkleRoot: merkleRoot}:
+	default:
+		this.delBlockVerifying(height)
+		log.Warnf("[p2p]OnBlockReceive Height:%d verify queue is full", height)
+	}
 	this.syncBlock()
 }
 
+//verifyBlocks pre-verify the received blocks, the valid blocks are cached for saving in order
+func (this *BlockSyncMgr) verifyBlocks() {
+	for {
+		select {
+		case <-this.exitCh:
+			return
+		case blockInfo := <-this.verifyCh:
+			height := blockInfo.block.Header.Height
+			headerHash := this.ledger.GetBlockHash(height)
+			err := verifyBlockBody(blockInfo.block, headerHash)
+			if err != nil {
+				this.delBlockVerifying(height)
+				this.onInvalidData(blockInfo.nodeID, err)
+				log.Warnf("[p2p]verifyBlocks Height:%d from id:%d error:%s", height, blockInfo.nodeID, err)
+				continue
+			}
+			this.addBlockCache(blockInfo.nodeID, blockInfo.block, blockInfo.merkleRoot)
+			this.delBlockVerifying(height)
+			go this.saveBlock()
+		}
+	}
+}
+
+//verifyBlockBody check the block hash against the verified header hash of the height, and check the transactions
+//root and signatures. The header hash is empty if the header hasn't been synced, then the header is verified by ledger
+func verifyBlockBody(block *types.Block, headerHash common.Uint256) error {
+	if headerHash != common.UINT256_EMPTY {
+		blockHash := block.Hash()
+		if blockHash != headerHash {
+			return fmt.Errorf("block hash %s mismatch header hash %s", blockHash.ToHexString(), headerHash.ToHexString())
+		}
+	}
+	txHashes := make([]common.Uint256, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		txHashes = append(txHashes, tx.Hash())
+	}
+	if common.ComputeMerkleRoot(txHashes) != block.Header.TransactionsRoot {
+		return fmt.Errorf("transactions root mismatch")
+	}
+	for _, tx := range block.Transactions {
+		if errCode := validation.VerifyTransaction(tx); errCode != errors.ErrNoError {
+			txHash := tx.Hash()
+			return fmt.Errorf("verify transaction %s error %s", txHash.ToHexString(), errCode.Error())
+		}
+	}
+	return nil
+}
+
+//onInvalidData penalize the node sending invalid header or block, and stop syncing from it if it's banned
+func (this *BlockSyncMgr) onInvalidData(nodeId uint64, err error) {
+	scorer := this.getScorer()
+	scorer.OnInvalidData(nodeId, err)
+	if scorer.IsBanned(nodeId) {
+		this.delNode(nodeId)
+	}
+}
+
 //OnAddNode to node list when a new node added
 func (this *BlockSyncMgr) OnAddNode(nodeId uint64) {
 	log.Debugf("[p2p]OnAddNode:%d", nodeId)
 	this.lock.Lock()
 	defer this.lock.Unlock()
-	w := NewNodeWeight(nodeId)
-	this.nodeWeights[nodeId] = w
+	this.syncNodes[nodeId] = true
+	this.scorer.AddPeer(nodeId)
 }
 
 //OnDelNode remove from node list. When the node disconnect
@@ -530,9 +542,10 @@ func (this *BlockSyncMgr) OnDelNode(nodeId uint64) {
 func (this *BlockSyncMgr) delNode(nodeId uint64) {
 	this.lock.Lock()
 	defer this.lock.Unlock()
-	delete(this.nodeWeights, nodeId)
+	delete(this.syncNodes, nodeId)
+	this.scorer.DelPeer(nodeId)
 	log.Infof("delNode:%d", nodeId)
-	if len(this.nodeWeights) == 0 {
+	if len(this.syncNodes) == 0 {
 		log.Warnf("no sync nodes")
 	}
 	log.Infof("OnDelNode:%d", nodeId)
@@ -600,6 +613,29 @@ func (this *BlockSyncMgr) delBlockCache(blockHeight uint32) {
 	delete(this.blocksCache, blockHeight)
 }
 
+//addBlockVerifying mark the block of height is waiting for pre-verification, return false if it's already marked
+func (this *BlockSyncMgr) addBlockVerifying(blockHeight uint32) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.verifyingBlocks[blockHeight] {
+		return false
+	}
+	this.verifyingBlocks[blockHeight] = true
+	return true
+}
+
+func (this *BlockSyncMgr) delBlockVerifying(blockHeight uint32) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.verifyingBlocks, blockHeight)
+}
+
+func (this *BlockSyncMgr) isBlockVerifying(blockHeight uint32) bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.verifyingBlocks[blockHeight]
+}
+
 func (this *BlockSyncMgr) tryGetSaveBlockLock() bool {
 	this.lock.Lock()
 	defer this.lock.Unlock()
@@ -638,11 +674,7 @@ func (this *BlockSyncMgr) saveBlock() {
 		err := this.ledger.AddBlock(nextBlock, merkleRoot)
 		this.delBlockCache(nextBlockHeight)
 		if err != nil {
-			this.addErrorRespCnt(fromID)
-			n := this.getNodeWeight(fromID)
-			if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
-				this.delNode(fromID)
-			}
+			this.onInvalidData(fromID, err)
 			log.Warnf("[p2p]saveBlock Height:%d AddBlock error:%s", nextBlockHeight, err)
 			reqNode := this.getNextNode(nextBlockHeight)
 			if reqNode == nil {
@@ -655,7 +687,7 @@ func (this *BlockSyncMgr) saveBlock() {
 				log.Warn("[p2p]require new block error:", err)
 				return
 			} else {
-				this.appendReqTime(reqNode.GetID())
+				this.getScorer().OnRequest(reqNode.GetID())
 			}
 			return
 		}
@@ -775,63 +807,64 @@ func (this *BlockSyncMgr) isBlockOnFlight(blockHash common.Uint256) bool {
 	return false
 }
 
-func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
-	weights := this.getAllNodeWeights()
-	sort.Sort(sort.Reverse(weights))
-	nodelist := make([]uint64, 0)
-	for _, n := range weights {
-		nodelist = append(nodelist, n.id)
-	}
-	nextNodeIndex := 0
-	triedNode := make(map[uint64]bool, 0)
-	for {
-		var nextNodeId uint64
-		nextNodeIndex, nextNodeId = getNextNodeId(nextNodeIndex, nodelist)
-		if nextNodeId == 0 {
-			return nil
-		}
-		_, ok := triedNode[nextNodeId]
-		if ok {
-			return nil
+//getSyncNodes return the established sync nodes reaching the height, sorted by score from high to low
+func (this *BlockSyncMgr) getSyncNodes(height uint32) []*peer.Peer {
+	this.lock.RLock()
+	ids := make([]uint64, 0, len(this.syncNodes))
+	for id := range this.syncNodes {
+		ids = append(ids, id)
+	}
+	scorer := this.scorer
+	this.lock.RUnlock()
+
+	nodes := make([]*peer.Peer, 0, len(ids))
+	scores := make(map[uint64]float64, len(ids))
+	for _, id := range ids {
+		if scorer.IsBanned(id) {
+			continue
 		}
-		triedNode[nextNodeId] = true
-		n := this.server.getNode(nextNodeId)
+		n := this.server.getNode(id)
 		if n == nil {
 			continue
 		}
 		if n.GetSyncState() != p2pComm.ESTABLISH {
 			continue
 		}
-		nodeBlockHeight := n.GetHeight()
-		if nextBlockHeight <= uint32(nodeBlockHeight) {
-			return n
+		if height > uint32(n.GetHeight()) {
+			continue
 		}
+		nodes = append(nodes, n)
+		scores[id] = scorer.Score(id)
 	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return scores[nodes[i].GetID()] > scores[nodes[j].GetID()]
+	})
+	return nodes
+}
+
+//getNextNode return the highest scored node reaching the height
+func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
+	nodes := this.getSyncNodes(nextBlockHeight)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
 }
 
 func (this *BlockSyncMgr) getNodeWithMinFailedTimes(flightInfo *SyncFlightInfo, curBlockHeight uint32) *peer.Peer {
 	var minFailedTimes = math.MaxInt64
 	var minFailedTimesNode *peer.Peer
-	triedNode := make(map[uint64]bool, 0)
-	for {
-		nextNode := this.getNextNode(curBlockHeight + 1)
-		if nextNode == nil {
-			return nil
-		}
-		failedTimes := flightInfo.GetFailedTimes(nextNode.GetID())
+	for _, n := range this.getSyncNodes(curBlockHeight + 1) {
+		failedTimes := flightInfo.GetFailedTimes(n.GetID())
 		if failedTimes == 0 {
-			return nextNode
-		}
-		_, ok := triedNode[nextNode.GetID()]
-		if ok {
-			return minFailedTimesNode
+			return n
 		}
-		triedNode[nextNode.GetID()] = true
 		if failedTimes < minFailedTimes {
 			minFailedTimes = failedTimes
-			minFailedTimesNode = nextNode
+			minFailedTimesNode = n
 		}
 	}
+	return minFailedTimesNode
 }
 
 //Stop to sync
@@ -839,62 +872,12 @@ func (this *BlockSyncMgr) Close() {
 	close(this.exitCh)
 }
 
-//getNodeWeight get nodeweight by id
-func (this *BlockSyncMgr) getNodeWeight(nodeId uint64) *NodeWeight {
-	this.lock.RLock()
-	defer this.lock.RUnlock()
-	return this.nodeWeights[nodeId]
-}
-
-//getAllNodeWeights get all nodeweight and return a slice
-func (this *BlockSyncMgr) getAllNodeWeights() NodeWeights {
-	this.lock.RLock()
-	defer this.lock.RUnlock()
-	weights := make(NodeWeights, 0, len(this.nodeWeights))
-	for _, w := range this.nodeWeights {
-		weights = append(weights, w)
-	}
-	return weights
-}
-
-//addTimeoutCnt incre a node's timeout count
-func (this *BlockSyncMgr) addTimeoutCnt(nodeId uint64) {
-	n := this.getNodeWeight(nodeId)
-	if n != nil {
-		n.AddTimeoutCnt()
-	}
-}
-
-//addErrorRespCnt incre a node's error resp count
-func (this *BlockSyncMgr) addErrorRespCnt(nodeId uint64) {
-	n := this.getNodeWeight(nodeId)
-	if n != nil {
-		n.AddErrorRespCnt()
-	}
-}
-
-//appendReqTime append a node's request time
-func (this *BlockSyncMgr) appendReqTime(nodeId uint64) {
-	n := this.getNodeWeight(nodeId)
-	if n != nil {
-		n.AppendNewReqtime()
-	}
-}
-
-//addNewSpeed apend the new speed to tail, remove the oldest one
-func (this *BlockSyncMgr) addNewSpeed(nodeId uint64, speed float32) {
-	n := this.getNodeWeight(nodeId)
-	if n != nil {
-		n.AppendNewSpeed(speed)
-	}
-}
-
 //pingOutsyncNodes send ping msg to lower height nodes for syncing
 func (this *BlockSyncMgr) pingOutsyncNodes(curHeight uint32) {
 	peers := make([]*peer.Peer, 0)
 	this.lock.RLock()
 	maxHeight := curHeight
-	for id := range this.nodeWeights {
+	for id := range this.syncNodes {
 		peer := this.server.getNode(id)
 		if peer == nil {
 			continue
@@ -913,16 +896,48 @@ func (this *BlockSyncMgr) pingOutsyncNodes(curHeight uint32) {
 	}
 }
 
-//Using polling for load balance
-func getNextNodeId(nextNodeIndex int, nodeList []uint64) (int, uint64) {
-	num := len(nodeList)
-	if num == 0 {
-		return 0, 0
+//nodeSelector spread the block requests over the sync nodes by smooth weighted round robin,
+//so that the nodes get requests in proportion to their scores
+type nodeSelector struct {
+	nodes   []*peer.Peer
+	weights []float64
+	current []float64
+}
+
+//newNodeSelector return a nodeSelector of the nodes
+func newNodeSelector(nodes []*peer.Peer, scorer PeerScorer) *nodeSelector {
+	weights := make([]float64, 0, len(nodes))
+	for _, n := range nodes {
+		w := scorer.Score(n.GetID())
+		if w <= 0 {
+			w = math.SmallestNonzeroFloat64
+		}
+		weights = append(weights, w)
 	}
-	if nextNodeIndex >= num {
-		nextNodeIndex = 0
+	return &nodeSelector{
+		nodes:   nodes,
+		weights: weights,
+		current: make([]float64, len(nodes)),
+	}
+}
+
+//next return the next node reaching the height and not in the excluded nodes, nil if no one available
+func (this *nodeSelector) next(height uint32, excluded map[uint64]bool) *peer.Peer {
+	best := -1
+	total := float64(0)
+	for i, n := range this.nodes {
+		if excluded[n.GetID()] || height > uint32(n.GetHeight()) {
+			continue
+		}
+		this.current[i] += this.weights[i]
+		total += this.weights[i]
+		if best < 0 || this.current[i] > this.current[best] {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
 	}
-	index := nextNodeIndex
-	nextNodeIndex++
-	return nextNodeIndex, nodeList[index]
+	this.current[best] -= total
+	return this.nodes[best]
 }
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/p2pserver/peer"
+)
+
+func newTestBlock(b testing.TB, txNum int) *types.Block {
+	acc := account.NewAccount("")
+	txs := make([]*types.Transaction, 0, txNum)
+	txHashes := make([]common.Uint256, 0, txNum)
+	for i := 0; i < txNum; i++ {
+		tx := &types.Transaction{
+			TxType:  types.Invoke,
+			Nonce:   uint32(i),
+			Payload: &payload.InvokeCode{Code: []byte{byte(i)}},
+			Sigs:    []types.Sig{},
+		}
+		// hash is set by deserialization, and signatures are not in the hash
+		tx = newTestTx(b, tx)
+		hash := tx.Hash()
+		sig, err := signature.Sign(acc, hash[:])
+		if err != nil {
+			b.Fatal("sign transaction error", err)
+		}
+		tx.Sigs = []types.Sig{{PubKeys: []keypair.PublicKey{acc.PublicKey}, M: 1, SigData: [][]byte{sig}}}
+		tx = newTestTx(b, tx)
+		txs = append(txs, tx)
+		txHashes = append(txHashes, tx.Hash())
+	}
+	header := &types.Header{
+		Height:           1,
+		TransactionsRoot: common.ComputeMerkleRoot(txHashes),
+	}
+	return &types.Block{Header: header, Transactions: txs}
+}
+
+func newTestTx(b testing.TB, tx *types.Transaction) *types.Transaction {
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		b.Fatal("serialize transaction error", err)
+	}
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	if err != nil {
+		b.Fatal("deserialize transaction error", err)
+	}
+	return tx
+}
+
+func newTestPeer(id uint64, height uint64) *peer.Peer {
+	p := peer.NewPeer()
+	p.UpdateInfo(time.Now(), 0, 0, 0, 0, id, 0, height, "")
+	return p
+}
+
+func TestVerifyBlockBody(t *testing.T) {
+	block := newTestBlock(t, 4)
+	if err := verifyBlockBody(block, block.Hash()); err != nil {
+		t.Error("TestVerifyBlockBody verify valid block error", err)
+	}
+	if err := verifyBlockBody(block, common.UINT256_EMPTY); err != nil {
+		t.Error("TestVerifyBlockBody verify block without header error", err)
+	}
+	if err := verifyBlockBody(block, common.Uint256{1}); err == nil {
+		t.Error("TestVerifyBlockBody header hash mismatch should fail")
+	}
+
+	tampered := &types.Block{Header: block.Header, Transactions: block.Transactions[:3]}
+	if err := verifyBlockBody(tampered, block.Hash()); err == nil {
+		t.Error("TestVerifyBlockBody transactions root mismatch should fail")
+	}
+
+	block.Transactions[0].Sigs = block.Transactions[1].Sigs
+	if err := verifyBlockBody(block, block.Hash()); err == nil {
+		t.Error("TestVerifyBlockBody invalid signature should fail")
+	}
+}
+
+func TestDefaultPeerScorer(t *testing.T) {
+	scorer := NewDefaultPeerScorer()
+	scorer.AddPeer(1)
+	scorer.AddPeer(2)
+	scorer.AddPeer(3)
+
+	scorer.OnTimeout(2)
+	if scorer.Score(2) >= scorer.Score(1) {
+		t.Error("TestDefaultPeerScorer timeout peer should be scored lower")
+	}
+	scorer.OnInvalidData(3, errors.New("invalid block"))
+	if scorer.Score(3) >= scorer.Score(2) {
+		t.Error("TestDefaultPeerScorer invalid data should be penalized more than timeout")
+	}
+	for i := 1; i < SYNC_MAX_ERROR_RESP_TIMES; i++ {
+		if scorer.IsBanned(3) {
+			t.Error("TestDefaultPeerScorer peer banned too early")
+		}
+		scorer.OnInvalidData(3, errors.New("invalid block"))
+	}
+	if !scorer.IsBanned(3) {
+		t.Error("TestDefaultPeerScorer peer should be banned")
+	}
+
+	scorer.DelPeer(1)
+	if scorer.Score(1) != 0 || scorer.IsBanned(1) {
+		t.Error("TestDefaultPeerScorer deleted peer should not be scored")
+	}
+}
+
+func TestNodeSelector(t *testing.T) {
+	scorer := NewDefaultPeerScorer()
+	nodes := []*peer.Peer{newTestPeer(1, 100), newTestPeer(2, 100), newTestPeer(3, 10)}
+	for _, n := range nodes {
+		scorer.AddPeer(n.GetID())
+	}
+	scorer.OnInvalidData(2, errors.New("invalid block"))
+
+	selector := newNodeSelector(nodes, scorer)
+	counts := make(map[uint64]int)
+	for i := 0; i < 100; i++ {
+		n := selector.next(50, nil)
+		if n == nil {
+			t.Fatal("TestNodeSelector no node selected")
+		}
+		counts[n.GetID()]++
+	}
+	if counts[3] != 0 {
+		t.Error("TestNodeSelector selected node lower than the height")
+	}
+	if counts[1] <= counts[2] || counts[2] == 0 {
+		t.Error("TestNodeSelector requests should be spread by score", counts)
+	}
+
+	n := selector.next(50, map[uint64]bool{1: true, 2: true})
+	if n != nil {
+		t.Error("TestNodeSelector excluded node selected")
+	}
+}
+
+func BenchmarkVerifyBlockBody(b *testing.B) {
+	block := newTestBlock(b, 100)
+	hash := block.Hash()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := verifyBlockBody(block, hash); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyBlockBodyParallel(b *testing.B) {
+	block := newTestBlock(b, 100)
+	hash := block.Hash()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := verifyBlockBody(block, hash); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkNodeSelector(b *testing.B) {
+	scorer := NewDefaultPeerScorer()
+	nodes := make([]*peer.Peer, 0, 50)
+	for i := uint64(1); i <= 50; i++ {
+		nodes = append(nodes, newTestPeer(i, 100))
+		scorer.AddPeer(i)
+	}
+	selector := newNodeSelector(nodes, scorer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		selector.next(50, nil)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"github.com/polynetwork/poly/common/metrics"
+)
+
+//Directions of the p2p traffic metrics
+const (
+	DIRECTION_IN  = "in"
+	DIRECTION_OUT = "out"
+)
+
+var (
+	//MessageCount counts the p2p messages by direction and message type
+	MessageCount = metrics.NewCounterVec("poly_p2p_messages_total", "Count of p2p messages", "direction", "type")
+	//MessageBytes counts the bytes of p2p messages by direction and message type
+	MessageBytes = metrics.NewCounterVec("poly_p2p_message_bytes_total", "Bytes of p2p messages including the header", "direction", "type")
+)
+
+func init() {
+	metrics.DefaultRegistry.MustRegister(MessageCount, MessageBytes)
+}
+
+//ObserveMessage records a p2p message of msgType and size bytes
+func ObserveMessage(direction, msgType string, size uint64) {
+	MessageCount.WithLabelValues(direction, msgType).Inc()
+	MessageBytes.WithLabelValues(direction, msgType).Add(size)
+}
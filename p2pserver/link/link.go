@@ -126,6 +126,7 @@ func (this *Link) Rx() {
 
 		t := time.Now()
 		this.UpdateRXTime(t)
+		common.ObserveMessage(common.DIRECTION_IN, msg.CmdType(), uint64(payloadSize)+common.MSG_HDR_LEN)
 
 		if !this.needSendMsg(msg) {
 			log.Debugf("skip handle msgType:%s from:%d", msg.CmdType(), this.id)
@@ -170,7 +171,7 @@ func (this *Link) Send(msg types.Message) error {
 	sink := comm.NewZeroCopySink(nil)
 	err := types.WriteMessage(sink, msg)
 	if err != nil {
-		log.Debugf("[p2p]error serialize messge ", err.Error())
+		log.Debugf("[p2p]error serialize messge %s", err.Error())
 		return err
 	}
 
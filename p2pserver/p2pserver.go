@@ -196,6 +196,11 @@ func (this *P2PServer) OnDelNode(id uint64) {
 	this.blockSync.OnDelNode(id)
 }
 
+// SetPeerScorer replaces the scorer used by the block sync mgr to rate sync peers
+func (this *P2PServer) SetPeerScorer(scorer PeerScorer) {
+	this.blockSync.SetPeerScorer(scorer)
+}
+
 // OnHeaderReceive adds the header list from network
 func (this *P2PServer) OnHeaderReceive(fromID uint64, headers []*types.Header) {
 	this.blockSync.OnHeaderReceive(fromID, headers)
@@ -572,7 +577,7 @@ func (this *P2PServer) tryRecentPeers() {
 	if comm.FileExisted(common.RECENT_FILE_NAME) {
 		buf, err := ioutil.ReadFile(common.RECENT_FILE_NAME)
 		if err != nil {
-			log.Warn("[p2p]read %s fail:%s, connect recent peers cancel", common.RECENT_FILE_NAME, err.Error())
+			log.Warnf("[p2p]read %s fail:%s, connect recent peers cancel", common.RECENT_FILE_NAME, err.Error())
 			return
 		}
 
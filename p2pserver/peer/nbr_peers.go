@@ -39,7 +39,7 @@ func (this *NbrPeers) Broadcast(msg types.Message, isConsensus bool) {
 	sink := comm.NewZeroCopySink(nil)
 	err := types.WriteMessage(sink, msg)
 	if err != nil {
-		log.Errorf("[p2p]error serialize message ", err.Error())
+		log.Errorf("[p2p]error serialize message %s", err.Error())
 		return
 	}
 
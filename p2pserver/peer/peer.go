@@ -348,7 +348,7 @@ func (this *Peer) Send(msg types.Message, isConsensus bool) error {
 	sink := comm.NewZeroCopySink(nil)
 	err := types.WriteMessage(sink, msg)
 	if err != nil {
-		log.Debugf("[p2p]error serialize messge ", err.Error())
+		log.Debugf("[p2p]error serialize messge %s", err.Error())
 		return err
 	}
 
@@ -356,10 +356,16 @@ func (this *Peer) Send(msg types.Message, isConsensus bool) error {
 }
 
 func (this *Peer) SendRaw(msgType string, msgPayload []byte, isConsensus bool) error {
+	var err error
 	if isConsensus && this.ConsLink.Valid() {
-		return this.SendToCons(msgType, msgPayload)
+		err = this.SendToCons(msgType, msgPayload)
+	} else {
+		err = this.SendToSync(msgType, msgPayload)
+	}
+	if err == nil {
+		common.ObserveMessage(common.DIRECTION_OUT, msgType, uint64(len(msgPayload)))
 	}
-	return this.SendToSync(msgType, msgPayload)
+	return err
 }
 
 //SetHttpInfoState set peer`s httpinfo state
@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"sync"
+	"time"
+)
+
+//PeerScorer rates the sync peers. BlockSyncMgr requests headers and blocks from the highest scored peers first,
+//and stops syncing from a peer once it is banned
+type PeerScorer interface {
+	//AddPeer start to score a new sync peer
+	AddPeer(id uint64)
+	//DelPeer stop to score a disconnected peer
+	DelPeer(id uint64)
+	//OnRequest is called when a header or block request is sent to the peer
+	OnRequest(id uint64)
+	//OnResponse is called when the requested data is received from the peer
+	OnResponse(id uint64, size uint32, elapsed time.Duration)
+	//OnTimeout is called when the peer doesn't response in time
+	OnTimeout(id uint64)
+	//OnInvalidData is called when the data received from the peer fails verification
+	OnInvalidData(id uint64, err error)
+	//Score return the score of peer, higher is better
+	Score(id uint64) float64
+	//IsBanned return whether the peer should not be used for syncing any more
+	IsBanned(id uint64) bool
+}
+
+//NodeWeight record some params of node, using for sort
+type NodeWeight struct {
+	id           uint64    //NodeID
+	speed        []float32 //Record node request-response speed, using for calc the avg speed, unit kB/s
+	timeoutCnt   int       //Node response timeout count
+	errorRespCnt int       //Node response error data count
+	reqTime      []int64   //Record request time, using for calc the avg req time interval, unit millisecond
+}
+
+//NewNodeWeight new a nodeweight
+func NewNodeWeight(id uint64) *NodeWeight {
+	s := make([]float32, 0, SYNC_NODE_RECORD_SPEED_CNT)
+	for i := 0; i < SYNC_NODE_RECORD_SPEED_CNT; i++ {
+		s = append(s, float32(SYNC_NODE_SPEED_INIT))
+	}
+	r := make([]int64, 0, SYNC_NODE_RECORD_TIME_CNT)
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for i := 0; i < SYNC_NODE_RECORD_TIME_CNT; i++ {
+		r = append(r, now)
+	}
+	return &NodeWeight{
+		id:           id,
+		speed:        s,
+		timeoutCnt:   0,
+		errorRespCnt: 0,
+		reqTime:      r,
+	}
+}
+
+//AddTimeoutCnt incre timeout count
+func (this *NodeWeight) AddTimeoutCnt() {
+	this.timeoutCnt++
+}
+
+//DecTimeoutCnt decre timeout count after a successful response
+func (this *NodeWeight) DecTimeoutCnt() {
+	if this.timeoutCnt > 0 {
+		this.timeoutCnt--
+	}
+}
+
+//AddErrorRespCnt incre receive error header/block count
+func (this *NodeWeight) AddErrorRespCnt() {
+	this.errorRespCnt++
+}
+
+//GetErrorRespCnt get the error response count
+func (this *NodeWeight) GetErrorRespCnt() int {
+	return this.errorRespCnt
+}
+
+//AppendNewReqTime append new request time
+func (this *NodeWeight) AppendNewReqtime() {
+	copy(this.reqTime[0:SYNC_NODE_RECORD_TIME_CNT-1], this.reqTime[1:])
+	this.reqTime[SYNC_NODE_RECORD_TIME_CNT-1] = time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+//addNewSpeed apend the new speed to tail, remove the oldest one
+func (this *NodeWeight) AppendNewSpeed(s float32) {
+	copy(this.speed[0:SYNC_NODE_RECORD_SPEED_CNT-1], this.speed[1:])
+	this.speed[SYNC_NODE_RECORD_SPEED_CNT-1] = s
+}
+
+//Weight calculate node's weight for sort. Highest weight node will be accessed first for next request.
+func (this *NodeWeight) Weight() float32 {
+	avgSpeed := float32(0.0)
+	for _, s := range this.speed {
+		avgSpeed += s
+	}
+	avgSpeed = avgSpeed / float32(len(this.speed))
+
+	avgInterval := float32(0.0)
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, t := range this.reqTime {
+		avgInterval += float32(now - t)
+	}
+	avgInterval = avgInterval / float32(len(this.reqTime))
+	w := avgSpeed + avgInterval
+	return w
+}
+
+//DefaultPeerScorer scores peers by the response speed and request interval of NodeWeight,
+//and divides the weight by the penalty of timeouts and invalid data
+type DefaultPeerScorer struct {
+	lock        sync.RWMutex
+	nodeWeights map[uint64]*NodeWeight //Map NodeID => NodeWeight
+}
+
+//NewDefaultPeerScorer return a DefaultPeerScorer instance
+func NewDefaultPeerScorer() *DefaultPeerScorer {
+	return &DefaultPeerScorer{
+		nodeWeights: make(map[uint64]*NodeWeight),
+	}
+}
+
+func (this *DefaultPeerScorer) AddPeer(id uint64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.nodeWeights[id] = NewNodeWeight(id)
+}
+
+func (this *DefaultPeerScorer) DelPeer(id uint64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.nodeWeights, id)
+}
+
+func (this *DefaultPeerScorer) OnRequest(id uint64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if n, ok := this.nodeWeights[id]; ok {
+		n.AppendNewReqtime()
+	}
+}
+
+func (this *DefaultPeerScorer) OnResponse(id uint64, size uint32, elapsed time.Duration) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	n, ok := this.nodeWeights[id]
+	if !ok {
+		return
+	}
+	t := elapsed.Milliseconds()
+	if t <= 0 {
+		t = 1
+	}
+	n.AppendNewSpeed(float32(size) / float32(t) * 1000.0 / 1024.0)
+	n.DecTimeoutCnt()
+}
+
+func (this *DefaultPeerScorer) OnTimeout(id uint64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if n, ok := this.nodeWeights[id]; ok {
+		n.AddTimeoutCnt()
+	}
+}
+
+func (this *DefaultPeerScorer) OnInvalidData(id uint64, err error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if n, ok := this.nodeWeights[id]; ok {
+		n.AddErrorRespCnt()
+	}
+}
+
+func (this *DefaultPeerScorer) Score(id uint64) float64 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	n, ok := this.nodeWeights[id]
+	if !ok {
+		return 0
+	}
+	penalty := 1 + n.timeoutCnt*SYNC_TIMEOUT_PENALTY + n.errorRespCnt*SYNC_INVALID_DATA_PENALTY
+	return float64(n.Weight()) / float64(penalty)
+}
+
+func (this *DefaultPeerScorer) IsBanned(id uint64) bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	n, ok := this.nodeWeights[id]
+	if !ok {
+		return false
+	}
+	return n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package polylightclient
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+)
+
+//MakeTxParam is the cross chain call carried by a ToMerkleValue. It mirrors the MakeTxParam of the cross chain manager
+//so that the light client does not depend on the native contracts.
+type MakeTxParam struct {
+	TxHash              []byte
+	CrossChainID        []byte
+	FromContractAddress []byte
+	ToChainID           uint64
+	ToContractAddress   []byte
+	Method              string
+	Args                []byte
+}
+
+func (this *MakeTxParam) Deserialization(source *common.ZeroCopySource) error {
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize txHash error")
+	}
+	crossChainID, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize crossChainID error")
+	}
+	fromContractAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize fromContractAddress error")
+	}
+	toChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize toChainID error")
+	}
+	toContractAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize toContractAddress error")
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize method error")
+	}
+	args, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MakeTxParam deserialize args error")
+	}
+
+	this.TxHash = txHash
+	this.CrossChainID = crossChainID
+	this.FromContractAddress = fromContractAddress
+	this.ToChainID = toChainID
+	this.ToContractAddress = toContractAddress
+	this.Method = method
+	this.Args = args
+	return nil
+}
+
+//ToMerkleValue is the cross chain state committed in the CrossStateRoot of a poly header, mirror of the ToMerkleValue
+//of the cross chain manager.
+type ToMerkleValue struct {
+	TxHash      []byte
+	FromChainID uint64
+	MakeTxParam *MakeTxParam
+}
+
+func (this *ToMerkleValue) Deserialization(source *common.ZeroCopySource) error {
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MerkleValue deserialize txHash error")
+	}
+	fromChainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("MerkleValue deserialize fromChainID error")
+	}
+
+	makeTxParam := new(MakeTxParam)
+	err := makeTxParam.Deserialization(source)
+	if err != nil {
+		return fmt.Errorf("MerkleValue deserialize makeTxParam error:%s", err)
+	}
+
+	this.TxHash = txHash
+	this.FromChainID = fromChainID
+	this.MakeTxParam = makeTxParam
+	return nil
+}
+
+//VerifyCrossState verify a cross chain state proof against a header of current epoch and return the ToMerkleValue it proves.
+//The cross states of block h are committed in the CrossStateRoot of block h+1, so header must be the block after the
+//height passed to getcrossstatesproof rpc.
+func (this *LightClient) VerifyCrossState(header *types.Header, proof []byte) (*ToMerkleValue, error) {
+	if err := this.VerifyHeader(header); err != nil {
+		return nil, fmt.Errorf("VerifyCrossState, %v", err)
+	}
+	return VerifyCrossStateProof(header.CrossStateRoot, proof)
+}
+
+//VerifyCrossStateProof verify proof, a merkle path built by merkle.MerkleLeafPath, against crossStateRoot
+//and decode the proved ToMerkleValue
+func VerifyCrossStateProof(crossStateRoot common.Uint256, proof []byte) (*ToMerkleValue, error) {
+	value, err := merkle.MerkleProve(proof, crossStateRoot[:])
+	if err != nil {
+		return nil, fmt.Errorf("VerifyCrossStateProof, MerkleProve error: %v", err)
+	}
+	merkleValue := new(ToMerkleValue)
+	if err := merkleValue.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("VerifyCrossStateProof, deserialize ToMerkleValue error: %v", err)
+	}
+	return merkleValue, nil
+}
@@ -0,0 +1,433 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package polylightclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	bcomn "github.com/polynetwork/poly/http/base/common"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	soloFixturePath    = "testdata/solo_chain.json"
+	soloWalletPath     = "testdata/solo_wallet.dat"
+	soloWalletPassword = "passwordtest"
+
+	// side chains of the vote router registered by the recorder
+	soloSourceChainID uint64 = 1001
+	soloTargetChainID uint64 = 1002
+
+	vbftFixturePath = "testdata/vbft_chain.json"
+	vbftWalletPath  = "testdata/vbft/wallet%d.dat"
+	// wallets 1 to 4 are the genesis peers of testdata/vbft/genesis.json, wallet 5 is approved as candidate
+	vbftNodes = 5
+)
+
+var (
+	update  = flag.Bool("update", false, "record testdata from a solo node")
+	soloRPC = flag.String("solo-rpc", "http://localhost:20336", "json rpc `address` of the solo node to record")
+
+	updateVbft = flag.Bool("update-vbft", false, "record testdata from a local vbft network")
+	vbftRPC    = flag.String("vbft-rpc", "http://localhost:30339", "json rpc `address` of a node of the vbft network to record")
+)
+
+type soloFixture struct {
+	Keeper      string              `json:"keeper"`
+	Headers     []string            `json:"headers"`
+	CrossStates []crossStateFixture `json:"cross_states"`
+	BlockProofs []blockProofFixture `json:"block_proofs"`
+}
+
+// crossStateFixture is the result of getcrossstatesproof at Height
+type crossStateFixture struct {
+	Height    uint32 `json:"height"`
+	TxHash    string `json:"tx_hash"`
+	ToChainID uint64 `json:"to_chain_id"`
+	Proof     string `json:"proof"`
+}
+
+// blockProofFixture is the result of getmerkleproof for Height in the block root of RootHeight
+type blockProofFixture struct {
+	Height     uint32 `json:"height"`
+	RootHeight uint32 `json:"root_height"`
+	Proof      string `json:"proof"`
+}
+
+// vbftFixture holds the headers of a vbft network from genesis to two blocks after EpochHeight, the
+// header whose NewChainConfig adds the fifth node to the keepers
+type vbftFixture struct {
+	EpochHeight uint32   `json:"epoch_height"`
+	Headers     []string `json:"headers"`
+}
+
+func loadSoloFixture(t *testing.T) (*soloFixture, keypair.PublicKey, []*types.Header) {
+	data, err := ioutil.ReadFile(soloFixturePath)
+	if err != nil {
+		t.Fatalf("read fixture error: %v", err)
+	}
+	fixture := new(soloFixture)
+	if err := json.Unmarshal(data, fixture); err != nil {
+		t.Fatalf("unmarshal fixture error: %v", err)
+	}
+	raw, err := hex.DecodeString(fixture.Keeper)
+	assert.NoError(t, err)
+	keeper, err := keypair.DeserializePublicKey(raw)
+	assert.NoError(t, err)
+	headers := make([]*types.Header, 0, len(fixture.Headers))
+	for _, v := range fixture.Headers {
+		headers = append(headers, decodeHeader(t, v))
+	}
+	return fixture, keeper, headers
+}
+
+func loadVbftFixture(t *testing.T) (*vbftFixture, []*types.Header) {
+	data, err := ioutil.ReadFile(vbftFixturePath)
+	if err != nil {
+		t.Fatalf("read fixture error: %v", err)
+	}
+	fixture := new(vbftFixture)
+	if err := json.Unmarshal(data, fixture); err != nil {
+		t.Fatalf("unmarshal fixture error: %v", err)
+	}
+	headers := make([]*types.Header, 0, len(fixture.Headers))
+	for _, v := range fixture.Headers {
+		headers = append(headers, decodeHeader(t, v))
+	}
+	return fixture, headers
+}
+
+func decodeHeader(t *testing.T, s string) *types.Header {
+	raw, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	header, err := types.HeaderFromRawBytes(raw)
+	if err != nil {
+		t.Fatalf("decode header error: %v", err)
+	}
+	return header
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode hex error: %v", err)
+	}
+	return raw
+}
+
+// soloRecorder drives a node through its json rpc, txs are signed by keeper
+type soloRecorder struct {
+	t       *testing.T
+	url     string
+	keeper  *account.Account
+	chainID uint64
+	nonce   uint32
+}
+
+func (self *soloRecorder) call(method string, params ...interface{}) json.RawMessage {
+	if params == nil {
+		params = []interface{}{}
+	}
+	req, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params, "id": 1})
+	assert.NoError(self.t, err)
+	resp, err := http.Post(self.url, "application/json", bytes.NewReader(req))
+	if err != nil {
+		self.t.Fatalf("%s error: %v", method, err)
+	}
+	defer resp.Body.Close()
+	res := &struct {
+		Error  int64           `json:"error"`
+		Desc   string          `json:"desc"`
+		Result json.RawMessage `json:"result"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		self.t.Fatalf("%s decode response error: %v", method, err)
+	}
+	if res.Error != 0 {
+		self.t.Fatalf("%s error: %d %s %s", method, res.Error, res.Desc, string(res.Result))
+	}
+	return res.Result
+}
+
+// invoke sends a tx calling method of the native contract, signed by the keeper and cosigners
+func (self *soloRecorder) invoke(contract common.Address, method string, args []byte, cosigners ...*account.Account) common.Uint256 {
+	sink := common.NewZeroCopySink(nil)
+	(&states.ContractInvokeParam{Address: contract, Method: method, Args: args}).Serialization(sink)
+	self.nonce++
+	tx := &types.Transaction{
+		Version: types.CURR_TX_VERSION,
+		TxType:  types.Invoke,
+		Nonce:   self.nonce,
+		ChainID: self.chainID,
+		Payload: &payload.InvokeCode{Code: sink.Bytes()},
+	}
+	sink = common.NewZeroCopySink(nil)
+	assert.NoError(self.t, tx.Serialization(sink))
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	assert.NoError(self.t, err)
+	hash := tx.Hash()
+	for _, signer := range append([]*account.Account{self.keeper}, cosigners...) {
+		sig, err := signature.Sign(signer, hash[:])
+		assert.NoError(self.t, err)
+		tx.Sigs = append(tx.Sigs, types.Sig{PubKeys: []keypair.PublicKey{signer.PublicKey}, M: 1, SigData: [][]byte{sig}})
+	}
+	sink = common.NewZeroCopySink(nil)
+	assert.NoError(self.t, tx.Serialization(sink))
+	self.call("sendrawtransaction", hex.EncodeToString(sink.Bytes()))
+	return hash
+}
+
+// wait returns the notifies of the tx once it is executed successfully
+func (self *soloRecorder) wait(hash common.Uint256) []bcomn.NotifyEventInfo {
+	for i := 0; i < 60; i++ {
+		notify := new(bcomn.ExecuteNotify)
+		if res := self.call("getsmartcodeevent", hash.ToHexString()); string(res) != "null" {
+			assert.NoError(self.t, json.Unmarshal(res, notify))
+			if notify.State != 1 {
+				self.t.Fatalf("tx %s failed: %v", hash.ToHexString(), notify.Notify)
+			}
+			return notify.Notify
+		}
+		time.Sleep(time.Second)
+	}
+	self.t.Fatalf("tx %s is not executed", hash.ToHexString())
+	return nil
+}
+
+func (self *soloRecorder) blockCount() uint32 {
+	var count uint32
+	assert.NoError(self.t, json.Unmarshal(self.call("getblockcount"), &count))
+	return count
+}
+
+func (self *soloRecorder) header(height uint32) *types.Header {
+	var raw string
+	assert.NoError(self.t, json.Unmarshal(self.call("getblock", height), &raw))
+	block, err := types.BlockFromRawBytes(mustDecodeHex(self.t, raw))
+	if err != nil {
+		self.t.Fatalf("decode block %d error: %v", height, err)
+	}
+	return block.Header
+}
+
+func (self *soloRecorder) proof(method string, params ...interface{}) string {
+	proof := new(bcomn.MerkleProof)
+	assert.NoError(self.t, json.Unmarshal(self.call(method, params...), proof))
+	return proof.AuditPath
+}
+
+// TestRecordSoloFixture records testdata/solo_chain.json from a solo node. The only consensus peer in
+// testdata/solo_genesis.json is the keeper of testdata/solo_wallet.dat, so it can approve side chains and vote
+// cross chain txs through the vote router alone. Start a node from an empty data dir with
+//
+//	poly --config polylightclient/testdata/solo_genesis.json --wallet polylightclient/testdata/solo_wallet.dat --password passwordtest
+//
+// and run `go test -run TestRecordSoloFixture -update` in this directory.
+func TestRecordSoloFixture(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update against a solo node to record the fixture")
+	}
+	wallet, err := account.Open(soloWalletPath)
+	assert.NoError(t, err)
+	keeper, err := wallet.GetDefaultAccount([]byte(soloWalletPassword))
+	if err != nil {
+		t.Fatalf("open wallet error: %v", err)
+	}
+	rec := &soloRecorder{t: t, url: *soloRPC, keeper: keeper, nonce: uint32(time.Now().Unix())}
+	rec.chainID = rec.header(rec.blockCount() - 1).ChainID
+
+	// register the source and target chains of the vote router
+	hashes := make([]common.Uint256, 0)
+	for _, chainID := range []uint64{soloSourceChainID, soloTargetChainID} {
+		sink := common.NewZeroCopySink(nil)
+		assert.NoError(t, (&side_chain_manager.RegisterSideChainParam{
+			Address:      keeper.Address,
+			ChainId:      chainID,
+			Router:       utils.VOTE_ROUTER,
+			Name:         fmt.Sprintf("solo%d", chainID),
+			BlocksToWait: 1,
+			CCMCAddress:  []byte{0x1, 0x2, 0x3},
+		}).Serialization(sink))
+		hashes = append(hashes, rec.invoke(utils.SideChainManagerContractAddress, side_chain_manager.REGISTER_SIDE_CHAIN, sink.Bytes()))
+	}
+	for _, hash := range hashes {
+		rec.wait(hash)
+	}
+	hashes = hashes[:0]
+	for _, chainID := range []uint64{soloSourceChainID, soloTargetChainID} {
+		sink := common.NewZeroCopySink(nil)
+		(&side_chain_manager.ChainidParam{Chainid: chainID, Address: keeper.Address}).Serialization(sink)
+		hashes = append(hashes, rec.invoke(utils.SideChainManagerContractAddress, side_chain_manager.APPROVE_REGISTER_SIDE_CHAIN, sink.Bytes()))
+	}
+	for _, hash := range hashes {
+		rec.wait(hash)
+	}
+
+	// two batches of cross chain txs voted by the keeper, the states of each block are committed by the next one
+	fixture := &soloFixture{Keeper: hex.EncodeToString(keypair.SerializePublicKey(keeper.PublicKey))}
+	for batch := 1; batch <= 2; batch++ {
+		hashes = hashes[:0]
+		for i := 0; i < 4-batch; i++ {
+			id := []byte{byte(batch), byte(i)}
+			sink := common.NewZeroCopySink(nil)
+			(&scom.MakeTxParam{
+				TxHash:              id,
+				CrossChainID:        id,
+				FromContractAddress: []byte{0x1, 0x2, 0x3},
+				ToChainID:           soloTargetChainID,
+				ToContractAddress:   []byte{0x4, 0x5, 0x6},
+				Method:              "unlock",
+				Args:                id,
+			}).Serialization(sink)
+			extra := sink.Bytes()
+			sink = common.NewZeroCopySink(nil)
+			(&scom.EntranceParam{
+				SourceChainID:  soloSourceChainID,
+				Height:         uint32(batch*100 + i),
+				RelayerAddress: keeper.Address[:],
+				Extra:          extra,
+			}).Serialization(sink)
+			hashes = append(hashes, rec.invoke(utils.CrossChainManagerContractAddress, scom.IMPORT_OUTER_TRANSFER_NAME, sink.Bytes()))
+		}
+		for _, hash := range hashes {
+			for _, notify := range rec.wait(hash) {
+				states, ok := notify.States.([]interface{})
+				if !ok || len(states) != 6 || states[0] != scom.NOTIFY_MAKE_PROOF {
+					continue
+				}
+				height := uint32(states[4].(float64))
+				key := states[5].(string)
+				raw := mustDecodeHex(t, key)
+				fixture.CrossStates = append(fixture.CrossStates, crossStateFixture{
+					Height:    height,
+					TxHash:    hex.EncodeToString(raw[len(raw)-common.UINT256_SIZE:]),
+					ToChainID: soloTargetChainID,
+					Proof:     rec.proof("getcrossstatesproof", height, key),
+				})
+			}
+		}
+	}
+	if len(fixture.CrossStates) == 0 {
+		t.Fatal("no cross chain tx is made")
+	}
+
+	// wait for the headers committing the last states
+	last := fixture.CrossStates[len(fixture.CrossStates)-1].Height + 2
+	for rec.blockCount() <= last {
+		time.Sleep(time.Second)
+	}
+	for height := uint32(0); height <= last; height++ {
+		fixture.Headers = append(fixture.Headers, hex.EncodeToString(rec.header(height).ToArray()))
+	}
+	for _, height := range []uint32{1, 2, fixture.CrossStates[0].Height} {
+		fixture.BlockProofs = append(fixture.BlockProofs, blockProofFixture{
+			Height:     height,
+			RootHeight: last,
+			Proof:      rec.proof("getmerkleproof", height, last),
+		})
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(soloFixturePath, append(data, '\n'), 0644))
+}
+
+// TestRecordVbftFixture records testdata/vbft_chain.json from a local vbft network of the four genesis peers of
+// testdata/vbft/genesis.json and a fifth node. Start node i of 1 to 5 from an empty data dir with
+//
+//	poly --config polylightclient/testdata/vbft/genesis.json --wallet polylightclient/testdata/vbft/wallet<i>.dat \
+//		--password passwordtest --networkid 3 --enable-consensus --nodeport <30328+10*i> --rpcport <30329+10*i>
+//
+// moving the other listening ports apart the same way, and run `go test -run TestRecordVbftFixture -update-vbft`
+// in this directory. The fifth node is registered and approved as candidate, the commitDpos every
+// max_block_change_view blocks then makes it a keeper.
+func TestRecordVbftFixture(t *testing.T) {
+	if !*updateVbft {
+		t.Skip("run with -update-vbft against a local vbft network to record the fixture")
+	}
+	recs := make([]*soloRecorder, 0, vbftNodes)
+	nonce := uint32(time.Now().Unix())
+	for i := 1; i <= vbftNodes; i++ {
+		wallet, err := account.Open(fmt.Sprintf(vbftWalletPath, i))
+		assert.NoError(t, err)
+		keeper, err := wallet.GetDefaultAccount([]byte(soloWalletPassword))
+		if err != nil {
+			t.Fatalf("open wallet %d error: %v", i, err)
+		}
+		recs = append(recs, &soloRecorder{t: t, url: *vbftRPC, keeper: keeper, nonce: nonce})
+	}
+	chainID := recs[0].header(recs[0].blockCount() - 1).ChainID
+	for _, rec := range recs {
+		rec.chainID = chainID
+	}
+
+	candidate := recs[vbftNodes-1].keeper
+	peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(candidate.PublicKey))
+	sink := common.NewZeroCopySink(nil)
+	(&node_manager.RegisterPeerParam{PeerPubkey: peerPubkey, Address: candidate.Address}).Serialization(sink)
+	// only consensus peers may send txs, so a genesis peer cosigns the registration
+	hash := recs[vbftNodes-1].invoke(utils.NodeManagerContractAddress, node_manager.REGISTER_CANDIDATE, sink.Bytes(), recs[0].keeper)
+	recs[vbftNodes-1].wait(hash)
+	// approved once signed by 2/3 of the four genesis peers
+	for _, rec := range recs[:3] {
+		sink := common.NewZeroCopySink(nil)
+		(&node_manager.PeerParam{PeerPubkey: peerPubkey, Address: rec.keeper.Address}).Serialization(sink)
+		rec.wait(rec.invoke(utils.NodeManagerContractAddress, node_manager.APPROVE_CANDIDATE, sink.Bytes()))
+	}
+
+	rec := recs[0]
+	fixture := new(vbftFixture)
+	for height := uint32(1); fixture.EpochHeight == 0; height++ {
+		for rec.blockCount() <= height {
+			time.Sleep(time.Second)
+		}
+		keepers, err := GetEpochKeepers(rec.header(height))
+		assert.NoError(t, err)
+		if len(keepers) == vbftNodes {
+			fixture.EpochHeight = height
+		}
+	}
+	last := fixture.EpochHeight + 2
+	for rec.blockCount() <= last {
+		time.Sleep(time.Second)
+	}
+	for height := uint32(0); height <= last; height++ {
+		fixture.Headers = append(fixture.Headers, hex.EncodeToString(rec.header(height).ToArray()))
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(vbftFixturePath, append(data, '\n'), 0644))
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package polylightclient verifies poly chain headers and cross chain states
+// the same way the cross chain manager contracts on target chains do.
+//
+// A light client starts from a trusted epoch header, the genesis block or a block
+// carrying a consensus change, and the keepers of that epoch. Headers of the epoch
+// must be signed by n - (n-1)/3 of the keepers. A header that carries NewChainConfig
+// in its vbft consensus payload moves the client to the next epoch.
+package polylightclient
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+)
+
+type LightClient struct {
+	epochHeight    uint32
+	keepers        []keypair.PublicKey
+	nextBookkeeper common.Address
+}
+
+//NewLightClient return a light client trusting header as the start of an epoch. The keepers of the epoch
+//are read from the NewChainConfig of header if keepers is empty, in solo mode they must be given.
+func NewLightClient(header *types.Header, keepers []keypair.PublicKey) (*LightClient, error) {
+	if len(keepers) == 0 {
+		var err error
+		keepers, err = GetEpochKeepers(header)
+		if err != nil {
+			return nil, fmt.Errorf("NewLightClient, GetEpochKeepers error: %v", err)
+		}
+		if len(keepers) == 0 {
+			return nil, fmt.Errorf("NewLightClient, no keepers in header of height %d", header.Height)
+		}
+	}
+	addr, err := types.AddressFromBookkeepers(keepers)
+	if err != nil {
+		return nil, fmt.Errorf("NewLightClient, AddressFromBookkeepers error: %v", err)
+	}
+	if addr != header.NextBookkeeper {
+		return nil, fmt.Errorf("NewLightClient, keepers address %s not match NextBookkeeper %s of header",
+			addr.ToBase58(), header.NextBookkeeper.ToBase58())
+	}
+	return &LightClient{
+		epochHeight:    header.Height,
+		keepers:        keepers,
+		nextBookkeeper: addr,
+	}, nil
+}
+
+//EpochHeight return the height of the header starting current epoch
+func (this *LightClient) EpochHeight() uint32 {
+	return this.epochHeight
+}
+
+//Keepers return the keepers of current epoch
+func (this *LightClient) Keepers() []keypair.PublicKey {
+	return this.keepers
+}
+
+//VerifyHeader check header is signed by enough keepers of current epoch
+func (this *LightClient) VerifyHeader(header *types.Header) error {
+	if header.Height < this.epochHeight {
+		return fmt.Errorf("VerifyHeader, height %d is lower than epoch height %d", header.Height, this.epochHeight)
+	}
+	hash := header.Hash()
+	if err := signature.VerifyMultiSignature(hash[:], this.keepers, Threshold(len(this.keepers)), header.SigData); err != nil {
+		return fmt.Errorf("VerifyHeader, verify signatures of header %d error: %v", header.Height, err)
+	}
+	return nil
+}
+
+//SyncEpochHeader verify a consensus change header and switch to the keepers it announces.
+//A header announcing the current keepers again, as every solo block does, keeps the epoch unchanged.
+func (this *LightClient) SyncEpochHeader(header *types.Header) error {
+	if header.NextBookkeeper == common.ADDRESS_EMPTY {
+		return fmt.Errorf("SyncEpochHeader, header %d is not an epoch header", header.Height)
+	}
+	if err := this.VerifyHeader(header); err != nil {
+		return fmt.Errorf("SyncEpochHeader, %v", err)
+	}
+	if header.NextBookkeeper == this.nextBookkeeper {
+		return nil
+	}
+	keepers, err := GetEpochKeepers(header)
+	if err != nil {
+		return fmt.Errorf("SyncEpochHeader, GetEpochKeepers error: %v", err)
+	}
+	if len(keepers) == 0 {
+		return fmt.Errorf("SyncEpochHeader, header %d changes NextBookkeeper without NewChainConfig", header.Height)
+	}
+	addr, err := types.AddressFromBookkeepers(keepers)
+	if err != nil {
+		return fmt.Errorf("SyncEpochHeader, AddressFromBookkeepers error: %v", err)
+	}
+	if addr != header.NextBookkeeper {
+		return fmt.Errorf("SyncEpochHeader, keepers address %s not match NextBookkeeper %s of header",
+			addr.ToBase58(), header.NextBookkeeper.ToBase58())
+	}
+	this.epochHeight = header.Height
+	this.keepers = keepers
+	this.nextBookkeeper = addr
+	return nil
+}
+
+//VerifyHeaderByAnchor verify a header of any height through an anchor header of current epoch,
+//blockProof is the merkle proof of header hash in the BlockRoot of anchor, as returned by getmerkleproof rpc
+func (this *LightClient) VerifyHeaderByAnchor(header, anchor *types.Header, blockProof []byte) error {
+	if header.Height >= anchor.Height {
+		return fmt.Errorf("VerifyHeaderByAnchor, anchor height %d must be higher than header height %d", anchor.Height, header.Height)
+	}
+	if err := this.VerifyHeader(anchor); err != nil {
+		return fmt.Errorf("VerifyHeaderByAnchor, verify anchor error: %v", err)
+	}
+	value, err := merkle.MerkleProve(blockProof, anchor.BlockRoot[:])
+	if err != nil {
+		return fmt.Errorf("VerifyHeaderByAnchor, MerkleProve error: %v", err)
+	}
+	hash := header.Hash()
+	if !bytes.Equal(value, hash[:]) {
+		return fmt.Errorf("VerifyHeaderByAnchor, proof is for block %x, not header %s", value, hash.ToHexString())
+	}
+	return nil
+}
+
+//Threshold return the number of keeper signatures needed for a header
+func Threshold(n int) int {
+	return n - (n-1)/3
+}
+
+//GetEpochKeepers return the keepers announced by the NewChainConfig of a vbft header,
+//nil is returned if header does not change consensus
+func GetEpochKeepers(header *types.Header) ([]keypair.PublicKey, error) {
+	if len(header.ConsensusPayload) == 0 {
+		return nil, nil
+	}
+	info, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return nil, fmt.Errorf("GetEpochKeepers, unmarshal consensus payload error: %v", err)
+	}
+	if info.NewChainConfig == nil {
+		return nil, nil
+	}
+	keepers := make([]keypair.PublicKey, 0, len(info.NewChainConfig.Peers))
+	for _, peer := range info.NewChainConfig.Peers {
+		pk, err := vconfig.Pubkey(peer.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetEpochKeepers, peer %d id error: %v", peer.Index, err)
+		}
+		keepers = append(keepers, pk)
+	}
+	return keepers, nil
+}
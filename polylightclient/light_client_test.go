@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package polylightclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoloHeaders(t *testing.T) {
+	_, keeper, headers := loadSoloFixture(t)
+	_, err := NewLightClient(headers[0], nil)
+	assert.Error(t, err, "solo genesis has no chain config")
+
+	lc, err := NewLightClient(headers[0], []keypair.PublicKey{keeper})
+	assert.NoError(t, err)
+	for _, header := range headers[1:] {
+		assert.NoError(t, lc.VerifyHeader(header))
+		assert.NoError(t, lc.SyncEpochHeader(header))
+	}
+	assert.Equal(t, uint32(0), lc.EpochHeight())
+
+	other := account.NewAccount("")
+	_, err = NewLightClient(headers[0], []keypair.PublicKey{other.PublicKey})
+	assert.Error(t, err)
+}
+
+func TestSoloTamperedHeader(t *testing.T) {
+	fixture, keeper, headers := loadSoloFixture(t)
+	lc, err := NewLightClient(headers[0], []keypair.PublicKey{keeper})
+	assert.NoError(t, err)
+
+	header := decodeHeader(t, fixture.Headers[2])
+	header.CrossStateRoot = common.Uint256{1}
+	assert.Error(t, lc.VerifyHeader(header))
+
+	other := account.NewAccount("")
+	header = decodeHeader(t, fixture.Headers[2])
+	hash := header.Hash()
+	sig, err := signature.Sign(other, hash[:])
+	assert.NoError(t, err)
+	header.Bookkeepers = []keypair.PublicKey{other.PublicKey}
+	header.SigData = [][]byte{sig}
+	assert.Error(t, lc.VerifyHeader(header))
+
+	header = decodeHeader(t, fixture.Headers[2])
+	header.SigData = nil
+	assert.Error(t, lc.VerifyHeader(header))
+}
+
+func TestSoloCrossStates(t *testing.T) {
+	fixture, keeper, headers := loadSoloFixture(t)
+	lc, err := NewLightClient(headers[0], []keypair.PublicKey{keeper})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fixture.CrossStates)
+
+	for _, state := range fixture.CrossStates {
+		proof := mustDecodeHex(t, state.Proof)
+		value, err := lc.VerifyCrossState(headers[state.Height+1], proof)
+		assert.NoError(t, err)
+		assert.Equal(t, state.TxHash, hex.EncodeToString(value.TxHash))
+		assert.Equal(t, state.ToChainID, value.MakeTxParam.ToChainID)
+
+		_, err = lc.VerifyCrossState(headers[state.Height], proof)
+		assert.Error(t, err, "states are committed by the next block")
+
+		proof[len(proof)-1] ^= 1
+		_, err = lc.VerifyCrossState(headers[state.Height+1], proof)
+		assert.Error(t, err)
+	}
+}
+
+func TestSoloHeaderByAnchor(t *testing.T) {
+	fixture, keeper, headers := loadSoloFixture(t)
+	lc, err := NewLightClient(headers[0], []keypair.PublicKey{keeper})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fixture.BlockProofs)
+
+	for _, item := range fixture.BlockProofs {
+		proof := mustDecodeHex(t, item.Proof)
+		anchor := headers[item.RootHeight]
+		assert.NoError(t, lc.VerifyHeaderByAnchor(headers[item.Height], anchor, proof))
+		assert.Error(t, lc.VerifyHeaderByAnchor(headers[item.Height+1], anchor, proof))
+	}
+}
+
+type testKeepers []*account.Account
+
+func newTestKeepers(n int) testKeepers {
+	keepers := make(testKeepers, 0, n)
+	for i := 0; i < n; i++ {
+		keepers = append(keepers, account.NewAccount(""))
+	}
+	return keepers
+}
+
+func (self testKeepers) pubKeys() []keypair.PublicKey {
+	pks := make([]keypair.PublicKey, 0, len(self))
+	for _, acc := range self {
+		pks = append(pks, acc.PublicKey)
+	}
+	return pks
+}
+
+// makeVbftHeader makes a header signed by signers, announcing next keepers in NewChainConfig if it is not empty
+func makeVbftHeader(t *testing.T, height uint32, next testKeepers, signers testKeepers) *types.Header {
+	header := &types.Header{Height: height}
+	if len(next) != 0 {
+		chainConfig := &vconfig.ChainConfig{N: uint32(len(next))}
+		for i, pk := range next.pubKeys() {
+			chainConfig.Peers = append(chainConfig.Peers, &vconfig.PeerConfig{Index: uint32(i + 1), ID: vconfig.PubkeyID(pk)})
+		}
+		payload, err := json.Marshal(&vconfig.VbftBlockInfo{NewChainConfig: chainConfig})
+		assert.NoError(t, err)
+		header.ConsensusPayload = payload
+		header.NextBookkeeper, err = types.AddressFromBookkeepers(next.pubKeys())
+		assert.NoError(t, err)
+	}
+	hash := header.Hash()
+	for _, acc := range signers {
+		sig, err := signature.Sign(acc, hash[:])
+		assert.NoError(t, err)
+		header.Bookkeepers = append(header.Bookkeepers, acc.PublicKey)
+		header.SigData = append(header.SigData, sig)
+	}
+	return header
+}
+
+func TestVbftEpochChange(t *testing.T) {
+	keepers := newTestKeepers(4)
+	lc, err := NewLightClient(makeVbftHeader(t, 0, keepers, nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(lc.Keepers()))
+
+	assert.NoError(t, lc.VerifyHeader(makeVbftHeader(t, 1, nil, keepers[1:])))
+	assert.Error(t, lc.VerifyHeader(makeVbftHeader(t, 1, nil, keepers[2:])), "3 of 4 signatures needed")
+	assert.Error(t, lc.SyncEpochHeader(makeVbftHeader(t, 2, nil, keepers)), "not an epoch header")
+
+	next := append(newTestKeepers(5), keepers[0], keepers[1])
+	assert.Error(t, lc.SyncEpochHeader(makeVbftHeader(t, 10, next, keepers[2:])))
+	assert.Equal(t, uint32(0), lc.EpochHeight())
+
+	epochHeader := makeVbftHeader(t, 10, next, keepers[:3])
+	epochHeader.NextBookkeeper = common.Address{1}
+	assert.Error(t, lc.SyncEpochHeader(epochHeader), "NextBookkeeper not match chain config")
+
+	assert.NoError(t, lc.SyncEpochHeader(makeVbftHeader(t, 10, next, keepers[:3])))
+	assert.Equal(t, uint32(10), lc.EpochHeight())
+	assert.Equal(t, len(next), len(lc.Keepers()))
+
+	assert.Error(t, lc.VerifyHeader(makeVbftHeader(t, 11, nil, keepers)))
+	assert.Error(t, lc.VerifyHeader(makeVbftHeader(t, 9, nil, next)), "header of previous epoch")
+	assert.Error(t, lc.VerifyHeader(makeVbftHeader(t, 11, nil, next[:4])))
+	assert.NoError(t, lc.VerifyHeader(makeVbftHeader(t, 11, nil, next[:5])))
+}
+
+func TestVbftHeaders(t *testing.T) {
+	fixture, headers := loadVbftFixture(t)
+	lc, err := NewLightClient(headers[0], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(lc.Keepers()))
+
+	for _, header := range headers[1:] {
+		keepers, err := GetEpochKeepers(header)
+		assert.NoError(t, err)
+		if keepers == nil {
+			assert.NoError(t, lc.VerifyHeader(header), "header %d", header.Height)
+			continue
+		}
+		if header.Height == fixture.EpochHeight {
+			assert.Equal(t, 4, len(lc.Keepers()))
+		}
+		assert.NoError(t, lc.SyncEpochHeader(header), "epoch header %d", header.Height)
+	}
+	assert.Equal(t, fixture.EpochHeight, lc.EpochHeight())
+	assert.Equal(t, 5, len(lc.Keepers()))
+
+	lc, err = NewLightClient(headers[fixture.EpochHeight], nil)
+	assert.NoError(t, err)
+	assert.Error(t, lc.VerifyHeader(headers[fixture.EpochHeight-1]), "header of previous epoch")
+	assert.NoError(t, lc.VerifyHeader(headers[len(headers)-1]))
+}
+
+func TestThreshold(t *testing.T) {
+	assert.Equal(t, 1, Threshold(1))
+	assert.Equal(t, 3, Threshold(4))
+	assert.Equal(t, 5, Threshold(7))
+	assert.Equal(t, 5, Threshold(6))
+}
+
+func TestNoNativeDeps(t *testing.T) {
+	out, err := exec.Command("go", "list", "-deps", ".").Output()
+	if err != nil {
+		t.Skipf("go list unavailable: %v", err)
+	}
+	for _, dep := range strings.Fields(string(out)) {
+		if strings.HasPrefix(dep, "github.com/polynetwork/poly/native") {
+			t.Errorf("polylightclient must not depend on %s", dep)
+		}
+	}
+}
@@ -20,6 +20,7 @@
 package common
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/polynetwork/poly/common"
@@ -130,6 +131,9 @@ func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 	for _, txEntry := range tp.txList {
 		orderByFee = append(orderByFee, txEntry)
 	}
+	sort.SliceStable(orderByFee, func(i, j int) bool {
+		return orderByFee[i].Tx.GasPrice > orderByFee[j].Tx.GasPrice
+	})
 
 	count := int(config.DefConfig.Consensus.MaxTxInBlock)
 	if count <= 0 {
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proc
+
+import (
+	"sync"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	scommon "github.com/polynetwork/poly/core/store/common"
+	tx "github.com/polynetwork/poly/core/types"
+	bactor "github.com/polynetwork/poly/http/base/actor"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+// relayedCall returns the source chain and method of a relayer entrypoint called by the txn,
+// ok is false if the txn doesn't call header_sync or cross_chain_manager entrypoints
+func relayedCall(txn *tx.Transaction) (chainID uint64, method string, ok bool) {
+	invoke, isInvoke := txn.Payload.(*payload.InvokeCode)
+	if !isInvoke {
+		return
+	}
+	param := new(states.ContractInvokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(invoke.Code)); err != nil {
+		return
+	}
+	source := common.NewZeroCopySource(param.Args)
+	switch {
+	case param.Address == utils.HeaderSyncContractAddress && param.Method == hscommon.SYNC_BLOCK_HEADER:
+		p := new(hscommon.SyncBlockHeaderParam)
+		if err := p.Deserialization(source); err != nil {
+			return
+		}
+		return p.ChainID, param.Method, true
+	case param.Address == utils.HeaderSyncContractAddress && param.Method == hscommon.SYNC_CROSS_CHAIN_MSG:
+		p := new(hscommon.SyncCrossChainMsgParam)
+		if err := p.Deserialization(source); err != nil {
+			return
+		}
+		return p.ChainID, param.Method, true
+	case param.Address == utils.CrossChainManagerContractAddress && param.Method == scom.IMPORT_OUTER_TRANSFER_NAME:
+		p := new(scom.EntranceParam)
+		if err := p.Deserialization(source); err != nil {
+			return
+		}
+		return p.SourceChainID, param.Method, true
+	}
+	return
+}
+
+// getRelayerScope returns the scope of the relayer in the ledger, nil if the relayer is not restricted
+func getRelayerScope(address common.Address) (*relayer_manager.RelayerScope, error) {
+	key := append([]byte(relayer_manager.RELAYER_SCOPE), address[:]...)
+	value, err := bactor.GetStorageItem(utils.RelayerManagerContractAddress, key)
+	if err != nil {
+		if err == scommon.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	scope := new(relayer_manager.RelayerScope)
+	if err := scope.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+// relayerQuota counts the distinct txns accepted from each relayer since the last block
+type relayerQuota struct {
+	sync.Mutex
+	height uint32
+	txs    map[common.Address]map[common.Uint256]bool
+}
+
+func newRelayerQuota() *relayerQuota {
+	return &relayerQuota{
+		txs: make(map[common.Address]map[common.Uint256]bool),
+	}
+}
+
+// acquire returns whether the relayer may send the txn at the height within quota, and records it if so
+func (q *relayerQuota) acquire(relayer common.Address, hash common.Uint256, height uint32, quota uint64) bool {
+	q.Lock()
+	defer q.Unlock()
+	if q.height != height {
+		q.height = height
+		q.txs = make(map[common.Address]map[common.Uint256]bool)
+	}
+	txs := q.txs[relayer]
+	if txs == nil {
+		txs = make(map[common.Uint256]bool)
+		q.txs[relayer] = txs
+	}
+	if txs[hash] {
+		return true
+	}
+	if uint64(len(txs)) >= quota {
+		return false
+	}
+	txs[hash] = true
+	return true
+}
+
+var quotas = newRelayerQuota()
+
+// isRelayerAllowed checks the scope and block quota of the registered relayer for the relayed call of txn
+func isRelayerAllowed(relayer common.Address, txn *tx.Transaction, chainID uint64, method string) (bool, error) {
+	scope, err := getRelayerScope(relayer)
+	if err != nil {
+		return false, err
+	}
+	if scope == nil {
+		return true, nil
+	}
+	if !scope.Allow(chainID, method) {
+		return false, nil
+	}
+	if scope.Quota == 0 {
+		return true, nil
+	}
+	return quotas.acquire(relayer, txn.Hash(), bactor.GetCurrentBlockHeight(), scope.Quota), nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+func newInvokeTx(contract common.Address, method string, args []byte) *types.Transaction {
+	sink := common.NewZeroCopySink(nil)
+	param := &states.ContractInvokeParam{Address: contract, Method: method, Args: args}
+	param.Serialization(sink)
+	return &types.Transaction{
+		TxType:  types.Invoke,
+		Payload: &payload.InvokeCode{Code: sink.Bytes()},
+	}
+}
+
+func TestRelayedCall(t *testing.T) {
+	sink := common.NewZeroCopySink(nil)
+	header := &hscommon.SyncBlockHeaderParam{ChainID: 3, Headers: [][]byte{{1}}}
+	header.Serialization(sink)
+	chainID, method, ok := relayedCall(newInvokeTx(utils.HeaderSyncContractAddress, hscommon.SYNC_BLOCK_HEADER, sink.Bytes()))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), chainID)
+	assert.Equal(t, hscommon.SYNC_BLOCK_HEADER, method)
+
+	sink = common.NewZeroCopySink(nil)
+	entrance := &scom.EntranceParam{SourceChainID: 5, Height: 10}
+	entrance.Serialization(sink)
+	chainID, method, ok = relayedCall(newInvokeTx(utils.CrossChainManagerContractAddress, scom.IMPORT_OUTER_TRANSFER_NAME, sink.Bytes()))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), chainID)
+	assert.Equal(t, scom.IMPORT_OUTER_TRANSFER_NAME, method)
+
+	_, _, ok = relayedCall(newInvokeTx(utils.RelayerManagerContractAddress, "registerRelayer", nil))
+	assert.False(t, ok)
+	_, _, ok = relayedCall(&types.Transaction{TxType: types.Invoke, Payload: &payload.InvokeCode{Code: []byte("ont")}})
+	assert.False(t, ok)
+}
+
+func TestRelayerQuota(t *testing.T) {
+	q := newRelayerQuota()
+	relayer := common.Address{1}
+	assert.True(t, q.acquire(relayer, common.Uint256{1}, 10, 2))
+	assert.True(t, q.acquire(relayer, common.Uint256{2}, 10, 2))
+	// the same txn received again doesn't count twice
+	assert.True(t, q.acquire(relayer, common.Uint256{1}, 10, 2))
+	assert.False(t, q.acquire(relayer, common.Uint256{3}, 10, 2))
+	assert.True(t, q.acquire(common.Address{2}, common.Uint256{3}, 10, 2))
+	// quota is reset by a new block
+	assert.True(t, q.acquire(relayer, common.Uint256{3}, 11, 2))
+}
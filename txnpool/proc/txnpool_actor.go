@@ -89,6 +89,9 @@ func (ta *TxActor) isValidSender(txn *tx.Transaction) (err error) {
 		return
 	}
 
+	// Relayers calling header_sync or cross_chain_manager entrypoints are restricted by their scope
+	chainID, method, relayed := relayedCall(txn)
+
 	// flag is set to true, meaning not any address in the signed addresses is permitted.
 	flag := true
 	for _, address := range addresses {
@@ -102,8 +105,17 @@ func (ta *TxActor) isValidSender(txn *tx.Transaction) (err error) {
 		// Check if address is registreed relayer
 		if len(value) > 0 {
 			// Here means address is registered relayer
-			flag = false
-			break
+			allowed := true
+			if relayed {
+				allowed, err = isRelayerAllowed(address, txn, chainID, method)
+				if err != nil {
+					return err
+				}
+			}
+			if allowed {
+				flag = false
+				break
+			}
 		}
 		// Check if address is included in permittedAddrMap, if so, the txn is permitted
 		if val, ok := permittedAddrMap[address]; val && ok {
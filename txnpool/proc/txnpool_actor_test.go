@@ -19,6 +19,7 @@
 package proc
 
 import (
+	"os"
 	"testing"
 	"time"
 
@@ -57,6 +58,16 @@ func init() {
 	}
 }
 
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if ledger.DefLedger != nil {
+		ledger.DefLedger.Close()
+	}
+	os.RemoveAll(config.DEFAULT_DATA_DIR)
+	os.RemoveAll(log.PATH)
+	os.Exit(code)
+}
+
 func TestTxActor(t *testing.T) {
 	t.Log("Starting tx actor test")
 	s := NewTxPoolServer(tc.MAX_WORKER_NUM, true, false)
@@ -24,6 +24,7 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/common/metrics"
 	tx "github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
 	tc "github.com/polynetwork/poly/txnpool/common"
@@ -506,6 +507,34 @@ func (s *TXPoolServer) getStats() []uint64 {
 	return ret
 }
 
+// txStatsNames are the metric label values of the transaction statistics
+var txStatsNames = []string{"received", "success", "failure", "duplicate", "sig_error", "state_error"}
+
+// RegisterMetrics registers the tx pool sizes and the transaction statistics
+func (s *TXPoolServer) RegisterMetrics(r *metrics.Registry) error {
+	return r.Register(
+		metrics.NewVecFunc(metrics.GAUGE, "poly_txpool_transactions", "Count of transactions in the tx pool",
+			[]string{"state"}, func() []metrics.Sample {
+				count := s.getTxCount()
+				return []metrics.Sample{
+					{LabelValues: []string{"verified"}, Value: float64(count[0])},
+					{LabelValues: []string{"pending"}, Value: float64(count[1])},
+				}
+			}),
+		metrics.NewVecFunc(metrics.COUNTER, "poly_txpool_stats_total", "Transaction statistics of the tx pool",
+			[]string{"type"}, func() []metrics.Sample {
+				stats := s.getStats()
+				samples := make([]metrics.Sample, 0, len(stats))
+				for i, v := range stats {
+					if i < len(txStatsNames) {
+						samples = append(samples, metrics.Sample{LabelValues: []string{txStatsNames[i]}, Value: float64(v)})
+					}
+				}
+				return samples
+			}),
+	)
+}
+
 // checkTx checks whether a transaction is in the pending list or
 // the transacton pool
 func (s *TXPoolServer) checkTx(hash common.Uint256) bool {
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/payload"
 	"github.com/polynetwork/poly/core/types"
@@ -49,13 +50,15 @@ func init() {
 		Code: code,
 	}
 
-	mutable := &types.MutableTransaction{
+	tx := &types.Transaction{
 		TxType:  types.Invoke,
 		Nonce:   uint32(time.Now().Unix()),
 		Payload: invokeCodePayload,
+		Sigs:    []types.Sig{},
 	}
-
-	txn, _ = mutable.IntoImmutable()
+	sink := common.NewZeroCopySink(nil)
+	tx.Serialization(sink)
+	txn, _ = types.TransactionFromRawBytes(sink.Bytes())
 
 	sender = tc.NilSender
 }
@@ -20,6 +20,7 @@ package stateful
 
 import (
 	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/types"
@@ -77,6 +78,13 @@ func (self *validator) Receive(context actor.Context) {
 			errCode = errors.ErrUnknown
 		} else if exist {
 			errCode = errors.ErrDuplicatedTx
+		} else if msg.Tx.GasPrice < config.DefConfig.Common.GasPrice {
+			log.Debugf("stateful-validator: tx %x gas price %d lower than %d", hash, msg.Tx.GasPrice, config.DefConfig.Common.GasPrice)
+			errCode = errors.ErrGasPrice
+		} else if len(msg.Tx.Sigs) == 0 && height+1 >= config.GetGasMeteringHeight(config.DefConfig.P2PNode.NetworkId) {
+			//unsigned transactions are not metered, only consensus makes them
+			log.Debugf("stateful-validator: tx %x is not signed", hash)
+			errCode = errors.ErrVerifySignature
 		}
 
 		response := &vatypes.CheckResponse{